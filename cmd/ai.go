@@ -0,0 +1,151 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"dev-cli/internal/config"
+	"dev-cli/internal/infra"
+	"dev-cli/internal/llm"
+	"dev-cli/internal/rag"
+	"dev-cli/internal/storage"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	benchModels []string
+	benchRounds int
+)
+
+var aiCmd = &cobra.Command{
+	Use:   "ai",
+	Short: "Inspect and tune the AI backends",
+}
+
+var aiBenchCmd = &cobra.Command{
+	Use:   "bench",
+	Short: "Benchmark installed Ollama models on this machine",
+	Long: `Run a fixed suite of Explain and Research prompts against local models and
+report latency, JSON-validity rate and token throughput.
+
+The fastest model whose JSON-validity is close to the best is recommended as
+the default (DEV_CLI_OLLAMA_MODEL).`,
+	Example: `  dev-cli ai bench
+  dev-cli ai bench --models qwen2.5-coder:3b-instruct,llama3.2:3b
+  dev-cli ai bench --rounds 3`,
+	Args: cobra.NoArgs,
+	RunE: runAIBench,
+}
+
+var aiIndexCmd = &cobra.Command{
+	Use:   "index",
+	Short: "Index this project's docs for AI answers",
+	Long: `Chunk the README, docs/ and Makefile of the current project into
+embeddings so '?' questions in the UI can quote the project's own scripts and
+conventions. Only changed files are re-embedded; questions refresh the index
+automatically, so this is only needed to warm it up.`,
+	Args: cobra.NoArgs,
+	RunE: runAIIndex,
+}
+
+func init() {
+	rootCmd.AddCommand(aiCmd)
+	aiCmd.AddCommand(aiBenchCmd)
+	aiCmd.AddCommand(aiIndexCmd)
+	aiBenchCmd.Flags().StringSliceVar(&benchModels, "models", nil, "Models to benchmark (default: all installed)")
+	aiBenchCmd.Flags().IntVar(&benchRounds, "rounds", 1, "Times to run the suite per model")
+}
+
+func runAIBench(cmd *cobra.Command, args []string) error {
+	cfg := config.Load()
+	if benchRounds < 1 {
+		return fmt.Errorf("--rounds must be at least 1")
+	}
+
+	models := benchModels
+	if len(models) == 0 {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		installed, err := infra.NewOllamaClient(nil, cfg.OllamaURL).ListModels(ctx)
+		if err != nil {
+			return fmt.Errorf("list Ollama models: %w", err)
+		}
+		for _, m := range installed {
+			// Embedding models cannot answer generate prompts.
+			if strings.Contains(m.Name, "embed") {
+				continue
+			}
+			models = append(models, m.Name)
+		}
+	}
+	if len(models) == 0 {
+		return fmt.Errorf("no models installed (try: ollama pull %s)", cfg.OllamaModel)
+	}
+
+	client := llm.NewClient(cfg)
+	total := len(llm.BenchSuite) * benchRounds
+	var results []llm.BenchResult
+	for _, model := range models {
+		done := 0
+		result := client.WithModel(model).Bench(llm.BenchSuite, benchRounds, func(bc llm.BenchCase, err error) {
+			done++
+			mark := "\033[32m✓\033[0m"
+			if err != nil {
+				mark = "\033[31m✗\033[0m"
+			}
+			fmt.Fprintf(os.Stderr, "\r\033[K%s [%d/%d] %s %s", model, done, total, mark, bc.Name)
+		})
+		fmt.Fprint(os.Stderr, "\r\033[K")
+		results = append(results, result)
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "MODEL\tLATENCY\tVALID JSON\tTOKENS/S\tERRORS")
+	fmt.Fprintln(w, "-----\t-------\t----------\t--------\t------")
+	for _, r := range results {
+		fmt.Fprintf(w, "%s\t%s\t%d/%d (%.0f%%)\t%.1f\t%d\n",
+			r.Model, r.Latency.Round(10*time.Millisecond), r.ValidJSON, r.Runs, r.ValidRate()*100, r.TokensPerSec, r.Errors)
+	}
+	w.Flush()
+	fmt.Println()
+
+	best, ok := llm.RecommendModel(results)
+	if !ok {
+		fmt.Println("\033[33m!\033[0m No model produced valid JSON; keep the current default or pull a larger model.")
+		return nil
+	}
+	if best == cfg.OllamaModel {
+		fmt.Printf("\033[32m✓\033[0m Current default %s is the best fit for this machine.\n", best)
+		return nil
+	}
+	fmt.Printf("\033[1m➜ Recommended default:\033[0m %s\n", best)
+	fmt.Printf("   export DEV_CLI_OLLAMA_MODEL=%s\n", best)
+	return nil
+}
+
+func runAIIndex(cmd *cobra.Command, args []string) error {
+	cwd, _ := os.Getwd()
+	fp := storage.DetectProjectFingerprint(cwd)
+	if fp == nil {
+		return fmt.Errorf("no project found at or above %s", cwd)
+	}
+
+	db, err := storage.InitDB()
+	if err != nil {
+		return fmt.Errorf("open history: %w", err)
+	}
+	defer db.Close()
+
+	stats, err := rag.Index(db, fp.DetectedAt, llm.NewHybridClient())
+	if err != nil {
+		return fmt.Errorf("index docs: %w", err)
+	}
+	fmt.Printf("\033[32m✓\033[0m %s: %d doc files, %d re-embedded, %d removed, %d chunks indexed\n",
+		fp.DetectedAt, stats.Files, stats.Embedded, stats.Removed, stats.Chunks)
+	return nil
+}
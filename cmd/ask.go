@@ -104,7 +104,7 @@ func looksLikeToolName(args []string) bool {
 }
 
 func fetchSolutions(query string) {
-	client := ai.NewHybridClient()
+	client := projectAI()
 
 	backend := "Ollama"
 	if client.HasPerplexity() {
@@ -0,0 +1,140 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"dev-cli/internal/config"
+	"dev-cli/internal/storage"
+
+	"github.com/spf13/cobra"
+)
+
+var dbRestoreLatest bool
+
+var dbCmd = &cobra.Command{
+	Use:   "db",
+	Short: "Back up and restore the history database",
+	Long: `Snapshot the history database (commands, sessions, root causes,
+runbooks, bookmarks) while it stays in use, and bring a snapshot back.
+
+Backups go to backups/ next to the database (~/.devlogs/backups), named
+history-<time>.db. With DEV_CLI_AUTO_BACKUP=1 one is taken a day as commands
+are logged, keeping the newest DEV_CLI_BACKUP_KEEP (default 7).`,
+}
+
+var dbBackupCmd = &cobra.Command{
+	Use:   "backup [file]",
+	Short: "Snapshot the history database",
+	Example: `  dev-cli db backup
+  dev-cli db backup ~/history-before-upgrade.db`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: runDBBackup,
+}
+
+var dbRestoreCmd = &cobra.Command{
+	Use:   "restore [backup]",
+	Short: "Replace the history database with a backup",
+	Long: `Replace the history database with a backup: a file, or the name of one
+in the backups directory. Without one, the backups are listed. The current
+database is backed up first, so a restore can be undone.`,
+	Example: `  dev-cli db restore --latest
+  dev-cli db restore history-20260301-090000.db`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: runDBRestore,
+}
+
+func init() {
+	rootCmd.AddCommand(dbCmd)
+	dbCmd.AddCommand(dbBackupCmd, dbRestoreCmd)
+	dbRestoreCmd.Flags().BoolVar(&dbRestoreLatest, "latest", false, "Restore the newest backup")
+}
+
+func runDBBackup(cmd *cobra.Command, args []string) error {
+	dir, err := storage.BackupDir()
+	if err != nil {
+		return err
+	}
+	db, err := storage.InitDB()
+	if err != nil {
+		return fmt.Errorf("open history: %w", err)
+	}
+	defer db.Close()
+
+	path := storage.BackupPath(dir, time.Now(), "")
+	if len(args) == 1 {
+		path = args[0]
+	}
+	if err := storage.Backup(db, path); err != nil {
+		return fmt.Errorf("back up: %w", err)
+	}
+	fmt.Printf("Backed up the history to %s\n", path)
+
+	// Only the backups directory is rotated; a named file is the user's.
+	if len(args) == 0 {
+		removed, err := storage.RotateBackups(dir, config.Current.BackupKeep)
+		if err != nil {
+			return fmt.Errorf("rotate backups: %w", err)
+		}
+		if len(removed) > 0 {
+			fmt.Printf("Removed %d old backups (keeping %d)\n", len(removed), config.Current.BackupKeep)
+		}
+	}
+	return nil
+}
+
+func runDBRestore(cmd *cobra.Command, args []string) error {
+	dir, err := storage.BackupDir()
+	if err != nil {
+		return err
+	}
+	backups, err := storage.ListBackups(dir)
+	if err != nil {
+		return fmt.Errorf("list backups: %w", err)
+	}
+
+	var src string
+	switch {
+	case len(args) == 1:
+		src = args[0]
+		if _, err := os.Stat(src); errors.Is(err, os.ErrNotExist) && filepath.Base(src) == src {
+			src = filepath.Join(dir, src)
+		}
+	case dbRestoreLatest:
+		if len(backups) == 0 {
+			return fmt.Errorf("no backups in %s", dir)
+		}
+		src = backups[0].Path
+	default:
+		if len(backups) == 0 {
+			fmt.Printf("No backups in %s yet; take one with 'dev-cli db backup'.\n", dir)
+			return nil
+		}
+		fmt.Printf("Backups in %s, newest first:\n", dir)
+		for _, b := range backups {
+			fmt.Printf("  %s  %s  %d KB\n", filepath.Base(b.Path), b.Time.Format("2006-01-02 15:04"), b.Size/1024)
+		}
+		fmt.Println("\nRestore one with 'dev-cli db restore <name>' or 'dev-cli db restore --latest'.")
+		return nil
+	}
+
+	db, err := storage.InitDB()
+	if err != nil {
+		return fmt.Errorf("open history: %w", err)
+	}
+	defer db.Close()
+
+	undo := storage.BackupPath(dir, time.Now(), "before-restore")
+	if err := storage.Backup(db, undo); err != nil {
+		return fmt.Errorf("back up the current history first: %w", err)
+	}
+	if err := storage.Restore(db, src); err != nil {
+		return fmt.Errorf("restore %s: %w", src, err)
+	}
+	fmt.Printf("Restored the history from %s\n", src)
+	fmt.Printf("The history it replaced is in %s\n", undo)
+	return nil
+}
@@ -11,6 +11,9 @@ import (
 	"strings"
 	"time"
 
+	"dev-cli/internal/config"
+	"dev-cli/internal/infra"
+
 	"github.com/spf13/cobra"
 )
 
@@ -61,7 +64,9 @@ Checks:
   - Ollama availability  
   - GPU/CUDA support
   - Required directories
-  - Network connectivity`,
+  - Network connectivity
+  - systemd units listed in DEV_CLI_SYSTEMD_UNITS (comma-separated,
+    "user:" for user units), restarted by --fix when not active`,
 	Example: `  # Run health checks
   dev-cli doctor
 
@@ -85,7 +90,14 @@ func runDoctor(cmd *cobra.Command, args []string) {
 		checkOllamaModel,
 		checkGPU,
 		checkDevlogsDir,
-		checkNetwork,
+	}
+	cfg := config.Load()
+	for _, spec := range cfg.SystemdUnits {
+		unit := infra.ParseSystemdUnit(spec)
+		checks = append(checks, func() CheckResult { return checkSystemdUnit(unit) })
+	}
+	if !cfg.Offline {
+		checks = append(checks, checkNetwork)
 	}
 
 	var failed, warned, passed int
@@ -444,6 +456,51 @@ func checkDevlogsDir() CheckResult {
 	return result
 }
 
+func checkSystemdUnit(unit infra.SystemdUnit) CheckResult {
+	name := "systemd: " + unit.String()
+	if !infra.SystemdAvailable() {
+		return CheckResult{Name: name, Status: "warn", Message: "systemctl not found (not a systemd host)"}
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	client := infra.NewSystemdClient()
+	status, err := client.Status(ctx, unit)
+	if err != nil {
+		return CheckResult{Name: name, Status: "fail", Message: err.Error()}
+	}
+	if !status.Found() {
+		return CheckResult{Name: name, Status: "fail", Message: "Unit not found"}
+	}
+	if status.Healthy() {
+		msg := fmt.Sprintf("%s (%s)", status.ActiveState, status.SubState)
+		if status.MainPID > 0 {
+			msg += fmt.Sprintf(", pid %d", status.MainPID)
+		}
+		if status.Restarts > 0 {
+			msg += fmt.Sprintf(", restarted %d times", status.Restarts)
+		}
+		return CheckResult{Name: name, Status: "ok", Message: msg}
+	}
+
+	result := CheckResult{
+		Name:    name,
+		Status:  "fail",
+		Message: fmt.Sprintf("%s (%s)", status.ActiveState, status.SubState),
+		FixCmd:  unit.RestartCommand(),
+	}
+	if unit.User {
+		// User units need no sudo; system ones go through FixCmd so sudo
+		// can ask for a password.
+		result.FixFunc = func() error {
+			ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+			defer cancel()
+			return client.Restart(ctx, unit)
+		}
+	}
+	return result
+}
+
 func checkNetwork() CheckResult {
 	result := CheckResult{Name: "Network"}
 
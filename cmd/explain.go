@@ -3,7 +3,10 @@ package cmd
 import (
 	"bufio"
 	"dev-cli/internal/ai"
+	"dev-cli/internal/config"
 	"dev-cli/internal/core"
+	"dev-cli/internal/llm"
+	"dev-cli/internal/storage"
 	"encoding/json"
 	"fmt"
 	"os"
@@ -145,8 +148,7 @@ func analyzeEntry(entry core.LogEntry, interactive bool) {
 	s.Suffix = " 🧠 Analyzing failure..."
 	s.Start()
 
-	client := ai.NewOllamaClient(core.LoadConfig())
-	result, err := client.Explain(entry.Command, entry.ExitCode, entry.Output)
+	result, err := explainAI().Explain(entry.Command, entry.ExitCode, entry.Output)
 	s.Stop()
 
 	if err != nil {
@@ -195,3 +197,54 @@ func analyzeEntry(entry core.LogEntry, interactive bool) {
 		}
 	}
 }
+
+// projectAI returns the AI client primed with the fingerprint of the project
+// in the working directory, so fixes match its toolchain.
+func projectAI() llm.LLMProvider {
+	client := llm.NewHybridClient()
+	project, ok := projectContext()
+	if !ok {
+		return client
+	}
+	return client.WithProject(project)
+}
+
+// explainAI returns the client that explains a failed command. Command
+// output stays on the local model unless the user routed explain to the
+// cloud (DEV_CLI_ROUTE_EXPLAIN=cloud or auto); the hybrid client sanitizes
+// the output before it leaves the machine.
+func explainAI() interface {
+	Explain(cmd string, exitCode int, output string) (*llm.ExplainResult, error)
+} {
+	cfg := config.Load()
+	if cfg.Route(config.FeatureExplain) != config.RouteLocal {
+		return projectAI()
+	}
+	client := llm.NewClient(cfg)
+	if project, ok := projectContext(); ok {
+		return client.WithProject(project)
+	}
+	return client
+}
+
+// projectContext fingerprints the project in the working directory, using
+// the stored fingerprint when there is one.
+func projectContext() (llm.ProjectContext, bool) {
+	cwd, _ := os.Getwd()
+
+	var fp *storage.ProjectFingerprint
+	if db, err := storage.InitDB(); err == nil {
+		fp, _ = storage.LoadProjectFingerprint(db, cwd)
+		db.Close()
+	} else {
+		fp = storage.DetectProjectFingerprint(cwd)
+	}
+	if fp == nil {
+		return llm.ProjectContext{}, false
+	}
+	return llm.ProjectContext{
+		Type:           fp.ProjectType,
+		PackageManager: fp.PackageManager,
+		Files:          fp.DetectedFiles,
+	}, true
+}
@@ -1,8 +1,13 @@
 package cmd
 
 import (
+	"context"
 	"dev-cli/internal/ai"
+	"dev-cli/internal/config"
+	"dev-cli/internal/storage"
 	"fmt"
+	"os"
+	"time"
 
 	"github.com/spf13/cobra"
 )
@@ -13,10 +18,12 @@ var fixCmd = &cobra.Command{
 	Long: `Launch an autonomous AI agent to solve a problem.
 The agent will:
   1. Analyze the issue you describe.
-  2. Propose a command to run.
-  3. Wait for your approval (y/n).
-  4. Execute and analyze the result.
-  5. Repeat until the issue is resolved.`,
+  2. Optionally call a tool, built-in or from a configured MCP server
+     (see mcp tools), to gather information; each call needs approval.
+  3. Propose a command to run.
+  4. Wait for your approval (y/n).
+  5. Execute and analyze the result.
+  6. Repeat until the issue is resolved.`,
 	Example: `  dev-cli fix "my nginx container keeps crashing"
   dev-cli fix "disk is full on /var"
   dev-cli fix "kubectl can't connect to cluster"`,
@@ -24,7 +31,26 @@ The agent will:
 	Run: func(cmd *cobra.Command, args []string) {
 		ag := ai.NewAgent()
 
-		err := ag.Resolve(args[0], func(proposal string) bool {
+		cfg := config.Load()
+		// Without history the agent still gets the other tools.
+		db, err := storage.InitDB()
+		if err == nil {
+			defer db.Close()
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+		reg, clients, errs := federatedRegistry(ctx, cfg, db)
+		cancel()
+		defer func() {
+			for _, c := range clients {
+				c.Close()
+			}
+		}()
+		for _, err := range errs {
+			fmt.Fprintf(os.Stderr, "\033[33m!\033[0m %v\n", err)
+		}
+		ag.SetTools(nil, reg)
+
+		err = ag.Resolve(args[0], func(proposal string) bool {
 			fmt.Printf("> Proposal: %s\n", proposal)
 			fmt.Print("  Allow? [y/N]: ")
 			var resp string
@@ -0,0 +1,108 @@
+package cmd
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+	"time"
+
+	"dev-cli/internal/llm"
+
+	"github.com/briandowns/spinner"
+	"github.com/spf13/cobra"
+)
+
+var (
+	commitMsgPrint bool
+	commitMsgYes   bool
+)
+
+var gitCmd = &cobra.Command{
+	Use:   "git",
+	Short: "AI helpers for git workflows",
+}
+
+var gitCommitMsgCmd = &cobra.Command{
+	Use:   "commit-msg",
+	Short: "Generate a conventional commit message from the staged diff",
+	Long: `Generate a Conventional Commits message from the staged changes.
+
+The staged diff is sanitized (secrets masked, size capped) before it is sent
+to the local model. You can then accept, edit, or reject the suggestion.`,
+	Example: `  git add -p && dev-cli git commit-msg
+  dev-cli git commit-msg --print > msg.txt
+  dev-cli git commit-msg --yes`,
+	RunE: runGitCommitMsg,
+}
+
+func init() {
+	rootCmd.AddCommand(gitCmd)
+	gitCmd.AddCommand(gitCommitMsgCmd)
+	gitCommitMsgCmd.Flags().BoolVar(&commitMsgPrint, "print", false, "Only print the message, do not commit")
+	gitCommitMsgCmd.Flags().BoolVarP(&commitMsgYes, "yes", "y", false, "Commit with the generated message without prompting")
+}
+
+func runGitCommitMsg(cmd *cobra.Command, args []string) error {
+	out, err := exec.Command("git", "diff", "--cached").Output()
+	if err != nil {
+		return fmt.Errorf("read staged diff: %w", err)
+	}
+	diff := string(out)
+	if strings.TrimSpace(diff) == "" {
+		return fmt.Errorf("nothing staged to commit (use git add first)")
+	}
+
+	s := spinner.New(spinner.CharSets[14], 100*time.Millisecond)
+	s.Suffix = " ✍️  Drafting commit message..."
+	s.Writer = os.Stderr
+	s.Start()
+	msg, err := llm.NewHybridClient().CommitMessage(diff)
+	s.Stop()
+	if err != nil {
+		return fmt.Errorf("generate commit message: %w", err)
+	}
+	if msg == "" {
+		return fmt.Errorf("model returned an empty commit message")
+	}
+
+	if commitMsgPrint {
+		fmt.Println(msg)
+		return nil
+	}
+
+	fmt.Println("\033[1mSuggested commit message:\033[0m")
+	fmt.Println()
+	for _, line := range strings.Split(msg, "\n") {
+		fmt.Printf("  %s\n", line)
+	}
+	fmt.Println()
+
+	choice := "a"
+	if !commitMsgYes {
+		fmt.Print("[a]ccept / [e]dit / [r]eject: ")
+		reader := bufio.NewReader(os.Stdin)
+		response, _ := reader.ReadString('\n')
+		choice = strings.TrimSpace(strings.ToLower(response))
+	}
+
+	gitArgs := []string{"commit", "-m", msg}
+	switch choice {
+	case "a", "accept", "y", "yes":
+	case "e", "edit":
+		gitArgs = append(gitArgs, "--edit")
+	default:
+		fmt.Println("Rejected.")
+		return nil
+	}
+
+	c := exec.Command("git", gitArgs...)
+	c.Stdin = os.Stdin
+	c.Stdout = os.Stdout
+	c.Stderr = os.Stderr
+	if err := c.Run(); err != nil {
+		return fmt.Errorf("git commit: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,205 @@
+package cmd
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"dev-cli/internal/llm"
+	"dev-cli/internal/storage"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	historyExportFormat  string
+	historyExportSince   string
+	historyExportFailed  bool
+	historyExportProject bool
+	historyExportOutput  string
+)
+
+var historyCmd = &cobra.Command{
+	Use:   "history",
+	Short: "Work with the recorded command history",
+}
+
+var historyExportCmd = &cobra.Command{
+	Use:   "export",
+	Short: "Export command history as JSON, CSV or JSONL",
+	Long: `Write the command history from the local database in a format other
+tools read, oldest first. Secrets in commands and output are masked and the
+home directory is shortened to ~, so the export can be shared.`,
+	Example: `  dev-cli history export --since 7d > history.json
+  dev-cli history export --format csv --failed -o failures.csv
+  dev-cli history export --project --since 30d
+  dev-cli history export --format jsonl --since 24h`,
+	Args: cobra.NoArgs,
+	RunE: runHistoryExport,
+}
+
+func init() {
+	rootCmd.AddCommand(historyCmd)
+	historyCmd.AddCommand(historyExportCmd)
+	historyExportCmd.Flags().StringVar(&historyExportFormat, "format", "json", "Output format: json, csv or jsonl")
+	historyExportCmd.Flags().StringVar(&historyExportSince, "since", "", "How far back to export (30m, 24h, 7d); all history by default")
+	historyExportCmd.Flags().BoolVar(&historyExportFailed, "failed", false, "Only export failed commands")
+	historyExportCmd.Flags().BoolVar(&historyExportProject, "project", false, "Only export commands run in the current project (its git root and below)")
+	historyExportCmd.Flags().StringVarP(&historyExportOutput, "output", "o", "", "File to write to instead of stdout")
+}
+
+// exportRecord is one exported history entry.
+type exportRecord struct {
+	Timestamp  time.Time `json:"timestamp"`
+	Command    string    `json:"command"`
+	ExitCode   int       `json:"exit_code"`
+	DurationMs int64     `json:"duration_ms"`
+	Directory  string    `json:"directory"`
+	SessionID  string    `json:"session_id"`
+	Output     string    `json:"output,omitempty"`
+	Resolution string    `json:"resolution,omitempty"`
+	Repeats    int       `json:"repeats"`
+}
+
+func runHistoryExport(cmd *cobra.Command, args []string) error {
+	write, ok := historyWriters[historyExportFormat]
+	if !ok {
+		return fmt.Errorf("unknown format %q (want json, csv or jsonl)", historyExportFormat)
+	}
+	since, err := parseSince(historyExportSince)
+	if err != nil {
+		return err
+	}
+
+	db, err := storage.InitDB()
+	if err != nil {
+		return fmt.Errorf("open history: %w", err)
+	}
+	defer db.Close()
+
+	filter := storage.HistoryFilter{Since: since}
+	if historyExportFailed {
+		filter.Status = storage.FailedExit
+	}
+	if historyExportProject {
+		cwd, err := os.Getwd()
+		if err != nil {
+			return fmt.Errorf("find project: %w", err)
+		}
+		filter.Project = storage.ProjectRoot(cwd)
+	}
+	items, err := storage.QueryHistory(db, filter)
+	if err != nil {
+		return fmt.Errorf("read history: %w", err)
+	}
+	slices.Reverse(items)
+
+	home, _ := os.UserHomeDir()
+	records := make([]exportRecord, len(items))
+	for i, item := range items {
+		output, err := storage.GetOutput(db, item)
+		if err != nil {
+			return fmt.Errorf("read output of #%d: %w", item.ID, err)
+		}
+		records[i] = sanitizeRecord(item, output, home)
+	}
+
+	out := io.Writer(os.Stdout)
+	if historyExportOutput != "" {
+		f, err := os.Create(historyExportOutput)
+		if err != nil {
+			return fmt.Errorf("create %s: %w", historyExportOutput, err)
+		}
+		defer f.Close()
+		out = f
+	}
+	if err := write(out, records); err != nil {
+		return fmt.Errorf("write export: %w", err)
+	}
+	if historyExportOutput != "" {
+		fmt.Fprintf(os.Stderr, "Exported %d commands to %s\n", len(records), historyExportOutput)
+	}
+	return nil
+}
+
+// parseSince reads a --since value: a Go duration, or a number of days
+// like 7d. Empty means no limit.
+func parseSince(s string) (time.Duration, error) {
+	if s == "" {
+		return 0, nil
+	}
+	if days, ok := strings.CutSuffix(s, "d"); ok {
+		n, err := strconv.Atoi(days)
+		if err != nil || n <= 0 {
+			return 0, fmt.Errorf("invalid --since %q: want a number of days like 7d", s)
+		}
+		return time.Duration(n) * 24 * time.Hour, nil
+	}
+	d, err := time.ParseDuration(s)
+	if err != nil || d <= 0 {
+		return 0, fmt.Errorf("invalid --since %q: want a duration like 24h or 7d", s)
+	}
+	return d, nil
+}
+
+// sanitizeRecord masks secrets in a history item's command and its output,
+// and replaces the home directory with ~.
+func sanitizeRecord(item storage.HistoryItem, output, home string) exportRecord {
+	dir := item.Directory
+	if home != "" && (dir == home || strings.HasPrefix(dir, home+string(os.PathSeparator))) {
+		dir = "~" + strings.TrimPrefix(dir, home)
+	}
+	return exportRecord{
+		Timestamp:  item.Timestamp,
+		Command:    llm.SanitizeForLLM(item.Command),
+		ExitCode:   item.ExitCode,
+		DurationMs: item.DurationMs,
+		Directory:  dir,
+		SessionID:  item.SessionID,
+		Output:     llm.SanitizeForLLM(output),
+		Resolution: llm.SanitizeForLLM(item.Resolution),
+		Repeats:    item.Repeats,
+	}
+}
+
+var historyWriters = map[string]func(io.Writer, []exportRecord) error{
+	"json": func(w io.Writer, records []exportRecord) error {
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(records)
+	},
+	"jsonl": func(w io.Writer, records []exportRecord) error {
+		enc := json.NewEncoder(w)
+		for _, r := range records {
+			if err := enc.Encode(r); err != nil {
+				return err
+			}
+		}
+		return nil
+	},
+	"csv": func(w io.Writer, records []exportRecord) error {
+		cw := csv.NewWriter(w)
+		cw.Write([]string{"timestamp", "command", "exit_code", "duration_ms", "directory", "session_id", "output", "resolution", "repeats"})
+		for _, r := range records {
+			cw.Write([]string{
+				r.Timestamp.Format(time.RFC3339),
+				r.Command,
+				strconv.Itoa(r.ExitCode),
+				strconv.FormatInt(r.DurationMs, 10),
+				r.Directory,
+				r.SessionID,
+				r.Output,
+				r.Resolution,
+				strconv.Itoa(r.Repeats),
+			})
+		}
+		cw.Flush()
+		return cw.Error()
+	},
+}
@@ -0,0 +1,72 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"dev-cli/internal/hook"
+
+	"github.com/spf13/cobra"
+)
+
+var hookShell string
+
+var hookCmd = &cobra.Command{
+	Use:   "hook [shell]",
+	Short: "Set up automatic history capture in your shell",
+	Long: `Commands run outside the TUI are recorded by a shell hook, which logs
+each command's exit code, duration and directory through 'dev-cli log'.
+
+With a shell argument, print the hook script (like 'dev-cli init').`,
+	ValidArgs: hook.Shells,
+	Args:      cobra.MaximumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		if len(args) == 0 {
+			cmd.Help()
+			return
+		}
+		printHook(cmd, args)
+	},
+}
+
+var hookInstallCmd = &cobra.Command{
+	Use:   "install",
+	Short: "Load the shell hook from your shell's startup file",
+	Example: `  dev-cli hook install
+  dev-cli hook install --shell zsh`,
+	Args: cobra.NoArgs,
+	RunE: runHookInstall,
+}
+
+func init() {
+	rootCmd.AddCommand(hookCmd)
+	hookCmd.AddCommand(hookInstallCmd)
+	hookInstallCmd.Flags().StringVar(&hookShell, "shell", loginShell(), "Shell to install the hook for (zsh)")
+}
+
+func runHookInstall(cmd *cobra.Command, args []string) error {
+	rc, err := hook.RCFile(hookShell)
+	if err != nil {
+		return err
+	}
+	changed, err := hook.Install(hookShell, rc)
+	if err != nil {
+		return fmt.Errorf("install hook: %w", err)
+	}
+	if !changed {
+		fmt.Printf("The hook is already loaded from %s\n", rc)
+		return nil
+	}
+	fmt.Printf("\033[32m✓\033[0m Added the hook to %s\n", rc)
+	fmt.Printf("Open a new shell or run: source %s\n", rc)
+	return nil
+}
+
+// loginShell names the user's shell from $SHELL, zsh when it isn't set.
+func loginShell() string {
+	if shell := os.Getenv("SHELL"); shell != "" {
+		return filepath.Base(shell)
+	}
+	return "zsh"
+}
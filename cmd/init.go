@@ -1,10 +1,15 @@
 package cmd
 
 import (
+	"database/sql"
 	"fmt"
+	"io"
 	"os"
+	"strings"
 	"time"
+	"unicode/utf8"
 
+	"dev-cli/internal/config"
 	"dev-cli/internal/hook"
 	"dev-cli/internal/storage"
 
@@ -14,21 +19,19 @@ import (
 var initCmd = &cobra.Command{
 	Use:       "init [shell]",
 	Short:     "Print shell integration script",
-	Aliases:   []string{"hook"},
 	Hidden:    true,
-	ValidArgs: []string{"zsh"},
+	ValidArgs: hook.Shells,
 	Args:      cobra.ExactArgs(1),
-	Run: func(cmd *cobra.Command, args []string) {
-		shell := args[0]
-		switch shell {
-		case "zsh":
-			os.Stdout.WriteString(hook.ZshHook)
-		default:
-			fmt.Fprintf(os.Stderr, "Unsupported shell: %s\n", shell)
-			fmt.Fprintln(os.Stderr, "Supported shells: zsh")
-			os.Exit(1)
-		}
-	},
+	Run:       printHook,
+}
+
+func printHook(cmd *cobra.Command, args []string) {
+	script, err := hook.Script(args[0])
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	os.Stdout.WriteString(script)
 }
 
 var (
@@ -37,13 +40,20 @@ var (
 	logCwd        string
 	logDurationMs int64
 	logOutput     string
+	logOutputFile string
+	logSession    string
 )
 
 var logEventCmd = &cobra.Command{
-	Use:    "log-event",
-	Short:  "Internal: Log a command execution",
-	Hidden: true,
+	Use:     "log-event",
+	Short:   "Internal: Log a command execution",
+	Long:    "Record a command in the history. Without --command, the command is read from stdin, which is how the shell hook passes it.",
+	Aliases: []string{"log"},
+	Hidden:  true,
 	Run: func(cmd *cobra.Command, args []string) {
+		if logCommand == "" {
+			logCommand = readPipedCommand()
+		}
 		if logCommand == "" {
 			return
 		}
@@ -54,21 +64,80 @@ var logEventCmd = &cobra.Command{
 		}
 		defer db.Close()
 
+		if logOutputFile != "" {
+			if output, err := readOutputFile(logOutputFile); err == nil {
+				logOutput = output
+			}
+		}
+
 		entry := storage.LogEntry{
 			Command:    logCommand,
 			ExitCode:   logExitCode,
 			Cwd:        logCwd,
 			DurationMs: logDurationMs,
 			Output:     logOutput,
+			SessionID:  logSession,
 			Timestamp:  time.Now().UTC().Format(time.RFC3339),
 		}
 
-		if err := storage.SaveCommand(db, entry); err != nil {
+		if err := storage.SaveCommandCollapsing(db, entry, config.Current.DedupWindow); err != nil {
 			fmt.Fprintf(os.Stderr, "log-event failed: %v\n", err)
 		}
+		autoBackup(db)
 	},
 }
 
+// autoBackup takes the daily database backup when DEV_CLI_AUTO_BACKUP is
+// set. The hook logs in the background, so this doesn't hold up the prompt.
+func autoBackup(db *sql.DB) {
+	if !config.Current.AutoBackup {
+		return
+	}
+	dir, err := storage.BackupDir()
+	if err == nil {
+		_, err = storage.AutoBackup(db, dir, config.Current.BackupKeep, time.Now())
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "automatic backup failed: %v\n", err)
+	}
+}
+
+// readPipedCommand reads the command from stdin when it is piped in, so
+// the hook doesn't have to fit it into an argument.
+func readPipedCommand() string {
+	info, err := os.Stdin.Stat()
+	if err != nil || info.Mode()&os.ModeCharDevice != 0 {
+		return ""
+	}
+	data, err := io.ReadAll(os.Stdin)
+	if err != nil {
+		return ""
+	}
+	return strings.TrimRight(string(data), "\n")
+}
+
+// readOutputFile reads the output the hook captured to path, keeping at
+// most the tail storage would keep anyway.
+func readOutputFile(path string) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+	// Read a rune's worth past the cap so TailOutput can cut on a boundary.
+	const readMax = storage.MaxOutputSize + utf8.UTFMax
+	if info, err := f.Stat(); err == nil && info.Size() > readMax {
+		if _, err := f.Seek(-readMax, io.SeekEnd); err != nil {
+			return "", err
+		}
+	}
+	data, err := io.ReadAll(f)
+	if err != nil {
+		return "", err
+	}
+	return storage.TailOutput(string(data), storage.MaxOutputSize), nil
+}
+
 func init() {
 	rootCmd.AddCommand(initCmd)
 
@@ -78,4 +147,6 @@ func init() {
 	logEventCmd.Flags().StringVar(&logCwd, "cwd", "", "Working directory")
 	logEventCmd.Flags().Int64Var(&logDurationMs, "duration-ms", 0, "Duration in milliseconds")
 	logEventCmd.Flags().StringVar(&logOutput, "output", "", "Command stdout/stderr output")
+	logEventCmd.Flags().StringVar(&logOutputFile, "output-file", "", "File holding the command output, read instead of --output")
+	logEventCmd.Flags().StringVar(&logSession, "session", "", "ID of the session the command ran in")
 }
@@ -0,0 +1,134 @@
+package cmd
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"dev-cli/internal/config"
+	"dev-cli/internal/mcp"
+	"dev-cli/internal/storage"
+	"dev-cli/internal/tools"
+
+	"github.com/spf13/cobra"
+)
+
+var mcpCmd = &cobra.Command{
+	Use:   "mcp",
+	Short: "Manage external MCP servers used by the agent",
+}
+
+var mcpToolsCmd = &cobra.Command{
+	Use:   "tools",
+	Short: "List agent tools, including those from configured MCP servers",
+	Long: `Connect to every server in the MCP config (DEV_CLI_MCP_CONFIG, default
+~/.devlogs/mcp.json) and list the tools the agent can call: the built-in ones
+plus each server's tools as <server>__<tool>.
+
+The config uses the common format:
+
+  {"mcpServers": {"github": {"command": "github-mcp-server", "args": ["stdio"]}}}`,
+	Args: cobra.NoArgs,
+	RunE: runMCPTools,
+}
+
+var mcpServeCmd = &cobra.Command{
+	Use:   "serve",
+	Short: "Serve dev-cli tools and failure notifications over MCP (stdio)",
+	Long: `Run an MCP server on stdin/stdout for assistants and agents.
+
+Tools are the built-in agent tools and those of every server in the MCP
+config (as <server>__<tool>), narrowed by DEV_CLI_TOOLS_ALLOW and
+DEV_CLI_TOOLS_READONLY, with every call audited to ~/.devlogs/tool_audit.jsonl.
+Clients that subscribe to the devcli://failures resource are notified of each
+new failed command, with its error signature and any known solutions.`,
+	Example: `  {"mcpServers": {"dev-cli": {"command": "dev-cli", "args": ["mcp", "serve"]}}}`,
+	Args:    cobra.NoArgs,
+	RunE:    runMCPServe,
+}
+
+func init() {
+	rootCmd.AddCommand(mcpCmd)
+	mcpCmd.AddCommand(mcpToolsCmd)
+	mcpCmd.AddCommand(mcpServeCmd)
+}
+
+func runMCPServe(cmd *cobra.Command, args []string) error {
+	cfg := config.Load()
+
+	db, err := storage.InitDB()
+	if err != nil {
+		return fmt.Errorf("open history: %w", err)
+	}
+	defer db.Close()
+
+	// Serving the configured servers' tools too makes dev-cli a hub.
+	ctx, cancel := context.WithTimeout(cmd.Context(), 30*time.Second)
+	reg, clients, errs := federatedRegistry(ctx, cfg, db)
+	cancel()
+	defer func() {
+		for _, c := range clients {
+			c.Close()
+		}
+	}()
+	for _, err := range errs {
+		fmt.Fprintf(os.Stderr, "warning: %v\n", err)
+	}
+
+	srv := mcp.NewServer(reg, db)
+	return srv.Serve(cmd.Context(), os.Stdin, os.Stdout)
+}
+
+// federatedRegistry returns the configured tool registry with the tools of
+// every reachable MCP server added. Close the returned clients when done.
+func federatedRegistry(ctx context.Context, cfg *config.Config, db *sql.DB) (*tools.Registry, []*mcp.Client, []error) {
+	var errs []error
+	reg, err := tools.NewConfiguredRegistry(cfg, db)
+	if err != nil {
+		errs = append(errs, err)
+	}
+	servers, err := mcp.LoadServers(cfg.MCPServersFile)
+	if err != nil {
+		return reg, nil, append(errs, err)
+	}
+	clients, fedErrs := mcp.Federate(ctx, reg, servers)
+	reg.Restrict(tools.Policy{Allow: cfg.ToolAllow, ReadOnly: cfg.ToolsReadOnly})
+	return reg, clients, append(errs, fedErrs...)
+}
+
+func runMCPTools(cmd *cobra.Command, args []string) error {
+	cfg := config.Load()
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	db, err := storage.InitDB()
+	if err != nil {
+		return fmt.Errorf("open history: %w", err)
+	}
+	defer db.Close()
+
+	reg, clients, errs := federatedRegistry(ctx, cfg, db)
+	defer func() {
+		for _, c := range clients {
+			c.Close()
+		}
+	}()
+	for _, err := range errs {
+		fmt.Fprintf(os.Stderr, "\033[33m!\033[0m %v\n", err)
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "TOOL\tSOURCE\tDESCRIPTION")
+	for _, info := range reg.List() {
+		source := "built-in"
+		if server, _, ok := strings.Cut(info.Name, "__"); ok {
+			source = server
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\n", info.Name, source, info.Description)
+	}
+	return w.Flush()
+}
@@ -0,0 +1,94 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"dev-cli/internal/llm"
+	"dev-cli/internal/tools"
+
+	"github.com/briandowns/spinner"
+	"github.com/spf13/cobra"
+)
+
+var reviewCmd = &cobra.Command{
+	Use:   "review [ref]",
+	Short: "AI code review of a git diff",
+	Long: `Send a sanitized git diff to the AI and print structured findings.
+
+Without a ref, staged changes are reviewed; if nothing is staged, all
+uncommitted changes against HEAD are reviewed instead.`,
+	Example: `  dev-cli review
+  dev-cli review main
+  dev-cli review HEAD~3`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: runReview,
+}
+
+func init() {
+	rootCmd.AddCommand(reviewCmd)
+}
+
+func runReview(cmd *cobra.Command, args []string) error {
+	ref := ""
+	if len(args) > 0 {
+		ref = args[0]
+	}
+
+	cwd, _ := os.Getwd()
+	diff, source, err := tools.ReviewDiff(cwd, ref)
+	if err != nil {
+		return fmt.Errorf("read diff: %w", err)
+	}
+	if strings.TrimSpace(diff) == "" {
+		fmt.Println("Nothing to review.")
+		return nil
+	}
+
+	s := spinner.New(spinner.CharSets[14], 100*time.Millisecond)
+	s.Suffix = " 🔎 Reviewing " + source + "..."
+	s.Writer = os.Stderr
+	s.Start()
+	result, err := llm.NewHybridClient().ReviewDiff(diff)
+	s.Stop()
+	if err != nil {
+		return fmt.Errorf("review diff: %w", err)
+	}
+
+	fmt.Printf("\033[1m🔎 Review of %s\033[0m\n", source)
+	if result.Summary != "" {
+		fmt.Printf("   \033[90m%s\033[0m\n", result.Summary)
+	}
+	if result.Truncated {
+		fmt.Printf("   \033[33m⚠ The diff was too long; only its start and end were reviewed. Review a narrower ref for the rest.\033[0m\n")
+	}
+	fmt.Println()
+
+	if len(result.Findings) == 0 {
+		fmt.Println("\033[32m✓\033[0m No findings")
+		return nil
+	}
+
+	for _, f := range result.Findings {
+		loc := f.File
+		if f.Line > 0 {
+			loc = fmt.Sprintf("%s:%d", f.File, f.Line)
+		}
+		fmt.Printf("%s \033[1m%s\033[0m\n", reviewSeverityIcon(f.Severity), loc)
+		fmt.Printf("   %s\n\n", f.Suggestion)
+	}
+	return nil
+}
+
+func reviewSeverityIcon(severity string) string {
+	switch severity {
+	case "error":
+		return "\033[31m✗\033[0m"
+	case "info":
+		return "\033[34mℹ\033[0m"
+	default:
+		return "\033[33m⚠\033[0m"
+	}
+}
@@ -4,9 +4,17 @@ import (
 	"fmt"
 	"os"
 
+	"dev-cli/internal/config"
+	"dev-cli/internal/core"
+
 	"github.com/spf13/cobra"
 )
 
+var (
+	offlineMode   bool
+	dockerContext string
+)
+
 var rootCmd = &cobra.Command{
 	Use:   "dev-cli",
 	Short: "DevOps command logging and analysis tool",
@@ -22,6 +30,20 @@ Quick Start:
   dev-cli ui                       Open the interactive dashboard`,
 }
 
+// applyGlobalFlags turns the root's flags into the env they stand for and
+// reloads both configs from it. It runs through cobra.OnInitialize, so a
+// subcommand's own PersistentPreRun doesn't replace it.
+func applyGlobalFlags() {
+	if offlineMode {
+		os.Setenv("DEV_CLI_OFFLINE", "1")
+		config.Current = config.Load()
+		core.CurrentConfig = core.LoadConfig()
+	}
+	if dockerContext != "" {
+		os.Setenv("DEV_CLI_DOCKER_CONTEXT", dockerContext)
+	}
+}
+
 func Execute() {
 	if err := rootCmd.Execute(); err != nil {
 		fmt.Fprintln(os.Stderr, err)
@@ -30,4 +52,7 @@ func Execute() {
 }
 
 func init() {
+	cobra.OnInitialize(applyGlobalFlags)
+	rootCmd.PersistentFlags().BoolVar(&offlineMode, "offline", false, "Disable all network AI and route everything to Ollama (env: DEV_CLI_OFFLINE)")
+	rootCmd.PersistentFlags().StringVar(&dockerContext, "context", "", "Docker context, \"podman\" or daemon URL to use (env: DEV_CLI_DOCKER_CONTEXT)")
 }
@@ -0,0 +1,75 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"dev-cli/internal/storage"
+
+	"github.com/google/uuid"
+	"github.com/spf13/cobra"
+)
+
+var (
+	sessionSource string
+	sessionCwd    string
+	sessionID     string
+)
+
+var sessionCmd = &cobra.Command{
+	Use:    "session",
+	Short:  "Internal: Mark where a shell session starts and ends",
+	Hidden: true,
+}
+
+var sessionStartCmd = &cobra.Command{
+	Use:   "start",
+	Short: "Record a new session and print its ID",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		db, err := storage.InitDB()
+		if err != nil {
+			return fmt.Errorf("open history: %w", err)
+		}
+		defer db.Close()
+
+		s := storage.Session{
+			ID:        uuid.New().String(),
+			Source:    sessionSource,
+			Directory: sessionCwd,
+			StartedAt: time.Now(),
+		}
+		if err := storage.StartSession(db, s); err != nil {
+			return err
+		}
+		fmt.Println(s.ID)
+		return nil
+	},
+}
+
+var sessionEndCmd = &cobra.Command{
+	Use:   "end",
+	Short: "Record that a session ended",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if sessionID == "" {
+			return nil
+		}
+		db, err := storage.InitDB()
+		if err != nil {
+			return fmt.Errorf("open history: %w", err)
+		}
+		defer db.Close()
+		return storage.EndSession(db, sessionID, time.Now())
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(sessionCmd)
+	sessionCmd.AddCommand(sessionStartCmd, sessionEndCmd)
+	cwd, _ := os.Getwd()
+	sessionStartCmd.Flags().StringVar(&sessionSource, "source", "zsh", "What the session runs in")
+	sessionStartCmd.Flags().StringVar(&sessionCwd, "cwd", cwd, "Directory the session started in")
+	sessionEndCmd.Flags().StringVar(&sessionID, "id", "", "ID printed by 'session start'")
+}
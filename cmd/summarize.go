@@ -0,0 +1,198 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"dev-cli/internal/llm"
+	"dev-cli/internal/storage"
+
+	"github.com/briandowns/spinner"
+	"github.com/spf13/cobra"
+)
+
+var (
+	summarizeSince time.Duration
+	summarizeNoAI  bool
+)
+
+var summarizeCmd = &cobra.Command{
+	Use:   "summarize",
+	Short: "Summarize recent terminal activity",
+	Long: `Pull command history from the local database, group failures and
+durations, and ask the AI for a short narrative summary plus follow-up tasks.
+
+Useful for standups and handoffs.`,
+	Example: `  dev-cli summarize
+  dev-cli summarize --since 8h
+  dev-cli summarize --since 30m --no-ai`,
+	Args: cobra.NoArgs,
+	RunE: runSummarize,
+}
+
+func init() {
+	rootCmd.AddCommand(summarizeCmd)
+	summarizeCmd.Flags().DurationVar(&summarizeSince, "since", 2*time.Hour, "How far back to look (30m, 2h, 24h)")
+	summarizeCmd.Flags().BoolVar(&summarizeNoAI, "no-ai", false, "Only print the statistics, skip the AI narrative")
+}
+
+// failureGroup collects repeated failures of the same command.
+type failureGroup struct {
+	Command  string
+	ExitCode int
+	Count    int
+	Resolved bool
+	Last     time.Time
+}
+
+type sessionStats struct {
+	Total     int
+	Failed    int
+	TotalTime time.Duration
+	Failures  []failureGroup
+	Slowest   []storage.HistoryItem
+}
+
+func runSummarize(cmd *cobra.Command, args []string) error {
+	db, err := storage.InitDB()
+	if err != nil {
+		return fmt.Errorf("open history: %w", err)
+	}
+	defer db.Close()
+
+	items, err := storage.GetHistorySince(db, summarizeSince)
+	if err != nil {
+		return fmt.Errorf("read history: %w", err)
+	}
+	if len(items) == 0 {
+		fmt.Printf("No commands recorded in the last %s.\n", summarizeSince)
+		return nil
+	}
+
+	stats := collectSessionStats(items)
+	printSessionStats(stats)
+
+	if summarizeNoAI {
+		return nil
+	}
+
+	s := spinner.New(spinner.CharSets[14], 100*time.Millisecond)
+	s.Suffix = " 📝 Summarizing session..."
+	s.Writer = os.Stderr
+	s.Start()
+	summary, err := llm.NewHybridClient().SummarizeSession(sessionDigest(items, stats))
+	s.Stop()
+	if err != nil {
+		return fmt.Errorf("summarize session: %w", err)
+	}
+
+	fmt.Println("\033[1m📝 Summary\033[0m")
+	fmt.Printf("   %s\n", summary.Narrative)
+	if len(summary.FollowUps) > 0 {
+		fmt.Println()
+		fmt.Println("\033[1m➜ Follow-ups\033[0m")
+		for _, task := range summary.FollowUps {
+			fmt.Printf("   • %s\n", task)
+		}
+	}
+	return nil
+}
+
+func collectSessionStats(items []storage.HistoryItem) sessionStats {
+	stats := sessionStats{Total: len(items)}
+	groups := make(map[string]*failureGroup)
+
+	for _, item := range items {
+		stats.TotalTime += time.Duration(item.DurationMs) * time.Millisecond
+		// 130 is Ctrl-C; an interrupted command is not a failure worth reporting.
+		if item.ExitCode == 0 || item.ExitCode == 130 {
+			continue
+		}
+		stats.Failed++
+		key := fmt.Sprintf("%s\x00%d", item.Command, item.ExitCode)
+		g, ok := groups[key]
+		if !ok {
+			g = &failureGroup{Command: item.Command, ExitCode: item.ExitCode}
+			groups[key] = g
+		}
+		g.Count++
+		g.Last = item.Timestamp
+		g.Resolved = item.Resolution == "solution"
+	}
+
+	for _, g := range groups {
+		stats.Failures = append(stats.Failures, *g)
+	}
+	sort.Slice(stats.Failures, func(i, j int) bool {
+		if stats.Failures[i].Count != stats.Failures[j].Count {
+			return stats.Failures[i].Count > stats.Failures[j].Count
+		}
+		return stats.Failures[i].Last.After(stats.Failures[j].Last)
+	})
+
+	slowest := make([]storage.HistoryItem, len(items))
+	copy(slowest, items)
+	sort.SliceStable(slowest, func(i, j int) bool { return slowest[i].DurationMs > slowest[j].DurationMs })
+	if len(slowest) > 5 {
+		slowest = slowest[:5]
+	}
+	stats.Slowest = slowest
+	return stats
+}
+
+func printSessionStats(stats sessionStats) {
+	fmt.Printf("\033[1m📊 Last %s\033[0m\n", summarizeSince)
+	fmt.Printf("   %d commands, %d failed, %s total runtime\n\n", stats.Total, stats.Failed, stats.TotalTime.Round(time.Second))
+
+	if len(stats.Failures) > 0 {
+		fmt.Println("\033[1m✗ Failures\033[0m")
+		for _, f := range stats.Failures {
+			status := ""
+			if f.Resolved {
+				status = " \033[32m(resolved)\033[0m"
+			}
+			fmt.Printf("   %3dx  %s \033[90m(exit %d)\033[0m%s\n", f.Count, f.Command, f.ExitCode, status)
+		}
+		fmt.Println()
+	}
+
+	fmt.Println("\033[1m⏱ Slowest\033[0m")
+	for _, item := range stats.Slowest {
+		d := time.Duration(item.DurationMs) * time.Millisecond
+		fmt.Printf("   %8s  %s\n", d.Round(100*time.Millisecond), item.Command)
+	}
+	fmt.Println()
+}
+
+// sessionDigest renders the stats and a chronological command log as plain
+// text for the model. The log is capped so long sessions still fit.
+func sessionDigest(items []storage.HistoryItem, stats sessionStats) string {
+	const maxLogLines = 80
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "Window: last %s. %d commands, %d failed, %s total runtime.\n\n",
+		summarizeSince, stats.Total, stats.Failed, stats.TotalTime.Round(time.Second))
+
+	if len(stats.Failures) > 0 {
+		sb.WriteString("Failures (count, command, exit code, resolved):\n")
+		for _, f := range stats.Failures {
+			fmt.Fprintf(&sb, "- %dx %s (exit %d, resolved=%t)\n", f.Count, f.Command, f.ExitCode, f.Resolved)
+		}
+		sb.WriteString("\n")
+	}
+
+	if len(items) > maxLogLines {
+		items = items[len(items)-maxLogLines:]
+		fmt.Fprintf(&sb, "Command log (last %d):\n", maxLogLines)
+	} else {
+		sb.WriteString("Command log:\n")
+	}
+	for _, item := range items {
+		fmt.Fprintf(&sb, "%s [%s] %s (exit %d, %dms)\n",
+			item.Timestamp.Format("15:04"), item.Directory, item.Command, item.ExitCode, item.DurationMs)
+	}
+	return sb.String()
+}
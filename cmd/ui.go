@@ -1,8 +1,12 @@
 package cmd
 
 import (
+	"bufio"
 	"fmt"
 	"os"
+	"os/exec"
+	"runtime"
+	"strings"
 
 	"dev-cli/internal/tui"
 
@@ -23,14 +27,57 @@ Tabs:
 
 Navigation: Use Tab/Shift+Tab or number keys. Press 'q' to quit.`,
 	Run: func(cmd *cobra.Command, args []string) {
-		p := tea.NewProgram(tui.InitialModel(), tea.WithAltScreen())
-		if _, err := p.Run(); err != nil {
+		model := tui.InitialModel
+		if uiDemo {
+			model = tui.DemoModel
+		}
+		report, err := tui.Run(model(), tea.WithAltScreen(), tea.WithReportFocus())
+		if report != nil {
+			reportCrash(report)
+			os.Exit(2)
+		}
+		if err != nil {
 			fmt.Fprintf(os.Stderr, "Error running dashboard: %v\n", err)
 			os.Exit(1)
 		}
 	},
 }
 
+// reportCrash tells the user where the crash report went and offers to open
+// a pre-filled GitHub issue for it.
+func reportCrash(report *tui.CrashReport) {
+	fmt.Fprintf(os.Stderr, "\n\033[31mdev-cli crashed:\033[0m %s\n", report.Panic)
+	if report.Path != "" {
+		fmt.Fprintf(os.Stderr, "Crash report written to %s\n", report.Path)
+	}
+
+	fmt.Fprint(os.Stderr, "Open a pre-filled GitHub issue? [y/N]: ")
+	reader := bufio.NewReader(os.Stdin)
+	response, _ := reader.ReadString('\n')
+	if strings.TrimSpace(strings.ToLower(response)) != "y" {
+		return
+	}
+
+	issue := report.IssueURL()
+	if err := openBrowser(issue); err != nil {
+		fmt.Fprintf(os.Stderr, "Could not open a browser; file the issue at:\n%s\n", issue)
+	}
+}
+
+func openBrowser(target string) error {
+	switch runtime.GOOS {
+	case "darwin":
+		return exec.Command("open", target).Start()
+	case "windows":
+		return exec.Command("rundll32", "url.dll,FileProtocolHandler", target).Start()
+	default:
+		return exec.Command("xdg-open", target).Start()
+	}
+}
+
+var uiDemo bool
+
 func init() {
 	rootCmd.AddCommand(uiCmd)
+	uiCmd.Flags().BoolVar(&uiDemo, "demo", false, "Populate the UI with synthetic data (no Docker/Ollama needed)")
 }
@@ -10,6 +10,7 @@ import (
 	"strings"
 	"time"
 
+	"dev-cli/internal/config"
 	"dev-cli/internal/llm"
 
 	"github.com/spf13/cobra"
@@ -47,7 +48,7 @@ func init() {
 	rootCmd.AddCommand(watchCmd)
 	watchCmd.Flags().StringVar(&watchDocker, "docker", "", "Docker container ID/name to monitor")
 	watchCmd.Flags().StringVar(&watchFile, "file", "", "Log file path to monitor")
-	watchCmd.Flags().StringVar(&watchAI, "ai", "local", "AI backend to use: 'local' (Ollama) or 'cloud' (Perplexity)")
+	watchCmd.Flags().StringVar(&watchAI, "ai", "", "AI backend to use: 'local' (Ollama), 'cloud' (Perplexity) or 'auto' (default: DEV_CLI_ROUTE_ANALYZE, else local)")
 	watchCmd.Flags().BoolVar(&watchOpenCode, "opencode", false, "Save error context for OpenCode handoff instead of local analysis")
 }
 
@@ -149,8 +150,15 @@ func runWatch(cmd *cobra.Command, args []string) {
 					fmt.Printf("\033[31mError analyzing log: %v\033[0m\n", err)
 				} else {
 					aiSource := "Local"
-					if watchAI == "cloud" {
+					switch watchAI {
+					case "cloud":
 						aiSource = "Cloud"
+					case "auto":
+						aiSource = "Hybrid"
+					case "":
+						if config.Current.Route(config.FeatureAnalyze) != config.RouteLocal && client.HasPerplexity() {
+							aiSource = "Hybrid"
+						}
 					}
 					fmt.Printf("\033[90m> [%s AI]\033[0m \033[1m%s\033[0m\n", aiSource, result.Explanation)
 					if result.Fix != "" {
@@ -12,7 +12,10 @@ import (
 	"text/tabwriter"
 	"time"
 
+	"dev-cli/internal/config"
+	"dev-cli/internal/llm"
 	"dev-cli/internal/pipeline"
+	"dev-cli/internal/plugins/notify"
 	"dev-cli/internal/storage"
 	"dev-cli/internal/workflow"
 
@@ -20,7 +23,12 @@ import (
 )
 
 var (
-	workflowVerbose bool
+	workflowVerbose  bool
+	workflowGenDir   string
+	runbookAllow     []string
+	runbookDeny      []string
+	runbookAllowRisk bool
+	workflowJSON     bool
 )
 
 var workflowCmd = &cobra.Command{
@@ -29,11 +37,29 @@ var workflowCmd = &cobra.Command{
 	Long: `Execute, resume, and manage multi-step workflow automations.
 
 Workflows are defined in YAML files and support:
-  - Sequential step execution
+  - Sequential step execution, each step optionally in its own "dir:",
+    with its own "shell:" and "env:" (on top of the workflow's env)
   - Conditional branching
-  - Automatic retry on failure
+  - Automatic retry on failure: "retries: N" waits 2s between attempts;
+    "retry: {attempts, backoff, max_delay, jitter, on_exit_codes}" doubles
+    the delay each time and only retries the listed exit codes
   - Rollback capabilities
-  - Checkpoint/resume for long operations`,
+  - Checkpoint/resume for long operations
+  - Passing data between steps: "register: name" captures a step's stdout,
+    and later commands and conditions use it as ${{ vars.name }}
+    (${{ env.NAME }} reads the workflow's, step's or process env); in a
+    command the shell gets each value as a quoted env var, so it is never
+    run as shell syntax
+  - Matrix steps: "matrix:" maps keys to value lists, and the step runs
+    once per combination, in parallel (capped by "max_parallel:"), with
+    ${{ matrix.key }} in its command and dir; each combination gets its
+    own result
+  - Secrets: "secrets:" maps env names to keyring:<service>[/<account>] or
+    file:<key> (see 'workflow secrets'); their values are masked in stored
+    output
+  - Notifications when a run ends: "notify:" lists notifiers of type
+    desktop, slack or webhook (with a url, which may use ${{ env.NAME }}),
+    each optionally limited with "on: [success]" or "on: [failure]"`,
 }
 
 var workflowRunCmd = &cobra.Command{
@@ -67,8 +93,7 @@ var workflowRunCmd = &cobra.Command{
 			return fmt.Errorf("failed to initialize workflow schema: %w", err)
 		}
 
-		bus := pipeline.NewEventBus()
-		engine := workflow.NewEngine(store, bus)
+		engine := newWorkflowEngine(store)
 		engine.SetVerbose(workflowVerbose)
 
 		ctx, cancel := context.WithCancel(context.Background())
@@ -128,8 +153,7 @@ var workflowResumeCmd = &cobra.Command{
 		fmt.Printf("▶ Resuming workflow: %s (run: %s)\n", wf.Name, runID)
 		fmt.Printf("  Current step: %d/%d\n\n", state.CurrentStepIdx+1, len(wf.Steps))
 
-		bus := pipeline.NewEventBus()
-		engine := workflow.NewEngine(store, bus)
+		engine := newWorkflowEngine(store)
 		engine.SetVerbose(workflowVerbose)
 
 		ctx, cancel := context.WithCancel(context.Background())
@@ -224,6 +248,15 @@ var workflowStatusCmd = &cobra.Command{
 			return fmt.Errorf("failed to load run: %w", err)
 		}
 
+		if workflowJSON {
+			data, err := workflow.MarshalRunState(state)
+			if err != nil {
+				return err
+			}
+			fmt.Println(string(data))
+			return nil
+		}
+
 		fmt.Printf("Workflow: %s\n", state.WorkflowName)
 		fmt.Printf("Run ID:   %s\n", state.RunID)
 		fmt.Printf("Status:   %s\n", formatStatus(state.Status))
@@ -301,6 +334,124 @@ var workflowRollbackCmd = &cobra.Command{
 	},
 }
 
+var workflowGenerateCmd = &cobra.Command{
+	Use:   "generate <goal>",
+	Short: "Generate a workflow YAML from a natural language goal",
+	Long: `Use the AI to draft a workflow for a goal. The draft is validated
+(every step must have a rollback) and written to ~/.devlogs/workflows for
+review. Nothing is executed.`,
+	Example: `  dev-cli workflow generate "deploy the api container and run migrations"
+  dev-cli workflow generate "rotate nginx logs" --dir ./workflows`,
+	Args: cobra.MinimumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		goal := strings.Join(args, " ")
+
+		dir := workflowGenDir
+		if dir == "" {
+			home, _ := os.UserHomeDir()
+			dir = filepath.Join(home, ".devlogs", "workflows")
+		}
+
+		fmt.Printf("🧠 Drafting workflow for: %s\n", goal)
+
+		var warnings []string
+		var wf *workflow.Workflow
+		draft, err := llm.NewHybridClient().GenerateWorkflow(goal, func(yaml string) error {
+			var verr error
+			wf, warnings, verr = workflow.ValidateGenerated([]byte(yaml))
+			return verr
+		})
+		if err != nil {
+			return fmt.Errorf("failed to generate workflow: %w", err)
+		}
+
+		path, err := workflow.SaveGenerated(dir, wf, []byte(draft))
+		if err != nil {
+			return err
+		}
+
+		fmt.Printf("\n%s\n", strings.TrimSpace(draft))
+		for _, w := range warnings {
+			fmt.Printf("\n⚠ %s", w)
+		}
+		fmt.Printf("\n\n✓ Saved %d steps to %s\n", len(wf.Steps), path)
+		fmt.Printf("  Review it, then run: dev-cli workflow run %s\n", path)
+		return nil
+	},
+}
+
+var workflowRunbookCmd = &cobra.Command{
+	Use:   "runbook <runbook-id>",
+	Short: "Execute a stored runbook under a command policy",
+	Long: `Run a learned runbook step-by-step through the workflow engine.
+
+Every command is checked against the policy first and the runbook is refused
+as a whole if any step is not permitted. The policy comes from
+DEV_CLI_RUNBOOK_ALLOW (comma-separated command prefixes) and
+DEV_CLI_RUNBOOK_DENY (comma-separated substrings), extended by the flags.
+Destructive commands are refused unless --allow-destructive is set.`,
+	Example: `  dev-cli workflow runbook a1b2c3d4 --allow "npm ,docker compose "
+  dev-cli workflow runbook a1b2c3d4 --deny "sudo"`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		db, err := storage.InitDB()
+		if err != nil {
+			return fmt.Errorf("failed to initialize database: %w", err)
+		}
+		defer db.Close()
+
+		rb, err := storage.GetRunbookByID(db, args[0])
+		if err != nil {
+			return fmt.Errorf("failed to load runbook %q: %w", args[0], err)
+		}
+
+		store := workflow.NewCheckpointStore(db)
+		if err := store.InitSchema(); err != nil {
+			return fmt.Errorf("failed to initialize workflow schema: %w", err)
+		}
+
+		cfg := config.Load()
+		policy := workflow.CommandPolicy{
+			Allow:            append(cfg.RunbookAllow, runbookAllow...),
+			Deny:             append(cfg.RunbookDeny, runbookDeny...),
+			AllowDestructive: runbookAllowRisk,
+		}
+
+		fmt.Printf("📖 Running runbook: %s (%d steps)\n\n", rb.Name, len(rb.Steps))
+
+		engine := workflow.NewEngine(store, pipeline.NewEventBus())
+		engine.SetVerbose(workflowVerbose)
+		result, err := engine.RunRunbook(context.Background(), rb, policy)
+		if result == nil {
+			return err
+		}
+		storage.UpdateRunbookStats(db, rb.ID, result.Status == workflow.StatusCompleted)
+
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "  STEP\tSTATUS\tEXIT\tDURATION")
+		for _, step := range rb.Steps {
+			if r := result.StepResults[step.ID]; r != nil {
+				fmt.Fprintf(w, "  %s\t%s\t%d\t%s\n", step.ID, formatStepStatus(r.Status), r.ExitCode, r.Duration.Truncate(time.Millisecond))
+			}
+		}
+		w.Flush()
+		fmt.Println()
+		printRunResult(result)
+		return nil
+	},
+}
+
+// newWorkflowEngine is the engine for runs started from the CLI, with the
+// notify plugin reporting finished runs to their workflow's notifiers.
+func newWorkflowEngine(store *workflow.CheckpointStore) *workflow.Engine {
+	bus := pipeline.NewEventBus()
+	engine := workflow.NewEngine(store, bus)
+	notifier := notify.New()
+	notifier.SetURLResolver(engine.NotifierURL)
+	notifier.Init(bus, nil)
+	return engine
+}
+
 func init() {
 	rootCmd.AddCommand(workflowCmd)
 
@@ -311,6 +462,14 @@ func init() {
 	workflowCmd.AddCommand(workflowListCmd)
 	workflowCmd.AddCommand(workflowStatusCmd)
 	workflowCmd.AddCommand(workflowRollbackCmd)
+	workflowCmd.AddCommand(workflowGenerateCmd)
+	workflowCmd.AddCommand(workflowRunbookCmd)
+
+	workflowGenerateCmd.Flags().StringVar(&workflowGenDir, "dir", "", "Directory to write the workflow to (default ~/.devlogs/workflows)")
+	workflowStatusCmd.Flags().BoolVar(&workflowJSON, "json", false, "Print the run state as JSON for polling by other tools")
+	workflowRunbookCmd.Flags().StringSliceVar(&runbookAllow, "allow", nil, "Command prefixes the runbook may run")
+	workflowRunbookCmd.Flags().StringSliceVar(&runbookDeny, "deny", nil, "Substrings that are always refused")
+	workflowRunbookCmd.Flags().BoolVar(&runbookAllowRisk, "allow-destructive", false, "Permit commands matching the destructive patterns")
 }
 
 func printRunResult(result *workflow.RunResult) {
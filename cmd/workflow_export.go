@@ -0,0 +1,58 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"dev-cli/internal/workflow"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	workflowExportFormat string
+	workflowExportOut    string
+)
+
+var workflowExportCmd = &cobra.Command{
+	Use:   "export <file.yaml>",
+	Short: "Convert a workflow to a GitHub Actions job or a Makefile",
+	Long: `Convert a workflow into a starting point for CI: a GitHub Actions workflow
+with one job run by hand (--format gha), or a Makefile with a target per step
+(--format make). Steps keep their dir, env, shell, file and env conditions,
+retries and registered vars; matrix steps become a step or target per
+combination. What doesn't translate, like notifications or output conditions,
+is printed as a warning and left as a TODO comment at the top of the file.`,
+	Example: `  dev-cli workflow export deploy.yaml --format gha -o .github/workflows/deploy.yml
+  dev-cli workflow export build.yaml --format make > Makefile`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		wf, err := workflow.ParseFile(args[0])
+		if err != nil {
+			return fmt.Errorf("failed to parse workflow: %w", err)
+		}
+		data, notes, err := workflow.Export(wf, workflowExportFormat)
+		if err != nil {
+			return err
+		}
+		for _, n := range notes {
+			fmt.Fprintf(os.Stderr, "⚠ %s\n", n)
+		}
+
+		if workflowExportOut == "" {
+			_, err := os.Stdout.Write(data)
+			return err
+		}
+		if err := os.WriteFile(workflowExportOut, data, 0644); err != nil {
+			return fmt.Errorf("failed to write export: %w", err)
+		}
+		fmt.Printf("✓ Exported %q to %s\n", wf.Name, workflowExportOut)
+		return nil
+	},
+}
+
+func init() {
+	workflowCmd.AddCommand(workflowExportCmd)
+	workflowExportCmd.Flags().StringVar(&workflowExportFormat, "format", "gha", "Format to export to: gha or make")
+	workflowExportCmd.Flags().StringVarP(&workflowExportOut, "output", "o", "", "File to write to (default stdout)")
+}
@@ -0,0 +1,204 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strconv"
+	"syscall"
+	"text/tabwriter"
+	"time"
+
+	"dev-cli/internal/storage"
+	"dev-cli/internal/workflow"
+
+	"github.com/spf13/cobra"
+)
+
+var workflowScheduleCmd = &cobra.Command{
+	Use:   "schedule <cron> <file.yaml>",
+	Short: "Run a workflow on a cron schedule",
+	Long: `Store a schedule that runs a workflow file at the times a five-field cron
+expression (minute hour day month weekday) or a macro like @daily matches.
+Schedules run while 'dev-cli workflow daemon' is running, and their runs are
+recorded like 'workflow run' ones, so 'workflow list' and 'workflow status'
+show them.`,
+	Example: `  dev-cli workflow schedule "0 3 * * *" ~/.devlogs/workflows/cleanup.yaml
+  dev-cli workflow schedule "*/15 9-17 * * 1-5" healthcheck.yaml
+  dev-cli workflow schedule @weekly backup.yaml`,
+	Args: cobra.ExactArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cron, err := workflow.ParseCron(args[0])
+		if err != nil {
+			return err
+		}
+		path, err := filepath.Abs(args[1])
+		if err != nil {
+			return err
+		}
+		wf, err := workflow.ParseFile(path)
+		if err != nil {
+			return fmt.Errorf("failed to parse workflow: %w", err)
+		}
+
+		store, closeDB, err := openWorkflowStore()
+		if err != nil {
+			return err
+		}
+		defer closeDB()
+
+		now := time.Now()
+		id, err := store.AddSchedule(cron.String(), path, now)
+		if err != nil {
+			return fmt.Errorf("failed to save schedule: %w", err)
+		}
+		fmt.Printf("✓ Scheduled %q as #%d (%s), next run %s\n", wf.Name, id, cron, formatNextRun(cron.Next(now)))
+		fmt.Println("  Schedules run while 'dev-cli workflow daemon' is running.")
+		return nil
+	},
+}
+
+var workflowSchedulesCmd = &cobra.Command{
+	Use:   "schedules",
+	Short: "List scheduled workflows",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		store, closeDB, err := openWorkflowStore()
+		if err != nil {
+			return err
+		}
+		defer closeDB()
+
+		schedules, err := store.ListSchedules()
+		if err != nil {
+			return fmt.Errorf("failed to list schedules: %w", err)
+		}
+		if len(schedules) == 0 {
+			fmt.Println("No scheduled workflows. Add one with: dev-cli workflow schedule \"<cron>\" <file.yaml>")
+			return nil
+		}
+
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "ID\tCRON\tWORKFLOW\tNEXT RUN\tLAST RUN")
+		fmt.Fprintln(w, "--\t----\t--------\t--------\t--------")
+		for _, sw := range schedules {
+			next, _ := sw.Next()
+			last := "-"
+			if !sw.LastRun.IsZero() {
+				last = sw.LastRun.Format("2006-01-02 15:04")
+				if sw.LastRunID != "" {
+					last += " (" + sw.LastRunID + ")"
+				}
+			}
+			fmt.Fprintf(w, "%d\t%s\t%s\t%s\t%s\n", sw.ID, sw.Cron, sw.Path, formatNextRun(next), last)
+		}
+		return w.Flush()
+	},
+}
+
+var workflowUnscheduleCmd = &cobra.Command{
+	Use:   "unschedule <id>",
+	Short: "Remove a workflow schedule",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		id, err := strconv.ParseInt(args[0], 10, 64)
+		if err != nil {
+			return fmt.Errorf("invalid schedule ID %q", args[0])
+		}
+		store, closeDB, err := openWorkflowStore()
+		if err != nil {
+			return err
+		}
+		defer closeDB()
+
+		if err := store.RemoveSchedule(id); err != nil {
+			return err
+		}
+		fmt.Printf("✓ Removed schedule #%d\n", id)
+		return nil
+	},
+}
+
+var workflowDaemonCmd = &cobra.Command{
+	Use:   "daemon",
+	Short: "Run scheduled workflows in the foreground",
+	Long: `Check the workflow schedules every minute and run the ones that are due,
+logging each run's start and end. Schedules added or removed meanwhile are
+picked up. A schedule that came due while no daemon was running runs once
+when it starts. Ctrl+C (or SIGTERM) stops it; runs in progress are paused,
+to be continued with 'workflow resume'.
+
+Run it under your service manager to keep it going, for example as a
+systemd user service with ExecStart=dev-cli workflow daemon.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		store, closeDB, err := openWorkflowStore()
+		if err != nil {
+			return err
+		}
+		defer closeDB()
+
+		engine := newWorkflowEngine(store)
+		engine.SetVerbose(workflowVerbose)
+		scheduler := workflow.NewScheduler(store, engine, logScheduleEvent)
+
+		ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+		defer stop()
+
+		schedules, err := store.ListSchedules()
+		if err != nil {
+			return fmt.Errorf("failed to list schedules: %w", err)
+		}
+		fmt.Printf("⏱ Workflow daemon started with %d schedules\n", len(schedules))
+		if err := scheduler.Run(ctx); err != nil {
+			return err
+		}
+		fmt.Println("⏹ Workflow daemon stopped")
+		return nil
+	},
+}
+
+func init() {
+	workflowCmd.AddCommand(workflowScheduleCmd)
+	workflowCmd.AddCommand(workflowSchedulesCmd)
+	workflowCmd.AddCommand(workflowUnscheduleCmd)
+	workflowCmd.AddCommand(workflowDaemonCmd)
+}
+
+// openWorkflowStore opens the history database with the workflow tables.
+func openWorkflowStore() (*workflow.CheckpointStore, func() error, error) {
+	db, err := storage.InitDB()
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to initialize database: %w", err)
+	}
+	store := workflow.NewCheckpointStore(db)
+	if err := store.InitSchema(); err != nil {
+		db.Close()
+		return nil, nil, fmt.Errorf("failed to initialize workflow schema: %w", err)
+	}
+	return store, db.Close, nil
+}
+
+func formatNextRun(t time.Time) string {
+	if t.IsZero() {
+		return "never"
+	}
+	return t.Format("2006-01-02 15:04")
+}
+
+// logScheduleEvent prints what the daemon's scheduler did.
+func logScheduleEvent(ev workflow.ScheduleEvent) {
+	prefix := fmt.Sprintf("%s #%d %s:", time.Now().Format("2006-01-02 15:04:05"), ev.Schedule.ID, filepath.Base(ev.Schedule.Path))
+	switch {
+	case ev.RunID == "":
+		fmt.Printf("%s ✗ could not start: %v\n", prefix, ev.Err)
+	case ev.Result == nil && ev.Err != nil:
+		fmt.Printf("%s ▶ started %s (schedule not updated: %v)\n", prefix, ev.RunID, ev.Err)
+	case ev.Result == nil:
+		fmt.Printf("%s ▶ started %s\n", prefix, ev.RunID)
+	default:
+		fmt.Printf("%s %s in %s\n", prefix, formatStatus(ev.Result.Status), ev.Result.Duration.Truncate(time.Second))
+	}
+}
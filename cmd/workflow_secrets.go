@@ -0,0 +1,162 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"strings"
+
+	"dev-cli/internal/config"
+	"dev-cli/internal/secrets"
+
+	"github.com/spf13/cobra"
+	"golang.org/x/term"
+)
+
+var workflowSecretsCmd = &cobra.Command{
+	Use:   "secrets",
+	Short: "Manage the encrypted secrets file workflows read from",
+	Long: `Manage the encrypted file that workflow secrets declared as "file:<key>"
+are read from (DEV_CLI_SECRETS_FILE, by default ~/.devlogs/secrets.enc). It is
+encrypted with a passphrase taken from DEV_CLI_SECRETS_PASSPHRASE, or asked
+for when unset; workflow runs need the variable set.
+
+A workflow declares its secrets by env name:
+
+  secrets:
+    DB_PASSWORD: file:db_password
+    GITHUB_TOKEN: keyring:github/me   # OS keyring service/account
+
+Each step gets them in its env, and their values are masked as *** in
+stored step output.`,
+}
+
+var workflowSecretsSetCmd = &cobra.Command{
+	Use:   "set <key>",
+	Short: "Store a secret, read from stdin or asked for",
+	Example: `  dev-cli workflow secrets set db_password
+  echo -n "$TOKEN" | dev-cli workflow secrets set api_token`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		key := args[0]
+		if key == "" || strings.ContainsAny(key, " \t\n") {
+			return fmt.Errorf("invalid secret key %q", key)
+		}
+		passphrase, err := secretsPassphrase()
+		if err != nil {
+			return err
+		}
+		values, err := secrets.LoadFile(config.Current.SecretsFile, passphrase)
+		if err != nil {
+			return err
+		}
+
+		var value string
+		if term.IsTerminal(int(os.Stdin.Fd())) {
+			if value, err = readHidden(fmt.Sprintf("Value for %s: ", key)); err != nil {
+				return err
+			}
+		} else {
+			data, err := io.ReadAll(os.Stdin)
+			if err != nil {
+				return fmt.Errorf("failed to read the value from stdin: %w", err)
+			}
+			value = strings.TrimSuffix(string(data), "\n")
+		}
+		if value == "" {
+			return fmt.Errorf("empty value for %s", key)
+		}
+
+		values[key] = value
+		if err := secrets.SaveFile(config.Current.SecretsFile, passphrase, values); err != nil {
+			return fmt.Errorf("failed to save secrets file: %w", err)
+		}
+		fmt.Printf("✓ Stored %s in %s\n", key, config.Current.SecretsFile)
+		return nil
+	},
+}
+
+var workflowSecretsListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List the keys in the secrets file",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		passphrase, err := secretsPassphrase()
+		if err != nil {
+			return err
+		}
+		values, err := secrets.LoadFile(config.Current.SecretsFile, passphrase)
+		if err != nil {
+			return err
+		}
+		if len(values) == 0 {
+			fmt.Println("No secrets stored. Add one with 'dev-cli workflow secrets set <key>'.")
+			return nil
+		}
+		keys := make([]string, 0, len(values))
+		for key := range values {
+			keys = append(keys, key)
+		}
+		slices.Sort(keys)
+		for _, key := range keys {
+			fmt.Println(key)
+		}
+		return nil
+	},
+}
+
+var workflowSecretsRmCmd = &cobra.Command{
+	Use:   "rm <key>",
+	Short: "Remove a secret from the secrets file",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		passphrase, err := secretsPassphrase()
+		if err != nil {
+			return err
+		}
+		values, err := secrets.LoadFile(config.Current.SecretsFile, passphrase)
+		if err != nil {
+			return err
+		}
+		if _, ok := values[args[0]]; !ok {
+			return fmt.Errorf("secret not found: %s", args[0])
+		}
+		delete(values, args[0])
+		if err := secrets.SaveFile(config.Current.SecretsFile, passphrase, values); err != nil {
+			return fmt.Errorf("failed to save secrets file: %w", err)
+		}
+		fmt.Printf("✓ Removed %s\n", args[0])
+		return nil
+	},
+}
+
+func init() {
+	workflowCmd.AddCommand(workflowSecretsCmd)
+	workflowSecretsCmd.AddCommand(workflowSecretsSetCmd)
+	workflowSecretsCmd.AddCommand(workflowSecretsListCmd)
+	workflowSecretsCmd.AddCommand(workflowSecretsRmCmd)
+}
+
+// secretsPassphrase is DEV_CLI_SECRETS_PASSPHRASE, or else asked for on
+// the terminal.
+func secretsPassphrase() (string, error) {
+	if config.Current.SecretsPassphrase != "" {
+		return config.Current.SecretsPassphrase, nil
+	}
+	if !term.IsTerminal(int(os.Stdin.Fd())) {
+		return "", fmt.Errorf("set DEV_CLI_SECRETS_PASSPHRASE to unlock the secrets file")
+	}
+	return readHidden("Secrets passphrase: ")
+}
+
+// readHidden asks for a line on the terminal without echoing it.
+func readHidden(prompt string) (string, error) {
+	fmt.Fprint(os.Stderr, prompt)
+	data, err := term.ReadPassword(int(os.Stdin.Fd()))
+	fmt.Fprintln(os.Stderr)
+	if err != nil {
+		return "", fmt.Errorf("failed to read input: %w", err)
+	}
+	return string(data), nil
+}
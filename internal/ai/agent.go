@@ -2,6 +2,8 @@ package ai
 
 import (
 	"bytes"
+	"context"
+	"encoding/json"
 	"fmt"
 	"io"
 	"os"
@@ -9,6 +11,8 @@ import (
 	"strings"
 	"time"
 
+	"dev-cli/internal/tools"
+
 	"github.com/briandowns/spinner"
 )
 
@@ -22,9 +26,26 @@ type Executor interface {
 	Execute(command string) (success bool, errOutput string)
 }
 
+// ToolPlanner picks one of the tools described by toolSchemas for prompt.
+type ToolPlanner interface {
+	GenerateWithTools(prompt string, toolSchemas string) (*ToolCallResult, error)
+}
+
+// ToolRunner is the registry of tools the agent may call, built-in and
+// federated from MCP servers.
+type ToolRunner interface {
+	GetSchemasJSON() (string, error)
+	Call(ctx context.Context, name string, params map[string]any) tools.ToolResult
+}
+
+// noTool is the tool name the planner answers with when no tool helps.
+const noTool = "none"
+
 type Agent struct {
 	solver   Solver
 	executor Executor
+	planner  ToolPlanner
+	tools    ToolRunner
 }
 
 func NewAgent() *Agent {
@@ -41,11 +62,31 @@ func NewAgentWithDeps(solver Solver, executor Executor) *Agent {
 	}
 }
 
+// SetTools lets the agent call a tool from reg, chosen by planner, before
+// each proposal; what the tool returns is added to the prompt. A nil
+// planner uses the agent's solver when it can plan.
+func (a *Agent) SetTools(planner ToolPlanner, reg ToolRunner) {
+	if planner == nil {
+		planner, _ = a.solver.(ToolPlanner)
+	}
+	a.planner, a.tools = planner, reg
+}
+
 func (a *Agent) Resolve(issue string, approval func(string) bool) error {
 	context := issue
 	var lastError string
 
 	for attempt := 1; attempt <= maxRetries; attempt++ {
+		prompt := context
+		if lastError != "" {
+			prompt = fmt.Sprintf("Previous command failed with:\n%s\n\nOriginal task: %s\n\nPlease provide a corrected command.", lastError, issue)
+		}
+		// The tool is asked about first, so its approval prompt isn't
+		// drawn over by the spinner.
+		if found := a.useTool(prompt, approval); found != "" {
+			prompt += "\n\n" + found
+		}
+
 		s := spinner.New(spinner.CharSets[14], 100*time.Millisecond)
 		if attempt == 1 {
 			s.Suffix = " > Analyzing..."
@@ -54,11 +95,6 @@ func (a *Agent) Resolve(issue string, approval func(string) bool) error {
 		}
 		s.Start()
 
-		prompt := context
-		if lastError != "" {
-			prompt = fmt.Sprintf("Previous command failed with:\n%s\n\nOriginal task: %s\n\nPlease provide a corrected command.", lastError, issue)
-		}
-
 		proposal, err := a.solver.Solve(prompt)
 		s.Stop()
 
@@ -92,6 +128,34 @@ func (a *Agent) Resolve(issue string, approval func(string) bool) error {
 	return fmt.Errorf("max retries exceeded")
 }
 
+// useTool asks the planner whether a tool would help with prompt and, once
+// approved, calls it, returning what it found for the prompt. It returns ""
+// when there are no tools, none is picked or the call is declined.
+func (a *Agent) useTool(prompt string, approval func(string) bool) string {
+	if a.planner == nil || a.tools == nil {
+		return ""
+	}
+	schemas, err := a.tools.GetSchemasJSON()
+	if err != nil {
+		return ""
+	}
+	call, err := a.planner.GenerateWithTools(prompt+"\n\nPick a tool only if it gathers information needed for this task; otherwise answer with tool_name \""+noTool+"\".", schemas)
+	if err != nil || call == nil || call.ToolName == "" || call.ToolName == noTool {
+		return ""
+	}
+
+	params, _ := json.Marshal(call.Parameters)
+	if !approval(fmt.Sprintf("tool %s %s", call.ToolName, params)) {
+		return ""
+	}
+	result := a.tools.Call(context.Background(), call.ToolName, call.Parameters)
+	if !result.Success {
+		return fmt.Sprintf("Tool %s failed: %s", call.ToolName, truncateAgent(result.Error, 500))
+	}
+	data, _ := json.Marshal(result.Data)
+	return fmt.Sprintf("Tool %s returned:\n%s", call.ToolName, truncateAgent(string(data), 2000))
+}
+
 type shellExecutor struct{}
 
 func (e *shellExecutor) Execute(command string) (bool, string) {
@@ -16,6 +16,7 @@ import (
 	"time"
 
 	"dev-cli/internal/core"
+	"dev-cli/internal/llm"
 )
 
 const (
@@ -163,6 +164,7 @@ RULES:
    - Good fix: "npm init -y new line and more command if needed to run in sequence"
    - Bad fix: "Make sure package.json exists"
    - If no fix possible, refer to sources more authentic to that problem to precise documentation etc ""
+3. %s
 
 EXAMPLES:
 - package.json missing → {"explanation": "Missing package.json", "fix": "npm init -y"}
@@ -171,9 +173,10 @@ EXAMPLES:
 
 Command: %s
 Exit Code: %d
-Output: %s
+Output:
+%s
 
-JSON response:`, cmd, exitCode, output)
+JSON response:`, llm.UntrustedNotice, cmd, exitCode, llm.PrepareUntrusted("OUTPUT", output, 0))
 
 	return c.generateExplain(prompt)
 }
@@ -292,6 +295,7 @@ OUTPUT JSON ONLY:
 
 func (c *OllamaClient) AnalyzeLog(logLines string) (*LogAnalysisResult, error) {
 	prompt := fmt.Sprintf(`You are a Log Analyzer. Identify the error in these log lines.
+%s
 
 OUTPUT JSON ONLY:
 {
@@ -300,7 +304,7 @@ OUTPUT JSON ONLY:
 }
 
 LOGS:
-%s`, logLines)
+%s`, llm.UntrustedNotice, llm.PrepareUntrusted("LOGS", logLines, 8000))
 
 	req := generateRequest{
 		Model:  c.model,
@@ -486,7 +490,7 @@ type PerplexityClient struct {
 }
 
 func NewPerplexityClient(cfg *core.Config) *PerplexityClient {
-	if cfg.PerplexityKey == "" {
+	if cfg.PerplexityKey == "" || cfg.Offline {
 		return nil
 	}
 
@@ -571,6 +575,7 @@ OUTPUT JSON ONLY (No markdown, no code fences):
 
 func (c *PerplexityClient) AnalyzeLog(ctx context.Context, logLines string) (*LogAnalysisResult, error) {
 	prompt := fmt.Sprintf(`You are a Log Analyzer. Identify the error in these log lines.
+%s
 
 OUTPUT JSON ONLY (No markdown):
 {
@@ -579,7 +584,7 @@ OUTPUT JSON ONLY (No markdown):
 }
 
 LOGS:
-%s`, logLines)
+%s`, llm.UntrustedNotice, llm.PrepareUntrusted("LOGS", logLines, 8000))
 
 	reqBody, err := json.Marshal(perplexityRequest{
 		Model: c.model,
@@ -741,6 +746,7 @@ type HybridClient struct {
 	perplexity *PerplexityClient
 	ollama     *OllamaClient
 	cache      *ResponseCache
+	offline    bool
 }
 
 var defaultCache = NewResponseCache(50, 10*time.Minute)
@@ -751,6 +757,7 @@ func NewHybridClient() *HybridClient {
 		perplexity: NewPerplexityClient(cfg),
 		ollama:     NewOllamaClient(cfg),
 		cache:      defaultCache,
+		offline:    cfg.Offline,
 	}
 }
 
@@ -781,6 +788,10 @@ func (h *HybridClient) HasPerplexity() bool {
 	return h.perplexity != nil
 }
 
+func (h *HybridClient) IsOffline() bool {
+	return h.offline
+}
+
 func (h *HybridClient) CacheStats() (size int, capacity int) {
 	return h.cache.Stats()
 }
@@ -795,6 +806,9 @@ func (h *HybridClient) AnalyzeLog(logLines string, aiMode string) (*LogAnalysisR
 	}
 
 	if aiMode == "cloud" {
+		if h.offline {
+			return nil, fmt.Errorf("cloud AI requested but offline mode is enabled")
+		}
 		if h.perplexity != nil {
 			return h.perplexity.AnalyzeLog(context.Background(), logLines)
 		}
@@ -808,8 +822,14 @@ func (h *HybridClient) Solve(goal string) (string, error) {
 	return h.ollama.Solve(goal)
 }
 
+// GenerateWithTools picks a tool for prompt on the local model; tool
+// results may hold local data, so they don't go to the cloud.
+func (h *HybridClient) GenerateWithTools(prompt string, toolSchemas string) (*ToolCallResult, error) {
+	return h.ollama.GenerateWithTools(prompt, toolSchemas)
+}
+
 func needsWebSearch(query string) bool {
-	if os.Getenv("DEV_CLI_FORCE_LOCAL") != "" {
+	if os.Getenv("DEV_CLI_FORCE_LOCAL") != "" || os.Getenv("DEV_CLI_OFFLINE") != "" {
 		return false
 	}
 
@@ -3,15 +3,100 @@ package config
 import (
 	"os"
 	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
 )
 
+// Route says which backend handles an AI feature.
+type Route string
+
+const (
+	RouteLocal Route = "local"
+	RouteCloud Route = "cloud"
+	// RouteAuto goes to the cloud only when the request looks like it needs
+	// fresh web results, and stays local otherwise.
+	RouteAuto Route = "auto"
+)
+
+// AI features that can be routed between Ollama and Perplexity. Reviews
+// send diffs, so they default to local. Commit messages and session
+// summaries always stay local and are not listed here.
+const (
+	FeatureResearch = "research"
+	FeatureAnalyze  = "analyze"
+	FeatureExplain  = "explain"
+	FeatureSolve    = "solve"
+	FeatureReview   = "review"
+)
+
+func defaultRoutes() map[string]Route {
+	return map[string]Route{
+		FeatureResearch: RouteAuto,
+		FeatureAnalyze:  RouteLocal,
+		FeatureExplain:  RouteLocal,
+		FeatureSolve:    RouteLocal,
+		FeatureReview:   RouteLocal,
+	}
+}
+
 type Config struct {
-	OllamaURL       string
-	OllamaModel     string
+	OllamaURL   string
+	OllamaModel string
+	// OllamaFallbackModel answers when OllamaModel fails or overflows its
+	// context; empty means llm.FallbackModel.
+	OllamaFallbackModel string
+	// EmbedModel embeds project docs for retrieval; empty means
+	// llm.DefaultEmbedModel.
+	EmbedModel      string
 	PerplexityKey   string
 	PerplexityModel string
 	ForceLocalLLM   bool
+	Offline         bool
 	LogDir          string
+	// RunbookAllow and RunbookDeny form the policy for unattended runbook
+	// and workflow execution (workflow runbook and the execute_runbook and
+	// run_workflow tools, which are only offered when RunbookAllow is set):
+	// allowed command prefixes and always-refused substrings.
+	RunbookAllow []string
+	RunbookDeny  []string
+	// ToolAllow limits the agent tools the agent and `mcp serve` expose
+	// (empty means all); ToolsReadOnly additionally drops tools that write
+	// or execute.
+	ToolAllow     []string
+	ToolsReadOnly bool
+	// MCPServersFile lists external MCP servers whose tools are federated
+	// into the agent ({"mcpServers": {...}}); defaults to LogDir/mcp.json.
+	MCPServersFile string
+	// SystemdUnits are host units (databases, daemons outside Docker)
+	// that doctor checks and can restart; "user:" marks a user unit.
+	SystemdUnits []string
+	// Theme names the UI palette (see theme.Names); empty means catppuccin.
+	Theme string
+	// Tabs names the UI tabs to show, in order; empty means all of them.
+	Tabs []string
+	// ExpertMode skips the y/n question before removing, killing or
+	// pruning in the Containers tab.
+	ExpertMode bool
+	// ASCII draws the UI without box drawing, emoji or sparkline glyphs,
+	// for limited terminals and screen readers.
+	ASCII bool
+	// AutoBackup backs the history database up once a day, keeping the
+	// newest BackupKeep backups (0 keeps them all).
+	AutoBackup bool
+	BackupKeep int
+	// DedupWindow folds a command into the previous history row when it
+	// repeats it (same command, directory and exit code) within the
+	// window; 0 records every run.
+	DedupWindow time.Duration
+	// SecretsFile is the encrypted file workflow "file:" secrets come
+	// from, unlocked with SecretsPassphrase; defaults to
+	// LogDir/secrets.enc.
+	SecretsFile       string
+	SecretsPassphrase string
+	// AIRoutes maps each feature to its backend. Override a single entry
+	// with DEV_CLI_ROUTE_<FEATURE>=local|cloud|auto.
+	AIRoutes map[string]Route
 }
 
 func Load() *Config {
@@ -20,6 +105,8 @@ func Load() *Config {
 		OllamaModel:     "qwen2.5-coder:3b-instruct",
 		PerplexityModel: "sonar-pro",
 		ForceLocalLLM:   false,
+		BackupKeep:      7,
+		AIRoutes:        defaultRoutes(),
 	}
 
 	if val := os.Getenv("DEV_CLI_OLLAMA_URL"); val != "" {
@@ -28,6 +115,12 @@ func Load() *Config {
 	if val := os.Getenv("DEV_CLI_OLLAMA_MODEL"); val != "" {
 		cfg.OllamaModel = val
 	}
+	if val := os.Getenv("DEV_CLI_OLLAMA_FALLBACK_MODEL"); val != "" {
+		cfg.OllamaFallbackModel = val
+	}
+	if val := os.Getenv("DEV_CLI_EMBED_MODEL"); val != "" {
+		cfg.EmbedModel = val
+	}
 	if val := os.Getenv("DEV_CLI_PERPLEXITY_KEY"); val != "" {
 		cfg.PerplexityKey = val
 	} else if val := os.Getenv("PERPLEXITY_API_KEY"); val != "" {
@@ -42,6 +135,11 @@ func Load() *Config {
 		cfg.ForceLocalLLM = true
 	}
 
+	if os.Getenv("DEV_CLI_OFFLINE") != "" {
+		cfg.Offline = true
+		cfg.ForceLocalLLM = true
+	}
+
 	if val := os.Getenv("DEV_CLI_LOG_DIR"); val != "" {
 		cfg.LogDir = val
 	} else {
@@ -49,11 +147,87 @@ func Load() *Config {
 		cfg.LogDir = filepath.Join(home, ".devlogs")
 	}
 
+	if val := os.Getenv("DEV_CLI_MCP_CONFIG"); val != "" {
+		cfg.MCPServersFile = val
+	} else {
+		cfg.MCPServersFile = filepath.Join(cfg.LogDir, "mcp.json")
+	}
+
+	if val := os.Getenv("DEV_CLI_SECRETS_FILE"); val != "" {
+		cfg.SecretsFile = val
+	} else {
+		cfg.SecretsFile = filepath.Join(cfg.LogDir, "secrets.enc")
+	}
+	cfg.SecretsPassphrase = os.Getenv("DEV_CLI_SECRETS_PASSPHRASE")
+
+	cfg.RunbookAllow = splitList(os.Getenv("DEV_CLI_RUNBOOK_ALLOW"))
+	cfg.RunbookDeny = splitList(os.Getenv("DEV_CLI_RUNBOOK_DENY"))
+	cfg.ToolAllow = splitList(os.Getenv("DEV_CLI_TOOLS_ALLOW"))
+	cfg.SystemdUnits = splitList(os.Getenv("DEV_CLI_SYSTEMD_UNITS"))
+	cfg.Theme = strings.TrimSpace(os.Getenv("DEV_CLI_THEME"))
+	cfg.Tabs = splitList(strings.ToLower(os.Getenv("DEV_CLI_TABS")))
+	if os.Getenv("DEV_CLI_TOOLS_READONLY") != "" {
+		cfg.ToolsReadOnly = true
+	}
+	if os.Getenv("DEV_CLI_EXPERT_MODE") != "" {
+		cfg.ExpertMode = true
+	}
+	if os.Getenv("DEV_CLI_ASCII") != "" {
+		cfg.ASCII = true
+	}
+	if os.Getenv("DEV_CLI_AUTO_BACKUP") != "" {
+		cfg.AutoBackup = true
+	}
+	if n, err := strconv.Atoi(os.Getenv("DEV_CLI_BACKUP_KEEP")); err == nil && n >= 0 {
+		cfg.BackupKeep = n
+	}
+	if n, err := strconv.Atoi(os.Getenv("DEV_CLI_DEDUP_WINDOW")); err == nil && n > 0 {
+		cfg.DedupWindow = time.Duration(n) * time.Second
+	}
+
+	for feature := range cfg.AIRoutes {
+		if route, ok := ParseRoute(os.Getenv("DEV_CLI_ROUTE_" + strings.ToUpper(feature))); ok {
+			cfg.AIRoutes[feature] = route
+		}
+	}
+
 	return cfg
 }
 
 func (c *Config) IsWebSearchEnabled() bool {
-	return !c.ForceLocalLLM && c.PerplexityKey != ""
+	return !c.Offline && !c.ForceLocalLLM && c.PerplexityKey != ""
+}
+
+// splitList parses a comma-separated env value, dropping empty entries.
+func splitList(s string) []string {
+	var out []string
+	for _, item := range strings.Split(s, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			out = append(out, item)
+		}
+	}
+	return out
+}
+
+// ParseRoute accepts "local", "cloud" or "auto" in any case.
+func ParseRoute(s string) (Route, bool) {
+	switch r := Route(strings.ToLower(strings.TrimSpace(s))); r {
+	case RouteLocal, RouteCloud, RouteAuto:
+		return r, true
+	}
+	return "", false
+}
+
+// Route returns the backend for feature. Offline and force-local pin every
+// feature to Ollama regardless of the table; unknown features are local.
+func (c *Config) Route(feature string) Route {
+	if c.Offline || c.ForceLocalLLM {
+		return RouteLocal
+	}
+	if route, ok := c.AIRoutes[feature]; ok {
+		return route
+	}
+	return RouteLocal
 }
 
 var Current = Load()
@@ -4,6 +4,7 @@ import (
 	"os"
 	"path/filepath"
 	"strings"
+	"unicode/utf8"
 
 	"github.com/charmbracelet/x/ansi"
 	"github.com/muesli/reflow/wordwrap"
@@ -15,6 +16,7 @@ type Config struct {
 	PerplexityKey   string
 	PerplexityModel string
 	ForceLocalLLM   bool
+	Offline         bool
 	LogDir          string
 }
 
@@ -43,6 +45,11 @@ func LoadConfig() *Config {
 	if os.Getenv("DEV_CLI_FORCE_LOCAL") != "" {
 		cfg.ForceLocalLLM = true
 	}
+
+	if os.Getenv("DEV_CLI_OFFLINE") != "" {
+		cfg.Offline = true
+		cfg.ForceLocalLLM = true
+	}
 	if val := os.Getenv("DEV_CLI_LOG_DIR"); val != "" {
 		cfg.LogDir = val
 	} else {
@@ -54,7 +61,7 @@ func LoadConfig() *Config {
 }
 
 func (c *Config) IsWebSearchEnabled() bool {
-	return !c.ForceLocalLLM && c.PerplexityKey != ""
+	return !c.Offline && !c.ForceLocalLLM && c.PerplexityKey != ""
 }
 
 var CurrentConfig = LoadConfig()
@@ -133,5 +140,9 @@ func TruncateOutput(output string, maxLen int) string {
 	if len(output) <= maxLen {
 		return output
 	}
-	return output[:maxLen-20] + "\n...[truncated]..."
+	end := maxLen - 20
+	for end > 0 && !utf8.RuneStart(output[end]) {
+		end--
+	}
+	return output[:end] + "\n...[truncated]..."
 }
@@ -11,6 +11,8 @@ import (
 	"strings"
 	"time"
 
+	"dev-cli/internal/storage"
+
 	"github.com/creack/pty"
 )
 
@@ -45,15 +47,15 @@ func ExecuteAndLogWithTimeout(command string, timeout time.Duration, publisher E
 	result := ExecuteWithContext(ctx, command)
 
 	if globalDB != nil {
-		logEntry := LogEntry{
+		logEntry := storage.LogEntry{
 			Command:    result.Command,
 			ExitCode:   result.ExitCode,
-			Output:     TruncateOutput(result.Output, 10240),
+			Output:     result.Output,
 			Cwd:        result.Cwd,
 			DurationMs: result.Duration.Milliseconds(),
 			Timestamp:  result.Timestamp.Format(time.RFC3339),
 		}
-		if err := SaveCommand(globalDB, logEntry); err != nil {
+		if err := storage.SaveCommand(globalDB, logEntry); err != nil {
 			fmt.Fprintf(os.Stderr, "Warning: failed to log command: %v\n", err)
 		}
 	}
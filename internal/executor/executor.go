@@ -5,6 +5,7 @@ import (
 	"context"
 	"database/sql"
 	"fmt"
+	"io"
 	"os"
 	"os/exec"
 	"strings"
@@ -14,8 +15,11 @@ import (
 )
 
 type Result struct {
-	Command   string
-	Output    string
+	Command string
+	Output  string
+	// Stdout is what the command wrote to stdout alone, without the
+	// trailing newline.
+	Stdout    string
 	ExitCode  int
 	Duration  time.Duration
 	Timestamp time.Time
@@ -47,7 +51,7 @@ func ExecuteAndLogWithTimeout(command string, timeout time.Duration, publisher E
 		logEntry := storage.LogEntry{
 			Command:    result.Command,
 			ExitCode:   result.ExitCode,
-			Output:     truncateOutput(result.Output, 10240),
+			Output:     result.Output,
 			Cwd:        result.Cwd,
 			DurationMs: result.Duration.Milliseconds(),
 			Timestamp:  result.Timestamp.Format(time.RFC3339),
@@ -65,13 +69,6 @@ func ExecuteAndLogWithTimeout(command string, timeout time.Duration, publisher E
 	return result
 }
 
-func truncateOutput(output string, maxLen int) string {
-	if len(output) <= maxLen {
-		return output
-	}
-	return output[:maxLen-20] + "\n...[truncated]..."
-}
-
 func getShell() string {
 	if shell := os.Getenv("SHELL"); shell != "" {
 		return shell
@@ -96,13 +93,41 @@ func ExecuteWithTimeout(command string, timeout time.Duration) Result {
 }
 
 func ExecuteWithContext(ctx context.Context, command string) Result {
+	return ExecuteWithOptions(ctx, command, Options{})
+}
+
+// Options change where and how ExecuteWithOptions runs a command. The zero
+// value runs it like ExecuteWithContext.
+type Options struct {
+	// Dir is the working directory; the process's when empty.
+	Dir string
+	// Shell runs the command with "<shell> -c" as is, without sourcing
+	// its rc file; the user's shell (with its rc) when empty.
+	Shell string
+	// Env is set on top of the process environment.
+	Env map[string]string
+	// Stdout and Stderr, when set, also get what the command writes to
+	// each as it writes it.
+	Stdout, Stderr io.Writer
+}
+
+// ExecuteWithOptions runs command like ExecuteWithContext, in opts.Dir with
+// opts.Shell and opts.Env, copying its output to opts.Stdout and
+// opts.Stderr.
+func ExecuteWithOptions(ctx context.Context, command string, opts Options) Result {
 	start := time.Now()
 	shell := getShell()
 	cwd, _ := os.Getwd()
+	if opts.Dir != "" {
+		cwd = opts.Dir
+	}
 
 	var cmd *exec.Cmd
 	var wrappedCmd string
-	if strings.HasSuffix(shell, "zsh") {
+	if opts.Shell != "" {
+		shell = opts.Shell
+		cmd = exec.CommandContext(ctx, shell, "-c", command)
+	} else if strings.HasSuffix(shell, "zsh") {
 		wrappedCmd = fmt.Sprintf("source ~/.zshrc 2>/dev/null; %s", command)
 		cmd = exec.CommandContext(ctx, shell, "-c", wrappedCmd)
 	} else if strings.HasSuffix(shell, "bash") {
@@ -116,9 +141,18 @@ func ExecuteWithContext(ctx context.Context, command string) Result {
 	var stdout, stderr bytes.Buffer
 	cmd.Stdout = &stdout
 	cmd.Stderr = &stderr
+	if opts.Stdout != nil {
+		cmd.Stdout = io.MultiWriter(&stdout, opts.Stdout)
+	}
+	if opts.Stderr != nil {
+		cmd.Stderr = io.MultiWriter(&stderr, opts.Stderr)
+	}
 
 	cmd.Dir = cwd
 	cmd.Env = os.Environ()
+	for key, value := range opts.Env {
+		cmd.Env = append(cmd.Env, key+"="+value)
+	}
 
 	hasTermEnv := false
 	for _, env := range cmd.Env {
@@ -136,6 +170,7 @@ func ExecuteWithContext(ctx context.Context, command string) Result {
 	duration := time.Since(start)
 
 	output := stdout.String()
+	stdoutStr := strings.TrimSuffix(output, "\n")
 	stderrStr := stderr.String()
 
 	stderrStr = filterShellNoise(stderrStr)
@@ -164,6 +199,7 @@ func ExecuteWithContext(ctx context.Context, command string) Result {
 	return Result{
 		Command:   command,
 		Output:    output,
+		Stdout:    stdoutStr,
 		ExitCode:  exitCode,
 		Duration:  duration,
 		Timestamp: start,
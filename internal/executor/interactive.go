@@ -0,0 +1,234 @@
+package executor
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/x/ansi"
+	"github.com/creack/pty"
+	"github.com/muesli/cancelreader"
+	"golang.org/x/term"
+)
+
+// maxInteractiveOutput is how much of an interactive session's output is
+// kept for its block; a long ssh session only needs its tail.
+const maxInteractiveOutput = 1 << 20
+
+// InteractiveCommand runs a command that needs the whole terminal (an
+// editor, htop, ssh) on a PTY attached to the caller's terminal, keeping
+// what it printed. It has the shape of tea.ExecCommand, so it can be
+// handed straight to tea.Exec.
+type InteractiveCommand struct {
+	command string
+	stdin   io.Reader
+	stdout  io.Writer
+
+	output tailBuffer
+	result Result
+}
+
+// Interactive prepares command to run on the caller's terminal.
+func Interactive(command string) *InteractiveCommand {
+	return &InteractiveCommand{command: command, stdin: os.Stdin, stdout: os.Stdout}
+}
+
+func (c *InteractiveCommand) SetStdin(r io.Reader)  { c.stdin = r }
+func (c *InteractiveCommand) SetStdout(w io.Writer) { c.stdout = w }
+
+// SetStderr is a no-op: the PTY merges stderr into stdout.
+func (c *InteractiveCommand) SetStderr(io.Writer) {}
+
+// Result is the outcome once Run returned, with the output the command
+// left on the main screen.
+func (c *InteractiveCommand) Result() Result {
+	return c.result
+}
+
+// Run hands the terminal to the command until it exits. A command that
+// ran and failed is not an error: its exit code is in Result.
+func (c *InteractiveCommand) Run() error {
+	start := time.Now()
+	shell := getShell()
+	cwd, _ := os.Getwd()
+	c.result = Result{Command: c.command, ExitCode: 1, Timestamp: start, Shell: shell, Cwd: cwd}
+
+	if in, ok := c.stdin.(*os.File); ok && term.IsTerminal(int(in.Fd())) {
+		state, err := term.MakeRaw(int(in.Fd()))
+		if err != nil {
+			return fmt.Errorf("raw mode failed: %w", err)
+		}
+		defer term.Restore(int(in.Fd()), state)
+	}
+
+	// The stdin copier is cancelled when the command exits, so it does not
+	// swallow the first keystroke meant for the TUI.
+	in, err := cancelreader.NewReader(c.stdin)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	cmd := ptyShellCommand(context.Background(), shell, c.command)
+	cmd.Dir = cwd
+	cmd.Env = os.Environ()
+	if os.Getenv("TERM") == "" {
+		cmd.Env = append(cmd.Env, "TERM=xterm-256color")
+	}
+
+	ptmx, err := pty.Start(cmd)
+	if err != nil {
+		c.result.Output = "Failed to start PTY: " + err.Error()
+		return err
+	}
+	defer ptmx.Close()
+
+	if tty, ok := c.stdout.(*os.File); ok {
+		pty.InheritSize(tty, ptmx)
+		defer watchResize(tty, ptmx)()
+	}
+
+	go io.Copy(ptmx, in)
+	// Reading ends with an error once the command and all it started are
+	// gone; that is the normal way out.
+	io.Copy(io.MultiWriter(c.stdout, &c.output), ptmx)
+	err = cmd.Wait()
+	in.Cancel()
+
+	c.result.Duration = time.Since(start)
+	c.result.Output = mainScreenOutput(c.output.String())
+	c.result.ExitCode = 0
+	if err != nil {
+		c.result.ExitCode = 1
+		if exitError, ok := err.(*exec.ExitError); ok {
+			c.result.ExitCode = exitError.ExitCode()
+		}
+	}
+	return nil
+}
+
+// altScreen matches what a full-screen program draws on the alternate
+// screen, which is gone once it exits.
+var altScreen = regexp.MustCompile(`(?s)\x1b\[\?(?:1049|1047|47)h.*?(?:\x1b\[\?(?:1049|1047|47)l|$)`)
+
+// mainScreenOutput turns a session's raw output into the text it left on
+// the main screen, with lines redrawn through "\r" reduced to their last
+// state.
+func mainScreenOutput(raw string) string {
+	text := ansi.Strip(altScreen.ReplaceAllString(raw, ""))
+	lines := strings.Split(strings.ReplaceAll(text, "\r\n", "\n"), "\n")
+	for i, line := range lines {
+		if j := strings.LastIndex(line, "\r"); j >= 0 {
+			lines[i] = line[j+1:]
+		}
+	}
+	return strings.TrimSpace(strings.Join(lines, "\n"))
+}
+
+// tailBuffer keeps the last maxInteractiveOutput bytes written to it.
+type tailBuffer struct {
+	buf []byte
+}
+
+func (t *tailBuffer) Write(p []byte) (int, error) {
+	t.buf = append(t.buf, p...)
+	if len(t.buf) > maxInteractiveOutput {
+		t.buf = t.buf[len(t.buf)-maxInteractiveOutput:]
+	}
+	return len(p), nil
+}
+
+func (t *tailBuffer) String() string {
+	return string(t.buf)
+}
+
+// interactivePrograms take over the terminal, so their output makes no
+// sense in a block.
+var interactivePrograms = map[string]bool{
+	"vi": true, "vim": true, "nvim": true, "nano": true, "emacs": true, "micro": true, "hx": true,
+	"less": true, "more": true, "man": true,
+	"top": true, "htop": true, "btop": true, "atop": true, "watch": true,
+	"ssh": true, "mosh": true, "telnet": true, "tmux": true, "screen": true,
+	"fzf": true, "tig": true, "lazygit": true, "lazydocker": true, "k9s": true, "ranger": true, "nnn": true,
+}
+
+// replPrograms are interactive when started without arguments.
+var replPrograms = map[string]bool{
+	"python": true, "python3": true, "node": true, "irb": true, "ghci": true, "lua": true,
+	"psql": true, "mysql": true, "sqlite3": true, "redis-cli": true, "mongosh": true,
+	"sh": true, "bash": true, "zsh": true, "fish": true,
+}
+
+// commandSeparators splits a command line into its pipeline and chain parts.
+var commandSeparators = regexp.MustCompile(`\|\|?|&&|;`)
+
+// IsInteractive reports whether command needs the whole terminal: a
+// full-screen program, a REPL, an attached container shell or a git
+// command that opens an editor, in any part of a pipeline or chain.
+func IsInteractive(command string) bool {
+	for _, part := range commandSeparators.Split(command, -1) {
+		if interactivePart(strings.Fields(part)) {
+			return true
+		}
+	}
+	return false
+}
+
+func interactivePart(fields []string) bool {
+	// Skip what only wraps the program: env assignments, env, sudo, exec.
+	for len(fields) > 0 && (strings.Contains(fields[0], "=") || fields[0] == "sudo" || fields[0] == "exec" || fields[0] == "env") {
+		fields = fields[1:]
+	}
+	if len(fields) == 0 {
+		return false
+	}
+
+	program, args := filepath.Base(fields[0]), fields[1:]
+	switch {
+	case interactivePrograms[program]:
+		return true
+	case replPrograms[program]:
+		return len(args) == 0
+	case program == "docker" || program == "podman" || program == "kubectl":
+		return hasArg(args, "-it", "-ti", "--tty")
+	case program == "git" && len(args) > 0:
+		switch args[0] {
+		case "commit":
+			return !commitHasMessage(args[1:])
+		case "rebase", "add":
+			return hasArg(args, "-i", "--interactive", "-p", "--patch")
+		}
+	}
+	return false
+}
+
+func hasArg(args []string, flags ...string) bool {
+	for _, arg := range args {
+		for _, flag := range flags {
+			if arg == flag {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// commitHasMessage reports whether git commit args give the message, so
+// no editor opens: -m or -F, alone or in a cluster like -am, or --no-edit.
+func commitHasMessage(args []string) bool {
+	for _, arg := range args {
+		switch {
+		case arg == "--no-edit", strings.HasPrefix(arg, "--message"), strings.HasPrefix(arg, "--file"):
+			return true
+		case strings.HasPrefix(arg, "-") && !strings.HasPrefix(arg, "--") && strings.ContainsAny(arg, "mF"):
+			return true
+		}
+	}
+	return false
+}
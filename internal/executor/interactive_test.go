@@ -0,0 +1,67 @@
+package executor
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestIsInteractive(t *testing.T) {
+	tests := []struct {
+		command string
+		want    bool
+	}{
+		{"vim main.go", true},
+		{"/usr/bin/htop", true},
+		{"EDITOR=nano sudo vim /etc/hosts", true},
+		{"git log | less", true},
+		{"ssh prod-1", true},
+		{"python3", true},
+		{"python3 script.py", false},
+		{"docker exec -it web sh", true},
+		{"docker build -t web .", false},
+		{"git commit", true},
+		{"git commit --amend", true},
+		{"git commit -am 'fix build'", false},
+		{"git commit --message=wip", false},
+		{"git rebase -i HEAD~3", true},
+		{"git status", false},
+		{"make build && make test", false},
+	}
+	for _, tt := range tests {
+		if got := IsInteractive(tt.command); got != tt.want {
+			t.Errorf("IsInteractive(%q) = %v, want %v", tt.command, got, tt.want)
+		}
+	}
+}
+
+func TestMainScreenOutput(t *testing.T) {
+	raw := "before\r\n\x1b[?1049h\x1b[2J\x1b[1;1Hfull screen\x1b[?1049l\x1b[32mafter\x1b[0m\r\n" +
+		"progress 10%\rprogress 100%\r\n"
+	if got, want := mainScreenOutput(raw), "before\nafter\nprogress 100%"; got != want {
+		t.Errorf("mainScreenOutput = %q, want %q", got, want)
+	}
+}
+
+func TestInteractiveCommand_Run(t *testing.T) {
+	if testing.Short() {
+		t.Skip("runs a shell")
+	}
+	t.Setenv("SHELL", "/bin/sh")
+
+	var screen bytes.Buffer
+	session := Interactive("read name; echo hello $name; exit 3")
+	session.SetStdin(strings.NewReader("dev\n"))
+	session.SetStdout(&screen)
+	if err := session.Run(); err != nil {
+		t.Fatal(err)
+	}
+
+	result := session.Result()
+	if result.ExitCode != 3 || !strings.HasSuffix(result.Output, "hello dev") {
+		t.Errorf("result = %+v, want exit code 3 and the greeting", result)
+	}
+	if !strings.Contains(screen.String(), "hello dev") {
+		t.Errorf("expected the session on the terminal, got %q", screen.String())
+	}
+}
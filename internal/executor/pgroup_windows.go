@@ -0,0 +1,8 @@
+package executor
+
+import "os/exec"
+
+// killProcessGroup kills cmd; Windows has no process groups to signal.
+func killProcessGroup(cmd *exec.Cmd) {
+	cmd.Process.Kill()
+}
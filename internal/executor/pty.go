@@ -24,17 +24,19 @@ func ExecutePTYWithTimeout(command string, timeout time.Duration) Result {
 }
 
 func ExecutePTYWithContext(ctx context.Context, command string) Result {
+	return ExecutePTYStreaming(ctx, command, nil)
+}
+
+// ExecutePTYStreaming runs command like ExecutePTYWithContext and, when out
+// is not nil, also copies the raw terminal output to it as it arrives.
+// CleanPTYOutput turns what out has seen so far into displayable text.
+// When ctx ends, the command's whole process group is killed: a deadline
+// gives exit code 124, a cancel 130 with the output printed until then.
+func ExecutePTYStreaming(ctx context.Context, command string, out io.Writer) Result {
 	start := time.Now()
 	shell := getShell()
 
-	var cmd *exec.Cmd
-	if strings.HasSuffix(shell, "zsh") {
-		cmd = exec.CommandContext(ctx, shell, "-i", "-c", command)
-	} else if strings.HasSuffix(shell, "bash") {
-		cmd = exec.CommandContext(ctx, shell, "-i", "-c", command)
-	} else {
-		cmd = exec.CommandContext(ctx, shell, "-c", command)
-	}
+	cmd := ptyShellCommand(ctx, shell, command)
 
 	cwd, _ := os.Getwd()
 	cmd.Dir = cwd
@@ -56,22 +58,31 @@ func ExecutePTYWithContext(ctx context.Context, command string) Result {
 	defer ptmx.Close()
 
 	var output bytes.Buffer
+	var dst io.Writer = &output
+	if out != nil {
+		dst = io.MultiWriter(&output, out)
+	}
 	done := make(chan error, 1)
 
 	go func() {
-		io.Copy(&output, ptmx)
+		io.Copy(dst, ptmx)
 		done <- cmd.Wait()
 	}()
 
 	select {
 	case err = <-done:
 	case <-ctx.Done():
-		cmd.Process.Kill()
+		killProcessGroup(cmd)
 		err = ctx.Err()
+		// Give the copy a moment to drain what the command printed last.
+		select {
+		case <-done:
+		case <-time.After(time.Second):
+		}
 	}
 
 	duration := time.Since(start)
-	outputStr := cleanPTYOutput(output.String())
+	outputStr := CleanPTYOutput(output.String())
 
 	exitCode := 0
 	if err != nil {
@@ -80,6 +91,9 @@ func ExecutePTYWithContext(ctx context.Context, command string) Result {
 		} else if err == context.DeadlineExceeded {
 			exitCode = 124
 			outputStr = "Command timed out"
+		} else if err == context.Canceled {
+			// Interrupted, like a shell's Ctrl+C.
+			exitCode = 130
 		} else {
 			exitCode = 1
 			if outputStr == "" {
@@ -98,7 +112,18 @@ func ExecutePTYWithContext(ctx context.Context, command string) Result {
 	}
 }
 
-func cleanPTYOutput(output string) string {
+// ptyShellCommand runs command through shell, interactively for zsh and
+// bash so the user's aliases and functions work.
+func ptyShellCommand(ctx context.Context, shell, command string) *exec.Cmd {
+	if strings.HasSuffix(shell, "zsh") || strings.HasSuffix(shell, "bash") {
+		return exec.CommandContext(ctx, shell, "-i", "-c", command)
+	}
+	return exec.CommandContext(ctx, shell, "-c", command)
+}
+
+// CleanPTYOutput strips terminal escapes and shell start-up noise from raw
+// PTY output.
+func CleanPTYOutput(output string) string {
 	output = stripANSI(output)
 
 	lines := strings.Split(output, "\n")
@@ -0,0 +1,26 @@
+//go:build unix
+
+package executor
+
+import (
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/creack/pty"
+)
+
+// watchResize keeps ptmx the size of tty until the returned stop is called.
+func watchResize(tty, ptmx *os.File) func() {
+	resized := make(chan os.Signal, 1)
+	signal.Notify(resized, syscall.SIGWINCH)
+	go func() {
+		for range resized {
+			pty.InheritSize(tty, ptmx)
+		}
+	}()
+	return func() {
+		signal.Stop(resized)
+		close(resized)
+	}
+}
@@ -0,0 +1,8 @@
+package executor
+
+import "os"
+
+// watchResize does nothing: Windows sends no SIGWINCH.
+func watchResize(tty, ptmx *os.File) func() {
+	return func() {}
+}
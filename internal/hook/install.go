@@ -0,0 +1,74 @@
+package hook
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Shells are the shells with an integration script.
+var Shells = []string{"zsh"}
+
+// Script is the integration script for shell.
+func Script(shell string) (string, error) {
+	switch shell {
+	case "zsh":
+		return ZshHook, nil
+	}
+	return "", fmt.Errorf("unsupported shell %q (supported: %s)", shell, strings.Join(Shells, ", "))
+}
+
+// InitLine is the line a shell's rc file needs to load the integration.
+func InitLine(shell string) string {
+	return fmt.Sprintf(`eval "$(dev-cli init %s)"`, shell)
+}
+
+// RCFile is the startup file of shell that Install adds the integration
+// to: ~/.zshrc, or the one in $ZDOTDIR.
+func RCFile(shell string) (string, error) {
+	if _, err := Script(shell); err != nil {
+		return "", err
+	}
+	dir := os.Getenv("ZDOTDIR")
+	if dir == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return "", err
+		}
+		dir = home
+	}
+	return filepath.Join(dir, ".zshrc"), nil
+}
+
+// Install appends the integration of shell to the rc file at path, which
+// is created if missing. It reports false when the file loads it already.
+func Install(shell, path string) (bool, error) {
+	line := InitLine(shell)
+	data, err := os.ReadFile(path)
+	if err != nil && !os.IsNotExist(err) {
+		return false, err
+	}
+	for _, l := range strings.Split(string(data), "\n") {
+		if strings.TrimSpace(l) == line {
+			return false, nil
+		}
+	}
+
+	var b strings.Builder
+	if len(data) > 0 && !strings.HasSuffix(string(data), "\n") {
+		b.WriteString("\n")
+	}
+	b.WriteString("\n# dev-cli: record commands run in this shell\n")
+	b.WriteString(line + "\n")
+
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+	if err != nil {
+		return false, err
+	}
+	if _, err := f.WriteString(b.String()); err != nil {
+		f.Close()
+		return false, err
+	}
+	return true, f.Close()
+}
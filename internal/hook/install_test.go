@@ -0,0 +1,44 @@
+package hook
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestInstall(t *testing.T) {
+	path := filepath.Join(t.TempDir(), ".zshrc")
+	if err := os.WriteFile(path, []byte("export EDITOR=vim"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	changed, err := Install("zsh", path)
+	if err != nil || !changed {
+		t.Fatalf("first Install = %v, %v; want true, nil", changed, err)
+	}
+	changed, err = Install("zsh", path)
+	if err != nil || changed {
+		t.Fatalf("second Install = %v, %v; want false, nil", changed, err)
+	}
+
+	data, _ := os.ReadFile(path)
+	got := string(data)
+	if !strings.HasPrefix(got, "export EDITOR=vim\n") {
+		t.Errorf("existing line not kept on its own:\n%s", got)
+	}
+	if n := strings.Count(got, InitLine("zsh")); n != 1 {
+		t.Errorf("init line appears %d times:\n%s", n, got)
+	}
+}
+
+func TestInstall_CreatesFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), ".zshrc")
+	if _, err := Install("zsh", path); err != nil {
+		t.Fatal(err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil || !strings.Contains(string(data), InitLine("zsh")) {
+		t.Fatalf("rc file = %q, %v", data, err)
+	}
+}
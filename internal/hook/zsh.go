@@ -3,6 +3,7 @@ package hook
 const ZshHook = `# dev-cli Zsh integration
 # eval "$(dev-cli init zsh)"
 
+typeset -g __DEVOPS_SESSION=""
 typeset -g __DEVOPS_CMD=""
 typeset -g __DEVOPS_START_TIME=0
 typeset -g __DEVOPS_SKIP_LOG=0
@@ -134,10 +135,10 @@ __devops_precmd() {
     local end_time=$(($(date +%s%N)/1000000))
     local duration_ms=$((end_time - __DEVOPS_START_TIME))
 
-    dev-cli log-event \
-        --command "$__DEVOPS_CMD" \
+    print -rn -- "$__DEVOPS_CMD" | dev-cli log \
         --exit-code "$exit_code" \
         --cwd "$PWD" \
+        --session "$__DEVOPS_SESSION" \
         --duration-ms "$duration_ms" 2>/dev/null &!
 
     if [[ $exit_code -ne 0 && $exit_code -ne 130 ]]; then
@@ -169,6 +170,7 @@ dcap() {
     
     local end=$(($(date +%s%N)/1000000))
     local duration=$((end - start))
+    # The history gets the whole file; suggestions only need the tail.
     local output=$(tail -c 10240 "$tmpfile" 2>/dev/null)
     __DEVOPS_LAST_OUTPUT="$output"
     
@@ -177,7 +179,8 @@ dcap() {
         --exit-code "$exit_code" \
         --cwd "$PWD" \
         --duration-ms "$duration" \
-        --output "$output" 2>/dev/null
+        --session "$__DEVOPS_SESSION" \
+        --output-file "$tmpfile" 2>/dev/null
     
     rm -f "$tmpfile"
     
@@ -203,10 +206,18 @@ dcap() {
     return $exit_code
 }
 
+__devops_zshexit() {
+    [[ -n "$__DEVOPS_SESSION" ]] && dev-cli session end --id "$__DEVOPS_SESSION" 2>/dev/null
+}
+
+# Each shell is a session: its commands are grouped under its ID
+__DEVOPS_SESSION="$(dev-cli session start --source zsh --cwd "$PWD" 2>/dev/null)"
+
 # Initialize by checking for any pending unresolved failures
 __devops_check_resolution
 
 autoload -Uz add-zsh-hook
 add-zsh-hook preexec __devops_preexec
 add-zsh-hook precmd __devops_precmd
+add-zsh-hook zshexit __devops_zshexit
 `
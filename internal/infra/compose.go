@@ -0,0 +1,218 @@
+package infra
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// Labels the compose plugin sets on every container it creates.
+const (
+	ComposeProjectLabel     = "com.docker.compose.project"
+	ComposeServiceLabel     = "com.docker.compose.service"
+	ComposeWorkingDirLabel  = "com.docker.compose.project.working_dir"
+	ComposeConfigFilesLabel = "com.docker.compose.project.config_files"
+)
+
+// ComposeService is one container of a compose project.
+type ComposeService struct {
+	Name        string
+	ContainerID string
+	State       string
+	Status      string
+}
+
+// ComposeProject groups the containers that share a compose project label.
+type ComposeProject struct {
+	Name        string
+	WorkingDir  string
+	ConfigFiles []string
+	Services    []ComposeService
+	Running     int
+}
+
+// Total is the number of containers in the project.
+func (p ComposeProject) Total() int { return len(p.Services) }
+
+// Status summarizes the project: "running" when every container is up,
+// "partial" when only some are, "stopped" when none are, and "down" when
+// its containers have been removed.
+func (p ComposeProject) Status() string {
+	switch {
+	case len(p.Services) == 0:
+		return "down"
+	case p.Running == len(p.Services):
+		return "running"
+	case p.Running > 0:
+		return "partial"
+	}
+	return "stopped"
+}
+
+// GroupComposeProjects builds the compose projects from container labels,
+// sorted by name. Containers without a project label are ignored.
+func GroupComposeProjects(containers []ContainerInfo) []ComposeProject {
+	byName := make(map[string]*ComposeProject)
+	for _, c := range containers {
+		name := c.Labels[ComposeProjectLabel]
+		if name == "" {
+			continue
+		}
+		p, ok := byName[name]
+		if !ok {
+			p = &ComposeProject{Name: name, WorkingDir: c.Labels[ComposeWorkingDirLabel]}
+			if files := c.Labels[ComposeConfigFilesLabel]; files != "" {
+				p.ConfigFiles = strings.Split(files, ",")
+			}
+			byName[name] = p
+		}
+
+		service := c.Labels[ComposeServiceLabel]
+		if service == "" {
+			service = c.Name
+		}
+		p.Services = append(p.Services, ComposeService{Name: service, ContainerID: c.ID, State: c.State, Status: c.Status})
+		if c.State == "running" {
+			p.Running++
+		}
+	}
+
+	projects := make([]ComposeProject, 0, len(byName))
+	for _, p := range byName {
+		sort.Slice(p.Services, func(i, j int) bool { return p.Services[i].Name < p.Services[j].Name })
+		projects = append(projects, *p)
+	}
+	sort.Slice(projects, func(i, j int) bool { return projects[i].Name < projects[j].Name })
+	return projects
+}
+
+// composeRunner runs "docker <args>" in dir, with env added to the
+// environment, and returns its combined output.
+type composeRunner func(ctx context.Context, dir string, env []string, args ...string) ([]byte, error)
+
+func runDocker(ctx context.Context, dir string, env []string, args ...string) ([]byte, error) {
+	cmd := exec.CommandContext(ctx, "docker", args...)
+	cmd.Dir = dir
+	cmd.Env = append(os.Environ(), env...)
+	return cmd.CombinedOutput()
+}
+
+// ComposeClient lists compose projects from container labels and drives
+// them through the docker compose plugin. Projects it has seen are
+// remembered, so one brought down can be brought up again even though its
+// containers (and their labels) are gone.
+type ComposeClient struct {
+	docker DockerAPI
+	run    composeRunner
+
+	mu    sync.Mutex
+	known map[string]ComposeProject
+}
+
+// NewComposeClient creates a client over docker. A nil docker falls back to
+// the shared daemon client.
+func NewComposeClient(docker DockerAPI) *ComposeClient {
+	return &ComposeClient{docker: docker, run: runDocker, known: make(map[string]ComposeProject)}
+}
+
+// ListProjects returns the projects with containers on the daemon, plus
+// any previously seen project that has since been brought down.
+func (c *ComposeClient) ListProjects(ctx context.Context) ([]ComposeProject, error) {
+	docker := c.docker
+	if docker == nil {
+		shared, err := GetSharedDockerClient()
+		if err != nil {
+			return nil, err
+		}
+		docker = shared
+	}
+	health := docker.CheckHealth(ctx)
+	if !health.Available {
+		return nil, health.Error
+	}
+	return c.Remember(GroupComposeProjects(health.Containers)), nil
+}
+
+// Remember records projects grouped from an existing container listing and
+// returns them merged with the known projects that are now down.
+func (c *ComposeClient) Remember(projects []ComposeProject) []ComposeProject {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	live := make(map[string]bool, len(projects))
+	for _, p := range projects {
+		live[p.Name] = true
+		c.known[p.Name] = p
+	}
+	for name, p := range c.known {
+		if !live[name] {
+			projects = append(projects, ComposeProject{Name: name, WorkingDir: p.WorkingDir, ConfigFiles: p.ConfigFiles})
+		}
+	}
+	sort.Slice(projects, func(i, j int) bool { return projects[i].Name < projects[j].Name })
+	return projects
+}
+
+// Up creates and starts the project's services in the background.
+func (c *ComposeClient) Up(ctx context.Context, name string) error {
+	return c.compose(ctx, name, "up", "-d")
+}
+
+// Down stops and removes the project's containers and networks.
+func (c *ComposeClient) Down(ctx context.Context, name string) error {
+	return c.compose(ctx, name, "down")
+}
+
+func (c *ComposeClient) compose(ctx context.Context, name string, action ...string) error {
+	c.mu.Lock()
+	p, ok := c.known[name]
+	c.mu.Unlock()
+	if !ok {
+		if _, err := c.ListProjects(ctx); err != nil {
+			return fmt.Errorf("compose %s: %w", action[0], err)
+		}
+		c.mu.Lock()
+		p, ok = c.known[name]
+		c.mu.Unlock()
+		if !ok {
+			return fmt.Errorf("no such compose project: %s", name)
+		}
+	}
+
+	args := []string{"compose", "-p", p.Name}
+	for _, f := range p.ConfigFiles {
+		args = append(args, "-f", f)
+	}
+	args = append(args, action...)
+
+	out, err := c.run(ctx, p.WorkingDir, c.daemonEnv(), args...)
+	if err != nil {
+		if msg := strings.TrimSpace(string(out)); msg != "" {
+			return fmt.Errorf("compose %s %s: %s", action[0], name, msg)
+		}
+		return fmt.Errorf("compose %s %s: %w", action[0], name, err)
+	}
+	return nil
+}
+
+// daemonEnv points the docker CLI at the daemon the client lists projects
+// from, which may be a --context or DEV_CLI_DOCKER_CONTEXT other than the
+// CLI's own.
+func (c *ComposeClient) daemonEnv() []string {
+	docker := c.docker
+	if docker == nil {
+		shared, err := GetSharedDockerClient()
+		if err != nil {
+			return nil
+		}
+		docker = shared
+	}
+	if d, ok := docker.(interface{ Endpoint() DaemonEndpoint }); ok {
+		return d.Endpoint().Env()
+	}
+	return nil
+}
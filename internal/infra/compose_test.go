@@ -0,0 +1,114 @@
+package infra
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func composeContainer(id, project, service, state string) ContainerInfo {
+	return ContainerInfo{ID: id, Name: project + "-" + service + "-1", State: state, Labels: map[string]string{
+		ComposeProjectLabel:     project,
+		ComposeServiceLabel:     service,
+		ComposeWorkingDirLabel:  "/src/" + project,
+		ComposeConfigFilesLabel: "/src/" + project + "/compose.yaml,/src/" + project + "/compose.dev.yaml",
+	}}
+}
+
+func TestGroupComposeProjects(t *testing.T) {
+	projects := GroupComposeProjects([]ContainerInfo{
+		composeContainer("a1", "shop", "web", "running"),
+		composeContainer("a2", "shop", "db", "exited"),
+		composeContainer("b1", "blog", "app", "running"),
+		{ID: "c1", Name: "standalone", State: "running"},
+	})
+
+	if len(projects) != 2 || projects[0].Name != "blog" || projects[1].Name != "shop" {
+		t.Fatalf("expected blog and shop, got %+v", projects)
+	}
+	shop := projects[1]
+	if shop.Total() != 2 || shop.Running != 1 || shop.Status() != "partial" {
+		t.Errorf("unexpected shop counts: %d/%d %s", shop.Running, shop.Total(), shop.Status())
+	}
+	if shop.Services[0].Name != "db" || shop.WorkingDir != "/src/shop" || len(shop.ConfigFiles) != 2 {
+		t.Errorf("unexpected shop project: %+v", shop)
+	}
+	if projects[0].Status() != "running" {
+		t.Errorf("expected blog running, got %s", projects[0].Status())
+	}
+}
+
+func TestComposeClient_UpDown(t *testing.T) {
+	ctx := context.Background()
+	docker := NewFakeDocker(composeContainer("a1", "shop", "web", "running"))
+	compose := NewComposeClient(docker)
+
+	var calls []string
+	compose.run = func(ctx context.Context, dir string, env []string, args ...string) ([]byte, error) {
+		calls = append(calls, dir+": "+strings.Join(args, " "))
+		if args[len(args)-1] == "down" {
+			docker.Containers = nil
+		}
+		return nil, nil
+	}
+
+	if err := compose.Down(ctx, "shop"); err != nil {
+		t.Fatalf("Down failed: %v", err)
+	}
+	projects, err := compose.ListProjects(ctx)
+	if err != nil {
+		t.Fatalf("ListProjects failed: %v", err)
+	}
+	if len(projects) != 1 || projects[0].Status() != "down" {
+		t.Fatalf("expected shop to be remembered as down, got %+v", projects)
+	}
+
+	if err := compose.Up(ctx, "shop"); err != nil {
+		t.Fatalf("Up failed: %v", err)
+	}
+	want := "/src/shop: compose -p shop -f /src/shop/compose.yaml -f /src/shop/compose.dev.yaml up -d"
+	if len(calls) != 2 || calls[1] != want {
+		t.Errorf("unexpected compose calls: %q", calls)
+	}
+
+	if err := compose.Up(ctx, "unknown"); err == nil {
+		t.Error("expected error for unknown project")
+	}
+
+	compose.run = func(ctx context.Context, dir string, env []string, args ...string) ([]byte, error) {
+		return []byte("no configuration file provided\n"), errors.New("exit status 1")
+	}
+	if err := compose.Up(ctx, "shop"); err == nil || !strings.Contains(err.Error(), "no configuration file") {
+		t.Errorf("expected compose output in error, got %v", err)
+	}
+}
+
+// endpointDocker is a fake daemon reached through a given endpoint.
+type endpointDocker struct {
+	*FakeDocker
+	endpoint DaemonEndpoint
+}
+
+func (d endpointDocker) Endpoint() DaemonEndpoint { return d.endpoint }
+
+func TestComposeClient_UsesSelectedDaemon(t *testing.T) {
+	docker := endpointDocker{
+		FakeDocker: NewFakeDocker(composeContainer("a1", "shop", "web", "running")),
+		endpoint:   DaemonEndpoint{Context: "build-box", Host: "tcp://build-box:2376", TLSDir: "/certs"},
+	}
+	compose := NewComposeClient(docker)
+
+	var got []string
+	compose.run = func(ctx context.Context, dir string, env []string, args ...string) ([]byte, error) {
+		got = env
+		return nil, nil
+	}
+	if err := compose.Down(context.Background(), "shop"); err != nil {
+		t.Fatalf("Down failed: %v", err)
+	}
+	want := []string{"DOCKER_HOST=tcp://build-box:2376", "DOCKER_TLS_VERIFY=1", "DOCKER_CERT_PATH=/certs"}
+	if strings.Join(got, " ") != strings.Join(want, " ") {
+		t.Errorf("expected compose pointed at build-box, got env %q", got)
+	}
+}
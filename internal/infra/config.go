@@ -14,6 +14,10 @@ type Config struct {
 	OllamaDefaultModel string        `yaml:"ollama_default_model"`
 	DevlogsDir         string        `yaml:"devlogs_dir"`
 	LogFormat          string        `yaml:"log_format"`
+	// DockerContext picks the daemon: a docker context, "podman" or a host
+	// URL. It comes from DEV_CLI_DOCKER_CONTEXT (set by --context); empty
+	// follows DOCKER_HOST and the docker CLI's current context.
+	DockerContext string
 }
 
 func DefaultConfig() Config {
@@ -28,6 +32,7 @@ func DefaultConfig() Config {
 		OllamaDefaultModel: "qwen2.5-coder:3b-instruct",
 		DevlogsDir:         devlogsDir,
 		LogFormat:          "jsonl",
+		DockerContext:      os.Getenv("DEV_CLI_DOCKER_CONTEXT"),
 	}
 }
 
@@ -65,3 +70,8 @@ func (c Config) WithLogFormat(format string) Config {
 	c.LogFormat = format
 	return c
 }
+
+func (c Config) WithDockerContext(name string) Config {
+	c.DockerContext = name
+	return c
+}
@@ -0,0 +1,178 @@
+package infra
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/client"
+)
+
+// PodmanContext is the built-in context name for the local Podman socket.
+const PodmanContext = "podman"
+
+// DaemonEndpoint is the engine API a DockerClient talks to.
+type DaemonEndpoint struct {
+	// Context is the docker context (or PodmanContext) the host came from;
+	// empty when the host was given directly, e.g. with DOCKER_HOST.
+	Context string
+	Host    string
+	// TLSDir holds ca.pem, cert.pem and key.pem for tcp hosts that need
+	// client certificates; empty otherwise.
+	TLSDir string
+}
+
+// String names the endpoint for display: the context when there is one,
+// the host otherwise.
+func (e DaemonEndpoint) String() string {
+	if e.Context != "" {
+		return e.Context
+	}
+	return e.Host
+}
+
+// Env is the environment that points the docker CLI at the endpoint, so
+// commands it runs (e.g. docker compose) reach the same daemon.
+func (e DaemonEndpoint) Env() []string {
+	env := []string{"DOCKER_HOST=" + e.Host}
+	if e.TLSDir != "" {
+		env = append(env, "DOCKER_TLS_VERIFY=1", "DOCKER_CERT_PATH="+e.TLSDir)
+	}
+	return env
+}
+
+// ResolveDaemon finds the endpoint for name, which may be a docker context,
+// PodmanContext, "default" or a host URL such as tcp://build-box:2376. An
+// empty name follows the docker CLI: DOCKER_HOST, then DOCKER_CONTEXT, then
+// the current context in ~/.docker/config.json.
+func ResolveDaemon(name string) (DaemonEndpoint, error) {
+	if name == "" {
+		if host := os.Getenv("DOCKER_HOST"); host != "" {
+			return DaemonEndpoint{Host: host}, nil
+		}
+		name = os.Getenv("DOCKER_CONTEXT")
+	}
+	if name == "" {
+		name = currentDockerContext()
+	}
+
+	switch {
+	case strings.Contains(name, "://"):
+		return DaemonEndpoint{Host: name}, nil
+	case name == "" || name == "default":
+		return DaemonEndpoint{Context: "default", Host: client.DefaultDockerHost}, nil
+	case name == PodmanContext:
+		return podmanEndpoint()
+	}
+	return dockerContextEndpoint(name)
+}
+
+// podmanSocketPaths are tried in order: the rootless socket, then the
+// system one.
+func podmanSocketPaths() []string {
+	var paths []string
+	if dir := os.Getenv("XDG_RUNTIME_DIR"); dir != "" {
+		paths = append(paths, filepath.Join(dir, "podman", "podman.sock"))
+	}
+	return append(paths, "/run/podman/podman.sock")
+}
+
+func podmanEndpoint() (DaemonEndpoint, error) {
+	if host := os.Getenv("CONTAINER_HOST"); host != "" {
+		return DaemonEndpoint{Context: PodmanContext, Host: host}, nil
+	}
+	for _, path := range podmanSocketPaths() {
+		if _, err := os.Stat(path); err == nil {
+			return DaemonEndpoint{Context: PodmanContext, Host: "unix://" + path}, nil
+		}
+	}
+	return DaemonEndpoint{}, fmt.Errorf("podman socket not found (start it with `systemctl --user start podman.socket`)")
+}
+
+func dockerConfigDir() string {
+	if dir := os.Getenv("DOCKER_CONFIG"); dir != "" {
+		return dir
+	}
+	home, _ := os.UserHomeDir()
+	return filepath.Join(home, ".docker")
+}
+
+// currentDockerContext reads the context selected with `docker context use`.
+func currentDockerContext() string {
+	data, err := os.ReadFile(filepath.Join(dockerConfigDir(), "config.json"))
+	if err != nil {
+		return ""
+	}
+	var cfg struct {
+		CurrentContext string `json:"currentContext"`
+	}
+	if json.Unmarshal(data, &cfg) != nil {
+		return ""
+	}
+	return cfg.CurrentContext
+}
+
+// dockerContextEndpoint reads a context created with `docker context create`.
+// The CLI stores each one under the SHA-256 of its name.
+func dockerContextEndpoint(name string) (DaemonEndpoint, error) {
+	sum := sha256.Sum256([]byte(name))
+	id := hex.EncodeToString(sum[:])
+	contexts := filepath.Join(dockerConfigDir(), "contexts")
+
+	data, err := os.ReadFile(filepath.Join(contexts, "meta", id, "meta.json"))
+	if errors.Is(err, os.ErrNotExist) {
+		return DaemonEndpoint{}, fmt.Errorf("docker context %q not found", name)
+	}
+	if err != nil {
+		return DaemonEndpoint{}, fmt.Errorf("read docker context %q: %w", name, err)
+	}
+
+	var meta struct {
+		Endpoints map[string]struct {
+			Host string `json:"Host"`
+		} `json:"Endpoints"`
+	}
+	if err := json.Unmarshal(data, &meta); err != nil {
+		return DaemonEndpoint{}, fmt.Errorf("parse docker context %q: %w", name, err)
+	}
+	host := meta.Endpoints["docker"].Host
+	if host == "" {
+		return DaemonEndpoint{}, fmt.Errorf("docker context %q has no docker endpoint", name)
+	}
+	if strings.HasPrefix(host, "ssh://") {
+		return DaemonEndpoint{}, fmt.Errorf("docker context %q uses ssh, which is not supported; forward the socket and use a unix:// or tcp:// host", name)
+	}
+
+	ep := DaemonEndpoint{Context: name, Host: host}
+	tlsDir := filepath.Join(contexts, "tls", id, "docker")
+	if _, err := os.Stat(filepath.Join(tlsDir, "cert.pem")); err == nil {
+		ep.TLSDir = tlsDir
+	}
+	return ep, nil
+}
+
+// engineName tells Podman's Docker-compatible API apart from Docker's.
+func engineName(v types.Version) string {
+	if strings.Contains(v.Platform.Name, "Podman") {
+		return "podman"
+	}
+	for _, c := range v.Components {
+		if strings.Contains(c.Name, "Podman") {
+			return "podman"
+		}
+	}
+	return "docker"
+}
+
+func daemonLabel(engine string, endpoint DaemonEndpoint) string {
+	if name := endpoint.String(); name != engine && name != "" {
+		return engine + " · " + name
+	}
+	return engine
+}
@@ -0,0 +1,98 @@
+package infra
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/docker/docker/api/types"
+)
+
+// writeDockerContext creates a context the way `docker context create` does.
+func writeDockerContext(t *testing.T, configDir, name, host string) {
+	t.Helper()
+	sum := sha256.Sum256([]byte(name))
+	dir := filepath.Join(configDir, "contexts", "meta", hex.EncodeToString(sum[:]))
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	meta := `{"Name":"` + name + `","Endpoints":{"docker":{"Host":"` + host + `","SkipTLSVerify":false}}}`
+	if err := os.WriteFile(filepath.Join(dir, "meta.json"), []byte(meta), 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestResolveDaemon(t *testing.T) {
+	configDir := t.TempDir()
+	t.Setenv("DOCKER_CONFIG", configDir)
+	t.Setenv("DOCKER_HOST", "")
+	t.Setenv("DOCKER_CONTEXT", "")
+	t.Setenv("CONTAINER_HOST", "")
+	writeDockerContext(t, configDir, "build-box", "tcp://build-box:2376")
+	writeDockerContext(t, configDir, "laptop", "ssh://me@laptop")
+
+	ep, err := ResolveDaemon("build-box")
+	if err != nil {
+		t.Fatalf("ResolveDaemon failed: %v", err)
+	}
+	if ep.Context != "build-box" || ep.Host != "tcp://build-box:2376" {
+		t.Errorf("unexpected endpoint: %+v", ep)
+	}
+
+	if ep, _ := ResolveDaemon("tcp://10.0.0.5:2375"); ep.Host != "tcp://10.0.0.5:2375" || ep.String() != ep.Host {
+		t.Errorf("host URLs should pass through, got %+v", ep)
+	}
+	if _, err := ResolveDaemon("missing"); err == nil {
+		t.Error("expected error for unknown context")
+	}
+	if _, err := ResolveDaemon("laptop"); err == nil {
+		t.Error("expected error for ssh context")
+	}
+
+	// An empty name follows the CLI's current context, then DOCKER_HOST.
+	if ep, _ := ResolveDaemon(""); ep.Context != "default" {
+		t.Errorf("expected default context, got %+v", ep)
+	}
+	os.WriteFile(filepath.Join(configDir, "config.json"), []byte(`{"currentContext":"build-box"}`), 0o644)
+	if ep, _ := ResolveDaemon(""); ep.Context != "build-box" {
+		t.Errorf("expected current context, got %+v", ep)
+	}
+	t.Setenv("DOCKER_HOST", "unix:///tmp/docker.sock")
+	if ep, _ := ResolveDaemon(""); ep.Host != "unix:///tmp/docker.sock" {
+		t.Errorf("DOCKER_HOST should win, got %+v", ep)
+	}
+}
+
+func TestResolveDaemon_Podman(t *testing.T) {
+	runtimeDir := t.TempDir()
+	t.Setenv("XDG_RUNTIME_DIR", runtimeDir)
+	t.Setenv("CONTAINER_HOST", "")
+
+	socket := filepath.Join(runtimeDir, "podman", "podman.sock")
+	if _, err := os.Stat("/run/podman/podman.sock"); err != nil {
+		if _, err := ResolveDaemon(PodmanContext); err == nil {
+			t.Error("expected error with no podman socket")
+		}
+	}
+
+	os.MkdirAll(filepath.Dir(socket), 0o755)
+	os.WriteFile(socket, nil, 0o600)
+	ep, err := ResolveDaemon(PodmanContext)
+	if err != nil {
+		t.Fatalf("ResolveDaemon failed: %v", err)
+	}
+	if ep.Host != "unix://"+socket || ep.Context != PodmanContext {
+		t.Errorf("unexpected endpoint: %+v", ep)
+	}
+
+	var podman types.Version
+	podman.Components = []types.ComponentVersion{{Name: "Podman Engine"}}
+	if got := daemonLabel(engineName(podman), ep); got != "podman" {
+		t.Errorf("daemonLabel = %q, want podman", got)
+	}
+	if got := daemonLabel(engineName(types.Version{}), DaemonEndpoint{Context: "build-box"}); got != "docker · build-box" {
+		t.Errorf("daemonLabel = %q", got)
+	}
+}
@@ -0,0 +1,164 @@
+package infra
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/build"
+	"github.com/docker/docker/api/types/filters"
+	"github.com/docker/docker/api/types/image"
+	"github.com/docker/docker/api/types/volume"
+)
+
+// DiskKind is a category of `docker system df`.
+type DiskKind string
+
+const (
+	DiskImages     DiskKind = "images"
+	DiskContainers DiskKind = "containers"
+	DiskVolumes    DiskKind = "volumes"
+	DiskBuildCache DiskKind = "build cache"
+)
+
+// DiskKinds lists the categories in the order `docker system df` shows
+// them.
+var DiskKinds = []DiskKind{DiskImages, DiskContainers, DiskVolumes, DiskBuildCache}
+
+// DiskUsageCategory is the space taken by one kind of object. Reclaimable
+// is what pruning the category would free.
+type DiskUsageCategory struct {
+	Kind        DiskKind
+	Count       int
+	Active      int
+	Size        int64
+	Reclaimable int64
+}
+
+// DiskUsage is the daemon's disk usage, one category per DiskKinds entry.
+type DiskUsage struct {
+	Categories []DiskUsageCategory
+}
+
+// Total is the space taken by all categories.
+func (u DiskUsage) Total() int64 {
+	var total int64
+	for _, c := range u.Categories {
+		total += c.Size
+	}
+	return total
+}
+
+// Category returns the usage of kind; the zero value if it is missing.
+func (u DiskUsage) Category(kind DiskKind) DiskUsageCategory {
+	for _, c := range u.Categories {
+		if c.Kind == kind {
+			return c
+		}
+	}
+	return DiskUsageCategory{Kind: kind}
+}
+
+// summarizeDiskUsage counts sizes the way `docker system df` does: an
+// image is reclaimable when no container uses it, a container when it is
+// not running, a volume when nothing mounts it and build cache when it is
+// not in use.
+func summarizeDiskUsage(du types.DiskUsage) DiskUsage {
+	images := DiskUsageCategory{Kind: DiskImages, Size: du.LayersSize}
+	for _, img := range du.Images {
+		images.Count++
+		if img.Containers > 0 {
+			images.Active++
+			continue
+		}
+		size := img.Size
+		if img.SharedSize > 0 {
+			size -= img.SharedSize
+		}
+		images.Reclaimable += size
+	}
+
+	containers := DiskUsageCategory{Kind: DiskContainers}
+	for _, c := range du.Containers {
+		containers.Count++
+		containers.Size += c.SizeRw
+		if c.State == "running" {
+			containers.Active++
+		} else {
+			containers.Reclaimable += c.SizeRw
+		}
+	}
+
+	volumes := DiskUsageCategory{Kind: DiskVolumes}
+	for _, v := range du.Volumes {
+		volumes.Count++
+		if v.UsageData == nil || v.UsageData.Size < 0 {
+			continue
+		}
+		volumes.Size += v.UsageData.Size
+		if v.UsageData.RefCount > 0 {
+			volumes.Active++
+		} else {
+			volumes.Reclaimable += v.UsageData.Size
+		}
+	}
+
+	cache := DiskUsageCategory{Kind: DiskBuildCache}
+	for _, r := range du.BuildCache {
+		cache.Count++
+		if r.Shared {
+			continue
+		}
+		cache.Size += r.Size
+		if r.InUse {
+			cache.Active++
+		} else {
+			cache.Reclaimable += r.Size
+		}
+	}
+
+	return DiskUsage{Categories: []DiskUsageCategory{images, containers, volumes, cache}}
+}
+
+// DiskUsage returns how much space images, containers, volumes and build
+// cache take, like `docker system df`.
+func (d *DockerClient) DiskUsage(ctx context.Context) (DiskUsage, error) {
+	du, err := d.cli.DiskUsage(ctx, types.DiskUsageOptions{})
+	if err != nil {
+		return DiskUsage{}, fmt.Errorf("disk usage failed: %w", err)
+	}
+	return summarizeDiskUsage(du), nil
+}
+
+// Prune removes the reclaimable objects of kind and returns the space
+// freed: images no container uses (not only dangling ones), stopped
+// containers, volumes nothing mounts (named ones too) or unused build
+// cache.
+func (d *DockerClient) Prune(ctx context.Context, kind DiskKind) (uint64, error) {
+	var reclaimed uint64
+	var err error
+	switch kind {
+	case DiskImages:
+		var report image.PruneReport
+		report, err = d.cli.ImagesPrune(ctx, filters.NewArgs(filters.Arg("dangling", "false")))
+		reclaimed = report.SpaceReclaimed
+	case DiskContainers:
+		reclaimed, err = d.PruneContainers(ctx)
+	case DiskVolumes:
+		var report volume.PruneReport
+		report, err = d.cli.VolumesPrune(ctx, filters.NewArgs(filters.Arg("all", "true")))
+		reclaimed = report.SpaceReclaimed
+	case DiskBuildCache:
+		var report *build.CachePruneReport
+		report, err = d.cli.BuildCachePrune(ctx, build.CachePruneOptions{})
+		if report != nil {
+			reclaimed = report.SpaceReclaimed
+		}
+	default:
+		return 0, fmt.Errorf("prune %s: unknown category", kind)
+	}
+	if err != nil {
+		return 0, fmt.Errorf("prune %s failed: %w", kind, err)
+	}
+	return reclaimed, nil
+}
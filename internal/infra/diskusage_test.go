@@ -0,0 +1,78 @@
+package infra
+
+import (
+	"context"
+	"testing"
+
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/build"
+	"github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/api/types/image"
+	"github.com/docker/docker/api/types/volume"
+)
+
+func TestSummarizeDiskUsage(t *testing.T) {
+	u := summarizeDiskUsage(types.DiskUsage{
+		LayersSize: 1000,
+		Images: []*image.Summary{
+			{Size: 600, SharedSize: 100, Containers: 1},
+			{Size: 400, SharedSize: 100, Containers: 0},
+		},
+		Containers: []*container.Summary{
+			{SizeRw: 10, State: "running"},
+			{SizeRw: 30, State: "exited"},
+		},
+		Volumes: []*volume.Volume{
+			{UsageData: &volume.UsageData{Size: 200, RefCount: 1}},
+			{UsageData: &volume.UsageData{Size: 50, RefCount: 0}},
+			{UsageData: &volume.UsageData{Size: -1, RefCount: -1}},
+		},
+		BuildCache: []*build.CacheRecord{
+			{Size: 70, InUse: true},
+			{Size: 80},
+			{Size: 90, Shared: true},
+		},
+	})
+
+	want := map[DiskKind]DiskUsageCategory{
+		DiskImages:     {Kind: DiskImages, Count: 2, Active: 1, Size: 1000, Reclaimable: 300},
+		DiskContainers: {Kind: DiskContainers, Count: 2, Active: 1, Size: 40, Reclaimable: 30},
+		DiskVolumes:    {Kind: DiskVolumes, Count: 3, Active: 1, Size: 250, Reclaimable: 50},
+		DiskBuildCache: {Kind: DiskBuildCache, Count: 3, Active: 1, Size: 150, Reclaimable: 80},
+	}
+	for _, kind := range DiskKinds {
+		if got := u.Category(kind); got != want[kind] {
+			t.Errorf("%s = %+v, want %+v", kind, got, want[kind])
+		}
+	}
+	if u.Total() != 1440 {
+		t.Errorf("Total() = %d", u.Total())
+	}
+}
+
+func TestFakeDocker_Prune(t *testing.T) {
+	fake := NewFakeDocker(
+		ContainerInfo{ID: "a1", Name: "web", State: "running"},
+		ContainerInfo{ID: "b2", Name: "old", State: "exited"},
+	)
+	fake.Disk = DiskUsage{Categories: []DiskUsageCategory{
+		{Kind: DiskContainers, Count: 2, Active: 1, Size: 40, Reclaimable: 30},
+	}}
+	ctx := context.Background()
+
+	reclaimed, err := fake.Prune(ctx, DiskContainers)
+	if err != nil || reclaimed != 30 {
+		t.Fatalf("Prune = %d, %v", reclaimed, err)
+	}
+	if len(fake.Containers) != 1 || fake.Containers[0].ID != "a1" {
+		t.Errorf("expected only the running container left, got %+v", fake.Containers)
+	}
+	u, _ := fake.DiskUsage(ctx)
+	if c := u.Category(DiskContainers); c.Size != 10 || c.Reclaimable != 0 || c.Count != 1 {
+		t.Errorf("usage after prune = %+v", c)
+	}
+
+	if _, err := fake.Prune(ctx, "networks"); err == nil {
+		t.Error("expected an error for an unknown category")
+	}
+}
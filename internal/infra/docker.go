@@ -5,12 +5,17 @@ import (
 	"encoding/json"
 	"fmt"
 	"io"
+	"path/filepath"
+	"slices"
 	"strings"
+	"sync"
 	"time"
 
+	"github.com/docker/docker/api/types"
 	"github.com/docker/docker/api/types/container"
 	"github.com/docker/docker/api/types/filters"
 	"github.com/docker/docker/api/types/image"
+	"github.com/docker/docker/api/types/mount"
 	"github.com/docker/docker/api/types/volume"
 	"github.com/docker/docker/client"
 )
@@ -23,6 +28,12 @@ type ContainerInfo struct {
 	State   string
 	Ports   []PortMapping
 	Created time.Time
+	Labels  map[string]string
+
+	// Health is the healthcheck status ("starting", "healthy" or
+	// "unhealthy"); empty when the container has no healthcheck.
+	Health       string
+	RestartCount int
 }
 
 type PortMapping struct {
@@ -39,6 +50,11 @@ type ContainerDetail struct {
 	NetworkID string
 	Cmd       []string
 	Uptime    string
+
+	// Limits are the memory and CPU limits; OOMKilled tells whether the
+	// last exit was the kernel killing it for going over the memory limit.
+	Limits    ResourceLimits
+	OOMKilled bool
 }
 
 type Mount struct {
@@ -73,6 +89,12 @@ type VolumeInfo struct {
 	Driver     string
 	Mountpoint string
 	CreatedAt  time.Time
+	// Size is the space the volume takes, or -1 when the daemon can't
+	// tell (volume drivers other than local).
+	Size int64
+	// Containers names the containers that mount the volume, running or
+	// not.
+	Containers []string
 }
 
 type ProcessInfo struct {
@@ -88,18 +110,60 @@ type DockerHealth struct {
 	Version    string
 	Containers []ContainerInfo
 	Error      error
+	// Daemon names the engine and endpoint, e.g. "docker · default" or
+	// "podman"; empty when unknown.
+	Daemon string
 }
 
 type DockerClient struct {
-	cli *client.Client
+	cli      *client.Client
+	endpoint DaemonEndpoint
+
+	// inspected remembers the restart count of each container, so
+	// CheckHealth only inspects containers whose state or health changed.
+	inspectMu sync.Mutex
+	inspected map[string]inspectedHealth
 }
 
+// inspectedHealth is what inspecting a container told CheckHealth, and the
+// state and health it had in the listing then.
+type inspectedHealth struct {
+	key      string
+	health   string
+	restarts int
+}
+
+// NewDockerClient connects to the daemon the docker CLI would use.
 func NewDockerClient() (*DockerClient, error) {
-	cli, err := client.NewClientWithOpts(client.FromEnv, client.WithAPIVersionNegotiation())
+	return NewDockerClientForContext("")
+}
+
+// NewDockerClientForContext connects to the daemon named by a docker
+// context, PodmanContext or a host URL; see ResolveDaemon.
+func NewDockerClientForContext(name string) (*DockerClient, error) {
+	endpoint, err := ResolveDaemon(name)
 	if err != nil {
 		return nil, fmt.Errorf("docker client failed: %w", err)
 	}
-	return &DockerClient{cli: cli}, nil
+
+	opts := []client.Opt{client.FromEnv, client.WithHost(endpoint.Host), client.WithAPIVersionNegotiation()}
+	if endpoint.TLSDir != "" {
+		opts = append(opts, client.WithTLSClientConfig(
+			filepath.Join(endpoint.TLSDir, "ca.pem"),
+			filepath.Join(endpoint.TLSDir, "cert.pem"),
+			filepath.Join(endpoint.TLSDir, "key.pem"),
+		))
+	}
+	cli, err := client.NewClientWithOpts(opts...)
+	if err != nil {
+		return nil, fmt.Errorf("docker client failed: %w", err)
+	}
+	return &DockerClient{cli: cli, endpoint: endpoint, inspected: make(map[string]inspectedHealth)}, nil
+}
+
+// Endpoint returns the daemon this client talks to.
+func (d *DockerClient) Endpoint() DaemonEndpoint {
+	return d.endpoint
 }
 
 func (d *DockerClient) CheckHealth(ctx context.Context) DockerHealth {
@@ -120,6 +184,7 @@ func (d *DockerClient) CheckHealth(ctx context.Context) DockerHealth {
 		return health
 	}
 	health.Version = version.Version
+	health.Daemon = daemonLabel(engineName(version), d.endpoint)
 
 	containers, err := d.cli.ContainerList(checkCtx, container.ListOptions{All: true})
 	if err != nil {
@@ -154,9 +219,32 @@ func (d *DockerClient) CheckHealth(ctx context.Context) DockerHealth {
 			State:   c.State,
 			Ports:   ports,
 			Created: time.Unix(c.Created, 0),
+			Labels:  c.Labels,
 		})
 	}
 
+	// The listing's status carries the health but not the restart count;
+	// inspect only the containers that are new or whose state or health
+	// changed since the last check.
+	d.inspectMu.Lock()
+	seen := make(map[string]inspectedHealth, len(health.Containers))
+	for i := range health.Containers {
+		c := &health.Containers[i]
+		c.Health = statusHealth(c.Status)
+		key := c.State + "|" + c.Health
+		if prev, ok := d.inspected[c.ID]; ok && prev.key == key {
+			c.Health, c.RestartCount = prev.health, prev.restarts
+			seen[c.ID] = prev
+			continue
+		}
+		if info, err := d.cli.ContainerInspect(checkCtx, c.ID); err == nil {
+			c.Health, c.RestartCount = inspectHealth(info)
+			seen[c.ID] = inspectedHealth{key: key, health: c.Health, restarts: c.RestartCount}
+		}
+	}
+	d.inspected = seen
+	d.inspectMu.Unlock()
+
 	health.Available = true
 	return health
 }
@@ -251,45 +339,79 @@ func (d *DockerClient) GetContainerStats(ctx context.Context, containerID string
 	}
 	defer stats.Body.Close()
 
-	// Define inline struct matching Docker stats JSON response
-	var v struct {
-		CPUStats struct {
-			CPUUsage struct {
-				TotalUsage uint64 `json:"total_usage"`
-			} `json:"cpu_usage"`
-			SystemUsage uint64 `json:"system_cpu_usage"`
-			OnlineCPUs  uint64 `json:"online_cpus"`
-		} `json:"cpu_stats"`
-		PreCPUStats struct {
-			CPUUsage struct {
-				TotalUsage uint64 `json:"total_usage"`
-			} `json:"cpu_usage"`
-			SystemUsage uint64 `json:"system_cpu_usage"`
-		} `json:"precpu_stats"`
-		MemoryStats struct {
-			Usage uint64            `json:"usage"`
-			Limit uint64            `json:"limit"`
-			Stats map[string]uint64 `json:"stats"`
-		} `json:"memory_stats"`
-		Networks map[string]struct {
-			RxBytes uint64 `json:"rx_bytes"`
-			TxBytes uint64 `json:"tx_bytes"`
-		} `json:"networks"`
-		BlkioStats struct {
-			IoServiceBytesRecursive []struct {
-				Op    string `json:"op"`
-				Value uint64 `json:"value"`
-			} `json:"io_service_bytes_recursive"`
-		} `json:"blkio_stats"`
-		PidsStats struct {
-			Current uint64 `json:"current"`
-		} `json:"pids_stats"`
-	}
-
+	var v statsFrame
 	if err := json.NewDecoder(stats.Body).Decode(&v); err != nil {
 		return nil, fmt.Errorf("decode stats failed: %w", err)
 	}
+	return v.snapshot(), nil
+}
+
+// StreamContainerStats keeps one stats connection open and sends a snapshot
+// for every frame the daemon pushes (about one per second). The channel is
+// closed when ctx is done or the stream ends.
+func (d *DockerClient) StreamContainerStats(ctx context.Context, containerID string) (<-chan ContainerStatsSnapshot, error) {
+	stats, err := d.cli.ContainerStats(ctx, containerID, true)
+	if err != nil {
+		return nil, fmt.Errorf("stream stats failed: %w", err)
+	}
+
+	ch := make(chan ContainerStatsSnapshot)
+	go func() {
+		defer close(ch)
+		defer stats.Body.Close()
 
+		dec := json.NewDecoder(stats.Body)
+		for {
+			var v statsFrame
+			if err := dec.Decode(&v); err != nil {
+				return
+			}
+			select {
+			case ch <- *v.snapshot():
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return ch, nil
+}
+
+// statsFrame matches one frame of the Docker stats JSON response.
+type statsFrame struct {
+	CPUStats struct {
+		CPUUsage struct {
+			TotalUsage uint64 `json:"total_usage"`
+		} `json:"cpu_usage"`
+		SystemUsage uint64 `json:"system_cpu_usage"`
+		OnlineCPUs  uint64 `json:"online_cpus"`
+	} `json:"cpu_stats"`
+	PreCPUStats struct {
+		CPUUsage struct {
+			TotalUsage uint64 `json:"total_usage"`
+		} `json:"cpu_usage"`
+		SystemUsage uint64 `json:"system_cpu_usage"`
+	} `json:"precpu_stats"`
+	MemoryStats struct {
+		Usage uint64            `json:"usage"`
+		Limit uint64            `json:"limit"`
+		Stats map[string]uint64 `json:"stats"`
+	} `json:"memory_stats"`
+	Networks map[string]struct {
+		RxBytes uint64 `json:"rx_bytes"`
+		TxBytes uint64 `json:"tx_bytes"`
+	} `json:"networks"`
+	BlkioStats struct {
+		IoServiceBytesRecursive []struct {
+			Op    string `json:"op"`
+			Value uint64 `json:"value"`
+		} `json:"io_service_bytes_recursive"`
+	} `json:"blkio_stats"`
+	PidsStats struct {
+		Current uint64 `json:"current"`
+	} `json:"pids_stats"`
+}
+
+func (v *statsFrame) snapshot() *ContainerStatsSnapshot {
 	snapshot := &ContainerStatsSnapshot{
 		Timestamp: time.Now(),
 		PIDs:      v.PidsStats.Current,
@@ -325,7 +447,7 @@ func (d *DockerClient) GetContainerStats(ctx context.Context, containerID string
 		}
 	}
 
-	return snapshot, nil
+	return snapshot
 }
 
 func (d *DockerClient) InspectContainer(ctx context.Context, containerID string) (*ContainerDetail, error) {
@@ -354,6 +476,10 @@ func (d *DockerClient) InspectContainer(ctx context.Context, containerID string)
 		Cmd:     info.Config.Cmd,
 	}
 
+	detail.Health, detail.RestartCount = inspectHealth(info)
+	detail.Limits = inspectLimits(info)
+	detail.OOMKilled = info.State.OOMKilled
+
 	if info.State.Running {
 		startTime, _ := time.Parse(time.RFC3339Nano, info.State.StartedAt)
 		detail.Uptime = time.Since(startTime).Round(time.Second).String()
@@ -374,6 +500,10 @@ func (d *DockerClient) InspectContainer(ctx context.Context, containerID string)
 	}
 
 	for portProto, bindings := range info.NetworkSettings.Ports {
+		// An exposed port that isn't published has no bindings.
+		if len(bindings) == 0 {
+			detail.Ports = append(detail.Ports, PortMapping{Private: uint16(portProto.Int()), Protocol: portProto.Proto()})
+		}
 		for _, b := range bindings {
 			var publicPort uint16
 			if b.HostPort != "" {
@@ -391,6 +521,33 @@ func (d *DockerClient) InspectContainer(ctx context.Context, containerID string)
 	return detail, nil
 }
 
+// inspectHealth returns the healthcheck status and restart count of an
+// inspected container.
+// statusHealth reads the healthcheck state from a listing's status, e.g.
+// "Up 2 minutes (healthy)" or "Up 3 seconds (health: starting)"; empty when
+// the container has no healthcheck.
+func statusHealth(status string) string {
+	switch {
+	case strings.HasSuffix(status, "(healthy)"):
+		return "healthy"
+	case strings.HasSuffix(status, "(unhealthy)"):
+		return "unhealthy"
+	case strings.HasSuffix(status, "(health: starting)"):
+		return "starting"
+	}
+	return ""
+}
+
+func inspectHealth(info container.InspectResponse) (health string, restarts int) {
+	if info.ContainerJSONBase == nil {
+		return "", 0
+	}
+	if info.State != nil && info.State.Health != nil && info.State.Health.Status != container.NoHealthcheck {
+		health = string(info.State.Health.Status)
+	}
+	return health, info.RestartCount
+}
+
 func (d *DockerClient) ListImages(ctx context.Context) ([]ImageInfo, error) {
 	images, err := d.cli.ImageList(ctx, image.ListOptions{})
 	if err != nil {
@@ -427,56 +584,117 @@ func (d *DockerClient) ListVolumes(ctx context.Context) ([]VolumeInfo, error) {
 		return nil, fmt.Errorf("list volumes failed: %w", err)
 	}
 
+	// The listing has neither sizes nor users: sizes come from
+	// `docker system df`, users from the containers' mounts. Both are best
+	// effort.
+	sizes := make(map[string]int64)
+	if du, err := d.cli.DiskUsage(ctx, types.DiskUsageOptions{Types: []types.DiskUsageObject{types.VolumeObject}}); err == nil {
+		for _, vol := range du.Volumes {
+			if vol.UsageData != nil {
+				sizes[vol.Name] = vol.UsageData.Size
+			}
+		}
+	}
+	containers, _ := d.cli.ContainerList(ctx, container.ListOptions{All: true})
+	users := volumeUsers(containers)
+
 	var result []VolumeInfo
 	for _, vol := range volumes.Volumes {
 		createdAt, _ := time.Parse(time.RFC3339, vol.CreatedAt)
+		size, ok := sizes[vol.Name]
+		if !ok {
+			size = -1
+		}
 		result = append(result, VolumeInfo{
 			Name:       vol.Name,
 			Driver:     vol.Driver,
 			Mountpoint: vol.Mountpoint,
 			CreatedAt:  createdAt,
+			Size:       size,
+			Containers: users[vol.Name],
 		})
 	}
 	return result, nil
 }
 
+// volumeUsers maps a volume name to the names of the containers mounting
+// it.
+func volumeUsers(containers []container.Summary) map[string][]string {
+	users := make(map[string][]string)
+	for _, c := range containers {
+		name := c.ID
+		if len(c.Names) > 0 {
+			name = strings.TrimPrefix(c.Names[0], "/")
+		}
+		for _, mnt := range c.Mounts {
+			if mnt.Type == mount.TypeVolume && !slices.Contains(users[mnt.Name], name) {
+				users[mnt.Name] = append(users[mnt.Name], name)
+			}
+		}
+	}
+	return users
+}
+
+// RemoveVolume deletes a volume and its files. The daemon refuses while a
+// container, running or not, mounts it, force or not.
 func (d *DockerClient) RemoveVolume(ctx context.Context, volumeName string, force bool) error {
 	return d.cli.VolumeRemove(ctx, volumeName, force)
 }
 
+// topArgs are the ps options TopContainer asks for, to get each process's
+// CPU and memory share along with its full command line.
+var topArgs = []string{"-eo", "pid,user,pcpu,pmem,args"}
+
+// TopContainer lists the processes of a running container, like `docker
+// top`. Their PIDs are the host's, not the container's.
 func (d *DockerClient) TopContainer(ctx context.Context, containerID string) ([]ProcessInfo, error) {
-	top, err := d.cli.ContainerTop(ctx, containerID, []string{})
+	top, err := d.cli.ContainerTop(ctx, containerID, topArgs)
+	if err != nil {
+		// Daemons that can't pass ps options (Windows) still list with
+		// the defaults.
+		top, err = d.cli.ContainerTop(ctx, containerID, nil)
+	}
 	if err != nil {
 		return nil, fmt.Errorf("top failed: %w", err)
 	}
+	return parseTop(top), nil
+}
 
-	pidIdx, userIdx, cmdIdx := -1, -1, -1
+// parseTop reads the columns of a `docker top` listing by their titles.
+func parseTop(top container.TopResponse) []ProcessInfo {
+	pidIdx, userIdx, cpuIdx, memIdx, cmdIdx := -1, -1, -1, -1, -1
 	for i, title := range top.Titles {
 		switch title {
 		case "PID":
 			pidIdx = i
-		case "USER":
+		case "USER", "UID":
 			userIdx = i
+		case "%CPU", "C":
+			cpuIdx = i
+		case "%MEM":
+			memIdx = i
 		case "CMD", "COMMAND":
 			cmdIdx = i
 		}
 	}
 
+	column := func(proc []string, i int) string {
+		if i >= 0 && i < len(proc) {
+			return proc[i]
+		}
+		return ""
+	}
 	var result []ProcessInfo
 	for _, proc := range top.Processes {
-		info := ProcessInfo{}
-		if pidIdx >= 0 && pidIdx < len(proc) {
-			info.PID = proc[pidIdx]
-		}
-		if userIdx >= 0 && userIdx < len(proc) {
-			info.User = proc[userIdx]
-		}
-		if cmdIdx >= 0 && cmdIdx < len(proc) {
-			info.Command = proc[cmdIdx]
-		}
-		result = append(result, info)
+		result = append(result, ProcessInfo{
+			PID:     column(proc, pidIdx),
+			User:    column(proc, userIdx),
+			CPU:     column(proc, cpuIdx),
+			Memory:  column(proc, memIdx),
+			Command: column(proc, cmdIdx),
+		})
 	}
-	return result, nil
+	return result
 }
 
 func (d *DockerClient) PruneContainers(ctx context.Context) (uint64, error) {
@@ -573,6 +791,43 @@ func (d *DockerClient) StreamLogsToWriter(ctx context.Context, containerID strin
 	}
 }
 
+// FollowContainerLogs streams log lines written from now on, in the same
+// timestamped form GetContainerLogs returns. The channel is closed when ctx
+// is done or the container stops.
+func (d *DockerClient) FollowContainerLogs(ctx context.Context, containerID string) (<-chan string, error) {
+	info, err := d.cli.ContainerInspect(ctx, containerID)
+	if err != nil {
+		return nil, fmt.Errorf("follow logs: %w", err)
+	}
+
+	reader, err := d.cli.ContainerLogs(ctx, containerID, container.LogsOptions{
+		ShowStdout: true,
+		ShowStderr: true,
+		Follow:     true,
+		Timestamps: true,
+		Tail:       "0",
+	})
+	if err != nil {
+		return nil, fmt.Errorf("follow logs: %w", err)
+	}
+
+	tty := info.Config != nil && info.Config.Tty
+	ch := make(chan string)
+	go func() {
+		defer close(ch)
+		defer reader.Close()
+		demuxLogLines(reader, tty, func(_, line string) bool {
+			select {
+			case ch <- line:
+				return true
+			case <-ctx.Done():
+				return false
+			}
+		})
+	}()
+	return ch, nil
+}
+
 // StreamLogsWithSnapshots streams logs and captures GPU/container stats at intervals.
 func (d *DockerClient) StreamLogsWithSnapshots(ctx context.Context, containerID string, containerName string, sink LogSink, gpu GPUProvider, snapshotInterval time.Duration) error {
 	options := container.LogsOptions{
@@ -591,6 +846,65 @@ func (d *DockerClient) StreamLogsWithSnapshots(ctx context.Context, containerID
 	return d.processLogStream(ctx, reader, containerName, sink, gpu, &snapshotInterval)
 }
 
+// TailLogs returns the log lines written after since, or the last tail
+// lines when since is zero. With follow > 0 it keeps collecting new lines
+// for that long, so a caller can page through a live container by passing
+// the last timestamp it saw back as since.
+func (d *DockerClient) TailLogs(ctx context.Context, containerID string, since time.Time, tail int, follow time.Duration) ([]LogEntry, error) {
+	options := container.LogsOptions{
+		ShowStdout: true,
+		ShowStderr: true,
+		Follow:     follow > 0,
+		Timestamps: true,
+		Tail:       fmt.Sprintf("%d", tail),
+	}
+	if !since.IsZero() {
+		options.Since = fmt.Sprintf("%d.%09d", since.Unix(), since.Nanosecond())
+		options.Tail = "all"
+	}
+
+	readCtx := ctx
+	if follow > 0 {
+		var cancel context.CancelFunc
+		readCtx, cancel = context.WithTimeout(ctx, follow)
+		defer cancel()
+	}
+
+	reader, err := d.cli.ContainerLogs(readCtx, containerID, options)
+	if err != nil {
+		return nil, fmt.Errorf("tail logs: %w", err)
+	}
+	defer reader.Close()
+
+	sink := &collectSink{}
+	err = d.processLogStream(readCtx, reader, containerID, sink, nil, nil)
+	if err != nil && ctx.Err() == nil && readCtx.Err() != nil {
+		// The follow window ended; that is the normal way out.
+		err = nil
+	}
+
+	// Docker's since filter is inclusive; drop what the caller already has.
+	entries := sink.entries[:0]
+	for _, e := range sink.entries {
+		if since.IsZero() || e.Timestamp.After(since) {
+			entries = append(entries, e)
+		}
+	}
+	return entries, err
+}
+
+// collectSink keeps log entries in memory.
+type collectSink struct {
+	entries []LogEntry
+}
+
+func (s *collectSink) Write(entry LogEntry) error {
+	s.entries = append(s.entries, entry)
+	return nil
+}
+
+func (s *collectSink) Close() error { return nil }
+
 // processLogStream handles the common log processing logic.
 func (d *DockerClient) processLogStream(ctx context.Context, reader io.ReadCloser, containerName string, sink LogSink, gpu GPUProvider, snapshotInterval *time.Duration) error {
 	buf := make([]byte, 8192)
@@ -0,0 +1,800 @@
+package infra
+
+import (
+	"archive/tar"
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/docker/go-connections/nat"
+)
+
+// DockerAPI is the subset of the Docker client used by the TUI and tools.
+// It lets callers swap the real daemon for FakeDocker in tests and demos.
+type DockerAPI interface {
+	CheckHealth(ctx context.Context) DockerHealth
+	GetContainerLogs(ctx context.Context, containerID string, tail int) ([]string, error)
+	FollowContainerLogs(ctx context.Context, containerID string) (<-chan string, error)
+	RunContainer(ctx context.Context, spec RunSpec) (string, error)
+	StartContainer(ctx context.Context, containerID string) error
+	StopContainer(ctx context.Context, containerID string) error
+	RestartContainer(ctx context.Context, containerID string) error
+	RemoveContainer(ctx context.Context, containerID string, force bool) error
+	GetContainerStats(ctx context.Context, containerID string) (*ContainerStatsSnapshot, error)
+	StreamContainerStats(ctx context.Context, containerID string) (<-chan ContainerStatsSnapshot, error)
+	InspectContainer(ctx context.Context, containerID string) (*ContainerDetail, error)
+	UpdateResources(ctx context.Context, containerID string, limits ResourceLimits) error
+	ListImages(ctx context.Context) ([]ImageInfo, error)
+	PullImage(ctx context.Context, ref string, report func(PullProgress)) error
+	ImageHistory(ctx context.Context, imageID string) ([]ImageLayer, error)
+	RemoveImage(ctx context.Context, imageID string, force bool) error
+	ListVolumes(ctx context.Context) ([]VolumeInfo, error)
+	RemoveVolume(ctx context.Context, volumeName string, force bool) error
+	BackupVolume(ctx context.Context, volume, dir string) (string, error)
+	RestoreVolume(ctx context.Context, volume, backup string) error
+	DiskUsage(ctx context.Context) (DiskUsage, error)
+	Prune(ctx context.Context, kind DiskKind) (uint64, error)
+	TopContainer(ctx context.Context, containerID string) ([]ProcessInfo, error)
+	SignalProcess(ctx context.Context, containerID, pid, signal string) error
+	ContainerExec(containerID string, cmd ...string) ExecCommand
+	ListContainerDir(ctx context.Context, containerID, dir string) ([]ContainerFile, error)
+	CopyFromContainer(ctx context.Context, containerID, src, dst string) error
+	CopyToContainer(ctx context.Context, containerID, src, dst string) error
+	Close() error
+}
+
+var _ DockerAPI = (*DockerClient)(nil)
+
+// FakeDocker is an in-memory DockerAPI backed by scripted containers.
+// Lifecycle calls mutate container state so UI flows can be exercised
+// without a daemon.
+type FakeDocker struct {
+	mu         sync.Mutex
+	Version    string
+	Daemon     string
+	Containers []ContainerInfo
+	Logs       map[string][]string
+	Stats      map[string]ContainerStatsSnapshot
+	Images     []ImageInfo
+	Volumes    []VolumeInfo
+	Processes  map[string][]ProcessInfo
+	// Execs records the command of every exec session that was run.
+	Execs []string
+	// Files maps a container ID to the contents of the files in it, by
+	// absolute path; directories are implied by the paths.
+	Files map[string]map[string]string
+	// Details maps a container ID to what only inspecting it tells: its
+	// environment, mounts, command and network.
+	Details map[string]ContainerDetail
+	// Limits maps a container ID to its resource limits.
+	Limits map[string]ResourceLimits
+	// Disk is what DiskUsage reports; Prune frees a category's
+	// reclaimable space.
+	Disk DiskUsage
+	// History maps an image ID or tag to its layers, newest first.
+	History map[string][]ImageLayer
+	// VolumeData maps a volume name to the contents of its files, by path
+	// relative to the volume root.
+	VolumeData map[string]map[string]string
+
+	// Err, when set, makes every call fail as if the daemon were down.
+	Err error
+}
+
+var _ DockerAPI = (*FakeDocker)(nil)
+
+// FakeStatsInterval paces FakeDocker's stats stream.
+var FakeStatsInterval = time.Second
+
+// FakeLogPollInterval is how often a FakeDocker log follower checks for
+// lines added with AppendLogs.
+var FakeLogPollInterval = 50 * time.Millisecond
+
+func NewFakeDocker(containers ...ContainerInfo) *FakeDocker {
+	return &FakeDocker{
+		Version:    "fake",
+		Containers: containers,
+		Logs:       make(map[string][]string),
+		Stats:      make(map[string]ContainerStatsSnapshot),
+		Processes:  make(map[string][]ProcessInfo),
+		Files:      make(map[string]map[string]string),
+		History:    make(map[string][]ImageLayer),
+		Limits:     make(map[string]ResourceLimits),
+		Details:    make(map[string]ContainerDetail),
+		VolumeData: make(map[string]map[string]string),
+	}
+}
+
+func (f *FakeDocker) CheckHealth(ctx context.Context) DockerHealth {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.Err != nil {
+		return DockerHealth{Error: fmt.Errorf("daemon unavailable: %w", f.Err)}
+	}
+	containers := make([]ContainerInfo, len(f.Containers))
+	copy(containers, f.Containers)
+	return DockerHealth{Available: true, Version: f.Version, Containers: containers, Daemon: f.Daemon}
+}
+
+func (f *FakeDocker) GetContainerLogs(ctx context.Context, containerID string, tail int) ([]string, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if _, err := f.find(containerID); err != nil {
+		return nil, fmt.Errorf("get logs failed: %w", err)
+	}
+	lines := f.Logs[containerID]
+	if tail > 0 && len(lines) > tail {
+		lines = lines[len(lines)-tail:]
+	}
+	out := make([]string, len(lines))
+	copy(out, lines)
+	return out, nil
+}
+
+// FollowContainerLogs sends every line appended to the container's logs
+// after the call, until ctx is done.
+func (f *FakeDocker) FollowContainerLogs(ctx context.Context, containerID string) (<-chan string, error) {
+	f.mu.Lock()
+	_, err := f.find(containerID)
+	seen := len(f.Logs[containerID])
+	f.mu.Unlock()
+	if err != nil {
+		return nil, fmt.Errorf("follow logs: %w", err)
+	}
+
+	ch := make(chan string)
+	go func() {
+		defer close(ch)
+		ticker := time.NewTicker(FakeLogPollInterval)
+		defer ticker.Stop()
+		for {
+			f.mu.Lock()
+			lines := f.Logs[containerID]
+			fresh := append([]string(nil), lines[min(seen, len(lines)):]...)
+			seen = len(lines)
+			f.mu.Unlock()
+
+			for _, line := range fresh {
+				select {
+				case ch <- line:
+				case <-ctx.Done():
+					return
+				}
+			}
+			select {
+			case <-ticker.C:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return ch, nil
+}
+
+// AppendLogs adds lines to a container's logs, as if it had written them.
+func (f *FakeDocker) AppendLogs(containerID string, lines ...string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.Logs[containerID] = append(f.Logs[containerID], lines...)
+}
+
+// RunContainer adds a running container for spec. Like the daemon, it
+// refuses images that are not present and names that are taken.
+func (f *FakeDocker) RunContainer(ctx context.Context, spec RunSpec) (string, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.Err != nil {
+		return "", f.Err
+	}
+	found := false
+	for _, img := range f.Images {
+		for _, tag := range img.Tags {
+			found = found || tag == spec.Image
+		}
+	}
+	if !found {
+		return "", fmt.Errorf("create container failed: no such image: %s", spec.Image)
+	}
+
+	id := fmt.Sprintf("f%011x", len(f.Containers)+1)
+	name := spec.Name
+	if name == "" {
+		name = "fake_" + id
+	}
+	if _, err := f.find(name); err == nil {
+		return "", fmt.Errorf("create container failed: name %q is already in use", name)
+	}
+
+	var ports []PortMapping
+	_, bindings, _ := nat.ParsePortSpecs(spec.Ports)
+	for port, binds := range bindings {
+		for _, b := range binds {
+			public, _ := strconv.Atoi(b.HostPort)
+			ports = append(ports, PortMapping{Private: uint16(port.Int()), Public: uint16(public), Protocol: port.Proto(), HostIP: b.HostIP})
+		}
+	}
+	f.Containers = append(f.Containers, ContainerInfo{
+		ID: id, Name: name, Image: spec.Image, State: "running", Status: "Up Less than a second",
+		Ports: ports, Created: time.Now(),
+	})
+	return id, nil
+}
+
+func (f *FakeDocker) StartContainer(ctx context.Context, containerID string) error {
+	return f.setState(containerID, "running", "Up Less than a second")
+}
+
+func (f *FakeDocker) StopContainer(ctx context.Context, containerID string) error {
+	return f.setState(containerID, "exited", "Exited (0) Less than a second ago")
+}
+
+func (f *FakeDocker) RestartContainer(ctx context.Context, containerID string) error {
+	return f.setState(containerID, "running", "Up Less than a second")
+}
+
+func (f *FakeDocker) RemoveContainer(ctx context.Context, containerID string, force bool) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	i, err := f.find(containerID)
+	if err != nil {
+		return err
+	}
+	if f.Containers[i].State == "running" && !force {
+		return fmt.Errorf("cannot remove running container %s", containerID)
+	}
+	f.Containers = append(f.Containers[:i], f.Containers[i+1:]...)
+	delete(f.Logs, containerID)
+	return nil
+}
+
+func (f *FakeDocker) GetContainerStats(ctx context.Context, containerID string) (*ContainerStatsSnapshot, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if _, err := f.find(containerID); err != nil {
+		return nil, err
+	}
+	stats := f.Stats[containerID]
+	stats.Timestamp = time.Now()
+	return &stats, nil
+}
+
+// StreamContainerStats sends the scripted snapshot for the container once
+// per FakeStatsInterval until ctx is done, so callers see a live stream.
+func (f *FakeDocker) StreamContainerStats(ctx context.Context, containerID string) (<-chan ContainerStatsSnapshot, error) {
+	f.mu.Lock()
+	_, err := f.find(containerID)
+	f.mu.Unlock()
+	if err != nil {
+		return nil, fmt.Errorf("stream stats failed: %w", err)
+	}
+
+	ch := make(chan ContainerStatsSnapshot)
+	go func() {
+		defer close(ch)
+		ticker := time.NewTicker(FakeStatsInterval)
+		defer ticker.Stop()
+		for {
+			f.mu.Lock()
+			stats := f.Stats[containerID]
+			f.mu.Unlock()
+			stats.Timestamp = time.Now()
+
+			select {
+			case ch <- stats:
+			case <-ctx.Done():
+				return
+			}
+			select {
+			case <-ticker.C:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return ch, nil
+}
+
+func (f *FakeDocker) InspectContainer(ctx context.Context, containerID string) (*ContainerDetail, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	i, err := f.find(containerID)
+	if err != nil {
+		return nil, err
+	}
+	c := f.Containers[i]
+	detail := f.Details[c.ID]
+	detail.ContainerInfo = c
+	detail.Uptime = c.Status
+	detail.Limits = f.Limits[c.ID]
+	return &detail, nil
+}
+
+// UpdateResources records the new limits; zero fields are left unchanged.
+func (f *FakeDocker) UpdateResources(ctx context.Context, containerID string, limits ResourceLimits) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	i, err := f.find(containerID)
+	if err != nil {
+		return fmt.Errorf("update failed: %w", err)
+	}
+	id := f.Containers[i].ID
+	current := f.Limits[id]
+	if limits.Memory > 0 {
+		current.Memory = limits.Memory
+	}
+	if limits.NanoCPUs > 0 {
+		current.NanoCPUs = limits.NanoCPUs
+	}
+	f.Limits[id] = current
+	return nil
+}
+
+func (f *FakeDocker) ListImages(ctx context.Context) ([]ImageInfo, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.Err != nil {
+		return nil, f.Err
+	}
+	images := make([]ImageInfo, len(f.Images))
+	copy(images, f.Images)
+	sort.Slice(images, func(i, j int) bool { return images[i].Created.After(images[j].Created) })
+	return images, nil
+}
+
+// PullImage reports two layers downloading to completion and adds the
+// image if it is not already present.
+func (f *FakeDocker) PullImage(ctx context.Context, ref string, report func(PullProgress)) error {
+	f.mu.Lock()
+	err := f.Err
+	f.mu.Unlock()
+	if err != nil {
+		return fmt.Errorf("pull %s: %w", ref, err)
+	}
+
+	progress := PullProgress{Ref: ref, Status: "Pulling from " + ref}
+	report(progress)
+	for _, current := range []int64{512, 1024} {
+		progress.Layers = []LayerProgress{
+			{ID: "layer1", Status: "Downloading", Current: current, Total: 1024},
+			{ID: "layer2", Status: "Downloading", Current: current * 2, Total: 2048},
+		}
+		report(progress.snapshot())
+	}
+	for i := range progress.Layers {
+		progress.Layers[i].Status = "Pull complete"
+	}
+	progress.Status = "Status: Downloaded newer image for " + ref
+	report(progress.snapshot())
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for _, img := range f.Images {
+		for _, tag := range img.Tags {
+			if tag == ref {
+				return nil
+			}
+		}
+	}
+	f.Images = append(f.Images, ImageInfo{ID: "sha256:" + ref, Tags: []string{ref}, Size: 3072, Created: time.Now()})
+	return nil
+}
+
+// ImageHistory returns the scripted layers of an image, found by ID or
+// by any of its tags.
+func (f *FakeDocker) ImageHistory(ctx context.Context, imageID string) ([]ImageLayer, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.Err != nil {
+		return nil, fmt.Errorf("image history failed: %w", f.Err)
+	}
+	for _, img := range f.Images {
+		if img.ID != imageID && !slices.Contains(img.Tags, imageID) {
+			continue
+		}
+		for _, key := range append([]string{img.ID}, img.Tags...) {
+			if layers, ok := f.History[key]; ok {
+				return slices.Clone(layers), nil
+			}
+		}
+		return nil, nil
+	}
+	return nil, fmt.Errorf("image history failed: no such image: %s", imageID)
+}
+
+// RemoveImage removes an image found by ID or tag; a tag of an image with
+// others only untags it. Like the daemon, it refuses an image a container
+// uses unless force is set.
+func (f *FakeDocker) RemoveImage(ctx context.Context, imageID string, force bool) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.Err != nil {
+		return fmt.Errorf("remove image failed: %w", f.Err)
+	}
+	i := slices.IndexFunc(f.Images, func(img ImageInfo) bool {
+		return img.ID == imageID || slices.Contains(img.Tags, imageID)
+	})
+	if i < 0 {
+		return fmt.Errorf("remove image failed: no such image: %s", imageID)
+	}
+	img := f.Images[i]
+	if !force {
+		for _, c := range f.Containers {
+			if c.Image == img.ID || slices.Contains(img.Tags, c.Image) {
+				return fmt.Errorf("conflict: unable to remove %s (must force) - container %s is using it", imageID, c.Name)
+			}
+		}
+	}
+	if img.ID != imageID && len(img.Tags) > 1 {
+		f.Images[i].Tags = slices.DeleteFunc(slices.Clone(img.Tags), func(tag string) bool { return tag == imageID })
+		return nil
+	}
+	f.Images = slices.Delete(f.Images, i, i+1)
+	return nil
+}
+
+func (f *FakeDocker) DiskUsage(ctx context.Context) (DiskUsage, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.Err != nil {
+		return DiskUsage{}, fmt.Errorf("disk usage failed: %w", f.Err)
+	}
+	return DiskUsage{Categories: slices.Clone(f.Disk.Categories)}, nil
+}
+
+// Prune frees the reclaimable space of kind in Disk; pruning containers
+// also removes the ones that are not running, and pruning volumes the
+// ones no container uses.
+func (f *FakeDocker) Prune(ctx context.Context, kind DiskKind) (uint64, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.Err != nil {
+		return 0, fmt.Errorf("prune %s failed: %w", kind, f.Err)
+	}
+	if !slices.Contains(DiskKinds, kind) {
+		return 0, fmt.Errorf("prune %s: unknown category", kind)
+	}
+	if kind == DiskContainers {
+		f.Containers = slices.DeleteFunc(f.Containers, func(c ContainerInfo) bool { return c.State != "running" })
+	}
+	if kind == DiskVolumes {
+		f.Volumes = slices.DeleteFunc(f.Volumes, func(v VolumeInfo) bool { return len(v.Containers) == 0 })
+	}
+
+	var reclaimed int64
+	for i, c := range f.Disk.Categories {
+		if c.Kind == kind {
+			reclaimed = c.Reclaimable
+			f.Disk.Categories[i].Size -= c.Reclaimable
+			f.Disk.Categories[i].Count = c.Active
+			f.Disk.Categories[i].Reclaimable = 0
+		}
+	}
+	return uint64(reclaimed), nil
+}
+
+func (f *FakeDocker) ListVolumes(ctx context.Context) ([]VolumeInfo, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.Err != nil {
+		return nil, f.Err
+	}
+	volumes := make([]VolumeInfo, len(f.Volumes))
+	copy(volumes, f.Volumes)
+	return volumes, nil
+}
+
+// RemoveVolume deletes a volume and its data, refusing while one of its
+// Containers uses it, like the daemon.
+func (f *FakeDocker) RemoveVolume(ctx context.Context, volumeName string, force bool) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if err := f.findVolume(volumeName); err != nil {
+		return fmt.Errorf("remove %s: %w", volumeName, err)
+	}
+	i := slices.IndexFunc(f.Volumes, func(v VolumeInfo) bool { return v.Name == volumeName })
+	if users := f.Volumes[i].Containers; len(users) > 0 {
+		return fmt.Errorf("remove %s: volume is in use - [%s]", volumeName, strings.Join(users, ", "))
+	}
+	f.Volumes = slices.Delete(f.Volumes, i, i+1)
+	delete(f.VolumeData, volumeName)
+	return nil
+}
+
+// BackupVolume writes VolumeData[volume] as a backup tar, in the layout
+// the daemon produces.
+func (f *FakeDocker) BackupVolume(ctx context.Context, volume, dir string) (string, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if err := f.findVolume(volume); err != nil {
+		return "", fmt.Errorf("backup failed: %w", err)
+	}
+	var buf bytes.Buffer
+	tw := tar.NewWriter(&buf)
+	for name, content := range f.VolumeData[volume] {
+		tw.WriteHeader(&tar.Header{Name: path.Join("volume", name), Typeflag: tar.TypeReg, Mode: 0o644, Size: int64(len(content))})
+		tw.Write([]byte(content))
+	}
+	tw.Close()
+
+	backup, err := writeBackup(&buf, dir, volume)
+	if err != nil {
+		return "", fmt.Errorf("backup failed: %w", err)
+	}
+	return backup, nil
+}
+
+// RestoreVolume reads a backup tar back into VolumeData[volume].
+func (f *FakeDocker) RestoreVolume(ctx context.Context, volume, backup string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if err := f.findVolume(volume); err != nil {
+		return fmt.Errorf("restore failed: %w", err)
+	}
+	file, err := os.Open(backup)
+	if err != nil {
+		return fmt.Errorf("restore failed: %w", err)
+	}
+	defer file.Close()
+
+	if f.VolumeData[volume] == nil {
+		f.VolumeData[volume] = make(map[string]string)
+	}
+	tr := tar.NewReader(file)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("restore failed: %w", err)
+		}
+		name, ok := strings.CutPrefix(hdr.Name, "volume/")
+		if !ok || hdr.Typeflag != tar.TypeReg {
+			continue
+		}
+		content, err := io.ReadAll(tr)
+		if err != nil {
+			return fmt.Errorf("restore failed: %w", err)
+		}
+		f.VolumeData[volume][name] = string(content)
+	}
+}
+
+// findVolume fails unless the volume exists. Callers must hold f.mu.
+func (f *FakeDocker) findVolume(volume string) error {
+	if f.Err != nil {
+		return f.Err
+	}
+	for _, v := range f.Volumes {
+		if v.Name == volume {
+			return nil
+		}
+	}
+	return fmt.Errorf("volume %s: no such volume", volume)
+}
+
+func (f *FakeDocker) TopContainer(ctx context.Context, containerID string) ([]ProcessInfo, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if _, err := f.find(containerID); err != nil {
+		return nil, err
+	}
+	return f.Processes[containerID], nil
+}
+
+// SignalProcess records the kill in Execs and takes the process out of
+// Processes, as if it exited.
+func (f *FakeDocker) SignalProcess(ctx context.Context, containerID, pid, signal string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	i, err := f.find(containerID)
+	if err != nil {
+		return fmt.Errorf("kill %s failed: %w", pid, err)
+	}
+	if f.Containers[i].State != "running" {
+		return fmt.Errorf("kill %s failed: container %s is not running", pid, containerID)
+	}
+	procs := f.Processes[containerID]
+	j := slices.IndexFunc(procs, func(p ProcessInfo) bool { return p.PID == pid })
+	if j < 0 {
+		return fmt.Errorf("kill %s failed: process %s is not running", pid, pid)
+	}
+	f.Execs = append(f.Execs, "kill -s "+signal+" "+pid)
+	f.Processes[containerID] = slices.Delete(slices.Clone(procs), j, j+1)
+	return nil
+}
+
+// ContainerExec returns a session that prints a banner instead of running
+// a shell, so exec flows can be exercised without a terminal.
+func (f *FakeDocker) ContainerExec(containerID string, cmd ...string) ExecCommand {
+	if len(cmd) == 0 {
+		cmd = []string{"sh"}
+	}
+	return &fakeExec{docker: f, containerID: containerID, cmd: strings.Join(cmd, " "), stdout: io.Discard}
+}
+
+type fakeExec struct {
+	docker      *FakeDocker
+	containerID string
+	cmd         string
+	stdout      io.Writer
+}
+
+func (e *fakeExec) SetStdin(io.Reader)    {}
+func (e *fakeExec) SetStdout(w io.Writer) { e.stdout = w }
+func (e *fakeExec) SetStderr(io.Writer)   {}
+
+func (e *fakeExec) Run() error {
+	e.docker.mu.Lock()
+	i, err := e.docker.find(e.containerID)
+	if err == nil && e.docker.Containers[i].State != "running" {
+		err = fmt.Errorf("container %s is not running", e.containerID)
+	}
+	if err == nil {
+		e.docker.Execs = append(e.docker.Execs, e.cmd)
+	}
+	e.docker.mu.Unlock()
+
+	if err != nil {
+		return fmt.Errorf("exec create failed: %w", err)
+	}
+	fmt.Fprintf(e.stdout, "fake shell in %s: %s\n", e.containerID, e.cmd)
+	return nil
+}
+
+func (f *FakeDocker) ListContainerDir(ctx context.Context, containerID, dir string) ([]ContainerFile, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	i, err := f.find(containerID)
+	if err != nil {
+		return nil, err
+	}
+	prefix := strings.TrimSuffix(dir, "/") + "/"
+	seen := make(map[string]bool)
+	var files []ContainerFile
+	for p := range f.Files[f.Containers[i].ID] {
+		rest, ok := strings.CutPrefix(p, prefix)
+		if !ok {
+			continue
+		}
+		name, _, isDir := strings.Cut(rest, "/")
+		if !seen[name] {
+			seen[name] = true
+			files = append(files, ContainerFile{Name: name, Dir: isDir})
+		}
+	}
+	if len(files) == 0 && dir != "/" {
+		return nil, fmt.Errorf("list %s failed: no such directory", dir)
+	}
+	sortContainerFiles(files)
+	return files, nil
+}
+
+func (f *FakeDocker) CopyFromContainer(ctx context.Context, containerID, src, dst string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	i, err := f.find(containerID)
+	if err != nil {
+		return err
+	}
+	if fi, err := os.Stat(dst); err == nil && fi.IsDir() {
+		dst = filepath.Join(dst, path.Base(src))
+	}
+
+	copied := false
+	for p, content := range f.Files[f.Containers[i].ID] {
+		rel, ok := strings.CutPrefix(p, src)
+		if !ok || (rel != "" && rel[0] != '/') {
+			continue
+		}
+		target := filepath.Join(dst, filepath.FromSlash(rel))
+		if err := os.MkdirAll(filepath.Dir(target), 0o755); err != nil {
+			return err
+		}
+		if err := os.WriteFile(target, []byte(content), 0o644); err != nil {
+			return err
+		}
+		copied = true
+	}
+	if !copied {
+		return fmt.Errorf("copy from container failed: no such file: %s", src)
+	}
+	return nil
+}
+
+func (f *FakeDocker) CopyToContainer(ctx context.Context, containerID, src, dst string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	i, err := f.find(containerID)
+	if err != nil {
+		return err
+	}
+	id := f.Containers[i].ID
+	if f.Files[id] == nil {
+		f.Files[id] = make(map[string]string)
+	}
+	for p := range f.Files[id] {
+		if strings.HasPrefix(p, strings.TrimSuffix(dst, "/")+"/") {
+			dst = path.Join(dst, filepath.Base(src))
+			break
+		}
+	}
+
+	return filepath.WalkDir(src, func(p string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return fmt.Errorf("copy to container failed: %w", err)
+		}
+		if !entry.Type().IsRegular() {
+			return nil
+		}
+		content, err := os.ReadFile(p)
+		if err != nil {
+			return err
+		}
+		rel, _ := filepath.Rel(src, p)
+		f.Files[id][path.Join(dst, filepath.ToSlash(rel))] = string(content)
+		return nil
+	})
+}
+
+func (f *FakeDocker) Close() error {
+	return nil
+}
+
+func (f *FakeDocker) setState(containerID, state, status string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	i, err := f.find(containerID)
+	if err != nil {
+		return err
+	}
+	f.Containers[i].State = state
+	f.Containers[i].Status = status
+	return nil
+}
+
+// find locates a container by ID or name. Callers must hold f.mu.
+func (f *FakeDocker) find(containerID string) (int, error) {
+	if f.Err != nil {
+		return -1, f.Err
+	}
+	for i, c := range f.Containers {
+		if c.ID == containerID || c.Name == containerID {
+			return i, nil
+		}
+	}
+	return -1, fmt.Errorf("no such container: %s", containerID)
+}
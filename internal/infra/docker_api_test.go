@@ -0,0 +1,132 @@
+package infra
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestFakeDocker_Lifecycle(t *testing.T) {
+	ctx := context.Background()
+	docker := NewFakeDocker(ContainerInfo{ID: "abc123", Name: "web", State: "running"})
+	docker.Logs["abc123"] = []string{"one", "two", "three"}
+
+	health := docker.CheckHealth(ctx)
+	if !health.Available || len(health.Containers) != 1 {
+		t.Fatalf("expected one available container, got %+v", health)
+	}
+
+	lines, err := docker.GetContainerLogs(ctx, "abc123", 2)
+	if err != nil {
+		t.Fatalf("GetContainerLogs failed: %v", err)
+	}
+	if len(lines) != 2 || lines[0] != "two" {
+		t.Errorf("expected last two lines, got %v", lines)
+	}
+
+	if err := docker.RemoveContainer(ctx, "web", false); err == nil {
+		t.Error("expected removing a running container without force to fail")
+	}
+	if err := docker.StopContainer(ctx, "web"); err != nil {
+		t.Fatalf("StopContainer failed: %v", err)
+	}
+	if got := docker.CheckHealth(ctx).Containers[0].State; got != "exited" {
+		t.Errorf("expected state exited, got %s", got)
+	}
+	if err := docker.RemoveContainer(ctx, "abc123", false); err != nil {
+		t.Fatalf("RemoveContainer failed: %v", err)
+	}
+	if _, err := docker.InspectContainer(ctx, "abc123"); err == nil {
+		t.Error("expected inspect of removed container to fail")
+	}
+}
+
+func TestFakeDocker_Unavailable(t *testing.T) {
+	docker := NewFakeDocker()
+	docker.Err = errors.New("connection refused")
+
+	health := docker.CheckHealth(context.Background())
+	if health.Available || health.Error == nil {
+		t.Errorf("expected unavailable daemon, got %+v", health)
+	}
+	if _, err := docker.ListImages(context.Background()); err == nil {
+		t.Error("expected ListImages to fail")
+	}
+}
+
+func TestFakeDocker_Exec(t *testing.T) {
+	docker := NewFakeDocker(
+		ContainerInfo{ID: "abc123", Name: "web", State: "running"},
+		ContainerInfo{ID: "def456", Name: "worker", State: "exited"},
+	)
+
+	var out strings.Builder
+	session := docker.ContainerExec("web")
+	session.SetStdout(&out)
+	if err := session.Run(); err != nil {
+		t.Fatalf("exec into running container failed: %v", err)
+	}
+	if len(docker.Execs) != 1 || docker.Execs[0] != "sh" || !strings.Contains(out.String(), "web") {
+		t.Errorf("unexpected exec record %v, output %q", docker.Execs, out.String())
+	}
+
+	if err := docker.ContainerExec("worker", "bash").Run(); err == nil {
+		t.Error("expected exec into a stopped container to fail")
+	}
+	if err := docker.ContainerExec("missing").Run(); err == nil {
+		t.Error("expected exec into a missing container to fail")
+	}
+}
+
+func TestFakeDocker_StreamStats(t *testing.T) {
+	FakeStatsInterval = time.Millisecond
+	t.Cleanup(func() { FakeStatsInterval = time.Second })
+
+	docker := NewFakeDocker(ContainerInfo{ID: "abc123", Name: "web", State: "running"})
+	docker.Stats["abc123"] = ContainerStatsSnapshot{CPUPercent: 12.5}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	ch, err := docker.StreamContainerStats(ctx, "abc123")
+	if err != nil {
+		t.Fatalf("StreamContainerStats failed: %v", err)
+	}
+	for range 3 {
+		if stats := <-ch; stats.CPUPercent != 12.5 || stats.Timestamp.IsZero() {
+			t.Fatalf("unexpected frame: %+v", stats)
+		}
+	}
+
+	cancel()
+	for range ch {
+	}
+
+	if _, err := docker.StreamContainerStats(context.Background(), "missing"); err == nil {
+		t.Error("expected error for missing container")
+	}
+}
+
+func TestFakeDocker_FollowLogs(t *testing.T) {
+	FakeLogPollInterval = time.Millisecond
+	t.Cleanup(func() { FakeLogPollInterval = 50 * time.Millisecond })
+
+	docker := NewFakeDocker(ContainerInfo{ID: "abc123", Name: "web", State: "running"})
+	docker.Logs["abc123"] = []string{"old"}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	ch, err := docker.FollowContainerLogs(ctx, "abc123")
+	if err != nil {
+		t.Fatalf("FollowContainerLogs failed: %v", err)
+	}
+
+	docker.AppendLogs("abc123", "new one", "new two")
+	if first, second := <-ch, <-ch; first != "new one" || second != "new two" {
+		t.Errorf("expected only appended lines, got %q, %q", first, second)
+	}
+
+	cancel()
+	for range ch {
+	}
+}
@@ -2,7 +2,12 @@ package infra
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
 	"testing"
 	"time"
 
@@ -181,6 +186,48 @@ func TestIntegration_DockerClient_ContainerLogs(t *testing.T) {
 	}
 }
 
+func TestIntegration_DockerClient_TailLogs(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test in short mode")
+	}
+
+	ctx := context.Background()
+
+	req := testcontainers.ContainerRequest{
+		Image:      "alpine:latest",
+		Cmd:        []string{"sh", "-c", "echo first && sleep 2 && echo second && sleep 10"},
+		WaitingFor: wait.ForLog("first").WithStartupTimeout(10 * time.Second),
+	}
+
+	container, err := testcontainers.GenericContainer(ctx, testcontainers.GenericContainerRequest{
+		ContainerRequest: req,
+		Started:          true,
+	})
+	if err != nil {
+		t.Fatalf("failed to start container: %v", err)
+	}
+	defer container.Terminate(ctx)
+
+	client, err := NewDockerClient()
+	if err != nil {
+		t.Fatalf("failed to create docker client: %v", err)
+	}
+	defer client.Close()
+
+	first, err := client.TailLogs(ctx, container.GetContainerID(), time.Time{}, 10, 0)
+	if err != nil || len(first) != 1 || first[0].Message != "first" {
+		t.Fatalf("expected only 'first', got %+v, %v", first, err)
+	}
+
+	next, err := client.TailLogs(ctx, container.GetContainerID(), first[0].Timestamp, 10, 5*time.Second)
+	if err != nil {
+		t.Fatalf("follow failed: %v", err)
+	}
+	if len(next) != 1 || next[0].Message != "second" {
+		t.Errorf("expected only 'second' after the cursor, got %+v", next)
+	}
+}
+
 func TestIntegration_MockOllamaAPI(t *testing.T) {
 	if testing.Short() {
 		t.Skip("skipping integration test in short mode")
@@ -273,3 +320,72 @@ http {
 		t.Error("expected HasModel to return false for 'nonexistent-model'")
 	}
 }
+
+func TestDockerClient_CheckHealth_InspectsOnlyChanges(t *testing.T) {
+	status := "Up 2 minutes (healthy)"
+	inspects := 0
+	var mu sync.Mutex
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		switch {
+		case strings.HasSuffix(r.URL.Path, "/_ping"):
+			w.Write([]byte("OK"))
+		case strings.HasSuffix(r.URL.Path, "/version"):
+			json.NewEncoder(w).Encode(map[string]string{"Version": "27.0.0", "ApiVersion": "1.45"})
+		case strings.HasSuffix(r.URL.Path, "/containers/json"):
+			json.NewEncoder(w).Encode([]map[string]any{{"Id": "abcdef0123456789", "Names": []string{"/web"}, "State": "running", "Status": status}})
+		case strings.HasSuffix(r.URL.Path, "/containers/abcdef012345/json"):
+			inspects++
+			health := "healthy"
+			if strings.Contains(status, "unhealthy") {
+				health = "unhealthy"
+			}
+			json.NewEncoder(w).Encode(map[string]any{"Id": "abcdef0123456789", "RestartCount": 2, "State": map[string]any{"Status": "running", "Health": map[string]any{"Status": health}}})
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	docker, err := NewDockerClientForContext("tcp://" + strings.TrimPrefix(server.URL, "http://"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	inspected := func() int {
+		mu.Lock()
+		defer mu.Unlock()
+		return inspects
+	}
+	setStatus := func(s string) {
+		mu.Lock()
+		status = s
+		mu.Unlock()
+	}
+	check := func() ContainerInfo {
+		t.Helper()
+		health := docker.CheckHealth(context.Background())
+		if !health.Available || len(health.Containers) != 1 {
+			t.Fatalf("unexpected health: %+v", health)
+		}
+		return health.Containers[0]
+	}
+
+	check()
+	setStatus("Up 3 minutes (healthy)")
+	if c := check(); c.Health != "healthy" || c.RestartCount != 2 {
+		t.Errorf("expected the cached health and restarts, got %+v", c)
+	}
+	if n := inspected(); n != 1 {
+		t.Errorf("expected one inspect while nothing changed, got %d", n)
+	}
+
+	setStatus("Up 4 minutes (unhealthy)")
+	if c := check(); c.Health != "unhealthy" {
+		t.Errorf("expected unhealthy, got %+v", c)
+	}
+	if n := inspected(); n != 2 {
+		t.Errorf("expected a health change to inspect again, got %d inspects", n)
+	}
+}
@@ -0,0 +1,157 @@
+package infra
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/docker/docker/api/types/container"
+	"github.com/muesli/cancelreader"
+	"golang.org/x/term"
+)
+
+// ExecCommand is an interactive process whose standard streams are set by
+// the caller before Run. It has the same shape as tea.ExecCommand, so an
+// exec session can be handed straight to tea.Exec.
+type ExecCommand interface {
+	Run() error
+	SetStdin(io.Reader)
+	SetStdout(io.Writer)
+	SetStderr(io.Writer)
+}
+
+// defaultShell prefers bash and falls back to sh for minimal images.
+var defaultShell = []string{"/bin/sh", "-c", "if command -v bash >/dev/null 2>&1; then exec bash; else exec sh; fi"}
+
+// ContainerExec returns a session that runs cmd (a shell when empty) in the
+// container on a PTY attached to the caller's terminal.
+func (d *DockerClient) ContainerExec(containerID string, cmd ...string) ExecCommand {
+	if len(cmd) == 0 {
+		cmd = defaultShell
+	}
+	return &execSession{cli: d, containerID: containerID, cmd: cmd, stdin: os.Stdin, stdout: os.Stdout}
+}
+
+type execSession struct {
+	cli         *DockerClient
+	containerID string
+	cmd         []string
+
+	stdin  io.Reader
+	stdout io.Writer
+}
+
+func (s *execSession) SetStdin(r io.Reader)  { s.stdin = r }
+func (s *execSession) SetStdout(w io.Writer) { s.stdout = w }
+
+// SetStderr is a no-op: with a TTY the daemon merges stderr into stdout.
+func (s *execSession) SetStderr(io.Writer) {}
+
+func (s *execSession) Run() error {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var size *[2]uint
+	if out, ok := s.stdout.(*os.File); ok {
+		if w, h, err := term.GetSize(int(out.Fd())); err == nil {
+			size = &[2]uint{uint(h), uint(w)}
+		}
+	}
+
+	created, err := s.cli.cli.ContainerExecCreate(ctx, s.containerID, container.ExecOptions{
+		Tty:          true,
+		AttachStdin:  true,
+		AttachStdout: true,
+		AttachStderr: true,
+		ConsoleSize:  size,
+		Env:          []string{"TERM=" + termEnv()},
+		Cmd:          s.cmd,
+	})
+	if err != nil {
+		return fmt.Errorf("exec create failed: %w", err)
+	}
+
+	resp, err := s.cli.cli.ContainerExecAttach(ctx, created.ID, container.ExecAttachOptions{Tty: true, ConsoleSize: size})
+	if err != nil {
+		return fmt.Errorf("exec attach failed: %w", err)
+	}
+	defer resp.Close()
+
+	if in, ok := s.stdin.(*os.File); ok && term.IsTerminal(int(in.Fd())) {
+		state, err := term.MakeRaw(int(in.Fd()))
+		if err != nil {
+			return fmt.Errorf("raw mode failed: %w", err)
+		}
+		defer term.Restore(int(in.Fd()), state)
+	}
+
+	// The stdin copier is cancelled when the shell exits, so it does not
+	// swallow the first keystroke meant for whoever owns the terminal next.
+	in, err := cancelreader.NewReader(s.stdin)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+	go func() {
+		io.Copy(resp.Conn, in)
+		resp.CloseWrite()
+	}()
+
+	_, err = io.Copy(s.stdout, resp.Reader)
+	in.Cancel()
+	return err
+}
+
+func termEnv() string {
+	if t := os.Getenv("TERM"); t != "" {
+		return t
+	}
+	return "xterm-256color"
+}
+
+// execLines runs cmd in a running container without a terminal and returns
+// the lines it printed. A non-zero exit fails with what it printed on
+// stderr.
+func (d *DockerClient) execLines(ctx context.Context, containerID string, cmd ...string) ([]string, error) {
+	created, err := d.cli.ContainerExecCreate(ctx, containerID, container.ExecOptions{
+		AttachStdout: true,
+		AttachStderr: true,
+		Cmd:          cmd,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("exec create failed: %w", err)
+	}
+	resp, err := d.cli.ContainerExecAttach(ctx, created.ID, container.ExecAttachOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("exec attach failed: %w", err)
+	}
+	defer resp.Close()
+
+	var stdout, stderr []string
+	err = demuxLogLines(resp.Reader, false, func(stream, line string) bool {
+		if stream == "stderr" {
+			stderr = append(stderr, line)
+		} else {
+			stdout = append(stdout, line)
+		}
+		return true
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	inspect, err := d.cli.ContainerExecInspect(ctx, created.ID)
+	if err != nil {
+		return nil, fmt.Errorf("exec inspect failed: %w", err)
+	}
+	if inspect.ExitCode != 0 {
+		if len(stderr) > 0 {
+			return nil, errors.New(strings.Join(stderr, "; "))
+		}
+		return nil, fmt.Errorf("exit %d", inspect.ExitCode)
+	}
+	return stdout, nil
+}
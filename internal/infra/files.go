@@ -0,0 +1,189 @@
+package infra
+
+import (
+	"archive/tar"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/docker/docker/api/types/container"
+)
+
+// ContainerFile is an entry of a directory inside a container.
+type ContainerFile struct {
+	Name string
+	Dir  bool
+}
+
+// sortContainerFiles lists directories first, then by name.
+func sortContainerFiles(files []ContainerFile) {
+	sort.Slice(files, func(i, j int) bool {
+		if files[i].Dir != files[j].Dir {
+			return files[i].Dir
+		}
+		return files[i].Name < files[j].Name
+	})
+}
+
+// ListContainerDir lists a directory inside a running container. The
+// daemon has no listing endpoint, so it runs `ls` in the container; images
+// without one (e.g. distroless) report an error.
+func (d *DockerClient) ListContainerDir(ctx context.Context, containerID, dir string) ([]ContainerFile, error) {
+	lines, err := d.execLines(ctx, containerID, "ls", "-1Ap", "--", dir)
+	if err != nil {
+		return nil, fmt.Errorf("list %s failed: %w", dir, err)
+	}
+
+	var files []ContainerFile
+	for _, line := range lines {
+		if name, isDir := strings.CutSuffix(line, "/"); name != "" {
+			files = append(files, ContainerFile{Name: name, Dir: isDir})
+		}
+	}
+	sortContainerFiles(files)
+	return files, nil
+}
+
+// CopyFromContainer copies a file or directory out of a container, like
+// `docker cp container:src dst`: an existing directory at dst receives it
+// under its own name.
+func (d *DockerClient) CopyFromContainer(ctx context.Context, containerID, src, dst string) error {
+	reader, stat, err := d.cli.CopyFromContainer(ctx, containerID, src)
+	if err != nil {
+		return fmt.Errorf("copy from container failed: %w", err)
+	}
+	defer reader.Close()
+
+	if fi, err := os.Stat(dst); err == nil && fi.IsDir() {
+		dst = filepath.Join(dst, stat.Name)
+	}
+	if err := extractTar(reader, stat.Name, dst); err != nil {
+		return fmt.Errorf("copy from container failed: %w", err)
+	}
+	return nil
+}
+
+// CopyToContainer copies a host file or directory into a container, like
+// `docker cp src container:dst`: an existing directory at dst receives it
+// under its own name.
+func (d *DockerClient) CopyToContainer(ctx context.Context, containerID, src, dst string) error {
+	if _, err := os.Stat(src); err != nil {
+		return fmt.Errorf("copy to container failed: %w", err)
+	}
+
+	dir, name := path.Dir(dst), path.Base(dst)
+	if stat, err := d.cli.ContainerStatPath(ctx, containerID, dst); err == nil && stat.Mode.IsDir() {
+		dir, name = dst, filepath.Base(src)
+	}
+
+	pr, pw := io.Pipe()
+	go func() { pw.CloseWithError(writeTar(pw, src, name)) }()
+	err := d.cli.CopyToContainer(ctx, containerID, dir, pr, container.CopyToContainerOptions{})
+	pr.Close()
+	if err != nil {
+		return fmt.Errorf("copy to container failed: %w", err)
+	}
+	return nil
+}
+
+// extractTar writes the archive the daemon sends for a copied path to
+// dst. Entries are named after the copied path's base name, root; entries
+// outside it are skipped, and so are links, which could point anywhere on
+// the host.
+func extractTar(r io.Reader, root, dst string) error {
+	tr := tar.NewReader(r)
+	for {
+		hdr, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		// A cleaned name under root has no ".." left in it.
+		rel, ok := strings.CutPrefix(path.Clean(hdr.Name), root)
+		if !ok || (rel != "" && rel[0] != '/') {
+			continue
+		}
+		target := filepath.Join(dst, filepath.FromSlash(rel))
+
+		switch hdr.Typeflag {
+		case tar.TypeDir:
+			if err := os.MkdirAll(target, 0o755); err != nil {
+				return err
+			}
+		case tar.TypeReg:
+			if err := os.MkdirAll(filepath.Dir(target), 0o755); err != nil {
+				return err
+			}
+			f, err := os.OpenFile(target, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, hdr.FileInfo().Mode().Perm())
+			if err != nil {
+				return err
+			}
+			_, err = io.Copy(f, tr)
+			if cerr := f.Close(); err == nil {
+				err = cerr
+			}
+			if err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// writeTar archives src (a file or a directory tree) with its root renamed
+// to name, skipping anything that is not a regular file or directory.
+func writeTar(w io.Writer, src, name string) error {
+	tw := tar.NewWriter(w)
+	err := filepath.WalkDir(src, func(p string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if !entry.IsDir() && !entry.Type().IsRegular() {
+			return nil
+		}
+		info, err := entry.Info()
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(src, p)
+		if err != nil {
+			return err
+		}
+
+		hdr, err := tar.FileInfoHeader(info, "")
+		if err != nil {
+			return err
+		}
+		hdr.Name = path.Join(name, filepath.ToSlash(rel))
+		if entry.IsDir() {
+			hdr.Name += "/"
+		}
+		if err := tw.WriteHeader(hdr); err != nil {
+			return err
+		}
+		if entry.IsDir() {
+			return nil
+		}
+
+		f, err := os.Open(p)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		_, err = io.Copy(tw, f)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+	return tw.Close()
+}
@@ -0,0 +1,102 @@
+package infra
+
+import (
+	"archive/tar"
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+func TestTarRoundTrip(t *testing.T) {
+	src := filepath.Join(t.TempDir(), "conf")
+	os.MkdirAll(filepath.Join(src, "conf.d"), 0o755)
+	os.WriteFile(filepath.Join(src, "nginx.conf"), []byte("worker_processes 1;"), 0o644)
+	os.WriteFile(filepath.Join(src, "conf.d", "default.conf"), []byte("server {}"), 0o600)
+
+	var buf bytes.Buffer
+	if err := writeTar(&buf, src, "nginx"); err != nil {
+		t.Fatalf("writeTar failed: %v", err)
+	}
+
+	dst := filepath.Join(t.TempDir(), "copy")
+	if err := extractTar(&buf, "nginx", dst); err != nil {
+		t.Fatalf("extractTar failed: %v", err)
+	}
+	got, err := os.ReadFile(filepath.Join(dst, "conf.d", "default.conf"))
+	if err != nil || string(got) != "server {}" {
+		t.Fatalf("nested file = %q, %v", got, err)
+	}
+	if fi, _ := os.Stat(filepath.Join(dst, "conf.d", "default.conf")); fi.Mode().Perm() != 0o600 {
+		t.Errorf("mode not kept: %v", fi.Mode())
+	}
+	if got, _ := os.ReadFile(filepath.Join(dst, "nginx.conf")); string(got) != "worker_processes 1;" {
+		t.Errorf("top-level file = %q", got)
+	}
+}
+
+func TestExtractTar_SkipsEscapes(t *testing.T) {
+	var buf bytes.Buffer
+	tw := tar.NewWriter(&buf)
+	for _, name := range []string{"app/../../evil", "other/file", "app/ok"} {
+		tw.WriteHeader(&tar.Header{Name: name, Typeflag: tar.TypeReg, Mode: 0o644, Size: 1})
+		tw.Write([]byte("x"))
+	}
+	tw.WriteHeader(&tar.Header{Name: "app/link", Typeflag: tar.TypeSymlink, Linkname: "/etc/passwd"})
+	tw.Close()
+
+	root := t.TempDir()
+	dst := filepath.Join(root, "out")
+	if err := extractTar(&buf, "app", dst); err != nil {
+		t.Fatalf("extractTar failed: %v", err)
+	}
+
+	var written []string
+	filepath.WalkDir(root, func(p string, d os.DirEntry, err error) error {
+		if !d.IsDir() {
+			rel, _ := filepath.Rel(root, p)
+			written = append(written, rel)
+		}
+		return nil
+	})
+	if !slices.Equal(written, []string{filepath.Join("out", "ok")}) {
+		t.Errorf("expected only out/ok, got %v", written)
+	}
+}
+
+func TestFakeDocker_Files(t *testing.T) {
+	fake := NewFakeDocker(ContainerInfo{ID: "a1", Name: "web", State: "running"})
+	fake.Files["a1"] = map[string]string{
+		"/etc/nginx/nginx.conf":          "worker_processes 1;",
+		"/etc/nginx/conf.d/default.conf": "server {}",
+		"/etc/hostname":                  "web",
+	}
+	ctx := context.Background()
+
+	files, err := fake.ListContainerDir(ctx, "a1", "/etc/nginx")
+	if err != nil {
+		t.Fatalf("ListContainerDir failed: %v", err)
+	}
+	if want := []ContainerFile{{Name: "conf.d", Dir: true}, {Name: "nginx.conf"}}; !slices.Equal(files, want) {
+		t.Errorf("ListContainerDir = %+v, want %+v", files, want)
+	}
+
+	dir := t.TempDir()
+	if err := fake.CopyFromContainer(ctx, "a1", "/etc/nginx/nginx.conf", dir); err != nil {
+		t.Fatalf("CopyFromContainer failed: %v", err)
+	}
+	if got, _ := os.ReadFile(filepath.Join(dir, "nginx.conf")); string(got) != "worker_processes 1;" {
+		t.Errorf("copied file = %q", got)
+	}
+
+	patched := filepath.Join(dir, "nginx.conf")
+	os.WriteFile(patched, []byte("worker_processes 4;"), 0o644)
+	if err := fake.CopyToContainer(ctx, "a1", patched, "/etc/nginx"); err != nil {
+		t.Fatalf("CopyToContainer failed: %v", err)
+	}
+	if got := fake.Files["a1"]["/etc/nginx/nginx.conf"]; got != "worker_processes 4;" {
+		t.Errorf("container file = %q", got)
+	}
+}
@@ -0,0 +1,243 @@
+package infra
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// drmRoot is where Linux lists GPUs, one cardN directory each.
+const drmRoot = "/sys/class/drm"
+
+// drmVendors maps PCI vendor IDs to the names GPUStats uses.
+var drmVendors = map[string]string{
+	"0x8086": "intel",
+	"0x1002": "amd",
+	"0x10de": "nvidia",
+}
+
+var drmCardName = regexp.MustCompile(`^card[0-9]+$`)
+
+// drmCards returns the GPU directories under root (skipping connectors
+// like card0-HDMI-A-1) and their vendor names, "drm" when unknown.
+func drmCards(root string) (cards, vendors []string) {
+	entries, err := os.ReadDir(root)
+	if err != nil {
+		return nil, nil
+	}
+	for _, e := range entries {
+		if !drmCardName.MatchString(e.Name()) {
+			continue
+		}
+		card := filepath.Join(root, e.Name())
+		vendor := drmVendors[readSysfs(filepath.Join(card, "device", "vendor"))]
+		if vendor == "" {
+			vendor = "drm"
+		}
+		cards = append(cards, card)
+		vendors = append(vendors, vendor)
+	}
+	return cards, vendors
+}
+
+// detectDRMGPU picks a provider for the GPUs under root: Intel's when
+// there is an Intel GPU, the common laptop case, else the generic sysfs
+// reader for the first card. It returns nil without any card.
+func detectDRMGPU(root string) GPUProvider {
+	cards, vendors := drmCards(root)
+	for i, vendor := range vendors {
+		if vendor == "intel" {
+			p := &IntelGPUProvider{card: cards[i]}
+			if path, err := exec.LookPath("intel_gpu_top"); err == nil {
+				p.gpuTop = path
+			}
+			return p
+		}
+	}
+	if len(cards) > 0 {
+		return &DRMGPUProvider{card: cards[0], vendor: vendors[0]}
+	}
+	return nil
+}
+
+func readSysfs(path string) string {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(data))
+}
+
+func readSysfsInt(path string) (int64, bool) {
+	v, err := strconv.ParseInt(readSysfs(path), 10, 64)
+	return v, err == nil
+}
+
+// IntelGPUProvider reads Intel integrated (and Arc) GPUs, from
+// intel_gpu_top when it is installed and allowed to run, else from the
+// GPU frequency in sysfs.
+type IntelGPUProvider struct {
+	card   string
+	gpuTop string
+}
+
+func (p *IntelGPUProvider) Vendor() string {
+	return "intel"
+}
+
+// intelGPUTopWindow is how long intel_gpu_top samples before it is stopped.
+const intelGPUTopWindow = 1200 * time.Millisecond
+
+func (p *IntelGPUProvider) GetStats() GPUStats {
+	stats := GPUStats{Vendor: "intel"}
+
+	if p.gpuTop != "" {
+		ctx, cancel := context.WithTimeout(context.Background(), intelGPUTopWindow)
+		defer cancel()
+		// It samples until stopped; the timeout ending it is expected.
+		out, _ := exec.CommandContext(ctx, p.gpuTop, "-J", "-s", "400", "-o", "-").Output()
+		if busy, ok := parseIntelGPUTop(out); ok {
+			stats.Available = true
+			stats.UtilizationPct = int(busy + 0.5)
+		}
+	}
+
+	if !stats.Available {
+		if pct, ok := drmFrequencyPct(p.card); ok {
+			stats.Available = true
+			stats.UtilizationPct = pct
+		}
+	}
+
+	drmMemory(p.card, &stats)
+	if temp, ok := drmTemperature(p.card); ok {
+		stats.Temperature = temp
+	}
+	if !stats.Available {
+		stats.Error = fmt.Errorf("intel GPU: no usage readable (install intel_gpu_top)")
+	}
+	return stats
+}
+
+// intelGPUTopSample is the part of an intel_gpu_top -J sample we use.
+type intelGPUTopSample struct {
+	Engines map[string]struct {
+		Busy float64 `json:"busy"`
+	} `json:"engines"`
+}
+
+// parseIntelGPUTop returns the busiest engine's load from the last complete
+// sample of intel_gpu_top -J output. Newer versions wrap the samples in a
+// JSON array; older ones only separate them with commas.
+func parseIntelGPUTop(out []byte) (float64, bool) {
+	out = bytes.TrimSpace(out)
+	if !bytes.HasPrefix(out, []byte("[")) {
+		out = append([]byte("["), out...)
+	}
+
+	dec := json.NewDecoder(bytes.NewReader(out))
+	if _, err := dec.Token(); err != nil {
+		return 0, false
+	}
+	var busy float64
+	found := false
+	for dec.More() {
+		var sample intelGPUTopSample
+		if err := dec.Decode(&sample); err != nil {
+			break
+		}
+		if len(sample.Engines) == 0 {
+			continue
+		}
+		busy, found = 0, true
+		for _, e := range sample.Engines {
+			busy = max(busy, e.Busy)
+		}
+	}
+	return busy, found
+}
+
+// DRMGPUProvider reads any GPU whose driver exposes its load in sysfs:
+// amdgpu's gpu_busy_percent, or the current against the maximum clock.
+type DRMGPUProvider struct {
+	card   string
+	vendor string
+}
+
+func (p *DRMGPUProvider) Vendor() string {
+	return p.vendor
+}
+
+func (p *DRMGPUProvider) GetStats() GPUStats {
+	stats := GPUStats{Vendor: p.vendor}
+
+	if busy, ok := readSysfsInt(filepath.Join(p.card, "device", "gpu_busy_percent")); ok {
+		stats.Available = true
+		stats.UtilizationPct = int(busy)
+	} else if pct, ok := drmFrequencyPct(p.card); ok {
+		stats.Available = true
+		stats.UtilizationPct = pct
+	}
+
+	drmMemory(p.card, &stats)
+	if temp, ok := drmTemperature(p.card); ok {
+		stats.Temperature = temp
+	}
+	if stats.TotalMemoryMB > 0 && !stats.Available {
+		stats.Available = true
+		stats.UtilizationPct = stats.UsedMemoryMB * 100 / stats.TotalMemoryMB
+	}
+	if !stats.Available {
+		stats.Error = fmt.Errorf("%s GPU: no usage readable in %s", p.vendor, p.card)
+	}
+	return stats
+}
+
+// drmFreqFiles are the actual and maximum GPU clock, as i915 and xe (first
+// GT) expose them.
+var drmFreqFiles = [][2]string{
+	{"gt_act_freq_mhz", "gt_max_freq_mhz"},
+	{"device/tile0/gt0/freq0/act_freq", "device/tile0/gt0/freq0/max_freq"},
+}
+
+// drmFrequencyPct approximates load as the actual GPU clock against its
+// maximum; an idle GPU drops its clock (to 0 in RC6).
+func drmFrequencyPct(card string) (int, bool) {
+	for _, files := range drmFreqFiles {
+		act, ok1 := readSysfsInt(filepath.Join(card, files[0]))
+		maxFreq, ok2 := readSysfsInt(filepath.Join(card, files[1]))
+		if ok1 && ok2 && maxFreq > 0 {
+			return int(min(act*100/maxFreq, 100)), true
+		}
+	}
+	return 0, false
+}
+
+// drmMemory fills in dedicated memory, for GPUs that have it.
+func drmMemory(card string, stats *GPUStats) {
+	used, ok1 := readSysfsInt(filepath.Join(card, "device", "mem_info_vram_used"))
+	total, ok2 := readSysfsInt(filepath.Join(card, "device", "mem_info_vram_total"))
+	if ok1 && ok2 && total > 0 {
+		stats.UsedMemoryMB = int(used >> 20)
+		stats.TotalMemoryMB = int(total >> 20)
+	}
+}
+
+// drmTemperature reads the GPU's first hwmon sensor, in °C.
+func drmTemperature(card string) (int, bool) {
+	inputs, _ := filepath.Glob(filepath.Join(card, "device", "hwmon", "hwmon*", "temp1_input"))
+	for _, input := range inputs {
+		if milli, ok := readSysfsInt(input); ok {
+			return int(milli / 1000), true
+		}
+	}
+	return 0, false
+}
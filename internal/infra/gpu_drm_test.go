@@ -0,0 +1,95 @@
+package infra
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// writeSysfs lays out files under root, creating their directories.
+func writeSysfs(t *testing.T, root string, files map[string]string) {
+	t.Helper()
+	for name, content := range files {
+		path := filepath.Join(root, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content+"\n"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestDetectDRMGPU(t *testing.T) {
+	root := t.TempDir()
+	if p := detectDRMGPU(root); p != nil {
+		t.Fatalf("expected no provider without cards, got %T", p)
+	}
+
+	writeSysfs(t, root, map[string]string{
+		"card0/device/vendor":      "0x1002",
+		"card1/device/vendor":      "0x8086",
+		"card1-HDMI-A-1/status":    "connected",
+		"renderD128/device/vendor": "0x8086",
+	})
+	if p := detectDRMGPU(root); p == nil || p.Vendor() != "intel" {
+		t.Fatalf("expected the Intel provider, got %v", p)
+	}
+
+	os.RemoveAll(filepath.Join(root, "card1"))
+	p, ok := detectDRMGPU(root).(*DRMGPUProvider)
+	if !ok || p.Vendor() != "amd" {
+		t.Fatalf("expected the generic provider for the AMD card, got %v", p)
+	}
+}
+
+func TestIntelGPUProvider_Sysfs(t *testing.T) {
+	card := filepath.Join(t.TempDir(), "card0")
+	writeSysfs(t, card, map[string]string{
+		"gt_act_freq_mhz": "650",
+		"gt_max_freq_mhz": "1300",
+	})
+
+	stats := (&IntelGPUProvider{card: card}).GetStats()
+	if !stats.Available || stats.UtilizationPct != 50 || stats.Vendor != "intel" {
+		t.Errorf("stats = %+v", stats)
+	}
+
+	stats = (&IntelGPUProvider{card: t.TempDir()}).GetStats()
+	if stats.Available || stats.Error == nil {
+		t.Errorf("expected no stats without sysfs files, got %+v", stats)
+	}
+}
+
+func TestDRMGPUProvider(t *testing.T) {
+	card := filepath.Join(t.TempDir(), "card0")
+	writeSysfs(t, card, map[string]string{
+		"device/gpu_busy_percent":         "37",
+		"device/mem_info_vram_used":       "536870912",
+		"device/mem_info_vram_total":      "4294967296",
+		"device/hwmon/hwmon3/temp1_input": "61000",
+	})
+
+	stats := (&DRMGPUProvider{card: card, vendor: "amd"}).GetStats()
+	want := GPUStats{Available: true, Vendor: "amd", UsedMemoryMB: 512, TotalMemoryMB: 4096, UtilizationPct: 37, Temperature: 61}
+	if stats != want {
+		t.Errorf("stats = %+v, want %+v", stats, want)
+	}
+}
+
+func TestParseIntelGPUTop(t *testing.T) {
+	// Older versions: no enclosing array; the last sample is cut short.
+	out := `{"period": {"duration": 400.1}, "engines": {"Render/3D/0": {"busy": 12.5}, "Video/0": {"busy": 3.0}}},
+{"period": {"duration": 400.0}, "engines": {"Render/3D/0": {"busy": 41.6}, "Video/0": {"busy": 55.2}}},
+{"period": {"duration": 40`
+	if busy, ok := parseIntelGPUTop([]byte(out)); !ok || busy != 55.2 {
+		t.Errorf("parseIntelGPUTop = %v, %v", busy, ok)
+	}
+
+	if busy, ok := parseIntelGPUTop([]byte(`[` + "\n" + `{"engines": {"Render/3D/0": {"busy": 7.0}}}]`)); !ok || busy != 7 {
+		t.Errorf("parseIntelGPUTop(array) = %v, %v", busy, ok)
+	}
+	if _, ok := parseIntelGPUTop(nil); ok {
+		t.Error("expected no sample from empty output")
+	}
+}
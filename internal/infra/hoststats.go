@@ -0,0 +1,87 @@
+package infra
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+
+	"github.com/shirou/gopsutil/v4/cpu"
+	"github.com/shirou/gopsutil/v4/disk"
+	"github.com/shirou/gopsutil/v4/load"
+	"github.com/shirou/gopsutil/v4/mem"
+)
+
+// HostStats is the load and capacity of the machine running the daemon's
+// containers, to judge their usage against.
+type HostStats struct {
+	CPUPercent float64
+	CPUs       int
+
+	MemUsed  uint64
+	MemTotal uint64
+
+	DiskPath  string
+	DiskUsed  uint64
+	DiskTotal uint64
+
+	// Load averages are zero where the OS has none (Windows).
+	Load1, Load5, Load15 float64
+}
+
+// MemPercent is the share of host memory in use.
+func (s HostStats) MemPercent() float64 {
+	if s.MemTotal == 0 {
+		return 0
+	}
+	return float64(s.MemUsed) * 100 / float64(s.MemTotal)
+}
+
+// DiskPercent is the share of the system disk in use.
+func (s HostStats) DiskPercent() float64 {
+	if s.DiskTotal == 0 {
+		return 0
+	}
+	return float64(s.DiskUsed) * 100 / float64(s.DiskTotal)
+}
+
+// hostDiskPath is the filesystem reported as the host disk: the root, or
+// the system drive on Windows.
+func hostDiskPath() string {
+	if runtime.GOOS == "windows" {
+		if drive := os.Getenv("SystemDrive"); drive != "" {
+			return drive + string(filepath.Separator)
+		}
+		return `C:\`
+	}
+	return "/"
+}
+
+// CollectHostStats samples the host's CPU, memory, disk and load. CPU usage
+// is measured since the previous call, so the first one covers the time
+// since boot.
+func CollectHostStats(ctx context.Context) (HostStats, error) {
+	var stats HostStats
+
+	vm, err := mem.VirtualMemoryWithContext(ctx)
+	if err != nil {
+		return stats, fmt.Errorf("read memory: %w", err)
+	}
+	stats.MemUsed, stats.MemTotal = vm.Used, vm.Total
+
+	if pct, err := cpu.PercentWithContext(ctx, 0, false); err == nil && len(pct) > 0 {
+		stats.CPUPercent = pct[0]
+	}
+	stats.CPUs = runtime.NumCPU()
+
+	stats.DiskPath = hostDiskPath()
+	if usage, err := disk.UsageWithContext(ctx, stats.DiskPath); err == nil {
+		stats.DiskUsed, stats.DiskTotal = usage.Used, usage.Total
+	}
+
+	if avg, err := load.AvgWithContext(ctx); err == nil {
+		stats.Load1, stats.Load5, stats.Load15 = avg.Load1, avg.Load5, avg.Load15
+	}
+	return stats, nil
+}
@@ -0,0 +1,26 @@
+package infra
+
+import (
+	"context"
+	"testing"
+)
+
+func TestHostStats_Percent(t *testing.T) {
+	s := HostStats{MemUsed: 4 << 30, MemTotal: 16 << 30, DiskUsed: 50, DiskTotal: 200}
+	if s.MemPercent() != 25 || s.DiskPercent() != 25 {
+		t.Errorf("MemPercent = %v, DiskPercent = %v", s.MemPercent(), s.DiskPercent())
+	}
+	if (HostStats{}).MemPercent() != 0 || (HostStats{}).DiskPercent() != 0 {
+		t.Error("expected 0% without totals")
+	}
+}
+
+func TestCollectHostStats(t *testing.T) {
+	stats, err := CollectHostStats(context.Background())
+	if err != nil {
+		t.Skipf("host stats unavailable: %v", err)
+	}
+	if stats.MemTotal == 0 || stats.MemUsed > stats.MemTotal || stats.CPUs == 0 {
+		t.Errorf("implausible host stats: %+v", stats)
+	}
+}
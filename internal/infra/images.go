@@ -0,0 +1,130 @@
+package infra
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// ImageLayer is one step of an image's build history.
+type ImageLayer struct {
+	ID        string
+	CreatedBy string
+	Size      int64
+	Created   time.Time
+	Comment   string
+}
+
+// Command is the instruction that created the layer, without the shell
+// wrapper the builder records around it.
+func (l ImageLayer) Command() string {
+	cmd := strings.TrimSpace(l.CreatedBy)
+	cmd = strings.TrimPrefix(cmd, "/bin/sh -c #(nop) ")
+	if rest, ok := strings.CutPrefix(cmd, "/bin/sh -c "); ok {
+		cmd = "RUN " + rest
+	}
+	cmd = strings.TrimSuffix(cmd, " # buildkit")
+	// BuildKit records RUN steps as "RUN /bin/sh -c ...".
+	if rest, ok := strings.CutPrefix(cmd, "RUN /bin/sh -c "); ok {
+		cmd = "RUN " + rest
+	}
+	return strings.Join(strings.Fields(cmd), " ")
+}
+
+// ImageAnalysis summarizes where an image's size comes from.
+type ImageAnalysis struct {
+	// Layers are in build order, base image first.
+	Layers []ImageLayer
+	Total  int64
+	// Largest is the index of the biggest layer, or -1 without layers.
+	Largest int
+	// Hints suggest ways to shrink the image, by layer index.
+	Hints map[int]string
+}
+
+// Share is the fraction of the image size taken by layer i.
+func (a ImageAnalysis) Share(i int) float64 {
+	if a.Total == 0 || i < 0 || i >= len(a.Layers) {
+		return 0
+	}
+	return float64(a.Layers[i].Size) / float64(a.Total)
+}
+
+// layerHints are common causes of bloated layers; a layer's command must
+// contain match and none of the fixes for the hint to apply.
+var layerHints = []struct {
+	match string
+	fixes []string
+	hint  string
+}{
+	{"apt-get install", []string{"/var/lib/apt/lists"}, "remove /var/lib/apt/lists/* in the same RUN"},
+	{"apk add", []string{"--no-cache"}, "use apk add --no-cache"},
+	{"yum install", []string{"yum clean all"}, "run yum clean all in the same RUN"},
+	{"dnf install", []string{"dnf clean all"}, "run dnf clean all in the same RUN"},
+	{"pip install", []string{"--no-cache-dir"}, "use pip install --no-cache-dir"},
+	{"npm install", []string{"--omit=dev", "--production"}, "use npm ci --omit=dev"},
+	{"COPY . ", nil, "add a .dockerignore to keep build context out"},
+	{"ADD . ", nil, "add a .dockerignore to keep build context out"},
+}
+
+// minHintSize keeps hints to layers that are worth shrinking.
+const minHintSize = 10 << 20
+
+// AnalyzeImage orders layers from the base image up and points out the
+// largest layer and the ones built in a way that usually wastes space.
+// layers are as returned by ImageHistory, newest first.
+func AnalyzeImage(layers []ImageLayer) ImageAnalysis {
+	a := ImageAnalysis{Largest: -1, Hints: make(map[int]string)}
+	for i := len(layers) - 1; i >= 0; i-- {
+		a.Layers = append(a.Layers, layers[i])
+	}
+
+	for i, l := range a.Layers {
+		a.Total += l.Size
+		if a.Largest < 0 || l.Size > a.Layers[a.Largest].Size {
+			a.Largest = i
+		}
+		if l.Size < minHintSize {
+			continue
+		}
+		cmd := l.Command()
+		for _, h := range layerHints {
+			if strings.Contains(cmd, h.match) && !containsAny(cmd, h.fixes) {
+				a.Hints[i] = h.hint
+				break
+			}
+		}
+	}
+	return a
+}
+
+func containsAny(s string, subs []string) bool {
+	for _, sub := range subs {
+		if strings.Contains(s, sub) {
+			return true
+		}
+	}
+	return false
+}
+
+// ImageHistory returns the layers of an image, newest first, like
+// `docker history`.
+func (d *DockerClient) ImageHistory(ctx context.Context, imageID string) ([]ImageLayer, error) {
+	history, err := d.cli.ImageHistory(ctx, imageID)
+	if err != nil {
+		return nil, fmt.Errorf("image history failed: %w", err)
+	}
+
+	layers := make([]ImageLayer, 0, len(history))
+	for _, h := range history {
+		layers = append(layers, ImageLayer{
+			ID:        h.ID,
+			CreatedBy: h.CreatedBy,
+			Size:      h.Size,
+			Created:   time.Unix(h.Created, 0),
+			Comment:   h.Comment,
+		})
+	}
+	return layers, nil
+}
@@ -0,0 +1,97 @@
+package infra
+
+import (
+	"context"
+	"testing"
+)
+
+func TestImageLayer_Command(t *testing.T) {
+	tests := []struct {
+		createdBy string
+		want      string
+	}{
+		{"/bin/sh -c #(nop)  CMD [\"nginx\" \"-g\" \"daemon off;\"]", `CMD ["nginx" "-g" "daemon off;"]`},
+		{"/bin/sh -c apt-get update &&     apt-get install -y curl", "RUN apt-get update && apt-get install -y curl"},
+		{"RUN /bin/sh -c pip install -r requirements.txt # buildkit", "RUN pip install -r requirements.txt"},
+		{"COPY . /app # buildkit", "COPY . /app"},
+	}
+	for _, tt := range tests {
+		if got := (ImageLayer{CreatedBy: tt.createdBy}).Command(); got != tt.want {
+			t.Errorf("Command(%q) = %q, want %q", tt.createdBy, got, tt.want)
+		}
+	}
+}
+
+func TestAnalyzeImage(t *testing.T) {
+	const mb = 1 << 20
+	// Newest first, as ImageHistory returns them.
+	a := AnalyzeImage([]ImageLayer{
+		{CreatedBy: "COPY . /app # buildkit", Size: 40 * mb},
+		{CreatedBy: "RUN /bin/sh -c apk add --no-cache git # buildkit", Size: 20 * mb},
+		{CreatedBy: "/bin/sh -c apt-get install -y build-essential", Size: 300 * mb},
+		{CreatedBy: "/bin/sh -c #(nop) ADD file:1234 in / ", Size: 80 * mb},
+	})
+
+	if len(a.Layers) != 4 || a.Layers[0].Size != 80*mb {
+		t.Fatalf("expected the base layer first, got %+v", a.Layers)
+	}
+	if a.Total != 440*mb || a.Largest != 1 {
+		t.Errorf("Total = %d, Largest = %d", a.Total, a.Largest)
+	}
+	if share := a.Share(1); share < 0.68 || share > 0.69 {
+		t.Errorf("Share(1) = %f", share)
+	}
+	if _, ok := a.Hints[1]; !ok {
+		t.Error("expected a hint for apt-get without cleanup")
+	}
+	if _, ok := a.Hints[2]; ok {
+		t.Error("apk add --no-cache needs no hint")
+	}
+	if _, ok := a.Hints[3]; !ok {
+		t.Error("expected a .dockerignore hint for COPY .")
+	}
+
+	if empty := AnalyzeImage(nil); empty.Largest != -1 || empty.Share(0) != 0 {
+		t.Errorf("empty analysis = %+v", empty)
+	}
+}
+
+func TestFakeDocker_ImageHistory(t *testing.T) {
+	fake := NewFakeDocker()
+	fake.Images = []ImageInfo{{ID: "abc123", Tags: []string{"web:latest"}}}
+	fake.History["abc123"] = []ImageLayer{{ID: "abc123", Size: 10}, {ID: "<missing>", Size: 20}}
+	ctx := context.Background()
+
+	layers, err := fake.ImageHistory(ctx, "web:latest")
+	if err != nil || len(layers) != 2 {
+		t.Fatalf("ImageHistory by tag = %+v, %v", layers, err)
+	}
+	if _, err := fake.ImageHistory(ctx, "missing:latest"); err == nil {
+		t.Error("expected an error for an unknown image")
+	}
+}
+
+func TestFakeDocker_RemoveImage(t *testing.T) {
+	f := NewFakeDocker(ContainerInfo{ID: "c1", Name: "web", Image: "web:dev", State: "running"})
+	f.Images = []ImageInfo{
+		{ID: "sha256:web", Tags: []string{"web:dev", "web:latest"}},
+		{ID: "sha256:old", Tags: []string{"old:1"}},
+	}
+	ctx := context.Background()
+
+	if err := f.RemoveImage(ctx, "old:1", false); err != nil || len(f.Images) != 1 {
+		t.Fatalf("expected old:1 removed, got %v, %+v", err, f.Images)
+	}
+	if err := f.RemoveImage(ctx, "web:latest", false); err == nil {
+		t.Error("expected an image a container uses to be refused without force")
+	}
+	if err := f.RemoveImage(ctx, "web:latest", true); err != nil || len(f.Images) != 1 || len(f.Images[0].Tags) != 1 {
+		t.Errorf("expected force to only untag web:latest, got %v, %+v", err, f.Images)
+	}
+	if err := f.RemoveImage(ctx, "sha256:web", true); err != nil || len(f.Images) != 0 {
+		t.Errorf("expected removal by ID, got %v, %+v", err, f.Images)
+	}
+	if err := f.RemoveImage(ctx, "missing", true); err == nil {
+		t.Error("expected an error for a missing image")
+	}
+}
@@ -0,0 +1,241 @@
+// Package kube reads pods, deployments and pod logs from the current
+// Kubernetes context for the Kubernetes tab.
+package kube
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/clientcmd"
+)
+
+// defaultContainerAnnotation is how kubectl picks a pod's container for logs.
+const defaultContainerAnnotation = "kubectl.kubernetes.io/default-container"
+
+type PodInfo struct {
+	Name      string
+	Namespace string
+	// Status reads like kubectl's STATUS column: a waiting or terminated
+	// reason such as CrashLoopBackOff when there is one, the phase otherwise.
+	Status   string
+	Ready    int
+	Total    int
+	Restarts int32
+	Node     string
+	Created  time.Time
+	// Container is the one whose logs are shown.
+	Container string
+}
+
+// Healthy reports whether the pod is running with every container ready,
+// or has completed successfully.
+func (p PodInfo) Healthy() bool {
+	return (p.Status == string(corev1.PodRunning) && p.Ready == p.Total) || p.Status == string(corev1.PodSucceeded)
+}
+
+type DeploymentInfo struct {
+	Name      string
+	Namespace string
+	Replicas  int32
+	Ready     int32
+	Updated   int32
+	Available int32
+	Created   time.Time
+}
+
+type Health struct {
+	Available   bool
+	Context     string
+	Namespace   string
+	Pods        []PodInfo
+	Deployments []DeploymentInfo
+	Error       error
+}
+
+// API is the part of the cluster client used by the TUI.
+type API interface {
+	CheckHealth(ctx context.Context) Health
+	PodLogs(ctx context.Context, namespace, pod, container string, tail int) ([]string, error)
+}
+
+var _ API = (*Client)(nil)
+
+type Client struct {
+	cs        kubernetes.Interface
+	context   string
+	namespace string
+}
+
+// NewClient connects to contextName from the kubeconfig (KUBECONFIG or
+// ~/.kube/config), or to its current context when contextName is empty.
+// It does not contact the cluster.
+func NewClient(contextName string) (*Client, error) {
+	loader := clientcmd.NewNonInteractiveDeferredLoadingClientConfig(
+		clientcmd.NewDefaultClientConfigLoadingRules(),
+		&clientcmd.ConfigOverrides{CurrentContext: contextName},
+	)
+
+	raw, err := loader.RawConfig()
+	if err != nil {
+		return nil, fmt.Errorf("kubeconfig: %w", err)
+	}
+	if contextName == "" {
+		contextName = raw.CurrentContext
+	}
+	if contextName == "" {
+		return nil, fmt.Errorf("no kube context configured")
+	}
+
+	namespace, _, err := loader.Namespace()
+	if err != nil {
+		return nil, fmt.Errorf("kubeconfig: %w", err)
+	}
+	restConfig, err := loader.ClientConfig()
+	if err != nil {
+		return nil, fmt.Errorf("kubeconfig: %w", err)
+	}
+	cs, err := kubernetes.NewForConfig(restConfig)
+	if err != nil {
+		return nil, fmt.Errorf("kube client failed: %w", err)
+	}
+	return NewClientForClientset(cs, contextName, namespace), nil
+}
+
+// NewClientForClientset wraps an existing clientset, such as client-go's
+// fake one in tests.
+func NewClientForClientset(cs kubernetes.Interface, contextName, namespace string) *Client {
+	if namespace == "" {
+		namespace = metav1.NamespaceDefault
+	}
+	return &Client{cs: cs, context: contextName, namespace: namespace}
+}
+
+func (c *Client) Context() string   { return c.context }
+func (c *Client) Namespace() string { return c.namespace }
+
+func (c *Client) CheckHealth(ctx context.Context) Health {
+	health := Health{Context: c.context, Namespace: c.namespace}
+
+	checkCtx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	pods, err := c.ListPods(checkCtx)
+	if err != nil {
+		health.Error = err
+		return health
+	}
+	deployments, err := c.ListDeployments(checkCtx)
+	if err != nil {
+		health.Error = err
+		return health
+	}
+
+	health.Pods = pods
+	health.Deployments = deployments
+	health.Available = true
+	return health
+}
+
+func (c *Client) ListPods(ctx context.Context) ([]PodInfo, error) {
+	list, err := c.cs.CoreV1().Pods(c.namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("list pods failed: %w", err)
+	}
+
+	pods := make([]PodInfo, 0, len(list.Items))
+	for _, p := range list.Items {
+		pods = append(pods, podInfo(p))
+	}
+	return pods, nil
+}
+
+func podInfo(p corev1.Pod) PodInfo {
+	info := PodInfo{
+		Name:      p.Name,
+		Namespace: p.Namespace,
+		Status:    string(p.Status.Phase),
+		Total:     len(p.Spec.Containers),
+		Node:      p.Spec.NodeName,
+		Created:   p.CreationTimestamp.Time,
+	}
+	if p.Status.Reason != "" {
+		info.Status = p.Status.Reason
+	}
+
+	for _, cs := range p.Status.ContainerStatuses {
+		info.Restarts += cs.RestartCount
+		if cs.Ready {
+			info.Ready++
+		}
+		switch {
+		case cs.State.Waiting != nil && cs.State.Waiting.Reason != "":
+			info.Status = cs.State.Waiting.Reason
+		case cs.State.Terminated != nil && cs.State.Terminated.Reason != "" && p.Status.Phase != corev1.PodSucceeded:
+			info.Status = cs.State.Terminated.Reason
+		}
+	}
+	if p.DeletionTimestamp != nil {
+		info.Status = "Terminating"
+	}
+
+	info.Container = p.Annotations[defaultContainerAnnotation]
+	if info.Container == "" && len(p.Spec.Containers) > 0 {
+		info.Container = p.Spec.Containers[0].Name
+	}
+	return info
+}
+
+func (c *Client) ListDeployments(ctx context.Context) ([]DeploymentInfo, error) {
+	list, err := c.cs.AppsV1().Deployments(c.namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("list deployments failed: %w", err)
+	}
+
+	deployments := make([]DeploymentInfo, 0, len(list.Items))
+	for _, d := range list.Items {
+		replicas := int32(1)
+		if d.Spec.Replicas != nil {
+			replicas = *d.Spec.Replicas
+		}
+		deployments = append(deployments, DeploymentInfo{
+			Name:      d.Name,
+			Namespace: d.Namespace,
+			Replicas:  replicas,
+			Ready:     d.Status.ReadyReplicas,
+			Updated:   d.Status.UpdatedReplicas,
+			Available: d.Status.AvailableReplicas,
+			Created:   d.CreationTimestamp.Time,
+		})
+	}
+	return deployments, nil
+}
+
+// PodLogs returns the last tail lines of a container's log, timestamped
+// like GetContainerLogs.
+func (c *Client) PodLogs(ctx context.Context, namespace, pod, container string, tail int) ([]string, error) {
+	opts := &corev1.PodLogOptions{Container: container, Timestamps: true}
+	if tail > 0 {
+		lines := int64(tail)
+		opts.TailLines = &lines
+	}
+
+	stream, err := c.cs.CoreV1().Pods(namespace).GetLogs(pod, opts).Stream(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("get logs failed: %w", err)
+	}
+	defer stream.Close()
+
+	var lines []string
+	scanner := bufio.NewScanner(stream)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		lines = append(lines, strings.TrimSuffix(scanner.Text(), "\r"))
+	}
+	return lines, scanner.Err()
+}
@@ -0,0 +1,91 @@
+package kube
+
+import (
+	"context"
+	"testing"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestClient_CheckHealth(t *testing.T) {
+	replicas := int32(2)
+	cs := fake.NewSimpleClientset(
+		&corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: "web-1", Namespace: "shop"},
+			Spec:       corev1.PodSpec{Containers: []corev1.Container{{Name: "web"}, {Name: "proxy"}}},
+			Status: corev1.PodStatus{
+				Phase: corev1.PodRunning,
+				ContainerStatuses: []corev1.ContainerStatus{
+					{Name: "web", Ready: true, RestartCount: 1},
+					{Name: "proxy", Ready: true},
+				},
+			},
+		},
+		&corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: "worker-1", Namespace: "shop",
+				Annotations: map[string]string{defaultContainerAnnotation: "worker"},
+			},
+			Spec: corev1.PodSpec{Containers: []corev1.Container{{Name: "init-db"}, {Name: "worker"}}},
+			Status: corev1.PodStatus{
+				Phase: corev1.PodRunning,
+				ContainerStatuses: []corev1.ContainerStatus{
+					{Name: "worker", RestartCount: 7, State: corev1.ContainerState{
+						Waiting: &corev1.ContainerStateWaiting{Reason: "CrashLoopBackOff"},
+					}},
+				},
+			},
+		},
+		&corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "elsewhere", Namespace: "default"}},
+		&appsv1.Deployment{
+			ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "shop"},
+			Spec:       appsv1.DeploymentSpec{Replicas: &replicas},
+			Status:     appsv1.DeploymentStatus{ReadyReplicas: 1, AvailableReplicas: 1},
+		},
+	)
+	client := NewClientForClientset(cs, "kind-dev", "shop")
+
+	health := client.CheckHealth(context.Background())
+	if !health.Available || health.Error != nil {
+		t.Fatalf("expected healthy cluster, got %+v", health)
+	}
+	if health.Context != "kind-dev" || health.Namespace != "shop" {
+		t.Errorf("unexpected context/namespace: %q/%q", health.Context, health.Namespace)
+	}
+	if len(health.Pods) != 2 {
+		t.Fatalf("expected the namespace's 2 pods, got %+v", health.Pods)
+	}
+
+	web, worker := health.Pods[0], health.Pods[1]
+	if !web.Healthy() || web.Ready != 2 || web.Total != 2 || web.Restarts != 1 || web.Container != "web" {
+		t.Errorf("unexpected web pod: %+v", web)
+	}
+	if worker.Healthy() || worker.Status != "CrashLoopBackOff" || worker.Restarts != 7 || worker.Container != "worker" {
+		t.Errorf("unexpected worker pod: %+v", worker)
+	}
+
+	if len(health.Deployments) != 1 {
+		t.Fatalf("expected 1 deployment, got %+v", health.Deployments)
+	}
+	if d := health.Deployments[0]; d.Replicas != 2 || d.Ready != 1 {
+		t.Errorf("unexpected deployment: %+v", d)
+	}
+
+	lines, err := client.PodLogs(context.Background(), "shop", "web-1", "web", 10)
+	if err != nil {
+		t.Fatalf("PodLogs failed: %v", err)
+	}
+	if len(lines) != 1 {
+		t.Errorf("expected the fake clientset's log line, got %q", lines)
+	}
+}
+
+func TestNewClientForClientset_DefaultNamespace(t *testing.T) {
+	client := NewClientForClientset(fake.NewSimpleClientset(), "minikube", "")
+	if client.Namespace() != "default" {
+		t.Errorf("expected default namespace, got %q", client.Namespace())
+	}
+}
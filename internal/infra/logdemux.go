@@ -0,0 +1,78 @@
+package infra
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"io"
+	"strings"
+)
+
+// demuxLogLines reads a container log stream and calls emit for every
+// complete line, with its stream name ("stdout" or "stderr"). Non-TTY
+// containers multiplex both streams into 8-byte-header frames; a frame can
+// hold several lines or part of one, so partial lines are buffered per
+// stream until their newline arrives. TTY containers send a raw stream.
+// It returns nil at EOF or when emit returns false.
+func demuxLogLines(r io.Reader, tty bool, emit func(stream, line string) bool) error {
+	if tty {
+		scanner := bufio.NewScanner(r)
+		scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+		for scanner.Scan() {
+			if !emit("stdout", strings.TrimSuffix(scanner.Text(), "\r")) {
+				return nil
+			}
+		}
+		return scanner.Err()
+	}
+
+	var header [8]byte
+	partial := map[byte]*strings.Builder{1: {}, 2: {}}
+	flush := func() {
+		for _, fd := range []byte{1, 2} {
+			if partial[fd].Len() > 0 {
+				emit(streamName(fd), partial[fd].String())
+			}
+		}
+	}
+
+	for {
+		if _, err := io.ReadFull(r, header[:]); err != nil {
+			if errors.Is(err, io.EOF) {
+				flush()
+				return nil
+			}
+			return err
+		}
+		fd := header[0]
+		if fd != 1 && fd != 2 {
+			// stdin (0) never carries output; treat anything odd as stdout.
+			fd = 1
+		}
+
+		payload := make([]byte, binary.BigEndian.Uint32(header[4:]))
+		if _, err := io.ReadFull(r, payload); err != nil {
+			return err
+		}
+
+		buf := partial[fd]
+		for _, b := range payload {
+			if b != '\n' {
+				buf.WriteByte(b)
+				continue
+			}
+			line := buf.String()
+			buf.Reset()
+			if !emit(streamName(fd), line) {
+				return nil
+			}
+		}
+	}
+}
+
+func streamName(fd byte) string {
+	if fd == 2 {
+		return "stderr"
+	}
+	return "stdout"
+}
@@ -0,0 +1,53 @@
+package infra
+
+import (
+	"bytes"
+	"encoding/binary"
+	"strings"
+	"testing"
+)
+
+func logFrame(fd byte, payload string) []byte {
+	header := make([]byte, 8)
+	header[0] = fd
+	binary.BigEndian.PutUint32(header[4:], uint32(len(payload)))
+	return append(header, payload...)
+}
+
+func TestDemuxLogLines(t *testing.T) {
+	var stream bytes.Buffer
+	stream.Write(logFrame(1, "first\nsecond\nthi"))
+	stream.Write(logFrame(2, "oops\n"))
+	stream.Write(logFrame(1, "rd\n"))
+	stream.Write(logFrame(1, "no newline"))
+
+	var got []string
+	err := demuxLogLines(&stream, false, func(s, line string) bool {
+		got = append(got, s+":"+line)
+		return true
+	})
+	if err != nil {
+		t.Fatalf("demuxLogLines failed: %v", err)
+	}
+
+	want := []string{"stdout:first", "stdout:second", "stderr:oops", "stdout:third", "stdout:no newline"}
+	if strings.Join(got, "|") != strings.Join(want, "|") {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
+
+func TestDemuxLogLines_TTYAndStop(t *testing.T) {
+	var got []string
+	err := demuxLogLines(strings.NewReader("one\r\ntwo\nthree\n"), true, func(_, line string) bool {
+		got = append(got, line)
+		return len(got) < 2
+	})
+	if err != nil || len(got) != 2 || got[0] != "one" || got[1] != "two" {
+		t.Errorf("expected two raw lines before stopping, got %q, %v", got, err)
+	}
+
+	truncated := logFrame(1, "hello\n")[:10]
+	if err := demuxLogLines(bytes.NewReader(truncated), false, func(_, _ string) bool { return true }); err == nil {
+		t.Error("expected error for a truncated frame")
+	}
+}
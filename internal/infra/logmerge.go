@@ -0,0 +1,166 @@
+package infra
+
+import (
+	"container/heap"
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+)
+
+// logPrefixSep separates the container name from the line in merged logs,
+// as in `docker compose logs`.
+const logPrefixSep = " | "
+
+// PrefixLogLine tags a line with the container it came from.
+func PrefixLogLine(name, line string) string {
+	return name + logPrefixSep + line
+}
+
+// SplitLogPrefix undoes PrefixLogLine. Container names cannot contain the
+// separator, so the first one ends the prefix.
+func SplitLogPrefix(line string) (name, rest string, ok bool) {
+	return strings.Cut(line, logPrefixSep)
+}
+
+// LogSource is one container's log lines, oldest first, as returned by
+// GetContainerLogs with their timestamps.
+type LogSource struct {
+	Name  string
+	Lines []string
+}
+
+// logTime parses the RFC 3339 timestamp the daemon puts before each line.
+// Lines without one (e.g. a wrapped stack trace) report ok=false.
+func logTime(line string) (t time.Time, ok bool) {
+	t, _, ok = SplitLogTime(line)
+	return t, ok
+}
+
+// SplitLogTime splits the daemon's timestamp off a log line. A line
+// without one comes back whole, with ok=false.
+func SplitLogTime(line string) (t time.Time, rest string, ok bool) {
+	stamp, rest, _ := strings.Cut(line, " ")
+	t, err := time.Parse(time.RFC3339Nano, stamp)
+	if err != nil {
+		return time.Time{}, line, false
+	}
+	return t, rest, true
+}
+
+type logCursor struct {
+	source int
+	next   int
+	at     time.Time
+}
+
+type logHeap []logCursor
+
+func (h logHeap) Len() int { return len(h) }
+func (h logHeap) Less(i, j int) bool {
+	if h[i].at.Equal(h[j].at) {
+		return h[i].source < h[j].source
+	}
+	return h[i].at.Before(h[j].at)
+}
+func (h logHeap) Swap(i, j int) { h[i], h[j] = h[j], h[i] }
+func (h *logHeap) Push(x any)   { *h = append(*h, x.(logCursor)) }
+func (h *logHeap) Pop() any {
+	old := *h
+	c := old[len(old)-1]
+	*h = old[:len(old)-1]
+	return c
+}
+
+// MergeLogs interleaves the sources by timestamp and prefixes every line
+// with its container's name. A line without a timestamp stays right after
+// the line before it in the same source, so multi-line messages hold
+// together.
+func MergeLogs(sources []LogSource) []string {
+	total := 0
+	h := &logHeap{}
+	for i, src := range sources {
+		total += len(src.Lines)
+		if len(src.Lines) > 0 {
+			at, _ := logTime(src.Lines[0])
+			heap.Push(h, logCursor{source: i, at: at})
+		}
+	}
+
+	merged := make([]string, 0, total)
+	for h.Len() > 0 {
+		c := heap.Pop(h).(logCursor)
+		src := sources[c.source]
+
+		// Take the line and any untimestamped continuation lines.
+		merged = append(merged, PrefixLogLine(src.Name, src.Lines[c.next]))
+		c.next++
+		for c.next < len(src.Lines) {
+			at, ok := logTime(src.Lines[c.next])
+			if ok {
+				c.at = at
+				break
+			}
+			merged = append(merged, PrefixLogLine(src.Name, src.Lines[c.next]))
+			c.next++
+		}
+		if c.next < len(src.Lines) {
+			heap.Push(h, c)
+		}
+	}
+	return merged
+}
+
+// GetMergedLogs fetches the last tail lines of each container and merges
+// them by timestamp.
+func GetMergedLogs(ctx context.Context, docker DockerAPI, containers []ContainerInfo, tail int) ([]string, error) {
+	sources := make([]LogSource, len(containers))
+	for i, c := range containers {
+		lines, err := docker.GetContainerLogs(ctx, c.ID, tail)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", c.Name, err)
+		}
+		sources[i] = LogSource{Name: c.Name, Lines: lines}
+	}
+	return MergeLogs(sources), nil
+}
+
+// FollowMergedLogs follows every container and fans their new lines into
+// one channel, prefixed with the container name. Live lines are passed on
+// as they arrive rather than reordered. The channel closes once every
+// stream has ended or ctx is cancelled.
+func FollowMergedLogs(ctx context.Context, docker DockerAPI, containers []ContainerInfo) (<-chan string, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	streams := make([]<-chan string, len(containers))
+	for i, c := range containers {
+		ch, err := docker.FollowContainerLogs(ctx, c.ID)
+		if err != nil {
+			cancel()
+			return nil, fmt.Errorf("%s: %w", c.Name, err)
+		}
+		streams[i] = ch
+	}
+
+	out := make(chan string, 64)
+	var wg sync.WaitGroup
+	for i, ch := range streams {
+		wg.Add(1)
+		go func(name string, ch <-chan string) {
+			defer wg.Done()
+			for line := range ch {
+				select {
+				case out <- PrefixLogLine(name, line):
+				case <-ctx.Done():
+					return
+				}
+			}
+		}(containers[i].Name, ch)
+	}
+	go func() {
+		wg.Wait()
+		cancel()
+		close(out)
+	}()
+	return out, nil
+}
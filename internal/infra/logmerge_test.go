@@ -0,0 +1,91 @@
+package infra
+
+import (
+	"context"
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestMergeLogs(t *testing.T) {
+	merged := MergeLogs([]LogSource{
+		{Name: "web", Lines: []string{
+			"2026-01-01T10:00:01Z GET /",
+			"2026-01-01T10:00:03Z panic: boom",
+			"goroutine 1 [running]:",
+			"2026-01-01T10:00:05Z restarted",
+		}},
+		{Name: "db", Lines: []string{
+			"2026-01-01T10:00:00Z ready",
+			"2026-01-01T10:00:03.5Z checkpoint",
+			"2026-01-01T10:00:04Z vacuum",
+		}},
+	})
+
+	want := []string{
+		"db | 2026-01-01T10:00:00Z ready",
+		"web | 2026-01-01T10:00:01Z GET /",
+		"web | 2026-01-01T10:00:03Z panic: boom",
+		"web | goroutine 1 [running]:",
+		"db | 2026-01-01T10:00:03.5Z checkpoint",
+		"db | 2026-01-01T10:00:04Z vacuum",
+		"web | 2026-01-01T10:00:05Z restarted",
+	}
+	if !slices.Equal(merged, want) {
+		t.Errorf("MergeLogs =\n%q\nwant\n%q", merged, want)
+	}
+
+	name, rest, ok := SplitLogPrefix(merged[0])
+	if !ok || name != "db" || rest != "2026-01-01T10:00:00Z ready" {
+		t.Errorf("SplitLogPrefix = %q, %q, %v", name, rest, ok)
+	}
+
+	at, msg, ok := SplitLogTime(rest)
+	if !ok || msg != "ready" || !at.Equal(time.Date(2026, 1, 1, 10, 0, 0, 0, time.UTC)) {
+		t.Errorf("SplitLogTime = %v, %q, %v", at, msg, ok)
+	}
+	if _, msg, ok := SplitLogTime("goroutine 1 [running]:"); ok || msg != "goroutine 1 [running]:" {
+		t.Errorf("SplitLogTime of a continuation line = %q, %v", msg, ok)
+	}
+}
+
+func TestFollowMergedLogs(t *testing.T) {
+	FakeLogPollInterval = time.Millisecond
+	t.Cleanup(func() { FakeLogPollInterval = 50 * time.Millisecond })
+
+	fake := NewFakeDocker(
+		ContainerInfo{ID: "a1", Name: "web", State: "running"},
+		ContainerInfo{ID: "b2", Name: "db", State: "running"},
+	)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	ch, err := FollowMergedLogs(ctx, fake, fake.Containers)
+	if err != nil {
+		t.Fatalf("FollowMergedLogs failed: %v", err)
+	}
+	fake.AppendLogs("a1", "hello from web")
+	fake.AppendLogs("b2", "hello from db")
+
+	var got []string
+	for len(got) < 2 {
+		select {
+		case line := <-ch:
+			got = append(got, line)
+		case <-time.After(time.Second):
+			t.Fatalf("timed out, got %q", got)
+		}
+	}
+	slices.Sort(got)
+	if !slices.Equal(got, []string{"db | hello from db", "web | hello from web"}) {
+		t.Errorf("unexpected lines: %q", got)
+	}
+
+	cancel()
+	for range ch {
+	}
+
+	if _, err := FollowMergedLogs(context.Background(), fake, []ContainerInfo{{ID: "missing", Name: "ghost"}}); err == nil {
+		t.Error("expected error for unknown container")
+	}
+}
@@ -0,0 +1,106 @@
+package infra
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// Signals SignalProcess sends, by their kill(1) names.
+const (
+	SignalTerm = "TERM"
+	SignalKill = "KILL"
+)
+
+// listProcsScript prints "pid cmdline" for every process the container
+// sees, with the cmdline's NUL separators left for the caller. Only sh and
+// cat are needed, so it runs in images without ps.
+const listProcsScript = `for p in /proc/[0-9]*; do printf '%s ' "${p#/proc/}"; cat "$p/cmdline" 2>/dev/null; echo; done`
+
+// nsProcess is a process as the container's own PID namespace sees it.
+type nsProcess struct {
+	PID     int
+	Command string
+}
+
+// parseNamespaceProcesses reads the output of listProcsScript.
+func parseNamespaceProcesses(lines []string) []nsProcess {
+	var procs []nsProcess
+	for _, line := range lines {
+		pid, cmdline, _ := strings.Cut(line, " ")
+		n, err := strconv.Atoi(pid)
+		if err != nil {
+			continue
+		}
+		procs = append(procs, nsProcess{PID: n, Command: normalizeCommand(strings.ReplaceAll(cmdline, "\x00", " "))})
+	}
+	return procs
+}
+
+func normalizeCommand(cmd string) string {
+	return strings.Join(strings.Fields(cmd), " ")
+}
+
+// namespacePID finds the PID inside the container of the process top
+// lists as hostPID. `docker top` shows host PIDs, which mean nothing to
+// kill inside the container, so processes are matched by command line:
+// the n-th oldest process running a command on the host is the n-th
+// oldest running it in the container.
+func namespacePID(top []ProcessInfo, procs []nsProcess, hostPID string) (int, error) {
+	i := slices.IndexFunc(top, func(p ProcessInfo) bool { return p.PID == hostPID })
+	if i < 0 {
+		return 0, fmt.Errorf("process %s is not running", hostPID)
+	}
+	command := normalizeCommand(top[i].Command)
+
+	var hostPIDs []int
+	for _, p := range top {
+		if normalizeCommand(p.Command) == command {
+			n, _ := strconv.Atoi(p.PID)
+			hostPIDs = append(hostPIDs, n)
+		}
+	}
+	slices.Sort(hostPIDs)
+	target, _ := strconv.Atoi(hostPID)
+	rank := slices.Index(hostPIDs, target)
+
+	var same []nsProcess
+	for _, p := range procs {
+		if p.Command == command {
+			same = append(same, p)
+		}
+	}
+	slices.SortFunc(same, func(a, b nsProcess) int { return cmp.Compare(a.PID, b.PID) })
+	if rank < 0 || rank >= len(same) {
+		return 0, fmt.Errorf("process %s (%s) not found in the container", hostPID, command)
+	}
+	return same[rank].PID, nil
+}
+
+// SignalProcess sends signal (SignalTerm or SignalKill) to a process that
+// TopContainer listed as pid, by running kill in the container.
+func (d *DockerClient) SignalProcess(ctx context.Context, containerID, pid, signal string) error {
+	if signal != SignalTerm && signal != SignalKill {
+		return fmt.Errorf("kill %s: unsupported signal %q", pid, signal)
+	}
+	top, err := d.TopContainer(ctx, containerID)
+	if err != nil {
+		return fmt.Errorf("kill %s failed: %w", pid, err)
+	}
+	lines, err := d.execLines(ctx, containerID, "sh", "-c", listProcsScript)
+	if err != nil {
+		return fmt.Errorf("kill %s failed: listing processes: %w", pid, err)
+	}
+	nsPID, err := namespacePID(top, parseNamespaceProcesses(lines), pid)
+	if err != nil {
+		return fmt.Errorf("kill %s failed: %w", pid, err)
+	}
+	// kill is a shell builtin, so it works where /bin/kill is missing.
+	if _, err := d.execLines(ctx, containerID, "sh", "-c", fmt.Sprintf("kill -s %s %d", signal, nsPID)); err != nil {
+		return fmt.Errorf("kill %s failed: %w", pid, err)
+	}
+	return nil
+}
@@ -0,0 +1,69 @@
+package infra
+
+import (
+	"context"
+	"slices"
+	"testing"
+
+	"github.com/docker/docker/api/types/container"
+)
+
+func TestParseTop(t *testing.T) {
+	procs := parseTop(container.TopResponse{
+		Titles:    []string{"PID", "USER", "%CPU", "%MEM", "COMMAND"},
+		Processes: [][]string{{"4242", "node", "12.5", "3.1", "node server.js --port 3000"}},
+	})
+	want := ProcessInfo{PID: "4242", User: "node", CPU: "12.5", Memory: "3.1", Command: "node server.js --port 3000"}
+	if len(procs) != 1 || procs[0] != want {
+		t.Errorf("parseTop = %+v, want %+v", procs, want)
+	}
+}
+
+func TestNamespacePID(t *testing.T) {
+	top := []ProcessInfo{
+		{PID: "5100", Command: "nginx: master process nginx -g daemon off;"},
+		{PID: "5160", Command: "nginx: worker process"},
+		{PID: "5161", Command: "nginx: worker process"},
+		{PID: "5200", Command: "sh -c tail -f /dev/null"},
+	}
+	procs := parseNamespaceProcesses([]string{
+		"1 nginx: master process nginx -g daemon off;",
+		"30 nginx: worker process",
+		"29 nginx: worker process",
+		"41 sh\x00-c\x00tail -f /dev/null\x00",
+		"57 sh\x00-c\x00for p in /proc/[0-9]*; do ...\x00",
+		"self",
+	})
+
+	for hostPID, want := range map[string]int{"5100": 1, "5160": 29, "5161": 30, "5200": 41} {
+		got, err := namespacePID(top, procs, hostPID)
+		if err != nil || got != want {
+			t.Errorf("namespacePID(%s) = %d, %v; want %d", hostPID, got, err, want)
+		}
+	}
+	if _, err := namespacePID(top, procs, "9999"); err == nil {
+		t.Error("expected an error for a process top doesn't list")
+	}
+	if _, err := namespacePID(top, procs[:1], "5160"); err == nil {
+		t.Error("expected an error for a process missing in the container")
+	}
+}
+
+func TestFakeDocker_SignalProcess(t *testing.T) {
+	fake := NewFakeDocker(ContainerInfo{ID: "a1", Name: "web", State: "running"})
+	fake.Processes["a1"] = []ProcessInfo{{PID: "10", Command: "nginx"}, {PID: "11", Command: "nginx: worker"}}
+	ctx := context.Background()
+
+	if err := fake.SignalProcess(ctx, "a1", "11", SignalTerm); err != nil {
+		t.Fatalf("SignalProcess failed: %v", err)
+	}
+	if procs, _ := fake.TopContainer(ctx, "a1"); len(procs) != 1 || procs[0].PID != "10" {
+		t.Errorf("expected the worker gone, got %+v", procs)
+	}
+	if !slices.Equal(fake.Execs, []string{"kill -s TERM 11"}) {
+		t.Errorf("Execs = %v", fake.Execs)
+	}
+	if err := fake.SignalProcess(ctx, "a1", "11", SignalKill); err == nil {
+		t.Error("expected an error for a process that is gone")
+	}
+}
@@ -0,0 +1,134 @@
+package infra
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/docker/docker/api/types/image"
+)
+
+// LayerProgress is the pull state of one image layer.
+type LayerProgress struct {
+	ID      string
+	Status  string
+	Current int64
+	Total   int64
+}
+
+// Done reports whether the layer needs no more work.
+func (l LayerProgress) Done() bool {
+	return l.Status == "Pull complete" || l.Status == "Already exists"
+}
+
+// PullProgress is the state of an image pull after each progress message.
+type PullProgress struct {
+	Ref string
+	// Status is the latest message not tied to a layer, e.g. the digest or
+	// "Downloaded newer image".
+	Status string
+	Layers []LayerProgress
+}
+
+// Bytes sums the download progress of the layers whose size is known.
+func (p PullProgress) Bytes() (current, total int64) {
+	for _, l := range p.Layers {
+		if l.Done() {
+			current += l.Total
+			total += l.Total
+			continue
+		}
+		current += l.Current
+		total += l.Total
+	}
+	return current, total
+}
+
+// LayersDone counts the layers that are pulled or already present.
+func (p PullProgress) LayersDone() int {
+	n := 0
+	for _, l := range p.Layers {
+		if l.Done() {
+			n++
+		}
+	}
+	return n
+}
+
+// pullMessage is one line of the daemon's JSON progress stream.
+type pullMessage struct {
+	ID             string `json:"id"`
+	Status         string `json:"status"`
+	ProgressDetail struct {
+		Current int64 `json:"current"`
+		Total   int64 `json:"total"`
+	} `json:"progressDetail"`
+	Error string `json:"error"`
+}
+
+// readPullProgress decodes a pull progress stream, calling report after
+// every message. An error message from the daemon ends the pull.
+func readPullProgress(r io.Reader, ref string, report func(PullProgress)) error {
+	progress := PullProgress{Ref: ref}
+	index := make(map[string]int)
+
+	dec := json.NewDecoder(r)
+	for {
+		var msg pullMessage
+		if err := dec.Decode(&msg); err != nil {
+			if errors.Is(err, io.EOF) {
+				return nil
+			}
+			return fmt.Errorf("decode pull progress: %w", err)
+		}
+		if msg.Error != "" {
+			return fmt.Errorf("pull %s: %s", ref, msg.Error)
+		}
+
+		// The tag is sent as an ID with "Pulling from"; it is not a layer.
+		if msg.ID == "" || strings.HasPrefix(msg.Status, "Pulling from") {
+			progress.Status = msg.Status
+			report(progress.snapshot())
+			continue
+		}
+
+		i, ok := index[msg.ID]
+		if !ok {
+			i = len(progress.Layers)
+			index[msg.ID] = i
+			progress.Layers = append(progress.Layers, LayerProgress{ID: msg.ID})
+		}
+		layer := &progress.Layers[i]
+		layer.Status = msg.Status
+		switch msg.Status {
+		case "Downloading":
+			layer.Current = msg.ProgressDetail.Current
+			layer.Total = msg.ProgressDetail.Total
+		case "Verifying Checksum", "Download complete", "Extracting":
+			// Extraction reports its own byte counts; the download is done.
+			layer.Current = layer.Total
+		}
+		report(progress.snapshot())
+	}
+}
+
+// snapshot copies the layers so a report can outlive the next message.
+func (p PullProgress) snapshot() PullProgress {
+	p.Layers = append([]LayerProgress(nil), p.Layers...)
+	return p
+}
+
+// PullImage pulls ref, reporting layer-by-layer progress as the daemon
+// streams it. report is called on the pulling goroutine, so a slow report
+// only slows down reading the stream.
+func (d *DockerClient) PullImage(ctx context.Context, ref string, report func(PullProgress)) error {
+	reader, err := d.cli.ImagePull(ctx, ref, image.PullOptions{})
+	if err != nil {
+		return fmt.Errorf("pull %s: %w", ref, err)
+	}
+	defer reader.Close()
+	return readPullProgress(reader, ref, report)
+}
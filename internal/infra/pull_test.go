@@ -0,0 +1,54 @@
+package infra
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestReadPullProgress(t *testing.T) {
+	stream := strings.Join([]string{
+		`{"status":"Pulling from library/redis","id":"7-alpine"}`,
+		`{"status":"Already exists","progressDetail":{},"id":"aaa"}`,
+		`{"status":"Pulling fs layer","progressDetail":{},"id":"bbb"}`,
+		`{"status":"Downloading","progressDetail":{"current":100,"total":400},"id":"bbb"}`,
+		`{"status":"Downloading","progressDetail":{"current":300,"total":400},"id":"bbb"}`,
+		`{"status":"Extracting","progressDetail":{"current":50,"total":900},"id":"bbb"}`,
+		`{"status":"Pull complete","progressDetail":{},"id":"bbb"}`,
+		`{"status":"Digest: sha256:abc"}`,
+		`{"status":"Status: Downloaded newer image for redis:7-alpine"}`,
+	}, "\n")
+
+	var reports []PullProgress
+	if err := readPullProgress(strings.NewReader(stream), "redis:7-alpine", func(p PullProgress) {
+		reports = append(reports, p)
+	}); err != nil {
+		t.Fatalf("readPullProgress failed: %v", err)
+	}
+	if len(reports) != 9 {
+		t.Fatalf("expected a report per message, got %d", len(reports))
+	}
+
+	if cur, total := reports[4].Bytes(); cur != 300 || total != 400 {
+		t.Errorf("expected 300/400 while downloading, got %d/%d", cur, total)
+	}
+	if cur, _ := reports[5].Bytes(); cur != 400 {
+		t.Errorf("extraction should not move download progress back, got %d", cur)
+	}
+	if reports[4].Layers[1].Status != "Downloading" {
+		t.Error("earlier reports must not change as the pull continues")
+	}
+
+	last := reports[len(reports)-1]
+	if len(last.Layers) != 2 || last.LayersDone() != 2 || !strings.HasPrefix(last.Status, "Status: Downloaded") {
+		t.Errorf("unexpected final progress: %+v", last)
+	}
+}
+
+func TestReadPullProgress_Error(t *testing.T) {
+	stream := `{"status":"Pulling from library/nope","id":"latest"}
+{"errorDetail":{"message":"manifest unknown"},"error":"manifest unknown"}`
+	err := readPullProgress(strings.NewReader(stream), "nope", func(PullProgress) {})
+	if err == nil || !strings.Contains(err.Error(), "manifest unknown") {
+		t.Errorf("expected daemon error, got %v", err)
+	}
+}
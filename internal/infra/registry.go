@@ -10,6 +10,9 @@ type Registry struct {
 	docker *DockerClient
 	ollama *OllamaClient
 	gpu    GPUProvider
+
+	// contexts holds clients for daemons other than config.DockerContext.
+	contexts map[string]*DockerClient
 }
 
 var (
@@ -56,7 +59,7 @@ func (r *Registry) Docker() (*DockerClient, error) {
 		return r.docker, nil
 	}
 
-	client, err := NewDockerClient()
+	client, err := NewDockerClientForContext(r.config.DockerContext)
 	if err != nil {
 		return nil, err
 	}
@@ -64,6 +67,30 @@ func (r *Registry) Docker() (*DockerClient, error) {
 	return r.docker, nil
 }
 
+// DockerContext returns the shared client for a named docker context,
+// PodmanContext or host URL; see ResolveDaemon.
+func (r *Registry) DockerContext(name string) (*DockerClient, error) {
+	if name == r.Config().DockerContext {
+		return r.Docker()
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if client, ok := r.contexts[name]; ok {
+		return client, nil
+	}
+	client, err := NewDockerClientForContext(name)
+	if err != nil {
+		return nil, err
+	}
+	if r.contexts == nil {
+		r.contexts = make(map[string]*DockerClient)
+	}
+	r.contexts[name] = client
+	return client, nil
+}
+
 func (r *Registry) Ollama() (*OllamaClient, error) {
 	r.mu.RLock()
 	if r.ollama != nil {
@@ -93,7 +120,7 @@ func (r *Registry) dockerUnsafe() (*DockerClient, error) {
 		return r.docker, nil
 	}
 
-	client, err := NewDockerClient()
+	client, err := NewDockerClientForContext(r.config.DockerContext)
 	if err != nil {
 		return nil, err
 	}
@@ -128,6 +155,10 @@ func (r *Registry) Close() error {
 		r.docker.Close()
 		r.docker = nil
 	}
+	for _, client := range r.contexts {
+		client.Close()
+	}
+	r.contexts = nil
 	r.ollama = nil
 	r.gpu = nil
 	return nil
@@ -141,7 +172,12 @@ func ResetRegistry() {
 	registry = nil
 }
 
-func GetSharedDockerClient() (*DockerClient, error) {
+// GetSharedDockerClient returns the client for the configured daemon, or
+// for contextName when one is given.
+func GetSharedDockerClient(contextName ...string) (*DockerClient, error) {
+	if len(contextName) > 0 && contextName[0] != "" {
+		return GetRegistry().DockerContext(contextName[0])
+	}
 	return GetRegistry().Docker()
 }
 
@@ -0,0 +1,105 @@
+package infra
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/docker/docker/api/types/container"
+	"github.com/docker/go-units"
+)
+
+// ResourceLimits are the memory and CPU limits of a container. Zero means
+// unlimited when inspecting and unchanged when updating.
+type ResourceLimits struct {
+	Memory   int64
+	NanoCPUs int64
+}
+
+// CPUs is the CPU limit as a number of CPUs, like `docker run --cpus`.
+func (l ResourceLimits) CPUs() float64 {
+	return float64(l.NanoCPUs) / 1e9
+}
+
+// ParseMemoryLimit reads a memory limit the way `docker update --memory`
+// does, e.g. "512m" or "1.5g".
+func ParseMemoryLimit(s string) (int64, error) {
+	memory, err := units.RAMInBytes(strings.TrimSpace(s))
+	if err != nil {
+		return 0, fmt.Errorf("invalid memory limit %q: want a size like 512m or 2g", s)
+	}
+	// The daemon refuses anything below 6 MiB.
+	if memory < 6<<20 {
+		return 0, fmt.Errorf("memory limit must be at least 6m")
+	}
+	return memory, nil
+}
+
+// ParseCPULimit reads a CPU limit given as a number of CPUs, e.g. "1.5",
+// and returns it in nano CPUs.
+func ParseCPULimit(s string) (int64, error) {
+	cpus, err := strconv.ParseFloat(strings.TrimSpace(s), 64)
+	if err != nil || cpus <= 0 {
+		return 0, fmt.Errorf("invalid CPU limit %q: want a number of CPUs like 1.5", s)
+	}
+	return int64(cpus * 1e9), nil
+}
+
+// inspectLimits returns the limits of an inspected container. A limit set
+// with --cpu-quota is converted to CPUs.
+func inspectLimits(info container.InspectResponse) ResourceLimits {
+	if info.ContainerJSONBase == nil || info.HostConfig == nil {
+		return ResourceLimits{}
+	}
+	r := info.HostConfig.Resources
+	limits := ResourceLimits{Memory: r.Memory, NanoCPUs: r.NanoCPUs}
+	if limits.NanoCPUs == 0 && r.CPUQuota > 0 && r.CPUPeriod > 0 {
+		limits.NanoCPUs = r.CPUQuota * 1e9 / r.CPUPeriod
+	}
+	return limits
+}
+
+// UpdateResources changes the limits of a running container in place,
+// without recreating it.
+func (d *DockerClient) UpdateResources(ctx context.Context, containerID string, limits ResourceLimits) error {
+	info, err := d.cli.ContainerInspect(ctx, containerID)
+	if err != nil {
+		return fmt.Errorf("update failed: %w", err)
+	}
+	resources := updatedResources(info, limits)
+	if _, err := d.cli.ContainerUpdate(ctx, containerID, container.UpdateConfig{Resources: resources}); err != nil {
+		return fmt.Errorf("update failed: %w", err)
+	}
+	return nil
+}
+
+// updatedResources is the update that sets limits on an inspected
+// container; zero fields are left as they are. Swap only changes when the
+// new memory limit would exceed it, which Docker refuses: it then follows
+// memory to twice its size, as for `docker run --memory`. Unlimited (-1)
+// and custom swap limits that still fit are kept.
+func updatedResources(info container.InspectResponse, limits ResourceLimits) container.Resources {
+	var host container.Resources
+	if info.ContainerJSONBase != nil && info.HostConfig != nil {
+		host = info.HostConfig.Resources
+	}
+
+	var resources container.Resources
+	if limits.Memory > 0 {
+		resources.Memory = limits.Memory
+		if host.MemorySwap > 0 && host.MemorySwap < limits.Memory {
+			resources.MemorySwap = 2 * limits.Memory
+		}
+	}
+	if limits.NanoCPUs > 0 {
+		// --cpus and --cpu-quota are exclusive; keep using the quota if
+		// that is how the container was started.
+		if host.CPUQuota > 0 && host.CPUPeriod > 0 {
+			resources.CPUQuota = limits.NanoCPUs * host.CPUPeriod / 1e9
+		} else {
+			resources.NanoCPUs = limits.NanoCPUs
+		}
+	}
+	return resources
+}
@@ -0,0 +1,107 @@
+package infra
+
+import (
+	"context"
+	"testing"
+
+	"github.com/docker/docker/api/types/container"
+)
+
+func TestParseMemoryLimit(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    int64
+		wantErr bool
+	}{
+		{in: "512m", want: 512 << 20},
+		{in: " 1.5g ", want: 3 << 29},
+		{in: "2GiB", want: 2 << 30},
+		{in: "512", wantErr: true},
+		{in: "lots", wantErr: true},
+		{in: "", wantErr: true},
+	}
+	for _, tt := range tests {
+		got, err := ParseMemoryLimit(tt.in)
+		if (err != nil) != tt.wantErr || got != tt.want {
+			t.Errorf("ParseMemoryLimit(%q) = %d, %v", tt.in, got, err)
+		}
+	}
+}
+
+func TestParseCPULimit(t *testing.T) {
+	if got, err := ParseCPULimit("1.5"); err != nil || got != 1_500_000_000 {
+		t.Errorf("ParseCPULimit(1.5) = %d, %v", got, err)
+	}
+	for _, in := range []string{"0", "-1", "two"} {
+		if _, err := ParseCPULimit(in); err == nil {
+			t.Errorf("ParseCPULimit(%q) should fail", in)
+		}
+	}
+}
+
+func TestInspectLimits(t *testing.T) {
+	info := container.InspectResponse{ContainerJSONBase: &container.ContainerJSONBase{
+		HostConfig: &container.HostConfig{Resources: container.Resources{
+			Memory:    256 << 20,
+			CPUQuota:  50000,
+			CPUPeriod: 100000,
+		}},
+	}}
+	limits := inspectLimits(info)
+	if limits.Memory != 256<<20 || limits.CPUs() != 0.5 {
+		t.Errorf("inspectLimits = %+v", limits)
+	}
+	if limits := inspectLimits(container.InspectResponse{}); limits != (ResourceLimits{}) {
+		t.Errorf("expected no limits without a host config, got %+v", limits)
+	}
+}
+
+func TestUpdatedResources(t *testing.T) {
+	inspected := func(r container.Resources) container.InspectResponse {
+		return container.InspectResponse{ContainerJSONBase: &container.ContainerJSONBase{
+			HostConfig: &container.HostConfig{Resources: r},
+		}}
+	}
+	const gib = 1 << 30
+	for _, tt := range []struct {
+		name     string
+		current  container.Resources
+		limits   ResourceLimits
+		wantSwap int64
+	}{
+		{"unlimited swap is kept", container.Resources{Memory: gib, MemorySwap: -1}, ResourceLimits{Memory: 4 * gib}, 0},
+		{"custom swap that fits is kept", container.Resources{Memory: gib, MemorySwap: 8 * gib}, ResourceLimits{Memory: 2 * gib}, 0},
+		{"no swap limit is kept", container.Resources{}, ResourceLimits{Memory: gib}, 0},
+		{"swap below the new memory follows it", container.Resources{Memory: gib, MemorySwap: 2 * gib}, ResourceLimits{Memory: 4 * gib}, 8 * gib},
+		{"CPU only leaves swap alone", container.Resources{Memory: gib, MemorySwap: gib}, ResourceLimits{NanoCPUs: 1e9}, 0},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			got := updatedResources(inspected(tt.current), tt.limits)
+			if got.MemorySwap != tt.wantSwap || got.Memory != tt.limits.Memory {
+				t.Errorf("updatedResources = memory %d swap %d, want memory %d swap %d", got.Memory, got.MemorySwap, tt.limits.Memory, tt.wantSwap)
+			}
+		})
+	}
+
+	quota := updatedResources(inspected(container.Resources{CPUQuota: 50000, CPUPeriod: 100000}), ResourceLimits{NanoCPUs: 2e9})
+	if quota.CPUQuota != 200000 || quota.NanoCPUs != 0 {
+		t.Errorf("expected a quota-limited container kept on its quota, got %+v", quota)
+	}
+}
+
+func TestFakeDocker_UpdateResources(t *testing.T) {
+	fake := NewFakeDocker(ContainerInfo{ID: "a1", Name: "api", State: "running"})
+	fake.Limits["a1"] = ResourceLimits{Memory: 256 << 20, NanoCPUs: 1e9}
+	ctx := context.Background()
+
+	if err := fake.UpdateResources(ctx, "api", ResourceLimits{Memory: 1 << 30}); err != nil {
+		t.Fatalf("UpdateResources failed: %v", err)
+	}
+	detail, _ := fake.InspectContainer(ctx, "a1")
+	if detail.Limits.Memory != 1<<30 || detail.Limits.NanoCPUs != 1e9 {
+		t.Errorf("expected only memory changed, got %+v", detail.Limits)
+	}
+	if err := fake.UpdateResources(ctx, "missing", ResourceLimits{Memory: 1 << 30}); err == nil {
+		t.Error("expected an error for an unknown container")
+	}
+}
@@ -0,0 +1,105 @@
+package infra
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/docker/docker/api/types/container"
+	"github.com/docker/go-connections/nat"
+)
+
+// RunSpec describes a container to create and start, in the same terms as
+// `docker run`.
+type RunSpec struct {
+	Image string
+	Name  string
+	// Ports are publish specs such as "8080:80" or "127.0.0.1:5432:5432/tcp".
+	Ports []string
+	// Env entries are KEY=value.
+	Env []string
+	// Volumes are bind or named-volume specs such as "./data:/data:ro".
+	Volumes []string
+}
+
+// ParseRunSpec builds a RunSpec from comma-separated form fields, checking
+// each entry and making relative bind-mount sources absolute.
+func ParseRunSpec(image, name, ports, env, volumes string) (RunSpec, error) {
+	spec := RunSpec{
+		Image:   strings.TrimSpace(image),
+		Name:    strings.TrimSpace(name),
+		Ports:   splitFields(ports),
+		Env:     splitFields(env),
+		Volumes: splitFields(volumes),
+	}
+	if spec.Image == "" {
+		return spec, fmt.Errorf("image is required")
+	}
+	if _, _, err := nat.ParsePortSpecs(spec.Ports); err != nil {
+		return spec, fmt.Errorf("ports: %w", err)
+	}
+	for _, e := range spec.Env {
+		if k, _, ok := strings.Cut(e, "="); !ok || k == "" {
+			return spec, fmt.Errorf("env %q: expected KEY=value", e)
+		}
+	}
+	// Resolved volumes go into a new slice rather than over the parsed
+	// ones, so the spec returned with an error is left as it was given.
+	resolved := make([]string, 0, len(spec.Volumes))
+	for _, v := range spec.Volumes {
+		src, rest, ok := strings.Cut(v, ":")
+		if !ok || src == "" || rest == "" {
+			return spec, fmt.Errorf("volume %q: expected source:target", v)
+		}
+		if strings.HasPrefix(src, "~/") {
+			home, err := os.UserHomeDir()
+			if err != nil {
+				return spec, err
+			}
+			src = filepath.Join(home, src[2:])
+		}
+		if strings.HasPrefix(src, ".") {
+			abs, err := filepath.Abs(src)
+			if err != nil {
+				return spec, err
+			}
+			src = abs
+		}
+		resolved = append(resolved, src+":"+rest)
+	}
+	spec.Volumes = resolved
+	return spec, nil
+}
+
+func splitFields(s string) []string {
+	var out []string
+	for _, f := range strings.Split(s, ",") {
+		if f = strings.TrimSpace(f); f != "" {
+			out = append(out, f)
+		}
+	}
+	return out
+}
+
+// RunContainer creates and starts a container from spec and returns its
+// short ID. The image must already be present; pull it first if not.
+func (d *DockerClient) RunContainer(ctx context.Context, spec RunSpec) (string, error) {
+	exposed, bindings, err := nat.ParsePortSpecs(spec.Ports)
+	if err != nil {
+		return "", fmt.Errorf("ports: %w", err)
+	}
+
+	created, err := d.cli.ContainerCreate(ctx,
+		&container.Config{Image: spec.Image, Env: spec.Env, ExposedPorts: exposed},
+		&container.HostConfig{PortBindings: bindings, Binds: spec.Volumes},
+		nil, nil, spec.Name)
+	if err != nil {
+		return "", fmt.Errorf("create container failed: %w", err)
+	}
+	if err := d.cli.ContainerStart(ctx, created.ID, container.StartOptions{}); err != nil {
+		return "", fmt.Errorf("start container failed: %w", err)
+	}
+	return created.ID[:12], nil
+}
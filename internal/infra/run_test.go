@@ -0,0 +1,64 @@
+package infra
+
+import (
+	"context"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestParseRunSpec(t *testing.T) {
+	spec, err := ParseRunSpec(" nginx:alpine ", "web", "8080:80, 127.0.0.1:8443:443/tcp", "A=1,B=two", "./html:/usr/share/nginx/html:ro, cache:/cache")
+	if err != nil {
+		t.Fatalf("ParseRunSpec failed: %v", err)
+	}
+	if spec.Image != "nginx:alpine" || len(spec.Ports) != 2 || len(spec.Env) != 2 || len(spec.Volumes) != 2 {
+		t.Errorf("unexpected spec: %+v", spec)
+	}
+	if src, _, _ := strings.Cut(spec.Volumes[0], ":"); !filepath.IsAbs(src) {
+		t.Errorf("expected relative bind source made absolute, got %q", spec.Volumes[0])
+	}
+	if spec.Volumes[1] != "cache:/cache" {
+		t.Errorf("named volumes should pass through, got %q", spec.Volumes[1])
+	}
+
+	tests := []struct {
+		name                       string
+		image, ports, env, volumes string
+	}{
+		{"missing image", "", "", "", ""},
+		{"bad port", "nginx", "80:http", "", ""},
+		{"bad env", "nginx", "", "NOVALUE", ""},
+		{"bad volume", "nginx", "", "", "/data"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := ParseRunSpec(tt.image, "", tt.ports, tt.env, tt.volumes); err == nil {
+				t.Error("expected error")
+			}
+		})
+	}
+}
+
+func TestFakeDocker_RunContainer(t *testing.T) {
+	ctx := context.Background()
+	docker := NewFakeDocker()
+	docker.Images = []ImageInfo{{ID: "sha256:1", Tags: []string{"nginx:alpine"}}}
+
+	if _, err := docker.RunContainer(ctx, RunSpec{Image: "redis:7"}); err == nil {
+		t.Error("expected error for missing image")
+	}
+
+	id, err := docker.RunContainer(ctx, RunSpec{Image: "nginx:alpine", Name: "web", Ports: []string{"8080:80"}})
+	if err != nil {
+		t.Fatalf("RunContainer failed: %v", err)
+	}
+	c := docker.CheckHealth(ctx).Containers[0]
+	if c.ID != id || c.State != "running" || len(c.Ports) != 1 || c.Ports[0].Public != 8080 || c.Ports[0].Private != 80 {
+		t.Errorf("unexpected container: %+v", c)
+	}
+
+	if _, err := docker.RunContainer(ctx, RunSpec{Image: "nginx:alpine", Name: "web"}); err == nil {
+		t.Error("expected error for duplicate name")
+	}
+}
@@ -40,6 +40,12 @@ func DetectGPU() GPUProvider {
 		return &AppleGPUProvider{}
 	}
 
+	if runtime.GOOS == "linux" {
+		if p := detectDRMGPU(drmRoot); p != nil {
+			return p
+		}
+	}
+
 	return &NoGPUProvider{}
 }
 
@@ -402,15 +408,55 @@ func parseStarshipSegments(clean string) []string {
 	return filtered
 }
 
+// GetStarshipStatusLine is the starship prompt as one line for the agent's
+// status bar, keeping the colors of its segments.
 func GetStarshipStatusLine() string {
 	prompt := GetStarshipPrompt()
 	if !prompt.Available {
 		return ""
 	}
+	return starshipStatusLine(prompt.Raw)
+}
+
+var (
+	sgrPattern = regexp.MustCompile(`\x1b\[[0-9;]*m`)
+	// trailingSGR are the color sequences a line ends with.
+	trailingSGR = regexp.MustCompile(`(\x1b\[[0-9;]*m)+$`)
+	// promptMarkers are what shells wrap escape sequences in so they don't
+	// count toward the prompt's width: zsh's %{ %}, bash's \[ \] and
+	// readline's \x01 \x02.
+	promptMarkers = regexp.MustCompile(`%\{|%\}|\\\[|\\\]|[\x01\x02]`)
+	// otherEscapes are the escape sequences that aren't colors, like window
+	// titles, hyperlinks and cursor moves.
+	otherEscapes = regexp.MustCompile(`\x1b\][^\x07\x1b]*(\x07|\x1b\\)|\x1b\[[0-9;?]*[A-Za-ln-z]`)
+)
 
-	line := prompt.Clean
-	line = strings.TrimRight(line, "❯> \n\r")
-	line = strings.TrimSpace(line)
+// starshipStatusLine joins the lines of a raw starship prompt into one,
+// dropping the prompt character and every escape sequence but the SGR
+// colors.
+func starshipStatusLine(raw string) string {
+	raw = promptMarkers.ReplaceAllString(raw, "")
+	raw = otherEscapes.ReplaceAllString(raw, "")
+
+	var lines []string
+	for _, line := range strings.Split(raw, "\n") {
+		if strings.Trim(sgrPattern.ReplaceAllString(line, ""), "❯> \r\t") != "" {
+			lines = append(lines, strings.TrimSpace(line))
+		}
+	}
+	line := strings.Join(lines, " ")
 
+	// The prompt character may end the last line, in its own color.
+	for {
+		trimmed := strings.TrimRight(line, "❯> \r\t")
+		trimmed = trailingSGR.ReplaceAllString(trimmed, "")
+		if trimmed == line {
+			break
+		}
+		line = trimmed
+	}
+	if strings.Contains(line, "\x1b[") {
+		line += "\x1b[0m"
+	}
 	return line
 }
@@ -0,0 +1,39 @@
+package infra
+
+import "testing"
+
+func TestStarshipStatusLine(t *testing.T) {
+	tests := []struct {
+		name string
+		raw  string
+		want string
+	}{
+		{
+			name: "zsh, two lines",
+			raw:  "\n%{\x1b[1;36m%}dev-cli%{\x1b[0m%} on %{\x1b[1;35m%} main%{\x1b[0m%}\n%{\x1b[1;32m%}❯%{\x1b[0m%} ",
+			want: "\x1b[1;36mdev-cli\x1b[0m on \x1b[1;35m main\x1b[0m",
+		},
+		{
+			name: "bash, one line",
+			raw:  "\\[\x1b[1;34m\\]~/src\\[\x1b[0m\\] via \\[\x1b[38;5;208m\\]go\\[\x1b[0m\\] \\[\x1b[1;32m\\]❯\\[\x1b[0m\\] ",
+			want: "\x1b[1;34m~/src\x1b[0m via \x1b[38;5;208mgo\x1b[0m",
+		},
+		{
+			name: "title and hyperlink dropped",
+			raw:  "\x1b]0;dev-cli\x07\x1b]8;;file:///src\x1b\\src\x1b]8;;\x1b\\ ❯ ",
+			want: "src",
+		},
+		{
+			name: "only the prompt character",
+			raw:  "\x1b[1;32m❯\x1b[0m ",
+			want: "",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := starshipStatusLine(tt.raw); got != tt.want {
+				t.Errorf("starshipStatusLine() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,159 @@
+package infra
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"os/exec"
+	"strconv"
+	"strings"
+)
+
+// SystemdUnit names a unit to watch. User units (written "user:name") are
+// managed through `systemctl --user`.
+type SystemdUnit struct {
+	Name string
+	User bool
+}
+
+// ParseSystemdUnit reads a unit spec like "postgresql" or
+// "user:redis.service"; a name without a suffix is taken as a service.
+func ParseSystemdUnit(spec string) SystemdUnit {
+	spec = strings.TrimSpace(spec)
+	unit := SystemdUnit{Name: spec}
+	if name, ok := strings.CutPrefix(spec, "user:"); ok {
+		unit = SystemdUnit{Name: name, User: true}
+	}
+	if !strings.Contains(unit.Name, ".") {
+		unit.Name += ".service"
+	}
+	return unit
+}
+
+func (u SystemdUnit) String() string {
+	if u.User {
+		return "user:" + u.Name
+	}
+	return u.Name
+}
+
+// RestartCommand is the shell command that restarts the unit; system
+// units need root.
+func (u SystemdUnit) RestartCommand() string {
+	if u.User {
+		return "systemctl --user restart " + u.Name
+	}
+	return "sudo systemctl restart " + u.Name
+}
+
+// SystemdStatus is the state of a unit as `systemctl show` reports it.
+type SystemdStatus struct {
+	Unit        SystemdUnit
+	Description string
+	LoadState   string // "loaded", "not-found", ...
+	ActiveState string // "active", "failed", "inactive", ...
+	SubState    string // "running", "exited", "dead", ...
+	MainPID     int
+	Restarts    int
+	// Since is when the unit entered its active state, as systemd prints it.
+	Since string
+}
+
+// Found reports whether systemd knows the unit.
+func (s SystemdStatus) Found() bool {
+	return s.LoadState != "" && s.LoadState != "not-found"
+}
+
+// Healthy reports whether the unit is active (or activating/reloading).
+func (s SystemdStatus) Healthy() bool {
+	switch s.ActiveState {
+	case "active", "reloading", "activating":
+		return true
+	}
+	return false
+}
+
+var systemdProperties = []string{
+	"Description", "LoadState", "ActiveState", "SubState", "MainPID", "NRestarts", "ActiveEnterTimestamp",
+}
+
+// parseSystemdShow reads the KEY=value lines of `systemctl show`.
+func parseSystemdShow(unit SystemdUnit, out []byte) SystemdStatus {
+	status := SystemdStatus{Unit: unit}
+	scanner := bufio.NewScanner(bytes.NewReader(out))
+	for scanner.Scan() {
+		key, value, ok := strings.Cut(scanner.Text(), "=")
+		if !ok {
+			continue
+		}
+		switch key {
+		case "Description":
+			status.Description = value
+		case "LoadState":
+			status.LoadState = value
+		case "ActiveState":
+			status.ActiveState = value
+		case "SubState":
+			status.SubState = value
+		case "MainPID":
+			status.MainPID, _ = strconv.Atoi(value)
+		case "NRestarts":
+			status.Restarts, _ = strconv.Atoi(value)
+		case "ActiveEnterTimestamp":
+			status.Since = value
+		}
+	}
+	return status
+}
+
+// systemdRunner runs "systemctl <args>" and returns its combined output.
+type systemdRunner func(ctx context.Context, args ...string) ([]byte, error)
+
+func runSystemctl(ctx context.Context, args ...string) ([]byte, error) {
+	return exec.CommandContext(ctx, "systemctl", args...).CombinedOutput()
+}
+
+// SystemdClient queries and restarts systemd units through systemctl, for
+// databases and daemons that run on the host rather than in containers.
+type SystemdClient struct {
+	run systemdRunner
+}
+
+func NewSystemdClient() *SystemdClient {
+	return &SystemdClient{run: runSystemctl}
+}
+
+// SystemdAvailable reports whether systemctl is installed.
+func SystemdAvailable() bool {
+	_, err := exec.LookPath("systemctl")
+	return err == nil
+}
+
+func systemctlArgs(unit SystemdUnit, args ...string) []string {
+	if unit.User {
+		return append([]string{"--user"}, args...)
+	}
+	return args
+}
+
+// Status returns the state of unit. An unknown unit is not an error; its
+// status reports !Found.
+func (c *SystemdClient) Status(ctx context.Context, unit SystemdUnit) (SystemdStatus, error) {
+	args := systemctlArgs(unit, "show", "--property="+strings.Join(systemdProperties, ","), unit.Name)
+	out, err := c.run(ctx, args...)
+	if err != nil {
+		return SystemdStatus{Unit: unit}, fmt.Errorf("systemctl show %s: %w: %s", unit.Name, err, strings.TrimSpace(string(out)))
+	}
+	return parseSystemdShow(unit, out), nil
+}
+
+// Restart restarts unit. For a system unit this needs root, or a polkit
+// rule allowing it; RestartCommand is the interactive alternative.
+func (c *SystemdClient) Restart(ctx context.Context, unit SystemdUnit) error {
+	out, err := c.run(ctx, systemctlArgs(unit, "restart", unit.Name)...)
+	if err != nil {
+		return fmt.Errorf("restart %s: %w: %s", unit.Name, err, strings.TrimSpace(string(out)))
+	}
+	return nil
+}
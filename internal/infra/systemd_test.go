@@ -0,0 +1,61 @@
+package infra
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+)
+
+func TestParseSystemdUnit(t *testing.T) {
+	tests := []struct {
+		spec string
+		want SystemdUnit
+	}{
+		{spec: "postgresql", want: SystemdUnit{Name: "postgresql.service"}},
+		{spec: " redis.service ", want: SystemdUnit{Name: "redis.service"}},
+		{spec: "user:syncthing", want: SystemdUnit{Name: "syncthing.service", User: true}},
+		{spec: "user:backup.timer", want: SystemdUnit{Name: "backup.timer", User: true}},
+	}
+	for _, tt := range tests {
+		if got := ParseSystemdUnit(tt.spec); got != tt.want {
+			t.Errorf("ParseSystemdUnit(%q) = %+v, want %+v", tt.spec, got, tt.want)
+		}
+	}
+}
+
+func TestSystemdClient(t *testing.T) {
+	var calls [][]string
+	client := &SystemdClient{run: func(_ context.Context, args ...string) ([]byte, error) {
+		calls = append(calls, args)
+		if args[len(args)-2] == "restart" {
+			return []byte("Failed to restart: access denied"), errors.New("exit status 1")
+		}
+		return []byte("Description=PostgreSQL\nLoadState=loaded\nActiveState=failed\nSubState=failed\nMainPID=0\nNRestarts=3\nActiveEnterTimestamp=\n"), nil
+	}}
+	ctx := context.Background()
+
+	unit := ParseSystemdUnit("user:postgresql")
+	status, err := client.Status(ctx, unit)
+	if err != nil {
+		t.Fatalf("Status failed: %v", err)
+	}
+	if !status.Found() || status.Healthy() || status.Restarts != 3 || status.Description != "PostgreSQL" {
+		t.Errorf("status = %+v", status)
+	}
+	if calls[0][0] != "--user" || calls[0][len(calls[0])-1] != "postgresql.service" {
+		t.Errorf("unexpected systemctl args %v", calls[0])
+	}
+
+	err = client.Restart(ctx, ParseSystemdUnit("postgresql"))
+	if err == nil || !slices.Equal(calls[1], []string{"restart", "postgresql.service"}) {
+		t.Errorf("Restart = %v with args %v", err, calls[1])
+	}
+}
+
+func TestSystemdStatus_NotFound(t *testing.T) {
+	status := parseSystemdShow(SystemdUnit{Name: "nope.service"}, []byte("LoadState=not-found\nActiveState=inactive\n"))
+	if status.Found() || status.Healthy() {
+		t.Errorf("status = %+v", status)
+	}
+}
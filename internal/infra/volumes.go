@@ -0,0 +1,179 @@
+package infra
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/api/types/mount"
+)
+
+// volumeHelperImage is the image of the throwaway container that mounts a
+// volume for backup and restore. It is never started, only copied from.
+const volumeHelperImage = "busybox:latest"
+
+// volumeMount is where the helper container mounts the volume. Backups are
+// tars of this directory, so their entries start with "volume/".
+const volumeMount = "/volume"
+
+// backupStamp names backups <volume>-<stamp>.tar.
+const backupStamp = "20060102-150405"
+
+// BackupDir is where volume backups are written.
+func (c Config) BackupDir() string {
+	return filepath.Join(c.DevlogsDir, "backups")
+}
+
+// VolumeBackup is a backup tar of a volume.
+type VolumeBackup struct {
+	Volume  string
+	Path    string
+	Size    int64
+	Created time.Time
+}
+
+func backupPath(dir, volume string, at time.Time) string {
+	return filepath.Join(dir, volume+"-"+at.Format(backupStamp)+".tar")
+}
+
+// ListVolumeBackups returns the backups of volume in dir, newest first. A
+// missing dir has no backups.
+func ListVolumeBackups(dir, volume string) ([]VolumeBackup, error) {
+	entries, err := os.ReadDir(dir)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var backups []VolumeBackup
+	for _, e := range entries {
+		name, isTar := strings.CutSuffix(e.Name(), ".tar")
+		stamp, ours := strings.CutPrefix(name, volume+"-")
+		// The stamp length tells "db-<stamp>" from "db-data-<stamp>".
+		if !isTar || !ours || e.IsDir() || len(stamp) != len(backupStamp) {
+			continue
+		}
+		created, err := time.ParseInLocation(backupStamp, stamp, time.Local)
+		if err != nil {
+			continue
+		}
+		info, err := e.Info()
+		if err != nil {
+			continue
+		}
+		backups = append(backups, VolumeBackup{
+			Volume:  volume,
+			Path:    filepath.Join(dir, e.Name()),
+			Size:    info.Size(),
+			Created: created,
+		})
+	}
+	sort.Slice(backups, func(i, j int) bool { return backups[i].Created.After(backups[j].Created) })
+	return backups, nil
+}
+
+// volumeHelper creates a stopped container with the volume mounted at
+// volumeMount, pulling the helper image first if needed. remove deletes it.
+func (d *DockerClient) volumeHelper(ctx context.Context, volume string) (id string, remove func(), err error) {
+	if _, err := d.cli.VolumeInspect(ctx, volume); err != nil {
+		return "", nil, fmt.Errorf("volume %s: %w", volume, err)
+	}
+	if _, err := d.cli.ImageInspect(ctx, volumeHelperImage); err != nil {
+		if err := d.PullImage(ctx, volumeHelperImage, func(PullProgress) {}); err != nil {
+			return "", nil, err
+		}
+	}
+
+	created, err := d.cli.ContainerCreate(ctx,
+		&container.Config{Image: volumeHelperImage},
+		&container.HostConfig{Mounts: []mount.Mount{{Type: mount.TypeVolume, Source: volume, Target: volumeMount}}},
+		nil, nil, "")
+	if err != nil {
+		return "", nil, fmt.Errorf("create helper container failed: %w", err)
+	}
+	return created.ID, func() {
+		d.cli.ContainerRemove(context.Background(), created.ID, container.RemoveOptions{Force: true})
+	}, nil
+}
+
+// BackupVolume writes the contents of a volume to a tar in dir and returns
+// its path. The volume is read through a stopped helper container, so
+// containers using it keep running.
+func (d *DockerClient) BackupVolume(ctx context.Context, volume, dir string) (string, error) {
+	id, remove, err := d.volumeHelper(ctx, volume)
+	if err != nil {
+		return "", fmt.Errorf("backup failed: %w", err)
+	}
+	defer remove()
+
+	reader, _, err := d.cli.CopyFromContainer(ctx, id, volumeMount)
+	if err != nil {
+		return "", fmt.Errorf("backup failed: %w", err)
+	}
+	defer reader.Close()
+
+	backup, err := writeBackup(reader, dir, volume)
+	if err != nil {
+		return "", fmt.Errorf("backup failed: %w", err)
+	}
+	return backup, nil
+}
+
+// writeBackup stores a backup tar under its final name only once it is
+// complete, so an interrupted backup never shows up as restorable.
+func writeBackup(r io.Reader, dir, volume string) (string, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return "", err
+	}
+	tmp, err := os.CreateTemp(dir, ".backup-*")
+	if err != nil {
+		return "", err
+	}
+	defer os.Remove(tmp.Name())
+
+	_, err = io.Copy(tmp, r)
+	if cerr := tmp.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		return "", err
+	}
+
+	backup := backupPath(dir, volume, time.Now())
+	if err := os.Rename(tmp.Name(), backup); err != nil {
+		return "", err
+	}
+	return backup, nil
+}
+
+// RestoreVolume unpacks a backup made by BackupVolume into a volume.
+// Files in the backup overwrite those in the volume; files that are only
+// in the volume are left alone.
+func (d *DockerClient) RestoreVolume(ctx context.Context, volume, backup string) error {
+	f, err := os.Open(backup)
+	if err != nil {
+		return fmt.Errorf("restore failed: %w", err)
+	}
+	defer f.Close()
+
+	id, remove, err := d.volumeHelper(ctx, volume)
+	if err != nil {
+		return fmt.Errorf("restore failed: %w", err)
+	}
+	defer remove()
+
+	// The entries start with "volume/", so they land in volumeMount.
+	if err := d.cli.CopyToContainer(ctx, id, path.Dir(volumeMount), f, container.CopyToContainerOptions{}); err != nil {
+		return fmt.Errorf("restore failed: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,114 @@
+package infra
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/api/types/mount"
+)
+
+func TestListVolumeBackups(t *testing.T) {
+	dir := t.TempDir()
+	older := time.Date(2026, 1, 1, 10, 0, 0, 0, time.Local)
+	for _, p := range []string{
+		backupPath(dir, "db", older),
+		backupPath(dir, "db", older.Add(time.Hour)),
+		backupPath(dir, "db-data", older),
+		filepath.Join(dir, "db-notes.txt"),
+	} {
+		os.WriteFile(p, []byte("x"), 0o644)
+	}
+
+	backups, err := ListVolumeBackups(dir, "db")
+	if err != nil {
+		t.Fatalf("ListVolumeBackups failed: %v", err)
+	}
+	if len(backups) != 2 {
+		t.Fatalf("expected 2 backups of db, got %+v", backups)
+	}
+	if !backups[0].Created.Equal(older.Add(time.Hour)) || backups[0].Size != 1 {
+		t.Errorf("expected the newest first, got %+v", backups[0])
+	}
+
+	if backups, err := ListVolumeBackups(filepath.Join(dir, "missing"), "db"); err != nil || backups != nil {
+		t.Errorf("missing dir = %+v, %v", backups, err)
+	}
+}
+
+func TestFakeDocker_BackupRestoreVolume(t *testing.T) {
+	fake := NewFakeDocker()
+	fake.Volumes = []VolumeInfo{{Name: "pgdata"}}
+	fake.VolumeData["pgdata"] = map[string]string{"PG_VERSION": "16", "base/1/1259": "rows"}
+	ctx := context.Background()
+	dir := t.TempDir()
+
+	backup, err := fake.BackupVolume(ctx, "pgdata", dir)
+	if err != nil {
+		t.Fatalf("BackupVolume failed: %v", err)
+	}
+	if backups, _ := ListVolumeBackups(dir, "pgdata"); len(backups) != 1 || backups[0].Path != backup {
+		t.Fatalf("expected the backup to be listed, got %+v", backups)
+	}
+
+	fake.VolumeData["pgdata"] = map[string]string{"PG_VERSION": "broken"}
+	if err := fake.RestoreVolume(ctx, "pgdata", backup); err != nil {
+		t.Fatalf("RestoreVolume failed: %v", err)
+	}
+	if got := fake.VolumeData["pgdata"]; got["PG_VERSION"] != "16" || got["base/1/1259"] != "rows" {
+		t.Errorf("restored data = %v", got)
+	}
+
+	if _, err := fake.BackupVolume(ctx, "missing", dir); err == nil {
+		t.Error("expected an error for an unknown volume")
+	}
+}
+
+func TestVolumeUsers(t *testing.T) {
+	users := volumeUsers([]container.Summary{
+		{ID: "a1", Names: []string{"/db"}, Mounts: []container.MountPoint{
+			{Type: mount.TypeVolume, Name: "pgdata"},
+			{Type: mount.TypeBind, Source: "/srv/conf"},
+		}},
+		{ID: "b2", Names: []string{"/backup"}, Mounts: []container.MountPoint{
+			{Type: mount.TypeVolume, Name: "pgdata"},
+			{Type: mount.TypeVolume, Name: "cache"},
+		}},
+		{ID: "c3"},
+	})
+
+	if got := users["pgdata"]; !slices.Equal(got, []string{"db", "backup"}) {
+		t.Errorf("pgdata users = %v", got)
+	}
+	if got := users["cache"]; !slices.Equal(got, []string{"backup"}) {
+		t.Errorf("cache users = %v", got)
+	}
+	if len(users) != 2 {
+		t.Errorf("expected only volume mounts, got %v", users)
+	}
+}
+
+func TestFakeDocker_RemoveVolume(t *testing.T) {
+	fake := NewFakeDocker()
+	fake.Volumes = []VolumeInfo{{Name: "pgdata", Containers: []string{"db"}}, {Name: "scratch"}}
+	fake.VolumeData["scratch"] = map[string]string{"tmp": "x"}
+	ctx := context.Background()
+
+	if err := fake.RemoveVolume(ctx, "pgdata", true); err == nil || !strings.Contains(err.Error(), "in use") {
+		t.Errorf("expected a volume in use to stay, got %v", err)
+	}
+	if err := fake.RemoveVolume(ctx, "scratch", false); err != nil {
+		t.Fatalf("RemoveVolume failed: %v", err)
+	}
+	if len(fake.Volumes) != 1 || fake.VolumeData["scratch"] != nil {
+		t.Errorf("expected scratch and its data gone, got %+v", fake.Volumes)
+	}
+	if err := fake.RemoveVolume(ctx, "scratch", false); err == nil {
+		t.Error("expected an error for an unknown volume")
+	}
+}
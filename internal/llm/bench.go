@@ -0,0 +1,140 @@
+package llm
+
+import (
+	"encoding/json"
+	"sort"
+	"strings"
+	"time"
+)
+
+// BenchCase is one prompt in the benchmark suite. Kind is "explain" or
+// "research" and selects both the prompt builder and the JSON shape a
+// response must have to count as valid.
+type BenchCase struct {
+	Name     string
+	Kind     string
+	Command  string
+	ExitCode int
+	Output   string
+	Query    string
+}
+
+// BenchSuite is the fixed set of prompts every model is measured on. It
+// mirrors what the CLI asks in practice: short failure explanations and
+// multi-solution research answers.
+var BenchSuite = []BenchCase{
+	{Name: "npm missing package.json", Kind: "explain", Command: "npm start", ExitCode: 254,
+		Output: "npm ERR! code ENOENT\nnpm ERR! enoent Could not read package.json"},
+	{Name: "go build undefined", Kind: "explain", Command: "go build ./...", ExitCode: 1,
+		Output: "./main.go:12:2: undefined: handler"},
+	{Name: "permission denied", Kind: "explain", Command: "./deploy.sh", ExitCode: 126,
+		Output: "bash: ./deploy.sh: Permission denied"},
+	{Name: "postgres setup", Kind: "research", Query: "set up a local postgres database for development"},
+	{Name: "python venv", Kind: "research", Query: "create an isolated python environment for a project"},
+}
+
+// BenchResult aggregates one model's runs over the suite.
+type BenchResult struct {
+	Model     string
+	Runs      int
+	Errors    int
+	ValidJSON int
+	Latency   time.Duration // mean wall time per successful call
+	Tokens    int
+	// TokensPerSec is generated tokens over model eval time, as reported by
+	// Ollama, so it excludes model load and prompt processing.
+	TokensPerSec float64
+}
+
+// ValidRate is the fraction of runs that returned usable JSON.
+func (r BenchResult) ValidRate() float64 {
+	if r.Runs == 0 {
+		return 0
+	}
+	return float64(r.ValidJSON) / float64(r.Runs)
+}
+
+// WithModel returns a copy of c that talks to a different model.
+func (c *Client) WithModel(model string) *Client {
+	clone := *c
+	clone.model = model
+	return &clone
+}
+
+// Bench runs suite rounds times against the client's model. progress, if
+// set, is called after every prompt.
+func (c *Client) Bench(suite []BenchCase, rounds int, progress func(BenchCase, error)) BenchResult {
+	result := BenchResult{Model: c.model}
+	var total time.Duration
+	var evalTime time.Duration
+
+	for round := 0; round < rounds; round++ {
+		for _, bc := range suite {
+			result.Runs++
+
+			prompt := researchPrompt(ProjectContext{}, bc.Query)
+			if bc.Kind == "explain" {
+				prompt = explainPrompt(ProjectContext{}, bc.Command, bc.ExitCode, bc.Output)
+			}
+
+			start := time.Now()
+			resp, err := c.generateWith(c.model, prompt, "json")
+			if progress != nil {
+				progress(bc, err)
+			}
+			if err != nil {
+				result.Errors++
+				continue
+			}
+			total += time.Since(start)
+			result.Tokens += resp.EvalCount
+			evalTime += time.Duration(resp.EvalDuration)
+
+			if validBenchJSON(bc.Kind, resp.Response) {
+				result.ValidJSON++
+			}
+		}
+	}
+
+	if ok := result.Runs - result.Errors; ok > 0 {
+		result.Latency = total / time.Duration(ok)
+	}
+	if evalTime > 0 {
+		result.TokensPerSec = float64(result.Tokens) / evalTime.Seconds()
+	}
+	return result
+}
+
+func validBenchJSON(kind, response string) bool {
+	response = strings.TrimSpace(response)
+	if kind == "explain" {
+		var r ExplainResult
+		return json.Unmarshal([]byte(response), &r) == nil && r.Explanation != ""
+	}
+	var r ResearchResult
+	return json.Unmarshal([]byte(response), &r) == nil && len(r.Solutions) > 0
+}
+
+// RecommendModel picks the model to use by default: the fastest among those
+// within ten points of the best JSON-validity rate. Models that never
+// produced valid JSON are not recommended; ok is false if none qualify.
+func RecommendModel(results []BenchResult) (model string, ok bool) {
+	best := 0.0
+	for _, r := range results {
+		best = max(best, r.ValidRate())
+	}
+	if best == 0 {
+		return "", false
+	}
+
+	var candidates []BenchResult
+	for _, r := range results {
+		if r.ValidRate() >= best-0.1 {
+			candidates = append(candidates, r)
+		}
+	}
+	sort.SliceStable(candidates, func(i, j int) bool {
+		return candidates[i].Latency < candidates[j].Latency
+	})
+	return candidates[0].Model, true
+}
@@ -0,0 +1,62 @@
+package llm
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestBench_MeasuresValidityAndThroughput(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req generateRequest
+		json.NewDecoder(r.Body).Decode(&req)
+
+		resp := generateResponse{Done: true, EvalCount: 50, EvalDuration: int64(500 * time.Millisecond)}
+		switch {
+		case req.Model == "broken":
+			resp.Response = "not json"
+		case strings.Contains(req.Prompt, "CLI error analyzer"):
+			resp.Response = `{"explanation": "missing file", "fix": ""}`
+		default:
+			resp.Response = `{"solutions": [{"id": 1, "title": "Docker"}]}`
+		}
+		json.NewEncoder(w).Encode(resp)
+	}))
+	defer server.Close()
+
+	client := &Client{baseURL: server.URL, model: "good", httpClient: http.DefaultClient}
+
+	good := client.Bench(BenchSuite, 2, nil)
+	if good.Runs != 2*len(BenchSuite) || good.ValidJSON != good.Runs {
+		t.Errorf("expected all %d runs valid, got %+v", 2*len(BenchSuite), good)
+	}
+	if good.TokensPerSec != 100 {
+		t.Errorf("expected 100 tokens/s, got %.1f", good.TokensPerSec)
+	}
+
+	broken := client.WithModel("broken").Bench(BenchSuite, 1, nil)
+	if broken.ValidJSON != 0 {
+		t.Errorf("expected no valid JSON from broken model, got %d", broken.ValidJSON)
+	}
+	if client.model != "good" {
+		t.Error("WithModel must not modify the original client")
+	}
+}
+
+func TestRecommendModel(t *testing.T) {
+	results := []BenchResult{
+		{Model: "big", Runs: 10, ValidJSON: 10, Latency: 3 * time.Second},
+		{Model: "small", Runs: 10, ValidJSON: 9, Latency: time.Second},
+		{Model: "tiny", Runs: 10, ValidJSON: 5, Latency: 200 * time.Millisecond},
+	}
+	if got, ok := RecommendModel(results); !ok || got != "small" {
+		t.Errorf("expected small (fast and nearly as valid), got %q", got)
+	}
+
+	if _, ok := RecommendModel([]BenchResult{{Model: "broken", Runs: 5}}); ok {
+		t.Error("a model with no valid JSON should not be recommended")
+	}
+}
@@ -0,0 +1,202 @@
+package llm
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+)
+
+// ChatTimeout bounds a whole chat reply; local models can take a while to
+// stream a long answer.
+const ChatTimeout = 5 * time.Minute
+
+// Chat providers.
+const (
+	ProviderOllama     = "ollama"
+	ProviderPerplexity = "perplexity"
+)
+
+// PerplexityChatModels are offered for chat alongside the configured
+// Perplexity model.
+var PerplexityChatModels = []string{"sonar", "sonar-pro", "sonar-reasoning-pro"}
+
+const chatSystemPrompt = "You are a helpful developer assistant in a terminal. Answer concisely and put commands and code in markdown code blocks."
+
+// ChatMessage is one turn of a conversation; Role is "user" or "assistant".
+type ChatMessage struct {
+	Role    string `json:"role"`
+	Content string `json:"content"`
+}
+
+// ChatModel is a model a conversation can be held with.
+type ChatModel struct {
+	Provider string
+	Name     string
+}
+
+func (m ChatModel) String() string {
+	return m.Provider + "/" + m.Name
+}
+
+type chatRequest struct {
+	Model    string        `json:"model"`
+	Messages []ChatMessage `json:"messages"`
+	Stream   bool          `json:"stream"`
+}
+
+type chatChunk struct {
+	Message ChatMessage `json:"message"`
+	Done    bool        `json:"done"`
+	Error   string      `json:"error,omitempty"`
+}
+
+// Chat continues the conversation in messages with model, streaming the
+// reply to onDelta as it arrives. The reply is returned whole as well.
+func (c *Client) Chat(model string, messages []ChatMessage, onDelta func(string)) (string, error) {
+	reqBody, err := json.Marshal(chatRequest{
+		Model:    model,
+		Messages: append([]ChatMessage{{Role: "system", Content: chatSystemPrompt}}, messages...),
+		Stream:   true,
+	})
+	if err != nil {
+		return "", fmt.Errorf("marshal request: %w", err)
+	}
+
+	client := *c.httpClient
+	client.Timeout = ChatTimeout
+	resp, err := client.Post(c.baseURL+"/api/chat", "application/json", bytes.NewReader(reqBody))
+	if err != nil {
+		return "", fmt.Errorf("call Ollama: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return "", &statusError{Code: resp.StatusCode, Body: string(body)}
+	}
+
+	var reply strings.Builder
+	scanner := bufio.NewScanner(resp.Body)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		var chunk chatChunk
+		if err := json.Unmarshal(scanner.Bytes(), &chunk); err != nil {
+			return "", fmt.Errorf("decode stream chunk: %w", err)
+		}
+		if chunk.Error != "" {
+			return "", fmt.Errorf("ollama: %s", chunk.Error)
+		}
+		if delta := chunk.Message.Content; delta != "" {
+			reply.WriteString(delta)
+			if onDelta != nil {
+				onDelta(delta)
+			}
+		}
+		if chunk.Done {
+			break
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return "", fmt.Errorf("read stream: %w", err)
+	}
+	return reply.String(), nil
+}
+
+// Models lists the models installed in Ollama.
+func (c *Client) Models() ([]string, error) {
+	resp, err := c.httpClient.Get(c.baseURL + "/api/tags")
+	if err != nil {
+		return nil, fmt.Errorf("call Ollama: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return nil, &statusError{Code: resp.StatusCode, Body: string(body)}
+	}
+
+	var tags struct {
+		Models []struct {
+			Name string `json:"name"`
+		} `json:"models"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&tags); err != nil {
+		return nil, fmt.Errorf("decode response: %w", err)
+	}
+	names := make([]string, 0, len(tags.Models))
+	for _, m := range tags.Models {
+		names = append(names, m.Name)
+	}
+	return names, nil
+}
+
+// Chat continues the conversation in messages with model, streaming the
+// reply to onDelta as it arrives.
+func (c *PerplexityClient) Chat(ctx context.Context, model string, messages []ChatMessage, onDelta func(string)) (string, error) {
+	msgs := []perplexityMessage{{Role: "system", Content: chatSystemPrompt}}
+	for _, m := range messages {
+		msgs = append(msgs, perplexityMessage{Role: m.Role, Content: m.Content})
+	}
+	reply, err := c.send(ctx, model, msgs, onDelta)
+	if err != nil {
+		return "", err
+	}
+	if len(reply.Citations) > 0 {
+		var sources strings.Builder
+		for i, url := range reply.Citations {
+			fmt.Fprintf(&sources, "\n[%d] %s", i+1, url)
+		}
+		if onDelta != nil {
+			onDelta("\n" + sources.String())
+		}
+		return reply.Content + "\n" + sources.String(), nil
+	}
+	return reply.Content, nil
+}
+
+// Chat continues the conversation in messages with model, which picks the
+// backend. Unlike the other features, chat is not routed: the model chosen
+// is the one that answers.
+func (h *HybridClient) Chat(model ChatModel, messages []ChatMessage, onDelta func(string)) (string, error) {
+	switch model.Provider {
+	case ProviderOllama:
+		return h.ollama.Chat(model.Name, messages, onDelta)
+	case ProviderPerplexity:
+		if h.perplexity == nil {
+			return "", fmt.Errorf("perplexity is not configured")
+		}
+		return h.perplexity.Chat(context.Background(), model.Name, messages, onDelta)
+	}
+	return "", fmt.Errorf("unknown provider %q", model.Provider)
+}
+
+// ChatModels lists the models to chat with: the configured Ollama model
+// first, the other installed ones, then Perplexity's when it is configured.
+// An unreachable Ollama still offers the configured model.
+func (h *HybridClient) ChatModels() ([]ChatModel, error) {
+	models := []ChatModel{{Provider: ProviderOllama, Name: h.ollama.model}}
+	installed, err := h.ollama.Models()
+	for _, name := range installed {
+		if name != h.ollama.model && !strings.Contains(name, "embed") {
+			models = append(models, ChatModel{Provider: ProviderOllama, Name: name})
+		}
+	}
+
+	if h.perplexity != nil {
+		names := PerplexityChatModels
+		if !slices.Contains(names, h.perplexity.model) {
+			names = append([]string{h.perplexity.model}, names...)
+		}
+		for _, name := range names {
+			models = append(models, ChatModel{Provider: ProviderPerplexity, Name: name})
+		}
+	}
+	return models, err
+}
@@ -0,0 +1,77 @@
+package llm
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestChat_StreamsConversation(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/chat" {
+			t.Errorf("unexpected path: %s", r.URL.Path)
+		}
+		var req chatRequest
+		json.NewDecoder(r.Body).Decode(&req)
+		if req.Model != "chatty" || !req.Stream {
+			t.Errorf("unexpected request: %+v", req)
+		}
+		if len(req.Messages) != 4 || req.Messages[0].Role != "system" || req.Messages[3].Content != "and in Go?" {
+			t.Errorf("expected the system prompt and the whole conversation, got %+v", req.Messages)
+		}
+		for _, part := range []string{"Use ", "`strings.Fields`", "."} {
+			fmt.Fprintf(w, `{"message":{"role":"assistant","content":%q},"done":false}`+"\n", part)
+		}
+		fmt.Fprintln(w, `{"message":{"role":"assistant","content":""},"done":true}`)
+	}))
+	defer server.Close()
+
+	client := &Client{baseURL: server.URL, httpClient: http.DefaultClient}
+	var deltas []string
+	reply, err := client.Chat("chatty", []ChatMessage{
+		{Role: "user", Content: "how do I split words in Python?"},
+		{Role: "assistant", Content: "Use `str.split()`."},
+		{Role: "user", Content: "and in Go?"},
+	}, func(d string) { deltas = append(deltas, d) })
+	if err != nil {
+		t.Fatalf("Chat failed: %v", err)
+	}
+	if reply != "Use `strings.Fields`." || len(deltas) != 3 {
+		t.Errorf("unexpected reply %q from deltas %q", reply, deltas)
+	}
+}
+
+func TestHybridChatModels(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"models":[{"name":"llama3.2:3b"},{"name":"configured:3b"},{"name":"nomic-embed-text:latest"}]}`)
+	}))
+	defer server.Close()
+
+	h := &HybridClient{
+		ollama:     &Client{baseURL: server.URL, model: "configured:3b", httpClient: http.DefaultClient},
+		perplexity: &PerplexityClient{model: "sonar-pro"},
+	}
+	models, err := h.ChatModels()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var names []string
+	for _, m := range models {
+		names = append(names, m.String())
+	}
+	want := []string{"ollama/configured:3b", "ollama/llama3.2:3b", "perplexity/sonar", "perplexity/sonar-pro", "perplexity/sonar-reasoning-pro"}
+	if !slices.Equal(names, want) {
+		t.Errorf("expected %v, got %v", want, names)
+	}
+
+	h.ollama.baseURL = "http://127.0.0.1:1"
+	h.perplexity = nil
+	models, err = h.ChatModels()
+	if err == nil || len(models) != 1 || !strings.HasSuffix(models[0].String(), "configured:3b") {
+		t.Errorf("expected the configured model despite Ollama being down, got %v, %v", models, err)
+	}
+}
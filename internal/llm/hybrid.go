@@ -46,11 +46,17 @@ type Solution struct {
 type ResearchResult struct {
 	Query     string     `json:"query"`
 	Solutions []Solution `json:"solutions"`
+	// Citations are the web sources behind a cloud answer, in the order the
+	// model referenced them.
+	Citations []string `json:"citations,omitempty"`
+	// Model names the model that produced the answer.
+	Model string `json:"-"`
 }
 
 type LogAnalysisResult struct {
 	Explanation string `json:"explanation"`
 	Fix         string `json:"fix"`
+	Model       string `json:"-"`
 }
 
 type cacheEntry struct {
@@ -147,6 +153,9 @@ type HybridClient struct {
 	perplexity *PerplexityClient
 	ollama     *Client
 	cache      *ResponseCache
+	offline    bool
+	cfg        *config.Config
+	project    ProjectContext
 }
 
 var defaultCache = NewResponseCache(50, 10*time.Minute)
@@ -157,37 +166,88 @@ func NewHybridClient() *HybridClient {
 		perplexity: NewPerplexityClient(cfg),
 		ollama:     NewClient(cfg),
 		cache:      defaultCache,
+		offline:    cfg.Offline,
+		cfg:        cfg,
 	}
 }
 
+// useCloud reports whether feature should go to Perplexity for input,
+// according to the routing table. Without a Perplexity client everything
+// runs locally.
+func (h *HybridClient) useCloud(feature, input string) bool {
+	if h.perplexity == nil || h.cfg == nil {
+		return false
+	}
+	switch h.cfg.Route(feature) {
+	case config.RouteCloud:
+		return true
+	case config.RouteAuto:
+		return needsWebSearch(input)
+	}
+	return false
+}
+
 func (h *HybridClient) Research(query string) (*ResearchResult, error) {
+	return h.ResearchStream(query, nil)
+}
 
-	if cached, ok := h.cache.Get(query); ok {
+// ResearchStream is Research with cloud answers streamed to onDelta as they
+// arrive, so callers can show progress. Local and cached answers arrive in
+// one piece and onDelta is not called.
+func (h *HybridClient) ResearchStream(query string, onDelta func(string)) (*ResearchResult, error) {
+	key := query
+	if h.project.Type != "" {
+		key += "\x00" + h.project.Type + "\x00" + strings.Join(h.project.Docs, "\x00")
+	}
+	if cached, ok := h.cache.Get(key); ok {
 		return cached, nil
 	}
 
 	var result *ResearchResult
 	var err error
 
-	if h.perplexity != nil && needsWebSearch(query) {
-		result, err = h.perplexity.Research(context.Background(), query)
+	if h.useCloud(config.FeatureResearch, query) {
+		result, err = h.perplexity.ResearchStream(context.Background(), query, onDelta)
 		if err == nil {
-			h.cache.Set(query, result)
+			h.cache.Set(key, result)
 			return result, nil
 		}
 	}
 
 	result, err = h.ollama.Research(query)
 	if err == nil {
-		h.cache.Set(query, result)
+		h.cache.Set(key, result)
 	}
 	return result, err
 }
 
+// Embed embeds texts with the local embedding model. Embeddings are only
+// used for local retrieval, so they never go to the cloud.
+func (h *HybridClient) Embed(texts []string) ([][]float32, error) {
+	return h.ollama.Embed(texts)
+}
+
+// WithProject returns a client whose Explain and Research prompts carry the
+// given project context. The cache is shared, keyed by project type and
+// retrieved docs.
+func (h *HybridClient) WithProject(p ProjectContext) LLMProvider {
+	clone := *h
+	clone.project = p
+	clone.ollama = h.ollama.WithProject(p)
+	if h.perplexity != nil {
+		clone.perplexity = h.perplexity.WithProject(p)
+	}
+	return &clone
+}
+
 func (h *HybridClient) HasPerplexity() bool {
 	return h.perplexity != nil
 }
 
+func (h *HybridClient) IsOffline() bool {
+	return h.offline
+}
+
 func (h *HybridClient) CacheStats() (size int, capacity int) {
 	return h.cache.Stats()
 }
@@ -196,27 +256,135 @@ func (h *HybridClient) ClearCache() {
 	h.cache.Clear()
 }
 
+// AnalyzeLog finds the error in a batch of log lines. Logs are sanitized
+// here; the prompt builders fence them and strip embedded instructions.
+// aiMode ("local", "cloud" or "auto") overrides the analyze route; leave it
+// empty to follow the routing table.
 func (h *HybridClient) AnalyzeLog(logLines string, aiMode string) (*LogAnalysisResult, error) {
+	logLines = PrepareForLLM(logLines, 8000)
 	if os.Getenv("DEV_CLI_FORCE_LOCAL") != "" || aiMode == "local" {
 		return h.ollama.AnalyzeLog(logLines)
 	}
 
 	if aiMode == "cloud" {
+		if h.offline {
+			return nil, fmt.Errorf("cloud AI requested but offline mode is enabled")
+		}
 		if h.perplexity != nil {
 			return h.perplexity.AnalyzeLog(context.Background(), logLines)
 		}
 		return nil, fmt.Errorf("cloud AI requested but PERPLEXITY_API_KEY is not set")
 	}
 
+	cloud := h.useCloud(config.FeatureAnalyze, logLines)
+	if aiMode == "auto" {
+		cloud = h.perplexity != nil && needsWebSearch(logLines)
+	}
+	if cloud {
+		if result, err := h.perplexity.AnalyzeLog(context.Background(), logLines); err == nil {
+			return result, nil
+		}
+	}
 	return h.ollama.AnalyzeLog(logLines)
 }
 
+// Solve turns a goal into a single shell command, on the backend the solve
+// route picks. Cloud failures fall back to the local model.
 func (h *HybridClient) Solve(goal string) (string, error) {
+	if h.useCloud(config.FeatureSolve, goal) {
+		if command, err := h.perplexity.Solve(context.Background(), goal); err == nil {
+			return command, nil
+		}
+	}
 	return h.ollama.Solve(goal)
 }
 
+// Explain asks why a command failed and for a one-line fix. Output is
+// sanitized first since it often carries tokens and paths; the explain
+// route picks the backend, falling back to the local model.
+func (h *HybridClient) Explain(cmd string, exitCode int, output string) (*ExplainResult, error) {
+	output = PrepareForLLM(output, 2000)
+	if h.useCloud(config.FeatureExplain, cmd) {
+		if result, err := h.perplexity.Explain(context.Background(), cmd, exitCode, output); err == nil {
+			return result, nil
+		}
+	}
+	return h.ollama.Explain(cmd, exitCode, output)
+}
+
+// CommitMessage drafts a conventional-commit message for a staged diff. The
+// diff is sanitized locally before it is sent to the model.
+func (h *HybridClient) CommitMessage(diff string) (string, error) {
+	return h.ollama.CommitMessage(PrepareForLLM(diff, 6000))
+}
+
+// GenerateWorkflow drafts workflow YAML for goal. Research results are used
+// as reference material when available; validate is called on every draft
+// and its error fed back to the model for up to maxAttempts tries.
+func (h *HybridClient) GenerateWorkflow(goal string, validate func(string) error) (string, error) {
+	const maxAttempts = 3
+
+	var research strings.Builder
+	if result, err := h.Research(goal); err == nil && len(result.Solutions) > 0 {
+		for _, step := range result.Solutions[0].Steps {
+			if step.Type == "command" {
+				research.WriteString("- " + step.Content + "\n")
+			}
+		}
+	}
+
+	var lastErr error
+	for attempt := 0; attempt < maxAttempts; attempt++ {
+		hint := ""
+		if lastErr != nil {
+			hint = lastErr.Error()
+		}
+		draft, err := h.ollama.GenerateWorkflow(goal, research.String(), hint)
+		if err != nil {
+			return "", err
+		}
+		if lastErr = validate(draft); lastErr == nil {
+			return draft, nil
+		}
+	}
+	return "", fmt.Errorf("no valid workflow after %d attempts: %w", maxAttempts, lastErr)
+}
+
+// reviewDiffLimit is how much of a sanitized diff a review sends.
+const reviewDiffLimit = 8000
+
+// ReviewDiff asks the model for structured review findings on a diff, on
+// the backend the review route picks, falling back to the local model.
+// Like CommitMessage, the diff is sanitized before leaving the process; a
+// diff too long to send whole is cut and the result marked Truncated.
+func (h *HybridClient) ReviewDiff(diff string) (*ReviewResult, error) {
+	diff = PrepareForLLM(diff, 0)
+	truncated := len(diff) > reviewDiffLimit
+	diff = strings.TrimSpace(TruncateForLLM(diff, reviewDiffLimit))
+
+	var result *ReviewResult
+	var err error
+	if h.useCloud(config.FeatureReview, diff) {
+		result, err = h.perplexity.ReviewDiff(context.Background(), diff)
+	}
+	if result == nil {
+		result, err = h.ollama.ReviewDiff(diff)
+	}
+	if err != nil {
+		return nil, err
+	}
+	result.Truncated = truncated
+	return result, nil
+}
+
+// SummarizeSession drafts a narrative summary of a session digest. Digests
+// contain raw commands, so they are sanitized like diffs.
+func (h *HybridClient) SummarizeSession(digest string) (*SessionSummary, error) {
+	return h.ollama.SummarizeSession(PrepareForLLM(digest, 6000))
+}
+
 func needsWebSearch(query string) bool {
-	if os.Getenv("DEV_CLI_FORCE_LOCAL") != "" {
+	if os.Getenv("DEV_CLI_FORCE_LOCAL") != "" || os.Getenv("DEV_CLI_OFFLINE") != "" {
 		return false
 	}
 
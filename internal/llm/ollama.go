@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"dev-cli/internal/config"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
@@ -14,10 +15,11 @@ import (
 )
 
 const (
-	DefaultOllamaURL = "http://localhost:11434"
-	DefaultModel     = "qwen2.5-coder:3b-instruct"
-	FallbackModel    = "qwen2.5-coder:3b-instruct-q8_0"
-	RequestTimeout   = 30 * time.Second
+	DefaultOllamaURL  = "http://localhost:11434"
+	DefaultModel      = "qwen2.5-coder:3b-instruct"
+	FallbackModel     = "qwen2.5-coder:3b-instruct-q8_0"
+	DefaultEmbedModel = "nomic-embed-text"
+	RequestTimeout    = 30 * time.Second
 )
 
 func EnsureOllamaRunning() error {
@@ -74,12 +76,25 @@ func waitForOllama(client *http.Client, timeout time.Duration) error {
 type ExplainResult struct {
 	Explanation string `json:"explanation"`
 	Fix         string `json:"fix"`
+	// Model names the model that produced the answer.
+	Model string `json:"-"`
 }
 
 type Client struct {
-	baseURL    string
-	model      string
-	httpClient *http.Client
+	baseURL       string
+	model         string
+	fallbackModel string
+	embedModel    string
+	project       ProjectContext
+	httpClient    *http.Client
+}
+
+// WithProject returns a copy of c that adds project context to Explain and
+// Research prompts.
+func (c *Client) WithProject(p ProjectContext) *Client {
+	clone := *c
+	clone.project = p
+	return &clone
 }
 
 func NewClient(cfg *config.Config) *Client {
@@ -93,9 +108,21 @@ func NewClient(cfg *config.Config) *Client {
 		model = cfg.OllamaModel
 	}
 
+	fallback := FallbackModel
+	if cfg.OllamaFallbackModel != "" {
+		fallback = cfg.OllamaFallbackModel
+	}
+
+	embedModel := DefaultEmbedModel
+	if cfg.EmbedModel != "" {
+		embedModel = cfg.EmbedModel
+	}
+
 	return &Client{
-		baseURL: baseURL,
-		model:   model,
+		baseURL:       baseURL,
+		model:         model,
+		fallbackModel: fallback,
+		embedModel:    embedModel,
 		httpClient: &http.Client{
 			Timeout: RequestTimeout,
 		},
@@ -111,40 +138,50 @@ type generateRequest struct {
 }
 
 type generateResponse struct {
+	Model    string `json:"model"`
 	Response string `json:"response"`
 	Done     bool   `json:"done"`
+	// EvalCount and EvalDuration (nanoseconds) describe the generated
+	// tokens; they are used to measure throughput.
+	EvalCount    int   `json:"eval_count,omitempty"`
+	EvalDuration int64 `json:"eval_duration,omitempty"`
 }
 
-func (c *Client) Explain(cmd string, exitCode int, output string) (*ExplainResult, error) {
-	if len(output) > 2000 {
-		output = output[len(output)-2000:]
+// generate sends prompt to the configured model. When the model fails
+// twice, is not installed, or the prompt overflows its context window, the
+// request is retried once on the fallback model. Model in the response names
+// whichever model answered.
+func (c *Client) generate(prompt, format string) (*generateResponse, error) {
+	resp, err := c.generateWith(c.model, prompt, format)
+	if err == nil {
+		return resp, nil
 	}
 
-	prompt := fmt.Sprintf(`You are a CLI error analyzer. Analyze this failed command and respond with JSON only.
-
-RULES:
-1. "explanation" = Brief 1-sentence error cause can attend for more precision only if needed.
-2. "fix" = EXACT shell command to run (NOT advice, NOT instructions - just the command)
-   - Good fix: "npm init -y new line and more command if needed to run in sequence"
-   - Bad fix: "Make sure package.json exists"
-   - If no fix possible, refer to sources more authentic to that problem to precise documentation etc ""
-
-EXAMPLES:
-- package.json missing → {"explanation": "Missing package.json", "fix": "npm init -y"}
-- permission denied → {"explanation": "Permission denied", "fix": "sudo !!"}
-- command not found → {"explanation": "Command not installed", "fix": ""}
+	var se *statusError
+	if !errors.As(err, &se) || c.fallbackModel == "" || c.fallbackModel == c.model {
+		return nil, err
+	}
 
-Command: %s
-Exit Code: %d
-Output: %s
+	if se.Code != http.StatusNotFound && !se.contextOverflow() {
+		if resp, err = c.generateWith(c.model, prompt, format); err == nil {
+			return resp, nil
+		}
+	}
 
-JSON response:`, cmd, exitCode, output)
+	resp, fbErr := c.generateWith(c.fallbackModel, prompt, format)
+	if fbErr != nil {
+		return nil, fmt.Errorf("%w (fallback %s: %v)", err, c.fallbackModel, fbErr)
+	}
+	return resp, nil
+}
 
+// generateWith sends a single non-streaming prompt to /api/generate.
+func (c *Client) generateWith(model, prompt, format string) (*generateResponse, error) {
 	req := generateRequest{
-		Model:  c.model,
+		Model:  model,
 		Prompt: prompt,
 		Stream: false,
-		Format: "json",
+		Format: format,
 	}
 
 	if os.Getenv("DEV_CLI_OLLAMA_UNLOAD") == "true" {
@@ -164,27 +201,140 @@ JSON response:`, cmd, exitCode, output)
 
 	if resp.StatusCode != http.StatusOK {
 		body, _ := io.ReadAll(resp.Body)
-		return nil, fmt.Errorf("ollama status %d: %s", resp.StatusCode, string(body))
+		return nil, &statusError{Code: resp.StatusCode, Body: string(body)}
 	}
 
 	var genResp generateResponse
 	if err := json.NewDecoder(resp.Body).Decode(&genResp); err != nil {
 		return nil, fmt.Errorf("decode response: %w", err)
 	}
+	if genResp.Model == "" {
+		genResp.Model = model
+	}
+	return &genResp, nil
+}
+
+type embedRequest struct {
+	Model string   `json:"model"`
+	Input []string `json:"input"`
+}
+
+type embedResponse struct {
+	Embeddings [][]float32 `json:"embeddings"`
+}
+
+// Embed returns one embedding vector per text from the embedding model.
+func (c *Client) Embed(texts []string) ([][]float32, error) {
+	reqBody, err := json.Marshal(embedRequest{Model: c.embedModel, Input: texts})
+	if err != nil {
+		return nil, fmt.Errorf("marshal request: %w", err)
+	}
+
+	resp, err := c.httpClient.Post(c.baseURL+"/api/embed", "application/json", bytes.NewReader(reqBody))
+	if err != nil {
+		return nil, fmt.Errorf("call Ollama: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return nil, &statusError{Code: resp.StatusCode, Body: string(body)}
+	}
+
+	var embResp embedResponse
+	if err := json.NewDecoder(resp.Body).Decode(&embResp); err != nil {
+		return nil, fmt.Errorf("decode response: %w", err)
+	}
+	if len(embResp.Embeddings) != len(texts) {
+		return nil, fmt.Errorf("expected %d embeddings, got %d", len(texts), len(embResp.Embeddings))
+	}
+	return embResp.Embeddings, nil
+}
+
+// statusError is a non-200 reply from Ollama. Transport errors are not
+// wrapped in it: if Ollama is unreachable a fallback model will not help.
+type statusError struct {
+	Code int
+	Body string
+}
+
+func (e *statusError) Error() string {
+	return fmt.Sprintf("ollama status %d: %s", e.Code, e.Body)
+}
+
+func (e *statusError) contextOverflow() bool {
+	body := strings.ToLower(e.Body)
+	return strings.Contains(body, "context length") ||
+		strings.Contains(body, "context window") ||
+		strings.Contains(body, "too long")
+}
+
+func (c *Client) Explain(cmd string, exitCode int, output string) (*ExplainResult, error) {
+	if len(output) > 2000 {
+		output = output[len(output)-2000:]
+	}
+
+	genResp, err := c.generate(explainPrompt(c.project, cmd, exitCode, output), "json")
+	if err != nil {
+		return nil, err
+	}
 
 	var result ExplainResult
 	responseText := strings.TrimSpace(genResp.Response)
 	if err := json.Unmarshal([]byte(responseText), &result); err != nil {
-		return &ExplainResult{Explanation: responseText, Fix: ""}, nil
+		return &ExplainResult{Explanation: responseText, Fix: "", Model: genResp.Model}, nil
 	}
 
+	result.Model = genResp.Model
 	return &result, nil
 }
 
+func explainPrompt(project ProjectContext, cmd string, exitCode int, output string) string {
+	return fmt.Sprintf(`You are a CLI error analyzer. Analyze this failed command and respond with JSON only.
+%s
+RULES:
+1. "explanation" = Brief 1-sentence error cause can attend for more precision only if needed.
+2. "fix" = EXACT shell command to run (NOT advice, NOT instructions - just the command)
+   - Good fix: "npm init -y new line and more command if needed to run in sequence"
+   - Bad fix: "Make sure package.json exists"
+   - If no fix possible, refer to sources more authentic to that problem to precise documentation etc ""
+3. %s
+
+EXAMPLES:
+- package.json missing → {"explanation": "Missing package.json", "fix": "npm init -y"}
+- permission denied → {"explanation": "Permission denied", "fix": "sudo !!"}
+- command not found → {"explanation": "Command not installed", "fix": ""}
+
+Command: %s
+Exit Code: %d
+Output:
+%s
+
+JSON response:`, project.promptLine(), UntrustedNotice, cmd, exitCode, untrusted("OUTPUT", output))
+}
+
 func (c *Client) Research(query string) (*ResearchResult, error) {
-	prompt := fmt.Sprintf(`You are a Senior Developer Assistant. The user needs to: "%s".
-Provide the TOP 3 distinct ways to achieve this.
+	genResp, err := c.generate(researchPrompt(c.project, query), "json")
+	if err != nil {
+		return nil, err
+	}
+
+	responseText := strings.TrimSpace(genResp.Response)
+
+	var result ResearchResult
+	if err := json.Unmarshal([]byte(responseText), &result); err != nil {
+		return nil, fmt.Errorf("parse solutions: %w", err)
+	}
+
+	result.Query = query
+	result.Model = genResp.Model
+	return &result, nil
+}
 
+func researchPrompt(project ProjectContext, query string) string {
+	return fmt.Sprintf(`You are a Senior Developer Assistant. The user needs to: "%s".
+Provide the TOP 3 distinct ways to achieve this.
+%s
 RULES:
 1. Option 1 = "Best Practice" / Modern way
 2. Option 2 = "Quickest/Easiest" way
@@ -206,53 +356,12 @@ OUTPUT JSON ONLY:
       "source": ""
     }
   ]
-}`, query)
-
-	req := generateRequest{
-		Model:  c.model,
-		Prompt: prompt,
-		Stream: false,
-		Format: "json",
-	}
-
-	if os.Getenv("DEV_CLI_OLLAMA_UNLOAD") == "true" {
-		req.KeepAlive = "0m"
-	}
-
-	reqBody, err := json.Marshal(req)
-	if err != nil {
-		return nil, fmt.Errorf("marshal request: %w", err)
-	}
-
-	resp, err := c.httpClient.Post(c.baseURL+"/api/generate", "application/json", bytes.NewReader(reqBody))
-	if err != nil {
-		return nil, fmt.Errorf("call Ollama: %w", err)
-	}
-	defer resp.Body.Close()
-
-	if resp.StatusCode != http.StatusOK {
-		body, _ := io.ReadAll(resp.Body)
-		return nil, fmt.Errorf("ollama status %d: %s", resp.StatusCode, string(body))
-	}
-
-	var genResp generateResponse
-	if err := json.NewDecoder(resp.Body).Decode(&genResp); err != nil {
-		return nil, fmt.Errorf("decode response: %w", err)
-	}
-
-	responseText := strings.TrimSpace(genResp.Response)
-
-	var result ResearchResult
-	if err := json.Unmarshal([]byte(responseText), &result); err != nil {
-		return nil, fmt.Errorf("parse solutions: %w", err)
-	}
-
-	result.Query = query
-	return &result, nil
+}`, query, project.promptLine())
 }
 
 func (c *Client) AnalyzeLog(logLines string) (*LogAnalysisResult, error) {
 	prompt := fmt.Sprintf(`You are a Log Analyzer. Identify the error in these log lines.
+%s
 
 OUTPUT JSON ONLY:
 {
@@ -261,46 +370,20 @@ OUTPUT JSON ONLY:
 }
 
 LOGS:
-%s`, logLines)
-
-	req := generateRequest{
-		Model:  c.model,
-		Prompt: prompt,
-		Stream: false,
-		Format: "json",
-	}
-
-	if os.Getenv("DEV_CLI_OLLAMA_UNLOAD") == "true" {
-		req.KeepAlive = "0m"
-	}
-
-	reqBody, err := json.Marshal(req)
-	if err != nil {
-		return nil, fmt.Errorf("marshal request: %w", err)
-	}
+%s`, UntrustedNotice, untrusted("LOGS", logLines))
 
-	resp, err := c.httpClient.Post(c.baseURL+"/api/generate", "application/json", bytes.NewReader(reqBody))
+	genResp, err := c.generate(prompt, "json")
 	if err != nil {
-		return nil, fmt.Errorf("call Ollama: %w", err)
-	}
-	defer resp.Body.Close()
-
-	if resp.StatusCode != http.StatusOK {
-		body, _ := io.ReadAll(resp.Body)
-		return nil, fmt.Errorf("ollama status %d: %s", resp.StatusCode, string(body))
-	}
-
-	var genResp generateResponse
-	if err := json.NewDecoder(resp.Body).Decode(&genResp); err != nil {
-		return nil, fmt.Errorf("decode response: %w", err)
+		return nil, err
 	}
 
 	var result LogAnalysisResult
 	responseText := strings.TrimSpace(genResp.Response)
 	if err := json.Unmarshal([]byte(responseText), &result); err != nil {
-		return &LogAnalysisResult{Explanation: responseText}, nil
+		return &LogAnalysisResult{Explanation: responseText, Model: genResp.Model}, nil
 	}
 
+	result.Model = genResp.Model
 	return &result, nil
 }
 
@@ -317,38 +400,199 @@ RULES:
 GOAL: %s
 COMMAND:`, goal, goal)
 
-	req := generateRequest{
-		Model:  c.model,
-		Prompt: prompt,
-		Stream: false,
+	genResp, err := c.generate(prompt, "")
+	if err != nil {
+		return "", err
 	}
 
-	if os.Getenv("DEV_CLI_OLLAMA_UNLOAD") == "true" {
-		req.KeepAlive = "0m"
+	return strings.TrimSpace(genResp.Response), nil
+}
+
+func (c *Client) CommitMessage(diff string) (string, error) {
+	prompt := fmt.Sprintf(`You are a Git Commit Assistant. Write a commit message for the staged diff below.
+
+RULES:
+1. Use the Conventional Commits format: type(scope): subject
+2. type is one of: feat, fix, docs, style, refactor, perf, test, build, ci, chore
+3. Subject is imperative, lowercase, at most 72 characters, no trailing period
+4. Optionally add a blank line and a short body explaining WHY (wrap at 72)
+5. Output ONLY the commit message. No markdown, no quotes, no explanations.
+6. %s
+
+DIFF:
+%s
+
+COMMIT MESSAGE:`, UntrustedNotice, untrusted("DIFF", diff))
+
+	genResp, err := c.generate(prompt, "")
+	if err != nil {
+		return "", err
 	}
 
-	reqBody, err := json.Marshal(req)
+	return cleanCommitMessage(genResp.Response), nil
+}
+
+type ReviewFinding struct {
+	File       string `json:"file"`
+	Line       int    `json:"line"`
+	Severity   string `json:"severity"`
+	Suggestion string `json:"suggestion"`
+}
+
+type ReviewResult struct {
+	Summary  string          `json:"summary"`
+	Findings []ReviewFinding `json:"findings"`
+	// Truncated is set when the diff was too long to send whole, so only
+	// its start and end were reviewed.
+	Truncated bool `json:"-"`
+}
+
+func (c *Client) ReviewDiff(diff string) (*ReviewResult, error) {
+	genResp, err := c.generate(reviewPrompt(diff), "json")
 	if err != nil {
-		return "", fmt.Errorf("marshal request: %w", err)
+		return nil, err
 	}
+	return parseReview(stripMarkdownFences(genResp.Response))
+}
 
-	resp, err := c.httpClient.Post(c.baseURL+"/api/generate", "application/json", bytes.NewReader(reqBody))
+// reviewPrompt asks for structured review findings on diff, for either
+// backend.
+func reviewPrompt(diff string) string {
+	return fmt.Sprintf(`You are a Senior Code Reviewer. Review this git diff for bugs, security issues, and risky changes.
+
+RULES:
+1. Only report concrete problems in ADDED or CHANGED lines.
+2. "line" is the line number in the new version of the file (0 if unknown).
+3. "severity" is one of: "error", "warning", "info".
+4. "suggestion" is one actionable sentence.
+5. If the diff looks fine, return an empty findings list.
+6. %s
+
+OUTPUT JSON ONLY:
+{
+  "summary": "One sentence overall assessment",
+  "findings": [
+    {"file": "main.go", "line": 42, "severity": "warning", "suggestion": "Check the error returned by Close"}
+  ]
+}
+
+DIFF:
+%s`, UntrustedNotice, untrusted("DIFF", diff))
+}
+
+// parseReview decodes a reply to reviewPrompt, normalizing severities.
+func parseReview(reply string) (*ReviewResult, error) {
+	var result ReviewResult
+	if err := json.Unmarshal([]byte(reply), &result); err != nil {
+		return nil, fmt.Errorf("parse review: %w", err)
+	}
+
+	for i := range result.Findings {
+		switch strings.ToLower(result.Findings[i].Severity) {
+		case "error", "critical", "high":
+			result.Findings[i].Severity = "error"
+		case "info", "low", "nit":
+			result.Findings[i].Severity = "info"
+		default:
+			result.Findings[i].Severity = "warning"
+		}
+	}
+
+	return &result, nil
+}
+
+// GenerateWorkflow drafts a dev-cli workflow YAML for goal. research is
+// optional background (e.g. steps from Research); lastErr, when set, is the
+// validation error from a previous attempt so the model can correct itself.
+func (c *Client) GenerateWorkflow(goal, research, lastErr string) (string, error) {
+	var extra strings.Builder
+	if research != "" {
+		extra.WriteString("\nREFERENCE STEPS (may be useful):\n" + untrusted("REFERENCE", research) + "\n" + UntrustedNotice + "\n")
+	}
+	if lastErr != "" {
+		extra.WriteString("\nYOUR PREVIOUS ATTEMPT WAS INVALID: " + lastErr + "\nFix it.\n")
+	}
+
+	prompt := fmt.Sprintf(`You are a DevOps Workflow Author. Write a dev-cli workflow YAML that achieves: "%s".
+
+SCHEMA:
+name: short-kebab-name            # required
+description: one sentence
+on_failure:
+  action: rollback                # abort | rollback | continue
+steps:                            # at least one
+  - id: unique_snake_id           # required, unique
+    name: Human readable name
+    command: single shell command # required
+    timeout: 2m                   # Go duration
+    retries: 0
+    rollback: shell command that undoes this step (use "true" if nothing to undo)
+
+RULES:
+1. Output ONLY the YAML. No markdown fences, no explanations.
+2. Every step that changes state MUST have a rollback command.
+3. Prefer idempotent, non-interactive commands (add -y / --yes where needed).
+4. Never include destructive commands (rm -rf /, mkfs, dd) unless the goal demands it.
+%s`, goal, extra.String())
+
+	genResp, err := c.generate(prompt, "")
 	if err != nil {
-		return "", fmt.Errorf("call Ollama: %w", err)
+		return "", err
 	}
-	defer resp.Body.Close()
 
-	if resp.StatusCode != http.StatusOK {
-		body, _ := io.ReadAll(resp.Body)
-		return "", fmt.Errorf("ollama status %d: %s", resp.StatusCode, string(body))
+	out := strings.TrimSpace(genResp.Response)
+	out = strings.TrimPrefix(out, "```yaml")
+	out = strings.TrimPrefix(out, "```yml")
+	return stripMarkdownFences(out) + "\n", nil
+}
+
+type SessionSummary struct {
+	Narrative string   `json:"narrative"`
+	FollowUps []string `json:"follow_ups"`
+}
+
+// SummarizeSession turns a digest of recent shell activity into a short
+// standup-style narrative plus follow-up tasks.
+func (c *Client) SummarizeSession(digest string) (*SessionSummary, error) {
+	prompt := fmt.Sprintf(`You are a DevOps Standup Assistant. Summarize this terminal session for a teammate.
+
+RULES:
+1. "narrative" is 2-4 sentences: what was worked on, what failed, what got fixed.
+2. "follow_ups" lists concrete next tasks (max 5), e.g. unresolved failures.
+3. Do not invent work that is not in the session.
+4. %s
+
+OUTPUT JSON ONLY:
+{
+  "narrative": "Worked on the shop-api build; go test kept failing on the payments package until the mock was regenerated.",
+  "follow_ups": ["Investigate flaky TestCheckout timeout"]
+}
+
+SESSION:
+%s`, UntrustedNotice, untrusted("SESSION", digest))
+
+	genResp, err := c.generate(prompt, "json")
+	if err != nil {
+		return nil, err
 	}
 
-	var genResp generateResponse
-	if err := json.NewDecoder(resp.Body).Decode(&genResp); err != nil {
-		return "", fmt.Errorf("decode response: %w", err)
+	var summary SessionSummary
+	if err := json.Unmarshal([]byte(stripMarkdownFences(genResp.Response)), &summary); err != nil {
+		return nil, fmt.Errorf("parse summary: %w", err)
 	}
+	return &summary, nil
+}
 
-	return strings.TrimSpace(genResp.Response), nil
+// cleanCommitMessage strips the fences and quoting small models tend to wrap
+// around a commit message.
+func cleanCommitMessage(s string) string {
+	s = stripMarkdownFences(s)
+	s = strings.Trim(s, "\"'`")
+	lines := strings.Split(s, "\n")
+	for i, line := range lines {
+		lines[i] = strings.TrimRight(line, " \t")
+	}
+	return strings.TrimSpace(strings.Join(lines, "\n"))
 }
 
 // ToolCallResult represents the result of a tool-aware LLM generation.
@@ -387,36 +631,9 @@ USER REQUEST: %s
 
 JSON RESPONSE:`, systemPrompt, prompt)
 
-	req := generateRequest{
-		Model:  c.model,
-		Prompt: fullPrompt,
-		Stream: false,
-		Format: "json",
-	}
-
-	if os.Getenv("DEV_CLI_OLLAMA_UNLOAD") == "true" {
-		req.KeepAlive = "0m"
-	}
-
-	reqBody, err := json.Marshal(req)
-	if err != nil {
-		return nil, fmt.Errorf("marshal request: %w", err)
-	}
-
-	resp, err := c.httpClient.Post(c.baseURL+"/api/generate", "application/json", bytes.NewReader(reqBody))
+	genResp, err := c.generate(fullPrompt, "json")
 	if err != nil {
-		return nil, fmt.Errorf("call Ollama: %w", err)
-	}
-	defer resp.Body.Close()
-
-	if resp.StatusCode != http.StatusOK {
-		body, _ := io.ReadAll(resp.Body)
-		return nil, fmt.Errorf("ollama status %d: %s", resp.StatusCode, string(body))
-	}
-
-	var genResp generateResponse
-	if err := json.NewDecoder(resp.Body).Decode(&genResp); err != nil {
-		return nil, fmt.Errorf("decode response: %w", err)
+		return nil, err
 	}
 
 	var result ToolCallResult
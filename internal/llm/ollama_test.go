@@ -4,6 +4,7 @@ import (
 	"encoding/json"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
 )
 
@@ -76,3 +77,191 @@ func TestExplain_WithFix(t *testing.T) {
 		t.Errorf("expected fix 'git add .', got '%s'", result.Fix)
 	}
 }
+
+func TestCommitMessage(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		resp := generateResponse{
+			Response: "```\nfeat(cli): add commit-msg command  \n\nDrafts messages from the staged diff.\n```",
+			Done:     true,
+		}
+		json.NewEncoder(w).Encode(resp)
+	}))
+	defer server.Close()
+
+	client := &Client{
+		baseURL:    server.URL,
+		model:      "test-model",
+		httpClient: http.DefaultClient,
+	}
+
+	msg, err := client.CommitMessage("diff --git a/main.go b/main.go")
+	if err != nil {
+		t.Fatalf("CommitMessage failed: %v", err)
+	}
+
+	want := "feat(cli): add commit-msg command\n\nDrafts messages from the staged diff."
+	if msg != want {
+		t.Errorf("expected %q, got %q", want, msg)
+	}
+}
+
+func TestReviewDiff(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req generateRequest
+		json.NewDecoder(r.Body).Decode(&req)
+		if req.Format != "json" {
+			t.Errorf("expected json format, got %q", req.Format)
+		}
+		resp := generateResponse{
+			Response: `{"summary": "Mostly fine", "findings": [{"file": "db.go", "line": 12, "severity": "critical", "suggestion": "Close rows"}, {"file": "db.go", "line": 30, "severity": "nit", "suggestion": "Rename var"}]}`,
+			Done:     true,
+		}
+		json.NewEncoder(w).Encode(resp)
+	}))
+	defer server.Close()
+
+	client := &Client{
+		baseURL:    server.URL,
+		model:      "test-model",
+		httpClient: http.DefaultClient,
+	}
+
+	result, err := client.ReviewDiff("diff --git a/db.go b/db.go")
+	if err != nil {
+		t.Fatalf("ReviewDiff failed: %v", err)
+	}
+	if len(result.Findings) != 2 {
+		t.Fatalf("expected 2 findings, got %d", len(result.Findings))
+	}
+	if result.Findings[0].Severity != "error" || result.Findings[1].Severity != "info" {
+		t.Errorf("expected severities to be normalized, got %q and %q", result.Findings[0].Severity, result.Findings[1].Severity)
+	}
+}
+
+func TestSummarizeSession(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		resp := generateResponse{
+			Response: "```json\n{\"narrative\": \"Fixed the build.\", \"follow_ups\": [\"Rerun CI\"]}\n```",
+			Done:     true,
+		}
+		json.NewEncoder(w).Encode(resp)
+	}))
+	defer server.Close()
+
+	client := &Client{
+		baseURL:    server.URL,
+		model:      "test-model",
+		httpClient: http.DefaultClient,
+	}
+
+	summary, err := client.SummarizeSession("12:00 go build (exit 1)")
+	if err != nil {
+		t.Fatalf("SummarizeSession failed: %v", err)
+	}
+	if summary.Narrative != "Fixed the build." {
+		t.Errorf("unexpected narrative %q", summary.Narrative)
+	}
+	if len(summary.FollowUps) != 1 || summary.FollowUps[0] != "Rerun CI" {
+		t.Errorf("unexpected follow-ups %v", summary.FollowUps)
+	}
+}
+
+func TestGenerate_FallsBackToFallbackModel(t *testing.T) {
+	tests := []struct {
+		name         string
+		failure      int
+		body         string
+		primaryCalls int
+	}{
+		{"repeated server errors", http.StatusInternalServerError, "llama runner crashed", 2},
+		{"context overflow", http.StatusBadRequest, "input length exceeds maximum context length", 1},
+		{"model not installed", http.StatusNotFound, `model "big" not found, try pulling it first`, 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			calls := map[string]int{}
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				var req generateRequest
+				json.NewDecoder(r.Body).Decode(&req)
+				calls[req.Model]++
+				if req.Model == "big" {
+					http.Error(w, tt.body, tt.failure)
+					return
+				}
+				json.NewEncoder(w).Encode(generateResponse{Model: req.Model, Response: `{"explanation": "ok", "fix": ""}`, Done: true})
+			}))
+			defer server.Close()
+
+			client := &Client{baseURL: server.URL, model: "big", fallbackModel: "small", httpClient: http.DefaultClient}
+			result, err := client.Explain("make", 2, "boom")
+			if err != nil {
+				t.Fatalf("Explain failed: %v", err)
+			}
+			if result.Model != "small" {
+				t.Errorf("expected answer from fallback model, got %q", result.Model)
+			}
+			if calls["big"] != tt.primaryCalls || calls["small"] != 1 {
+				t.Errorf("unexpected calls: %v", calls)
+			}
+		})
+	}
+}
+
+func TestGenerate_NoFallbackWhenUnreachable(t *testing.T) {
+	client := &Client{baseURL: "http://127.0.0.1:1", model: "big", fallbackModel: "small", httpClient: http.DefaultClient}
+	if _, err := client.Solve("list files"); err == nil {
+		t.Fatal("expected an error when Ollama is unreachable")
+	}
+}
+
+func TestExplain_IncludesProjectContext(t *testing.T) {
+	var prompt string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req generateRequest
+		json.NewDecoder(r.Body).Decode(&req)
+		prompt = req.Prompt
+		json.NewEncoder(w).Encode(generateResponse{Response: `{"explanation": "undefined symbol", "fix": "go build ./..."}`, Done: true})
+	}))
+	defer server.Close()
+
+	base := &Client{baseURL: server.URL, model: "test-model", httpClient: http.DefaultClient}
+	client := base.WithProject(ProjectContext{Type: "go", PackageManager: "go mod", Files: []string{"go.mod", "Makefile"}})
+	if _, err := client.Explain("make build", 2, "undefined: handler"); err != nil {
+		t.Fatalf("Explain failed: %v", err)
+	}
+	if !strings.Contains(prompt, "PROJECT CONTEXT: go project, package manager go mod (files: go.mod, Makefile)") {
+		t.Errorf("prompt is missing project context:\n%s", prompt)
+	}
+
+	if _, err := base.Explain("make build", 2, "undefined: handler"); err != nil {
+		t.Fatalf("Explain failed: %v", err)
+	}
+	if strings.Contains(prompt, "PROJECT CONTEXT") {
+		t.Error("WithProject must not change the original client")
+	}
+}
+
+func TestEmbed(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/embed" {
+			t.Errorf("unexpected path: %s", r.URL.Path)
+		}
+		var req embedRequest
+		json.NewDecoder(r.Body).Decode(&req)
+		if req.Model != "embedder" || len(req.Input) != 2 {
+			t.Errorf("unexpected request: %+v", req)
+		}
+		json.NewEncoder(w).Encode(embedResponse{Embeddings: [][]float32{{1, 0}, {0, 1}}})
+	}))
+	defer server.Close()
+
+	client := &Client{baseURL: server.URL, embedModel: "embedder", httpClient: http.DefaultClient}
+	vectors, err := client.Embed([]string{"a", "b"})
+	if err != nil {
+		t.Fatalf("Embed failed: %v", err)
+	}
+	if len(vectors) != 2 || vectors[1][1] != 1 {
+		t.Errorf("unexpected vectors: %v", vectors)
+	}
+}
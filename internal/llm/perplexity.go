@@ -1,12 +1,15 @@
 package llm
 
 import (
+	"bufio"
 	"bytes"
 	"context"
 	"encoding/json"
 	"fmt"
 	"io"
 	"net/http"
+	"regexp"
+	"strconv"
 	"strings"
 	"time"
 
@@ -20,11 +23,21 @@ const (
 type PerplexityClient struct {
 	apiKey     string
 	model      string
+	baseURL    string // overrides PerplexityAPIURL in tests
+	project    ProjectContext
 	httpClient *http.Client
 }
 
+// WithProject returns a copy of c that adds project context to Explain and
+// Research prompts.
+func (c *PerplexityClient) WithProject(p ProjectContext) *PerplexityClient {
+	clone := *c
+	clone.project = p
+	return &clone
+}
+
 func NewPerplexityClient(cfg *config.Config) *PerplexityClient {
-	if cfg.PerplexityKey == "" {
+	if cfg.PerplexityKey == "" || cfg.Offline {
 		return nil
 	}
 
@@ -45,60 +58,62 @@ type perplexityMessage struct {
 type perplexityRequest struct {
 	Model    string              `json:"model"`
 	Messages []perplexityMessage `json:"messages"`
+	Stream   bool                `json:"stream,omitempty"`
 }
 
 type perplexityChoice struct {
 	Message struct {
 		Content string `json:"content"`
 	} `json:"message"`
+	// Delta carries the new text in streamed chunks.
+	Delta struct {
+		Content string `json:"content"`
+	} `json:"delta"`
 }
 
 type perplexityResponse struct {
 	Choices []perplexityChoice `json:"choices"`
+	// Citations lists the URLs the answer drew on; the text refers to them
+	// as [1], [2], ...
+	Citations []string `json:"citations"`
 }
 
-func (c *PerplexityClient) Research(ctx context.Context, query string) (*ResearchResult, error) {
-	prompt := fmt.Sprintf(`You are a Senior Developer Assistant. The user needs to: "%s".
-Provide the TOP 3 distinct ways to achieve this.
+// perplexityReply is a finished completion with its sources.
+type perplexityReply struct {
+	Content   string
+	Citations []string
+}
 
-RULES:
-1. Option 1 = "Best Practice" / Modern way
-2. Option 2 = "Quickest/Easiest" way
-3. Option 3 = "Alternative" (edge case or manual approach)
-4. Each solution can have multiple steps
-5. Step type is "command" for shell commands, "file" for code snippets to add to files
-6. For "file" type, include the target filename in "file" field
-7. Include source URLs when available
+const jsonSystemPrompt = "You are a helpful developer assistant. Always respond with valid JSON only, no markdown formatting."
 
-OUTPUT JSON ONLY (No markdown, no code fences):
-{
-  "solutions": [
-    {
-      "id": 1,
-      "title": "Using npm (Recommended)",
-      "description": "Modern package manager with better caching",
-      "steps": [
-        {"type": "command", "content": "npm install tailwindcss", "note": "Install package"},
-        {"type": "command", "content": "npx tailwindcss init", "note": "Initialize config"},
-        {"type": "file", "file": "tailwind.config.js", "content": "module.exports = { content: ['./src/**/*.{js,jsx}'] }", "note": "Configure paths"}
-      ],
-      "source": "https://tailwindcss.com/docs"
-    }
-  ]
-}`, query)
+// complete sends a single system+user exchange and returns the reply with
+// any markdown fences removed. When onDelta is set the response is streamed
+// and onDelta is called with each chunk of text as it arrives.
+func (c *PerplexityClient) complete(ctx context.Context, system, prompt string, onDelta func(string)) (*perplexityReply, error) {
+	reply, err := c.send(ctx, c.model, []perplexityMessage{
+		{Role: "system", Content: system},
+		{Role: "user", Content: prompt},
+	}, onDelta)
+	if err != nil {
+		return nil, err
+	}
+	reply.Content = stripMarkdownFences(reply.Content)
+	return reply, nil
+}
 
+// send asks model to continue the conversation in messages, streaming the
+// reply to onDelta when set.
+func (c *PerplexityClient) send(ctx context.Context, model string, messages []perplexityMessage, onDelta func(string)) (*perplexityReply, error) {
 	reqBody, err := json.Marshal(perplexityRequest{
-		Model: c.model,
-		Messages: []perplexityMessage{
-			{Role: "system", Content: "You are a helpful developer assistant. Always respond with valid JSON only, no markdown formatting."},
-			{Role: "user", Content: prompt},
-		},
+		Model:    model,
+		Messages: messages,
+		Stream:   onDelta != nil,
 	})
 	if err != nil {
 		return nil, fmt.Errorf("marshal request: %w", err)
 	}
 
-	req, err := http.NewRequestWithContext(ctx, "POST", PerplexityAPIURL, bytes.NewReader(reqBody))
+	req, err := http.NewRequestWithContext(ctx, "POST", c.apiURL(), bytes.NewReader(reqBody))
 	if err != nil {
 		return nil, fmt.Errorf("create request: %w", err)
 	}
@@ -117,6 +132,10 @@ OUTPUT JSON ONLY (No markdown, no code fences):
 		return nil, fmt.Errorf("perplexity status %d: %s", resp.StatusCode, string(body))
 	}
 
+	if onDelta != nil {
+		return readPerplexityStream(resp.Body, onDelta)
+	}
+
 	var pResp perplexityResponse
 	if err := json.NewDecoder(resp.Body).Decode(&pResp); err != nil {
 		return nil, fmt.Errorf("decode response: %w", err)
@@ -126,15 +145,113 @@ OUTPUT JSON ONLY (No markdown, no code fences):
 		return nil, fmt.Errorf("no response from Perplexity")
 	}
 
-	content := strings.TrimSpace(pResp.Choices[0].Message.Content)
-	content = stripMarkdownFences(content)
+	return &perplexityReply{
+		Content:   pResp.Choices[0].Message.Content,
+		Citations: pResp.Citations,
+	}, nil
+}
+
+// readPerplexityStream consumes a server-sent event stream of completion
+// chunks. Citations may arrive on any chunk; the last non-empty list wins.
+func readPerplexityStream(r io.Reader, onDelta func(string)) (*perplexityReply, error) {
+	var content strings.Builder
+	var citations []string
+
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		data, ok := strings.CutPrefix(line, "data:")
+		if !ok {
+			continue
+		}
+		data = strings.TrimSpace(data)
+		if data == "[DONE]" {
+			break
+		}
+
+		var chunk perplexityResponse
+		if err := json.Unmarshal([]byte(data), &chunk); err != nil {
+			return nil, fmt.Errorf("decode stream chunk: %w", err)
+		}
+		if len(chunk.Citations) > 0 {
+			citations = chunk.Citations
+		}
+		if len(chunk.Choices) == 0 {
+			continue
+		}
+		if delta := chunk.Choices[0].Delta.Content; delta != "" {
+			content.WriteString(delta)
+			onDelta(delta)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("read stream: %w", err)
+	}
+	if content.Len() == 0 {
+		return nil, fmt.Errorf("no response from Perplexity")
+	}
+
+	return &perplexityReply{Content: content.String(), Citations: citations}, nil
+}
+
+func (c *PerplexityClient) apiURL() string {
+	if c.baseURL != "" {
+		return c.baseURL
+	}
+	return PerplexityAPIURL
+}
+
+func (c *PerplexityClient) Research(ctx context.Context, query string) (*ResearchResult, error) {
+	return c.ResearchStream(ctx, query, nil)
+}
+
+// ResearchStream is Research with the response streamed: onDelta receives
+// each chunk of raw model output as it arrives. A nil onDelta disables
+// streaming. Citations are resolved into Solution.Source.
+func (c *PerplexityClient) ResearchStream(ctx context.Context, query string, onDelta func(string)) (*ResearchResult, error) {
+	prompt := fmt.Sprintf(`You are a Senior Developer Assistant. The user needs to: "%s".
+Provide the TOP 3 distinct ways to achieve this.
+%s
+RULES:
+1. Option 1 = "Best Practice" / Modern way
+2. Option 2 = "Quickest/Easiest" way
+3. Option 3 = "Alternative" (edge case or manual approach)
+4. Each solution can have multiple steps
+5. Step type is "command" for shell commands, "file" for code snippets to add to files
+6. For "file" type, include the target filename in "file" field
+7. Include source URLs when available, or cite them as [n] in "source"
+
+OUTPUT JSON ONLY (No markdown, no code fences):
+{
+  "solutions": [
+    {
+      "id": 1,
+      "title": "Using npm (Recommended)",
+      "description": "Modern package manager with better caching",
+      "steps": [
+        {"type": "command", "content": "npm install tailwindcss", "note": "Install package"},
+        {"type": "command", "content": "npx tailwindcss init", "note": "Initialize config"},
+        {"type": "file", "file": "tailwind.config.js", "content": "module.exports = { content: ['./src/**/*.{js,jsx}'] }", "note": "Configure paths"}
+      ],
+      "source": "https://tailwindcss.com/docs"
+    }
+  ]
+}`, query, c.project.promptLine())
+
+	reply, err := c.complete(ctx, jsonSystemPrompt, prompt, onDelta)
+	if err != nil {
+		return nil, err
+	}
 
 	var result ResearchResult
-	if err := json.Unmarshal([]byte(content), &result); err != nil {
+	if err := json.Unmarshal([]byte(reply.Content), &result); err != nil {
 		return nil, fmt.Errorf("parse solutions: %w", err)
 	}
 
 	result.Query = query
+	result.Model = c.model
+	attachCitations(&result, reply.Citations)
 	return &result, nil
 }
 
@@ -151,6 +268,7 @@ func stripMarkdownFences(s string) string {
 
 func (c *PerplexityClient) AnalyzeLog(ctx context.Context, logLines string) (*LogAnalysisResult, error) {
 	prompt := fmt.Sprintf(`You are a Log Analyzer. Identify the error in these log lines.
+%s
 
 OUTPUT JSON ONLY (No markdown):
 {
@@ -159,54 +277,116 @@ OUTPUT JSON ONLY (No markdown):
 }
 
 LOGS:
-%s`, logLines)
+%s`, UntrustedNotice, untrusted("LOGS", logLines))
 
-	reqBody, err := json.Marshal(perplexityRequest{
-		Model: c.model,
-		Messages: []perplexityMessage{
-			{Role: "system", Content: "You are a helpful developer assistant. Always respond with valid JSON only, no markdown formatting."},
-			{Role: "user", Content: prompt},
-		},
-	})
+	reply, err := c.complete(ctx, jsonSystemPrompt, prompt, nil)
 	if err != nil {
-		return nil, fmt.Errorf("marshal request: %w", err)
+		return nil, err
 	}
 
-	req, err := http.NewRequestWithContext(ctx, "POST", PerplexityAPIURL, bytes.NewReader(reqBody))
-	if err != nil {
-		return nil, fmt.Errorf("create request: %w", err)
+	var result LogAnalysisResult
+	if err := json.Unmarshal([]byte(reply.Content), &result); err != nil {
+		return &LogAnalysisResult{Explanation: reply.Content, Model: c.model}, nil
 	}
+	result.Model = c.model
 
-	req.Header.Set("Authorization", "Bearer "+c.apiKey)
-	req.Header.Set("Content-Type", "application/json")
+	return &result, nil
+}
 
-	resp, err := c.httpClient.Do(req)
+// Explain is the cloud counterpart of Client.Explain.
+func (c *PerplexityClient) Explain(ctx context.Context, cmd string, exitCode int, output string) (*ExplainResult, error) {
+	prompt := fmt.Sprintf(`You are a CLI error analyzer. Explain why this command failed.
+%s%s
+
+OUTPUT JSON ONLY (No markdown):
+{
+  "explanation": "Brief 1-sentence cause of the error",
+  "fix": "EXACT shell command that fixes it (or empty if none)"
+}
+
+Command: %s
+Exit Code: %d
+Output:
+%s`, c.project.promptLine(), UntrustedNotice, cmd, exitCode, untrusted("OUTPUT", output))
+
+	reply, err := c.complete(ctx, jsonSystemPrompt, prompt, nil)
 	if err != nil {
-		return nil, fmt.Errorf("call Perplexity: %w", err)
+		return nil, err
 	}
-	defer resp.Body.Close()
 
-	if resp.StatusCode != http.StatusOK {
-		body, _ := io.ReadAll(resp.Body)
-		return nil, fmt.Errorf("perplexity status %d: %s", resp.StatusCode, string(body))
+	var result ExplainResult
+	if err := json.Unmarshal([]byte(reply.Content), &result); err != nil {
+		return &ExplainResult{Explanation: reply.Content, Model: c.model}, nil
 	}
+	result.Model = c.model
+	return &result, nil
+}
 
-	var pResp perplexityResponse
-	if err := json.NewDecoder(resp.Body).Decode(&pResp); err != nil {
-		return nil, fmt.Errorf("decode response: %w", err)
+// ReviewDiff is the cloud counterpart of Client.ReviewDiff.
+func (c *PerplexityClient) ReviewDiff(ctx context.Context, diff string) (*ReviewResult, error) {
+	reply, err := c.complete(ctx, jsonSystemPrompt, reviewPrompt(diff), nil)
+	if err != nil {
+		return nil, err
 	}
+	return parseReview(reply.Content)
+}
 
-	if len(pResp.Choices) == 0 {
-		return nil, fmt.Errorf("no response from Perplexity")
+// Solve is the cloud counterpart of Client.Solve.
+func (c *PerplexityClient) Solve(ctx context.Context, goal string) (string, error) {
+	prompt := fmt.Sprintf(`The user wants to: "%s".
+Provide a SINGLE shell command to achieve this.
+
+RULES:
+1. Output ONLY the command. No markdown, no explanations.
+2. If multiple steps are needed, chain them with && or ;
+3. Assume a standard Linux environment.
+4. BE SAFE. Do not return commands that delete data without confirmation unless explicitly asked.`, goal)
+
+	reply, err := c.complete(ctx, "You are an autonomous CLI agent. Reply with a shell command only.", prompt, nil)
+	if err != nil {
+		return "", err
 	}
+	return strings.TrimSpace(reply.Content), nil
+}
 
-	content := strings.TrimSpace(pResp.Choices[0].Message.Content)
-	content = stripMarkdownFences(content)
+var citationRef = regexp.MustCompile(`\[(\d+)\]`)
 
-	var result LogAnalysisResult
-	if err := json.Unmarshal([]byte(content), &result); err != nil {
-		return &LogAnalysisResult{Explanation: content}, nil
+// attachCitations resolves [n] references in each solution against the
+// citations list. A Source that is already a URL is kept; otherwise the
+// first reference found in the source, description or step notes wins.
+func attachCitations(result *ResearchResult, citations []string) {
+	if len(citations) == 0 {
+		return
+	}
+	result.Citations = citations
+
+	resolve := func(text string) string {
+		for _, m := range citationRef.FindAllStringSubmatch(text, -1) {
+			if n, err := strconv.Atoi(m[1]); err == nil && n >= 1 && n <= len(citations) {
+				return citations[n-1]
+			}
+		}
+		return ""
 	}
 
-	return &result, nil
+	for i := range result.Solutions {
+		sol := &result.Solutions[i]
+		if strings.HasPrefix(sol.Source, "http://") || strings.HasPrefix(sol.Source, "https://") {
+			continue
+		}
+		texts := []string{sol.Source, sol.Description, sol.Title}
+		for _, step := range sol.Steps {
+			texts = append(texts, step.Note)
+		}
+		for _, text := range texts {
+			if url := resolve(text); url != "" {
+				sol.Source = url
+				break
+			}
+		}
+		// A bare number is a citation index without brackets.
+		if n, err := strconv.Atoi(strings.TrimSpace(sol.Source)); err == nil && n >= 1 && n <= len(citations) {
+			sol.Source = citations[n-1]
+		}
+	}
 }
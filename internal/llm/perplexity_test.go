@@ -1,7 +1,11 @@
 package llm
 
 import (
-	"os"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
 	"testing"
 
 	"dev-cli/internal/config"
@@ -9,10 +13,8 @@ import (
 
 func TestPerplexityConfig(t *testing.T) {
 
-	os.Setenv("DEV_CLI_PERPLEXITY_KEY", "test-key")
-	os.Setenv("DEV_CLI_PERPLEXITY_MODEL", "sonar-pro")
-	defer os.Unsetenv("DEV_CLI_PERPLEXITY_KEY")
-	defer os.Unsetenv("DEV_CLI_PERPLEXITY_MODEL")
+	t.Setenv("DEV_CLI_PERPLEXITY_KEY", "test-key")
+	t.Setenv("DEV_CLI_PERPLEXITY_MODEL", "sonar-pro")
 
 	cfg := config.Load()
 
@@ -37,11 +39,10 @@ func TestPerplexityConfig(t *testing.T) {
 }
 
 func TestPerplexityDefaultConfig(t *testing.T) {
-	os.Unsetenv("DEV_CLI_PERPLEXITY_KEY")
-	os.Unsetenv("DEV_CLI_PERPLEXITY_MODEL")
+	t.Setenv("DEV_CLI_PERPLEXITY_KEY", "")
+	t.Setenv("DEV_CLI_PERPLEXITY_MODEL", "")
 
-	os.Setenv("PERPLEXITY_API_KEY", "legacy-key")
-	defer os.Unsetenv("PERPLEXITY_API_KEY")
+	t.Setenv("PERPLEXITY_API_KEY", "legacy-key")
 
 	cfg := config.Load()
 
@@ -54,3 +55,186 @@ func TestPerplexityDefaultConfig(t *testing.T) {
 		t.Errorf("expected client.model to be 'sonar-pro', got '%s'", client.model)
 	}
 }
+
+func TestPerplexityOfflineMode(t *testing.T) {
+	t.Setenv("DEV_CLI_PERPLEXITY_KEY", "test-key")
+	t.Setenv("DEV_CLI_OFFLINE", "1")
+
+	cfg := config.Load()
+	if !cfg.Offline || cfg.IsWebSearchEnabled() {
+		t.Error("expected offline mode to disable web search")
+	}
+
+	if client := NewPerplexityClient(cfg); client != nil {
+		t.Error("expected no Perplexity client in offline mode")
+	}
+
+	if needsWebSearch("how to install docker") {
+		t.Error("offline mode should never route to web search")
+	}
+
+	h := &HybridClient{offline: true}
+	if _, err := h.AnalyzeLog("error", "cloud"); err == nil {
+		t.Error("expected cloud analysis to fail in offline mode")
+	}
+}
+
+func TestRouteConfig(t *testing.T) {
+	t.Setenv("DEV_CLI_ROUTE_EXPLAIN", "Cloud")
+	t.Setenv("DEV_CLI_ROUTE_SOLVE", "bogus")
+
+	cfg := config.Load()
+	if got := cfg.Route(config.FeatureExplain); got != config.RouteCloud {
+		t.Errorf("explain route = %q, want cloud", got)
+	}
+	if got := cfg.Route(config.FeatureSolve); got != config.RouteLocal {
+		t.Errorf("invalid override should keep default, got %q", got)
+	}
+	if got := cfg.Route(config.FeatureResearch); got != config.RouteAuto {
+		t.Errorf("research route = %q, want auto", got)
+	}
+
+	cfg.ForceLocalLLM = true
+	if got := cfg.Route(config.FeatureExplain); got != config.RouteLocal {
+		t.Errorf("force-local should pin explain to local, got %q", got)
+	}
+}
+
+func TestHybridExplain_FollowsRoute(t *testing.T) {
+	cloudCalls, localCalls := 0, 0
+	cloud := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		cloudCalls++
+		var resp perplexityResponse
+		resp.Choices = make([]perplexityChoice, 1)
+		resp.Choices[0].Message.Content = `{"explanation": "from cloud", "fix": "npm init -y"}`
+		json.NewEncoder(w).Encode(resp)
+	}))
+	defer cloud.Close()
+	local := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		localCalls++
+		json.NewEncoder(w).Encode(generateResponse{Response: `{"explanation": "from local", "fix": ""}`, Done: true})
+	}))
+	defer local.Close()
+
+	cfg := config.Load()
+	cfg.ForceLocalLLM, cfg.Offline = false, false
+	h := &HybridClient{
+		perplexity: &PerplexityClient{apiKey: "k", model: "sonar-pro", baseURL: cloud.URL, httpClient: http.DefaultClient},
+		ollama:     &Client{baseURL: local.URL, model: "test-model", httpClient: http.DefaultClient},
+		cache:      NewResponseCache(1, 0),
+		cfg:        cfg,
+	}
+
+	result, err := h.Explain("npm start", 1, "missing package.json")
+	if err != nil || result.Explanation != "from local" {
+		t.Fatalf("default explain route should be local, got %+v, %v", result, err)
+	}
+
+	cfg.AIRoutes[config.FeatureExplain] = config.RouteCloud
+	result, err = h.Explain("npm start", 1, "missing package.json")
+	if err != nil || result.Explanation != "from cloud" {
+		t.Fatalf("cloud explain route should use Perplexity, got %+v, %v", result, err)
+	}
+	if cloudCalls != 1 || localCalls != 1 {
+		t.Errorf("expected one call per backend, got cloud=%d local=%d", cloudCalls, localCalls)
+	}
+}
+
+func TestHybridReviewDiff_FollowsRoute(t *testing.T) {
+	var cloudPrompt string
+	cloud := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req perplexityRequest
+		json.NewDecoder(r.Body).Decode(&req)
+		cloudPrompt = req.Messages[len(req.Messages)-1].Content
+		var resp perplexityResponse
+		resp.Choices = make([]perplexityChoice, 1)
+		resp.Choices[0].Message.Content = `{"summary": "from cloud", "findings": [{"file": "a.go", "severity": "high", "suggestion": "x"}]}`
+		json.NewEncoder(w).Encode(resp)
+	}))
+	defer cloud.Close()
+	local := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(generateResponse{Response: `{"summary": "from local", "findings": []}`, Done: true})
+	}))
+	defer local.Close()
+
+	cfg := config.Load()
+	cfg.ForceLocalLLM, cfg.Offline = false, false
+	h := &HybridClient{
+		perplexity: &PerplexityClient{apiKey: "k", model: "sonar-pro", baseURL: cloud.URL, httpClient: http.DefaultClient},
+		ollama:     &Client{baseURL: local.URL, model: "test-model", httpClient: http.DefaultClient},
+		cache:      NewResponseCache(1, 0),
+		cfg:        cfg,
+	}
+
+	result, err := h.ReviewDiff("diff --git a/a.go b/a.go\n+x")
+	if err != nil || result.Summary != "from local" || result.Truncated {
+		t.Fatalf("default review route should be local, got %+v, %v", result, err)
+	}
+
+	cfg.AIRoutes[config.FeatureReview] = config.RouteCloud
+	long := "diff --git a/a.go b/a.go\n" + strings.Repeat("+line\n", 3000)
+	result, err = h.ReviewDiff(long)
+	if err != nil || result.Summary != "from cloud" || result.Findings[0].Severity != "error" {
+		t.Fatalf("cloud review route should use Perplexity, got %+v, %v", result, err)
+	}
+	if !result.Truncated || !strings.Contains(cloudPrompt, "[truncated]") {
+		t.Errorf("expected a long diff cut and reported, got Truncated=%v", result.Truncated)
+	}
+}
+
+func TestResearchStream_ParsesCitations(t *testing.T) {
+	answer := `{"solutions": [{"id": 1, "title": "Use the installer [2]", "description": "Official script", "steps": [], "source": "[1]"}, {"id": 2, "title": "Manual", "description": "See docs [2]", "steps": []}]}`
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req perplexityRequest
+		json.NewDecoder(r.Body).Decode(&req)
+		if !req.Stream {
+			t.Error("expected a streaming request")
+		}
+		w.Header().Set("Content-Type", "text/event-stream")
+		for i := 0; i < len(answer); i += 40 {
+			end := min(i+40, len(answer))
+			chunk, _ := json.Marshal(map[string]any{
+				"choices":   []map[string]any{{"delta": map[string]string{"content": answer[i:end]}}},
+				"citations": []string{"https://example.com/install", "https://example.com/docs"},
+			})
+			fmt.Fprintf(w, "data: %s\n\n", chunk)
+		}
+		fmt.Fprint(w, "data: [DONE]\n\n")
+	}))
+	defer server.Close()
+
+	client := &PerplexityClient{apiKey: "k", model: "sonar-pro", baseURL: server.URL, httpClient: http.DefaultClient}
+	var streamed strings.Builder
+	result, err := client.ResearchStream(t.Context(), "install foo", func(d string) { streamed.WriteString(d) })
+	if err != nil {
+		t.Fatalf("ResearchStream failed: %v", err)
+	}
+	if streamed.String() != answer {
+		t.Errorf("deltas did not add up to the answer: %q", streamed.String())
+	}
+	if len(result.Citations) != 2 {
+		t.Fatalf("expected 2 citations, got %v", result.Citations)
+	}
+	if got := result.Solutions[0].Source; got != "https://example.com/install" {
+		t.Errorf("explicit [1] source resolved to %q", got)
+	}
+	if got := result.Solutions[1].Source; got != "https://example.com/docs" {
+		t.Errorf("description reference resolved to %q", got)
+	}
+}
+
+func TestAttachCitations_KeepsURLs(t *testing.T) {
+	result := &ResearchResult{Solutions: []Solution{
+		{Source: "https://kept.example"},
+		{Source: "2"},
+		{Source: "[9]"},
+	}}
+	attachCitations(result, []string{"https://a.example", "https://b.example"})
+
+	want := []string{"https://kept.example", "https://b.example", "[9]"}
+	for i, w := range want {
+		if got := result.Solutions[i].Source; got != w {
+			t.Errorf("solution %d source = %q, want %q", i, got, w)
+		}
+	}
+}
@@ -0,0 +1,35 @@
+package llm
+
+import (
+	"fmt"
+	"strings"
+)
+
+// ProjectContext describes the project a request comes from so the model
+// suggests fixes for the right toolchain (no npm fixes inside Go repos).
+type ProjectContext struct {
+	Type           string
+	PackageManager string
+	// Files are the marker files detected at the project root.
+	Files []string
+	// Docs are excerpts of the project's own documentation relevant to the
+	// request, formatted as "path: text".
+	Docs []string
+}
+
+// promptLine renders the context as a prompt rule, or "" when unknown.
+func (p ProjectContext) promptLine() string {
+	if p.Type == "" {
+		return ""
+	}
+	line := fmt.Sprintf("PROJECT CONTEXT: %s project, package manager %s", p.Type, p.PackageManager)
+	if len(p.Files) > 0 {
+		line += " (files: " + strings.Join(p.Files, ", ") + ")"
+	}
+	line += ". Suggest commands and fixes for this toolchain only.\n"
+	if len(p.Docs) > 0 {
+		line += "PROJECT DOCS (prefer the scripts, targets and conventions shown here):\n" +
+			untrusted("DOCS", strings.Join(p.Docs, "\n---\n")) + "\n" + UntrustedNotice + "\n"
+	}
+	return line
+}
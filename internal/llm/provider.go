@@ -0,0 +1,248 @@
+package llm
+
+import (
+	"fmt"
+	"hash/fnv"
+	"strings"
+	"sync"
+)
+
+// LLMProvider is the AI surface used by plugins and the TUI. HybridClient is
+// the production implementation; FakeProvider serves canned responses.
+type LLMProvider interface {
+	Research(query string) (*ResearchResult, error)
+	ResearchStream(query string, onDelta func(string)) (*ResearchResult, error)
+	AnalyzeLog(logLines string, aiMode string) (*LogAnalysisResult, error)
+	Solve(goal string) (string, error)
+	Explain(cmd string, exitCode int, output string) (*ExplainResult, error)
+	CommitMessage(diff string) (string, error)
+	ReviewDiff(diff string) (*ReviewResult, error)
+	GenerateWorkflow(goal string, validate func(string) error) (string, error)
+	SummarizeSession(digest string) (*SessionSummary, error)
+	Embed(texts []string) ([][]float32, error)
+	// Chat continues a conversation with one of the ChatModels, streaming
+	// the reply to onDelta.
+	Chat(model ChatModel, messages []ChatMessage, onDelta func(string)) (string, error)
+	ChatModels() ([]ChatModel, error)
+	// WithProject returns a provider that adds project context to Explain
+	// and Research prompts.
+	WithProject(p ProjectContext) LLMProvider
+	HasPerplexity() bool
+	IsOffline() bool
+}
+
+var _ LLMProvider = (*HybridClient)(nil)
+
+// FakeProvider is an LLMProvider that returns canned responses and records
+// every call, so AI flows can be tested without Ollama or Perplexity.
+type FakeProvider struct {
+	mu sync.Mutex
+
+	// Answers maps an exact query to its research result. Queries without an
+	// entry get a single solution echoing the query.
+	Answers   map[string]*ResearchResult
+	Analysis  *LogAnalysisResult
+	SolveText string
+	Explained *ExplainResult
+	CommitMsg string
+	Review    *ReviewResult
+	Workflow  string
+	Summary   *SessionSummary
+	Offline   bool
+	// ChatReply answers every chat message; empty echoes the message.
+	ChatReply string
+	Models    []ChatModel
+
+	// Project is the context passed to the last WithProject call.
+	Project ProjectContext
+	// Logs are the log lines passed to the last AnalyzeLog call.
+	Logs string
+
+	// Err, when set, is returned by every call.
+	Err error
+
+	Calls []string
+}
+
+var _ LLMProvider = (*FakeProvider)(nil)
+
+func NewFakeProvider() *FakeProvider {
+	return &FakeProvider{
+		Answers:   make(map[string]*ResearchResult),
+		Analysis:  &LogAnalysisResult{Explanation: "fake analysis", Fix: "echo fixed"},
+		SolveText: "echo solved",
+		Explained: &ExplainResult{Explanation: "fake explanation", Fix: "echo fixed"},
+		CommitMsg: "chore: update files",
+		Review:    &ReviewResult{Summary: "No issues found"},
+		Summary:   &SessionSummary{Narrative: "fake session summary"},
+		Models:    []ChatModel{{Provider: ProviderOllama, Name: "fake-model"}},
+	}
+}
+
+func (f *FakeProvider) record(call string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.Calls = append(f.Calls, call)
+	return f.Err
+}
+
+// CallCount reports how many recorded calls equal name. It holds the
+// fake's lock, so it's safe while handlers are still calling in.
+func (f *FakeProvider) CallCount(name string) int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	n := 0
+	for _, c := range f.Calls {
+		if c == name {
+			n++
+		}
+	}
+	return n
+}
+
+func (f *FakeProvider) Research(query string) (*ResearchResult, error) {
+	if err := f.record("Research"); err != nil {
+		return nil, err
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if r, ok := f.Answers[query]; ok {
+		return r, nil
+	}
+	return &ResearchResult{
+		Query: query,
+		Solutions: []Solution{{
+			ID:          1,
+			Title:       "Canned answer",
+			Description: fmt.Sprintf("fake response for %q", query),
+			Source:      "fake",
+		}},
+	}, nil
+}
+
+// ResearchStream delivers the canned answer's description as a single
+// delta before returning it.
+func (f *FakeProvider) ResearchStream(query string, onDelta func(string)) (*ResearchResult, error) {
+	result, err := f.Research(query)
+	if err == nil && onDelta != nil && len(result.Solutions) > 0 {
+		onDelta(result.Solutions[0].Description)
+	}
+	return result, err
+}
+
+func (f *FakeProvider) AnalyzeLog(logLines string, aiMode string) (*LogAnalysisResult, error) {
+	if err := f.record("AnalyzeLog"); err != nil {
+		return nil, err
+	}
+	f.mu.Lock()
+	f.Logs = logLines
+	f.mu.Unlock()
+	return f.Analysis, nil
+}
+
+func (f *FakeProvider) Solve(goal string) (string, error) {
+	if err := f.record("Solve"); err != nil {
+		return "", err
+	}
+	return f.SolveText, nil
+}
+
+func (f *FakeProvider) Explain(cmd string, exitCode int, output string) (*ExplainResult, error) {
+	if err := f.record("Explain"); err != nil {
+		return nil, err
+	}
+	return f.Explained, nil
+}
+
+func (f *FakeProvider) CommitMessage(diff string) (string, error) {
+	if err := f.record("CommitMessage"); err != nil {
+		return "", err
+	}
+	return f.CommitMsg, nil
+}
+
+func (f *FakeProvider) ReviewDiff(diff string) (*ReviewResult, error) {
+	if err := f.record("ReviewDiff"); err != nil {
+		return nil, err
+	}
+	return f.Review, nil
+}
+
+func (f *FakeProvider) GenerateWorkflow(goal string, validate func(string) error) (string, error) {
+	if err := f.record("GenerateWorkflow"); err != nil {
+		return "", err
+	}
+	if validate != nil {
+		if err := validate(f.Workflow); err != nil {
+			return "", fmt.Errorf("generated workflow is invalid: %w", err)
+		}
+	}
+	return f.Workflow, nil
+}
+
+func (f *FakeProvider) SummarizeSession(digest string) (*SessionSummary, error) {
+	if err := f.record("SummarizeSession"); err != nil {
+		return nil, err
+	}
+	return f.Summary, nil
+}
+
+// Embed returns bag-of-words vectors: texts sharing words are similar, which
+// is enough to exercise retrieval deterministically.
+func (f *FakeProvider) Embed(texts []string) ([][]float32, error) {
+	if err := f.record("Embed"); err != nil {
+		return nil, err
+	}
+	out := make([][]float32, len(texts))
+	for i, text := range texts {
+		v := make([]float32, 64)
+		for _, word := range strings.Fields(strings.ToLower(text)) {
+			h := fnv.New32a()
+			h.Write([]byte(strings.Trim(word, ".,:;!?()`'\"")))
+			v[h.Sum32()%64]++
+		}
+		out[i] = v
+	}
+	return out, nil
+}
+
+// Chat streams ChatReply to onDelta word by word.
+func (f *FakeProvider) Chat(model ChatModel, messages []ChatMessage, onDelta func(string)) (string, error) {
+	if err := f.record("Chat " + model.String()); err != nil {
+		return "", err
+	}
+	reply := f.ChatReply
+	if reply == "" && len(messages) > 0 {
+		reply = "fake reply to: " + messages[len(messages)-1].Content
+	}
+	if onDelta != nil {
+		for _, word := range strings.SplitAfter(reply, " ") {
+			onDelta(word)
+		}
+	}
+	return reply, nil
+}
+
+func (f *FakeProvider) ChatModels() ([]ChatModel, error) {
+	if err := f.record("ChatModels"); err != nil {
+		return nil, err
+	}
+	return f.Models, nil
+}
+
+// WithProject records the context and returns the same fake, so calls made
+// through the returned provider are still visible in Calls.
+func (f *FakeProvider) WithProject(p ProjectContext) LLMProvider {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.Project = p
+	return f
+}
+
+func (f *FakeProvider) HasPerplexity() bool {
+	return false
+}
+
+func (f *FakeProvider) IsOffline() bool {
+	return f.Offline
+}
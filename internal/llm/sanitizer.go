@@ -111,6 +111,24 @@ func MaskEnvVars(input string) string {
 	return sensitiveVars.ReplaceAllString(input, `$1$2=[REDACTED]`)
 }
 
+// secretEnvName matches environment variable names whose values are
+// secrets, whatever the value looks like.
+var secretEnvName = regexp.MustCompile(`(?i)(KEY|SECRET|PASSWORD|PASSWD|TOKEN|CREDENTIAL|AUTH)`)
+
+// SanitizeEnv masks an environment variable ("NAME=value"): the whole
+// value when the name looks secret, otherwise the secrets the sanitizer
+// finds in it, like passwords in URLs.
+func SanitizeEnv(env string) string {
+	name, value, ok := strings.Cut(env, "=")
+	if !ok {
+		return env
+	}
+	if value != "" && secretEnvName.MatchString(name) {
+		return name + "=[REDACTED]"
+	}
+	return name + "=" + SanitizeOutput(value)
+}
+
 func SanitizeOutput(output string) string {
 	result := globalSanitizer.Sanitize(output)
 	result = MaskEnvVars(result)
@@ -185,6 +185,26 @@ func TestSanitize_Password(t *testing.T) {
 	}
 }
 
+func TestSanitizeEnv(t *testing.T) {
+	tests := []struct {
+		input string
+		want  string
+	}{
+		{"POSTGRES_PASSWORD=hunter2", "POSTGRES_PASSWORD=[REDACTED]"},
+		{"STRIPE_SECRET_KEY=sk_test_fake", "STRIPE_SECRET_KEY=[REDACTED]"},
+		{"GITHUB_TOKEN=", "GITHUB_TOKEN="},
+		{"DATABASE_URL=postgresql://app:hunter2@db:5432/app", "DATABASE_URL=postgresql://[user]:[REDACTED]@db:5432/app"},
+		{"PATH=/usr/local/bin:/usr/bin", "PATH=/usr/local/bin:/usr/bin"},
+		{"NODE_ENV=production", "NODE_ENV=production"},
+	}
+
+	for _, tt := range tests {
+		if got := SanitizeEnv(tt.input); got != tt.want {
+			t.Errorf("SanitizeEnv(%q) = %q, want %q", tt.input, got, tt.want)
+		}
+	}
+}
+
 func TestSanitizeWithReport(t *testing.T) {
 	input := "api_key=supersecretapikey12345 and password=secret123"
 	sanitizer := DefaultSanitizer()
@@ -0,0 +1,279 @@
+// Package mcp connects to external Model Context Protocol servers over the
+// stdio transport and exposes their tools to the agent's tool registry.
+package mcp
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// protocolVersion is the MCP revision this client speaks.
+const protocolVersion = "2024-11-05"
+
+// ServerConfig describes how to launch one external MCP server. The file
+// format matches the "mcpServers" block used by other MCP clients.
+type ServerConfig struct {
+	Command string            `json:"command"`
+	Args    []string          `json:"args,omitempty"`
+	Env     map[string]string `json:"env,omitempty"`
+}
+
+// LoadServers reads server definitions from a JSON file of the form
+// {"mcpServers": {"name": {"command": ..., "args": [...]}}}. A missing file
+// means no servers are configured.
+func LoadServers(path string) (map[string]ServerConfig, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var file struct {
+		MCPServers map[string]ServerConfig `json:"mcpServers"`
+	}
+	if err := json.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("parse %s: %w", path, err)
+	}
+	for name, s := range file.MCPServers {
+		if s.Command == "" {
+			return nil, fmt.Errorf("server %q: command is required", name)
+		}
+		if strings.Contains(name, "__") {
+			return nil, fmt.Errorf("server %q: name must not contain \"__\"", name)
+		}
+	}
+	return file.MCPServers, nil
+}
+
+// RemoteTool is a tool advertised by a server.
+type RemoteTool struct {
+	Name        string          `json:"name"`
+	Description string          `json:"description"`
+	InputSchema json.RawMessage `json:"inputSchema"`
+}
+
+// Client is a connection to one running server. A single read loop owns
+// the server's stdout and hands each response to the call waiting on its
+// ID, so a call that gives up early leaves the connection usable.
+type Client struct {
+	Name string
+
+	cmd    *exec.Cmd
+	stdin  io.WriteCloser
+	stdout *bufio.Reader
+
+	writeMu sync.Mutex
+
+	mu      sync.Mutex
+	nextID  int
+	pending map[int]chan rpcResponse
+	// readErr is why the read loop stopped; calls fail with it after that.
+	readErr error
+}
+
+type rpcRequest struct {
+	JSONRPC string `json:"jsonrpc"`
+	ID      *int   `json:"id,omitempty"`
+	Method  string `json:"method"`
+	Params  any    `json:"params,omitempty"`
+}
+
+type rpcResponse struct {
+	ID *int `json:"id"`
+	// Method is set on requests the server sends to us.
+	Method string          `json:"method"`
+	Result json.RawMessage `json:"result"`
+	Error  *struct {
+		Code    int    `json:"code"`
+		Message string `json:"message"`
+	} `json:"error"`
+}
+
+// Start launches the server and performs the MCP initialize handshake.
+func Start(ctx context.Context, name string, cfg ServerConfig) (*Client, error) {
+	cmd := exec.Command(cfg.Command, cfg.Args...)
+	cmd.Env = os.Environ()
+	for k, v := range cfg.Env {
+		cmd.Env = append(cmd.Env, k+"="+v)
+	}
+
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		return nil, err
+	}
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return nil, err
+	}
+	if err := cmd.Start(); err != nil {
+		return nil, fmt.Errorf("start %s: %w", name, err)
+	}
+
+	c := &Client{Name: name, cmd: cmd, stdin: stdin, stdout: bufio.NewReader(stdout), pending: make(map[int]chan rpcResponse)}
+	go c.readLoop()
+
+	init := map[string]any{
+		"protocolVersion": protocolVersion,
+		"capabilities":    map[string]any{},
+		"clientInfo":      map[string]string{"name": "dev-cli", "version": "1.0"},
+	}
+	if _, err := c.call(ctx, "initialize", init); err != nil {
+		c.Close()
+		return nil, fmt.Errorf("initialize %s: %w", name, err)
+	}
+	if err := c.send(rpcRequest{JSONRPC: "2.0", Method: "notifications/initialized"}); err != nil {
+		c.Close()
+		return nil, err
+	}
+	return c, nil
+}
+
+// ListTools returns the tools the server offers.
+func (c *Client) ListTools(ctx context.Context) ([]RemoteTool, error) {
+	raw, err := c.call(ctx, "tools/list", map[string]any{})
+	if err != nil {
+		return nil, err
+	}
+	var result struct {
+		Tools []RemoteTool `json:"tools"`
+	}
+	if err := json.Unmarshal(raw, &result); err != nil {
+		return nil, fmt.Errorf("decode tools/list: %w", err)
+	}
+	sort.Slice(result.Tools, func(i, j int) bool { return result.Tools[i].Name < result.Tools[j].Name })
+	return result.Tools, nil
+}
+
+// CallTool invokes a tool and returns its text content. isError is the
+// server's report that the tool itself failed, as opposed to err, which
+// means the call could not be made.
+func (c *Client) CallTool(ctx context.Context, name string, args map[string]any) (text string, isError bool, err error) {
+	raw, err := c.call(ctx, "tools/call", map[string]any{"name": name, "arguments": args})
+	if err != nil {
+		return "", false, err
+	}
+	var result struct {
+		Content []struct {
+			Type string `json:"type"`
+			Text string `json:"text"`
+		} `json:"content"`
+		IsError bool `json:"isError"`
+	}
+	if err := json.Unmarshal(raw, &result); err != nil {
+		return "", false, fmt.Errorf("decode tools/call: %w", err)
+	}
+
+	var parts []string
+	for _, block := range result.Content {
+		if block.Type == "text" {
+			parts = append(parts, block.Text)
+		}
+	}
+	return strings.Join(parts, "\n"), result.IsError, nil
+}
+
+// Close stops the server.
+func (c *Client) Close() error {
+	c.stdin.Close()
+	if c.cmd.Process != nil {
+		c.cmd.Process.Kill()
+	}
+	c.cmd.Wait()
+	return nil
+}
+
+func (c *Client) send(req rpcRequest) error {
+	data, err := json.Marshal(req)
+	if err != nil {
+		return err
+	}
+	c.writeMu.Lock()
+	defer c.writeMu.Unlock()
+	_, err = c.stdin.Write(append(data, '\n'))
+	return err
+}
+
+// readLoop reads the server's output until it closes, delivering each
+// response to the call waiting on its ID. Notifications, server-initiated
+// requests and responses nobody waits for any more are dropped.
+func (c *Client) readLoop() {
+	for {
+		line, err := c.stdout.ReadBytes('\n')
+		if err != nil {
+			c.mu.Lock()
+			c.readErr = fmt.Errorf("server closed: %w", err)
+			for id, ch := range c.pending {
+				close(ch)
+				delete(c.pending, id)
+			}
+			c.mu.Unlock()
+			return
+		}
+
+		var resp rpcResponse
+		if json.Unmarshal(line, &resp) != nil || resp.Method != "" || resp.ID == nil {
+			continue
+		}
+		c.mu.Lock()
+		ch, ok := c.pending[*resp.ID]
+		delete(c.pending, *resp.ID)
+		c.mu.Unlock()
+		if ok {
+			ch <- resp
+		}
+	}
+}
+
+// call sends a request and waits for the response with the same ID.
+func (c *Client) call(ctx context.Context, method string, params any) (json.RawMessage, error) {
+	c.mu.Lock()
+	if c.readErr != nil {
+		err := c.readErr
+		c.mu.Unlock()
+		return nil, fmt.Errorf("%s: %w", method, err)
+	}
+	c.nextID++
+	id := c.nextID
+	ch := make(chan rpcResponse, 1)
+	c.pending[id] = ch
+	c.mu.Unlock()
+
+	forget := func() {
+		c.mu.Lock()
+		delete(c.pending, id)
+		c.mu.Unlock()
+	}
+
+	if err := c.send(rpcRequest{JSONRPC: "2.0", ID: &id, Method: method, Params: params}); err != nil {
+		forget()
+		return nil, fmt.Errorf("%s: %w", method, err)
+	}
+
+	select {
+	case <-ctx.Done():
+		forget()
+		return nil, ctx.Err()
+	case resp, ok := <-ch:
+		if !ok {
+			c.mu.Lock()
+			err := c.readErr
+			c.mu.Unlock()
+			return nil, fmt.Errorf("%s: %w", method, err)
+		}
+		if resp.Error != nil {
+			return nil, fmt.Errorf("%s: %s (code %d)", method, resp.Error.Message, resp.Error.Code)
+		}
+		return resp.Result, nil
+	}
+}
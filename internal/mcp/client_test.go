@@ -0,0 +1,227 @@
+package mcp
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"dev-cli/internal/ai"
+	"dev-cli/internal/tools"
+)
+
+// TestMain doubles as a fake MCP server when re-executed by the tests.
+func TestMain(m *testing.M) {
+	if os.Getenv("DEV_CLI_FAKE_MCP_SERVER") == "1" {
+		runFakeServer()
+		os.Exit(0)
+	}
+	os.Exit(m.Run())
+}
+
+// runFakeServer serves one "echo" tool over stdio and sends a notification
+// before every response, as real servers may. A "delay_ms" argument holds
+// the response back.
+func runFakeServer() {
+	in := bufio.NewScanner(os.Stdin)
+	out := json.NewEncoder(os.Stdout)
+	for in.Scan() {
+		var req struct {
+			ID     *int            `json:"id"`
+			Method string          `json:"method"`
+			Params json.RawMessage `json:"params"`
+		}
+		if json.Unmarshal(in.Bytes(), &req) != nil || req.ID == nil {
+			continue
+		}
+		out.Encode(map[string]any{"jsonrpc": "2.0", "method": "notifications/message", "params": map[string]any{}})
+
+		var result any
+		switch req.Method {
+		case "initialize":
+			result = map[string]any{"protocolVersion": protocolVersion, "capabilities": map[string]any{"tools": map[string]any{}}}
+		case "tools/list":
+			result = map[string]any{"tools": []map[string]any{{
+				"name":        "echo",
+				"description": "Echo text back",
+				"inputSchema": map[string]any{
+					"type":       "object",
+					"properties": map[string]any{"text": map[string]any{"type": "string", "description": "Text to echo"}, "times": map[string]any{"type": "integer"}},
+					"required":   []string{"text"},
+				},
+			}}}
+		case "tools/call":
+			var p struct {
+				Arguments map[string]any `json:"arguments"`
+			}
+			json.Unmarshal(req.Params, &p)
+			if ms, ok := p.Arguments["delay_ms"].(float64); ok {
+				time.Sleep(time.Duration(ms) * time.Millisecond)
+			}
+			text, _ := p.Arguments["text"].(string)
+			result = map[string]any{"content": []map[string]any{{"type": "text", "text": text}}, "isError": text == ""}
+		default:
+			out.Encode(map[string]any{"jsonrpc": "2.0", "id": *req.ID, "error": map[string]any{"code": -32601, "message": "method not found"}})
+			continue
+		}
+		out.Encode(map[string]any{"jsonrpc": "2.0", "id": *req.ID, "result": result})
+	}
+}
+
+func fakeServer() ServerConfig {
+	return ServerConfig{Command: os.Args[0], Env: map[string]string{"DEV_CLI_FAKE_MCP_SERVER": "1"}}
+}
+
+func TestFederate(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	reg := tools.NewRegistry()
+	servers := map[string]ServerConfig{
+		"fake":   fakeServer(),
+		"broken": {Command: filepath.Join(t.TempDir(), "does-not-exist")},
+	}
+	clients, errs := Federate(ctx, reg, servers)
+	defer func() {
+		for _, c := range clients {
+			c.Close()
+		}
+	}()
+
+	if len(clients) != 1 || len(errs) != 1 {
+		t.Fatalf("expected one working and one broken server, got %d clients, errs %v", len(clients), errs)
+	}
+
+	tool, ok := reg.Get("fake__echo")
+	if !ok {
+		t.Fatalf("remote tool not registered, have %v", reg.Names())
+	}
+	params := tool.Parameters()
+	if len(params) != 2 || params[0].Name != "text" || !params[0].Required || params[1].Type != "int" {
+		t.Errorf("unexpected parameters: %+v", params)
+	}
+
+	result := reg.Call(ctx, "fake__echo", map[string]any{"text": "hello"})
+	if !result.Success || result.Data != "hello" {
+		t.Errorf("unexpected result: %+v", result)
+	}
+	if result := reg.Call(ctx, "fake__echo", map[string]any{}); result.Success {
+		t.Error("isError from the server should fail the call")
+	}
+
+	schemas, err := reg.GetSchemasJSON()
+	if err != nil || !json.Valid([]byte(schemas)) {
+		t.Errorf("schemas should include remote tools as valid JSON: %v", err)
+	}
+}
+
+type fakePlanner struct {
+	schemas string
+	call    *ai.ToolCallResult
+}
+
+func (p *fakePlanner) GenerateWithTools(prompt string, toolSchemas string) (*ai.ToolCallResult, error) {
+	p.schemas = toolSchemas
+	return p.call, nil
+}
+
+type fakeSolver struct{ prompts []string }
+
+func (s *fakeSolver) Solve(goal string) (string, error) {
+	s.prompts = append(s.prompts, goal)
+	return "true", nil
+}
+
+type fakeExecutor struct{}
+
+func (fakeExecutor) Execute(command string) (bool, string) { return true, "" }
+
+func TestFederate_AgentCallsRemoteTools(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	reg := tools.NewRegistry()
+	clients, errs := Federate(ctx, reg, map[string]ServerConfig{"fake": fakeServer()})
+	defer func() {
+		for _, c := range clients {
+			c.Close()
+		}
+	}()
+	if len(errs) > 0 {
+		t.Fatal(errs)
+	}
+
+	planner := &fakePlanner{call: &ai.ToolCallResult{ToolName: "fake__echo", Parameters: map[string]any{"text": "disk 97% full"}}}
+	solver := &fakeSolver{}
+	agent := ai.NewAgentWithDeps(solver, fakeExecutor{})
+	agent.SetTools(planner, reg)
+
+	var asked []string
+	err := agent.Resolve("the disk is full", func(proposal string) bool {
+		asked = append(asked, proposal)
+		return true
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(planner.schemas, "fake__echo") {
+		t.Errorf("expected the remote tool in the agent's schema, got %s", planner.schemas)
+	}
+	if len(asked) != 2 || !strings.HasPrefix(asked[0], "tool fake__echo") {
+		t.Errorf("expected the tool call approved before the command, got %q", asked)
+	}
+	if len(solver.prompts) != 1 || !strings.Contains(solver.prompts[0], "disk 97% full") {
+		t.Errorf("expected the remote tool's result in the prompt, got %q", solver.prompts)
+	}
+}
+
+func TestClient_CancelledCallKeepsConnection(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	c, err := Start(ctx, "fake", fakeServer())
+	if err != nil {
+		t.Fatalf("Start failed: %v", err)
+	}
+	defer c.Close()
+
+	short, cancelShort := context.WithTimeout(ctx, 20*time.Millisecond)
+	defer cancelShort()
+	if _, _, err := c.CallTool(short, "echo", map[string]any{"text": "late", "delay_ms": 200}); err == nil {
+		t.Fatal("expected the slow call to time out")
+	}
+
+	// The late reply to the cancelled call must not be taken for this one.
+	text, isError, err := c.CallTool(ctx, "echo", map[string]any{"text": "next"})
+	if err != nil || isError || text != "next" {
+		t.Errorf("expected the next call to get its own reply, got %q, %v, %v", text, isError, err)
+	}
+}
+
+func TestLoadServers(t *testing.T) {
+	dir := t.TempDir()
+
+	servers, err := LoadServers(filepath.Join(dir, "missing.json"))
+	if err != nil || servers != nil {
+		t.Errorf("missing file should mean no servers, got %v, %v", servers, err)
+	}
+
+	path := filepath.Join(dir, "mcp.json")
+	os.WriteFile(path, []byte(`{"mcpServers": {"fs": {"command": "mcp-fs", "args": ["/tmp"]}}}`), 0644)
+	servers, err = LoadServers(path)
+	if err != nil {
+		t.Fatalf("LoadServers failed: %v", err)
+	}
+	if s := servers["fs"]; s.Command != "mcp-fs" || len(s.Args) != 1 {
+		t.Errorf("unexpected config: %+v", servers)
+	}
+
+	os.WriteFile(path, []byte(`{"mcpServers": {"fs": {"args": ["/tmp"]}}}`), 0644)
+	if _, err := LoadServers(path); err == nil {
+		t.Error("expected error for server without command")
+	}
+}
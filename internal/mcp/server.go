@@ -0,0 +1,253 @@
+package mcp
+
+import (
+	"bufio"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+
+	"dev-cli/internal/storage"
+	"dev-cli/internal/tools"
+)
+
+// FailuresURI is the resource clients subscribe to for failure
+// notifications. Reading it returns the most recent failed commands.
+const FailuresURI = "devcli://failures"
+
+// failurePollInterval is how often the history table is checked for new
+// failures; rows are written by the shell hook in a separate process.
+var failurePollInterval = 2 * time.Second
+
+// Server exposes a tool registry to an MCP client over stdio and, when it
+// has a history database, pushes a notification for every new failure.
+type Server struct {
+	reg *tools.Registry
+	db  *sql.DB
+
+	outMu sync.Mutex
+	out   *json.Encoder
+
+	mu         sync.Mutex
+	subscribed bool
+}
+
+// NewServer creates a server for reg. db may be nil, in which case the
+// failures resource is not offered.
+func NewServer(reg *tools.Registry, db *sql.DB) *Server {
+	return &Server{reg: reg, db: db}
+}
+
+type serverRequest struct {
+	ID     json.RawMessage `json:"id"`
+	Method string          `json:"method"`
+	Params json.RawMessage `json:"params"`
+}
+
+type rpcError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+// Serve handles requests from in until it is closed or ctx is done.
+func (s *Server) Serve(ctx context.Context, in io.Reader, out io.Writer) error {
+	s.out = json.NewEncoder(out)
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	if s.db != nil {
+		// Take the starting point before serving, so a failure recorded
+		// while the first request is handled is not mistaken for history.
+		var lastID int64
+		if recent, err := storage.GetRecentHistory(s.db, 1); err == nil && len(recent) > 0 {
+			lastID = recent[0].ID
+		}
+		go s.watchFailures(ctx, lastID)
+	}
+
+	scanner := bufio.NewScanner(in)
+	scanner.Buffer(make([]byte, 64*1024), 4*1024*1024)
+	for scanner.Scan() {
+		var req serverRequest
+		if err := json.Unmarshal(scanner.Bytes(), &req); err != nil {
+			s.write(map[string]any{"jsonrpc": "2.0", "id": nil, "error": rpcError{-32700, "parse error"}})
+			continue
+		}
+		// Notifications (no ID) need no reply.
+		if len(req.ID) == 0 {
+			continue
+		}
+
+		result, rerr := s.handle(ctx, req)
+		if rerr != nil {
+			s.write(map[string]any{"jsonrpc": "2.0", "id": req.ID, "error": rerr})
+		} else {
+			s.write(map[string]any{"jsonrpc": "2.0", "id": req.ID, "result": result})
+		}
+	}
+	return scanner.Err()
+}
+
+func (s *Server) handle(ctx context.Context, req serverRequest) (any, *rpcError) {
+	switch req.Method {
+	case "initialize":
+		caps := map[string]any{"tools": map[string]any{}, "logging": map[string]any{}}
+		if s.db != nil {
+			caps["resources"] = map[string]any{"subscribe": true}
+		}
+		return map[string]any{
+			"protocolVersion": protocolVersion,
+			"capabilities":    caps,
+			"serverInfo":      map[string]string{"name": "dev-cli", "version": "1.0"},
+		}, nil
+
+	case "ping":
+		return map[string]any{}, nil
+
+	case "tools/list":
+		schemas := s.reg.GetSchemas()
+		list := make([]map[string]any, 0, len(schemas))
+		for _, schema := range schemas {
+			list = append(list, map[string]any{
+				"name":        schema.Name,
+				"description": schema.Description,
+				"inputSchema": schema.Parameters,
+			})
+		}
+		return map[string]any{"tools": list}, nil
+
+	case "tools/call":
+		var p struct {
+			Name      string         `json:"name"`
+			Arguments map[string]any `json:"arguments"`
+		}
+		if err := json.Unmarshal(req.Params, &p); err != nil {
+			return nil, &rpcError{-32602, "invalid params"}
+		}
+		result := s.reg.Call(ctx, p.Name, p.Arguments)
+		text := result.Error
+		if result.Success {
+			data, _ := json.Marshal(result.Data)
+			text = string(data)
+		}
+		return map[string]any{
+			"content": []map[string]string{{"type": "text", "text": text}},
+			"isError": !result.Success,
+		}, nil
+
+	case "resources/list":
+		if s.db == nil {
+			return map[string]any{"resources": []any{}}, nil
+		}
+		return map[string]any{"resources": []map[string]string{{
+			"uri":         FailuresURI,
+			"name":        "Recent command failures",
+			"description": "Failed shell commands with their error signature and known solutions",
+			"mimeType":    "application/json",
+		}}}, nil
+
+	case "resources/read":
+		if s.db == nil {
+			return nil, &rpcError{-32002, "resource not found"}
+		}
+		items, err := storage.GetFailures(s.db, storage.QueryOpts{Limit: 10})
+		if err != nil {
+			return nil, &rpcError{-32603, err.Error()}
+		}
+		events := make([]FailureEvent, 0, len(items))
+		for _, item := range items {
+			events = append(events, s.failureEvent(item))
+		}
+		data, _ := json.Marshal(events)
+		return map[string]any{"contents": []map[string]string{{
+			"uri": FailuresURI, "mimeType": "application/json", "text": string(data),
+		}}}, nil
+
+	case "resources/subscribe", "resources/unsubscribe":
+		var p struct {
+			URI string `json:"uri"`
+		}
+		json.Unmarshal(req.Params, &p)
+		if s.db == nil || p.URI != FailuresURI {
+			return nil, &rpcError{-32002, "resource not found"}
+		}
+		s.mu.Lock()
+		s.subscribed = req.Method == "resources/subscribe"
+		s.mu.Unlock()
+		return map[string]any{}, nil
+
+	case "logging/setLevel":
+		return map[string]any{}, nil
+	}
+
+	return nil, &rpcError{-32601, fmt.Sprintf("method not found: %s", req.Method)}
+}
+
+// FailureEvent is the payload pushed for a new failed command.
+type FailureEvent struct {
+	ID        int64    `json:"id"`
+	Command   string   `json:"command"`
+	ExitCode  int      `json:"exit_code"`
+	Directory string   `json:"directory"`
+	Signature string   `json:"signature"`
+	Solutions []string `json:"solutions,omitempty"`
+}
+
+func (s *Server) failureEvent(item storage.HistoryItem) FailureEvent {
+	output, _ := storage.GetOutput(s.db, item)
+
+	ev := FailureEvent{
+		ID:        item.ID,
+		Command:   item.Command,
+		ExitCode:  item.ExitCode,
+		Directory: item.Directory,
+		Signature: storage.GenerateErrorSignature(item.Command, item.ExitCode, output),
+	}
+	if rc, err := storage.GetRootCauseBySignature(s.db, ev.Signature); err == nil && rc != nil {
+		ev.Solutions = rc.RemediationSteps
+	}
+	return ev
+}
+
+// watchFailures polls for failures recorded after lastID and notifies a
+// subscribed client about each one.
+func (s *Server) watchFailures(ctx context.Context, lastID int64) {
+	ticker := time.NewTicker(failurePollInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		items, err := storage.GetFailuresAfter(s.db, lastID)
+		if err != nil {
+			continue
+		}
+		for _, item := range items {
+			lastID = max(lastID, item.ID)
+
+			s.mu.Lock()
+			subscribed := s.subscribed
+			s.mu.Unlock()
+			if !subscribed {
+				continue
+			}
+
+			s.write(map[string]any{"jsonrpc": "2.0", "method": "notifications/resources/updated",
+				"params": map[string]string{"uri": FailuresURI}})
+			s.write(map[string]any{"jsonrpc": "2.0", "method": "notifications/message",
+				"params": map[string]any{"level": "error", "logger": "dev-cli", "data": s.failureEvent(item)}})
+		}
+	}
+}
+
+func (s *Server) write(msg any) {
+	s.outMu.Lock()
+	defer s.outMu.Unlock()
+	s.out.Encode(msg)
+}
@@ -0,0 +1,136 @@
+package mcp
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"io"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"dev-cli/internal/storage"
+	"dev-cli/internal/tools"
+)
+
+type testConn struct {
+	t   *testing.T
+	in  *io.PipeWriter
+	out *bufio.Scanner
+	id  int
+}
+
+func startTestServer(t *testing.T, srv *Server) *testConn {
+	t.Helper()
+	inR, inW := io.Pipe()
+	outR, outW := io.Pipe()
+	ctx, cancel := context.WithCancel(context.Background())
+	go srv.Serve(ctx, inR, outW)
+	t.Cleanup(func() {
+		cancel()
+		inW.Close()
+		outR.Close()
+	})
+	return &testConn{t: t, in: inW, out: bufio.NewScanner(outR)}
+}
+
+// next reads the next message, failing the test after a timeout.
+func (c *testConn) next() map[string]any {
+	c.t.Helper()
+	line := make(chan []byte, 1)
+	go func() {
+		if c.out.Scan() {
+			line <- append([]byte(nil), c.out.Bytes()...)
+		}
+	}()
+	select {
+	case data := <-line:
+		var msg map[string]any
+		if err := json.Unmarshal(data, &msg); err != nil {
+			c.t.Fatalf("invalid message %s: %v", data, err)
+		}
+		return msg
+	case <-time.After(5 * time.Second):
+		c.t.Fatal("timed out waiting for server message")
+		return nil
+	}
+}
+
+func (c *testConn) call(method string, params any) map[string]any {
+	c.t.Helper()
+	c.id++
+	data, _ := json.Marshal(map[string]any{"jsonrpc": "2.0", "id": c.id, "method": method, "params": params})
+	c.in.Write(append(data, '\n'))
+	msg := c.next()
+	if msg["error"] != nil {
+		c.t.Fatalf("%s failed: %v", method, msg["error"])
+	}
+	return msg["result"].(map[string]any)
+}
+
+func TestServer_Tools(t *testing.T) {
+	reg := tools.NewRegistry()
+	reg.MustRegister(&tools.CheckPortsTool{})
+	conn := startTestServer(t, NewServer(reg, nil))
+
+	init := conn.call("initialize", map[string]any{"protocolVersion": protocolVersion})
+	if init["protocolVersion"] != protocolVersion {
+		t.Errorf("unexpected initialize result: %v", init)
+	}
+
+	list := conn.call("tools/list", map[string]any{})
+	toolList := list["tools"].([]any)
+	if len(toolList) != 1 || toolList[0].(map[string]any)["name"] != "check_ports" {
+		t.Errorf("unexpected tools: %v", toolList)
+	}
+
+	result := conn.call("tools/call", map[string]any{"name": "write_file", "arguments": map[string]any{}})
+	if result["isError"] != true {
+		t.Errorf("unregistered tool should return isError, got %v", result)
+	}
+}
+
+func TestServer_FailureNotifications(t *testing.T) {
+	interval := failurePollInterval
+	failurePollInterval = 10 * time.Millisecond
+	t.Cleanup(func() { failurePollInterval = interval })
+
+	db, err := storage.OpenDB(filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	storage.SaveCommand(db, storage.LogEntry{Command: "old failure", ExitCode: 1})
+	output := "npm ERR! missing script: start"
+	storage.SaveRootCause(db, storage.RootCause{
+		ID:               "rc1",
+		ErrorSignature:   storage.GenerateErrorSignature("npm start", 1, output),
+		Timestamp:        time.Now(),
+		RemediationSteps: []string{"add a start script to package.json"},
+	})
+
+	conn := startTestServer(t, NewServer(tools.NewRegistry(), db))
+	conn.call("initialize", map[string]any{})
+	conn.call("resources/subscribe", map[string]any{"uri": FailuresURI})
+
+	storage.SaveCommand(db, storage.LogEntry{Command: "npm start", ExitCode: 1, Output: output, Cwd: "/app"})
+
+	if msg := conn.next(); msg["method"] != "notifications/resources/updated" {
+		t.Fatalf("expected resource update, got %v", msg)
+	}
+	msg := conn.next()
+	if msg["method"] != "notifications/message" {
+		t.Fatalf("expected message notification, got %v", msg)
+	}
+	data, _ := json.Marshal(msg["params"].(map[string]any)["data"])
+	var ev FailureEvent
+	json.Unmarshal(data, &ev)
+	if ev.Command != "npm start" || ev.Signature == "" {
+		t.Errorf("unexpected event: %+v", ev)
+	}
+	if len(ev.Solutions) != 1 || !strings.Contains(ev.Solutions[0], "start script") {
+		t.Errorf("known solution not attached: %+v", ev)
+	}
+}
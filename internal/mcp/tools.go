@@ -0,0 +1,127 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"time"
+
+	"dev-cli/internal/tools"
+)
+
+// Tool adapts a remote tool to tools.Tool. Its name is "<server>__<tool>"
+// so tools from different servers cannot collide with each other or with
+// the built-in ones.
+type Tool struct {
+	client *Client
+	remote RemoteTool
+	params []tools.ToolParam
+}
+
+// NewTool wraps a remote tool served by client.
+func NewTool(client *Client, remote RemoteTool) *Tool {
+	return &Tool{client: client, remote: remote, params: schemaParams(remote.InputSchema)}
+}
+
+func (t *Tool) Name() string                  { return t.client.Name + "__" + t.remote.Name }
+func (t *Tool) Description() string           { return t.remote.Description }
+func (t *Tool) Parameters() []tools.ToolParam { return t.params }
+
+func (t *Tool) Execute(ctx context.Context, params map[string]any) tools.ToolResult {
+	start := time.Now()
+	text, isError, err := t.client.CallTool(ctx, t.remote.Name, params)
+	if err != nil {
+		return tools.NewErrorResult(err.Error(), time.Since(start))
+	}
+	if isError {
+		return tools.NewErrorResult(text, time.Since(start))
+	}
+	return tools.NewResult(text, time.Since(start))
+}
+
+// schemaParams flattens the top level of a JSON Schema object into the
+// registry's parameter list. Nested objects are passed through as-is.
+func schemaParams(schema json.RawMessage) []tools.ToolParam {
+	var s struct {
+		Properties map[string]struct {
+			Type        string `json:"type"`
+			Description string `json:"description"`
+			Default     any    `json:"default"`
+			Items       *struct {
+				Type string `json:"type"`
+			} `json:"items"`
+		} `json:"properties"`
+		Required []string `json:"required"`
+	}
+	if len(schema) == 0 || json.Unmarshal(schema, &s) != nil {
+		return nil
+	}
+
+	required := make(map[string]bool)
+	for _, name := range s.Required {
+		required[name] = true
+	}
+
+	params := make([]tools.ToolParam, 0, len(s.Properties))
+	for name, prop := range s.Properties {
+		typ := "string"
+		switch prop.Type {
+		case "integer":
+			typ = "int"
+		case "number":
+			typ = "number"
+		case "boolean":
+			typ = "bool"
+		case "object":
+			typ = "object"
+		case "array":
+			typ = "[]string"
+			if prop.Items != nil && prop.Items.Type == "integer" {
+				typ = "[]int"
+			}
+		}
+		params = append(params, tools.ToolParam{
+			Name:        name,
+			Type:        typ,
+			Description: prop.Description,
+			Required:    required[name],
+			Default:     prop.Default,
+		})
+	}
+	sort.Slice(params, func(i, j int) bool { return params[i].Name < params[j].Name })
+	return params
+}
+
+// Federate starts every configured server and registers its tools in reg.
+// A server that fails to start or list its tools is reported in errs and
+// skipped; the others are still registered. Callers own the returned
+// clients and must Close them.
+func Federate(ctx context.Context, reg *tools.Registry, servers map[string]ServerConfig) (clients []*Client, errs []error) {
+	names := make([]string, 0, len(servers))
+	for name := range servers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		client, err := Start(ctx, name, servers[name])
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		remote, err := client.ListTools(ctx)
+		if err != nil {
+			client.Close()
+			errs = append(errs, fmt.Errorf("%s: %w", name, err))
+			continue
+		}
+		for _, rt := range remote {
+			if err := reg.Register(NewTool(client, rt)); err != nil {
+				errs = append(errs, err)
+			}
+		}
+		clients = append(clients, client)
+	}
+	return clients, errs
+}
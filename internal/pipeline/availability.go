@@ -0,0 +1,56 @@
+package pipeline
+
+// Subsystem names an external dependency the UI can run without.
+type Subsystem string
+
+const (
+	SubsystemDocker  Subsystem = "docker"
+	SubsystemOllama  Subsystem = "ollama"
+	SubsystemHistory Subsystem = "history"
+)
+
+// Availability is the result of the last probe of a subsystem. Until the
+// first probe finishes Checked is false and features should assume it works.
+type Availability struct {
+	Checked   bool
+	Available bool
+	// Hint is a one-line setup prompt shown inline where the feature lives.
+	Hint string
+}
+
+// Missing reports whether the subsystem was probed and found absent.
+func (a Availability) Missing() bool {
+	return a.Checked && !a.Available
+}
+
+var setupHints = map[Subsystem]string{
+	SubsystemDocker:  "Docker is not reachable. Start the daemon (systemctl start docker, or open Docker Desktop); containers show up here automatically.",
+	SubsystemOllama:  "Ollama is not reachable. Install it from https://ollama.com, then run: ollama serve",
+	SubsystemHistory: "History database is unavailable. Run: dev-cli doctor --fix",
+}
+
+func (s *StateStore) SetAvailable(sub Subsystem, available bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.availability[sub] = available
+}
+
+func (s *StateStore) Availability(sub Subsystem) Availability {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	available, checked := s.availability[sub]
+	return Availability{Checked: checked, Available: available, Hint: setupHints[sub]}
+}
+
+// Degraded reports whether any probed subsystem is missing, i.e. the UI is
+// running in reduced mode.
+func (s *StateStore) Degraded() bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	for _, available := range s.availability {
+		if !available {
+			return true
+		}
+	}
+	return false
+}
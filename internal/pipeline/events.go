@@ -13,6 +13,9 @@ const (
 	EventCommandComplete EventType = "command.complete"
 	EventCommandError    EventType = "command.error"
 
+	EventJobStart EventType = "job.start"
+	EventJobDone  EventType = "job.done"
+
 	EventContainerLog    EventType = "container.log"
 	EventContainerStatus EventType = "container.status"
 	EventContainerAlert  EventType = "container.alert"
@@ -29,8 +32,10 @@ const (
 	// Workflow events
 	EventWorkflowStart      EventType = "workflow.start"
 	EventWorkflowStep       EventType = "workflow.step"
+	EventWorkflowOutput     EventType = "workflow.output"
 	EventWorkflowCheckpoint EventType = "workflow.checkpoint"
 	EventWorkflowComplete   EventType = "workflow.complete"
+	EventWorkflowFailed     EventType = "workflow.failed"
 	EventWorkflowRollback   EventType = "workflow.rollback"
 
 	// RCA (Root Cause Analysis) events
@@ -0,0 +1,59 @@
+package pipeline
+
+import "time"
+
+// Job is a command left running in the background, outside the blocks,
+// until its output is brought back as a block.
+type Job struct {
+	ID         string
+	Command    string
+	Started    time.Time
+	Running    bool
+	Output     string
+	ExitCode   int
+	Duration   time.Duration
+	WorkingDir string
+}
+
+// AddJob tracks a new background job.
+func (s *StateStore) AddJob(job Job) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.jobs = append(s.jobs, job)
+}
+
+func (s *StateStore) UpdateJob(id string, fn func(*Job)) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for i := range s.jobs {
+		if s.jobs[i].ID == id {
+			fn(&s.jobs[i])
+			return
+		}
+	}
+}
+
+// GetJobs lists the background jobs, oldest first.
+func (s *StateStore) GetJobs() []Job {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	result := make([]Job, len(s.jobs))
+	copy(result, s.jobs)
+	return result
+}
+
+// RemoveJob stops tracking a job, returning it.
+func (s *StateStore) RemoveJob(id string) (Job, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for i, job := range s.jobs {
+		if job.ID == id {
+			s.jobs = append(s.jobs[:i], s.jobs[i+1:]...)
+			return job, true
+		}
+	}
+	return Job{}, false
+}
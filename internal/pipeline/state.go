@@ -1,6 +1,7 @@
 package pipeline
 
 import (
+	"slices"
 	"sync"
 	"time"
 
@@ -26,9 +27,19 @@ type Block struct {
 	ExitCode  int
 	Duration  time.Duration
 	Folded    bool
+	// Running is set while the command is still executing; Output then holds
+	// what it has printed so far.
+	Running bool
+	// Pinned keeps the block through Ctrl+L and the MaxBlocks limit;
+	// BookmarkID is its stored bookmark, 0 until saved.
+	Pinned     bool
+	BookmarkID int64
 
 	AISuggestion string
 	AIAnalyzed   bool
+	// AIModel names the model that produced the AI output, which may be the
+	// fallback model rather than the configured one.
+	AIModel string
 
 	WorkingDir string
 }
@@ -42,6 +53,31 @@ type Suggestion struct {
 	Confidence  float64
 }
 
+type AnnotationSeverity string
+
+const (
+	SeverityInfo    AnnotationSeverity = "info"
+	SeveritySuccess AnnotationSeverity = "success"
+	SeverityWarning AnnotationSeverity = "warning"
+	SeverityError   AnnotationSeverity = "error"
+)
+
+// BlockAnnotation is a structured note a plugin attaches to a block. The agent
+// view renders annotations generically, so plugins can surface hints and
+// runnable actions without any view changes.
+type BlockAnnotation struct {
+	BlockID  string
+	Source   string
+	Type     string
+	Severity AnnotationSeverity
+	Text     string
+	Command  string
+	// Key, when set, runs Command with that key on the selected block,
+	// labelled Action, so a block can offer several one-key fixes.
+	Key    string
+	Action string
+}
+
 type StateStore struct {
 	mu sync.RWMutex
 
@@ -53,11 +89,15 @@ type StateStore struct {
 	DockerHealth infra.DockerHealth
 	GPUStats     infra.GPUStats
 	StarshipLine string
+	availability map[Subsystem]bool
 
 	Suggestions   []Suggestion
+	annotations   map[string][]BlockAnnotation
 	LastError     *Block
 	ErrorPatterns map[string]string
 
+	jobs []Job
+
 	Cwd       string
 	Shell     string
 	IsLoading bool
@@ -70,7 +110,9 @@ func NewStateStore() *StateStore {
 		SelectedIdx:   -1,
 		MaxBlocks:     100,
 		Suggestions:   make([]Suggestion, 0),
+		annotations:   make(map[string][]BlockAnnotation),
 		ErrorPatterns: make(map[string]string),
+		availability:  make(map[Subsystem]bool),
 	}
 }
 
@@ -79,9 +121,15 @@ func (s *StateStore) AddBlock(block Block) {
 	defer s.mu.Unlock()
 
 	if len(s.Blocks) >= s.MaxBlocks {
-		oldest := s.Blocks[0]
+		// The oldest block goes, unless it is pinned.
+		i := slices.IndexFunc(s.Blocks, func(b Block) bool { return !b.Pinned })
+		if i < 0 {
+			i = 0
+		}
+		oldest := s.Blocks[i]
 		delete(s.blockIndex, oldest.ID)
-		s.Blocks = s.Blocks[1:]
+		delete(s.annotations, oldest.ID)
+		s.Blocks = slices.Delete(s.Blocks, i, i+1)
 
 		s.rebuildIndex()
 	}
@@ -158,14 +206,90 @@ func (s *StateStore) GetSuggestionsForBlock(blockID string) []Suggestion {
 	return result
 }
 
+const maxAnnotationsPerBlock = 5
+
+func (s *StateStore) AddAnnotation(a BlockAnnotation) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.blockIndex[a.BlockID]; !ok {
+		return
+	}
+	if a.Severity == "" {
+		a.Severity = SeverityInfo
+	}
+
+	list := append(s.annotations[a.BlockID], a)
+	if len(list) > maxAnnotationsPerBlock {
+		list = list[len(list)-maxAnnotationsPerBlock:]
+	}
+	s.annotations[a.BlockID] = list
+}
+
+func (s *StateStore) GetAnnotationsForBlock(blockID string) []BlockAnnotation {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	list := s.annotations[blockID]
+	if len(list) == 0 {
+		return nil
+	}
+	result := make([]BlockAnnotation, len(list))
+	copy(result, list)
+	return result
+}
+
+// ClearAnnotations removes annotations on a block. An empty source clears all
+// of them; otherwise only those added by that source are removed.
+func (s *StateStore) ClearAnnotations(blockID, source string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if source == "" {
+		delete(s.annotations, blockID)
+		return
+	}
+
+	kept := s.annotations[blockID][:0]
+	for _, a := range s.annotations[blockID] {
+		if a.Source != source {
+			kept = append(kept, a)
+		}
+	}
+	if len(kept) == 0 {
+		delete(s.annotations, blockID)
+		return
+	}
+	s.annotations[blockID] = kept
+}
+
+// ClearBlocks drops every block but the pinned ones.
 func (s *StateStore) ClearBlocks() {
 	s.mu.Lock()
 	defer s.mu.Unlock()
-	s.Blocks = make([]Block, 0)
-	s.blockIndex = make(map[string]int)
+	kept := make([]Block, 0)
+	annotations := make(map[string][]BlockAnnotation)
+	for _, b := range s.Blocks {
+		if b.Pinned {
+			kept = append(kept, b)
+			annotations[b.ID] = s.annotations[b.ID]
+		}
+	}
+	s.Blocks = kept
+	s.annotations = annotations
+	s.rebuildIndex()
 	s.SelectedIdx = -1
 }
 
+// PrependBlocks puts blocks ahead of the current ones, e.g. those kept from
+// an earlier session.
+func (s *StateStore) PrependBlocks(blocks ...Block) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.Blocks = append(slices.Clone(blocks), s.Blocks...)
+	s.rebuildIndex()
+}
+
 func (s *StateStore) rebuildIndex() {
 	s.blockIndex = make(map[string]int, len(s.Blocks))
 	for i, block := range s.Blocks {
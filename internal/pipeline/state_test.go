@@ -119,6 +119,25 @@ func TestStateStore_MaxBlocks(t *testing.T) {
 	}
 }
 
+func TestStateStore_PinnedBlocks(t *testing.T) {
+	store := NewStateStore()
+	store.MaxBlocks = 3
+
+	store.AddBlock(Block{ID: "pinned", Pinned: true})
+	for i := 0; i < 5; i++ {
+		store.AddBlock(Block{ID: string(rune('0' + i))})
+	}
+	if store.GetBlock("pinned") == nil || store.GetBlock("2") != nil || store.GetBlock("4") == nil {
+		t.Errorf("expected the oldest unpinned blocks evicted, got %+v", store.Blocks)
+	}
+
+	store.PrependBlocks(Block{ID: "old", Pinned: true})
+	store.ClearBlocks()
+	if len(store.Blocks) != 2 || store.Blocks[0].ID != "old" || store.GetBlock("pinned") == nil {
+		t.Errorf("expected ClearBlocks to keep the pinned blocks in order, got %+v", store.Blocks)
+	}
+}
+
 func TestStateStore_AddSuggestion(t *testing.T) {
 	store := NewStateStore()
 
@@ -178,6 +197,55 @@ func TestStateStore_ClearBlocks(t *testing.T) {
 	}
 }
 
+func TestStateStore_Annotations(t *testing.T) {
+	store := NewStateStore()
+	store.AddBlock(Block{ID: "1"})
+
+	store.AddAnnotation(BlockAnnotation{BlockID: "1", Source: "ai", Type: "fix", Text: "install it", Command: "npm install"})
+	store.AddAnnotation(BlockAnnotation{BlockID: "1", Source: "lint", Type: "hint", Severity: SeverityWarning, Text: "slow"})
+	store.AddAnnotation(BlockAnnotation{BlockID: "missing", Text: "dropped"})
+
+	annotations := store.GetAnnotationsForBlock("1")
+	if len(annotations) != 2 {
+		t.Fatalf("expected 2 annotations, got %d", len(annotations))
+	}
+	if annotations[0].Severity != SeverityInfo {
+		t.Errorf("expected default severity info, got %s", annotations[0].Severity)
+	}
+	if len(store.GetAnnotationsForBlock("missing")) != 0 {
+		t.Error("annotations for unknown blocks should be dropped")
+	}
+
+	store.ClearAnnotations("1", "ai")
+	annotations = store.GetAnnotationsForBlock("1")
+	if len(annotations) != 1 || annotations[0].Source != "lint" {
+		t.Errorf("expected only lint annotation to remain, got %+v", annotations)
+	}
+
+	store.ClearBlocks()
+	store.AddBlock(Block{ID: "1"})
+	if len(store.GetAnnotationsForBlock("1")) != 0 {
+		t.Error("ClearBlocks should drop annotations")
+	}
+}
+
+func TestStateStore_AnnotationLimit(t *testing.T) {
+	store := NewStateStore()
+	store.AddBlock(Block{ID: "1"})
+
+	for i := 0; i < maxAnnotationsPerBlock+3; i++ {
+		store.AddAnnotation(BlockAnnotation{BlockID: "1", Text: string(rune('a' + i))})
+	}
+
+	annotations := store.GetAnnotationsForBlock("1")
+	if len(annotations) != maxAnnotationsPerBlock {
+		t.Fatalf("expected %d annotations, got %d", maxAnnotationsPerBlock, len(annotations))
+	}
+	if annotations[0].Text != "d" {
+		t.Errorf("oldest annotations should be dropped first, got %s", annotations[0].Text)
+	}
+}
+
 func TestStateStore_LastError(t *testing.T) {
 	store := NewStateStore()
 
@@ -323,3 +391,29 @@ func TestRebuildIndex(t *testing.T) {
 		}
 	}
 }
+
+func TestAvailability(t *testing.T) {
+	store := NewStateStore()
+
+	docker := store.Availability(SubsystemDocker)
+	if docker.Checked || docker.Missing() {
+		t.Error("unprobed subsystem should not be reported missing")
+	}
+	if store.Degraded() {
+		t.Error("store should not be degraded before any probe")
+	}
+
+	store.SetAvailable(SubsystemDocker, false)
+	store.SetAvailable(SubsystemOllama, true)
+
+	docker = store.Availability(SubsystemDocker)
+	if !docker.Missing() || docker.Hint == "" {
+		t.Errorf("expected missing docker with a setup hint, got %+v", docker)
+	}
+	if store.Availability(SubsystemOllama).Missing() {
+		t.Error("ollama should be available")
+	}
+	if !store.Degraded() {
+		t.Error("store should be degraded with docker missing")
+	}
+}
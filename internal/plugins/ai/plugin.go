@@ -2,23 +2,54 @@ package ai
 
 import (
 	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"os/exec"
 	"strings"
+	"sync"
 	"time"
 
 	"dev-cli/internal/llm"
 	"dev-cli/internal/pipeline"
+	"dev-cli/internal/rag"
+	"dev-cli/internal/storage"
+	"dev-cli/internal/tools"
+)
+
+const (
+	// maxExplanations bounds the per-block explanation cache.
+	maxExplanations = 32
+	// maxDocSnippets is how many documentation chunks a question gets.
+	maxDocSnippets = 3
 )
 
 type Plugin struct {
 	bus      *pipeline.EventBus
 	state    *pipeline.StateStore
-	client   *llm.HybridClient
+	client   llm.LLMProvider
 	patterns map[string]string
+
+	mu           sync.Mutex
+	explanations map[string]*explanation
+	explainOrder []string
+	// db stores project fingerprints once history is open; until then
+	// fingerprints are detected but not persisted, and failures aren't
+	// compared with the project's past ones.
+	db *sql.DB
+}
+
+// explanation is a cached (or still in-flight) Explain call for a block.
+type explanation struct {
+	done   chan struct{}
+	result *llm.ExplainResult
+	err    error
 }
 
-func New(client *llm.HybridClient) *Plugin {
+func New(client llm.LLMProvider) *Plugin {
 	return &Plugin{
-		client: client,
+		client:       client,
+		explanations: make(map[string]*explanation),
 		patterns: map[string]string{
 			"command not found":         "Check if the command is installed or if it's an alias",
 			"permission denied":         "Try with sudo or check file permissions",
@@ -46,6 +77,70 @@ func (p *Plugin) Init(bus *pipeline.EventBus, state *pipeline.StateStore) error
 	return nil
 }
 
+// SetDB gives the plugin the history database for project fingerprints
+// and the project's past failures.
+func (p *Plugin) SetDB(db *sql.DB) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.db = db
+}
+
+// fingerprint returns the project containing dir (the cwd when empty), or
+// nil outside any project.
+func (p *Plugin) fingerprint(dir string) *storage.ProjectFingerprint {
+	if dir == "" {
+		dir, _ = p.state.GetContext()["cwd"].(string)
+	}
+	if db := p.database(); db != nil {
+		fp, _ := storage.LoadProjectFingerprint(db, dir)
+		return fp
+	}
+	return storage.DetectProjectFingerprint(dir)
+}
+
+func (p *Plugin) database() *sql.DB {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.db
+}
+
+func projectContext(fp *storage.ProjectFingerprint) llm.ProjectContext {
+	return llm.ProjectContext{
+		Type:           fp.ProjectType,
+		PackageManager: fp.PackageManager,
+		Files:          fp.DetectedFiles,
+	}
+}
+
+// clientFor returns the AI client primed with the fingerprint of the project
+// containing dir, so answers match its toolchain.
+func (p *Plugin) clientFor(dir string) llm.LLMProvider {
+	fp := p.fingerprint(dir)
+	if fp == nil {
+		return p.client
+	}
+	return p.client.WithProject(projectContext(fp))
+}
+
+// relevantDocs retrieves the project documentation most related to query.
+// Retrieval needs the history database and Ollama; without either, or on
+// any error, the answer simply goes without docs.
+func (p *Plugin) relevantDocs(root, query string) []string {
+	db := p.database()
+	if db == nil || p.state.Availability(pipeline.SubsystemOllama).Missing() {
+		return nil
+	}
+	chunks, err := rag.Search(db, root, query, p.client, maxDocSnippets)
+	if err != nil {
+		return nil
+	}
+	docs := make([]string, len(chunks))
+	for i, c := range chunks {
+		docs[i] = c.Path + ": " + c.Content
+	}
+	return docs
+}
+
 func (p *Plugin) Start(ctx context.Context) error {
 	return nil
 }
@@ -60,28 +155,177 @@ func (p *Plugin) handleCommandError(event pipeline.Event) {
 		return
 	}
 
-	suggestion := p.matchPattern(block.Output)
+	if s := p.knownSolution(block); s != nil {
+		text := fmt.Sprintf("Fixed this error %d of %d times: %s", s.Successes, s.Successes+s.Failures, s.Command)
+		p.suggest(block, "Known Fix", text, s.Command, s.Score(time.Now()))
+	}
+	if suggestion := p.matchPattern(block.Output); suggestion != "" {
+		p.suggest(block, "Quick Fix", suggestion, "", 0.8)
+	}
+	if hint := p.projectHint(block); hint != "" {
+		p.suggest(block, "Seen Before", hint, "", 0.6)
+	}
 
-	if suggestion != "" {
-		p.state.AddSuggestion(pipeline.Suggestion{
-			ForBlockID:  block.ID,
-			Type:        "fix",
-			Title:       "Quick Fix",
-			Explanation: suggestion,
-			Confidence:  0.8,
-		})
+	// 130 is Ctrl-C; nothing to explain about an interrupted command.
+	if block.ExitCode != 130 {
+		p.prefetchExplanation(block)
+	}
+}
+
+// suggest attaches a fix suggestion to a failed block and announces it;
+// command, when set, is what the Agent's fix key runs.
+func (p *Plugin) suggest(block pipeline.Block, title, text, command string, confidence float64) {
+	p.state.AddSuggestion(pipeline.Suggestion{
+		ForBlockID:  block.ID,
+		Type:        "fix",
+		Title:       title,
+		Command:     command,
+		Explanation: text,
+		Confidence:  confidence,
+	})
+
+	p.state.AddAnnotation(pipeline.BlockAnnotation{
+		BlockID:  block.ID,
+		Source:   p.Name(),
+		Type:     "fix",
+		Severity: pipeline.SeverityWarning,
+		Text:     text,
+	})
+
+	p.bus.Publish(pipeline.Event{
+		Type:      pipeline.EventAISuggestion,
+		Timestamp: time.Now(),
+		Source:    p.Name(),
+		BlockID:   block.ID,
+		Data: map[string]string{
+			"suggestion": text,
+		},
+	})
+}
+
+// knownSolution is the best ranked fix run for the block's error before,
+// if it worked more often than not.
+func (p *Plugin) knownSolution(block pipeline.Block) *storage.Solution {
+	db := p.database()
+	if db == nil || block.ExitCode == 130 {
+		return nil
+	}
+	signature := storage.GenerateErrorSignature(block.Command, block.ExitCode, block.Output)
+	solutions, err := storage.GetSolutionsForError(db, signature, 1)
+	if err != nil || len(solutions) == 0 {
+		return nil
+	}
+	if s := solutions[0]; s.Successes > 0 && s.Score(time.Now()) > 0.5 {
+		return &s
+	}
+	return nil
+}
+
+// projectHint recalls how the block's command failed before in the same
+// project (its git root and below) and what fixed it then. Failures in
+// other projects are left out: the same command there usually fails for
+// other reasons.
+func (p *Plugin) projectHint(block pipeline.Block) string {
+	db := p.database()
+	if db == nil || block.WorkingDir == "" || block.ExitCode == 130 {
+		return ""
+	}
+	h, err := storage.ProjectFailures(db, storage.ProjectRoot(block.WorkingDir), block.Command, block.Timestamp)
+	switch {
+	case err != nil:
+		return ""
+	case h.Fix != "":
+		return fmt.Sprintf("Failed here before and was fixed with: %s", h.Fix)
+	case h.Failures > 1:
+		return fmt.Sprintf("Failed %d times before in this project", h.Failures)
+	}
+	return ""
+}
+
+// prefetchExplanation starts the Explain call for a failed block in the
+// background so @fix can answer from cache instead of waiting on the model.
+// It returns nil when Ollama is known to be down.
+func (p *Plugin) prefetchExplanation(block pipeline.Block) *explanation {
+	if p.client == nil || p.state.Availability(pipeline.SubsystemOllama).Missing() {
+		return nil
+	}
+
+	p.mu.Lock()
+	if e, ok := p.explanations[block.ID]; ok {
+		p.mu.Unlock()
+		return e
+	}
+	e := &explanation{done: make(chan struct{})}
+	p.explanations[block.ID] = e
+	p.explainOrder = append(p.explainOrder, block.ID)
+	if len(p.explainOrder) > maxExplanations {
+		delete(p.explanations, p.explainOrder[0])
+		p.explainOrder = p.explainOrder[1:]
+	}
+	p.mu.Unlock()
 
+	go func() {
+		e.result, e.err = p.clientFor(block.WorkingDir).Explain(block.Command, block.ExitCode, block.Output)
+		close(e.done)
+		if e.err != nil {
+			return
+		}
 		p.bus.Publish(pipeline.Event{
-			Type:      pipeline.EventAISuggestion,
+			Type:      pipeline.EventAIAnalysis,
 			Timestamp: time.Now(),
 			Source:    p.Name(),
 			BlockID:   block.ID,
-			Data: map[string]string{
-				"suggestion": suggestion,
-			},
+			Data:      e.result,
 		})
+	}()
+	return e
+}
+
+// Explain returns the explanation for a block, joining a prefetch that is
+// already in flight rather than issuing a second request. Failed calls are
+// evicted so the next attempt retries.
+func (p *Plugin) Explain(block pipeline.Block) (*llm.ExplainResult, error) {
+	if p.client == nil {
+		return nil, fmt.Errorf("AI client not available")
 	}
 
+	e := p.prefetchExplanation(block)
+	if e == nil {
+		return nil, errors.New(p.state.Availability(pipeline.SubsystemOllama).Hint)
+	}
+	<-e.done
+	if e.err != nil {
+		p.mu.Lock()
+		if p.explanations[block.ID] == e {
+			delete(p.explanations, block.ID)
+		}
+		p.mu.Unlock()
+	}
+	return e.result, e.err
+}
+
+// ExplainBlock renders the (possibly prefetched) explanation for target into
+// the given AI block and offers the suggested fix as a runnable command.
+func (p *Plugin) ExplainBlock(blockID string, target pipeline.Block) (string, error) {
+	result, err := p.Explain(target)
+	if err != nil {
+		p.state.UpdateBlock(blockID, func(b *pipeline.Block) {
+			b.Output = "Explain failed: " + err.Error()
+		})
+		return "", err
+	}
+
+	out := result.Explanation
+	p.state.UpdateBlock(blockID, func(b *pipeline.Block) {
+		b.Output = out
+		b.AISuggestion = result.Fix
+		b.AIAnalyzed = true
+		b.AIModel = result.Model
+	})
+	p.state.UpdateBlock(target.ID, func(b *pipeline.Block) {
+		b.AIAnalyzed = true
+	})
+	return out, nil
 }
 
 func (p *Plugin) matchPattern(output string) string {
@@ -100,7 +344,7 @@ func (p *Plugin) AnalyzeError(block pipeline.Block) (*pipeline.Suggestion, error
 		return nil, nil
 	}
 
-	result, err := p.client.Research(
+	result, err := p.clientFor(block.WorkingDir).Research(
 		"Fix this command error: " + block.Command + "\n\nError: " + block.Output,
 	)
 	if err != nil {
@@ -122,6 +366,14 @@ func (p *Plugin) AnalyzeError(block pipeline.Block) (*pipeline.Suggestion, error
 	}
 
 	p.state.AddSuggestion(*suggestion)
+	p.state.AddAnnotation(pipeline.BlockAnnotation{
+		BlockID:  block.ID,
+		Source:   p.Name(),
+		Type:     "fix",
+		Severity: pipeline.SeverityWarning,
+		Text:     suggestion.Title,
+		Command:  fix,
+	})
 	return suggestion, nil
 }
 
@@ -133,11 +385,26 @@ func (p *Plugin) AnswerQuery(query string, blockID string) (string, error) {
 	context := p.state.GetContext()
 
 	enrichedQuery := query
-	if context["git_branch"] != "" {
-		enrichedQuery += " (in git repo: " + context["git_branch"].(string) + ")"
+	if branch, ok := context["git_branch"].(string); ok && branch != "" {
+		enrichedQuery += " (in git repo: " + branch + ")"
+	}
+
+	// Cloud answers stream in as raw JSON; show progress rather than the
+	// half-parsed text until the result is complete.
+	received := 0
+	client := p.client
+	if fp := p.fingerprint(""); fp != nil {
+		project := projectContext(fp)
+		project.Docs = p.relevantDocs(fp.DetectedAt, query)
+		client = client.WithProject(project)
 	}
 
-	result, err := p.client.Research(enrichedQuery)
+	result, err := client.ResearchStream(enrichedQuery, func(delta string) {
+		received += len(delta)
+		p.state.UpdateBlock(blockID, func(b *pipeline.Block) {
+			b.Output = fmt.Sprintf("Researching... (%d chars received)", received)
+		})
+	})
 	if err != nil {
 		return "", err
 	}
@@ -153,12 +420,179 @@ func (p *Plugin) AnswerQuery(query string, blockID string) (string, error) {
 				response.WriteString(step.Content + "\n")
 			}
 		}
+		if strings.HasPrefix(sol.Source, "http") {
+			response.WriteString("Source: " + sol.Source + "\n")
+		}
 		response.WriteString("\n")
 	}
+	if len(result.Citations) > 0 {
+		response.WriteString("Sources:\n")
+		for i, url := range result.Citations {
+			fmt.Fprintf(&response, "[%d] %s\n", i+1, url)
+		}
+	}
 
 	p.state.UpdateBlock(blockID, func(b *pipeline.Block) {
 		b.Output = response.String()
+		b.AIModel = result.Model
 	})
 
 	return response.String(), nil
 }
+
+// AnalyzeContainerLogs renders an analysis of an unhealthy container's
+// recent logs into the given AI block, offering the suggested fix.
+func (p *Plugin) AnalyzeContainerLogs(blockID, name string, lines []string) (string, error) {
+	if p.client == nil {
+		return "AI client not available", nil
+	}
+	if len(lines) == 0 {
+		p.state.UpdateBlock(blockID, func(b *pipeline.Block) {
+			b.Output = "No logs from " + name + " to analyze"
+		})
+		return "", nil
+	}
+
+	result, err := p.client.AnalyzeLog(strings.Join(lines, "\n"), "")
+	if err != nil {
+		p.state.UpdateBlock(blockID, func(b *pipeline.Block) {
+			b.Output = "Log analysis failed: " + err.Error()
+		})
+		return "", err
+	}
+
+	p.state.UpdateBlock(blockID, func(b *pipeline.Block) {
+		b.Output = result.Explanation
+		b.AISuggestion = result.Fix
+		b.AIAnalyzed = true
+		b.AIModel = result.Model
+	})
+	return result.Explanation, nil
+}
+
+// DraftCommitMessage fills the given AI block with a commit message for the
+// staged diff in the current directory and attaches a runnable commit action.
+func (p *Plugin) DraftCommitMessage(blockID string) (string, error) {
+	if p.client == nil {
+		return "AI client not available", nil
+	}
+
+	gitDiff := exec.Command("git", "diff", "--cached")
+	gitDiff.Dir = p.state.Cwd
+	out, err := gitDiff.Output()
+	if err != nil {
+		p.state.UpdateBlock(blockID, func(b *pipeline.Block) {
+			b.Output = "Not a git repository or git unavailable"
+		})
+		return "", fmt.Errorf("read staged diff: %w", err)
+	}
+	if strings.TrimSpace(string(out)) == "" {
+		p.state.UpdateBlock(blockID, func(b *pipeline.Block) {
+			b.Output = "Nothing staged to commit (use git add first)"
+		})
+		return "", nil
+	}
+
+	msg, err := p.client.CommitMessage(string(out))
+	if err != nil {
+		p.state.UpdateBlock(blockID, func(b *pipeline.Block) {
+			b.Output = "Failed to draft commit message: " + err.Error()
+		})
+		return "", err
+	}
+
+	p.state.UpdateBlock(blockID, func(b *pipeline.Block) {
+		b.Output = msg
+	})
+
+	commitCmd := "git commit"
+	for _, para := range strings.Split(msg, "\n\n") {
+		if para = strings.TrimSpace(para); para != "" {
+			commitCmd += " -m " + shellQuote(para)
+		}
+	}
+	p.state.AddAnnotation(pipeline.BlockAnnotation{
+		BlockID:  blockID,
+		Source:   p.Name(),
+		Type:     "commit",
+		Severity: pipeline.SeverityInfo,
+		Text:     "Commit with this message",
+		Command:  commitCmd,
+	})
+
+	return msg, nil
+}
+
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
+// ReviewChanges reviews the diff against ref (or the staged/uncommitted
+// changes when ref is empty) and renders the findings into the given block.
+func (p *Plugin) ReviewChanges(blockID, ref string) (string, error) {
+	if p.client == nil {
+		return "AI client not available", nil
+	}
+
+	diff, source, err := tools.ReviewDiff(p.state.Cwd, ref)
+	if err != nil {
+		p.state.UpdateBlock(blockID, func(b *pipeline.Block) {
+			b.Output = "Could not read diff: " + err.Error()
+		})
+		return "", err
+	}
+	if strings.TrimSpace(diff) == "" {
+		p.state.UpdateBlock(blockID, func(b *pipeline.Block) {
+			b.Output = "Nothing to review"
+		})
+		return "", nil
+	}
+
+	result, err := p.client.ReviewDiff(diff)
+	if err != nil {
+		p.state.UpdateBlock(blockID, func(b *pipeline.Block) {
+			b.Output = "Review failed: " + err.Error()
+		})
+		return "", err
+	}
+
+	var out strings.Builder
+	out.WriteString("Review of " + source + "\n")
+	if result.Summary != "" {
+		out.WriteString(result.Summary + "\n")
+	}
+	if result.Truncated {
+		out.WriteString("The diff was too long; only its start and end were reviewed.\n")
+	}
+	if len(result.Findings) == 0 {
+		out.WriteString("\nNo findings")
+	}
+	for _, f := range result.Findings {
+		loc := f.File
+		if f.Line > 0 {
+			loc = fmt.Sprintf("%s:%d", f.File, f.Line)
+		}
+		out.WriteString(fmt.Sprintf("\n[%s] %s\n  %s", f.Severity, loc, f.Suggestion))
+
+		severity := pipeline.SeverityWarning
+		switch f.Severity {
+		case "error":
+			severity = pipeline.SeverityError
+		case "info":
+			severity = pipeline.SeverityInfo
+		}
+		p.state.AddAnnotation(pipeline.BlockAnnotation{
+			BlockID:  blockID,
+			Source:   p.Name(),
+			Type:     "review",
+			Severity: severity,
+			Text:     loc + ": " + f.Suggestion,
+		})
+	}
+
+	p.state.UpdateBlock(blockID, func(b *pipeline.Block) {
+		b.Output = out.String()
+		b.AIAnalyzed = true
+	})
+	return out.String(), nil
+}
@@ -0,0 +1,182 @@
+package ai
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"dev-cli/internal/llm"
+	"dev-cli/internal/pipeline"
+	"dev-cli/internal/storage"
+)
+
+func newTestPlugin(t *testing.T, client *llm.FakeProvider) (*Plugin, *pipeline.EventBus) {
+	t.Helper()
+	p := New(client)
+	bus := pipeline.NewEventBus()
+	if err := p.Init(bus, pipeline.NewStateStore()); err != nil {
+		t.Fatalf("Init failed: %v", err)
+	}
+	return p, bus
+}
+
+func countCalls(f *llm.FakeProvider, name string) int {
+	return f.CallCount(name)
+}
+
+func TestExplain_UsesPrefetch(t *testing.T) {
+	fake := llm.NewFakeProvider()
+	p, bus := newTestPlugin(t, fake)
+
+	analyzed := make(chan struct{}, 1)
+	bus.Subscribe(pipeline.EventAIAnalysis, func(pipeline.Event) { analyzed <- struct{}{} })
+
+	failed := pipeline.Block{ID: "b1", Command: "npm start", ExitCode: 1, Output: "missing script"}
+	bus.Publish(pipeline.Event{Type: pipeline.EventCommandError, BlockID: failed.ID, Data: failed})
+
+	select {
+	case <-analyzed:
+	case <-time.After(2 * time.Second):
+		t.Fatal("prefetch did not complete")
+	}
+
+	result, err := p.Explain(failed)
+	if err != nil {
+		t.Fatalf("Explain failed: %v", err)
+	}
+	if result.Fix != "echo fixed" {
+		t.Errorf("unexpected fix %q", result.Fix)
+	}
+	if n := countCalls(fake, "Explain"); n != 1 {
+		t.Errorf("expected a single Explain call, got %d", n)
+	}
+}
+
+func TestExplain_RetriesAfterError(t *testing.T) {
+	fake := llm.NewFakeProvider()
+	fake.Err = errors.New("ollama down")
+	p, _ := newTestPlugin(t, fake)
+
+	block := pipeline.Block{ID: "b2", Command: "make", ExitCode: 2}
+	if _, err := p.Explain(block); err == nil {
+		t.Fatal("expected error from failing provider")
+	}
+
+	fake.Err = nil
+	if _, err := p.Explain(block); err != nil {
+		t.Fatalf("expected retry to succeed, got %v", err)
+	}
+	if n := countCalls(fake, "Explain"); n != 2 {
+		t.Errorf("expected failed explanation to be evicted, got %d calls", n)
+	}
+}
+
+func TestPrefetch_SkipsInterrupted(t *testing.T) {
+	fake := llm.NewFakeProvider()
+	_, bus := newTestPlugin(t, fake)
+
+	block := pipeline.Block{ID: "b3", Command: "sleep 100", ExitCode: 130}
+	bus.Publish(pipeline.Event{Type: pipeline.EventCommandError, BlockID: block.ID, Data: block})
+
+	if n := countCalls(fake, "Explain"); n != 0 {
+		t.Errorf("expected no prefetch for Ctrl-C, got %d calls", n)
+	}
+}
+
+func TestAnswerQuery_ListsSources(t *testing.T) {
+	fake := llm.NewFakeProvider()
+	fake.Answers["install foo"] = &llm.ResearchResult{
+		Query:     "install foo",
+		Solutions: []llm.Solution{{ID: 1, Title: "Installer", Source: "https://example.com/install"}},
+		Citations: []string{"https://example.com/install"},
+	}
+	p, _ := newTestPlugin(t, fake)
+
+	out, err := p.AnswerQuery("install foo", "ai-1")
+	if err != nil {
+		t.Fatalf("AnswerQuery failed: %v", err)
+	}
+	if !strings.Contains(out, "Source: https://example.com/install") || !strings.Contains(out, "[1] https://example.com/install") {
+		t.Errorf("expected sources in answer, got:\n%s", out)
+	}
+}
+
+func TestExplain_UsesProjectFingerprint(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "go.mod"), []byte("module x\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	fake := llm.NewFakeProvider()
+	p, _ := newTestPlugin(t, fake)
+
+	block := pipeline.Block{ID: "b4", Command: "go build", ExitCode: 1, WorkingDir: dir}
+	if _, err := p.Explain(block); err != nil {
+		t.Fatalf("Explain failed: %v", err)
+	}
+	if fake.Project.Type != "go" || fake.Project.PackageManager != "go mod" {
+		t.Errorf("expected go project context, got %+v", fake.Project)
+	}
+}
+
+func TestCommandError_RecallsProjectFix(t *testing.T) {
+	db, err := storage.OpenDB(filepath.Join(t.TempDir(), "history.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	root := t.TempDir()
+	os.Mkdir(filepath.Join(root, ".git"), 0755)
+	sub := filepath.Join(root, "web")
+	os.Mkdir(sub, 0755)
+
+	earlier := time.Now().Add(-time.Hour).Format(time.RFC3339)
+	for _, e := range []storage.LogEntry{
+		{Command: "npm test", ExitCode: 1, Cwd: root, SessionID: "s1", Timestamp: earlier},
+		{Command: "npm ci", Cwd: root, SessionID: "s1", Timestamp: earlier},
+		{Command: "npm test", ExitCode: 1, Cwd: t.TempDir(), SessionID: "s2", Timestamp: earlier},
+	} {
+		if err := storage.SaveCommand(db, e); err != nil {
+			t.Fatal(err)
+		}
+	}
+	storage.MarkResolution(db, 1, "solution")
+
+	p, bus := newTestPlugin(t, llm.NewFakeProvider())
+	p.SetDB(db)
+	block := pipeline.Block{ID: "b5", Command: "npm test", ExitCode: 1, WorkingDir: sub, Timestamp: time.Now()}
+	bus.Publish(pipeline.Event{Type: pipeline.EventCommandError, BlockID: block.ID, Data: block})
+
+	var hints []string
+	for _, s := range p.state.GetSuggestionsForBlock(block.ID) {
+		hints = append(hints, s.Explanation)
+	}
+	if len(hints) != 1 || !strings.Contains(hints[0], "npm ci") {
+		t.Errorf("expected the project's earlier fix to be suggested, got %q", hints)
+	}
+}
+
+func TestCommandError_SuggestsKnownFix(t *testing.T) {
+	db, err := storage.OpenDB(filepath.Join(t.TempDir(), "history.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	block := pipeline.Block{ID: "b6", Command: "npm start", ExitCode: 1, Output: "Error: Cannot find module 'express'"}
+	sig := storage.GenerateErrorSignature(block.Command, block.ExitCode, block.Output)
+	storage.IncrementSolutionFailure(db, sig, "npm rebuild")
+	storage.IncrementSolutionSuccess(db, sig, "npm ci")
+	storage.IncrementSolutionSuccess(db, sig, "npm ci")
+
+	p, bus := newTestPlugin(t, llm.NewFakeProvider())
+	p.SetDB(db)
+	bus.Publish(pipeline.Event{Type: pipeline.EventCommandError, BlockID: block.ID, Data: block})
+
+	suggestions := p.state.GetSuggestionsForBlock(block.ID)
+	if len(suggestions) == 0 || suggestions[0].Command != "npm ci" || !strings.Contains(suggestions[0].Explanation, "2 of 2") {
+		t.Errorf("expected npm ci offered first, got %+v", suggestions)
+	}
+}
@@ -0,0 +1,86 @@
+package command
+
+import (
+	"context"
+	"time"
+
+	"dev-cli/internal/executor"
+	"dev-cli/internal/pipeline"
+
+	"github.com/google/uuid"
+)
+
+// StartJob runs command in the background as a tracked job and returns its
+// ID right away. Jobs have no timeout, since dev servers and watchers are
+// meant to keep running; Cancel stops them.
+func (p *Plugin) StartJob(command string) string {
+	jobID := uuid.New().String()
+	ctx, cancel := context.WithCancel(context.Background())
+	p.track(jobID, cancel)
+
+	job := pipeline.Job{
+		ID:         jobID,
+		Command:    command,
+		Started:    time.Now(),
+		Running:    true,
+		WorkingDir: p.state.Cwd,
+	}
+	p.state.AddJob(job)
+	p.bus.Publish(pipeline.Event{
+		Type:      pipeline.EventJobStart,
+		Timestamp: job.Started,
+		Source:    p.Name(),
+		Data:      job,
+	})
+
+	go func() {
+		defer cancel()
+		defer p.untrack(jobID)
+
+		stream := p.jobWriter(jobID)
+		result := executor.ExecutePTYStreaming(ctx, command, stream)
+		stream.Close()
+
+		p.state.UpdateJob(jobID, func(j *pipeline.Job) {
+			j.Running = false
+			j.Output = result.Output
+			j.ExitCode = result.ExitCode
+			j.Duration = result.Duration
+			job = *j
+		})
+		p.bus.Publish(pipeline.Event{
+			Type:      pipeline.EventJobDone,
+			Timestamp: time.Now(),
+			Source:    p.Name(),
+			Data:      job,
+		})
+	}()
+
+	return jobID
+}
+
+// CollectJob brings a finished job back as a block, as if it had run in
+// the foreground, and stops tracking it. It reports false for a job that
+// is unknown or still running.
+func (p *Plugin) CollectJob(jobID string) (pipeline.Block, bool) {
+	for _, job := range p.state.GetJobs() {
+		if job.ID != jobID || job.Running {
+			continue
+		}
+		p.state.RemoveJob(jobID)
+
+		block := pipeline.Block{
+			ID:         job.ID,
+			Type:       pipeline.BlockTypeCommand,
+			Timestamp:  job.Started,
+			Command:    job.Command,
+			Output:     job.Output,
+			ExitCode:   job.ExitCode,
+			Duration:   job.Duration,
+			WorkingDir: job.WorkingDir,
+		}
+		p.state.AddBlock(block)
+		return p.finish(block, job.ExitCode == 130), true
+	}
+	return pipeline.Block{}, false
+}
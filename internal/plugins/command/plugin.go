@@ -2,21 +2,37 @@ package command
 
 import (
 	"context"
+	"sync"
 	"time"
 
 	"dev-cli/internal/executor"
+	"dev-cli/internal/infra"
 	"dev-cli/internal/pipeline"
 
 	"github.com/google/uuid"
 )
 
+// commandTimeout is how long a command may run before it is killed.
+const commandTimeout = 60 * time.Second
+
 type Plugin struct {
 	bus   *pipeline.EventBus
 	state *pipeline.StateStore
+	// checkPort finds who holds a port a failed command could not bind.
+	checkPort func(port int) *infra.PortConflict
+
+	mu sync.Mutex
+	// cancels interrupts the commands in flight, by block or job ID.
+	cancels map[string]context.CancelFunc
+	// running counts the commands in flight, for Stop to wait on.
+	running sync.WaitGroup
 }
 
 func New() *Plugin {
-	return &Plugin{}
+	return &Plugin{
+		checkPort: infra.CheckPortAvailable,
+		cancels:   make(map[string]context.CancelFunc),
+	}
 }
 
 func (p *Plugin) Name() string {
@@ -33,7 +49,15 @@ func (p *Plugin) Start(ctx context.Context) error {
 	return nil
 }
 
+// Stop interrupts every command and job still running and waits for them
+// to finish, so none outlives the plugin.
 func (p *Plugin) Stop() error {
+	p.mu.Lock()
+	for _, cancel := range p.cancels {
+		cancel()
+	}
+	p.mu.Unlock()
+	p.running.Wait()
 	return nil
 }
 
@@ -50,10 +74,41 @@ func (p *Plugin) Execute(command string) pipeline.Block {
 		},
 	})
 
-	result := executor.ExecutePTY(command)
+	p.state.AddBlock(pipeline.Block{
+		ID:         blockID,
+		Type:       pipeline.BlockTypeCommand,
+		Timestamp:  time.Now(),
+		Command:    command,
+		Running:    true,
+		WorkingDir: p.state.Cwd,
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), commandTimeout)
+	defer cancel()
+	p.track(blockID, cancel)
+	defer p.untrack(blockID)
+	stream := p.blockWriter(blockID)
+	result := executor.ExecutePTYStreaming(ctx, command, stream)
+	stream.Close()
+
+	var block pipeline.Block
+	p.state.UpdateBlock(blockID, func(b *pipeline.Block) {
+		b.Timestamp = result.Timestamp
+		b.Output = result.Output
+		b.ExitCode = result.ExitCode
+		b.Duration = result.Duration
+		b.Running = false
+		block = *b
+	})
+
+	return p.finish(block, ctx.Err() == context.Canceled)
+}
 
+// Record adds a block for a command that ran elsewhere, such as on the
+// whole terminal, and reports it like one run here.
+func (p *Plugin) Record(result executor.Result) pipeline.Block {
 	block := pipeline.Block{
-		ID:         blockID,
+		ID:         uuid.New().String(),
 		Type:       pipeline.BlockTypeCommand,
 		Timestamp:  result.Timestamp,
 		Command:    result.Command,
@@ -62,26 +117,76 @@ func (p *Plugin) Execute(command string) pipeline.Block {
 		Duration:   result.Duration,
 		WorkingDir: p.state.Cwd,
 	}
-
 	p.state.AddBlock(block)
+	return p.finish(block, false)
+}
 
+// finish reports a block's command as done. A failure is annotated and
+// published for the AI, unless the user interrupted it: then there is
+// nothing to diagnose.
+func (p *Plugin) finish(block pipeline.Block, interrupted bool) pipeline.Block {
 	eventType := pipeline.EventCommandComplete
-	if result.ExitCode != 0 {
+	if block.ExitCode != 0 && !interrupted {
 		eventType = pipeline.EventCommandError
 		block.Type = pipeline.BlockTypeError
+		p.annotatePortConflict(block)
 	}
 
 	p.bus.Publish(pipeline.Event{
 		Type:      eventType,
 		Timestamp: time.Now(),
 		Source:    p.Name(),
-		BlockID:   blockID,
+		BlockID:   block.ID,
 		Data:      block,
 	})
 
 	return block
 }
 
+// track makes the command running under id cancellable, until untrack.
+func (p *Plugin) track(id string, cancel context.CancelFunc) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.cancels[id] = cancel
+	p.running.Add(1)
+}
+
+func (p *Plugin) untrack(id string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	delete(p.cancels, id)
+	p.running.Done()
+}
+
+// Cancel interrupts the command running in the block or job with id,
+// killing its process group. It reports whether such a command was running.
+func (p *Plugin) Cancel(id string) bool {
+	p.mu.Lock()
+	cancel, ok := p.cancels[id]
+	p.mu.Unlock()
+	if ok {
+		cancel()
+	}
+	return ok
+}
+
+// annotatePortConflict offers one-key fixes when block failed because its
+// port was taken.
+func (p *Plugin) annotatePortConflict(block pipeline.Block) {
+	port, ok := conflictPort(block.Command, block.Output)
+	if !ok || p.checkPort == nil {
+		return
+	}
+	conflict := p.checkPort(port)
+	if conflict == nil {
+		return
+	}
+	for _, a := range portConflictAnnotations(block, conflict) {
+		a.Source = p.Name()
+		p.state.AddAnnotation(a)
+	}
+}
+
 func (p *Plugin) ExecuteAI(query string) pipeline.Block {
 	blockID := uuid.New().String()
 
@@ -0,0 +1,131 @@
+package command
+
+import (
+	"testing"
+	"time"
+
+	"dev-cli/internal/pipeline"
+)
+
+func TestExecute_Cancel(t *testing.T) {
+	if testing.Short() {
+		t.Skip("runs a shell")
+	}
+	t.Setenv("SHELL", "/bin/sh")
+
+	bus := pipeline.NewEventBus()
+	state := pipeline.NewStateStore()
+	p := New()
+	if err := p.Init(bus, state); err != nil {
+		t.Fatal(err)
+	}
+	var failed bool
+	bus.Subscribe(pipeline.EventCommandError, func(pipeline.Event) { failed = true })
+
+	started := make(chan string, 1)
+	bus.Subscribe(pipeline.EventCommandStart, func(e pipeline.Event) { started <- e.BlockID })
+	done := make(chan pipeline.Block, 1)
+	start := time.Now()
+	go func() { done <- p.Execute("echo waiting; sleep 30 & wait") }()
+
+	blockID := <-started
+	for state.GetBlock(blockID) == nil {
+		time.Sleep(10 * time.Millisecond)
+	}
+	if !p.Cancel(blockID) {
+		t.Fatal("expected the running command to be cancellable")
+	}
+
+	block := <-done
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("cancel took %v, want the process group killed right away", elapsed)
+	}
+	if block.ExitCode != 130 || block.Running {
+		t.Errorf("block = %+v, want it interrupted with exit code 130", block)
+	}
+	if failed {
+		t.Error("an interrupted command should not be reported as a failure")
+	}
+	if p.Cancel(blockID) {
+		t.Error("expected nothing to cancel once the command ended")
+	}
+}
+
+func TestStartJob(t *testing.T) {
+	if testing.Short() {
+		t.Skip("runs a shell")
+	}
+	t.Setenv("SHELL", "/bin/sh")
+
+	bus := pipeline.NewEventBus()
+	state := pipeline.NewStateStore()
+	p := New()
+	if err := p.Init(bus, state); err != nil {
+		t.Fatal(err)
+	}
+	done := make(chan pipeline.Job, 2)
+	bus.Subscribe(pipeline.EventJobDone, func(e pipeline.Event) { done <- e.Data.(pipeline.Job) })
+
+	slow := p.StartJob("sleep 30")
+	quick := p.StartJob("echo built")
+	if jobs := state.GetJobs(); len(jobs) != 2 || !jobs[0].Running {
+		t.Fatalf("expected two running jobs, got %+v", jobs)
+	}
+	if len(state.GetBlocks()) != 0 {
+		t.Error("a job should not add a block until it is collected")
+	}
+	if _, ok := p.CollectJob(slow); ok {
+		t.Error("expected a running job not to be collectable")
+	}
+
+	if job := <-done; job.ID != quick || job.ExitCode != 0 || job.Output != "built" {
+		t.Errorf("finished job = %+v", job)
+	}
+	block, ok := p.CollectJob(quick)
+	if !ok || block.Command != "echo built" || block.Output != "built" {
+		t.Errorf("collected block = %+v, %v", block, ok)
+	}
+	if jobs := state.GetJobs(); len(jobs) != 1 || jobs[0].ID != slow {
+		t.Errorf("expected only the slow job left, got %+v", jobs)
+	}
+
+	if !p.Cancel(slow) {
+		t.Fatal("expected the slow job to be cancellable")
+	}
+	if job := <-done; job.ID != slow || job.ExitCode != 130 {
+		t.Errorf("stopped job = %+v, want exit code 130", job)
+	}
+}
+
+func TestStop_EndsJobs(t *testing.T) {
+	if testing.Short() {
+		t.Skip("runs a shell")
+	}
+	t.Setenv("SHELL", "/bin/sh")
+
+	state := pipeline.NewStateStore()
+	p := New()
+	if err := p.Init(pipeline.NewEventBus(), state); err != nil {
+		t.Fatal(err)
+	}
+	p.StartJob("sleep 30")
+	p.StartJob("sleep 30")
+
+	stopped := make(chan error)
+	go func() { stopped <- p.Stop() }()
+	select {
+	case err := <-stopped:
+		if err != nil {
+			t.Fatal(err)
+		}
+	case <-time.After(10 * time.Second):
+		t.Fatal("Stop did not return")
+	}
+	// A job cancelled before its shell started fails rather than exiting
+	// 130; either way none may still be running.
+	for _, job := range state.GetJobs() {
+		if job.Running || job.ExitCode == 0 {
+			t.Errorf("expected every job interrupted once Stop returns, got %+v", job)
+		}
+	}
+}
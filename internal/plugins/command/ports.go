@@ -0,0 +1,116 @@
+package command
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"dev-cli/internal/infra"
+	"dev-cli/internal/pipeline"
+)
+
+// bindErrorPattern matches the "port taken" errors of Go, Node, Python and
+// Docker.
+var bindErrorPattern = regexp.MustCompile(`(?i)address already in use|EADDRINUSE|port is already allocated`)
+
+var (
+	// outputPortPattern finds the port in lines like "listen tcp :8080" or
+	// "Bind for 0.0.0.0:5432 failed".
+	outputPortPattern = regexp.MustCompile(`:(\d{2,5})\b`)
+	// commandPortPattern finds a port given on the command line: --port
+	// 8000, -p 8000, PORT=8000 or host:8000.
+	commandPortPattern = regexp.MustCompile(`(?:--port[= ]|-p\s*|PORT=|:)(\d{2,5})\b`)
+)
+
+// conflictPort returns the port a failed command could not bind, from the
+// error line if it names one, else from the command itself.
+func conflictPort(command, output string) (int, bool) {
+	if !bindErrorPattern.MatchString(output) {
+		return 0, false
+	}
+	for _, line := range strings.Split(output, "\n") {
+		if !bindErrorPattern.MatchString(line) && !strings.Contains(line, "Bind for") {
+			continue
+		}
+		if m := outputPortPattern.FindAllStringSubmatch(line, -1); m != nil {
+			if port, ok := validPort(m[len(m)-1][1]); ok {
+				return port, true
+			}
+		}
+	}
+	if m := commandPortPattern.FindStringSubmatch(command); m != nil {
+		return validPort(m[1])
+	}
+	return 0, false
+}
+
+func validPort(s string) (int, bool) {
+	port, err := strconv.Atoi(s)
+	return port, err == nil && port > 0 && port < 65536
+}
+
+// portArgPattern finds where a command says which port to listen on: the
+// host side of a publish spec (-p 8080:80, -p 127.0.0.1:8080:80), a port
+// flag (--port 8000, -p 8000), PORT=8000, or a listen address
+// (0.0.0.0:8000, localhost:8000). The port is the first submatch.
+var portArgPattern = regexp.MustCompile(`(?:(?:^|\s)(?:-p|--publish)(?:=|\s*)(?:\d+\.\d+\.\d+\.\d+:)?|(?:^|\s)--port(?:=|\s+)|\bPORT=|(?:\blocalhost|\b\d+\.\d+\.\d+\.\d+|\[::1?\]):)(\d{2,5})\b`)
+
+// withPort rewrites command to listen on port instead of old, changing only
+// the places portArgPattern finds, so a container port or an unrelated
+// number stays as it is. A command that doesn't give the port gets it
+// through the PORT variable most dev servers read.
+func withPort(command string, old, port int) string {
+	var b strings.Builder
+	last := 0
+	for _, m := range portArgPattern.FindAllStringSubmatchIndex(command, -1) {
+		if n, _ := strconv.Atoi(command[m[2]:m[3]]); n != old {
+			continue
+		}
+		b.WriteString(command[last:m[2]])
+		b.WriteString(strconv.Itoa(port))
+		last = m[3]
+	}
+	if last == 0 {
+		return fmt.Sprintf("PORT=%d %s", port, command)
+	}
+	b.WriteString(command[last:])
+	return b.String()
+}
+
+// portConflictAnnotations offers the ways out of a port conflict: re-run
+// on the suggested free port, or kill the process holding the port.
+func portConflictAnnotations(block pipeline.Block, conflict *infra.PortConflict) []pipeline.BlockAnnotation {
+	owner := "another process"
+	if conflict.Process != "" {
+		owner = conflict.Process
+	}
+	if conflict.PID > 0 {
+		owner += fmt.Sprintf(" (pid %d)", conflict.PID)
+	}
+
+	var annotations []pipeline.BlockAnnotation
+	if conflict.Suggested > 0 {
+		annotations = append(annotations, pipeline.BlockAnnotation{
+			BlockID:  block.ID,
+			Type:     "port-rerun",
+			Severity: pipeline.SeverityWarning,
+			Text:     fmt.Sprintf("Port %d is held by %s; %d is free", conflict.Port, owner, conflict.Suggested),
+			Command:  withPort(block.Command, conflict.Port, conflict.Suggested),
+			Key:      "p",
+			Action:   fmt.Sprintf("run on %d", conflict.Suggested),
+		})
+	}
+	if conflict.PID > 0 {
+		annotations = append(annotations, pipeline.BlockAnnotation{
+			BlockID:  block.ID,
+			Type:     "port-kill",
+			Severity: pipeline.SeverityWarning,
+			Text:     fmt.Sprintf("Free port %d by stopping %s", conflict.Port, owner),
+			Command:  fmt.Sprintf("kill %d", conflict.PID),
+			Key:      "x",
+			Action:   "kill",
+		})
+	}
+	return annotations
+}
@@ -0,0 +1,86 @@
+package command
+
+import (
+	"testing"
+
+	"dev-cli/internal/infra"
+	"dev-cli/internal/pipeline"
+)
+
+func TestConflictPort(t *testing.T) {
+	tests := []struct {
+		command, output string
+		want            int
+	}{
+		{"go run .", "listen tcp :8080: bind: address already in use", 8080},
+		{"npm run dev", "Error: listen EADDRINUSE: address already in use :::3000", 3000},
+		{"docker run -p 5432:5432 postgres", "Bind for 0.0.0.0:5432 failed: port is already allocated", 5432},
+		{"python -m http.server 8000", "OSError: [Errno 98] Address already in use", 0},
+		{"uvicorn app:app --port 8000", "OSError: [Errno 98] Address already in use", 8000},
+		{"go build ./...", "undefined: foo", 0},
+	}
+	for _, tt := range tests {
+		got, ok := conflictPort(tt.command, tt.output)
+		if got != tt.want || ok != (tt.want != 0) {
+			t.Errorf("conflictPort(%q, %q) = %d, %v; want %d", tt.command, tt.output, got, ok, tt.want)
+		}
+	}
+}
+
+func TestWithPort(t *testing.T) {
+	if got := withPort("uvicorn app:app --port 8000", 8000, 8001); got != "uvicorn app:app --port 8001" {
+		t.Errorf("withPort = %q", got)
+	}
+	if got := withPort("npm run dev", 3000, 3001); got != "PORT=3001 npm run dev" {
+		t.Errorf("withPort = %q", got)
+	}
+
+	tests := []struct {
+		command string
+		old     int
+		want    string
+	}{
+		{"docker run -p 5432:5432 postgres", 5432, "docker run -p 5433:5432 postgres"},
+		{"docker run --publish 127.0.0.1:5432:5432 postgres", 5432, "docker run --publish 127.0.0.1:5433:5432 postgres"},
+		{"sleep 5432 && flask run --port=5432", 5432, "sleep 5432 && flask run --port=5433"},
+		{"gunicorn -b 0.0.0.0:5432 app:app", 5432, "gunicorn -b 0.0.0.0:5433 app:app"},
+	}
+	for _, tt := range tests {
+		if got := withPort(tt.command, tt.old, 5433); got != tt.want {
+			t.Errorf("withPort(%q) = %q, want %q", tt.command, got, tt.want)
+		}
+	}
+}
+
+func TestAnnotatePortConflict(t *testing.T) {
+	state := pipeline.NewStateStore()
+	p := New()
+	if err := p.Init(pipeline.NewEventBus(), state); err != nil {
+		t.Fatal(err)
+	}
+	p.checkPort = func(port int) *infra.PortConflict {
+		return &infra.PortConflict{Port: port, Process: "node", PID: 4242, Suggested: port + 1}
+	}
+
+	block := pipeline.Block{ID: "b1", Command: "npm start -- --port 3000", Output: "Error: listen EADDRINUSE: address already in use :::3000"}
+	state.AddBlock(block)
+	p.annotatePortConflict(block)
+
+	annotations := state.GetAnnotationsForBlock("b1")
+	if len(annotations) != 2 {
+		t.Fatalf("expected a re-run and a kill action, got %+v", annotations)
+	}
+	if a := annotations[0]; a.Key != "p" || a.Command != "npm start -- --port 3001" || a.Source != "command" {
+		t.Errorf("re-run annotation = %+v", a)
+	}
+	if a := annotations[1]; a.Key != "x" || a.Command != "kill 4242" {
+		t.Errorf("kill annotation = %+v", a)
+	}
+
+	p.checkPort = func(int) *infra.PortConflict { return nil }
+	state.ClearAnnotations("b1", "")
+	p.annotatePortConflict(block)
+	if got := state.GetAnnotationsForBlock("b1"); len(got) != 0 {
+		t.Errorf("expected no actions once the port is free, got %+v", got)
+	}
+}
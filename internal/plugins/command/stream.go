@@ -0,0 +1,81 @@
+package command
+
+import (
+	"bytes"
+	"sync"
+	"time"
+
+	"dev-cli/internal/executor"
+	"dev-cli/internal/pipeline"
+)
+
+// outputInterval is how often streamed output is passed on, so a chatty
+// command does not re-clean its whole output for every chunk.
+const outputInterval = 100 * time.Millisecond
+
+// outputWriter receives a running command's raw output and hands update
+// the cleaned output so far, along with the raw text new since last time.
+type outputWriter struct {
+	update func(output, chunk string)
+
+	mu      sync.Mutex
+	raw     bytes.Buffer
+	pending bytes.Buffer
+	flushed time.Time
+}
+
+// blockWriter keeps a running command's block current and publishes what
+// is new as command.output events.
+func (p *Plugin) blockWriter(blockID string) *outputWriter {
+	return &outputWriter{update: func(output, chunk string) {
+		p.state.UpdateBlock(blockID, func(b *pipeline.Block) {
+			b.Output = output
+		})
+		p.bus.Publish(pipeline.Event{
+			Type:      pipeline.EventCommandOutput,
+			Timestamp: time.Now(),
+			Source:    p.Name(),
+			BlockID:   blockID,
+			Data:      chunk,
+		})
+	}}
+}
+
+// jobWriter keeps a background job's output current.
+func (p *Plugin) jobWriter(jobID string) *outputWriter {
+	return &outputWriter{update: func(output, _ string) {
+		p.state.UpdateJob(jobID, func(j *pipeline.Job) {
+			j.Output = output
+		})
+	}}
+}
+
+func (w *outputWriter) Write(chunk []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.raw.Write(chunk)
+	w.pending.Write(chunk)
+	if time.Since(w.flushed) < outputInterval {
+		return len(chunk), nil
+	}
+	w.flush()
+	return len(chunk), nil
+}
+
+// Close passes on any output still held back by the interval.
+func (w *outputWriter) Close() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.pending.Len() > 0 {
+		w.flush()
+	}
+	return nil
+}
+
+func (w *outputWriter) flush() {
+	w.flushed = time.Now()
+	w.update(executor.CleanPTYOutput(w.raw.String()), w.pending.String())
+	w.pending.Reset()
+}
@@ -0,0 +1,56 @@
+package command
+
+import (
+	"strings"
+	"sync"
+	"testing"
+
+	"dev-cli/internal/pipeline"
+)
+
+func TestExecute_StreamsOutput(t *testing.T) {
+	if testing.Short() {
+		t.Skip("runs a shell")
+	}
+	t.Setenv("SHELL", "/bin/sh")
+
+	bus := pipeline.NewEventBus()
+	state := pipeline.NewStateStore()
+	p := New()
+	if err := p.Init(bus, state); err != nil {
+		t.Fatal(err)
+	}
+
+	var mu sync.Mutex
+	var streamed strings.Builder
+	var partial []pipeline.Block
+	bus.Subscribe(pipeline.EventCommandOutput, func(e pipeline.Event) {
+		mu.Lock()
+		defer mu.Unlock()
+		streamed.WriteString(e.Data.(string))
+		if b := state.GetBlock(e.BlockID); b != nil {
+			partial = append(partial, *b)
+		}
+	})
+
+	block := p.Execute("echo first; sleep 0.3; echo second")
+
+	mu.Lock()
+	defer mu.Unlock()
+	if len(partial) == 0 {
+		t.Fatal("expected output events while the command ran")
+	}
+	if first := partial[0]; !first.Running || !strings.Contains(first.Output, "first") || strings.Contains(first.Output, "second") {
+		t.Errorf("first partial block = %+v, want running with only the first line", first)
+	}
+	if got := streamed.String(); !strings.Contains(got, "first") || !strings.Contains(got, "second") {
+		t.Errorf("streamed output = %q, want both lines", got)
+	}
+
+	if block.Running || block.Output != "first\r\nsecond" && block.Output != "first\nsecond" {
+		t.Errorf("final block = %+v", block)
+	}
+	if stored := state.GetBlock(block.ID); stored == nil || stored.Running || stored.Output != block.Output {
+		t.Errorf("stored block = %+v, want the final result", stored)
+	}
+}
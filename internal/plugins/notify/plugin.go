@@ -0,0 +1,214 @@
+// Package notify reports finished workflow runs to the notifiers each
+// workflow lists: desktop notifications, Slack and generic webhooks.
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+	"time"
+
+	"dev-cli/internal/pipeline"
+	"dev-cli/internal/workflow"
+)
+
+// sendTimeout bounds each notification, so a dead webhook can't hold up
+// the end of a run for long.
+const sendTimeout = 10 * time.Second
+
+// Summary is a finished run as the notifiers report it; generic webhooks
+// get it as their JSON body.
+type Summary struct {
+	RunID    string         `json:"run_id"`
+	Workflow string         `json:"workflow"`
+	Status   string         `json:"status"`
+	Duration string         `json:"duration"`
+	Error    string         `json:"error,omitempty"`
+	Steps    map[string]int `json:"steps"`
+	Message  string         `json:"message"`
+}
+
+type Plugin struct {
+	bus    *pipeline.EventBus
+	client *http.Client
+	// desktop shows a desktop notification.
+	desktop func(ctx context.Context, title, body string) error
+	// warn reports a notification that couldn't be sent.
+	warn func(err error)
+	// resolve returns the URL a notifier of run runID posts to.
+	resolve func(runID string, n workflow.Notifier) (string, error)
+}
+
+func New() *Plugin {
+	return &Plugin{
+		client:  &http.Client{Timeout: sendTimeout},
+		desktop: desktopNotify,
+		warn: func(err error) {
+			fmt.Fprintf(os.Stderr, "Warning: workflow notification failed: %v\n", err)
+		},
+		resolve: func(runID string, n workflow.Notifier) (string, error) {
+			return workflow.Interpolate(n.URL, nil, nil)
+		},
+	}
+}
+
+// SetURLResolver makes the plugin resolve notifier URLs with resolve,
+// usually the running engine's NotifierURL, so they can use the
+// workflow's env and secrets rather than only the process env.
+func (p *Plugin) SetURLResolver(resolve func(runID string, n workflow.Notifier) (string, error)) {
+	p.resolve = resolve
+}
+
+// SetWarn makes the plugin report notifications it couldn't send to warn
+// instead of stderr, for callers that own the terminal.
+func (p *Plugin) SetWarn(warn func(err error)) {
+	p.warn = warn
+}
+
+func (p *Plugin) Name() string {
+	return "notify"
+}
+
+func (p *Plugin) Init(bus *pipeline.EventBus, state *pipeline.StateStore) error {
+	p.bus = bus
+	bus.Subscribe(pipeline.EventWorkflowComplete, p.handleRunEnd)
+	bus.Subscribe(pipeline.EventWorkflowFailed, p.handleRunEnd)
+	return nil
+}
+
+func (p *Plugin) Start(ctx context.Context) error {
+	return nil
+}
+
+func (p *Plugin) Stop() error {
+	return nil
+}
+
+// handleRunEnd sends the run's summary to each of its workflow's notifiers
+// that wants it. It runs on the publishing goroutine, so a run isn't
+// reported finished before its notifications are out.
+func (p *Plugin) handleRunEnd(e pipeline.Event) {
+	data, _ := e.Data.(map[string]interface{})
+	notifiers, _ := data["notify"].([]workflow.Notifier)
+	if len(notifiers) == 0 {
+		return
+	}
+	success := e.Type == pipeline.EventWorkflowComplete
+	summary := summarize(data, success)
+
+	for _, n := range notifiers {
+		if !n.Wants(success) {
+			continue
+		}
+		if err := p.send(n, summary); err != nil {
+			p.warn(fmt.Errorf("%s: %w", n.Type, err))
+		}
+	}
+}
+
+func summarize(data map[string]interface{}, success bool) Summary {
+	s := Summary{Steps: make(map[string]int)}
+	s.RunID, _ = data["run_id"].(string)
+	s.Workflow, _ = data["workflow_name"].(string)
+	s.Status, _ = data["status"].(string)
+	s.Duration, _ = data["duration"].(string)
+	s.Error, _ = data["error"].(string)
+	steps, _ := data["steps"].(map[workflow.StepStatus]int)
+	for status, n := range steps {
+		s.Steps[string(status)] = n
+	}
+
+	var counts []string
+	for _, status := range []workflow.StepStatus{workflow.StepSuccess, workflow.StepFailed, workflow.StepSkipped, workflow.StepRolledBack} {
+		if n := steps[status]; n > 0 {
+			counts = append(counts, fmt.Sprintf("%d %s", n, status))
+		}
+	}
+	if success {
+		s.Message = fmt.Sprintf("✓ %s completed in %s", s.Workflow, s.Duration)
+	} else {
+		s.Message = fmt.Sprintf("✗ %s %s after %s", s.Workflow, s.Status, s.Duration)
+		if s.Error != "" {
+			s.Message += ": " + s.Error
+		}
+	}
+	if len(counts) > 0 {
+		s.Message += " (steps: " + strings.Join(counts, ", ") + ")"
+	}
+	return s
+}
+
+// send delivers s to n. Errors quote n's URL as written in the workflow,
+// since the resolved one may hold a secret.
+func (p *Plugin) send(n workflow.Notifier, s Summary) error {
+	ctx, cancel := context.WithTimeout(context.Background(), sendTimeout)
+	defer cancel()
+
+	if n.Type == "desktop" {
+		return p.desktop(ctx, "dev-cli workflow", s.Message)
+	}
+
+	url, err := p.resolve(s.RunID, n)
+	if err != nil {
+		return err
+	}
+	var body any = s
+	if n.Type == "slack" {
+		body = map[string]string{"text": s.Message}
+	}
+	payload, err := json.Marshal(body)
+	if err != nil {
+		return err
+	}
+	if err := p.post(ctx, url, payload); err != nil {
+		return errors.New(strings.ReplaceAll(err.Error(), url, n.URL))
+	}
+	return nil
+}
+
+func (p *Plugin) post(ctx context.Context, url string, payload []byte) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(payload))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("webhook returned %s", resp.Status)
+	}
+	return nil
+}
+
+// desktopNotify shows a notification with notify-send on Linux and
+// osascript on macOS.
+func desktopNotify(ctx context.Context, title, body string) error {
+	var cmd *exec.Cmd
+	switch runtime.GOOS {
+	case "darwin":
+		script := fmt.Sprintf("display notification %s with title %s", appleScriptString(body), appleScriptString(title))
+		cmd = exec.CommandContext(ctx, "osascript", "-e", script)
+	case "windows":
+		return fmt.Errorf("desktop notifications are not supported on windows")
+	default:
+		cmd = exec.CommandContext(ctx, "notify-send", "--app-name=dev-cli", title, body)
+	}
+	if out, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("%w: %s", err, strings.TrimSpace(string(out)))
+	}
+	return nil
+}
+
+func appleScriptString(s string) string {
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(s) + `"`
+}
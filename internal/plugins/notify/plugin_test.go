@@ -0,0 +1,170 @@
+package notify
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	"dev-cli/internal/pipeline"
+	"dev-cli/internal/secrets"
+	"dev-cli/internal/workflow"
+)
+
+func TestNotifyOnRunEnd(t *testing.T) {
+	if testing.Short() {
+		t.Skip("runs a shell")
+	}
+	t.Setenv("SHELL", "/bin/sh")
+
+	var mu sync.Mutex
+	posts := make(map[string][]string)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		mu.Lock()
+		posts[r.URL.Path] = append(posts[r.URL.Path], string(body))
+		mu.Unlock()
+	}))
+	defer srv.Close()
+	t.Setenv("DEV_CLI_TEST_HOOK", srv.URL)
+
+	bus := pipeline.NewEventBus()
+	p := New()
+	var desktop []string
+	p.desktop = func(ctx context.Context, title, body string) error {
+		desktop = append(desktop, body)
+		return nil
+	}
+	p.warn = func(err error) { t.Errorf("unexpected notification error: %v", err) }
+	if err := p.Init(bus, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	wf, err := workflow.Parse([]byte(`
+name: Deploy
+notify:
+  - type: desktop
+  - type: slack
+    url: ${{ env.DEV_CLI_TEST_HOOK }}/slack
+    on: [failure]
+  - type: webhook
+    url: ${{ env.DEV_CLI_TEST_HOOK }}/hook
+steps:
+  - id: build
+    command: "true"
+  - id: ship
+    command: exit 3
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := workflow.NewEngine(nil, bus).Run(context.Background(), wf); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(desktop) != 1 || !strings.HasPrefix(desktop[0], "✗ Deploy failed after") ||
+		!strings.Contains(desktop[0], "(steps: 1 success, 1 failed)") {
+		t.Errorf("unexpected desktop notification %q", desktop)
+	}
+	if len(posts["/slack"]) != 1 || !strings.Contains(posts["/slack"][0], `"text":"✗ Deploy failed`) {
+		t.Errorf("unexpected slack post %q", posts["/slack"])
+	}
+	var summary Summary
+	if len(posts["/hook"]) != 1 {
+		t.Fatalf("expected one webhook post, got %q", posts["/hook"])
+	}
+	if err := json.Unmarshal([]byte(posts["/hook"][0]), &summary); err != nil {
+		t.Fatal(err)
+	}
+	if summary.Workflow != "Deploy" || summary.Status != "failed" || summary.Steps["failed"] != 1 || summary.RunID == "" {
+		t.Errorf("unexpected webhook summary %+v", summary)
+	}
+
+	// A successful run skips the failure-only Slack notifier.
+	wf.Steps = wf.Steps[:1]
+	if _, err := workflow.NewEngine(nil, bus).Run(context.Background(), wf); err != nil {
+		t.Fatal(err)
+	}
+	if len(posts["/slack"]) != 1 || len(posts["/hook"]) != 2 || len(desktop) != 2 || !strings.HasPrefix(desktop[1], "✓ Deploy completed in") {
+		t.Errorf("expected the success to reach only the desktop and webhook, got %q and %q", desktop, posts)
+	}
+}
+
+func TestNotifyURLFromSecrets(t *testing.T) {
+	if testing.Short() {
+		t.Skip("runs a shell")
+	}
+	t.Setenv("SHELL", "/bin/sh")
+
+	var mu sync.Mutex
+	var paths []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		paths = append(paths, r.URL.Path)
+		mu.Unlock()
+	}))
+	defer srv.Close()
+
+	secretsFile := filepath.Join(t.TempDir(), "secrets.enc")
+	if err := secrets.SaveFile(secretsFile, "pass", map[string]string{
+		"hook": srv.URL + "/services/T0K3N",
+		"dead": "http://127.0.0.1:1/services/D34D",
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	bus := pipeline.NewEventBus()
+	engine := workflow.NewEngine(nil, bus)
+	engine.SetSecretsFile(secretsFile, "pass")
+	p := New()
+	var warnings []string
+	p.warn = func(err error) { warnings = append(warnings, err.Error()) }
+	p.SetURLResolver(engine.NotifierURL)
+	if err := p.Init(bus, nil); err != nil {
+		t.Fatal(err)
+	}
+	var events []string
+	bus.SubscribeAll(func(e pipeline.Event) { events = append(events, fmt.Sprintf("%+v", e)) })
+
+	wf, err := workflow.Parse([]byte(`
+name: Deploy
+secrets:
+  SLACK_HOOK: file:hook
+  DEAD_HOOK: file:dead
+notify:
+  - type: slack
+    url: ${{ env.SLACK_HOOK }}
+  - type: webhook
+    url: ${{ env.DEAD_HOOK }}
+steps:
+  - id: build
+    command: "true"
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := engine.Run(context.Background(), wf); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(paths) != 1 || paths[0] != "/services/T0K3N" {
+		t.Errorf("expected the slack URL to come from the secret, got %q", paths)
+	}
+	if len(warnings) != 1 || strings.Contains(warnings[0], "D34D") || !strings.Contains(warnings[0], "${{ env.DEAD_HOOK }}") {
+		t.Errorf("expected one warning quoting the URL as written, got %q", warnings)
+	}
+	if len(events) == 0 {
+		t.Fatal("expected the run's events")
+	}
+	for _, e := range events {
+		if strings.Contains(e, "T0K3N") || strings.Contains(e, "D34D") {
+			t.Errorf("expected no secret in published events, got %s", e)
+		}
+	}
+}
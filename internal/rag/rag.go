@@ -0,0 +1,259 @@
+// Package rag indexes a project's own documentation (README, docs/,
+// Makefile) into embeddings and retrieves the snippets relevant to a
+// question, so AI answers use the project's actual scripts and conventions.
+package rag
+
+import (
+	"database/sql"
+	"fmt"
+	"io/fs"
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"unicode/utf8"
+
+	"dev-cli/internal/storage"
+)
+
+const (
+	// maxChunkChars keeps chunks small enough to quote several in a prompt.
+	maxChunkChars = 1000
+	// maxChunks caps the index of a single project.
+	maxChunks = 200
+	// maxDocFileSize skips generated or vendored giants.
+	maxDocFileSize = 256 * 1024
+)
+
+// Embedder turns texts into vectors; llm.LLMProvider satisfies it.
+type Embedder interface {
+	Embed(texts []string) ([][]float32, error)
+}
+
+// Stats describes what an Index call did.
+type Stats struct {
+	Files    int // documentation files found
+	Embedded int // files (re)embedded because they were new or changed
+	Removed  int // files dropped from the index
+	Chunks   int // chunks in the index afterwards
+}
+
+// DocFiles lists the documentation files of the project at root, relative
+// to root: top-level README/CONTRIBUTING/Makefile and markdown under docs/.
+func DocFiles(root string) []string {
+	var files []string
+
+	entries, _ := os.ReadDir(root)
+	for _, e := range entries {
+		if e.IsDir() {
+			continue
+		}
+		upper := strings.ToUpper(e.Name())
+		if strings.HasPrefix(upper, "README") || strings.HasPrefix(upper, "CONTRIBUTING") || e.Name() == "Makefile" {
+			files = append(files, e.Name())
+		}
+	}
+
+	filepath.WalkDir(filepath.Join(root, "docs"), func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return nil
+		}
+		if d.IsDir() {
+			if strings.HasPrefix(d.Name(), ".") || d.Name() == "node_modules" {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if ext := strings.ToLower(filepath.Ext(path)); ext == ".md" || ext == ".txt" {
+			if rel, err := filepath.Rel(root, path); err == nil {
+				files = append(files, rel)
+			}
+		}
+		return nil
+	})
+
+	sort.Strings(files)
+	return files
+}
+
+// Chunk splits a document at markdown headings and blank lines, then packs
+// the pieces into chunks of at most maxChunkChars. A heading starts a new
+// chunk so sections stay together.
+func Chunk(content string) []string {
+	var chunks []string
+	var cur strings.Builder
+
+	flush := func() {
+		if text := strings.TrimSpace(cur.String()); text != "" {
+			chunks = append(chunks, text)
+		}
+		cur.Reset()
+	}
+
+	for _, para := range strings.Split(content, "\n\n") {
+		para = strings.TrimSpace(para)
+		if para == "" {
+			continue
+		}
+		if strings.HasPrefix(para, "#") || cur.Len()+len(para) > maxChunkChars {
+			flush()
+		}
+		for len(para) > maxChunkChars {
+			// Back off to a rune boundary so no chunk ends in half a
+			// character.
+			cut := maxChunkChars
+			for cut > 0 && !utf8.RuneStart(para[cut]) {
+				cut--
+			}
+			chunks = append(chunks, para[:cut])
+			para = para[cut:]
+		}
+		if cur.Len() > 0 {
+			cur.WriteString("\n\n")
+		}
+		cur.WriteString(para)
+	}
+	flush()
+	return chunks
+}
+
+// Index brings the stored chunks of the project at root up to date. Only
+// files whose modification time changed are re-embedded, so calling it
+// before every search is cheap.
+func Index(db *sql.DB, root string, e Embedder) (Stats, error) {
+	existing, err := storage.GetDocChunks(db, root)
+	if err != nil {
+		return Stats{}, fmt.Errorf("read index: %w", err)
+	}
+	indexed := make(map[string]int64)
+	counts := make(map[string]int)
+	for _, c := range existing {
+		indexed[c.Path] = c.ModTime.Unix()
+		counts[c.Path]++
+	}
+	total := len(existing)
+
+	var stats Stats
+	current := make(map[string]bool)
+	for _, rel := range DocFiles(root) {
+		info, err := os.Stat(filepath.Join(root, rel))
+		if err != nil || info.Size() > maxDocFileSize {
+			continue
+		}
+		stats.Files++
+		current[rel] = true
+		if mod, ok := indexed[rel]; ok && mod == info.ModTime().Unix() {
+			continue
+		}
+
+		data, err := os.ReadFile(filepath.Join(root, rel))
+		if err != nil {
+			continue
+		}
+		total -= counts[rel]
+		texts := Chunk(string(data))
+		if len(texts) > maxChunks-total {
+			texts = texts[:max(0, maxChunks-total)]
+		}
+		if len(texts) == 0 {
+			// Nothing left to store, either because the file is now empty or
+			// the index is full: drop what it had so it doesn't go stale.
+			if counts[rel] > 0 {
+				if err := storage.DeleteDocChunks(db, root, rel); err != nil {
+					return stats, fmt.Errorf("remove %s: %w", rel, err)
+				}
+				stats.Removed++
+			}
+			continue
+		}
+
+		// Prefix the path so the embedding knows where the text lives.
+		inputs := make([]string, len(texts))
+		for i, t := range texts {
+			inputs[i] = rel + "\n" + t
+		}
+		vectors, err := e.Embed(inputs)
+		if err != nil {
+			return stats, fmt.Errorf("embed %s: %w", rel, err)
+		}
+
+		chunks := make([]storage.DocChunk, len(texts))
+		for i, t := range texts {
+			chunks[i] = storage.DocChunk{Project: root, Path: rel, Index: i, Content: t, Embedding: vectors[i], ModTime: info.ModTime()}
+		}
+		if err := storage.ReplaceDocChunks(db, root, rel, chunks); err != nil {
+			return stats, fmt.Errorf("store %s: %w", rel, err)
+		}
+		stats.Embedded++
+		total += len(chunks)
+	}
+
+	for path := range indexed {
+		if !current[path] {
+			if err := storage.DeleteDocChunks(db, root, path); err != nil {
+				return stats, fmt.Errorf("remove %s: %w", path, err)
+			}
+			stats.Removed++
+		}
+	}
+
+	chunks, err := storage.GetDocChunks(db, root)
+	if err != nil {
+		return stats, fmt.Errorf("read index: %w", err)
+	}
+	stats.Chunks = len(chunks)
+	return stats, nil
+}
+
+// Search refreshes the index and returns the k chunks most similar to query.
+func Search(db *sql.DB, root, query string, e Embedder, k int) ([]storage.DocChunk, error) {
+	if _, err := Index(db, root, e); err != nil {
+		return nil, err
+	}
+	chunks, err := storage.GetDocChunks(db, root)
+	if err != nil || len(chunks) == 0 {
+		return nil, err
+	}
+
+	vectors, err := e.Embed([]string{query})
+	if err != nil {
+		return nil, fmt.Errorf("embed query: %w", err)
+	}
+	q := vectors[0]
+
+	scores := make([]float64, len(chunks))
+	for i, c := range chunks {
+		scores[i] = cosine(q, c.Embedding)
+	}
+	order := make([]int, len(chunks))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(a, b int) bool { return scores[order[a]] > scores[order[b]] })
+
+	var out []storage.DocChunk
+	for _, i := range order {
+		if len(out) == k || scores[i] <= 0 {
+			break
+		}
+		out = append(out, chunks[i])
+	}
+	return out, nil
+}
+
+func cosine(a, b []float32) float64 {
+	if len(a) != len(b) || len(a) == 0 {
+		return 0
+	}
+	var dot, na, nb float64
+	for i := range a {
+		dot += float64(a[i]) * float64(b[i])
+		na += float64(a[i]) * float64(a[i])
+		nb += float64(b[i]) * float64(b[i])
+	}
+	if na == 0 || nb == 0 {
+		return 0
+	}
+	return dot / (math.Sqrt(na) * math.Sqrt(nb))
+}
@@ -0,0 +1,106 @@
+package rag
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+	"unicode/utf8"
+
+	"dev-cli/internal/llm"
+	"dev-cli/internal/storage"
+)
+
+func writeFile(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestChunk_SplitsAtHeadings(t *testing.T) {
+	doc := "# Title\n\nIntro text.\n\n## Build\n\nRun make build.\n\nThen make test."
+	chunks := Chunk(doc)
+	if len(chunks) != 2 {
+		t.Fatalf("expected 2 chunks, got %d: %q", len(chunks), chunks)
+	}
+	if !strings.HasPrefix(chunks[1], "## Build") || !strings.Contains(chunks[1], "make test") {
+		t.Errorf("build section should stay together, got %q", chunks[1])
+	}
+
+	long := strings.Repeat("x", 2500)
+	for _, c := range Chunk(long) {
+		if len(c) > maxChunkChars {
+			t.Errorf("chunk of %d chars exceeds limit", len(c))
+		}
+	}
+
+	for _, c := range Chunk("x" + strings.Repeat("é", 1500)) {
+		if len(c) > maxChunkChars || !utf8.ValidString(c) {
+			t.Errorf("chunk split a rune or exceeds the limit: %d bytes, valid=%v", len(c), utf8.ValidString(c))
+		}
+	}
+}
+
+func TestIndexAndSearch(t *testing.T) {
+	root := t.TempDir()
+	writeFile(t, filepath.Join(root, "README.md"), "# Shop\n\nAn online shop.\n\n## Database\n\nStart postgres with make db-up before running migrations.")
+	writeFile(t, filepath.Join(root, "Makefile"), "test:\n\tgo test ./...")
+	writeFile(t, filepath.Join(root, "docs", "deploy.md"), "# Deploy\n\nDeploy with make release to the staging cluster.")
+	writeFile(t, filepath.Join(root, "main.go"), "package main")
+
+	db, err := storage.OpenDB(filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	fake := llm.NewFakeProvider()
+
+	stats, err := Index(db, root, fake)
+	if err != nil {
+		t.Fatalf("Index failed: %v", err)
+	}
+	if stats.Files != 3 || stats.Embedded != 3 {
+		t.Errorf("expected 3 doc files embedded, got %+v", stats)
+	}
+
+	stats, err = Index(db, root, fake)
+	if err != nil || stats.Embedded != 0 {
+		t.Errorf("unchanged files should not be re-embedded, got %+v, %v", stats, err)
+	}
+
+	results, err := Search(db, root, "how do I start the postgres database", fake, 1)
+	if err != nil {
+		t.Fatalf("Search failed: %v", err)
+	}
+	if len(results) != 1 || !strings.Contains(results[0].Content, "make db-up") {
+		t.Errorf("expected the database section, got %+v", results)
+	}
+
+	os.Remove(filepath.Join(root, "Makefile"))
+	future := time.Now().Add(time.Hour)
+	os.Chtimes(filepath.Join(root, "README.md"), future, future)
+	stats, err = Index(db, root, fake)
+	if err != nil {
+		t.Fatalf("reindex failed: %v", err)
+	}
+	if stats.Removed != 1 || stats.Embedded != 1 {
+		t.Errorf("expected Makefile removed and README re-embedded, got %+v", stats)
+	}
+
+	writeFile(t, filepath.Join(root, "docs", "deploy.md"), "")
+	os.Chtimes(filepath.Join(root, "docs", "deploy.md"), future, future)
+	if _, err := Index(db, root, fake); err != nil {
+		t.Fatalf("reindex failed: %v", err)
+	}
+	chunks, _ := storage.GetDocChunks(db, root)
+	for _, c := range chunks {
+		if c.Path == filepath.Join("docs", "deploy.md") {
+			t.Errorf("emptied file kept a stale chunk: %q", c.Content)
+		}
+	}
+}
@@ -0,0 +1,114 @@
+// Package secrets reads secret values from the OS keyring and keeps them
+// in a passphrase-encrypted file.
+package secrets
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/pbkdf2"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// kdfIterations is the PBKDF2-SHA256 work factor for the file's key.
+const kdfIterations = 600_000
+
+// fileFormat is the JSON on disk: the secrets as a JSON object, sealed
+// with AES-256-GCM under a key derived from the passphrase and salt.
+type fileFormat struct {
+	Salt  []byte `json:"salt"`
+	Nonce []byte `json:"nonce"`
+	Data  []byte `json:"data"`
+}
+
+// LoadFile decrypts the secrets file at path. A file that doesn't exist
+// holds no secrets.
+func LoadFile(path, passphrase string) (map[string]string, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return map[string]string{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if passphrase == "" {
+		return nil, fmt.Errorf("secrets file %s needs a passphrase (set DEV_CLI_SECRETS_PASSPHRASE)", path)
+	}
+
+	var f fileFormat
+	if err := json.Unmarshal(data, &f); err != nil {
+		return nil, fmt.Errorf("read secrets file %s: %w", path, err)
+	}
+	gcm, err := fileCipher(passphrase, f.Salt)
+	if err != nil {
+		return nil, err
+	}
+	if len(f.Nonce) != gcm.NonceSize() {
+		return nil, fmt.Errorf("read secrets file %s: bad nonce", path)
+	}
+	plain, err := gcm.Open(nil, f.Nonce, f.Data, nil)
+	if err != nil {
+		return nil, fmt.Errorf("decrypt secrets file %s: wrong passphrase or corrupted file", path)
+	}
+
+	values := make(map[string]string)
+	if err := json.Unmarshal(plain, &values); err != nil {
+		return nil, fmt.Errorf("read secrets file %s: %w", path, err)
+	}
+	return values, nil
+}
+
+// SaveFile encrypts values into the secrets file at path with a fresh salt
+// and nonce, readable only by the user.
+func SaveFile(path, passphrase string, values map[string]string) error {
+	if passphrase == "" {
+		return fmt.Errorf("a passphrase is required to encrypt the secrets file")
+	}
+	plain, err := json.Marshal(values)
+	if err != nil {
+		return err
+	}
+
+	salt := make([]byte, 16)
+	if _, err := rand.Read(salt); err != nil {
+		return err
+	}
+	gcm, err := fileCipher(passphrase, salt)
+	if err != nil {
+		return err
+	}
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return err
+	}
+	data, err := json.Marshal(fileFormat{Salt: salt, Nonce: nonce, Data: gcm.Seal(nil, nonce, plain, nil)})
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return err
+	}
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
+
+func fileCipher(passphrase string, salt []byte) (cipher.AEAD, error) {
+	key, err := pbkdf2.Key(sha256.New, passphrase, salt, kdfIterations, 32)
+	if err != nil {
+		return nil, err
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
@@ -0,0 +1,47 @@
+package secrets
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestFileRoundTrip(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "secrets.enc")
+
+	values, err := LoadFile(path, "")
+	if err != nil || len(values) != 0 {
+		t.Fatalf("expected a missing file to hold no secrets, got %v, %v", values, err)
+	}
+
+	want := map[string]string{"DB_PASSWORD": "hunter2", "API_TOKEN": "t0k3n"}
+	if err := SaveFile(path, "correct horse", want); err != nil {
+		t.Fatal(err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(data), "hunter2") {
+		t.Error("expected the file to be encrypted")
+	}
+	if info, _ := os.Stat(path); info.Mode().Perm() != 0o600 {
+		t.Errorf("expected mode 0600, got %v", info.Mode().Perm())
+	}
+
+	got, err := LoadFile(path, "correct horse")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 2 || got["DB_PASSWORD"] != "hunter2" || got["API_TOKEN"] != "t0k3n" {
+		t.Errorf("LoadFile = %v", got)
+	}
+
+	if _, err := LoadFile(path, "wrong"); err == nil || !strings.Contains(err.Error(), "wrong passphrase") {
+		t.Errorf("expected a wrong passphrase error, got %v", err)
+	}
+	if _, err := LoadFile(path, ""); err == nil {
+		t.Error("expected an existing file to need a passphrase")
+	}
+}
@@ -0,0 +1,48 @@
+package secrets
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os/exec"
+	"runtime"
+	"strings"
+	"time"
+)
+
+// Keyring looks up the secret stored for service and account in the OS
+// keyring: the login keychain on macOS (security) and the Secret Service
+// on Linux (secret-tool, from libsecret). Store one with
+//
+//	security add-generic-password -s <service> -a <account> -w
+//	secret-tool store --label=<label> service <service> account <account>
+func Keyring(service, account string) (string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	var cmd *exec.Cmd
+	switch runtime.GOOS {
+	case "darwin":
+		cmd = exec.CommandContext(ctx, "security", "find-generic-password", "-s", service, "-a", account, "-w")
+	case "linux", "freebsd", "openbsd", "netbsd":
+		cmd = exec.CommandContext(ctx, "secret-tool", "lookup", "service", service, "account", account)
+	default:
+		return "", fmt.Errorf("the keyring is not supported on %s; use the secrets file", runtime.GOOS)
+	}
+
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		if _, ok := err.(*exec.ExitError); ok {
+			return "", fmt.Errorf("no keyring secret for service %q account %q", service, account)
+		}
+		return "", fmt.Errorf("keyring lookup for %q: %w", service, err)
+	}
+	// secret-tool prints an empty line for no match on some versions.
+	value := strings.TrimSuffix(stdout.String(), "\n")
+	if value == "" {
+		return "", fmt.Errorf("no keyring secret for service %q account %q", service, account)
+	}
+	return value, nil
+}
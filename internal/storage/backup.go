@@ -0,0 +1,198 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"modernc.org/sqlite"
+)
+
+// Backups are named history-<time>.db, by when they were taken, or
+// history-<time>-<label>.db to tell why.
+const (
+	backupPrefix     = "history-"
+	backupSuffix     = ".db"
+	backupTimeLayout = "20060102-150405"
+)
+
+// autoBackupEvery is how old the latest backup gets before AutoBackup
+// takes another.
+const autoBackupEvery = 24 * time.Hour
+
+// BackupFile is a database backup in the backups directory.
+type BackupFile struct {
+	Path string
+	Time time.Time
+	Size int64
+}
+
+// BackupDir is where database backups go: backups/ next to the history
+// database, shared with the Containers tab's volume backups.
+func BackupDir() (string, error) {
+	path, err := DBPath()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(filepath.Dir(path), "backups"), nil
+}
+
+// BackupPath names a backup taken at t in dir, with an optional label.
+func BackupPath(dir string, t time.Time, label string) string {
+	name := backupPrefix + t.Format(backupTimeLayout)
+	if label != "" {
+		name += "-" + label
+	}
+	return filepath.Join(dir, name+backupSuffix)
+}
+
+// backupConn is the part of the sqlite driver's connection that copies
+// a database page by page while it stays in use.
+type backupConn interface {
+	NewBackup(dstURI string) (*sqlite.Backup, error)
+	NewRestore(srcURI string) (*sqlite.Backup, error)
+}
+
+// Backup writes a consistent snapshot of db to dst with SQLite's online
+// backup API, so commands can keep being logged meanwhile. It doesn't
+// overwrite an existing file.
+func Backup(db *sql.DB, dst string) error {
+	if _, err := os.Stat(dst); err == nil {
+		return fmt.Errorf("%s already exists", dst)
+	}
+	if err := os.MkdirAll(filepath.Dir(dst), 0755); err != nil {
+		return fmt.Errorf("create backup dir: %w", err)
+	}
+	return copyPages(db, func(c backupConn) (*sqlite.Backup, error) { return c.NewBackup(dst) })
+}
+
+// Restore replaces the contents of db with the backup at src, then brings
+// its schema up to date. src must be a dev-cli history database.
+func Restore(db *sql.DB, src string) error {
+	if err := checkBackup(src); err != nil {
+		return err
+	}
+	if err := copyPages(db, func(c backupConn) (*sqlite.Backup, error) { return c.NewRestore(src) }); err != nil {
+		return err
+	}
+	return migrate(db)
+}
+
+func copyPages(db *sql.DB, start func(backupConn) (*sqlite.Backup, error)) error {
+	conn, err := db.Conn(context.Background())
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	return conn.Raw(func(driverConn any) error {
+		c, ok := driverConn.(backupConn)
+		if !ok {
+			return errors.New("the database driver can't do online backups")
+		}
+		b, err := start(c)
+		if err != nil {
+			return err
+		}
+		for more := true; more; {
+			if more, err = b.Step(-1); err != nil {
+				b.Finish()
+				return err
+			}
+		}
+		return b.Finish()
+	})
+}
+
+// checkBackup makes sure path holds a history database, so a restore
+// doesn't replace the history with some other file.
+func checkBackup(path string) error {
+	if _, err := os.Stat(path); err != nil {
+		return err
+	}
+	db, err := sql.Open("sqlite", "file:"+path+"?mode=ro")
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+	var n int
+	if err := db.QueryRow(`SELECT COUNT(*) FROM history`).Scan(&n); err != nil {
+		return fmt.Errorf("%s is not a dev-cli history database: %w", path, err)
+	}
+	return nil
+}
+
+// ListBackups returns the database backups in dir, newest first.
+func ListBackups(dir string) ([]BackupFile, error) {
+	entries, err := os.ReadDir(dir)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var backups []BackupFile
+	for _, e := range entries {
+		stamp, ok := strings.CutPrefix(e.Name(), backupPrefix)
+		if !ok || e.IsDir() {
+			continue
+		}
+		stamp, ok = strings.CutSuffix(stamp, backupSuffix)
+		if !ok || len(stamp) < len(backupTimeLayout) {
+			continue
+		}
+		stamp, label := stamp[:len(backupTimeLayout)], stamp[len(backupTimeLayout):]
+		t, err := time.ParseInLocation(backupTimeLayout, stamp, time.Local)
+		if err != nil || (label != "" && label[0] != '-') {
+			continue
+		}
+		info, err := e.Info()
+		if err != nil {
+			continue
+		}
+		backups = append(backups, BackupFile{Path: filepath.Join(dir, e.Name()), Time: t, Size: info.Size()})
+	}
+	slices.SortFunc(backups, func(a, b BackupFile) int { return b.Time.Compare(a.Time) })
+	return backups, nil
+}
+
+// RotateBackups deletes all but the newest keep backups in dir; keep 0
+// keeps them all. It returns the deleted ones.
+func RotateBackups(dir string, keep int) ([]BackupFile, error) {
+	backups, err := ListBackups(dir)
+	if err != nil || keep <= 0 || len(backups) <= keep {
+		return nil, err
+	}
+	for _, b := range backups[keep:] {
+		if err := os.Remove(b.Path); err != nil {
+			return nil, err
+		}
+	}
+	return backups[keep:], nil
+}
+
+// AutoBackup backs db up into dir when the latest backup there is a day
+// old or there is none, then rotates down to keep backups. It returns the
+// new backup's path, or "" when none was due.
+func AutoBackup(db *sql.DB, dir string, keep int, now time.Time) (string, error) {
+	backups, err := ListBackups(dir)
+	if err != nil {
+		return "", err
+	}
+	if len(backups) > 0 && now.Sub(backups[0].Time) < autoBackupEvery {
+		return "", nil
+	}
+	path := BackupPath(dir, now, "")
+	if err := Backup(db, path); err != nil {
+		return "", err
+	}
+	_, err = RotateBackups(dir, keep)
+	return path, err
+}
@@ -0,0 +1,78 @@
+package storage
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestBackupRestore(t *testing.T) {
+	dir := t.TempDir()
+	db, err := OpenDB(filepath.Join(dir, "history.db"))
+	if err != nil {
+		t.Fatalf("OpenDB failed: %v", err)
+	}
+	defer db.Close()
+	SaveCommand(db, LogEntry{Command: "make", ExitCode: 2})
+
+	backups := filepath.Join(dir, "backups")
+	path := BackupPath(backups, time.Now(), "")
+	if err := Backup(db, path); err != nil {
+		t.Fatalf("Backup failed: %v", err)
+	}
+	if err := Backup(db, path); err == nil {
+		t.Error("expected Backup to refuse overwriting a backup")
+	}
+
+	SaveCommand(db, LogEntry{Command: "rm -rf build"})
+	if err := Restore(db, path); err != nil {
+		t.Fatalf("Restore failed: %v", err)
+	}
+	items, _ := QueryHistory(db, HistoryFilter{})
+	if len(items) != 1 || items[0].Command != "make" {
+		t.Errorf("expected the history of the backup, got %+v", items)
+	}
+
+	junk := filepath.Join(dir, "notes.db")
+	os.WriteFile(junk, []byte("not a database"), 0644)
+	if err := Restore(db, junk); err == nil {
+		t.Error("expected Restore to refuse a file that isn't a history database")
+	}
+}
+
+func TestAutoBackup(t *testing.T) {
+	dir := t.TempDir()
+	db, err := OpenDB(filepath.Join(dir, "history.db"))
+	if err != nil {
+		t.Fatalf("OpenDB failed: %v", err)
+	}
+	defer db.Close()
+
+	backups := filepath.Join(dir, "backups")
+	day := time.Date(2026, 3, 1, 9, 0, 0, 0, time.Local)
+	for i := range 4 {
+		if path, err := AutoBackup(db, backups, 3, day.AddDate(0, 0, i)); err != nil || path == "" {
+			t.Fatalf("day %d: AutoBackup = %q, %v", i, path, err)
+		}
+	}
+	if path, _ := AutoBackup(db, backups, 3, day.AddDate(0, 0, 3).Add(time.Hour)); path != "" {
+		t.Errorf("expected no second backup the same day, got %s", path)
+	}
+
+	list, err := ListBackups(backups)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(list) != 3 || !list[0].Time.Equal(day.AddDate(0, 0, 3)) || !list[2].Time.Equal(day.AddDate(0, 0, 1)) {
+		t.Errorf("expected the newest 3 backups, newest first, got %+v", list)
+	}
+
+	labeled := BackupPath(backups, day.AddDate(0, 0, 5), "before-restore")
+	if err := Backup(db, labeled); err != nil {
+		t.Fatal(err)
+	}
+	if list, _ := ListBackups(backups); list[0].Path != labeled {
+		t.Errorf("expected the labeled backup listed first, got %+v", list[0])
+	}
+}
@@ -0,0 +1,59 @@
+package storage
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+// Bookmark is an Agent block pinned by the user, kept so it comes back
+// pinned in later sessions.
+type Bookmark struct {
+	ID int64
+	// Kind is the block's type: "command", or "ai" for a question.
+	Kind      string
+	Command   string
+	Output    string
+	ExitCode  int
+	Directory string
+	CreatedAt time.Time
+}
+
+// AddBookmark stores b and returns its ID.
+func AddBookmark(db *sql.DB, b Bookmark) (int64, error) {
+	res, err := db.Exec(`INSERT INTO bookmarks (created_at, kind, command, output, exit_code, directory)
+		VALUES (?, ?, ?, ?, ?, ?)`,
+		b.CreatedAt.Unix(), b.Kind, b.Command, b.Output, b.ExitCode, b.Directory)
+	if err != nil {
+		return 0, fmt.Errorf("insert bookmark: %w", err)
+	}
+	return res.LastInsertId()
+}
+
+// DeleteBookmark removes the bookmark of an unpinned block.
+func DeleteBookmark(db *sql.DB, id int64) error {
+	_, err := db.Exec(`DELETE FROM bookmarks WHERE id = ?`, id)
+	return err
+}
+
+// GetBookmarks returns every bookmark, oldest first.
+func GetBookmarks(db *sql.DB) ([]Bookmark, error) {
+	rows, err := db.Query(`SELECT id, created_at, kind, command, COALESCE(output, ''), COALESCE(exit_code, 0), COALESCE(directory, '')
+		FROM bookmarks ORDER BY created_at, id`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var bookmarks []Bookmark
+	for rows.Next() {
+		var b Bookmark
+		var created int64
+		if err := rows.Scan(&b.ID, &created, &b.Kind, &b.Command, &b.Output, &b.ExitCode, &b.Directory); err != nil {
+			return nil, err
+		}
+		b.CreatedAt = time.Unix(created, 0)
+		bookmarks = append(bookmarks, b)
+	}
+	return bookmarks, rows.Err()
+}
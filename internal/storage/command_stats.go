@@ -0,0 +1,165 @@
+package storage
+
+import (
+	"database/sql"
+	"fmt"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+)
+
+// CommandUsage sums up the runs of one base command (see BaseCommand).
+type CommandUsage struct {
+	Command  string
+	Runs     int
+	Failures int
+	// P50 and P95 are the median and 95th percentile run durations.
+	P50 time.Duration
+	P95 time.Duration
+}
+
+// FailureRate is the share of the runs that failed, 0 to 1.
+func (c CommandUsage) FailureRate() float64 {
+	if c.Runs == 0 {
+		return 0
+	}
+	return float64(c.Failures) / float64(c.Runs)
+}
+
+// BaseCommand is the program command runs: its first word after any
+// VAR=value assignments and sudo, without a directory. "" when there is
+// none.
+func BaseCommand(command string) string {
+	for _, word := range strings.Fields(command) {
+		if word == "sudo" || (strings.Contains(word, "=") && !strings.HasPrefix(word, "=")) {
+			continue
+		}
+		return filepath.Base(word)
+	}
+	return ""
+}
+
+// GetCommandUsage returns the usage of the base commands in history, the
+// most run first, at most limit of them.
+func GetCommandUsage(db *sql.DB, limit int) ([]CommandUsage, error) {
+	if err := RefreshCommandStats(db); err != nil {
+		return nil, err
+	}
+	return queryCommandStats(db, `ORDER BY runs DESC, command LIMIT ?`, limit)
+}
+
+// GetCommandUsageFor returns the usage of one base command, and false if
+// history has no runs of it.
+func GetCommandUsageFor(db *sql.DB, command string) (CommandUsage, bool, error) {
+	if err := RefreshCommandStats(db); err != nil {
+		return CommandUsage{}, false, err
+	}
+	usage, err := queryCommandStats(db, `WHERE command = ?`, BaseCommand(command))
+	if err != nil || len(usage) == 0 {
+		return CommandUsage{}, false, err
+	}
+	return usage[0], true, nil
+}
+
+func queryCommandStats(db *sql.DB, clause string, args ...any) ([]CommandUsage, error) {
+	rows, err := db.Query(`SELECT command, runs, failures, p50_ms, p95_ms FROM command_stats `+clause, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var usage []CommandUsage
+	for rows.Next() {
+		var u CommandUsage
+		var p50, p95 int64
+		if err := rows.Scan(&u.Command, &u.Runs, &u.Failures, &p50, &p95); err != nil {
+			return nil, err
+		}
+		u.P50, u.P95 = time.Duration(p50)*time.Millisecond, time.Duration(p95)*time.Millisecond
+		usage = append(usage, u)
+	}
+	return usage, rows.Err()
+}
+
+// RefreshCommandStats recomputes the command_stats table from history if
+// history changed since it was last computed, so readers pay for a scan
+// only after new commands. A collapsed row counts as each of its repeats.
+// Interrupted commands (exit 130) don't count as failures.
+func RefreshCommandStats(db *sql.DB) error {
+	var latest, computed int64
+	if err := db.QueryRow(`SELECT COALESCE(MAX(id), 0) FROM history`).Scan(&latest); err != nil {
+		return fmt.Errorf("refresh command stats: %w", err)
+	}
+	if err := db.QueryRow(`SELECT COALESCE(MAX(through_id), 0) FROM command_stats`).Scan(&computed); err != nil {
+		return fmt.Errorf("refresh command stats: %w", err)
+	}
+	if latest == computed {
+		return nil
+	}
+
+	tx, err := db.Begin()
+	if err != nil {
+		return fmt.Errorf("refresh command stats: %w", err)
+	}
+	defer tx.Rollback()
+
+	rows, err := tx.Query(`SELECT command, exit_code, duration_ms, repeats FROM history WHERE id <= ?`, latest)
+	if err != nil {
+		return fmt.Errorf("refresh command stats: %w", err)
+	}
+	byCommand := make(map[string]*CommandUsage)
+	durations := make(map[string][]int64)
+	for rows.Next() {
+		var command string
+		var exitCode, repeats int
+		var durationMs int64
+		if err := rows.Scan(&command, &exitCode, &durationMs, &repeats); err != nil {
+			rows.Close()
+			return fmt.Errorf("refresh command stats: %w", err)
+		}
+		base := BaseCommand(command)
+		if base == "" {
+			continue
+		}
+		u, ok := byCommand[base]
+		if !ok {
+			u = &CommandUsage{Command: base}
+			byCommand[base] = u
+		}
+		u.Runs += repeats
+		if exitCode != 0 && exitCode != 130 {
+			u.Failures += repeats
+		}
+		for range repeats {
+			durations[base] = append(durations[base], durationMs)
+		}
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("refresh command stats: %w", err)
+	}
+
+	if _, err := tx.Exec(`DELETE FROM command_stats`); err != nil {
+		return fmt.Errorf("refresh command stats: %w", err)
+	}
+	for base, u := range byCommand {
+		d := durations[base]
+		slices.Sort(d)
+		if _, err := tx.Exec(`INSERT INTO command_stats (command, runs, failures, p50_ms, p95_ms, through_id)
+			VALUES (?, ?, ?, ?, ?, ?)`,
+			base, u.Runs, u.Failures, percentile(d, 50), percentile(d, 95), latest); err != nil {
+			return fmt.Errorf("refresh command stats: %w", err)
+		}
+	}
+	return tx.Commit()
+}
+
+// percentile is the nearest-rank pth percentile of sorted.
+func percentile(sorted []int64, p int) int64 {
+	if len(sorted) == 0 {
+		return 0
+	}
+	rank := (p*len(sorted) + 99) / 100
+	return sorted[max(rank, 1)-1]
+}
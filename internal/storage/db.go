@@ -2,6 +2,7 @@ package storage
 
 import (
 	"database/sql"
+	"encoding/json"
 	"fmt"
 	"os"
 	"path/filepath"
@@ -10,28 +11,37 @@ import (
 )
 
 func InitDB() (*sql.DB, error) {
-	var dbPath string
+	dbPath, err := DBPath()
+	if err != nil {
+		return nil, err
+	}
+	return OpenDB(dbPath)
+}
+
+// DBPath is where InitDB keeps the history database, creating its
+// directory: DEV_CLI_LOG_DIR, or ~/.devlogs.
+func DBPath() (string, error) {
 	if envDir := os.Getenv("DEV_CLI_LOG_DIR"); envDir != "" {
 		if err := os.MkdirAll(envDir, 0755); err != nil {
-			return nil, fmt.Errorf("create log dir: %w", err)
-		}
-		dbPath = filepath.Join(envDir, "history.db")
-	} else {
-		home, err := os.UserHomeDir()
-		if err != nil {
-			return nil, fmt.Errorf("get user home dir: %w", err)
+			return "", fmt.Errorf("create log dir: %w", err)
 		}
-		dir := filepath.Join(home, ".devlogs")
-		if err := os.MkdirAll(dir, 0755); err != nil {
-			return nil, fmt.Errorf("create data dir: %w", err)
-		}
-		dbPath = filepath.Join(dir, "history.db")
+		return filepath.Join(envDir, "history.db"), nil
 	}
-	return OpenDB(dbPath)
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("get user home dir: %w", err)
+	}
+	dir := filepath.Join(home, ".devlogs")
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return "", fmt.Errorf("create data dir: %w", err)
+	}
+	return filepath.Join(dir, "history.db"), nil
 }
 
 func OpenDB(path string) (*sql.DB, error) {
-	db, err := sql.Open("sqlite", path)
+	// Workflow runs write checkpoints from their own goroutines, so
+	// readers and writers wait on the lock instead of failing SQLITE_BUSY.
+	db, err := sql.Open("sqlite", path+"?_pragma=busy_timeout(5000)")
 	if err != nil {
 		return nil, fmt.Errorf("open db: %w", err)
 	}
@@ -94,6 +104,16 @@ func migrate(db *sql.DB) error {
 		FOREIGN KEY (run_id) REFERENCES workflow_runs(id)
 	);
 
+	-- Workflow files run on a cron schedule by workflow daemon
+	CREATE TABLE IF NOT EXISTS workflow_schedules (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		cron TEXT NOT NULL,
+		path TEXT NOT NULL,
+		created_at INTEGER NOT NULL,
+		last_run INTEGER,
+		last_run_id TEXT
+	);
+
 	CREATE INDEX IF NOT EXISTS idx_workflow_runs_status ON workflow_runs(status);
 	CREATE INDEX IF NOT EXISTS idx_step_results_run_id ON workflow_step_results(run_id);
 
@@ -131,6 +151,66 @@ func migrate(db *sql.DB) error {
 		detected_time INTEGER
 	);
 
+	-- Project documentation chunks for retrieval (RAG)
+	CREATE TABLE IF NOT EXISTS doc_chunks (
+		project TEXT NOT NULL,
+		path TEXT NOT NULL,
+		chunk INTEGER NOT NULL,
+		content TEXT NOT NULL,
+		embedding BLOB,
+		mod_time INTEGER,
+		PRIMARY KEY (project, path, chunk)
+	);
+
+	-- Agent blocks pinned across sessions
+	CREATE TABLE IF NOT EXISTS bookmarks (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		created_at INTEGER NOT NULL,
+		kind TEXT NOT NULL,
+		command TEXT NOT NULL,
+		output TEXT,
+		exit_code INTEGER,
+		directory TEXT
+	);
+
+	-- Commands run to fix an error, and how often they worked
+	CREATE TABLE IF NOT EXISTS solutions (
+		error_signature TEXT NOT NULL,
+		command TEXT NOT NULL,
+		successes INTEGER NOT NULL DEFAULT 0,
+		failures INTEGER NOT NULL DEFAULT 0,
+		last_used INTEGER NOT NULL,
+		PRIMARY KEY (error_signature, command)
+	);
+
+	-- Runs, failures and durations per base command, recomputed from
+	-- history through through_id
+	CREATE TABLE IF NOT EXISTS command_stats (
+		command TEXT PRIMARY KEY,
+		runs INTEGER NOT NULL,
+		failures INTEGER NOT NULL,
+		p50_ms INTEGER NOT NULL,
+		p95_ms INTEGER NOT NULL,
+		through_id INTEGER NOT NULL
+	);
+
+	-- Complete outputs too long for history's details, zstd-compressed
+	CREATE TABLE IF NOT EXISTS outputs (
+		history_id INTEGER PRIMARY KEY,
+		size INTEGER NOT NULL,
+		data BLOB NOT NULL,
+		FOREIGN KEY (history_id) REFERENCES history(id)
+	);
+
+	-- TUI launches and hooked shells, whose commands carry their ID
+	CREATE TABLE IF NOT EXISTS sessions (
+		id TEXT PRIMARY KEY,
+		source TEXT NOT NULL,
+		directory TEXT,
+		started_at INTEGER NOT NULL,
+		ended_at INTEGER
+	);
+
 	CREATE INDEX IF NOT EXISTS idx_root_cause_signature ON root_causes(error_signature);
 	CREATE INDEX IF NOT EXISTS idx_root_cause_history ON root_causes(history_item_id);
 	CREATE INDEX IF NOT EXISTS idx_runbook_project ON runbooks(project_id);
@@ -144,6 +224,76 @@ func migrate(db *sql.DB) error {
 	}
 
 	_, _ = db.Exec("ALTER TABLE history ADD COLUMN resolution TEXT")
+	_, _ = db.Exec("ALTER TABLE project_fingerprints ADD COLUMN detected_files TEXT")
+	_, _ = db.Exec("ALTER TABLE history ADD COLUMN error_signature TEXT")
+	_, _ = db.Exec("ALTER TABLE history ADD COLUMN repeats INTEGER NOT NULL DEFAULT 1")
+	_, _ = db.Exec("ALTER TABLE workflow_runs ADD COLUMN vars TEXT")
+	if _, err := db.Exec("CREATE INDEX IF NOT EXISTS idx_history_signature ON history(error_signature)"); err != nil {
+		return err
+	}
+
+	return resign(db)
+}
+
+// resign signs the failures in history, and the root causes diagnosed
+// from them, with the current GenerateErrorSignature, once per
+// signatureVersion. Root causes without a history item keep theirs.
+func resign(db *sql.DB) error {
+	var version int
+	if err := db.QueryRow("PRAGMA user_version").Scan(&version); err != nil {
+		return err
+	}
+	if version >= signatureVersion {
+		return nil
+	}
+
+	rows, err := db.Query(`SELECT h.id, h.command, h.exit_code, h.details, o.data
+		FROM history h LEFT JOIN outputs o ON o.history_id = h.id WHERE h.exit_code != 0`)
+	if err != nil {
+		return err
+	}
+	signatures := make(map[int64]string)
+	for rows.Next() {
+		var id int64
+		var command string
+		var exitCode int
+		var details sql.NullString
+		var blob []byte
+		if err := rows.Scan(&id, &command, &exitCode, &details, &blob); err != nil {
+			rows.Close()
+			return err
+		}
+		var d historyDetails
+		_ = json.Unmarshal([]byte(details.String), &d)
+		if blob != nil {
+			if out, err := decodeOutput(blob); err == nil {
+				d.Output = out
+			}
+		}
+		signatures[id] = GenerateErrorSignature(command, exitCode, d.Output)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
 
-	return nil
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	for id, signature := range signatures {
+		if _, err := tx.Exec(`UPDATE history SET error_signature = ? WHERE id = ?`, signature, id); err != nil {
+			return err
+		}
+	}
+	if _, err := tx.Exec(`UPDATE root_causes SET error_signature =
+		(SELECT h.error_signature FROM history h WHERE h.id = root_causes.history_item_id)
+		WHERE history_item_id IN (SELECT id FROM history WHERE error_signature IS NOT NULL)`); err != nil {
+		return err
+	}
+	if _, err := tx.Exec(fmt.Sprintf("PRAGMA user_version = %d", signatureVersion)); err != nil {
+		return err
+	}
+	return tx.Commit()
 }
@@ -3,8 +3,11 @@ package storage
 import (
 	"os"
 	"path/filepath"
+	"slices"
+	"strings"
 	"testing"
 	"time"
+	"unicode/utf8"
 )
 
 func TestStorage(t *testing.T) {
@@ -141,3 +144,562 @@ func TestResolutionTracking(t *testing.T) {
 		t.Error("Expected error for invalid ID, got nil")
 	}
 }
+
+func TestGetHistorySince(t *testing.T) {
+	db, err := OpenDB(filepath.Join(t.TempDir(), "history.db"))
+	if err != nil {
+		t.Fatalf("OpenDB failed: %v", err)
+	}
+	defer db.Close()
+
+	now := time.Now()
+	for _, e := range []LogEntry{
+		{Command: "make old", Timestamp: now.Add(-5 * time.Hour).Format(time.RFC3339)},
+		{Command: "go build", Timestamp: now.Add(-90 * time.Minute).Format(time.RFC3339)},
+		{Command: "go test", ExitCode: 1, Timestamp: now.Add(-10 * time.Minute).Format(time.RFC3339)},
+	} {
+		if err := SaveCommand(db, e); err != nil {
+			t.Fatalf("SaveCommand failed: %v", err)
+		}
+	}
+
+	items, err := GetHistorySince(db, 2*time.Hour)
+	if err != nil {
+		t.Fatalf("GetHistorySince failed: %v", err)
+	}
+	if len(items) != 2 {
+		t.Fatalf("expected 2 items within 2h, got %d", len(items))
+	}
+	if items[0].Command != "go build" || items[1].Command != "go test" {
+		t.Errorf("expected oldest first, got %q then %q", items[0].Command, items[1].Command)
+	}
+}
+
+func TestGetFrequentAndRecentCommands(t *testing.T) {
+	db, err := OpenDB(filepath.Join(t.TempDir(), "history.db"))
+	if err != nil {
+		t.Fatalf("OpenDB failed: %v", err)
+	}
+	defer db.Close()
+
+	for _, command := range []string{"go test ./...", "git status", "go test ./...", "make", "git status", "go test ./..."} {
+		if err := SaveCommand(db, LogEntry{Command: command}); err != nil {
+			t.Fatalf("SaveCommand failed: %v", err)
+		}
+	}
+
+	commands, err := GetFrequentCommands(db, 10)
+	if err != nil {
+		t.Fatalf("GetFrequentCommands failed: %v", err)
+	}
+	want := []CommandFrequency{{"go test ./...", 3}, {"git status", 2}, {"make", 1}}
+	if !slices.Equal(commands, want) {
+		t.Errorf("GetFrequentCommands = %v, want %v", commands, want)
+	}
+
+	recent, err := GetRecentCommands(db, 2)
+	if err != nil {
+		t.Fatalf("GetRecentCommands failed: %v", err)
+	}
+	if want := []string{"go test ./...", "git status"}; !slices.Equal(recent, want) {
+		t.Errorf("GetRecentCommands = %q, want %q", recent, want)
+	}
+}
+
+func TestQueryHistory(t *testing.T) {
+	db, err := OpenDB(filepath.Join(t.TempDir(), "history.db"))
+	if err != nil {
+		t.Fatalf("OpenDB failed: %v", err)
+	}
+	defer db.Close()
+
+	now := time.Now()
+	for _, e := range []LogEntry{
+		{Command: "make older", Cwd: "/srv/api-v2", SessionID: "s3", Timestamp: now.Add(-5 * 24 * time.Hour).Format(time.RFC3339)},
+		{Command: "go vet", Cwd: "/srv/api/cmd", SessionID: "s3", Timestamp: now.Add(-4 * 24 * time.Hour).Format(time.RFC3339)},
+		{Command: "make old", Cwd: "/srv/api", SessionID: "s1", Timestamp: now.Add(-3 * 24 * time.Hour).Format(time.RFC3339)},
+		{Command: "go build", Cwd: "/srv/api", SessionID: "s2", Timestamp: now.Add(-2 * time.Hour).Format(time.RFC3339)},
+		{Command: "go test", ExitCode: 1, Cwd: "/srv/api", SessionID: "s2", Timestamp: now.Add(-10 * time.Minute).Format(time.RFC3339)},
+		{Command: "npm test", ExitCode: 1, Cwd: "/srv/web", SessionID: "s2", Timestamp: now.Add(-5 * time.Minute).Format(time.RFC3339)},
+	} {
+		if err := SaveCommand(db, e); err != nil {
+			t.Fatalf("SaveCommand failed: %v", err)
+		}
+	}
+
+	tests := []struct {
+		name   string
+		filter HistoryFilter
+		want   []string
+	}{
+		{"everything", HistoryFilter{}, []string{"npm test", "go test", "go build", "make old", "go vet", "make older"}},
+		{"limit", HistoryFilter{Limit: 2}, []string{"npm test", "go test"}},
+		{"failed", HistoryFilter{Status: FailedExit}, []string{"npm test", "go test"}},
+		{"succeeded", HistoryFilter{Status: SucceededExit}, []string{"go build", "make old", "go vet", "make older"}},
+		{"directory", HistoryFilter{Directory: "/srv/api"}, []string{"go test", "go build", "make old"}},
+		{"project", HistoryFilter{Project: "/srv/api"}, []string{"go test", "go build", "make old", "go vet"}},
+		{"session", HistoryFilter{SessionID: "s1"}, []string{"make old"}},
+		{"since", HistoryFilter{Since: 24 * time.Hour}, []string{"npm test", "go test", "go build"}},
+		{"combined", HistoryFilter{Status: FailedExit, Directory: "/srv/api", Since: time.Hour}, []string{"go test"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			items, err := QueryHistory(db, tt.filter)
+			if err != nil {
+				t.Fatalf("QueryHistory failed: %v", err)
+			}
+			var got []string
+			for _, item := range items {
+				got = append(got, item.Command)
+				if tt.filter.Limit == 0 && !tt.filter.Matches(item, time.Now()) {
+					t.Errorf("Matches rejects %q, which the query returned", item.Command)
+				}
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("QueryHistory = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGetHistoryStats(t *testing.T) {
+	db, err := OpenDB(filepath.Join(t.TempDir(), "history.db"))
+	if err != nil {
+		t.Fatalf("OpenDB failed: %v", err)
+	}
+	defer db.Close()
+
+	at := time.Date(2026, 3, 2, 14, 30, 0, 0, time.Local)
+	for _, e := range []LogEntry{
+		{Command: "go test ./...", ExitCode: 1, DurationMs: 3000},
+		{Command: "go test ./...", DurationMs: 5000},
+		{Command: "go test ./...", ExitCode: 1, DurationMs: 4000},
+		{Command: "make lint", ExitCode: 2, DurationMs: 100},
+		{Command: "make lint", DurationMs: 100},
+		{Command: "make lint", DurationMs: 100},
+		{Command: "make lint", DurationMs: 100},
+		{Command: "npm run dev", ExitCode: 130, DurationMs: 60000},
+		{Command: "npm run dev", ExitCode: 130, DurationMs: 60000},
+		{Command: "npm run dev", ExitCode: 130, DurationMs: 60000},
+		{Command: "rm -rf /tmp/x", ExitCode: 1},
+	} {
+		e.Timestamp = at.Format(time.RFC3339)
+		if err := SaveCommand(db, e); err != nil {
+			t.Fatalf("SaveCommand failed: %v", err)
+		}
+	}
+
+	stats, err := GetHistoryStats(db, HistoryFilter{})
+	if err != nil {
+		t.Fatalf("GetHistoryStats failed: %v", err)
+	}
+	if stats.Total != 11 || stats.Failed != 4 {
+		t.Errorf("expected 11 commands with 4 failed, got %d and %d", stats.Total, stats.Failed)
+	}
+	if stats.Hours[14] != 11 {
+		t.Errorf("expected every command in the 14h bucket, got %v", stats.Hours)
+	}
+
+	if got := stats.MostRun[0]; got.Command != "make lint" || got.Runs != 4 {
+		t.Errorf("expected make lint to be run most, got %+v", got)
+	}
+	var rated []string
+	for _, c := range stats.FailureRates {
+		rated = append(rated, c.Command)
+	}
+	// npm run dev was only interrupted, rm ran too rarely to rank.
+	if !slices.Equal(rated, []string{"go test ./...", "make lint"}) {
+		t.Errorf("FailureRates = %v", rated)
+	}
+	if got := stats.Slowest[0]; got.Command != "npm run dev" || got.AvgDuration() != time.Minute {
+		t.Errorf("expected npm run dev to be slowest at 1m, got %+v", got)
+	}
+	if got := stats.Slowest[1]; got.Command != "go test ./..." || got.AvgDuration() != 4*time.Second {
+		t.Errorf("expected go test to average 4s, got %+v", got)
+	}
+
+	failed, err := GetHistoryStats(db, HistoryFilter{Status: FailedExit})
+	if err != nil {
+		t.Fatalf("GetHistoryStats failed: %v", err)
+	}
+	if failed.Total != 7 {
+		t.Errorf("expected the filter to apply, got %d commands", failed.Total)
+	}
+}
+
+func TestBookmarks(t *testing.T) {
+	db, err := OpenDB(filepath.Join(t.TempDir(), "history.db"))
+	if err != nil {
+		t.Fatalf("OpenDB failed: %v", err)
+	}
+	defer db.Close()
+
+	base := time.Date(2024, 3, 4, 14, 0, 0, 0, time.UTC)
+	first, err := AddBookmark(db, Bookmark{Kind: "command", Command: "go test ./...", Output: "FAIL", ExitCode: 1, Directory: "/src", CreatedAt: base})
+	if err != nil {
+		t.Fatalf("AddBookmark failed: %v", err)
+	}
+	if _, err := AddBookmark(db, Bookmark{Kind: "ai", Command: "why?", Output: "because", CreatedAt: base.Add(time.Minute)}); err != nil {
+		t.Fatalf("AddBookmark failed: %v", err)
+	}
+
+	bookmarks, err := GetBookmarks(db)
+	if err != nil {
+		t.Fatalf("GetBookmarks failed: %v", err)
+	}
+	if len(bookmarks) != 2 || bookmarks[0].ID != first || bookmarks[0].ExitCode != 1 || bookmarks[0].Directory != "/src" || !bookmarks[0].CreatedAt.Equal(base) {
+		t.Fatalf("expected both bookmarks back, oldest first, got %+v", bookmarks)
+	}
+
+	if err := DeleteBookmark(db, first); err != nil {
+		t.Fatalf("DeleteBookmark failed: %v", err)
+	}
+	bookmarks, _ = GetBookmarks(db)
+	if len(bookmarks) != 1 || bookmarks[0].Command != "why?" {
+		t.Errorf("expected only the question left, got %+v", bookmarks)
+	}
+}
+
+func TestSessions(t *testing.T) {
+	db, err := OpenDB(filepath.Join(t.TempDir(), "history.db"))
+	if err != nil {
+		t.Fatalf("OpenDB failed: %v", err)
+	}
+	defer db.Close()
+
+	start := time.Now().Add(-time.Hour).Truncate(time.Second)
+	for _, s := range []Session{
+		{ID: "tui-1", Source: "tui", Directory: "/src", StartedAt: start},
+		{ID: "zsh-1", Source: "zsh", StartedAt: start.Add(time.Minute)},
+		{ID: "idle", Source: "tui", StartedAt: start.Add(2 * time.Minute)},
+	} {
+		if err := StartSession(db, s); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// Starting again keeps the first start.
+	if err := StartSession(db, Session{ID: "tui-1", Source: "tui", StartedAt: start.Add(time.Hour)}); err != nil {
+		t.Fatal(err)
+	}
+
+	for i, e := range []LogEntry{
+		{Command: "make", ExitCode: 2, SessionID: "tui-1"},
+		{Command: "make", SessionID: "tui-1"},
+		{Command: "ls", SessionID: "zsh-1"},
+	} {
+		e.Timestamp = start.Add(time.Duration(i+5) * time.Minute).Format(time.RFC3339)
+		if err := SaveCommand(db, e); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := EndSession(db, "tui-1", start.Add(30*time.Minute)); err != nil {
+		t.Fatal(err)
+	}
+
+	sessions, err := ListSessions(db, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// The idle session ran nothing, so it isn't listed.
+	if len(sessions) != 2 {
+		t.Fatalf("got %d sessions, want 2: %+v", len(sessions), sessions)
+	}
+	zsh, tui := sessions[0], sessions[1]
+	if zsh.ID != "zsh-1" || tui.ID != "tui-1" {
+		t.Fatalf("sessions not newest first: %s, %s", zsh.ID, tui.ID)
+	}
+	if tui.Commands != 2 || tui.Failed != 1 || tui.Directory != "/src" || !tui.StartedAt.Equal(start) {
+		t.Errorf("tui session = %+v", tui)
+	}
+	if got := tui.LastSeen(); !got.Equal(start.Add(30 * time.Minute)) {
+		t.Errorf("tui LastSeen = %v, want its end", got)
+	}
+	if !zsh.EndedAt.IsZero() || !zsh.LastSeen().Equal(start.Add(7*time.Minute)) {
+		t.Errorf("zsh session = %+v, want it open and last seen at its command", zsh)
+	}
+}
+
+func TestProjectFailures(t *testing.T) {
+	db, err := OpenDB(filepath.Join(t.TempDir(), "history.db"))
+	if err != nil {
+		t.Fatalf("OpenDB failed: %v", err)
+	}
+	defer db.Close()
+
+	start := time.Now().Add(-time.Hour).Truncate(time.Second)
+	for i, e := range []LogEntry{
+		{Command: "make", ExitCode: 2, Cwd: "/srv/api", SessionID: "s1"},
+		{Command: "go mod tidy", Cwd: "/srv/api", SessionID: "s1"},
+		{Command: "make", ExitCode: 2, Cwd: "/srv/api/cmd", SessionID: "s2"},
+		{Command: "make", ExitCode: 2, Cwd: "/srv/web", SessionID: "s2"},
+		{Command: "make", ExitCode: 2, Cwd: "/srv/api", SessionID: "s3"},
+	} {
+		e.Timestamp = start.Add(time.Duration(i) * time.Minute).Format(time.RFC3339)
+		if err := SaveCommand(db, e); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := MarkResolution(db, 1, "solution"); err != nil {
+		t.Fatal(err)
+	}
+
+	// The failure at minute 4 is the one being looked up; the one in
+	// /srv/web belongs to another project.
+	h, err := ProjectFailures(db, "/srv/api", "make", start.Add(4*time.Minute))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if h.Failures != 2 || h.Fix != "go mod tidy" {
+		t.Errorf("ProjectFailures = %+v, want 2 failures fixed by go mod tidy", h)
+	}
+
+	h, err = ProjectFailures(db, "/srv/web", "make", start.Add(4*time.Minute))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if h.Failures != 1 || h.Fix != "" {
+		t.Errorf("ProjectFailures in /srv/web = %+v, want 1 failure without a fix", h)
+	}
+}
+
+func TestSaveCommand_LargeOutput(t *testing.T) {
+	db, err := OpenDB(filepath.Join(t.TempDir(), "history.db"))
+	if err != nil {
+		t.Fatalf("OpenDB failed: %v", err)
+	}
+	defer db.Close()
+
+	long := "Error: first line\n" + strings.Repeat("building step ...\n", 2000) + "FAIL: last line\n"
+	for _, e := range []LogEntry{
+		{Command: "make", ExitCode: 2, Output: long},
+		{Command: "ls", Output: "a\nb\n"},
+	} {
+		if err := SaveCommand(db, e); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	items, err := QueryHistory(db, HistoryFilter{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	short, big := items[0], items[1]
+	if len(big.Details) > 2*outputInlineLimit || !strings.Contains(big.Details, "FAIL: last line") {
+		t.Errorf("expected the details to keep only the tail, got %d bytes", len(big.Details))
+	}
+	if out, err := GetOutput(db, big); err != nil || out != long {
+		t.Errorf("GetOutput = %d bytes (%v), want the complete %d", len(out), err, len(long))
+	}
+	if out, err := GetOutput(db, short); err != nil || out != "a\nb\n" {
+		t.Errorf("GetOutput of an inlined output = %q (%v)", out, err)
+	}
+
+	var stored int
+	db.QueryRow(`SELECT length(data) FROM outputs WHERE history_id = ?`, big.ID).Scan(&stored)
+	if stored == 0 || stored >= len(long)/10 {
+		t.Errorf("expected the output compressed, stored %d of %d bytes", stored, len(long))
+	}
+	if similar, _ := GetSimilarFailures(db, GenerateErrorSignature("make", 2, long), 1); len(similar) != 1 {
+		t.Error("expected the failure signed from its complete output")
+	}
+}
+
+func TestSaveCommand_OutputCapKeepsTail(t *testing.T) {
+	db, err := OpenDB(filepath.Join(t.TempDir(), "history.db"))
+	if err != nil {
+		t.Fatalf("OpenDB failed: %v", err)
+	}
+	defer db.Close()
+
+	// "é" is two bytes, so an odd cut would land inside it.
+	huge := strings.Repeat("é", MaxOutputSize/2) + "x" + "FAIL: last line\n"
+	if err := SaveCommand(db, LogEntry{Command: "make", ExitCode: 2, Output: huge}); err != nil {
+		t.Fatal(err)
+	}
+	items, err := QueryHistory(db, HistoryFilter{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	out, err := GetOutput(db, items[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(out) > MaxOutputSize || !strings.HasSuffix(out, "FAIL: last line\n") {
+		t.Errorf("GetOutput = %d bytes, want at most %d ending in the tail", len(out), MaxOutputSize)
+	}
+	if !utf8.ValidString(out) || !utf8.ValidString(items[0].Details) {
+		t.Error("expected the output cut on a rune boundary")
+	}
+}
+
+func TestTailOutput(t *testing.T) {
+	for _, tt := range []struct {
+		in    string
+		limit int
+		want  string
+	}{
+		{"short", 10, "short"},
+		{"abcdef", 3, "def"},
+		{"aéb", 2, "b"},
+		{"aéb", 3, "éb"},
+		{"日本語", 4, "語"},
+	} {
+		if got := TailOutput(tt.in, tt.limit); got != tt.want {
+			t.Errorf("TailOutput(%q, %d) = %q, want %q", tt.in, tt.limit, got, tt.want)
+		}
+	}
+}
+
+func TestSolutions(t *testing.T) {
+	db, err := OpenDB(filepath.Join(t.TempDir(), "history.db"))
+	if err != nil {
+		t.Fatalf("OpenDB failed: %v", err)
+	}
+	defer db.Close()
+
+	sig := GenerateErrorSignature("npm start", 1, "Error: Cannot find module 'express'")
+	record := func(command string, ok bool, times int) {
+		for range times {
+			increment := IncrementSolutionFailure
+			if ok {
+				increment = IncrementSolutionSuccess
+			}
+			if err := increment(db, sig, command); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+	record("npm install", true, 1)
+	record("npm install", false, 1)
+	record("npm ci", true, 4)
+	record("rm -rf node_modules", false, 2)
+	record("npm install express", true, 1)
+
+	solutions, err := GetSolutionsForError(db, sig, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	for _, s := range solutions {
+		got = append(got, s.Command)
+	}
+	want := []string{"npm ci", "npm install express", "npm install", "rm -rf node_modules"}
+	if !slices.Equal(got, want) {
+		t.Errorf("GetSolutionsForError = %v, want %v", got, want)
+	}
+	if s := solutions[0]; s.Successes != 4 || s.Failures != 0 {
+		t.Errorf("npm ci = %+v, want 4 successes", s)
+	}
+
+	// Outcomes from long ago barely count: the score drifts back to 0.5.
+	old := Solution{Successes: 4, LastUsed: time.Now().Add(-365 * 24 * time.Hour)}
+	if score := old.Score(time.Now()); score < 0.5 || score > 0.51 {
+		t.Errorf("a year-old solution scores %.3f, want about 0.5", score)
+	}
+	if limited, _ := GetSolutionsForError(db, sig, 1); len(limited) != 1 {
+		t.Errorf("expected the limit to apply, got %d", len(limited))
+	}
+}
+
+func TestCommandUsage(t *testing.T) {
+	db, err := OpenDB(filepath.Join(t.TempDir(), "history.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	for i, d := range []int64{100, 200, 300, 400, 5000} {
+		exit := 0
+		if i == 4 {
+			exit = 1
+		}
+		SaveCommand(db, LogEntry{Command: "go test ./...", ExitCode: exit, DurationMs: d})
+	}
+	SaveCommand(db, LogEntry{Command: "CGO_ENABLED=0 /usr/local/go/bin/go build", DurationMs: 900})
+	SaveCommand(db, LogEntry{Command: "sudo ls /root", ExitCode: 130})
+
+	usage, err := GetCommandUsage(db, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(usage) != 2 || usage[0].Command != "go" || usage[1].Command != "ls" {
+		t.Fatalf("GetCommandUsage = %+v, want go then ls", usage)
+	}
+	if u := usage[0]; u.Runs != 6 || u.Failures != 1 || u.P50 != 300*time.Millisecond || u.P95 != 5*time.Second {
+		t.Errorf("go = %+v, want 6 runs, 1 failure, p50 300ms, p95 5s", u)
+	}
+	if usage[1].Failures != 0 {
+		t.Errorf("expected an interrupted command not to count as failed, got %+v", usage[1])
+	}
+
+	// A new command shows up in the next read.
+	SaveCommand(db, LogEntry{Command: "ls", ExitCode: 2})
+	ls, ok, err := GetCommandUsageFor(db, "ls -la")
+	if err != nil || !ok || ls.Runs != 2 || ls.FailureRate() != 0.5 {
+		t.Errorf("GetCommandUsageFor(ls) = %+v, %v, %v; want 2 runs, half failed", ls, ok, err)
+	}
+	if _, ok, _ := GetCommandUsageFor(db, "make"); ok {
+		t.Error("expected no usage for a command never run")
+	}
+}
+
+func TestSaveCommandCollapsing(t *testing.T) {
+	db, err := OpenDB(filepath.Join(t.TempDir(), "history.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	start := time.Date(2025, 3, 1, 10, 0, 0, 0, time.UTC)
+	save := func(command string, exit int, after time.Duration, output string) {
+		t.Helper()
+		err := SaveCommandCollapsing(db, LogEntry{
+			Command: command, ExitCode: exit, Cwd: "/src/app", SessionID: "s1", Output: output,
+			DurationMs: int64(after / time.Millisecond), Timestamp: start.Add(after).Format(time.RFC3339),
+		}, 10*time.Second)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	save("curl localhost:8080/health", 7, 0, "connection refused")
+	save("curl localhost:8080/health", 7, 5*time.Second, "connection refused")
+	GetCommandUsage(db, 10) // stats computed before the next repeat
+	save("curl localhost:8080/health", 7, 12*time.Second, strings.Repeat("refused\n", 1000))
+	save("curl localhost:8080/health", 0, 14*time.Second, "ok") // exit code differs
+	save("curl localhost:8080/health", 0, 40*time.Second, "ok") // outside the window
+	save("curl localhost:8080/health", 0, 45*time.Second, "ok") // repeats the one before
+	save("make build", 0, 46*time.Second, "")
+
+	items, err := QueryHistory(db, HistoryFilter{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []int
+	for _, item := range items {
+		got = append(got, item.Repeats)
+	}
+	if want := []int{1, 2, 1, 3}; !slices.Equal(got, want) {
+		t.Fatalf("repeats = %v, want %v", got, want)
+	}
+	failed := items[3]
+	if !failed.Timestamp.Equal(start.Add(12*time.Second)) || failed.DurationMs != 12000 {
+		t.Errorf("expected the collapsed row to describe the latest run, got %+v", failed)
+	}
+	if output, _ := GetOutput(db, failed); output != strings.Repeat("refused\n", 1000) {
+		t.Errorf("expected the latest run's complete output, got %d bytes", len(output))
+	}
+
+	usage, _, err := GetCommandUsageFor(db, "curl")
+	if err != nil || usage.Runs != 6 || usage.Failures != 3 {
+		t.Errorf("expected every repeat counted in the stats, got %+v, %v", usage, err)
+	}
+	if stats, _ := GetHistoryStats(db, HistoryFilter{}); stats.Total != 7 || stats.Failed != 3 {
+		t.Errorf("GetHistoryStats = %d total, %d failed; want 7 and 3", stats.Total, stats.Failed)
+	}
+
+	// Without a window every run gets its own row.
+	SaveCommand(db, LogEntry{Command: "make build", Cwd: "/src/app", SessionID: "s1"})
+	if items, _ := QueryHistory(db, HistoryFilter{}); len(items) != 5 {
+		t.Errorf("expected SaveCommand not to collapse, got %d rows", len(items))
+	}
+}
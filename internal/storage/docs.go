@@ -0,0 +1,89 @@
+package storage
+
+import (
+	"database/sql"
+	"encoding/binary"
+	"fmt"
+	"math"
+	"time"
+)
+
+// DocChunk is a piece of project documentation and its embedding, used to
+// ground AI answers in the project's own scripts and conventions.
+type DocChunk struct {
+	Project   string // project root
+	Path      string // relative to Project
+	Index     int
+	Content   string
+	Embedding []float32
+	ModTime   time.Time // of the source file when it was indexed
+}
+
+// ReplaceDocChunks swaps all chunks of one file for a new set.
+func ReplaceDocChunks(db *sql.DB, project, path string, chunks []DocChunk) error {
+	tx, err := db.Begin()
+	if err != nil {
+		return fmt.Errorf("begin: %w", err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(`DELETE FROM doc_chunks WHERE project = ? AND path = ?`, project, path); err != nil {
+		return fmt.Errorf("delete chunks: %w", err)
+	}
+	for _, c := range chunks {
+		_, err := tx.Exec(`INSERT INTO doc_chunks (project, path, chunk, content, embedding, mod_time)
+			VALUES (?, ?, ?, ?, ?, ?)`,
+			project, path, c.Index, c.Content, encodeEmbedding(c.Embedding), c.ModTime.Unix())
+		if err != nil {
+			return fmt.Errorf("insert chunk: %w", err)
+		}
+	}
+	return tx.Commit()
+}
+
+// DeleteDocChunks removes the chunks of a file that no longer exists.
+func DeleteDocChunks(db *sql.DB, project, path string) error {
+	_, err := db.Exec(`DELETE FROM doc_chunks WHERE project = ? AND path = ?`, project, path)
+	return err
+}
+
+// GetDocChunks returns every indexed chunk of a project.
+func GetDocChunks(db *sql.DB, project string) ([]DocChunk, error) {
+	rows, err := db.Query(`SELECT path, chunk, content, embedding, mod_time
+		FROM doc_chunks WHERE project = ? ORDER BY path, chunk`, project)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var chunks []DocChunk
+	for rows.Next() {
+		c := DocChunk{Project: project}
+		var blob []byte
+		var modTime int64
+		if err := rows.Scan(&c.Path, &c.Index, &c.Content, &blob, &modTime); err != nil {
+			return nil, err
+		}
+		c.Embedding = decodeEmbedding(blob)
+		c.ModTime = time.Unix(modTime, 0)
+		chunks = append(chunks, c)
+	}
+	return chunks, rows.Err()
+}
+
+// Embeddings are stored as little-endian float32s.
+func encodeEmbedding(v []float32) []byte {
+	buf := make([]byte, 4*len(v))
+	for i, f := range v {
+		binary.LittleEndian.PutUint32(buf[4*i:], math.Float32bits(f))
+	}
+	return buf
+}
+
+func decodeEmbedding(buf []byte) []float32 {
+	v := make([]float32, len(buf)/4)
+	for i := range v {
+		v[i] = math.Float32frombits(binary.LittleEndian.Uint32(buf[4*i:]))
+	}
+	return v
+}
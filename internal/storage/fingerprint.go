@@ -0,0 +1,174 @@
+package storage
+
+import (
+	"database/sql"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+)
+
+// projectMarker maps a file at a project root to the project type and the
+// package manager it implies. Lockfiles refine the package manager of a
+// type that was already detected.
+type projectMarker struct {
+	File           string
+	ProjectType    string
+	PackageManager string
+}
+
+// projectMarkers are checked in order; the first match sets the project type.
+var projectMarkers = []projectMarker{
+	{"go.mod", "go", "go mod"},
+	{"Cargo.toml", "rust", "cargo"},
+	{"package.json", "nodejs", "npm"},
+	{"pyproject.toml", "python", "pip"},
+	{"requirements.txt", "python", "pip"},
+	{"Pipfile", "python", "pipenv"},
+	{"setup.py", "python", "pip"},
+	{"pom.xml", "java", "maven"},
+	{"build.gradle", "java", "gradle"},
+	{"build.gradle.kts", "java", "gradle"},
+	{"Gemfile", "ruby", "bundler"},
+	{"composer.json", "php", "composer"},
+}
+
+var lockfileManagers = []projectMarker{
+	{"pnpm-lock.yaml", "nodejs", "pnpm"},
+	{"yarn.lock", "nodejs", "yarn"},
+	{"bun.lockb", "nodejs", "bun"},
+	{"poetry.lock", "python", "poetry"},
+	{"uv.lock", "python", "uv"},
+}
+
+// supportFiles are recorded when present but do not decide the type.
+var supportFiles = []string{"Dockerfile", "docker-compose.yml", "compose.yaml", "Makefile"}
+
+// DetectProjectFingerprint walks up from dir to the nearest directory with a
+// project marker and fingerprints it. It returns nil outside any project.
+func DetectProjectFingerprint(dir string) *ProjectFingerprint {
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return nil
+	}
+	var fp *ProjectFingerprint
+	findUp(dir, func(d string) bool {
+		fp = fingerprintDir(d)
+		return fp != nil
+	})
+	return fp
+}
+
+// ProjectRoot is the project dir is in: the nearest directory up from it
+// with a .git, or else with a project marker. Outside any project it is
+// dir itself.
+func ProjectRoot(dir string) string {
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return dir
+	}
+	if root := findUp(dir, func(d string) bool {
+		_, err := os.Stat(filepath.Join(d, ".git"))
+		return err == nil
+	}); root != "" {
+		return root
+	}
+	if fp := DetectProjectFingerprint(dir); fp != nil {
+		return fp.DetectedAt
+	}
+	return dir
+}
+
+// InProject reports whether dir is root or below it.
+func InProject(dir, root string) bool {
+	rel, err := filepath.Rel(root, dir)
+	return err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
+
+// findUp walks up from dir, stopping at the home directory, to the first
+// directory match accepts. It returns "" when none does.
+func findUp(dir string, match func(string) bool) string {
+	home, _ := os.UserHomeDir()
+	for {
+		if match(dir) {
+			return dir
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir || dir == home {
+			return ""
+		}
+		dir = parent
+	}
+}
+
+func fingerprintDir(dir string) *ProjectFingerprint {
+	exists := func(name string) bool {
+		_, err := os.Stat(filepath.Join(dir, name))
+		return err == nil
+	}
+
+	var fp *ProjectFingerprint
+	var files []string
+	for _, m := range projectMarkers {
+		if !exists(m.File) {
+			continue
+		}
+		files = append(files, m.File)
+		if fp == nil {
+			fp = &ProjectFingerprint{ProjectType: m.ProjectType, PackageManager: m.PackageManager}
+		}
+	}
+	if fp == nil {
+		return nil
+	}
+
+	for _, m := range lockfileManagers {
+		if exists(m.File) {
+			files = append(files, m.File)
+			if m.ProjectType == fp.ProjectType {
+				fp.PackageManager = m.PackageManager
+			}
+		}
+	}
+	for _, name := range supportFiles {
+		if exists(name) {
+			files = append(files, name)
+		}
+	}
+
+	fp.ID = hashString(dir)
+	fp.DetectedAt = dir
+	fp.DetectedFiles = files
+	fp.DetectedTime = time.Now()
+	return fp
+}
+
+// LoadProjectFingerprint returns the fingerprint for the project containing
+// dir, detecting it and saving it when it is new or has changed. Learned
+// fields (common issues, runbooks) are kept across re-detection.
+func LoadProjectFingerprint(db *sql.DB, dir string) (*ProjectFingerprint, error) {
+	fp := DetectProjectFingerprint(dir)
+	if fp == nil {
+		return nil, nil
+	}
+
+	stored, err := GetProjectFingerprint(db, fp.DetectedAt)
+	if err != nil {
+		return nil, err
+	}
+	if stored != nil {
+		if stored.ProjectType == fp.ProjectType && stored.PackageManager == fp.PackageManager &&
+			slices.Equal(stored.DetectedFiles, fp.DetectedFiles) {
+			return stored, nil
+		}
+		fp.ID = stored.ID
+		fp.CommonIssues = stored.CommonIssues
+		fp.AssociatedRunbooks = stored.AssociatedRunbooks
+	}
+
+	if err := SaveProjectFingerprint(db, *fp); err != nil {
+		return nil, err
+	}
+	return fp, nil
+}
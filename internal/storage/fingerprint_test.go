@@ -0,0 +1,95 @@
+package storage
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+func TestDetectProjectFingerprint(t *testing.T) {
+	root := t.TempDir()
+	for _, name := range []string{"go.mod", "package.json", "pnpm-lock.yaml", "Makefile"} {
+		if err := os.WriteFile(filepath.Join(root, name), nil, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	sub := filepath.Join(root, "cmd", "server")
+	if err := os.MkdirAll(sub, 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	fp := DetectProjectFingerprint(sub)
+	if fp == nil {
+		t.Fatal("expected a fingerprint from a subdirectory of the project")
+	}
+	if fp.DetectedAt != root {
+		t.Errorf("expected root %s, got %s", root, fp.DetectedAt)
+	}
+	if fp.ProjectType != "go" || fp.PackageManager != "go mod" {
+		t.Errorf("go.mod should win over package.json, got %s/%s", fp.ProjectType, fp.PackageManager)
+	}
+	want := []string{"go.mod", "package.json", "pnpm-lock.yaml", "Makefile"}
+	if !slices.Equal(fp.DetectedFiles, want) {
+		t.Errorf("detected files = %v, want %v", fp.DetectedFiles, want)
+	}
+}
+
+func TestDetectProjectFingerprint_Lockfile(t *testing.T) {
+	root := t.TempDir()
+	os.WriteFile(filepath.Join(root, "package.json"), nil, 0644)
+	os.WriteFile(filepath.Join(root, "yarn.lock"), nil, 0644)
+
+	fp := DetectProjectFingerprint(root)
+	if fp == nil || fp.ProjectType != "nodejs" || fp.PackageManager != "yarn" {
+		t.Fatalf("expected nodejs/yarn, got %+v", fp)
+	}
+}
+
+func TestProjectRoot(t *testing.T) {
+	root := t.TempDir()
+	module := filepath.Join(root, "services", "api")
+	sub := filepath.Join(module, "cmd")
+	if err := os.MkdirAll(sub, 0755); err != nil {
+		t.Fatal(err)
+	}
+	os.WriteFile(filepath.Join(module, "go.mod"), nil, 0644)
+
+	if got := ProjectRoot(sub); got != module {
+		t.Errorf("without git, ProjectRoot = %s, want the go.mod directory %s", got, module)
+	}
+	os.Mkdir(filepath.Join(root, ".git"), 0755)
+	if got := ProjectRoot(sub); got != root {
+		t.Errorf("ProjectRoot = %s, want the git root %s", got, root)
+	}
+	if !InProject(sub, root) || InProject(root+"-other", root) {
+		t.Error("InProject should hold below the root and not for a sibling sharing its prefix")
+	}
+}
+
+func TestLoadProjectFingerprint_KeepsLearnedFields(t *testing.T) {
+	db := setupTestDB(t)
+	root := t.TempDir()
+	os.WriteFile(filepath.Join(root, "go.mod"), nil, 0644)
+
+	fp, err := LoadProjectFingerprint(db, root)
+	if err != nil || fp == nil {
+		t.Fatalf("LoadProjectFingerprint failed: %v", err)
+	}
+	fp.CommonIssues = []string{"undefined: handler"}
+	if err := SaveProjectFingerprint(db, *fp); err != nil {
+		t.Fatal(err)
+	}
+
+	os.WriteFile(filepath.Join(root, "Dockerfile"), nil, 0644)
+	updated, err := LoadProjectFingerprint(db, root)
+	if err != nil {
+		t.Fatalf("reload failed: %v", err)
+	}
+	if !slices.Contains(updated.DetectedFiles, "Dockerfile") {
+		t.Errorf("expected new Dockerfile to be detected, got %v", updated.DetectedFiles)
+	}
+	if len(updated.CommonIssues) != 1 || updated.ID != fp.ID {
+		t.Errorf("learned fields lost on re-detection: %+v", updated)
+	}
+}
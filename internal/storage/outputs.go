@@ -0,0 +1,89 @@
+package storage
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"unicode/utf8"
+
+	"github.com/klauspost/compress/zstd"
+)
+
+// MaxOutputSize bounds the output kept with a command. Longer output keeps
+// its tail, where errors usually are; stored compressed out of line, even
+// that much costs little.
+const MaxOutputSize = 4 << 20
+
+// outputInlineLimit is the most output the details column keeps. Longer
+// output is stored whole, zstd-compressed, in the outputs table, and the
+// details keep its tail, so listing history stays fast while the complete
+// log is there for root cause analysis.
+const outputInlineLimit = 4096
+
+var (
+	outputEncoder, _ = zstd.NewWriter(nil, zstd.WithEncoderLevel(zstd.SpeedDefault))
+	outputDecoder, _ = zstd.NewReader(nil)
+)
+
+// historyDetails is what the details column holds.
+type historyDetails struct {
+	Output    string `json:"output"`
+	Truncated bool   `json:"truncated,omitempty"`
+}
+
+// TailOutput returns at most the last limit bytes of output, starting on
+// a rune boundary.
+func TailOutput(output string, limit int) string {
+	if len(output) <= limit {
+		return output
+	}
+	start := len(output) - limit
+	for start < len(output) && !utf8.RuneStart(output[start]) {
+		start++
+	}
+	return output[start:]
+}
+
+// inlineDetails encodes the details column for output, keeping only its
+// tail when it is too long to inline.
+func inlineDetails(output string) ([]byte, error) {
+	d := historyDetails{Output: output}
+	if len(output) > outputInlineLimit {
+		d.Output, d.Truncated = TailOutput(output, outputInlineLimit), true
+	}
+	return json.Marshal(d)
+}
+
+// saveOutput stores the complete output of a history item compressed.
+func saveOutput(tx *sql.Tx, historyID int64, output string) error {
+	_, err := tx.Exec(`INSERT OR REPLACE INTO outputs (history_id, size, data) VALUES (?, ?, ?)`,
+		historyID, len(output), outputEncoder.EncodeAll([]byte(output), nil))
+	return err
+}
+
+// GetOutput returns the complete output of a history item: the stored
+// blob when the output was too long to inline, else the details' output.
+func GetOutput(db *sql.DB, item HistoryItem) (string, error) {
+	var d historyDetails
+	if item.Details != "" {
+		_ = json.Unmarshal([]byte(item.Details), &d)
+	}
+	if !d.Truncated {
+		return d.Output, nil
+	}
+
+	var data []byte
+	err := db.QueryRow(`SELECT data FROM outputs WHERE history_id = ?`, item.ID).Scan(&data)
+	if errors.Is(err, sql.ErrNoRows) {
+		return d.Output, nil
+	}
+	if err != nil {
+		return "", err
+	}
+	return decodeOutput(data)
+}
+
+func decodeOutput(data []byte) (string, error) {
+	out, err := outputDecoder.DecodeAll(data, nil)
+	return string(out), err
+}
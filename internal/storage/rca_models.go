@@ -3,6 +3,8 @@ package storage
 import (
 	"fmt"
 	"hash/fnv"
+	"regexp"
+	"strings"
 	"time"
 )
 
@@ -50,18 +52,22 @@ type ProjectFingerprint struct {
 	PackageManager     string    `json:"package_manager"`     // "npm", "go mod", "pip", etc.
 	CommonIssues       []string  `json:"common_issues"`       // Frequent error patterns
 	AssociatedRunbooks []string  `json:"associated_runbooks"` // Runbook IDs
+	DetectedFiles      []string  `json:"detected_files"`      // Marker files found at the root
 	DetectedAt         string    `json:"detected_at"`         // Directory path
 	DetectedTime       time.Time `json:"detected_time"`
 }
 
 // GenerateErrorSignature generates a normalized signature for an error.
-// Used for cache lookups and pattern matching.
+// Used for cache lookups and pattern matching. The first line of output is
+// normalized first, so the same error hashes the same whatever paths,
+// PIDs, times or IDs it mentions.
 func GenerateErrorSignature(command string, exitCode int, output string) string {
 
 	firstLine := output
 	if idx := indexOf(output, '\n'); idx > 0 {
 		firstLine = output[:idx]
 	}
+	firstLine = NormalizeErrorLine(firstLine)
 	if len(firstLine) > 100 {
 		firstLine = firstLine[:100]
 	}
@@ -70,6 +76,49 @@ func GenerateErrorSignature(command string, exitCode int, output string) string
 	return hashString(combined)
 }
 
+// signatureVersion changes with GenerateErrorSignature; databases signed
+// with an older one are signed again on open.
+const signatureVersion = 1
+
+// volatilePatterns are the parts of an error line that differ between
+// occurrences of the same error, in the order they are replaced: earlier
+// ones contain what later ones would match.
+var volatilePatterns = []struct {
+	re   *regexp.Regexp
+	with string
+}{
+	{regexp.MustCompile(`[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}`), "<id>"},
+	{regexp.MustCompile(`(?:/private/var/folders|/var/folders|/var/tmp|/tmp)/[^\s'":]*`), "<tmp>"},
+	{regexp.MustCompile(`(?i)[a-z]:\\Users\\[^\\]+\\AppData\\Local\\Temp\\[^\s'":]*`), "<tmp>"},
+	{regexp.MustCompile(`\d{4}-\d{2}-\d{2}[T ]\d{2}:\d{2}:\d{2}(?:\.\d+)?(?:Z|[+-]\d{2}:?\d{2})?`), "<time>"},
+	{regexp.MustCompile(`\b\d{1,2}:\d{2}:\d{2}(?:\.\d+)?\b`), "<time>"},
+	{regexp.MustCompile(`\b(?:\d+(?:\.\d+)?(?:ns|us|µs|ms|s|m|h))+\b`), "<dur>"},
+	{regexp.MustCompile(`\b0x[0-9a-fA-F]+\b`), "<hex>"},
+}
+
+var (
+	hexID  = regexp.MustCompile(`\b[0-9a-fA-F]{7,}\b`)
+	number = regexp.MustCompile(`\d+`)
+)
+
+// NormalizeErrorLine replaces what varies between occurrences of the same
+// error in line: UUIDs, temporary paths, timestamps, durations, hex IDs
+// (container IDs, commit hashes, addresses) and numbers such as PIDs and
+// ports.
+func NormalizeErrorLine(line string) string {
+	for _, p := range volatilePatterns {
+		line = p.re.ReplaceAllString(line, p.with)
+	}
+	line = hexID.ReplaceAllStringFunc(line, func(id string) string {
+		// A run of hex letters alone is a word, like "facade".
+		if strings.ContainsAny(id, "0123456789") {
+			return "<hex>"
+		}
+		return id
+	})
+	return number.ReplaceAllString(line, "<n>")
+}
+
 // indexOf finds the first occurrence of a rune in a string
 func indexOf(s string, r rune) int {
 	for i, c := range s {
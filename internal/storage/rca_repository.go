@@ -179,6 +179,29 @@ func GetRunbooksForProject(db *sql.DB, projectID string) ([]Runbook, error) {
 	return results, nil
 }
 
+// ListRunbooks retrieves every runbook, grouped by project and, within a
+// project, the most successful first.
+func ListRunbooks(db *sql.DB) ([]Runbook, error) {
+	query := `SELECT id, project_id, name, description, steps, success_rate, last_used, usage_count, tags
+		FROM runbooks ORDER BY project_id, success_rate DESC, name`
+
+	rows, err := db.Query(query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var results []Runbook
+	for rows.Next() {
+		rb, err := scanRunbookRow(rows)
+		if err != nil {
+			return nil, err
+		}
+		results = append(results, *rb)
+	}
+	return results, rows.Err()
+}
+
 // UpdateRunbookStats updates a runbook's success rate after execution.
 func UpdateRunbookStats(db *sql.DB, id string, success bool) error {
 
@@ -260,10 +283,14 @@ func SaveProjectFingerprint(db *sql.DB, fp ProjectFingerprint) error {
 	if err != nil {
 		return fmt.Errorf("marshal associated_runbooks: %w", err)
 	}
+	filesJSON, err := json.Marshal(fp.DetectedFiles)
+	if err != nil {
+		return fmt.Errorf("marshal detected_files: %w", err)
+	}
 
 	query := `INSERT OR REPLACE INTO project_fingerprints
-		(id, project_type, package_manager, common_issues, associated_runbooks, detected_at, detected_time)
-		VALUES (?, ?, ?, ?, ?, ?, ?)`
+		(id, project_type, package_manager, common_issues, associated_runbooks, detected_at, detected_time, detected_files)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?)`
 
 	_, err = db.Exec(query,
 		fp.ID,
@@ -273,13 +300,14 @@ func SaveProjectFingerprint(db *sql.DB, fp ProjectFingerprint) error {
 		string(runbooksJSON),
 		fp.DetectedAt,
 		fp.DetectedTime.Unix(),
+		string(filesJSON),
 	)
 	return err
 }
 
 // GetProjectFingerprint retrieves a project fingerprint by directory path.
 func GetProjectFingerprint(db *sql.DB, path string) (*ProjectFingerprint, error) {
-	query := `SELECT id, project_type, package_manager, common_issues, associated_runbooks, detected_at, detected_time
+	query := `SELECT id, project_type, package_manager, common_issues, associated_runbooks, detected_at, detected_time, COALESCE(detected_files, '[]')
 		FROM project_fingerprints WHERE detected_at = ?`
 
 	row := db.QueryRow(query, path)
@@ -288,7 +316,7 @@ func GetProjectFingerprint(db *sql.DB, path string) (*ProjectFingerprint, error)
 
 // GetProjectFingerprintByType retrieves project fingerprints by type.
 func GetProjectFingerprintByType(db *sql.DB, projectType string) ([]ProjectFingerprint, error) {
-	query := `SELECT id, project_type, package_manager, common_issues, associated_runbooks, detected_at, detected_time
+	query := `SELECT id, project_type, package_manager, common_issues, associated_runbooks, detected_at, detected_time, COALESCE(detected_files, '[]')
 		FROM project_fingerprints WHERE project_type = ?`
 
 	rows, err := db.Query(query, projectType)
@@ -311,9 +339,9 @@ func GetProjectFingerprintByType(db *sql.DB, projectType string) ([]ProjectFinge
 func scanProjectFingerprint(row *sql.Row) (*ProjectFingerprint, error) {
 	var fp ProjectFingerprint
 	var detectedTime int64
-	var issuesJSON, runbooksJSON string
+	var issuesJSON, runbooksJSON, filesJSON string
 
-	err := row.Scan(&fp.ID, &fp.ProjectType, &fp.PackageManager, &issuesJSON, &runbooksJSON, &fp.DetectedAt, &detectedTime)
+	err := row.Scan(&fp.ID, &fp.ProjectType, &fp.PackageManager, &issuesJSON, &runbooksJSON, &fp.DetectedAt, &detectedTime, &filesJSON)
 	if err == sql.ErrNoRows {
 		return nil, nil
 	}
@@ -328,6 +356,9 @@ func scanProjectFingerprint(row *sql.Row) (*ProjectFingerprint, error) {
 	if err := json.Unmarshal([]byte(runbooksJSON), &fp.AssociatedRunbooks); err != nil {
 		fp.AssociatedRunbooks = []string{}
 	}
+	if err := json.Unmarshal([]byte(filesJSON), &fp.DetectedFiles); err != nil {
+		fp.DetectedFiles = []string{}
+	}
 
 	return &fp, nil
 }
@@ -335,9 +366,9 @@ func scanProjectFingerprint(row *sql.Row) (*ProjectFingerprint, error) {
 func scanProjectFingerprintRow(rows *sql.Rows) (*ProjectFingerprint, error) {
 	var fp ProjectFingerprint
 	var detectedTime int64
-	var issuesJSON, runbooksJSON string
+	var issuesJSON, runbooksJSON, filesJSON string
 
-	err := rows.Scan(&fp.ID, &fp.ProjectType, &fp.PackageManager, &issuesJSON, &runbooksJSON, &fp.DetectedAt, &detectedTime)
+	err := rows.Scan(&fp.ID, &fp.ProjectType, &fp.PackageManager, &issuesJSON, &runbooksJSON, &fp.DetectedAt, &detectedTime, &filesJSON)
 	if err != nil {
 		return nil, err
 	}
@@ -349,6 +380,9 @@ func scanProjectFingerprintRow(rows *sql.Rows) (*ProjectFingerprint, error) {
 	if err := json.Unmarshal([]byte(runbooksJSON), &fp.AssociatedRunbooks); err != nil {
 		fp.AssociatedRunbooks = []string{}
 	}
+	if err := json.Unmarshal([]byte(filesJSON), &fp.DetectedFiles); err != nil {
+		fp.DetectedFiles = []string{}
+	}
 
 	return &fp, nil
 }
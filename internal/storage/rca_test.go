@@ -2,7 +2,10 @@ package storage
 
 import (
 	"database/sql"
+	"fmt"
 	"os"
+	"path/filepath"
+	"strings"
 	"testing"
 	"time"
 )
@@ -191,4 +194,97 @@ func TestErrorSignature(t *testing.T) {
 	if sig1 == sig3 {
 		t.Error("different errors should produce different signatures")
 	}
+
+	same := [][2]string{
+		{"Error: listen tcp :3000: bind: address already in use", "Error: listen tcp :8080: bind: address already in use"},
+		{"open /tmp/go-build123/b001/main: permission denied", "open /tmp/go-build987/b001/main: permission denied"},
+		{"2024-03-01T10:04:05Z container 3f2a1b9c8d7e exited after 1.5s", "2025-11-20T08:00:59.123+02:00 container 9e8d7c6b5a4f exited after 2m3s"},
+		{"panic: runtime error at 0xc000123 (pid 4242)", "panic: runtime error at 0xdeadbeef (pid 7)"},
+		{"job 123e4567-e89b-12d3-a456-426614174000 failed", "job 00000000-0000-4000-8000-000000000000 failed"},
+	}
+	for _, pair := range same {
+		if GenerateErrorSignature("run", 1, pair[0]) != GenerateErrorSignature("run", 1, pair[1]) {
+			t.Errorf("expected one signature for %q and %q, normalized %q and %q",
+				pair[0], pair[1], NormalizeErrorLine(pair[0]), NormalizeErrorLine(pair[1]))
+		}
+	}
+	if got := NormalizeErrorLine("cannot find package facade"); got != "cannot find package facade" {
+		t.Errorf("words of hex letters should stay, got %q", got)
+	}
+}
+
+func TestGetSimilarFailures_Resigns(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "history.db")
+	db, err := OpenDB(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// A database from before normalization: signatures of the raw line.
+	for i, output := range []string{"dial tcp 10.0.0.7:5432: connection refused", "dial tcp 10.0.0.9:5432: connection refused"} {
+		if _, err := db.Exec(`INSERT INTO history (timestamp, command, exit_code, duration_ms, directory, session_id, details, error_signature) VALUES (?, 'psql', 2, 0, '', '', ?, ?)`,
+			time.Now().Unix(), `{"output":"`+output+`"}`, hashString(output)); err != nil {
+			t.Fatal(err)
+		}
+		if err := SaveRootCause(db, RootCause{ID: fmt.Sprintf("rc-%d", i), ErrorSignature: hashString(output), Timestamp: time.Now(), HistoryItemID: int64(i + 1)}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	SaveRootCause(db, RootCause{ID: "orphan", ErrorSignature: "old", Timestamp: time.Now()})
+	db.Exec("PRAGMA user_version = 0")
+	db.Close()
+
+	db, err = OpenDB(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	signature := GenerateErrorSignature("psql", 2, "dial tcp 10.0.0.1:5432: connection refused")
+	similar, err := GetSimilarFailures(db, signature, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(similar) != 2 || similar[0].ID != 2 {
+		t.Errorf("expected both failures, newest first, got %+v", similar)
+	}
+	if rc, _ := GetRootCauseBySignature(db, signature); rc == nil {
+		t.Error("expected the root causes to be signed again")
+	}
+	if rc, _ := GetRootCauseByID(db, "orphan"); rc == nil || rc.ErrorSignature != "old" {
+		t.Errorf("a root cause without a history item should keep its signature, got %+v", rc)
+	}
+
+	// New failures are signed as they are saved.
+	if err := SaveCommand(db, LogEntry{Command: "psql", ExitCode: 2, Output: "dial tcp 10.0.0.3:5432: connection refused"}); err != nil {
+		t.Fatal(err)
+	}
+	if similar, _ := GetSimilarFailures(db, signature, 10); len(similar) != 3 {
+		t.Errorf("expected the new failure to match too, got %d", len(similar))
+	}
+}
+
+func TestListRunbooks(t *testing.T) {
+	db := setupTestDB(t)
+
+	for _, rb := range []Runbook{
+		{ID: "rb-1", ProjectID: "web", Name: "Rebuild", SuccessRate: 0.5},
+		{ID: "rb-2", ProjectID: "api", Name: "Reset DB", SuccessRate: 0.2},
+		{ID: "rb-3", ProjectID: "web", Name: "Clear cache", SuccessRate: 0.9},
+	} {
+		if err := SaveRunbook(db, rb); err != nil {
+			t.Fatalf("SaveRunbook failed: %v", err)
+		}
+	}
+
+	runbooks, err := ListRunbooks(db)
+	if err != nil {
+		t.Fatalf("ListRunbooks failed: %v", err)
+	}
+	var ids []string
+	for _, rb := range runbooks {
+		ids = append(ids, rb.ID)
+	}
+	if want := []string{"rb-2", "rb-3", "rb-1"}; strings.Join(ids, ",") != strings.Join(want, ",") {
+		t.Errorf("expected %v, got %v", want, ids)
+	}
 }
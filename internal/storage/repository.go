@@ -2,8 +2,8 @@ package storage
 
 import (
 	"database/sql"
-	"encoding/json"
 	"fmt"
+	"path/filepath"
 	"strings"
 	"time"
 )
@@ -29,28 +29,108 @@ type HistoryItem struct {
 	SessionID  string
 	Details    string // Raw JSON
 	Resolution string // "solution", "unrelated", "skipped", or "" (empty)
+	// Repeats is how many consecutive identical runs the row stands for
+	// (see SaveCommandCollapsing); the rest of it describes the latest.
+	Repeats int
 }
 
 func SaveCommand(db *sql.DB, entry LogEntry) error {
+	return SaveCommandCollapsing(db, entry, 0)
+}
+
+// SaveCommandCollapsing saves entry like SaveCommand, except that when the
+// latest command of its session is the same command, run in the same
+// directory with the same exit code at most window earlier, the run is
+// folded into that row: its Repeats goes up and its time, duration and
+// output become entry's. This keeps watch-style loops from flooding the
+// history. A window of 0 always adds a row.
+func SaveCommandCollapsing(db *sql.DB, entry LogEntry, window time.Duration) error {
 	ts, err := time.Parse(time.RFC3339, entry.Timestamp)
 	if err != nil {
 		ts = time.Now()
 	}
+	entry.Output = TailOutput(entry.Output, MaxOutputSize)
+
+	details, err := inlineDetails(entry.Output)
+	if err != nil {
+		return fmt.Errorf("marshal details: %w", err)
+	}
 
-	detailsMap := map[string]interface{}{
-		"output": entry.Output,
+	var signature sql.NullString
+	if entry.ExitCode != 0 {
+		signature.String, signature.Valid = GenerateErrorSignature(entry.Command, entry.ExitCode, entry.Output), true
 	}
 
-	detailsJSON, err := json.Marshal(detailsMap)
+	tx, err := db.Begin()
 	if err != nil {
-		return fmt.Errorf("marshal details: %w", err)
+		return err
+	}
+	defer tx.Rollback()
+
+	if window > 0 {
+		id, err := repeatOf(tx, entry, ts, window)
+		if err != nil {
+			return err
+		}
+		if id != 0 {
+			if _, err := tx.Exec(`UPDATE history SET timestamp = ?, duration_ms = ?, details = ?, error_signature = ?, repeats = repeats + 1
+				WHERE id = ?`, ts.Unix(), entry.DurationMs, string(details), signature, id); err != nil {
+				return err
+			}
+			if len(entry.Output) > outputInlineLimit {
+				err = saveOutput(tx, id, entry.Output)
+			} else {
+				_, err = tx.Exec(`DELETE FROM outputs WHERE history_id = ?`, id)
+			}
+			if err != nil {
+				return fmt.Errorf("save output: %w", err)
+			}
+			// The row's ID stays, so the stats can't tell they are stale.
+			if _, err := tx.Exec(`DELETE FROM command_stats`); err != nil {
+				return err
+			}
+			return tx.Commit()
+		}
 	}
 
-	query := `INSERT INTO history (timestamp, command, exit_code, duration_ms, directory, session_id, details)
-			  VALUES (?, ?, ?, ?, ?, ?, ?)`
+	query := `INSERT INTO history (timestamp, command, exit_code, duration_ms, directory, session_id, details, error_signature)
+			  VALUES (?, ?, ?, ?, ?, ?, ?, ?)`
 
-	_, err = db.Exec(query, ts.Unix(), entry.Command, entry.ExitCode, entry.DurationMs, entry.Cwd, entry.SessionID, string(detailsJSON))
-	return err
+	result, err := tx.Exec(query, ts.Unix(), entry.Command, entry.ExitCode, entry.DurationMs, entry.Cwd, entry.SessionID, string(details), signature)
+	if err != nil {
+		return err
+	}
+	if len(entry.Output) > outputInlineLimit {
+		id, err := result.LastInsertId()
+		if err != nil {
+			return err
+		}
+		if err := saveOutput(tx, id, entry.Output); err != nil {
+			return fmt.Errorf("save output: %w", err)
+		}
+	}
+	return tx.Commit()
+}
+
+// repeatOf returns the ID of the row a run of entry at ts repeats within
+// window, or 0 if it is not a repeat.
+func repeatOf(tx *sql.Tx, entry LogEntry, ts time.Time, window time.Duration) (int64, error) {
+	var id, last int64
+	var command, directory string
+	var exitCode int
+	err := tx.QueryRow(`SELECT id, timestamp, command, exit_code, directory FROM history
+		WHERE session_id = ? ORDER BY id DESC LIMIT 1`, entry.SessionID).Scan(&id, &last, &command, &exitCode, &directory)
+	if err == sql.ErrNoRows {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+	since := ts.Sub(time.Unix(last, 0))
+	if command != entry.Command || directory != entry.Cwd || exitCode != entry.ExitCode || since < 0 || since > window {
+		return 0, nil
+	}
+	return id, nil
 }
 
 func GetRecentHistory(db *sql.DB, limit int) ([]HistoryItem, error) {
@@ -76,6 +156,142 @@ func GetRecentHistory(db *sql.DB, limit int) ([]HistoryItem, error) {
 	return items, nil
 }
 
+// ExitStatus narrows history to failed or successful commands.
+type ExitStatus int
+
+const (
+	AnyExit ExitStatus = iota
+	FailedExit
+	SucceededExit
+)
+
+// HistoryFilter narrows a history query; zero fields don't filter.
+type HistoryFilter struct {
+	Status    ExitStatus
+	Directory string
+	// Project keeps to commands run in this directory or below it, like
+	// the root ProjectRoot finds.
+	Project   string
+	SessionID string
+	Since     time.Duration
+	Limit     int
+}
+
+// QueryHistory returns the history matching f, newest first.
+func QueryHistory(db *sql.DB, f HistoryFilter) ([]HistoryItem, error) {
+	query := `SELECT id, timestamp, command, exit_code, duration_ms, directory, session_id, details, COALESCE(resolution, ''), repeats
+			  FROM history`
+	where, args := f.where()
+	if where != "" {
+		query += " WHERE " + where
+	}
+	query += " ORDER BY id DESC"
+	if f.Limit > 0 {
+		query += " LIMIT ?"
+		args = append(args, f.Limit)
+	}
+
+	rows, err := db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var items []HistoryItem
+	for rows.Next() {
+		var item HistoryItem
+		var ts int64
+		if err := rows.Scan(&item.ID, &ts, &item.Command, &item.ExitCode, &item.DurationMs, &item.Directory, &item.SessionID, &item.Details, &item.Resolution, &item.Repeats); err != nil {
+			return nil, err
+		}
+		item.Timestamp = time.Unix(ts, 0)
+		items = append(items, item)
+	}
+	return items, rows.Err()
+}
+
+// where is the SQL condition selecting the history f matches, with its
+// arguments; it is empty when f doesn't filter.
+func (f HistoryFilter) where() (string, []interface{}) {
+	var clauses []string
+	var args []interface{}
+
+	switch f.Status {
+	case FailedExit:
+		clauses = append(clauses, "exit_code != 0")
+	case SucceededExit:
+		clauses = append(clauses, "exit_code = 0")
+	}
+	if f.Directory != "" {
+		clauses = append(clauses, "directory = ?")
+		args = append(args, f.Directory)
+	}
+	if f.Project != "" {
+		clauses = append(clauses, `(directory = ? OR directory LIKE ? ESCAPE '\')`)
+		args = append(args, f.Project, likeEscaper.Replace(strings.TrimSuffix(f.Project, string(filepath.Separator)))+string(filepath.Separator)+"%")
+	}
+	if f.SessionID != "" {
+		clauses = append(clauses, "session_id = ?")
+		args = append(args, f.SessionID)
+	}
+	if f.Since > 0 {
+		clauses = append(clauses, "timestamp >= ?")
+		args = append(args, time.Now().Add(-f.Since).Unix())
+	}
+	return strings.Join(clauses, " AND "), args
+}
+
+// Matches reports whether item passes f at now, for history that doesn't
+// come from the database. It ignores Limit.
+func (f HistoryFilter) Matches(item HistoryItem, now time.Time) bool {
+	switch {
+	case f.Status == FailedExit && item.ExitCode == 0,
+		f.Status == SucceededExit && item.ExitCode != 0,
+		f.Directory != "" && item.Directory != f.Directory,
+		f.Project != "" && !InProject(item.Directory, f.Project),
+		f.SessionID != "" && item.SessionID != f.SessionID,
+		f.Since > 0 && item.Timestamp.Before(now.Add(-f.Since)):
+		return false
+	}
+	return true
+}
+
+// FailureHistory is how a command fared before within one project.
+type FailureHistory struct {
+	Failures int    // times it failed before
+	Fix      string // what fixed the latest failure marked solved, if any
+}
+
+// ProjectFailures looks up how command failed in the project at root before
+// before. The fix of a failure marked solved is the next command of its
+// session that succeeded; a plain rerun doesn't count as one.
+func ProjectFailures(db *sql.DB, root, command string, before time.Time) (FailureHistory, error) {
+	where, args := HistoryFilter{Status: FailedExit, Project: root}.where()
+	args = append(args, command, before.Unix())
+
+	var h FailureHistory
+	var solved sql.NullInt64
+	err := db.QueryRow(`SELECT COUNT(*), MAX(CASE WHEN resolution = 'solution' THEN id END)
+			  FROM history WHERE `+where+` AND command = ? AND timestamp < ?`, args...).Scan(&h.Failures, &solved)
+	if err != nil || !solved.Valid {
+		return h, err
+	}
+
+	err = db.QueryRow(`SELECT command FROM history
+			  WHERE session_id = (SELECT session_id FROM history WHERE id = ?) AND id > ? AND exit_code = 0
+			  ORDER BY id LIMIT 1`, solved.Int64, solved.Int64).Scan(&h.Fix)
+	if err == sql.ErrNoRows {
+		err = nil
+	}
+	if h.Fix == command {
+		h.Fix = ""
+	}
+	return h, err
+}
+
+// likeEscaper escapes the wildcards of a LIKE pattern, with \ as escape.
+var likeEscaper = strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`)
+
 func SearchHistory(db *sql.DB, query string) ([]HistoryItem, error) {
 	sqlQuery := `SELECT id, timestamp, command, exit_code, duration_ms, directory, session_id, details, COALESCE(resolution, '') 
 				 FROM history 
@@ -103,6 +319,60 @@ func SearchHistory(db *sql.DB, query string) ([]HistoryItem, error) {
 	return items, nil
 }
 
+// GetRecentCommands returns distinct commands from history, most recently
+// run first.
+func GetRecentCommands(db *sql.DB, limit int) ([]string, error) {
+	rows, err := db.Query(`SELECT command FROM history
+			  WHERE command != ''
+			  GROUP BY command
+			  ORDER BY MAX(id) DESC
+			  LIMIT ?`, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var commands []string
+	for rows.Next() {
+		var command string
+		if err := rows.Scan(&command); err != nil {
+			return nil, err
+		}
+		commands = append(commands, command)
+	}
+	return commands, rows.Err()
+}
+
+// CommandFrequency is how many times a command was run.
+type CommandFrequency struct {
+	Command string
+	Count   int
+}
+
+// GetFrequentCommands returns distinct commands from history, the most run
+// first and, among equally frequent ones, the most recent first.
+func GetFrequentCommands(db *sql.DB, limit int) ([]CommandFrequency, error) {
+	rows, err := db.Query(`SELECT command, COUNT(*) FROM history
+			  WHERE command != ''
+			  GROUP BY command
+			  ORDER BY COUNT(*) DESC, MAX(id) DESC
+			  LIMIT ?`, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var commands []CommandFrequency
+	for rows.Next() {
+		var c CommandFrequency
+		if err := rows.Scan(&c.Command, &c.Count); err != nil {
+			return nil, err
+		}
+		commands = append(commands, c)
+	}
+	return commands, rows.Err()
+}
+
 type QueryOpts struct {
 	Limit  int
 	Filter string
@@ -158,6 +428,81 @@ func GetFailures(db *sql.DB, opts QueryOpts) ([]HistoryItem, error) {
 	return items, nil
 }
 
+// GetFailuresAfter returns failed commands with an ID greater than afterID,
+// oldest first, so callers can follow new failures as they are logged.
+func GetFailuresAfter(db *sql.DB, afterID int64) ([]HistoryItem, error) {
+	query := `SELECT id, timestamp, command, exit_code, duration_ms, directory, session_id, details, COALESCE(resolution, '')
+			  FROM history WHERE id > ? AND exit_code != 0 ORDER BY id ASC`
+
+	rows, err := db.Query(query, afterID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var items []HistoryItem
+	for rows.Next() {
+		var item HistoryItem
+		var ts int64
+		if err := rows.Scan(&item.ID, &ts, &item.Command, &item.ExitCode, &item.DurationMs, &item.Directory, &item.SessionID, &item.Details, &item.Resolution); err != nil {
+			return nil, err
+		}
+		item.Timestamp = time.Unix(ts, 0)
+		items = append(items, item)
+	}
+	return items, rows.Err()
+}
+
+// GetSimilarFailures returns the failures whose error signature is
+// signature, newest first, at most limit of them.
+func GetSimilarFailures(db *sql.DB, signature string, limit int) ([]HistoryItem, error) {
+	query := `SELECT id, timestamp, command, exit_code, duration_ms, directory, session_id, details, COALESCE(resolution, '')
+			  FROM history WHERE error_signature = ? ORDER BY id DESC LIMIT ?`
+
+	rows, err := db.Query(query, signature, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var items []HistoryItem
+	for rows.Next() {
+		var item HistoryItem
+		var ts int64
+		if err := rows.Scan(&item.ID, &ts, &item.Command, &item.ExitCode, &item.DurationMs, &item.Directory, &item.SessionID, &item.Details, &item.Resolution); err != nil {
+			return nil, err
+		}
+		item.Timestamp = time.Unix(ts, 0)
+		items = append(items, item)
+	}
+	return items, rows.Err()
+}
+
+// GetHistorySince returns every command recorded within the given window,
+// oldest first.
+func GetHistorySince(db *sql.DB, since time.Duration) ([]HistoryItem, error) {
+	query := `SELECT id, timestamp, command, exit_code, duration_ms, directory, session_id, details, COALESCE(resolution, '')
+			  FROM history WHERE timestamp >= ? ORDER BY timestamp ASC, id ASC`
+
+	rows, err := db.Query(query, time.Now().Add(-since).Unix())
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var items []HistoryItem
+	for rows.Next() {
+		var item HistoryItem
+		var ts int64
+		if err := rows.Scan(&item.ID, &ts, &item.Command, &item.ExitCode, &item.DurationMs, &item.Directory, &item.SessionID, &item.Details, &item.Resolution); err != nil {
+			return nil, err
+		}
+		item.Timestamp = time.Unix(ts, 0)
+		items = append(items, item)
+	}
+	return items, rows.Err()
+}
+
 // GetLastUnresolvedFailure returns the most recent failed command that hasn't been resolved.
 func GetLastUnresolvedFailure(db *sql.DB) (*HistoryItem, error) {
 	query := `SELECT id, timestamp, command, exit_code, duration_ms, directory, session_id, details, COALESCE(resolution, '')
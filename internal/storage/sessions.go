@@ -0,0 +1,83 @@
+package storage
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+// Session is one TUI launch or one shell with the hook loaded; the
+// commands run in it carry its ID.
+type Session struct {
+	ID string
+	// Source is "tui", or the shell ("zsh") of a hook's session.
+	Source    string
+	Directory string
+	StartedAt time.Time
+	// EndedAt is zero while the session runs, and for a shell that was
+	// killed rather than exited.
+	EndedAt time.Time
+
+	// Filled in by ListSessions.
+	Commands   int
+	Failed     int
+	LastActive time.Time
+}
+
+// LastSeen is when the session ended, or its last command ran if later.
+func (s Session) LastSeen() time.Time {
+	if s.LastActive.After(s.EndedAt) {
+		return s.LastActive
+	}
+	return s.EndedAt
+}
+
+// StartSession records that s began; starting it again changes nothing.
+func StartSession(db *sql.DB, s Session) error {
+	_, err := db.Exec(`INSERT OR IGNORE INTO sessions (id, source, directory, started_at) VALUES (?, ?, ?, ?)`,
+		s.ID, s.Source, s.Directory, s.StartedAt.Unix())
+	if err != nil {
+		return fmt.Errorf("start session: %w", err)
+	}
+	return nil
+}
+
+// EndSession records when the session with id ended.
+func EndSession(db *sql.DB, id string, at time.Time) error {
+	_, err := db.Exec(`UPDATE sessions SET ended_at = ? WHERE id = ?`, at.Unix(), id)
+	if err != nil {
+		return fmt.Errorf("end session: %w", err)
+	}
+	return nil
+}
+
+// ListSessions returns the latest limit sessions that ran commands,
+// newest first, with how many ran and failed.
+func ListSessions(db *sql.DB, limit int) ([]Session, error) {
+	rows, err := db.Query(`SELECT s.id, s.source, COALESCE(s.directory, ''), s.started_at, COALESCE(s.ended_at, 0),
+			COUNT(h.id), COALESCE(SUM(h.exit_code != 0), 0), COALESCE(MAX(h.timestamp), 0)
+		FROM sessions s JOIN history h ON h.session_id = s.id
+		GROUP BY s.id
+		ORDER BY s.started_at DESC, s.id
+		LIMIT ?`, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var sessions []Session
+	for rows.Next() {
+		var s Session
+		var started, ended, last int64
+		if err := rows.Scan(&s.ID, &s.Source, &s.Directory, &started, &ended, &s.Commands, &s.Failed, &last); err != nil {
+			return nil, err
+		}
+		s.StartedAt = time.Unix(started, 0)
+		if ended > 0 {
+			s.EndedAt = time.Unix(ended, 0)
+		}
+		s.LastActive = time.Unix(last, 0)
+		sessions = append(sessions, s)
+	}
+	return sessions, rows.Err()
+}
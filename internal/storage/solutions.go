@@ -0,0 +1,94 @@
+package storage
+
+import (
+	"cmp"
+	"database/sql"
+	"fmt"
+	"math"
+	"slices"
+	"time"
+)
+
+// solutionHalfLife is how long it takes what a solution's outcomes say
+// about it to count half as much, so fixes that stopped being run fade
+// back towards unknown.
+const solutionHalfLife = 30 * 24 * time.Hour
+
+// Solution is a command run to fix an error, with how that went.
+type Solution struct {
+	Signature string
+	Command   string
+	Successes int
+	Failures  int
+	LastUsed  time.Time
+}
+
+// Score estimates how likely the solution is to work at now: the mean of
+// a Beta(1, 1) prior updated with its outcomes, which are weighed down
+// the longer ago it was last used. An untried solution scores 0.5.
+func (s Solution) Score(now time.Time) float64 {
+	w := math.Pow(0.5, now.Sub(s.LastUsed).Hours()/solutionHalfLife.Hours())
+	return (w*float64(s.Successes) + 1) / (w*float64(s.Successes+s.Failures) + 2)
+}
+
+// IncrementSolutionSuccess records that command fixed the error with
+// signature.
+func IncrementSolutionSuccess(db *sql.DB, signature, command string) error {
+	return recordSolution(db, signature, command, 1, 0)
+}
+
+// IncrementSolutionFailure records that command was run for the error with
+// signature but failed itself.
+func IncrementSolutionFailure(db *sql.DB, signature, command string) error {
+	return recordSolution(db, signature, command, 0, 1)
+}
+
+func recordSolution(db *sql.DB, signature, command string, successes, failures int) error {
+	_, err := db.Exec(`INSERT INTO solutions (error_signature, command, successes, failures, last_used)
+		VALUES (?, ?, ?, ?, ?)
+		ON CONFLICT (error_signature, command) DO UPDATE SET
+			successes = successes + excluded.successes,
+			failures = failures + excluded.failures,
+			last_used = excluded.last_used`,
+		signature, command, successes, failures, time.Now().Unix())
+	if err != nil {
+		return fmt.Errorf("record solution: %w", err)
+	}
+	return nil
+}
+
+// GetSolutionsForError returns the solutions tried for the error with
+// signature, best Score first, at most limit of them.
+func GetSolutionsForError(db *sql.DB, signature string, limit int) ([]Solution, error) {
+	rows, err := db.Query(`SELECT command, successes, failures, last_used FROM solutions WHERE error_signature = ?`, signature)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var solutions []Solution
+	for rows.Next() {
+		s := Solution{Signature: signature}
+		var lastUsed int64
+		if err := rows.Scan(&s.Command, &s.Successes, &s.Failures, &lastUsed); err != nil {
+			return nil, err
+		}
+		s.LastUsed = time.Unix(lastUsed, 0)
+		solutions = append(solutions, s)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	slices.SortStableFunc(solutions, func(a, b Solution) int {
+		if c := cmp.Compare(b.Score(now), a.Score(now)); c != 0 {
+			return c
+		}
+		return b.Successes - a.Successes
+	})
+	if limit > 0 && len(solutions) > limit {
+		solutions = solutions[:limit]
+	}
+	return solutions, nil
+}
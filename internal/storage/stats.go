@@ -0,0 +1,137 @@
+package storage
+
+import (
+	"database/sql"
+	"sort"
+	"strings"
+	"time"
+)
+
+// statsTop bounds each leaderboard of HistoryStats.
+const statsTop = 8
+
+// statsMinRuns is how often a command must have run to rank by failure
+// rate, so a single failed run doesn't top the board at 100%.
+const statsMinRuns = 3
+
+// CommandStats sums up the runs of one command.
+type CommandStats struct {
+	Command  string
+	Runs     int
+	Failures int
+	Total    time.Duration
+}
+
+// AvgDuration is how long a run of the command takes on average.
+func (c CommandStats) AvgDuration() time.Duration {
+	if c.Runs == 0 {
+		return 0
+	}
+	return c.Total / time.Duration(c.Runs)
+}
+
+// HistoryStats aggregates a stretch of history for the Stats view.
+type HistoryStats struct {
+	Total  int
+	Failed int
+	// MostRun, FailureRates and Slowest are leaderboards of statsTop
+	// commands: the most run, the most often failing and the slowest on
+	// average.
+	MostRun      []CommandStats
+	FailureRates []CommandStats
+	Slowest      []CommandStats
+	// Hours counts the commands started in each hour of the day, local time.
+	Hours [24]int
+}
+
+// GetHistoryStats aggregates the history matching f; f.Limit is ignored.
+func GetHistoryStats(db *sql.DB, f HistoryFilter) (HistoryStats, error) {
+	where, args := f.where()
+	query := `SELECT timestamp, command, exit_code, duration_ms, repeats FROM history`
+	if where != "" {
+		query += " WHERE " + where
+	}
+
+	rows, err := db.Query(query, args...)
+	if err != nil {
+		return HistoryStats{}, err
+	}
+	defer rows.Close()
+
+	var items []HistoryItem
+	for rows.Next() {
+		var item HistoryItem
+		var ts int64
+		if err := rows.Scan(&ts, &item.Command, &item.ExitCode, &item.DurationMs, &item.Repeats); err != nil {
+			return HistoryStats{}, err
+		}
+		item.Timestamp = time.Unix(ts, 0)
+		items = append(items, item)
+	}
+	if err := rows.Err(); err != nil {
+		return HistoryStats{}, err
+	}
+	return SummarizeHistory(items), nil
+}
+
+// SummarizeHistory aggregates items, counting a collapsed row as each of
+// its repeats with the latest's duration. Interrupted commands (exit 130)
+// don't count as failures.
+func SummarizeHistory(items []HistoryItem) HistoryStats {
+	var stats HistoryStats
+	byCommand := make(map[string]*CommandStats)
+
+	for _, item := range items {
+		runs := max(item.Repeats, 1)
+		stats.Total += runs
+		stats.Hours[item.Timestamp.Hour()] += runs
+
+		command := strings.TrimSpace(item.Command)
+		if command == "" {
+			continue
+		}
+		c, ok := byCommand[command]
+		if !ok {
+			c = &CommandStats{Command: command}
+			byCommand[command] = c
+		}
+		c.Runs += runs
+		c.Total += time.Duration(runs) * time.Duration(item.DurationMs) * time.Millisecond
+		if item.ExitCode != 0 && item.ExitCode != 130 {
+			c.Failures += runs
+			stats.Failed += runs
+		}
+	}
+
+	all := make([]CommandStats, 0, len(byCommand))
+	for _, c := range byCommand {
+		all = append(all, *c)
+	}
+	// Ties go alphabetically, so the boards don't reshuffle between loads.
+	sort.Slice(all, func(i, j int) bool { return all[i].Command < all[j].Command })
+
+	stats.MostRun = topCommands(all, nil, func(a, b CommandStats) bool { return a.Runs > b.Runs })
+	stats.FailureRates = topCommands(all,
+		func(c CommandStats) bool { return c.Runs >= statsMinRuns && c.Failures > 0 },
+		func(a, b CommandStats) bool { return a.Failures*b.Runs > b.Failures*a.Runs })
+	stats.Slowest = topCommands(all,
+		func(c CommandStats) bool { return c.Total > 0 },
+		func(a, b CommandStats) bool { return a.AvgDuration() > b.AvgDuration() })
+	return stats
+}
+
+// topCommands returns up to statsTop of the commands keep accepts (all
+// without keep), ordered by less.
+func topCommands(all []CommandStats, keep func(CommandStats) bool, less func(a, b CommandStats) bool) []CommandStats {
+	var top []CommandStats
+	for _, c := range all {
+		if keep == nil || keep(c) {
+			top = append(top, c)
+		}
+	}
+	sort.SliceStable(top, func(i, j int) bool { return less(top[i], top[j]) })
+	if len(top) > statsTop {
+		top = top[:statsTop]
+	}
+	return top
+}
@@ -202,3 +202,100 @@ func (t *QueryDockerTool) list(ctx context.Context, docker *infra.DockerClient,
 		Count:      len(containers),
 	}, time.Since(start))
 }
+
+const (
+	// maxFollow bounds how long one tail_container_logs call may block.
+	maxFollow = 60 * time.Second
+	// maxTailLines bounds one page of tail_container_logs output.
+	maxTailLines = 500
+)
+
+// TailContainerLogsTool returns container logs in incremental pages so an
+// assistant can watch a container: each result carries a cursor that the
+// next call passes back to get only the lines written since.
+type TailContainerLogsTool struct{}
+
+func (t *TailContainerLogsTool) Name() string { return "tail_container_logs" }
+func (t *TailContainerLogsTool) Description() string {
+	return "Tail container logs page by page; pass the returned cursor to get only newer lines"
+}
+
+func (t *TailContainerLogsTool) Parameters() []ToolParam {
+	return []ToolParam{
+		{Name: "container", Type: "string", Description: "Container ID or name", Required: true},
+		{Name: "cursor", Type: "string", Description: "Cursor from the previous page (omit for the first page)", Required: false},
+		{Name: "follow", Type: "duration", Description: "Wait this long for new lines, max 60s (e.g. '10s')", Required: false, Default: "0s"},
+		{Name: "tail", Type: "int", Description: "Lines to return on the first page", Required: false, Default: 100},
+	}
+}
+
+// LogLine is one line of a TailLogsResult.
+type LogLine struct {
+	Time    string `json:"time"`
+	Stream  string `json:"stream"`
+	Message string `json:"message"`
+}
+
+// TailLogsResult is one page of container logs.
+type TailLogsResult struct {
+	Container string    `json:"container"`
+	Lines     []LogLine `json:"lines"`
+	Count     int       `json:"count"`
+	// Cursor is passed back to fetch the next page.
+	Cursor string `json:"cursor"`
+	// More is set when the page was cut at the line limit; call again
+	// immediately with the cursor for the rest.
+	More bool `json:"more,omitempty"`
+}
+
+func (t *TailContainerLogsTool) Execute(ctx context.Context, params map[string]any) ToolResult {
+	start := time.Now()
+
+	container := GetString(params, "container", "")
+	if container == "" {
+		return NewErrorResult("container is required", time.Since(start))
+	}
+
+	var since time.Time
+	if cursor := GetString(params, "cursor", ""); cursor != "" {
+		parsed, err := time.Parse(time.RFC3339Nano, cursor)
+		if err != nil {
+			return NewErrorResult(fmt.Sprintf("invalid cursor %q", cursor), time.Since(start))
+		}
+		since = parsed
+	}
+	follow := min(GetDuration(params, "follow", 0), maxFollow)
+	tail := min(GetInt(params, "tail", 100), maxTailLines)
+
+	docker, err := infra.GetRegistry().Docker()
+	if err != nil {
+		return NewErrorResult(fmt.Sprintf("Docker not available: %v", err), time.Since(start))
+	}
+
+	entries, err := docker.TailLogs(ctx, container, since, tail, follow)
+	if err != nil {
+		return NewErrorResult(fmt.Sprintf("failed to tail logs: %v", err), time.Since(start))
+	}
+
+	result := TailLogsResult{Container: container, Lines: make([]LogLine, 0, len(entries)), Cursor: GetString(params, "cursor", "")}
+	if len(entries) > maxTailLines {
+		entries = entries[:maxTailLines]
+		result.More = true
+	}
+	for _, e := range entries {
+		result.Lines = append(result.Lines, LogLine{
+			Time:    e.Timestamp.Format(time.RFC3339Nano),
+			Stream:  e.Stream,
+			Message: e.Message,
+		})
+	}
+	if len(entries) > 0 {
+		result.Cursor = entries[len(entries)-1].Timestamp.Format(time.RFC3339Nano)
+	} else if result.Cursor == "" {
+		// Nothing logged yet: start the next page from now.
+		result.Cursor = start.Format(time.RFC3339Nano)
+	}
+	result.Count = len(result.Lines)
+
+	return NewResult(result, time.Since(start))
+}
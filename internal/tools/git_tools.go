@@ -2,6 +2,8 @@ package tools
 
 import (
 	"context"
+	"fmt"
+	"os/exec"
 	"strconv"
 	"strings"
 	"time"
@@ -259,3 +261,39 @@ func (t *GitInfoTool) getBranch(start time.Time) ToolResult {
 		Branches: branches,
 	}, time.Since(start))
 }
+
+// ReviewDiff returns the diff to review in dir. With an explicit ref it diffs
+// the working tree against that ref; otherwise staged changes win, falling
+// back to all uncommitted changes against HEAD. A ref starting with "-" is
+// refused, so it can't pass git an option such as --output.
+func ReviewDiff(dir, ref string) (diff string, source string, err error) {
+	if strings.HasPrefix(ref, "-") {
+		return "", "", fmt.Errorf("invalid ref %q", ref)
+	}
+
+	run := func(args ...string) (string, error) {
+		cmd := exec.Command("git", args...)
+		cmd.Dir = dir
+		out, err := cmd.Output()
+		if err != nil {
+			return "", fmt.Errorf("git %s: %w", strings.Join(args, " "), err)
+		}
+		return string(out), nil
+	}
+
+	if ref != "" {
+		diff, err = run("diff", ref)
+		return diff, ref, err
+	}
+
+	diff, err = run("diff", "--cached")
+	if err != nil {
+		return "", "", err
+	}
+	if strings.TrimSpace(diff) != "" {
+		return diff, "staged changes", nil
+	}
+
+	diff, err = run("diff", "HEAD")
+	return diff, "uncommitted changes", err
+}
@@ -0,0 +1,81 @@
+package tools
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"dev-cli/internal/storage"
+)
+
+// GetCommandStatsTool reports how often commands run, fail and how long
+// they take, from the stats materialized over the whole history.
+type GetCommandStatsTool struct {
+	DB *sql.DB
+}
+
+func (t *GetCommandStatsTool) Name() string { return "get_command_stats" }
+func (t *GetCommandStatsTool) Description() string {
+	return "Get runs, failure rate and median / 95th percentile duration per base command (like git or npm) from the shell history"
+}
+
+func (t *GetCommandStatsTool) Parameters() []ToolParam {
+	return []ToolParam{
+		{Name: "command", Type: "string", Description: "Command to report on (its program is used); the most run ones when empty", Required: false},
+		{Name: "limit", Type: "int", Description: "Maximum commands to return", Required: false, Default: 10},
+	}
+}
+
+// CommandStat is the usage of one base command.
+type CommandStat struct {
+	Command     string  `json:"command"`
+	Runs        int     `json:"runs"`
+	Failures    int     `json:"failures"`
+	FailureRate float64 `json:"failure_rate"`
+	P50Ms       int64   `json:"p50_ms"`
+	P95Ms       int64   `json:"p95_ms"`
+}
+
+// CommandStatsResult contains command usage, most run first.
+type CommandStatsResult struct {
+	Commands []CommandStat `json:"commands"`
+	Count    int           `json:"count"`
+}
+
+func (t *GetCommandStatsTool) Execute(ctx context.Context, params map[string]any) ToolResult {
+	start := time.Now()
+
+	var usage []storage.CommandUsage
+	if command := GetString(params, "command", ""); command != "" {
+		u, ok, err := storage.GetCommandUsageFor(t.DB, command)
+		if err != nil {
+			return NewErrorResult(fmt.Sprintf("failed to query command stats: %v", err), time.Since(start))
+		}
+		if ok {
+			usage = append(usage, u)
+		}
+	} else {
+		limit := GetInt(params, "limit", 10)
+		if limit <= 0 || limit > 100 {
+			limit = 100
+		}
+		var err error
+		if usage, err = storage.GetCommandUsage(t.DB, limit); err != nil {
+			return NewErrorResult(fmt.Sprintf("failed to query command stats: %v", err), time.Since(start))
+		}
+	}
+
+	stats := make([]CommandStat, len(usage))
+	for i, u := range usage {
+		stats[i] = CommandStat{
+			Command:     u.Command,
+			Runs:        u.Runs,
+			Failures:    u.Failures,
+			FailureRate: u.FailureRate(),
+			P50Ms:       u.P50.Milliseconds(),
+			P95Ms:       u.P95.Milliseconds(),
+		}
+	}
+	return NewResult(CommandStatsResult{Commands: stats, Count: len(stats)}, time.Since(start))
+}
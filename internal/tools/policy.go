@@ -0,0 +1,150 @@
+package tools
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"dev-cli/internal/config"
+	"dev-cli/internal/workflow"
+)
+
+// mutatingTools change the machine they run on; read-only mode drops them.
+var mutatingTools = map[string]bool{
+	"write_file":      true,
+	"run_command":     true,
+	"execute_runbook": true,
+	"run_workflow":    true,
+}
+
+// Policy restricts which tools a registry exposes, e.g. to the clients of
+// `mcp serve`.
+type Policy struct {
+	// Allow lists tool names; empty allows every tool.
+	Allow []string
+	// ReadOnly drops tools that write files or run commands.
+	ReadOnly bool
+}
+
+// Permits reports whether the policy allows the named tool.
+func (p Policy) Permits(name string) bool {
+	if p.ReadOnly && mutatingTools[name] {
+		return false
+	}
+	if len(p.Allow) == 0 {
+		return true
+	}
+	for _, allowed := range p.Allow {
+		if allowed == name {
+			return true
+		}
+	}
+	return false
+}
+
+// Restrict unregisters every tool the policy does not permit.
+func (r *Registry) Restrict(p Policy) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for name := range r.tools {
+		if !p.Permits(name) {
+			delete(r.tools, name)
+		}
+	}
+}
+
+// AuditEntry is one line of the tool audit log.
+type AuditEntry struct {
+	Time     time.Time      `json:"time"`
+	Tool     string         `json:"tool"`
+	Params   map[string]any `json:"params"`
+	Success  bool           `json:"success"`
+	Error    string         `json:"error,omitempty"`
+	Duration time.Duration  `json:"duration"`
+}
+
+var auditMu sync.Mutex
+
+// SetAuditLog makes Call append an AuditEntry as a JSON line to path for
+// every invocation, including calls to unknown or restricted tools.
+func (r *Registry) SetAuditLog(path string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.auditPath = path
+}
+
+// Call runs the named tool and records it in the audit log, if one is set.
+func (r *Registry) Call(ctx context.Context, name string, params map[string]any) ToolResult {
+	r.mu.RLock()
+	tool, ok := r.tools[name]
+	auditPath := r.auditPath
+	r.mu.RUnlock()
+
+	var result ToolResult
+	if ok {
+		result = tool.Execute(ctx, params)
+	} else {
+		result = NewErrorResult(fmt.Sprintf("tool %q is not available", name), 0)
+	}
+
+	if auditPath != "" {
+		appendAudit(auditPath, AuditEntry{
+			Time:     time.Now(),
+			Tool:     name,
+			Params:   params,
+			Success:  result.Success,
+			Error:    result.Error,
+			Duration: result.Duration,
+		})
+	}
+	return result
+}
+
+// appendAudit writes best-effort: a broken audit log must not break tools.
+func appendAudit(path string, entry AuditEntry) {
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return
+	}
+
+	auditMu.Lock()
+	defer auditMu.Unlock()
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return
+	}
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+	if err != nil {
+		return
+	}
+	defer f.Close()
+	f.Write(append(data, '\n'))
+}
+
+// NewConfiguredRegistry returns a registry with the default tools, plus the
+// history tools when db is non-nil and the workflow tools when
+// DEV_CLI_RUNBOOK_ALLOW is set too (running commands under it and
+// DEV_CLI_RUNBOOK_DENY), narrowed by DEV_CLI_TOOLS_ALLOW /
+// DEV_CLI_TOOLS_READONLY and auditing every call to tool_audit.jsonl in the
+// log directory. The registry is usable even with an error, which says why
+// the workflow tools were left out.
+func NewConfiguredRegistry(cfg *config.Config, db *sql.DB) (*Registry, error) {
+	r := NewRegistry()
+	r.RegisterDefaults()
+	var err error
+	if db != nil {
+		r.RegisterHistoryTools(db)
+		// Without an allowlist every non-destructive command would pass,
+		// too much for a caller with no human at the keyboard.
+		if len(cfg.RunbookAllow) > 0 {
+			err = r.RegisterWorkflowTools(db, workflow.CommandPolicy{Allow: cfg.RunbookAllow, Deny: cfg.RunbookDeny})
+		}
+	}
+	r.Restrict(Policy{Allow: cfg.ToolAllow, ReadOnly: cfg.ToolsReadOnly})
+	r.SetAuditLog(filepath.Join(cfg.LogDir, "tool_audit.jsonl"))
+	return r, err
+}
@@ -0,0 +1,109 @@
+package tools
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"dev-cli/internal/storage"
+)
+
+// GetRootCausesTool lists recent root-cause analyses so agents can reuse
+// them instead of re-deriving a diagnosis.
+type GetRootCausesTool struct {
+	DB *sql.DB
+}
+
+func (t *GetRootCausesTool) Name() string { return "get_root_causes" }
+func (t *GetRootCausesTool) Description() string {
+	return "List recent root-cause analyses with their causal chain and remediation steps"
+}
+
+func (t *GetRootCausesTool) Parameters() []ToolParam {
+	return []ToolParam{
+		{Name: "limit", Type: "int", Description: "Maximum analyses to return", Required: false, Default: 10},
+	}
+}
+
+// RootCausesResult contains stored root-cause analyses.
+type RootCausesResult struct {
+	RootCauses []storage.RootCause `json:"root_causes"`
+	Count      int                 `json:"count"`
+}
+
+func (t *GetRootCausesTool) Execute(ctx context.Context, params map[string]any) ToolResult {
+	start := time.Now()
+
+	limit := GetInt(params, "limit", 10)
+	if limit <= 0 || limit > 100 {
+		limit = 100
+	}
+
+	rcs, err := storage.GetRecentRootCauses(t.DB, limit)
+	if err != nil {
+		return NewErrorResult(fmt.Sprintf("failed to query root causes: %v", err), time.Since(start))
+	}
+	if rcs == nil {
+		rcs = []storage.RootCause{}
+	}
+
+	return NewResult(RootCausesResult{RootCauses: rcs, Count: len(rcs)}, time.Since(start))
+}
+
+// GetRootCauseBySignatureTool looks up the analysis for one error, either by
+// its signature or by the failed command, which it turns into a signature.
+type GetRootCauseBySignatureTool struct {
+	DB *sql.DB
+}
+
+func (t *GetRootCauseBySignatureTool) Name() string { return "get_root_cause_by_signature" }
+func (t *GetRootCauseBySignatureTool) Description() string {
+	return "Find a prior root-cause analysis by error signature, or by command, exit code and output, and how often that failure was recorded"
+}
+
+func (t *GetRootCauseBySignatureTool) Parameters() []ToolParam {
+	return []ToolParam{
+		{Name: "signature", Type: "string", Description: "Error signature", Required: false},
+		{Name: "command", Type: "string", Description: "Failed command (when no signature is given)", Required: false},
+		{Name: "exit_code", Type: "int", Description: "Exit code of the failed command", Required: false, Default: 1},
+		{Name: "output", Type: "string", Description: "Output of the failed command", Required: false},
+	}
+}
+
+// maxOccurrences caps how many recorded failures a lookup counts.
+const maxOccurrences = 100
+
+// RootCauseLookupResult is the analysis for a signature, if any, and how
+// often the history recorded that failure.
+type RootCauseLookupResult struct {
+	Signature   string             `json:"signature"`
+	Found       bool               `json:"found"`
+	RootCause   *storage.RootCause `json:"root_cause,omitempty"`
+	Occurrences int                `json:"occurrences"`
+}
+
+func (t *GetRootCauseBySignatureTool) Execute(ctx context.Context, params map[string]any) ToolResult {
+	start := time.Now()
+
+	signature := GetString(params, "signature", "")
+	if signature == "" {
+		command := GetString(params, "command", "")
+		if command == "" {
+			return NewErrorResult("signature or command is required", time.Since(start))
+		}
+		signature = storage.GenerateErrorSignature(command, GetInt(params, "exit_code", 1), GetString(params, "output", ""))
+	}
+
+	rc, err := storage.GetRootCauseBySignature(t.DB, signature)
+	if err != nil {
+		return NewErrorResult(fmt.Sprintf("failed to query root cause: %v", err), time.Since(start))
+	}
+
+	similar, err := storage.GetSimilarFailures(t.DB, signature, maxOccurrences)
+	if err != nil {
+		return NewErrorResult(fmt.Sprintf("failed to query similar failures: %v", err), time.Since(start))
+	}
+
+	return NewResult(RootCauseLookupResult{Signature: signature, Found: rc != nil, RootCause: rc, Occurrences: len(similar)}, time.Since(start))
+}
@@ -1,15 +1,19 @@
 package tools
 
 import (
+	"database/sql"
 	"fmt"
 	"sort"
 	"sync"
+
+	"dev-cli/internal/workflow"
 )
 
 // Registry manages tool registration and lookup.
 type Registry struct {
-	mu    sync.RWMutex
-	tools map[string]Tool
+	mu        sync.RWMutex
+	tools     map[string]Tool
+	auditPath string
 }
 
 var (
@@ -120,12 +124,34 @@ func (r *Registry) RegisterDefaults() {
 	r.MustRegister(&RunCommandTool{})
 	r.MustRegister(&SearchCodebaseTool{})
 	r.MustRegister(&QueryDockerTool{})
+	r.MustRegister(&TailContainerLogsTool{})
 	r.MustRegister(&CheckPortsTool{})
 	r.MustRegister(&GitInfoTool{})
 	r.MustRegister(&PackageInfoTool{})
 	r.MustRegister(&GitInspectorTool{})
 }
 
+// RegisterHistoryTools registers the tools that read the history database.
+func (r *Registry) RegisterHistoryTools(db *sql.DB) {
+	r.MustRegister(&GetRootCausesTool{DB: db})
+	r.MustRegister(&GetRootCauseBySignatureTool{DB: db})
+	r.MustRegister(&GetCommandStatsTool{DB: db})
+}
+
+// RegisterWorkflowTools registers the tools that execute stored runbooks
+// and YAML workflows, each command checked against policy, and the one that
+// reports on workflow runs. It creates the workflow tables here, once, so
+// the tools never run DDL while a run is writing its checkpoints.
+func (r *Registry) RegisterWorkflowTools(db *sql.DB, policy workflow.CommandPolicy) error {
+	if err := workflow.NewCheckpointStore(db).InitSchema(); err != nil {
+		return fmt.Errorf("failed to initialize workflow schema: %w", err)
+	}
+	r.MustRegister(&ExecuteRunbookTool{DB: db, Policy: policy})
+	r.MustRegister(&RunWorkflowTool{DB: db, Policy: policy})
+	r.MustRegister(&GetWorkflowStatusTool{DB: db})
+	return nil
+}
+
 // GetSchemas returns JSON schemas for all registered tools.
 func (r *Registry) GetSchemas() []ToolSchema {
 	r.mu.RLock()
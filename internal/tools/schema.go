@@ -89,8 +89,12 @@ func mapTypeToJSONSchema(internalType string) string {
 		return "string"
 	case "int":
 		return "integer"
+	case "number":
+		return "number"
 	case "bool":
 		return "boolean"
+	case "object":
+		return "object"
 	case "[]string", "[]int":
 		return "array"
 	default:
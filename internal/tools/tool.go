@@ -26,7 +26,7 @@ type Tool interface {
 // ToolParam defines a parameter for a tool.
 type ToolParam struct {
 	Name        string `json:"name"`
-	Type        string `json:"type"` // string, int, bool, []string, []int
+	Type        string `json:"type"` // string, int, number, bool, object, []string, []int
 	Description string `json:"description"`
 	Required    bool   `json:"required"`
 	Default     any    `json:"default,omitempty"`
@@ -2,10 +2,17 @@ package tools
 
 import (
 	"context"
+	"encoding/json"
 	"os"
+	"os/exec"
 	"path/filepath"
+	"strings"
 	"testing"
 	"time"
+
+	"dev-cli/internal/config"
+	"dev-cli/internal/storage"
+	"dev-cli/internal/workflow"
 )
 
 func TestReadFileTool(t *testing.T) {
@@ -220,8 +227,8 @@ func TestRegistry(t *testing.T) {
 		reg := NewRegistry()
 		reg.RegisterDefaults()
 
-		if reg.Count() != 10 {
-			t.Errorf("expected 10 default tools, got %d", reg.Count())
+		if reg.Count() != 11 {
+			t.Errorf("expected 11 default tools, got %d", reg.Count())
 		}
 	})
 }
@@ -270,3 +277,325 @@ func TestParameterHelpers(t *testing.T) {
 		}
 	})
 }
+
+func TestReviewDiff(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not installed")
+	}
+
+	dir := t.TempDir()
+	git := func(args ...string) {
+		cmd := exec.Command("git", args...)
+		cmd.Dir = dir
+		cmd.Env = append(os.Environ(), "GIT_AUTHOR_NAME=t", "GIT_AUTHOR_EMAIL=t@t", "GIT_COMMITTER_NAME=t", "GIT_COMMITTER_EMAIL=t@t")
+		if out, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git %v: %v\n%s", args, err, out)
+		}
+	}
+
+	file := filepath.Join(dir, "a.txt")
+	git("init", "-q")
+	os.WriteFile(file, []byte("one\n"), 0644)
+	git("add", "a.txt")
+	git("commit", "-qm", "init")
+
+	os.WriteFile(file, []byte("two\n"), 0644)
+	diff, source, err := ReviewDiff(dir, "")
+	if err != nil {
+		t.Fatalf("ReviewDiff failed: %v", err)
+	}
+	if source != "uncommitted changes" || diff == "" {
+		t.Errorf("expected uncommitted diff, got source %q", source)
+	}
+
+	git("add", "a.txt")
+	_, source, _ = ReviewDiff(dir, "")
+	if source != "staged changes" {
+		t.Errorf("expected staged changes to win, got %q", source)
+	}
+
+	out := filepath.Join(t.TempDir(), "written")
+	if _, _, err := ReviewDiff(dir, "--output="+out); err == nil {
+		t.Error("expected a ref that looks like an option refused")
+	}
+	if _, err := os.Stat(out); err == nil {
+		t.Error("expected git not to be handed the option")
+	}
+}
+
+func TestRegistryPolicy(t *testing.T) {
+	t.Run("Read-only drops mutating tools", func(t *testing.T) {
+		r := NewRegistry()
+		r.RegisterDefaults()
+		r.Restrict(Policy{ReadOnly: true})
+
+		for _, name := range []string{"write_file", "run_command"} {
+			if _, ok := r.Get(name); ok {
+				t.Errorf("%s should not be registered in read-only mode", name)
+			}
+		}
+		if _, ok := r.Get("read_file"); !ok {
+			t.Error("read_file should stay registered")
+		}
+	})
+
+	t.Run("Allowlist keeps only named tools", func(t *testing.T) {
+		r := NewRegistry()
+		r.RegisterDefaults()
+		r.Restrict(Policy{Allow: []string{"git_info", "run_command"}, ReadOnly: true})
+
+		if names := r.Names(); len(names) != 1 || names[0] != "git_info" {
+			t.Errorf("expected only git_info, got %v", names)
+		}
+	})
+}
+
+func TestRegistryCallAudit(t *testing.T) {
+	tmpDir := t.TempDir()
+	auditPath := filepath.Join(tmpDir, "audit", "tool_audit.jsonl")
+	testFile := filepath.Join(tmpDir, "notes.txt")
+	os.WriteFile(testFile, []byte("hello"), 0644)
+
+	r := NewRegistry()
+	r.MustRegister(&ReadFileTool{})
+	r.SetAuditLog(auditPath)
+
+	if result := r.Call(context.Background(), "read_file", map[string]any{"path": testFile}); !result.Success {
+		t.Fatalf("read_file failed: %s", result.Error)
+	}
+	if result := r.Call(context.Background(), "write_file", map[string]any{"path": testFile}); result.Success {
+		t.Error("unregistered tool should fail")
+	}
+
+	data, err := os.ReadFile(auditPath)
+	if err != nil {
+		t.Fatalf("audit log not written: %v", err)
+	}
+	lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("expected 2 audit entries, got %d", len(lines))
+	}
+
+	var entry AuditEntry
+	if err := json.Unmarshal([]byte(lines[0]), &entry); err != nil {
+		t.Fatal(err)
+	}
+	if entry.Tool != "read_file" || !entry.Success || entry.Params["path"] != testFile {
+		t.Errorf("unexpected first entry: %+v", entry)
+	}
+	json.Unmarshal([]byte(lines[1]), &entry)
+	if entry.Tool != "write_file" || entry.Success {
+		t.Errorf("denied call should be audited as a failure: %+v", entry)
+	}
+}
+
+func TestTailContainerLogsTool(t *testing.T) {
+	tool := &TailContainerLogsTool{}
+
+	t.Run("Container required", func(t *testing.T) {
+		result := tool.Execute(context.Background(), map[string]any{})
+		if result.Success {
+			t.Error("expected failure without container")
+		}
+	})
+
+	t.Run("Invalid cursor", func(t *testing.T) {
+		result := tool.Execute(context.Background(), map[string]any{
+			"container": "web",
+			"cursor":    "yesterday",
+		})
+		if result.Success || !strings.Contains(result.Error, "invalid cursor") {
+			t.Errorf("expected invalid cursor error, got %+v", result)
+		}
+	})
+}
+
+func TestRootCauseTools(t *testing.T) {
+	db, err := storage.OpenDB(filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	sig := storage.GenerateErrorSignature("npm start", 1, "npm ERR! missing script: start")
+	storage.SaveRootCause(db, storage.RootCause{
+		ID:               "rc1",
+		ErrorSignature:   sig,
+		Timestamp:        time.Now(),
+		RootCauseNodes:   []string{"package.json has no start script"},
+		RemediationSteps: []string{"add a start script"},
+		Confidence:       0.9,
+	})
+	storage.SaveCommand(db, storage.LogEntry{Command: "npm start", ExitCode: 1, Output: "npm ERR! missing script: start"})
+
+	t.Run("List recent", func(t *testing.T) {
+		result := (&GetRootCausesTool{DB: db}).Execute(context.Background(), map[string]any{"limit": 5})
+		if !result.Success {
+			t.Fatalf("expected success, got %s", result.Error)
+		}
+		data := result.Data.(RootCausesResult)
+		if data.Count != 1 || data.RootCauses[0].ID != "rc1" {
+			t.Errorf("unexpected root causes: %+v", data)
+		}
+	})
+
+	t.Run("Lookup by command", func(t *testing.T) {
+		result := (&GetRootCauseBySignatureTool{DB: db}).Execute(context.Background(), map[string]any{
+			"command":   "npm start",
+			"exit_code": 1,
+			"output":    "npm ERR! missing script: start",
+		})
+		data := result.Data.(RootCauseLookupResult)
+		if !data.Found || data.Signature != sig || data.RootCause.RemediationSteps[0] != "add a start script" || data.Occurrences != 1 {
+			t.Errorf("unexpected lookup: %+v", data)
+		}
+	})
+
+	t.Run("Unknown signature", func(t *testing.T) {
+		result := (&GetRootCauseBySignatureTool{DB: db}).Execute(context.Background(), map[string]any{"signature": "nope"})
+		if !result.Success || result.Data.(RootCauseLookupResult).Found {
+			t.Errorf("expected a successful miss, got %+v", result)
+		}
+	})
+}
+
+func TestGetCommandStatsTool(t *testing.T) {
+	db, err := storage.OpenDB(filepath.Join(t.TempDir(), "history.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	storage.SaveCommand(db, storage.LogEntry{Command: "npm test", ExitCode: 1, DurationMs: 3000})
+	storage.SaveCommand(db, storage.LogEntry{Command: "npm install", DurationMs: 1000})
+	storage.SaveCommand(db, storage.LogEntry{Command: "git status", DurationMs: 10})
+
+	result := (&GetCommandStatsTool{DB: db}).Execute(context.Background(), map[string]any{})
+	data := result.Data.(CommandStatsResult)
+	if !result.Success || data.Count != 2 || data.Commands[0].Command != "npm" || data.Commands[0].FailureRate != 0.5 {
+		t.Errorf("unexpected stats: %+v", result)
+	}
+
+	result = (&GetCommandStatsTool{DB: db}).Execute(context.Background(), map[string]any{"command": "git log"})
+	data = result.Data.(CommandStatsResult)
+	if data.Count != 1 || data.Commands[0].Command != "git" || data.Commands[0].P95Ms != 10 {
+		t.Errorf("unexpected stats for git: %+v", data)
+	}
+}
+
+func TestExecuteRunbookTool(t *testing.T) {
+	db, err := storage.OpenDB(filepath.Join(t.TempDir(), "history.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	storage.SaveRunbook(db, storage.Runbook{
+		ID:   "rb1",
+		Name: "Greet",
+		Steps: []storage.RunbookStep{
+			{ID: "hello", Command: "echo hello"},
+			{ID: "bye", Command: "echo bye"},
+		},
+	})
+	policy := workflow.CommandPolicy{Allow: []string{"echo "}}
+	if err := NewRegistry().RegisterWorkflowTools(db, policy); err != nil {
+		t.Fatal(err)
+	}
+	tool := &ExecuteRunbookTool{DB: db, Policy: policy}
+
+	result := tool.Execute(context.Background(), map[string]any{"runbook_id": "rb1"})
+	if !result.Success {
+		t.Fatalf("expected success, got %s", result.Error)
+	}
+	data := result.Data.(ExecuteRunbookResult)
+	if data.Status != string(workflow.StatusCompleted) || len(data.Steps) != 2 {
+		t.Fatalf("unexpected run: %+v", data)
+	}
+	if data.Steps[0].ID != "hello" || !strings.Contains(data.Steps[0].Output, "hello") {
+		t.Errorf("unexpected first step: %+v", data.Steps[0])
+	}
+
+	tool.Policy = workflow.CommandPolicy{Deny: []string{"bye"}}
+	result = tool.Execute(context.Background(), map[string]any{"runbook_id": "rb1"})
+	if result.Success || !strings.Contains(result.Error, "bye") {
+		t.Errorf("expected the runbook refused by policy, got %+v", result)
+	}
+}
+
+func TestWorkflowTools(t *testing.T) {
+	dir := t.TempDir()
+	db, err := storage.OpenDB(filepath.Join(dir, "history.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	path := filepath.Join(dir, "greet.yaml")
+	os.WriteFile(path, []byte("name: greet\nsteps:\n  - id: hello\n    command: echo hello\n"), 0644)
+	policy := workflow.CommandPolicy{Allow: []string{"echo "}}
+	if err := NewRegistry().RegisterWorkflowTools(db, policy); err != nil {
+		t.Fatal(err)
+	}
+
+	result := (&RunWorkflowTool{DB: db, Policy: policy}).Execute(context.Background(), map[string]any{"path": path})
+	if !result.Success {
+		t.Fatalf("expected run_workflow to start the run, got %s", result.Error)
+	}
+	runID := result.Data.(RunWorkflowResult).RunID
+
+	status := &GetWorkflowStatusTool{DB: db}
+	deadline := time.Now().Add(30 * time.Second)
+	for {
+		result = status.Execute(context.Background(), map[string]any{"run_id": runID})
+		if !result.Success {
+			t.Fatalf("get_workflow_status failed: %s", result.Error)
+		}
+		state, err := workflow.UnmarshalRunState(result.Data.(json.RawMessage))
+		if err != nil {
+			t.Fatalf("status is not run state JSON: %v", err)
+		}
+		if state.Status == workflow.StatusCompleted {
+			if r := state.StepResults["hello"]; r == nil || !strings.Contains(r.Output, "hello") {
+				t.Errorf("unexpected step result: %+v", r)
+			}
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("run did not complete, last status %s", state.Status)
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+
+	refused := (&RunWorkflowTool{DB: db, Policy: workflow.CommandPolicy{Deny: []string{"hello"}}}).Execute(context.Background(), map[string]any{"path": path})
+	if refused.Success || !strings.Contains(refused.Error, "refused by policy") {
+		t.Errorf("expected the workflow refused by policy, got %+v", refused)
+	}
+	if missing := status.Execute(context.Background(), map[string]any{"run_id": "nope"}); missing.Success {
+		t.Error("expected an unknown run ID to fail")
+	}
+}
+
+func TestConfiguredRegistry_WorkflowToolsNeedAllowlist(t *testing.T) {
+	db, err := storage.OpenDB(filepath.Join(t.TempDir(), "history.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	cfg := &config.Config{LogDir: t.TempDir()}
+	r, _ := NewConfiguredRegistry(cfg, db)
+	if _, ok := r.Get("execute_runbook"); ok {
+		t.Error("expected no execute_runbook without DEV_CLI_RUNBOOK_ALLOW")
+	}
+	cfg.RunbookAllow = []string{"npm "}
+	r, err = NewConfiguredRegistry(cfg, db)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range []string{"execute_runbook", "run_workflow", "get_workflow_status"} {
+		if _, ok := r.Get(name); !ok {
+			t.Errorf("expected %s with an allowlist", name)
+		}
+	}
+}
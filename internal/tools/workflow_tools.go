@@ -0,0 +1,195 @@
+package tools
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"dev-cli/internal/pipeline"
+	"dev-cli/internal/storage"
+	"dev-cli/internal/workflow"
+)
+
+// ExecuteRunbookTool runs a stored runbook step by step through the workflow
+// engine. Like the other workflow tools it expects the tables
+// RegisterWorkflowTools creates. Every command is checked against Policy first, so a runbook the
+// policy doesn't fully permit is refused before anything runs.
+type ExecuteRunbookTool struct {
+	DB     *sql.DB
+	Policy workflow.CommandPolicy
+}
+
+func (t *ExecuteRunbookTool) Name() string { return "execute_runbook" }
+func (t *ExecuteRunbookTool) Description() string {
+	return "Execute a stored runbook step by step under the configured command policy and return the result of every step"
+}
+
+func (t *ExecuteRunbookTool) Parameters() []ToolParam {
+	return []ToolParam{
+		{Name: "runbook_id", Type: "string", Description: "ID of the runbook to execute", Required: true},
+	}
+}
+
+// RunbookStepOutcome is the result of one runbook step.
+type RunbookStepOutcome struct {
+	ID         string `json:"id"`
+	Name       string `json:"name"`
+	Status     string `json:"status"`
+	ExitCode   int    `json:"exit_code"`
+	Output     string `json:"output,omitempty"`
+	Error      string `json:"error,omitempty"`
+	DurationMs int64  `json:"duration_ms"`
+}
+
+// ExecuteRunbookResult contains a runbook run, its steps in runbook order.
+type ExecuteRunbookResult struct {
+	RunbookID string               `json:"runbook_id"`
+	RunID     string               `json:"run_id"`
+	Status    string               `json:"status"`
+	Steps     []RunbookStepOutcome `json:"steps"`
+	Error     string               `json:"error,omitempty"`
+}
+
+func (t *ExecuteRunbookTool) Execute(ctx context.Context, params map[string]any) ToolResult {
+	start := time.Now()
+
+	id := GetString(params, "runbook_id", "")
+	if id == "" {
+		return NewErrorResult("runbook_id is required", time.Since(start))
+	}
+
+	rb, err := storage.GetRunbookByID(t.DB, id)
+	if err != nil {
+		return NewErrorResult(fmt.Sprintf("failed to load runbook %q: %v", id, err), time.Since(start))
+	}
+
+	store := workflow.NewCheckpointStore(t.DB)
+
+	engine := workflow.NewEngine(store, pipeline.NewEventBus())
+	result, err := engine.RunRunbook(ctx, rb, t.Policy)
+	if result == nil {
+		// A refusal by the policy lists every step it denied.
+		return NewErrorResult(err.Error(), time.Since(start))
+	}
+	storage.UpdateRunbookStats(t.DB, rb.ID, result.Status == workflow.StatusCompleted)
+
+	out := ExecuteRunbookResult{
+		RunbookID: rb.ID,
+		RunID:     result.RunID,
+		Status:    string(result.Status),
+		Error:     result.Error,
+	}
+	for i, step := range rb.Steps {
+		// FromRunbook numbers steps that have no ID.
+		stepID := step.ID
+		if stepID == "" {
+			stepID = fmt.Sprintf("step_%d", i)
+		}
+		r := result.StepResults[stepID]
+		if r == nil {
+			continue
+		}
+		out.Steps = append(out.Steps, RunbookStepOutcome{
+			ID:         stepID,
+			Name:       step.Name,
+			Status:     string(r.Status),
+			ExitCode:   r.ExitCode,
+			Output:     r.Output,
+			Error:      r.Error,
+			DurationMs: r.Duration.Milliseconds(),
+		})
+	}
+	return NewResult(out, time.Since(start))
+}
+
+// RunWorkflowTool starts a YAML workflow in the background and returns its
+// run ID for get_workflow_status to poll. Every command is checked against
+// Policy first, as for runbooks.
+type RunWorkflowTool struct {
+	DB     *sql.DB
+	Policy workflow.CommandPolicy
+}
+
+func (t *RunWorkflowTool) Name() string { return "run_workflow" }
+func (t *RunWorkflowTool) Description() string {
+	return "Start a YAML workflow in the background under the configured command policy and return its run ID"
+}
+
+func (t *RunWorkflowTool) Parameters() []ToolParam {
+	return []ToolParam{
+		{Name: "path", Type: "string", Description: "Path to the workflow YAML file", Required: true},
+	}
+}
+
+// RunWorkflowResult identifies a started workflow run.
+type RunWorkflowResult struct {
+	RunID    string `json:"run_id"`
+	Workflow string `json:"workflow"`
+	Steps    int    `json:"steps"`
+}
+
+func (t *RunWorkflowTool) Execute(ctx context.Context, params map[string]any) ToolResult {
+	start := time.Now()
+
+	path := GetString(params, "path", "")
+	if path == "" {
+		return NewErrorResult("path is required", time.Since(start))
+	}
+
+	wf, err := workflow.ParseFile(path)
+	if err != nil {
+		return NewErrorResult(fmt.Sprintf("failed to parse workflow: %v", err), time.Since(start))
+	}
+	if err := t.Policy.CheckWorkflow(wf); err != nil {
+		return NewErrorResult(err.Error(), time.Since(start))
+	}
+
+	store := workflow.NewCheckpointStore(t.DB)
+
+	// The run outlives this call, so it doesn't inherit ctx.
+	engine := workflow.NewEngine(store, pipeline.NewEventBus())
+	engine.SetCommandPolicy(t.Policy)
+	runID, _, err := engine.Start(context.Background(), wf)
+	if err != nil {
+		return NewErrorResult(fmt.Sprintf("failed to start workflow: %v", err), time.Since(start))
+	}
+	return NewResult(RunWorkflowResult{RunID: runID, Workflow: wf.Name, Steps: len(wf.Steps)}, time.Since(start))
+}
+
+// GetWorkflowStatusTool reports the checkpointed state of a workflow run.
+type GetWorkflowStatusTool struct {
+	DB *sql.DB
+}
+
+func (t *GetWorkflowStatusTool) Name() string { return "get_workflow_status" }
+func (t *GetWorkflowStatusTool) Description() string {
+	return "Get the status and per-step results of a workflow run started with run_workflow or the CLI"
+}
+
+func (t *GetWorkflowStatusTool) Parameters() []ToolParam {
+	return []ToolParam{
+		{Name: "run_id", Type: "string", Description: "Run ID returned by run_workflow", Required: true},
+	}
+}
+
+func (t *GetWorkflowStatusTool) Execute(ctx context.Context, params map[string]any) ToolResult {
+	start := time.Now()
+
+	runID := GetString(params, "run_id", "")
+	if runID == "" {
+		return NewErrorResult("run_id is required", time.Since(start))
+	}
+
+	store := workflow.NewCheckpointStore(t.DB)
+	state, err := store.LoadRun(runID)
+	if err != nil {
+		return NewErrorResult(fmt.Sprintf("failed to load run %q: %v", runID, err), time.Since(start))
+	}
+	data, err := workflow.MarshalRunState(state)
+	if err != nil {
+		return NewErrorResult(fmt.Sprintf("failed to encode run state: %v", err), time.Since(start))
+	}
+	return NewResult(json.RawMessage(data), time.Since(start))
+}
@@ -3,9 +3,17 @@ package tui
 import (
 	"context"
 	"database/sql"
+	"fmt"
 	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
 
+	"dev-cli/internal/config"
+	"dev-cli/internal/executor"
 	"dev-cli/internal/infra"
+	"dev-cli/internal/infra/kube"
 	"dev-cli/internal/llm"
 	"dev-cli/internal/pipeline"
 	"dev-cli/internal/plugins/ai"
@@ -13,15 +21,27 @@ import (
 	"dev-cli/internal/storage"
 	"dev-cli/internal/tui/components"
 	"dev-cli/internal/tui/tabs/agent"
+	"dev-cli/internal/tui/tabs/chat"
+	"dev-cli/internal/tui/tabs/cluster"
 	"dev-cli/internal/tui/tabs/history"
 	"dev-cli/internal/tui/tabs/monitor"
+	"dev-cli/internal/tui/tabs/runbooks"
+	"dev-cli/internal/tui/theme"
+	"dev-cli/internal/workflow"
 
 	"github.com/charmbracelet/bubbles/help"
 	"github.com/charmbracelet/bubbles/spinner"
+	"github.com/charmbracelet/bubbles/textinput"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
+	"github.com/google/uuid"
 )
 
+// loadingTimeout caps how long the loading screen waits on the Docker probe
+// before showing the app anyway.
+const loadingTimeout = 3 * time.Second
+
 type SessionState int
 
 const (
@@ -42,16 +62,60 @@ const (
 	TabAgent Tab = iota
 	TabContainers
 	TabHistory
+	// TabKubernetes is only shown when a kube context is configured.
+	TabKubernetes
+	TabRunbooks
+	TabChat
 )
 
+// tabNames are the names DEV_CLI_TABS picks tabs by.
+var tabNames = map[string]Tab{
+	"agent":      TabAgent,
+	"containers": TabContainers,
+	"history":    TabHistory,
+	"kubernetes": TabKubernetes,
+	"runbooks":   TabRunbooks,
+	"chat":       TabChat,
+}
+
+// defaultTabs is the tab order when none is configured.
+var defaultTabs = []Tab{TabAgent, TabContainers, TabHistory, TabKubernetes, TabRunbooks, TabChat}
+
+// parseTabs turns the configured tab names into tabs, skipping unknown
+// names and repeats. Naming no tab at all shows every one.
+func parseTabs(names []string) []Tab {
+	var tabs []Tab
+	for _, name := range names {
+		if tab, ok := tabNames[name]; ok && !slices.Contains(tabs, tab) {
+			tabs = append(tabs, tab)
+		}
+	}
+	if len(tabs) == 0 {
+		return defaultTabs
+	}
+	return tabs
+}
+
 type Model struct {
 	state     SessionState
 	mode      AppMode
 	activeTab Tab
-	width     int
-	height    int
-	quitting  bool
-	tickCount int
+	// tabOrder is the configured tabs, in order; tabs() drops the ones
+	// that cannot show.
+	tabOrder []Tab
+	width    int
+	height   int
+	quitting bool
+	// quitPending is a quit waiting for the running workflow or runbook
+	// to pause, so its checkpoint can be resumed.
+	quitPending bool
+	tickCount   int
+	// topTicks counts spinner ticks towards the next refresh of the open
+	// process list.
+	topTicks int
+	focused  bool
+	title    string
+	demo     bool
 
 	agent      agent.Model
 	containers monitor.Model
@@ -61,20 +125,86 @@ type Model struct {
 	statusBar components.StatusBar
 	spinner   spinner.Model
 	help      help.Model
-
-	db       *sql.DB
-	aiClient *llm.HybridClient
+	// palette is the open Ctrl+P command palette, nil when closed.
+	palette *commandPalette
+	// notifications are the toasts and the Ctrl+G log of what changed
+	// in the background.
+	notifications components.Notifications
+
+	db *sql.DB
+	// session is this launch, which the commands the Agent runs are
+	// recorded under.
+	session  storage.Session
+	aiClient llm.LLMProvider
+	docker   infra.DockerAPI
+	compose  *infra.ComposeClient
 	pipe     *pipeline.Pipeline
 	cwd      string
+
+	// The selected container's stats stream; see watchStats.
+	statsID     string
+	statsCh     <-chan infra.ContainerStatsSnapshot
+	statsCancel context.CancelFunc
+
+	// The selected container's log stream while follow mode is on.
+	logsID     string
+	logsCh     <-chan string
+	logsCancel context.CancelFunc
+
+	// The cluster behind the Kubernetes tab; nil hides the tab.
+	kube       kube.API
+	kubernetes cluster.Model
+	// podLogs is the pod whose logs were last requested.
+	podLogs string
+
+	runbooks runbooks.Model
+	// The runbook run in progress: cancelling aborts it, and the engine
+	// waits on runbookReply for the step being asked about.
+	runbookCancel context.CancelFunc
+	runbookReply  chan<- bool
+
+	// workflowRun is the last workflow run from the palette, shown in an
+	// overlay while workflowOpen; cancelling workflowCancel pauses it.
+	workflowRun    *workflowRun
+	workflowOpen   bool
+	workflowCancel context.CancelFunc
+
+	chat chat.Model
+
+	// unhealthy holds the containers whose logs were sent for analysis
+	// when they turned unhealthy. Recovering drops a container, so a
+	// relapse is analyzed again.
+	unhealthy map[string]bool
 }
 
+// InitialModel returns the app wired to the real Docker daemon and AI
+// backends, plus the current kube context when there is one.
 func InitialModel() Model {
+	m := NewModel(nil, llm.NewHybridClient())
+	if client, err := kube.NewClient(""); err == nil {
+		m = m.WithKube(client)
+	}
+	return m
+}
+
+// NewModel builds the app around the given backends. A nil docker falls back
+// to the shared daemon client, resolved lazily on first use.
+func NewModel(docker infra.DockerAPI, aiClient llm.LLMProvider) Model {
+	cfg := config.Load()
+	if p, ok := theme.Lookup(cfg.Theme); ok {
+		theme.Apply(p)
+	}
+
+	theme.SetASCII(cfg.ASCII)
+
 	s := spinner.New()
 	s.Spinner = spinner.Dot
-	s.Style = lipgloss.NewStyle().Foreground(lipgloss.Color("#cba6f7"))
+	if cfg.ASCII {
+		s.Spinner = spinner.Line
+	}
+	s.Style = lipgloss.NewStyle().Foreground(theme.Mauve)
 
 	cwd, _ := os.Getwd()
-	aiClient := llm.NewHybridClient()
 
 	pipe := pipeline.NewPipeline()
 
@@ -88,38 +218,100 @@ func InitialModel() Model {
 
 	pipe.State().SetCwd(cwd)
 
-	tabBar := components.NewTabBar([]components.TabItem{
-		{Icon: "◈", Label: "Agent"},
-		{Icon: "⬢", Label: "Containers"},
-		{Icon: "↻", Label: "History"},
-	})
-
-	return Model{
-		state:     StateLoading,
-		mode:      ModeNormal,
-		activeTab: TabAgent,
-		cwd:       cwd,
-		aiClient:  aiClient,
-		pipe:      pipe,
+	m := Model{
+		state:    StateLoading,
+		mode:     ModeNormal,
+		tabOrder: parseTabs(cfg.Tabs),
+		focused:  true,
+		cwd:      cwd,
+		aiClient: aiClient,
+		docker:   docker,
+		compose:  infra.NewComposeClient(docker),
+		pipe:     pipe,
 
 		agent:      agent.New(pipe),
-		containers: monitor.New(),
+		containers: monitor.New().SetExpertMode(cfg.ExpertMode),
 		history:    history.New(),
+		kubernetes: cluster.New(),
+		runbooks:   runbooks.New(),
+		chat:       chat.New(),
+		unhealthy:  make(map[string]bool),
 
-		tabBar:    tabBar,
 		statusBar: components.NewStatusBar(),
 		spinner:   s,
 		help:      help.New(),
+		session:   storage.Session{ID: uuid.New().String(), Source: "tui", Directory: cwd, StartedAt: time.Now()},
+	}
+	m.activeTab = m.tabs()[0]
+	m.tabBar = components.NewTabBar(m.tabItems())
+	return m
+}
+
+// tabs lists the tabs shown, in the configured order; number keys pick
+// them by position. Kubernetes needs a kube context.
+func (m Model) tabs() []Tab {
+	var tabs []Tab
+	for _, tab := range m.tabOrder {
+		if tab != TabKubernetes || m.kube != nil {
+			tabs = append(tabs, tab)
+		}
+	}
+	if len(tabs) == 0 {
+		return []Tab{TabAgent}
 	}
+	return tabs
+}
+
+// showsTab reports whether tab is one of the tabs shown.
+func (m Model) showsTab(tab Tab) bool {
+	return slices.Contains(m.tabs(), tab)
+}
+
+func (m Model) tabItems() []components.TabItem {
+	all := map[Tab]components.TabItem{
+		TabAgent:      {Icon: "◈", Label: "Agent"},
+		TabContainers: {Icon: "⬢", Label: "Containers"},
+		TabHistory:    {Icon: "↻", Label: "History"},
+		TabKubernetes: {Icon: "☸", Label: "Kubernetes"},
+		TabRunbooks:   {Icon: "▤", Label: "Runbooks"},
+		TabChat:       {Icon: "◇", Label: "Chat"},
+	}
+	var items []components.TabItem
+	for _, tab := range m.tabs() {
+		items = append(items, all[tab])
+	}
+	return items
+}
+
+// WithKube adds the Kubernetes tab, backed by client.
+func (m Model) WithKube(client kube.API) Model {
+	m.kube = client
+	m.tabBar = components.NewTabBar(m.tabItems()).SetWidth(m.width)
+	return m
+}
+
+// tabAt is the tab n places after the active one, wrapping around.
+func (m Model) tabAt(n int) Tab {
+	tabs := m.tabs()
+	i := slices.Index(tabs, m.activeTab) + n
+	return tabs[(i%len(tabs)+len(tabs))%len(tabs)]
 }
 
 func (m Model) Init() tea.Cmd {
+	if m.demo {
+		return m.demoInit()
+	}
 	return tea.Batch(
 		m.spinner.Tick,
-		checkDockerHealth,
+		m.checkDockerHealth,
 		checkGPUStats,
+		checkHostStats,
 		checkServices,
 		checkDBAndHistory,
+		m.kubeHealthCmd(),
+		loadChatModels(m.aiClient),
+		reportCwdCmd(m.cwd),
+		tea.Tick(loadingTimeout, func(time.Time) tea.Msg { return loadingTimeoutMsg{} }),
 	)
 }
 
@@ -136,31 +328,550 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		m.agent = m.agent.SetSize(msg.Width, msg.Height-4)
 		m.containers = m.containers.SetSize(msg.Width, msg.Height-4)
 		m.history = m.history.SetSize(msg.Width, msg.Height-4)
+		m.kubernetes = m.kubernetes.SetSize(msg.Width, msg.Height-4)
+		m.runbooks = m.runbooks.SetSize(msg.Width, msg.Height-4)
+		m.chat = m.chat.SetSize(msg.Width, msg.Height-4)
 
 	case dockerHealthMsg:
+		// A missing daemon is not fatal: the app drops into reduced mode
+		// and the Containers tab shows how to start Docker instead.
+		m.state = StateMain
+		if prev := m.pipe.State().DockerHealth; prev.Available && msg.health.Available {
+			for _, change := range containerChanges(prev.Containers, msg.health.Containers) {
+				var cmd tea.Cmd
+				m, cmd = m.notify(change.Level, change.Text)
+				cmds = append(cmds, cmd)
+			}
+		}
+		m.pipe.State().SetAvailable(pipeline.SubsystemDocker, msg.health.Available)
 		m.agent = m.agent.SetDockerHealth(msg.health)
 		m.containers = m.containers.SetServices(msg.health.Containers)
+		m.containers = m.containers.SetDaemon(msg.health.Daemon)
+		if msg.health.Available {
+			m.containers = m.containers.SetProjects(m.compose.Remember(infra.GroupComposeProjects(msg.health.Containers)))
+		} else {
+			m.containers = m.containers.SetProjects(nil)
+		}
+		m.containers = m.containers.SetAvailability(m.pipe.State().Availability(pipeline.SubsystemDocker))
+		if m.containers.MergedLogs() {
+			cmds = append(cmds, m.fetchLogTargets())
+		} else if msg.health.Available && len(msg.health.Containers) > 0 {
+			cmds = append(cmds, m.fetchLogs(msg.health.Containers[0].ID))
+		}
 		if msg.health.Available {
-			m.state = StateMain
-			if len(msg.health.Containers) > 0 {
-				cmds = append(cmds, fetchContainerLogs(msg.health.Containers[0].ID))
+			cmds = append(cmds, m.fetchImages, m.fetchVolumes, m.fetchDiskUsage)
+		}
+		cmds = append(cmds, m.analyzeUnhealthy(msg.health.Containers)...)
+		var cmd tea.Cmd
+		m, cmd = m.watchStats(m.containers.SelectedService())
+		cmds = append(cmds, cmd)
+		m, cmd = m.watchLogs()
+		cmds = append(cmds, cmd)
+
+	case statsStreamMsg:
+		if msg.containerID == m.statsID {
+			if msg.err != nil {
+				m.statsID = ""
+				break
+			}
+			m.statsCh = msg.ch
+			cmds = append(cmds, waitStats(msg.name, msg.ch))
+		}
+
+	case containerStatsMsg:
+		if msg.ch == m.statsCh {
+			if !msg.ok {
+				// The stream ended (container stopped or daemon went
+				// away); the next health check starts a new one.
+				m.statsID, m.statsCh = "", nil
+				break
+			}
+			m.containers = m.containers.AddStatsSample(msg.name, msg.stats)
+			cmds = append(cmds, waitStats(msg.name, msg.ch))
+		}
+
+	case monitor.RunContainerMsg:
+		cmds = append(cmds, m.runContainer(msg.Spec))
+
+	case containerRunMsg:
+		if msg.err != nil {
+			m.containers = m.containers.WizardFailed(msg.err)
+			break
+		}
+		m.containers = m.containers.CloseWizard()
+		m.mode = m.getModeFromTab()
+		cmds = append(cmds, m.checkDockerHealth)
+
+	case monitor.PullImageMsg:
+		if !m.containers.Pulling() {
+			m.containers = m.containers.SetPullProgress(infra.PullProgress{Ref: msg.Ref})
+			cmds = append(cmds, m.pullImage(msg.Ref))
+		}
+
+	case pullProgressMsg:
+		if msg.done {
+			m.containers = m.containers.FinishPull(msg.err)
+			cmds = append(cmds, m.fetchImages)
+			break
+		}
+		m.containers = m.containers.SetPullProgress(msg.progress)
+		cmds = append(cmds, waitPull(msg.ch))
+
+	case imagesMsg:
+		if msg.err == nil {
+			m.containers = m.containers.SetImages(msg.images)
+		}
+
+	case monitor.InspectContainerMsg:
+		cmds = append(cmds, m.inspectContainer(msg.ContainerID))
+
+	case containerInspectedMsg:
+		m.containers = m.containers.SetInspectDetail(msg.containerID, msg.detail, msg.err)
+
+	case monitor.ProcessesMsg:
+		cmds = append(cmds, m.topContainer(msg.ContainerID))
+
+	case processesMsg:
+		m.containers = m.containers.SetProcesses(msg.containerID, msg.procs, msg.err)
+
+	case monitor.SignalProcessMsg:
+		cmds = append(cmds, m.signalProcess(msg))
+
+	case processSignaledMsg:
+		m.containers = m.containers.ProcessSignaled(msg.containerID, msg.pid, msg.signal, msg.err)
+		cmds = append(cmds, m.topContainer(msg.containerID))
+
+	case monitor.AnalyzeLogsMsg:
+		cmds = append(cmds, m.analyzeLogs(msg.Lines))
+
+	case logAnalysisMsg:
+		m.containers = m.containers.SetAnalysis(msg.result, msg.err)
+
+	case monitor.UseFixMsg:
+		// The fix waits in the Agent's input, to be read before Enter.
+		m.containers = m.containers.CloseAnalysis()
+		input := m.agent.Input()
+		input.SetValue(msg.Command)
+		input.CursorEnd()
+		m.agent = m.agent.SetInput(input).SetInsertMode(true)
+		m.activeTab = TabAgent
+		m.mode = m.getModeFromTab()
+
+	case monitor.UpdateResourcesMsg:
+		cmds = append(cmds, m.updateResources(msg))
+
+	case resourcesUpdatedMsg:
+		var cmd tea.Cmd
+		m.containers, cmd = m.containers.ResourcesUpdated(msg.err)
+		cmds = append(cmds, cmd)
+
+	case monitor.RemoveImageMsg:
+		cmds = append(cmds, m.removeImage(msg.Image, msg.Force))
+
+	case imageRemovedMsg:
+		m.containers = m.containers.ImageRemoved(msg.image, msg.err)
+		cmds = append(cmds, m.fetchImages, m.fetchDiskUsage)
+		var cmd tea.Cmd
+		if msg.err != nil {
+			m, cmd = m.notify(components.NotifyError, "Removing "+msg.image+" failed")
+		} else {
+			m, cmd = m.notify(components.NotifySuccess, "Removed image "+msg.image)
+		}
+		cmds = append(cmds, cmd)
+
+	case monitor.ImageHistoryMsg:
+		cmds = append(cmds, m.imageHistory(msg.Image))
+
+	case imageHistoryMsg:
+		m.containers = m.containers.SetLayers(msg.image, msg.layers, msg.err)
+
+	case volumesMsg:
+		if msg.err == nil {
+			m.containers = m.containers.SetVolumes(msg.volumes)
+		}
+
+	case diskUsageMsg:
+		if msg.err == nil {
+			m.containers = m.containers.SetDiskUsage(msg.usage)
+		}
+
+	case monitor.PruneMsg:
+		cmds = append(cmds, m.prune(msg.Kind))
+
+	case pruneDoneMsg:
+		m.containers = m.containers.PruneDone(msg.reclaimed, msg.err)
+		cmds = append(cmds, m.checkDockerHealth)
+
+	case monitor.BackupVolumeMsg:
+		cmds = append(cmds, m.backupVolume(msg.Volume))
+
+	case monitor.ListBackupsMsg:
+		cmds = append(cmds, listBackups(msg.Volume))
+
+	case backupsMsg:
+		m.containers = m.containers.OpenRestore(msg.volume, msg.backups, msg.err)
+		m.mode = m.getModeFromTab()
+
+	case monitor.RestoreVolumeMsg:
+		cmds = append(cmds, m.restoreVolume(msg.Volume, msg.Backup))
+
+	case monitor.RemoveVolumeMsg:
+		cmds = append(cmds, m.removeVolume(msg.Volume))
+
+	case volumeOpMsg:
+		m.containers = m.containers.VolumeOpDone(msg.status, msg.err)
+		cmds = append(cmds, m.fetchVolumes, m.fetchDiskUsage)
+
+	case logStreamMsg:
+		if msg.containerID == m.logsID {
+			if msg.err != nil {
+				m.logsID = ""
+				break
+			}
+			m.logsCh = msg.ch
+			cmds = append(cmds, waitLogs(msg.ch))
+		}
+
+	case logLinesMsg:
+		if msg.ch == m.logsCh {
+			m.containers = m.containers.AppendLogLines(msg.lines...)
+			if !msg.ok {
+				m.logsID, m.logsCh = "", nil
+				break
 			}
+			cmds = append(cmds, waitLogs(msg.ch))
+		}
+
+	case kubeHealthMsg:
+		m.kubernetes = m.kubernetes.SetHealth(msg.health)
+		// Logs are refreshed with the listing while the tab is on screen.
+		if pod := m.kubernetes.SelectedPod(); pod != nil && (pod.Name != m.podLogs || m.activeTab == TabKubernetes) {
+			m.podLogs = pod.Name
+			cmds = append(cmds, m.fetchPodLogs(*pod))
+		}
+
+	case podLogsMsg:
+		if pod := m.kubernetes.SelectedPod(); pod != nil && pod.Name == msg.pod {
+			m.kubernetes = m.kubernetes.SetLogLines(msg.lines, msg.err)
+		}
+
+	case loadingTimeoutMsg:
+		m.state = StateMain
+
+	case monitor.ExecContainerMsg:
+		dockerClient, err := m.dockerClient()
+		if err != nil {
+			m.containers = m.containers.SetExecError(err)
+			break
+		}
+		session := dockerClient.ContainerExec(msg.ContainerID)
+		cmds = append(cmds, tea.Exec(session, func(err error) tea.Msg {
+			return containerExecDoneMsg{containerID: msg.ContainerID, err: err}
+		}))
+
+	case monitor.ContainerActionMsg:
+		cmds = append(cmds, m.containerAction(msg))
+
+	case containerActionDoneMsg:
+		m.containers = m.containers.SetExecError(msg.err)
+		cmds = append(cmds, m.checkDockerHealth)
+
+	case monitor.BulkActionMsg:
+		cmds = append(cmds, m.bulkAction(msg))
+
+	case bulkActionDoneMsg:
+		var err error
+		if len(msg.errs) > 0 {
+			err = msg.errs[0]
+		}
+		m.containers = m.containers.SetExecError(err)
+		cmds = append(cmds, m.checkDockerHealth)
+		// The health check reports each container that changed; failures
+		// are summed up here.
+		if err != nil {
+			var cmd tea.Cmd
+			m, cmd = m.notify(components.NotifyError, fmt.Sprintf("%s failed for %d of %d containers", msg.verb, len(msg.errs), msg.total))
+			cmds = append(cmds, cmd)
+		}
+
+	case agent.InteractiveMsg:
+		session := executor.Interactive(msg.Command)
+		cmds = append(cmds, tea.Exec(session, func(err error) tea.Msg {
+			return interactiveDoneMsg{result: session.Result(), err: err}
+		}))
+
+	case interactiveDoneMsg:
+		m.agent = m.agent.InteractiveDone(msg.result, msg.err)
+		if blocks := m.agent.Blocks(); len(blocks) > 0 {
+			cmds = append(cmds, m.recordCommand(blocks[len(blocks)-1]))
 		}
 
+	case containerExecDoneMsg:
+		m.containers = m.containers.SetExecError(msg.err)
+		cmds = append(cmds, m.checkDockerHealth)
+
+	case monitor.ProjectActionMsg:
+		cmds = append(cmds, m.composeAction(msg))
+
+	case projectActionDoneMsg:
+		m.containers = m.containers.SetProjectError(msg.err)
+		cmds = append(cmds, m.checkDockerHealth)
+
+	case monitor.ListDirMsg:
+		cmds = append(cmds, m.listDir(msg))
+
+	case dirListedMsg:
+		m.containers = m.containers.SetDirEntries(msg.containerID, msg.path, msg.entries, msg.err)
+
+	case monitor.CopyFileMsg:
+		cmds = append(cmds, m.copyFile(msg))
+
+	case fileCopiedMsg:
+		m.containers = m.containers.FileCopied(msg.status, msg.err)
+
+	case monitor.MergeLogsMsg:
+		cmds = append(cmds, m.fetchLogTargets())
+
 	case containerLogsMsg:
-		m.containers = m.containers.SetLogLines(msg.lines)
+		// A reply for the other mode arrives after the user switched.
+		if msg.merged == m.containers.MergedLogs() {
+			m.containers = m.containers.SetLogLines(msg.lines)
+		}
 
 	case gpuStatsMsg:
 		m.agent = m.agent.SetGPUStats(msg.stats)
 
+	case hostStatsMsg:
+		if msg.err == nil {
+			m.containers = m.containers.SetHostStats(msg.stats)
+		}
+
 	case serviceHealthMsg:
-		_ = msg.services
+		for _, svc := range msg.services {
+			if svc.Name == "Ollama" {
+				m.pipe.State().SetAvailable(pipeline.SubsystemOllama, svc.Available)
+			}
+		}
 
 	case historyLoadedMsg:
+		m.pipe.State().SetAvailable(pipeline.SubsystemHistory, msg.err == nil)
+		m.history = m.history.SetAvailability(m.pipe.State().Availability(pipeline.SubsystemHistory))
+		m.runbooks = m.runbooks.SetAvailability(m.pipe.State().Availability(pipeline.SubsystemHistory))
 		if msg.err == nil {
 			m.db = msg.db
 			m.history = m.history.SetHistory(msg.history)
+			if p, ok := m.pipe.GetPlugin("ai").(*ai.Plugin); ok {
+				p.SetDB(msg.db)
+			}
+			if msg.db != nil {
+				cmds = append(cmds, loadCompletions(msg.db), loadRunbooks(msg.db), loadBookmarks(msg.db), startSession(msg.db, m.session))
+			}
+		}
+
+	case history.FilterMsg:
+		cmds = append(cmds, m.queryHistory(msg.Filter))
+
+	case historyFilteredMsg:
+		// A later filter may have been picked while this one was queried.
+		if msg.err == nil && msg.filter == m.history.Filter() {
+			m.history = m.history.SetHistory(msg.history)
+		}
+
+	case history.StatsMsg:
+		cmds = append(cmds, m.queryStats(msg.Filter))
+
+	case history.SessionsMsg:
+		cmds = append(cmds, m.querySessions())
+
+	case sessionsLoadedMsg:
+		if msg.err == nil {
+			m.history = m.history.SetSessions(msg.sessions)
+		}
+
+	case history.ReplayMsg:
+		cmds = append(cmds, m.querySessionReplay(msg.SessionID))
+
+	case history.DraftWorkflowMsg:
+		cmds = append(cmds, draftWorkflow(msg.Commands, time.Now()))
+
+	case agent.DraftWorkflowMsg:
+		cmds = append(cmds, draftWorkflow(msg.Commands, time.Now()))
+
+	case workflowDraftedMsg:
+		var cmd tea.Cmd
+		if msg.err != nil {
+			m, cmd = m.notify(components.NotifyError, fmt.Sprintf("Drafting a workflow failed: %v", msg.err))
+		} else {
+			m, cmd = m.notify(components.NotifySuccess, fmt.Sprintf("Drafted a %d-step workflow in %s; replace its placeholder rollbacks before running it", msg.steps, msg.path))
+		}
+		cmds = append(cmds, cmd)
+
+	case sessionReplayMsg:
+		if msg.err == nil {
+			m.agent = m.agent.ReplaySession(msg.history)
+			m.activeTab = TabAgent
+			m.mode = m.getModeFromTab()
+		}
+
+	case historyStatsMsg:
+		if msg.err == nil && msg.filter == m.history.Filter() {
+			m.history = m.history.SetStats(msg.stats)
+		}
+
+	case runbooksLoadedMsg:
+		if msg.err == nil {
+			m.runbooks = m.runbooks.SetRunbooks(msg.runbooks)
+		}
+
+	case runbooks.SaveMsg:
+		cmds = append(cmds, saveRunbook(m.db, msg.Runbook))
+
+	case runbookSavedMsg:
+		m.runbooks = m.runbooks.SetError(msg.err)
+		cmds = append(cmds, loadRunbooks(m.db))
+
+	case runbooks.RunMsg:
+		ctx, cancel := context.WithCancel(context.Background())
+		m.runbookCancel = cancel
+		cmds = append(cmds, runRunbook(ctx, m.db, msg.Runbook))
+
+	case runbookProgressMsg:
+		switch {
+		case msg.done:
+			if m.runbookCancel != nil {
+				m.runbookCancel()
+			}
+			m.runbookCancel, m.runbookReply = nil, nil
+			if m.readyToQuit() {
+				m.quitting = true
+				return m, tea.Quit
+			}
+			m.runbooks = m.runbooks.RunDone(msg.result, msg.err)
+			var cmd tea.Cmd
+			switch {
+			case msg.err != nil:
+				m, cmd = m.notify(components.NotifyError, fmt.Sprintf("Runbook %s failed: %v", msg.name, msg.err))
+			case msg.result.Status == workflow.StatusCompleted:
+				m, cmd = m.notify(components.NotifySuccess, "Runbook "+msg.name+" completed")
+			default:
+				m, cmd = m.notify(components.NotifyWarning, fmt.Sprintf("Runbook %s %s", msg.name, msg.result.Status))
+			}
+			cmds = append(cmds, cmd)
+			// The run updated the runbook's success rate.
+			cmds = append(cmds, loadRunbooks(m.db))
+		case msg.asking != "":
+			m.runbookReply = msg.reply
+			m.runbooks = m.runbooks.StepAsked(msg.asking)
+			cmds = append(cmds, waitRunbook(msg.ch))
+		default:
+			m.runbooks = m.runbooks.StepDone(msg.stepID, msg.status, msg.exitCode)
+			cmds = append(cmds, waitRunbook(msg.ch))
+		}
+
+	case workflowProgressMsg:
+		if msg.warning != nil {
+			var cmd tea.Cmd
+			m, cmd = m.notify(components.NotifyWarning, msg.warning.Error())
+			cmds = append(cmds, cmd, waitWorkflow(msg.ch))
+			break
+		}
+		if m.workflowRun == nil {
+			break
+		}
+		m.workflowRun.apply(msg, time.Now())
+		if !msg.done {
+			cmds = append(cmds, waitWorkflow(msg.ch))
+			break
 		}
+		if m.workflowCancel != nil {
+			m.workflowCancel()
+			m.workflowCancel = nil
+		}
+		if m.readyToQuit() {
+			m.quitting = true
+			return m, tea.Quit
+		}
+		run := m.workflowRun
+		var cmd tea.Cmd
+		switch {
+		case msg.result == nil:
+			m, cmd = m.notify(components.NotifyError, fmt.Sprintf("Workflow %s failed: %s", run.name, run.err))
+		case run.status == workflow.StatusCompleted:
+			m, cmd = m.notify(components.NotifySuccess, fmt.Sprintf("Workflow %s completed in %s", run.name, formatElapsed(run.ended.Sub(run.started))))
+		case run.status == workflow.StatusPaused:
+			m, cmd = m.notify(components.NotifyWarning, fmt.Sprintf("Workflow %s paused; resume it with dev-cli workflow resume %s", run.name, msg.result.RunID))
+		default:
+			m, cmd = m.notify(components.NotifyError, fmt.Sprintf("Workflow %s %s: %s", run.name, run.status, run.err))
+		}
+		cmds = append(cmds, cmd)
+
+	case runbooks.AnswerMsg:
+		if m.runbookReply != nil {
+			m.runbookReply <- msg.Run
+			m.runbookReply = nil
+		}
+
+	case runbooks.AbortMsg:
+		if m.runbookCancel != nil {
+			m.runbookCancel()
+		}
+
+	case chat.ModelsMsg:
+		cmds = append(cmds, loadChatModels(m.aiClient))
+
+	case chatModelsMsg:
+		m.chat = m.chat.SetModels(msg.models, msg.err)
+
+	case chat.SendMsg:
+		cmds = append(cmds, m.askChat(msg.Model, msg.Messages))
+
+	case chatStreamMsg:
+		if msg.done {
+			m.chat = m.chat.FinishReply(msg.reply, msg.err)
+			if m.activeTab != TabChat {
+				var cmd tea.Cmd
+				if msg.err != nil {
+					m, cmd = m.notify(components.NotifyError, "Chat failed: "+msg.err.Error())
+				} else if model, ok := m.chat.SelectedModel(); ok {
+					m, cmd = m.notify(components.NotifySuccess, "Chat: "+model.String()+" answered")
+				}
+				cmds = append(cmds, cmd)
+			}
+		} else {
+			m.chat = m.chat.AppendReply(msg.delta)
+			cmds = append(cmds, waitChat(msg.ch))
+		}
+
+	case completionsLoadedMsg:
+		m.agent = m.agent.SetCompletions(msg.history, msg.executables)
+
+	case agent.SearchHistoryMsg:
+		cmds = append(cmds, loadSearchHistory(m.db))
+
+	case searchHistoryLoadedMsg:
+		m.agent = m.agent.SetSearchHistory(msg.commands)
+
+	case agent.CopyMsg:
+		cmds = append(cmds, copyToClipboardCmd(msg.Text, msg.What))
+
+	case clipboardCopiedMsg:
+		m.agent = m.agent.Copied(msg.what, msg.err)
+
+	case bookmarksLoadedMsg:
+		if msg.err == nil {
+			m.agent = m.agent.SetBookmarks(msg.bookmarks)
+		}
+
+	case agent.BookmarkMsg:
+		cmds = append(cmds, saveBookmark(m.db, msg.BlockID, msg.Bookmark))
+
+	case bookmarkSavedMsg:
+		var cmd tea.Cmd
+		m.agent, cmd = m.agent.Bookmarked(msg.blockID, msg.id, msg.err)
+		cmds = append(cmds, cmd)
+
+	case agent.UnbookmarkMsg:
+		cmds = append(cmds, deleteBookmark(m.db, msg.ID))
 
 	case starshipLineMsg:
 		m.agent = m.agent.SetStarshipLine(msg.line)
@@ -170,47 +881,116 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		m.spinner, cmd = m.spinner.Update(msg)
 		cmds = append(cmds, cmd)
 
+		if id := m.containers.ProcessesContainer(); id != "" {
+			m.topTicks++
+			if m.topTicks >= processesRefreshTicks {
+				m.topTicks = 0
+				cmds = append(cmds, m.topContainer(id))
+			}
+		}
+
 		m.tickCount++
-		if m.tickCount >= 10 {
+		if m.tickCount >= 10 && !m.demo {
 			m.tickCount = 0
-			cmds = append(cmds, checkGPUStats, checkDockerHealth, checkServices, checkStarshipLine)
+			cmds = append(cmds, checkGPUStats, checkHostStats, m.checkDockerHealth, checkServices, checkStarshipLine, m.kubeHealthCmd())
 		}
 
+	case tea.FocusMsg:
+		m.focused = true
+
+	case tea.BlurMsg:
+		m.focused = false
+
 	case agent.CommandExecutedMsg:
 		var cmd tea.Cmd
 		m.agent, cmd = m.agent.Update(msg, agent.DefaultKeyMap())
 		m.mode = m.getModeFromTab()
 		cmds = append(cmds, cmd)
 
+		if block := m.pipe.State().GetBlock(msg.BlockID); block != nil {
+			cmds = append(cmds, m.recordCommand(*block))
+			if msg.FixFor != "" {
+				cmds = append(cmds, m.recordFix(msg.FixFor, *block))
+			}
+		}
+		if block := m.pipe.State().GetBlock(msg.BlockID); block != nil && !m.focused && block.Duration >= longCommandThreshold {
+			status := "finished"
+			if block.ExitCode != 0 {
+				status = fmt.Sprintf("failed (exit %d)", block.ExitCode)
+			}
+			cmds = append(cmds, notifyCmd("dev-cli", block.Command+" "+status))
+		}
+		if block := m.pipe.State().GetBlock(msg.BlockID); block != nil && strings.HasPrefix(block.Command, "dev-cli workflow run ") {
+			name := strings.TrimPrefix(block.Command, "dev-cli workflow run ")
+			if block.ExitCode == 0 {
+				m, cmd = m.notify(components.NotifySuccess, "Workflow "+name+" completed")
+			} else {
+				m, cmd = m.notify(components.NotifyError, fmt.Sprintf("Workflow %s failed (exit %d)", name, block.ExitCode))
+			}
+			cmds = append(cmds, cmd)
+		}
+
 	case agent.AIResponseMsg:
 		var cmd tea.Cmd
 		m.agent, cmd = m.agent.Update(msg, agent.DefaultKeyMap())
 		cmds = append(cmds, cmd)
 
+		// Answers arriving while another tab is shown would go unnoticed.
+		if block := m.pipe.State().GetBlock(msg.BlockID); block != nil && m.activeTab != TabAgent {
+			if msg.Error != nil {
+				m, cmd = m.notify(components.NotifyError, "AI failed on "+block.Command+": "+msg.Error.Error())
+			} else {
+				m, cmd = m.notify(components.NotifySuccess, "AI answered "+block.Command)
+			}
+			cmds = append(cmds, cmd)
+		}
+
+	case toastExpiredMsg:
+		m.notifications = m.notifications.Expire(msg.id)
+
 	case tea.KeyMsg:
-		if msg.String() == "ctrl+c" {
-			m.quitting = true
-			return m, tea.Quit
+		// In the Agent tab, Ctrl+C interrupts a running command first.
+		if msg.String() == "ctrl+c" && !(m.activeTab == TabAgent && m.agent.CommandRunning()) {
+			return m.quit()
+		}
+
+		if m.palette != nil {
+			return m.updatePalette(msg)
+		}
+		if m.workflowOpen && msg.String() != "ctrl+p" {
+			return m.updateWorkflowRun(msg)
+		}
+		if m.notifications.Open() {
+			return m.updateNotifications(msg)
+		}
+		if msg.String() == "ctrl+g" {
+			m.notifications = m.notifications.Toggle()
+			return m, nil
+		}
+		if msg.String() == "ctrl+p" && !m.agent.SearchOpen() && !m.agent.FindOpen() && !m.agent.EditorOpen() && !m.containers.ModalOpen() && !m.runbooks.Editing() && !m.chat.InsertMode() && !m.chat.PickerOpen() {
+			m.palette = newCommandPalette(m.paletteActions())
+			m.mode = m.getModeFromTab()
+			return m, textinput.Blink
 		}
 
 		if m.mode == ModeNormal {
 			switch msg.String() {
 			case "tab":
-				m.activeTab = Tab((int(m.activeTab) + 1) % 3)
+				m.activeTab = m.tabAt(1)
 			case "shift+tab":
-				m.activeTab = Tab((int(m.activeTab) + 2) % 3)
-			case "1":
-				m.activeTab = TabAgent
-			case "2":
-				m.activeTab = TabContainers
-				if m.containers.SelectedService() != nil {
-					cmds = append(cmds, fetchContainerLogs(m.containers.SelectedService().ID))
+				m.activeTab = m.tabAt(-1)
+			case "1", "2", "3", "4", "5", "6":
+				if n := int(msg.Runes[0] - '0'); n <= len(m.tabs()) {
+					m.activeTab = m.tabs()[n-1]
+					if m.activeTab == TabContainers {
+						cmds = append(cmds, m.fetchLogTargets())
+					}
 				}
-			case "3":
-				m.activeTab = TabHistory
+			case "T":
+				theme.Apply(theme.Next())
+				m.spinner.Style = lipgloss.NewStyle().Foreground(theme.Mauve)
 			case "q":
-				m.quitting = true
-				return m, tea.Quit
+				return m.quit()
 			}
 		}
 
@@ -223,58 +1003,129 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 
 		case TabContainers:
 			oldCursor := m.containers.ServicesList().Index()
+			wasRecording := m.containers.IsRecording()
 			m.containers, cmd = m.containers.Update(msg, monitor.DefaultKeyMap())
+			m.mode = m.getModeFromTab()
 			cmds = append(cmds, cmd)
 
+			if recording := m.containers.IsRecording(); recording != wasRecording {
+				if recording {
+					m, cmd = m.notify(components.NotifyInfo, "Recording logs to "+m.containers.RecordingPath())
+				} else {
+					m, cmd = m.notify(components.NotifySuccess, "Saved the log recording to "+m.containers.RecordingPath())
+				}
+				cmds = append(cmds, cmd)
+			}
+
 			if m.containers.ServicesList().Index() != oldCursor {
-				if svc := m.containers.SelectedService(); svc != nil {
-					cmds = append(cmds, fetchContainerLogs(svc.ID))
+				if svc := m.containers.SelectedService(); svc != nil && !m.containers.MergedLogs() {
+					cmds = append(cmds, m.fetchLogs(svc.ID))
 				}
+				m, cmd = m.watchStats(m.containers.SelectedService())
+				cmds = append(cmds, cmd)
 			}
+			m, cmd = m.watchLogs()
+			cmds = append(cmds, cmd)
 
 		case TabHistory:
 			m.history, cmd = m.history.Update(msg, history.DefaultKeyMap())
 			cmds = append(cmds, cmd)
+
+		case TabKubernetes:
+			m.kubernetes, cmd = m.kubernetes.Update(msg, cluster.DefaultKeyMap())
+			cmds = append(cmds, cmd)
+
+			if pod := m.kubernetes.SelectedPod(); pod != nil && pod.Name != m.podLogs {
+				m.podLogs = pod.Name
+				cmds = append(cmds, m.fetchPodLogs(*pod))
+			}
+
+		case TabRunbooks:
+			m.runbooks, cmd = m.runbooks.Update(msg, runbooks.DefaultKeyMap())
+			m.mode = m.getModeFromTab()
+			cmds = append(cmds, cmd)
+
+		case TabChat:
+			m.chat, cmd = m.chat.Update(msg, chat.DefaultKeyMap())
+			m.mode = m.getModeFromTab()
+			cmds = append(cmds, cmd)
 		}
 	}
 
+	if title := m.windowTitle(); title != m.title {
+		m.title = title
+		cmds = append(cmds, tea.SetWindowTitle(title))
+	}
+
 	return m, tea.Batch(cmds...)
 }
 
 func (m Model) getModeFromTab() AppMode {
+	if m.palette != nil {
+		return ModeInsert
+	}
 	switch m.activeTab {
 	case TabAgent:
-		if m.agent.InsertMode() {
+		if m.agent.InsertMode() || m.agent.SearchOpen() || m.agent.FindOpen() {
+			return ModeInsert
+		}
+	case TabContainers:
+		if m.containers.ModalOpen() {
+			return ModeInsert
+		}
+	case TabRunbooks:
+		if m.runbooks.Editing() {
+			return ModeInsert
+		}
+	case TabChat:
+		if m.chat.InsertMode() || m.chat.PickerOpen() {
 			return ModeInsert
 		}
 	}
 	return ModeNormal
 }
 
+// quit ends the program. A running workflow or runbook is paused first
+// and the quit waits for its checkpoint; quitting again doesn't wait.
+func (m Model) quit() (Model, tea.Cmd) {
+	if m.quitPending || (m.workflowCancel == nil && m.runbookCancel == nil) {
+		m.quitting = true
+		return m, tea.Quit
+	}
+	m.quitPending = true
+	if m.workflowCancel != nil {
+		m.workflowCancel()
+	}
+	if m.runbookCancel != nil {
+		m.runbookCancel()
+	}
+	return m.notify(components.NotifyInfo, "Pausing the run before quitting; quit again to leave now")
+}
+
+// readyToQuit reports whether a pending quit has no run left to wait for.
+func (m Model) readyToQuit() bool {
+	return m.quitPending && m.workflowCancel == nil && m.runbookCancel == nil
+}
+
 func (m Model) View() string {
 	if m.quitting {
 		return "Goodbye!\n"
 	}
 
 	if m.state == StateLoading {
-		return m.viewLoading()
+		return theme.Plain(m.viewLoading())
 	}
 
-	return m.viewMain()
+	return theme.Plain(m.viewMain())
 }
 
 func (m Model) viewLoading() string {
-	title := lipgloss.NewStyle().
-		Bold(true).
-		Foreground(lipgloss.Color("#11111b")).
-		Background(lipgloss.Color("#cba6f7")).
-		Padding(0, 1).
-		Render("dev-cli")
+	title := theme.Title.Render("dev-cli")
 
 	status := m.spinner.View() + " Initializing..."
 	box := lipgloss.NewStyle().
 		Border(lipgloss.RoundedBorder()).
-		BorderForeground(lipgloss.Color("#585b70")).
+		BorderForeground(theme.Surface2).
 		Padding(1, 2).
 		Render(status)
 
@@ -282,7 +1133,7 @@ func (m Model) viewLoading() string {
 }
 
 func (m Model) viewMain() string {
-	m.tabBar = m.tabBar.SetActive(int(m.activeTab)).SetInsertMode(m.mode == ModeInsert)
+	m.tabBar = m.tabBar.SetActive(slices.Index(m.tabs(), m.activeTab)).SetInsertMode(m.mode == ModeInsert).SetUnread(m.notifications.Unread())
 	tabBar := m.tabBar.Render()
 
 	var content string
@@ -293,13 +1144,33 @@ func (m Model) viewMain() string {
 		content = m.containers.View()
 	case TabHistory:
 		content = m.history.View()
+	case TabKubernetes:
+		content = m.kubernetes.View()
+	case TabRunbooks:
+		content = m.runbooks.View()
+	case TabChat:
+		content = m.chat.View()
 	}
 
 	contentHeight := m.height - 3
 	if contentHeight < 10 {
 		contentHeight = 10
 	}
+	if m.palette != nil {
+		content = lipgloss.Place(m.width, contentHeight, lipgloss.Center, lipgloss.Top,
+			m.palette.view(min(m.width-4, 72), contentHeight))
+	} else if m.workflowOpen {
+		spinner := strings.TrimSpace(ansi.Strip(m.spinner.View()))
+		content = lipgloss.Place(m.width, contentHeight, lipgloss.Center, lipgloss.Top,
+			m.workflowRun.view(min(m.width-4, 100), contentHeight, spinner, time.Now()))
+	}
 	styledContent := lipgloss.NewStyle().Height(contentHeight).MaxWidth(m.width).Render(content)
+	if m.notifications.Open() {
+		log := m.notifications.RenderLog(min(m.width-4, 64), contentHeight, time.Now())
+		styledContent = components.Overlay(styledContent, log, max(m.width-lipgloss.Width(log)-1, 0), 0)
+	} else if toasts := m.notifications.RenderToasts(); toasts != "" {
+		styledContent = components.Overlay(styledContent, toasts, max(m.width-lipgloss.Width(toasts)-1, 0), 0)
+	}
 
 	focusLabel := m.getFocusLabel()
 	var statusBar string
@@ -310,6 +1181,12 @@ func (m Model) viewMain() string {
 		statusBar = m.statusBar.Render(MonitorKeys, focusLabel)
 	case TabHistory:
 		statusBar = m.statusBar.Render(HistoryKeys, focusLabel)
+	case TabKubernetes:
+		statusBar = m.statusBar.Render(KubeKeys, focusLabel)
+	case TabRunbooks:
+		statusBar = m.statusBar.Render(RunbookKeys, focusLabel)
+	case TabChat:
+		statusBar = m.statusBar.Render(ChatKeys, focusLabel)
 	}
 
 	return lipgloss.JoinVertical(lipgloss.Left, tabBar, styledContent, statusBar)
@@ -329,6 +1206,12 @@ func (m Model) getFocusLabel() string {
 			return "Logs"
 		case monitor.FocusStats:
 			return "Stats"
+		case monitor.FocusProjects:
+			return "Projects"
+		case monitor.FocusVolumes:
+			return "Volumes"
+		case monitor.FocusDisk:
+			return "Disk"
 		}
 		return "Containers"
 	case TabHistory:
@@ -336,19 +1219,48 @@ func (m Model) getFocusLabel() string {
 			return "History"
 		}
 		return "Details"
+	case TabKubernetes:
+		switch m.kubernetes.Focus() {
+		case cluster.FocusPods:
+			return "Pods"
+		case cluster.FocusDeployments:
+			return "Deployments"
+		case cluster.FocusLogs:
+			return "Logs"
+		}
+		return "Kubernetes"
+	case TabRunbooks:
+		if m.runbooks.Focus() == runbooks.FocusSteps {
+			return "Steps"
+		}
+		return "Runbooks"
+	case TabChat:
+		if m.chat.PickerOpen() {
+			return "Models"
+		}
+		return "Chat"
 	}
 	return "Main"
 }
 
 type containerLogsMsg struct {
 	containerID string
-	lines       []string
-	err         error
+	// merged is set for interleaved logs of several containers.
+	merged bool
+	lines  []string
+	err    error
+}
+
+func (m Model) dockerClient() (infra.DockerAPI, error) {
+	if m.docker != nil {
+		return m.docker, nil
+	}
+	return infra.GetSharedDockerClient()
 }
 
-func fetchContainerLogs(containerID string) tea.Cmd {
+func (m Model) fetchLogs(containerID string) tea.Cmd {
 	return func() tea.Msg {
-		dockerClient, err := infra.GetSharedDockerClient()
+		dockerClient, err := m.dockerClient()
 		if err != nil {
 			return containerLogsMsg{containerID: containerID, err: err}
 		}
@@ -362,8 +1274,499 @@ func fetchContainerLogs(containerID string) tea.Cmd {
 	}
 }
 
-func checkDockerHealth() tea.Msg {
-	dockerClient, err := infra.GetSharedDockerClient()
+// fetchLogTargets loads the logs panel: the merged containers when there
+// are any, the selected service otherwise.
+func (m Model) fetchLogTargets() tea.Cmd {
+	if !m.containers.MergedLogs() {
+		if svc := m.containers.SelectedService(); svc != nil {
+			return m.fetchLogs(svc.ID)
+		}
+		return nil
+	}
+
+	targets := m.containers.LogTargets()
+	return func() tea.Msg {
+		dockerClient, err := m.dockerClient()
+		if err != nil {
+			return containerLogsMsg{merged: true, err: err}
+		}
+		lines, err := infra.GetMergedLogs(context.Background(), dockerClient, targets, 100)
+		return containerLogsMsg{merged: true, lines: lines, err: err}
+	}
+}
+
+func (m Model) listDir(msg monitor.ListDirMsg) tea.Cmd {
+	return func() tea.Msg {
+		dockerClient, err := m.dockerClient()
+		if err != nil {
+			return dirListedMsg{containerID: msg.ContainerID, path: msg.Path, err: err}
+		}
+		entries, err := dockerClient.ListContainerDir(context.Background(), msg.ContainerID, msg.Path)
+		return dirListedMsg{containerID: msg.ContainerID, path: msg.Path, entries: entries, err: err}
+	}
+}
+
+// copyFile runs a file browser copy. Relative host paths are taken from
+// the directory dev-cli was started in.
+func (m Model) copyFile(msg monitor.CopyFileMsg) tea.Cmd {
+	host := msg.HostPath
+	if !filepath.IsAbs(host) {
+		host = filepath.Join(m.cwd, host)
+	}
+	return func() tea.Msg {
+		dockerClient, err := m.dockerClient()
+		if err != nil {
+			return fileCopiedMsg{err: err}
+		}
+		if msg.Upload {
+			err = dockerClient.CopyToContainer(context.Background(), msg.ContainerID, host, msg.ContainerPath)
+			return fileCopiedMsg{status: "Uploaded " + msg.HostPath + " to " + msg.ContainerPath, err: err}
+		}
+		err = dockerClient.CopyFromContainer(context.Background(), msg.ContainerID, msg.ContainerPath, host)
+		return fileCopiedMsg{status: "Saved " + msg.ContainerPath + " to " + msg.HostPath, err: err}
+	}
+}
+
+// analyzeUnhealthy starts a log analysis for every container that turned
+// unhealthy since the last health check. The result lands in the Agent
+// tab as an AI block.
+func (m Model) analyzeUnhealthy(containers []infra.ContainerInfo) []tea.Cmd {
+	var cmds []tea.Cmd
+	seen := make(map[string]bool, len(containers))
+	for _, c := range containers {
+		if c.Health != "unhealthy" {
+			continue
+		}
+		seen[c.ID] = true
+		if m.unhealthy[c.ID] {
+			continue
+		}
+		m.unhealthy[c.ID] = true
+		cmds = append(cmds, m.analyzeContainerLogs(c))
+		if !m.focused {
+			cmds = append(cmds, notifyCmd("dev-cli", c.Name+" is unhealthy"))
+		}
+	}
+	for id := range m.unhealthy {
+		if !seen[id] {
+			delete(m.unhealthy, id)
+		}
+	}
+	return cmds
+}
+
+func (m Model) analyzeContainerLogs(c infra.ContainerInfo) tea.Cmd {
+	cmdPlugin, _ := m.pipe.GetPlugin("command").(*command.Plugin)
+	aiPlugin, _ := m.pipe.GetPlugin("ai").(*ai.Plugin)
+	if cmdPlugin == nil || aiPlugin == nil {
+		return nil
+	}
+	return func() tea.Msg {
+		var lines []string
+		if dockerClient, err := m.dockerClient(); err == nil {
+			lines, _ = dockerClient.GetContainerLogs(context.Background(), c.ID, 50)
+		}
+		b := cmdPlugin.ExecuteAI("Analyze logs of unhealthy container " + c.Name)
+		aiPlugin.AnalyzeContainerLogs(b.ID, c.Name, lines)
+		return nil
+	}
+}
+
+// analyzeLogs asks the AI what went wrong in lines, masking secrets first.
+func (m Model) analyzeLogs(lines []string) tea.Cmd {
+	aiClient := m.aiClient
+	return func() tea.Msg {
+		if aiClient == nil {
+			return logAnalysisMsg{err: fmt.Errorf("no AI backend configured")}
+		}
+		result, err := aiClient.AnalyzeLog(llm.SanitizeForLLM(strings.Join(lines, "\n")), "")
+		return logAnalysisMsg{result: result, err: err}
+	}
+}
+
+// watchStats keeps a single stats stream open for the selected container,
+// replacing the previous one when the selection changes. The demo keeps its
+// scripted stats, and stopped containers have nothing to stream.
+func (m Model) watchStats(svc *infra.ContainerInfo) (Model, tea.Cmd) {
+	if svc != nil && svc.ID == m.statsID && svc.State == "running" {
+		return m, nil
+	}
+	if m.statsCancel != nil {
+		m.statsCancel()
+	}
+	m.statsID, m.statsCh, m.statsCancel = "", nil, nil
+	if m.demo || svc == nil || svc.State != "running" {
+		return m, nil
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	m.statsID, m.statsCancel = svc.ID, cancel
+	id, name := svc.ID, svc.Name
+	return m, func() tea.Msg {
+		dockerClient, err := m.dockerClient()
+		if err != nil {
+			return statsStreamMsg{containerID: id, err: err}
+		}
+		ch, err := dockerClient.StreamContainerStats(ctx, id)
+		return statsStreamMsg{containerID: id, name: name, ch: ch, err: err}
+	}
+}
+
+// waitStats delivers the next frame of a stats stream.
+func waitStats(name string, ch <-chan infra.ContainerStatsSnapshot) tea.Cmd {
+	return func() tea.Msg {
+		stats, ok := <-ch
+		return containerStatsMsg{name: name, ch: ch, stats: stats, ok: ok}
+	}
+}
+
+// watchLogs streams new log lines while follow mode is on: the selected
+// container's, or those of every running merged container. It stops the
+// stream when follow is turned off or the targets change.
+func (m Model) watchLogs() (Model, tea.Cmd) {
+	merged := m.containers.MergedLogs()
+	var targets []infra.ContainerInfo
+	if m.containers.FollowMode() && !m.demo {
+		for _, c := range m.containers.LogTargets() {
+			if c.State == "running" {
+				targets = append(targets, c)
+			}
+		}
+	}
+
+	// The stream key tells a merged stream of one container from the
+	// plain one, since their lines differ.
+	ids := make([]string, len(targets))
+	for i, c := range targets {
+		ids[i] = c.ID
+	}
+	key := strings.Join(ids, ",")
+	if merged {
+		key = "merged:" + key
+	}
+
+	follow := len(targets) > 0
+	if follow && key == m.logsID {
+		return m, nil
+	}
+	if m.logsCancel != nil {
+		m.logsCancel()
+	}
+	m.logsID, m.logsCh, m.logsCancel = "", nil, nil
+	if !follow {
+		return m, nil
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	m.logsID, m.logsCancel = key, cancel
+	return m, func() tea.Msg {
+		dockerClient, err := m.dockerClient()
+		if err != nil {
+			return logStreamMsg{containerID: key, err: err}
+		}
+		var ch <-chan string
+		if merged {
+			ch, err = infra.FollowMergedLogs(ctx, dockerClient, targets)
+		} else {
+			ch, err = dockerClient.FollowContainerLogs(ctx, targets[0].ID)
+		}
+		return logStreamMsg{containerID: key, ch: ch, err: err}
+	}
+}
+
+// waitLogs delivers the next lines of a log stream, batching whatever has
+// already arrived so a chatty container does not flood the update loop.
+func waitLogs(ch <-chan string) tea.Cmd {
+	return func() tea.Msg {
+		line, ok := <-ch
+		if !ok {
+			return logLinesMsg{ch: ch}
+		}
+		lines := []string{line}
+		for len(lines) < 100 {
+			select {
+			case line, ok := <-ch:
+				if !ok {
+					return logLinesMsg{ch: ch, lines: lines}
+				}
+				lines = append(lines, line)
+			default:
+				return logLinesMsg{ch: ch, lines: lines, ok: true}
+			}
+		}
+		return logLinesMsg{ch: ch, lines: lines, ok: true}
+	}
+}
+
+// pullImage starts pulling ref in the background; waitPull delivers its
+// progress until a final message with done set.
+func (m Model) pullImage(ref string) tea.Cmd {
+	return func() tea.Msg {
+		dockerClient, err := m.dockerClient()
+		if err != nil {
+			return pullProgressMsg{done: true, err: err}
+		}
+
+		ch := make(chan pullProgressMsg, 16)
+		go func() {
+			err := dockerClient.PullImage(context.Background(), ref, func(p infra.PullProgress) {
+				ch <- pullProgressMsg{ch: ch, progress: p}
+			})
+			ch <- pullProgressMsg{ch: ch, done: true, err: err}
+			close(ch)
+		}()
+		return waitPull(ch)()
+	}
+}
+
+func waitPull(ch <-chan pullProgressMsg) tea.Cmd {
+	return func() tea.Msg {
+		return <-ch
+	}
+}
+
+func (m Model) runContainer(spec infra.RunSpec) tea.Cmd {
+	return func() tea.Msg {
+		dockerClient, err := m.dockerClient()
+		if err != nil {
+			return containerRunMsg{err: err}
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+		defer cancel()
+		id, err := dockerClient.RunContainer(ctx, spec)
+		return containerRunMsg{containerID: id, err: err}
+	}
+}
+
+func (m Model) fetchImages() tea.Msg {
+	dockerClient, err := m.dockerClient()
+	if err != nil {
+		return imagesMsg{err: err}
+	}
+	images, err := dockerClient.ListImages(context.Background())
+	return imagesMsg{images: images, err: err}
+}
+
+// containerAction starts, stops or restarts a container.
+func (m Model) containerAction(msg monitor.ContainerActionMsg) tea.Cmd {
+	return func() tea.Msg {
+		dockerClient, err := m.dockerClient()
+		if err != nil {
+			return containerActionDoneMsg{action: msg.Action, containerID: msg.ContainerID, err: err}
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+		defer cancel()
+		switch msg.Action {
+		case "start":
+			err = dockerClient.StartContainer(ctx, msg.ContainerID)
+		case "stop":
+			err = dockerClient.StopContainer(ctx, msg.ContainerID)
+		case "restart":
+			err = dockerClient.RestartContainer(ctx, msg.ContainerID)
+		default:
+			err = fmt.Errorf("unknown container action: %s", msg.Action)
+		}
+		if err != nil {
+			err = fmt.Errorf("%s failed: %w", msg.Action, err)
+		}
+		return containerActionDoneMsg{action: msg.Action, containerID: msg.ContainerID, err: err}
+	}
+}
+
+// bulkAction applies an action to each container in turn, going on past
+// failures so one stuck container doesn't hold up the rest.
+func (m Model) bulkAction(msg monitor.BulkActionMsg) tea.Cmd {
+	verbs := map[string]string{"restart": "Restarting", "stop": "Stopping", "remove": "Removing"}
+	return func() tea.Msg {
+		done := bulkActionDoneMsg{verb: verbs[msg.Action], total: len(msg.Containers)}
+		dockerClient, err := m.dockerClient()
+		if err != nil {
+			done.errs = []error{err}
+			return done
+		}
+		for _, c := range msg.Containers {
+			ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+			switch msg.Action {
+			case "restart":
+				err = dockerClient.RestartContainer(ctx, c.ID)
+			case "stop":
+				err = dockerClient.StopContainer(ctx, c.ID)
+			case "remove":
+				err = dockerClient.RemoveContainer(ctx, c.ID, c.State == "running")
+			default:
+				err = fmt.Errorf("unknown container action: %s", msg.Action)
+			}
+			cancel()
+			if err != nil {
+				done.errs = append(done.errs, fmt.Errorf("%s %s failed: %w", msg.Action, c.Name, err))
+			}
+		}
+		return done
+	}
+}
+
+func (m Model) inspectContainer(containerID string) tea.Cmd {
+	return func() tea.Msg {
+		dockerClient, err := m.dockerClient()
+		if err != nil {
+			return containerInspectedMsg{containerID: containerID, err: err}
+		}
+		detail, err := dockerClient.InspectContainer(context.Background(), containerID)
+		return containerInspectedMsg{containerID: containerID, detail: detail, err: err}
+	}
+}
+
+func (m Model) updateResources(msg monitor.UpdateResourcesMsg) tea.Cmd {
+	return func() tea.Msg {
+		dockerClient, err := m.dockerClient()
+		if err != nil {
+			return resourcesUpdatedMsg{err: err}
+		}
+		return resourcesUpdatedMsg{err: dockerClient.UpdateResources(context.Background(), msg.ContainerID, msg.Limits)}
+	}
+}
+
+// processesRefreshTicks is how many spinner ticks (a tenth of a second
+// each) the open process list waits between refreshes.
+const processesRefreshTicks = 30
+
+func (m Model) topContainer(containerID string) tea.Cmd {
+	return func() tea.Msg {
+		dockerClient, err := m.dockerClient()
+		if err != nil {
+			return processesMsg{containerID: containerID, err: err}
+		}
+		procs, err := dockerClient.TopContainer(context.Background(), containerID)
+		return processesMsg{containerID: containerID, procs: procs, err: err}
+	}
+}
+
+func (m Model) signalProcess(msg monitor.SignalProcessMsg) tea.Cmd {
+	return func() tea.Msg {
+		dockerClient, err := m.dockerClient()
+		if err == nil {
+			err = dockerClient.SignalProcess(context.Background(), msg.ContainerID, msg.PID, msg.Signal)
+		}
+		return processSignaledMsg{containerID: msg.ContainerID, pid: msg.PID, signal: msg.Signal, err: err}
+	}
+}
+
+func (m Model) removeImage(image string, force bool) tea.Cmd {
+	return func() tea.Msg {
+		dockerClient, err := m.dockerClient()
+		if err != nil {
+			return imageRemovedMsg{image: image, err: err}
+		}
+		return imageRemovedMsg{image: image, err: dockerClient.RemoveImage(context.Background(), image, force)}
+	}
+}
+
+func (m Model) imageHistory(image string) tea.Cmd {
+	return func() tea.Msg {
+		dockerClient, err := m.dockerClient()
+		if err != nil {
+			return imageHistoryMsg{image: image, err: err}
+		}
+		layers, err := dockerClient.ImageHistory(context.Background(), image)
+		return imageHistoryMsg{image: image, layers: layers, err: err}
+	}
+}
+
+func (m Model) fetchVolumes() tea.Msg {
+	dockerClient, err := m.dockerClient()
+	if err != nil {
+		return volumesMsg{err: err}
+	}
+	volumes, err := dockerClient.ListVolumes(context.Background())
+	return volumesMsg{volumes: volumes, err: err}
+}
+
+func (m Model) fetchDiskUsage() tea.Msg {
+	dockerClient, err := m.dockerClient()
+	if err != nil {
+		return diskUsageMsg{err: err}
+	}
+	usage, err := dockerClient.DiskUsage(context.Background())
+	return diskUsageMsg{usage: usage, err: err}
+}
+
+func (m Model) prune(kind infra.DiskKind) tea.Cmd {
+	return func() tea.Msg {
+		dockerClient, err := m.dockerClient()
+		if err != nil {
+			return pruneDoneMsg{err: err}
+		}
+		reclaimed, err := dockerClient.Prune(context.Background(), kind)
+		return pruneDoneMsg{reclaimed: reclaimed, err: err}
+	}
+}
+
+// backupVolume tars a volume into the backups directory under
+// ~/.devlogs.
+func (m Model) backupVolume(volume string) tea.Cmd {
+	return func() tea.Msg {
+		dockerClient, err := m.dockerClient()
+		if err != nil {
+			return volumeOpMsg{err: err}
+		}
+		path, err := dockerClient.BackupVolume(context.Background(), volume, infra.DefaultConfig().BackupDir())
+		return volumeOpMsg{status: "Saved " + filepath.Base(path), err: err}
+	}
+}
+
+// removeVolume deletes a volume no container uses.
+func (m Model) removeVolume(volume string) tea.Cmd {
+	return func() tea.Msg {
+		dockerClient, err := m.dockerClient()
+		if err != nil {
+			return volumeOpMsg{err: err}
+		}
+		err = dockerClient.RemoveVolume(context.Background(), volume, false)
+		return volumeOpMsg{status: "Removed " + volume, err: err}
+	}
+}
+
+func listBackups(volume string) tea.Cmd {
+	return func() tea.Msg {
+		backups, err := infra.ListVolumeBackups(infra.DefaultConfig().BackupDir(), volume)
+		return backupsMsg{volume: volume, backups: backups, err: err}
+	}
+}
+
+func (m Model) restoreVolume(volume, backup string) tea.Cmd {
+	return func() tea.Msg {
+		dockerClient, err := m.dockerClient()
+		if err != nil {
+			return volumeOpMsg{err: err}
+		}
+		err = dockerClient.RestoreVolume(context.Background(), volume, backup)
+		return volumeOpMsg{status: "Restored " + volume + " from " + filepath.Base(backup), err: err}
+	}
+}
+
+// composeAction brings a compose project up or down; the health check that
+// follows refreshes the Projects panel.
+func (m Model) composeAction(msg monitor.ProjectActionMsg) tea.Cmd {
+	return func() tea.Msg {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
+		defer cancel()
+
+		var err error
+		switch msg.Action {
+		case "up":
+			err = m.compose.Up(ctx, msg.Project)
+		case "down":
+			err = m.compose.Down(ctx, msg.Project)
+		default:
+			err = fmt.Errorf("unknown project action: %s", msg.Action)
+		}
+		return projectActionDoneMsg{project: msg.Project, err: err}
+	}
+}
+
+func (m Model) checkDockerHealth() tea.Msg {
+	dockerClient, err := m.dockerClient()
 	if err != nil {
 		return dockerHealthMsg{
 			health: infra.DockerHealth{
@@ -377,11 +1780,38 @@ func checkDockerHealth() tea.Msg {
 	return dockerHealthMsg{health: health}
 }
 
+// kubeHealthCmd lists the cluster's pods and deployments; nil without a
+// kube context.
+func (m Model) kubeHealthCmd() tea.Cmd {
+	if m.kube == nil {
+		return nil
+	}
+	return func() tea.Msg {
+		return kubeHealthMsg{health: m.kube.CheckHealth(context.Background())}
+	}
+}
+
+func (m Model) fetchPodLogs(pod kube.PodInfo) tea.Cmd {
+	return func() tea.Msg {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+		lines, err := m.kube.PodLogs(ctx, pod.Namespace, pod.Name, pod.Container, 200)
+		return podLogsMsg{pod: pod.Name, lines: lines, err: err}
+	}
+}
+
 func checkGPUStats() tea.Msg {
 	stats := infra.GetGPUStats()
 	return gpuStatsMsg{stats: stats}
 }
 
+func checkHostStats() tea.Msg {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	stats, err := infra.CollectHostStats(ctx)
+	return hostStatsMsg{stats: stats, err: err}
+}
+
 func checkServices() tea.Msg {
 	services := infra.CheckServices()
 	return serviceHealthMsg{services: services}
@@ -393,7 +1823,7 @@ func checkDBAndHistory() tea.Msg {
 		return historyLoadedMsg{err: err}
 	}
 
-	history, err := storage.GetRecentHistory(db, 50)
+	history, err := storage.QueryHistory(db, storage.HistoryFilter{Limit: historyLimit})
 	if err != nil {
 		return historyLoadedMsg{db: db, err: err}
 	}
@@ -401,6 +1831,72 @@ func checkDBAndHistory() tea.Msg {
 	return historyLoadedMsg{db: db, history: history}
 }
 
+// historyLimit bounds the commands the History tab lists, for any filter.
+const historyLimit = 500
+
+// queryHistory reads the history matching filter; the demo filters its
+// synthetic history instead.
+func (m Model) queryHistory(filter storage.HistoryFilter) tea.Cmd {
+	if m.demo {
+		items := demoHistoryMatching(filter)
+		return func() tea.Msg { return historyFilteredMsg{filter: filter, history: items} }
+	}
+	db := m.db
+	if db == nil {
+		return nil
+	}
+	return func() tea.Msg {
+		query := filter
+		query.Limit = historyLimit
+		items, err := storage.QueryHistory(db, query)
+		return historyFilteredMsg{filter: filter, history: items, err: err}
+	}
+}
+
+// queryStats aggregates the history matching filter for the Stats view.
+func (m Model) queryStats(filter storage.HistoryFilter) tea.Cmd {
+	if m.demo {
+		stats := storage.SummarizeHistory(demoHistoryMatching(filter))
+		return func() tea.Msg { return historyStatsMsg{filter: filter, stats: stats} }
+	}
+	db := m.db
+	if db == nil {
+		return nil
+	}
+	return func() tea.Msg {
+		stats, err := storage.GetHistoryStats(db, filter)
+		return historyStatsMsg{filter: filter, stats: stats, err: err}
+	}
+}
+
+// completionHistoryLimit bounds the history commands the agent input
+// completes from.
+const completionHistoryLimit = 500
+
+// loadCompletions reads what the agent input completes from: the most run
+// commands and the executables on PATH.
+func loadCompletions(db *sql.DB) tea.Cmd {
+	return func() tea.Msg {
+		history, _ := storage.GetFrequentCommands(db, completionHistoryLimit)
+		return completionsLoadedMsg{history: history, executables: agent.PathExecutables(os.Getenv("PATH"))}
+	}
+}
+
+// searchHistoryLimit bounds the stored commands Ctrl+R searches.
+const searchHistoryLimit = 5000
+
+// loadSearchHistory reads the stored commands for the Ctrl+R search; with
+// no database the search keeps to this session.
+func loadSearchHistory(db *sql.DB) tea.Cmd {
+	return func() tea.Msg {
+		if db == nil {
+			return searchHistoryLoadedMsg{}
+		}
+		commands, _ := storage.GetRecentCommands(db, searchHistoryLimit)
+		return searchHistoryLoadedMsg{commands: commands}
+	}
+}
+
 type starshipLineMsg struct {
 	line string
 }
@@ -1,10 +1,34 @@
 package tui
 
 import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
 	"strings"
 	"testing"
+	"time"
+	"unicode"
 
+	"dev-cli/internal/executor"
+	"dev-cli/internal/infra"
+	"dev-cli/internal/infra/kube"
+	"dev-cli/internal/llm"
+	"dev-cli/internal/pipeline"
+	"dev-cli/internal/storage"
+	"dev-cli/internal/tui/tabs/agent"
+	"dev-cli/internal/tui/tabs/history"
+	"dev-cli/internal/tui/tabs/monitor"
+	"dev-cli/internal/tui/tabs/runbooks"
+	"dev-cli/internal/tui/theme"
+	"dev-cli/internal/workflow"
+
+	"github.com/charmbracelet/bubbles/spinner"
 	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
 )
 
 func TestInitialModel(t *testing.T) {
@@ -47,6 +71,20 @@ func TestModel_TabSwitching(t *testing.T) {
 	newModel, _ = m.Update(tabMsg)
 	m = newModel.(Model)
 
+	if m.activeTab != TabRunbooks {
+		t.Errorf("expected TabRunbooks after third tab, got %v", m.activeTab)
+	}
+
+	newModel, _ = m.Update(tabMsg)
+	m = newModel.(Model)
+
+	if m.activeTab != TabChat {
+		t.Errorf("expected TabChat after fourth tab, got %v", m.activeTab)
+	}
+
+	newModel, _ = m.Update(tabMsg)
+	m = newModel.(Model)
+
 	if m.activeTab != TabAgent {
 		t.Errorf("expected TabAgent after wrap, got %v", m.activeTab)
 	}
@@ -192,8 +230,8 @@ func TestModel_ShiftTabReverse(t *testing.T) {
 	newModel, _ := model.Update(shiftTabMsg)
 	m := newModel.(Model)
 
-	if m.activeTab != TabHistory {
-		t.Errorf("expected TabHistory after Shift+Tab from first tab, got %v", m.activeTab)
+	if m.activeTab != TabChat {
+		t.Errorf("expected TabChat after Shift+Tab from first tab, got %v", m.activeTab)
 	}
 }
 
@@ -210,3 +248,3136 @@ func TestModel_EscapeKey(t *testing.T) {
 		t.Errorf("expected ModeNormal after Escape, got %v", m.mode)
 	}
 }
+
+func TestModel_WindowTitleFollowsTab(t *testing.T) {
+	model := InitialModel()
+	model.state = StateMain
+
+	newModel, _ := model.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("3")})
+	m := newModel.(Model)
+
+	if m.title != "dev-cli: History" {
+		t.Errorf("expected title to follow active tab, got %q", m.title)
+	}
+}
+
+func TestModel_FocusTracking(t *testing.T) {
+	model := InitialModel()
+
+	newModel, _ := model.Update(tea.BlurMsg{})
+	m := newModel.(Model)
+	if m.focused {
+		t.Error("expected model to be unfocused after BlurMsg")
+	}
+
+	newModel, _ = m.Update(tea.FocusMsg{})
+	m = newModel.(Model)
+	if !m.focused {
+		t.Error("expected model to be focused after FocusMsg")
+	}
+}
+
+func TestSanitizeOSC(t *testing.T) {
+	got := sanitizeOSC("make build;\x07done\n")
+	if strings.ContainsAny(got, "\x07\n;") {
+		t.Errorf("expected control chars and separators stripped, got %q", got)
+	}
+}
+
+func TestDemoModel(t *testing.T) {
+	model := DemoModel()
+
+	if !model.demo {
+		t.Fatal("expected demo flag to be set")
+	}
+	if len(model.pipe.State().GetBlocks()) == 0 {
+		t.Error("expected demo blocks to be seeded")
+	}
+
+	newModel, _ := model.Update(dockerHealthMsg{health: demoDockerHealth()})
+	m := newModel.(Model)
+	if m.state != StateMain {
+		t.Error("expected demo docker health to leave the loading state")
+	}
+
+	newModel, _ = m.Update(historyLoadedMsg{history: demoHistory()})
+	m = newModel.(Model)
+	if !strings.Contains(m.View(), "Agent") {
+		t.Error("expected demo view to render")
+	}
+}
+
+func TestModel_ReducedModeWithoutDocker(t *testing.T) {
+	docker := infra.NewFakeDocker()
+	docker.Err = errors.New("connection refused")
+	model := NewModel(docker, llm.NewFakeProvider())
+
+	newModel, _ := model.Update(tea.WindowSizeMsg{Width: 120, Height: 40})
+	newModel, _ = newModel.Update(model.checkDockerHealth())
+	m := newModel.(Model)
+	if m.state != StateMain {
+		t.Fatal("expected missing docker to leave the loading state")
+	}
+	if !m.pipe.State().Availability(pipeline.SubsystemDocker).Missing() {
+		t.Error("expected docker to be recorded as missing")
+	}
+
+	m.activeTab = TabContainers
+	if !strings.Contains(m.View(), "Docker is not") {
+		t.Error("expected containers tab to show the docker setup prompt")
+	}
+}
+
+func TestModel_LoadingTimeout(t *testing.T) {
+	model := InitialModel()
+
+	newModel, _ := model.Update(loadingTimeoutMsg{})
+	if newModel.(Model).state != StateMain {
+		t.Error("expected loading timeout to show the app")
+	}
+}
+
+func TestModel_ComposeProjects(t *testing.T) {
+	docker := infra.NewFakeDocker(
+		infra.ContainerInfo{ID: "a1", Name: "shop-web-1", State: "running",
+			Labels: map[string]string{infra.ComposeProjectLabel: "shop", infra.ComposeServiceLabel: "web"}},
+		infra.ContainerInfo{ID: "a2", Name: "shop-db-1", State: "exited",
+			Labels: map[string]string{infra.ComposeProjectLabel: "shop", infra.ComposeServiceLabel: "db"}},
+	)
+	model := NewModel(docker, llm.NewFakeProvider())
+
+	newModel, _ := model.Update(tea.WindowSizeMsg{Width: 120, Height: 40})
+	newModel, _ = newModel.Update(model.checkDockerHealth())
+	m := newModel.(Model)
+	m.activeTab = TabContainers
+
+	if projects := m.containers.Projects(); len(projects) != 1 || projects[0].Status() != "partial" {
+		t.Fatalf("expected one partial shop project, got %+v", projects)
+	}
+	if !strings.Contains(m.View(), "Projects [1]") {
+		t.Error("expected containers tab to show the Projects panel")
+	}
+
+	m.containers = m.containers.SetFocus(monitor.FocusProjects)
+	_, cmd := m.containers.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("x")}, monitor.DefaultKeyMap())
+	if cmd == nil {
+		t.Fatal("expected stop on a focused project to request compose down")
+	}
+	if action, ok := cmd().(monitor.ProjectActionMsg); !ok || action.Action != "down" || action.Project != "shop" {
+		t.Errorf("expected down for shop, got %#v", action)
+	}
+
+	newModel, _ = m.Update(projectActionDoneMsg{project: "shop", err: errors.New("compose down shop: boom")})
+	m = newModel.(Model)
+	if !strings.Contains(m.View(), "boom") {
+		t.Error("expected the compose error in the Projects panel")
+	}
+}
+
+func TestModel_ExecIntoContainer(t *testing.T) {
+	docker := infra.NewFakeDocker(infra.ContainerInfo{ID: "a1", Name: "web", State: "running"})
+	model := NewModel(docker, llm.NewFakeProvider())
+
+	newModel, _ := model.Update(tea.WindowSizeMsg{Width: 120, Height: 40})
+	newModel, _ = newModel.Update(model.checkDockerHealth())
+	m := newModel.(Model)
+	m.activeTab = TabContainers
+
+	_, cmd := m.containers.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("e")}, monitor.DefaultKeyMap())
+	if cmd == nil {
+		t.Fatal("expected exec on a running service to request a shell")
+	}
+	msg, ok := cmd().(monitor.ExecContainerMsg)
+	if !ok || msg.ContainerID != "a1" {
+		t.Fatalf("expected exec for a1, got %#v", msg)
+	}
+
+	if _, cmd := m.Update(msg); cmd == nil {
+		t.Error("expected the app to suspend for the exec session")
+	}
+
+	newModel, _ = m.Update(containerExecDoneMsg{containerID: "a1", err: errors.New("exec attach failed: boom")})
+	m = newModel.(Model)
+	if !strings.Contains(m.View(), "boom") {
+		t.Error("expected the exec error in the Containers tab")
+	}
+}
+
+// runCmd runs cmd and flattens batches into the messages they produce.
+func runCmd(cmd tea.Cmd) []tea.Msg {
+	if cmd == nil {
+		return nil
+	}
+	msg := cmd()
+	if batch, ok := msg.(tea.BatchMsg); ok {
+		var msgs []tea.Msg
+		for _, c := range batch {
+			msgs = append(msgs, runCmd(c)...)
+		}
+		return msgs
+	}
+	return []tea.Msg{msg}
+}
+
+func TestModel_StreamsSelectedContainerStats(t *testing.T) {
+	infra.FakeStatsInterval = time.Millisecond
+	t.Cleanup(func() { infra.FakeStatsInterval = time.Second })
+
+	docker := infra.NewFakeDocker(infra.ContainerInfo{ID: "a1", Name: "web", State: "running"})
+	docker.Stats["a1"] = infra.ContainerStatsSnapshot{CPUPercent: 42, MemUsed: 64 << 20, MemLimit: 256 << 20}
+	model := NewModel(docker, llm.NewFakeProvider())
+
+	newModel, cmd := model.Update(model.checkDockerHealth())
+	m := newModel.(Model)
+	defer m.statsCancel()
+
+	var stream tea.Msg
+	for _, msg := range runCmd(cmd) {
+		if _, ok := msg.(statsStreamMsg); ok {
+			stream = msg
+		}
+	}
+	if stream == nil {
+		t.Fatal("expected the health check to open a stats stream for the selected container")
+	}
+
+	newModel, cmd = m.Update(stream)
+	m = newModel.(Model)
+	for range 3 {
+		msgs := runCmd(cmd)
+		if len(msgs) != 1 {
+			t.Fatalf("expected one stats frame, got %v", msgs)
+		}
+		newModel, cmd = m.Update(msgs[0])
+		m = newModel.(Model)
+	}
+
+	stats := m.containers.GetSelectedServiceStats()
+	if len(stats.CPUHistory) != 3 || stats.CPUHistory[2] != 42 || stats.MemUsed != 64 || stats.MemTotal != 256 {
+		t.Errorf("expected three streamed samples, got %+v", stats)
+	}
+
+	newModel, _ = m.Update(dockerHealthMsg{health: docker.CheckHealth(context.Background())})
+	if newModel.(Model).statsID != "a1" {
+		t.Error("expected the stream to be kept across health checks")
+	}
+}
+
+func TestModel_FollowModeStreamsLogs(t *testing.T) {
+	infra.FakeLogPollInterval = time.Millisecond
+	t.Cleanup(func() { infra.FakeLogPollInterval = 50 * time.Millisecond })
+
+	docker := infra.NewFakeDocker(infra.ContainerInfo{ID: "a1", Name: "web", State: "running"})
+	docker.Logs["a1"] = []string{"booting"}
+	model := NewModel(docker, llm.NewFakeProvider())
+
+	newModel, _ := model.Update(model.checkDockerHealth())
+	newModel, _ = newModel.Update(model.fetchLogs("a1")())
+	m := newModel.(Model)
+	defer m.statsCancel()
+	m.activeTab = TabContainers
+
+	newModel, cmd := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("f")})
+	m = newModel.(Model)
+	var stream tea.Msg
+	for _, msg := range runCmd(cmd) {
+		if _, ok := msg.(logStreamMsg); ok {
+			stream = msg
+		}
+	}
+	if stream == nil {
+		t.Fatal("expected follow mode to open a log stream")
+	}
+
+	newModel, cmd = m.Update(stream)
+	m = newModel.(Model)
+	docker.AppendLogs("a1", "GET /health 200")
+	msgs := runCmd(cmd)
+	if len(msgs) != 1 {
+		t.Fatalf("expected one batch of log lines, got %v", msgs)
+	}
+	newModel, _ = m.Update(msgs[0])
+	m = newModel.(Model)
+
+	lines := m.containers.LogLines()
+	if len(lines) != 2 || lines[1] != "GET /health 200" {
+		t.Errorf("expected the streamed line appended, got %q", lines)
+	}
+
+	newModel, _ = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("f")})
+	if m = newModel.(Model); m.logsID != "" {
+		t.Error("expected turning follow off to stop the stream")
+	}
+}
+
+func TestModel_PullImage(t *testing.T) {
+	docker := infra.NewFakeDocker()
+	docker.Images = []infra.ImageInfo{{ID: "sha256:1", Tags: []string{"redis:7"}}}
+	model := NewModel(docker, llm.NewFakeProvider())
+
+	newModel, _ := model.Update(tea.WindowSizeMsg{Width: 120, Height: 40})
+	newModel, _ = newModel.Update(model.fetchImages())
+	m := newModel.(Model)
+	m.state = StateMain
+	m.activeTab = TabContainers
+	m.containers = m.containers.SetFocus(monitor.FocusImages)
+
+	_, cmd := m.containers.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("P")}, monitor.DefaultKeyMap())
+	if cmd == nil {
+		t.Fatal("expected P on a tagged image to request a pull")
+	}
+	pull, ok := cmd().(monitor.PullImageMsg)
+	if !ok || pull.Ref != "redis:7" {
+		t.Fatalf("expected pull of redis:7, got %#v", pull)
+	}
+
+	docker.Images = append(docker.Images, infra.ImageInfo{ID: "sha256:2", Tags: []string{"postgres:16"}})
+	newModel, cmd = m.Update(pull)
+	m = newModel.(Model)
+	if !m.containers.Pulling() {
+		t.Fatal("expected the pull to be shown as in progress")
+	}
+
+	sawBar := false
+	for m.containers.Pulling() {
+		msgs := runCmd(cmd)
+		var next []tea.Cmd
+		for _, msg := range msgs {
+			newModel, c := m.Update(msg)
+			m = newModel.(Model)
+			next = append(next, c)
+			if strings.Contains(m.View(), "↓ redis:7") {
+				sawBar = true
+			}
+		}
+		cmd = tea.Batch(next...)
+	}
+	if !sawBar {
+		t.Error("expected pull progress in the Images panel")
+	}
+
+	for _, msg := range runCmd(cmd) {
+		newModel, _ = m.Update(msg)
+		m = newModel.(Model)
+	}
+	if len(m.containers.Images()) != 2 {
+		t.Errorf("expected images to be refreshed after the pull, got %+v", m.containers.Images())
+	}
+}
+
+func TestModel_ImageActions(t *testing.T) {
+	now := time.Now()
+	docker := infra.NewFakeDocker(infra.ContainerInfo{ID: "c1", Name: "web", Image: "web:dev", State: "running"})
+	docker.Images = []infra.ImageInfo{
+		{ID: "sha256:old", Tags: []string{"old:1"}, Created: now},
+		{ID: "sha256:web", Tags: []string{"web:dev"}, Created: now.Add(-time.Hour)},
+		{ID: "sha256:none", Tags: []string{"<none>:<none>"}, Created: now.Add(-2 * time.Hour)},
+	}
+	model := NewModel(docker, llm.NewFakeProvider())
+	newModel, _ := model.Update(tea.WindowSizeMsg{Width: 120, Height: 40})
+	newModel, _ = newModel.Update(model.checkDockerHealth())
+	newModel, _ = newModel.Update(model.fetchImages())
+	m := newModel.(Model)
+	m.activeTab = TabContainers
+	m.containers = m.containers.SetFocus(monitor.FocusImages)
+
+	// press sends keys to the tab and returns the message of the last one.
+	press := func(keys ...string) tea.Msg {
+		t.Helper()
+		var cmd tea.Cmd
+		for _, k := range keys {
+			msg := tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(k)}
+			if k == "enter" {
+				msg = tea.KeyMsg{Type: tea.KeyEnter}
+			}
+			m.containers, cmd = m.containers.Update(msg, monitor.DefaultKeyMap())
+		}
+		if cmd == nil {
+			return nil
+		}
+		return cmd()
+	}
+	remove := func(msg tea.Msg) {
+		t.Helper()
+		newModel, _ := m.Update(msg)
+		m = newModel.(Model)
+		for _, msg := range runCmd(m.removeImage(msg.(monitor.RemoveImageMsg).Image, msg.(monitor.RemoveImageMsg).Force)) {
+			newModel, _ = m.Update(msg)
+			m = newModel.(Model)
+		}
+		newModel, _ = m.Update(m.fetchImages())
+		m = newModel.(Model)
+	}
+
+	press("a")
+	view := m.View()
+	for _, want := range []string{"⬡ old:1", "Not used by any container", "Inspect layers", "Force remove", "Pull newer"} {
+		if !strings.Contains(view, want) {
+			t.Errorf("expected %q in the image menu, got:\n%s", want, view)
+		}
+	}
+	if m.getModeFromTab() != ModeInsert {
+		t.Error("expected the menu to take the keyboard")
+	}
+
+	press("d")
+	if !strings.Contains(m.View(), "⚠ Remove old:1") {
+		t.Fatalf("expected d to ask before removing, got:\n%s", m.View())
+	}
+	if msg := press("n"); msg != nil || !strings.Contains(m.View(), "Inspect layers") {
+		t.Fatal("expected n to go back to the menu")
+	}
+	msg := press("d", "y")
+	if rm, ok := msg.(monitor.RemoveImageMsg); !ok || rm.Image != "old:1" || rm.Force {
+		t.Fatalf("expected old:1 removed without force, got %#v", msg)
+	}
+	remove(msg)
+	if len(m.containers.Images()) != 2 || m.containers.ModalOpen() {
+		t.Fatalf("expected old:1 gone and the menu closed, got %+v", m.containers.Images())
+	}
+	if toasts := m.notifications.Toasts(); len(toasts) != 1 || toasts[0].Text != "Removed image old:1" {
+		t.Errorf("expected a toast for the removal, got %+v", toasts)
+	}
+
+	// web:dev is in use: removing it fails, force removing it works.
+	press("g")
+	press("a")
+	if !strings.Contains(m.View(), "Used by web") {
+		t.Errorf("expected the menu to name the containers using the image, got:\n%s", m.View())
+	}
+	remove(press("d", "y"))
+	if len(m.containers.Images()) != 2 || !strings.Contains(m.View(), "unable to remov") {
+		t.Fatalf("expected the refusal shown in the Images panel, got:\n%s", m.View())
+	}
+	press("a", "D")
+	if !strings.Contains(m.View(), "web keep running") {
+		t.Errorf("expected the force removal to warn about web, got:\n%s", m.View())
+	}
+	msg = press("y")
+	if rm, ok := msg.(monitor.RemoveImageMsg); !ok || !rm.Force {
+		t.Fatalf("expected a forced removal, got %#v", msg)
+	}
+	remove(msg)
+	if len(m.containers.Images()) != 1 {
+		t.Fatalf("expected web:dev force removed, got %+v", m.containers.Images())
+	}
+
+	// The untagged image has nothing to pull, but can still be run.
+	press("a")
+	if strings.Contains(m.View(), "Pull newer") {
+		t.Error("expected no pull for an untagged image")
+	}
+	press("esc")
+	if msg := press("a", "j", "j", "j", "enter"); !m.containers.WizardOpen() {
+		t.Errorf("expected Run to open the run wizard, got %#v", msg)
+	}
+}
+
+func TestModel_RunWizard(t *testing.T) {
+	docker := infra.NewFakeDocker()
+	docker.Images = []infra.ImageInfo{{ID: "sha256:1", Tags: []string{"nginx:alpine"}}}
+	model := NewModel(docker, llm.NewFakeProvider())
+
+	newModel, _ := model.Update(tea.WindowSizeMsg{Width: 120, Height: 40})
+	newModel, _ = newModel.Update(model.fetchImages())
+	m := newModel.(Model)
+	m.state = StateMain
+	m.activeTab = TabContainers
+	m.containers = m.containers.SetFocus(monitor.FocusImages)
+
+	press := func(keys ...string) tea.Cmd {
+		var cmd tea.Cmd
+		for _, k := range keys {
+			msg := tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(k)}
+			switch k {
+			case "enter":
+				msg = tea.KeyMsg{Type: tea.KeyEnter}
+			case "backspace":
+				msg = tea.KeyMsg{Type: tea.KeyBackspace}
+			}
+			newModel, cmd = m.Update(msg)
+			m = newModel.(Model)
+		}
+		return cmd
+	}
+
+	press("n")
+	if !m.containers.WizardOpen() || m.mode != ModeInsert {
+		t.Fatal("expected n to open the run wizard in insert mode")
+	}
+	if !strings.Contains(m.View(), "nginx:alpine") {
+		t.Error("expected the wizard to be prefilled with the selected image")
+	}
+
+	// Typing "q" must go to the form, not quit the app.
+	press("w", "e", "b", "q", "backspace", "enter", "8", "0", "8", "0", ":", "8", "0", "enter", "enter")
+	var run tea.Msg
+	for _, msg := range runCmd(press("enter")) {
+		if _, ok := msg.(monitor.RunContainerMsg); ok {
+			run = msg
+		}
+	}
+	if m.quitting || run == nil {
+		t.Fatalf("expected the wizard to submit a run, quitting=%v", m.quitting)
+	}
+	if spec := run.(monitor.RunContainerMsg).Spec; spec.Name != "web" || spec.Image != "nginx:alpine" || len(spec.Ports) != 1 {
+		t.Errorf("unexpected spec: %+v", spec)
+	}
+
+	newModel, _ = m.Update(m.runContainer(run.(monitor.RunContainerMsg).Spec)())
+	m = newModel.(Model)
+	if m.containers.WizardOpen() || m.mode != ModeNormal {
+		t.Error("expected the wizard to close after a successful run")
+	}
+	if c := docker.CheckHealth(context.Background()).Containers; len(c) != 1 || c[0].Name != "web" {
+		t.Errorf("expected the container to be created, got %+v", c)
+	}
+}
+
+func TestModel_ShowsConnectedDaemon(t *testing.T) {
+	docker := infra.NewFakeDocker(infra.ContainerInfo{ID: "a1", Name: "web", State: "running"})
+	docker.Daemon = "podman"
+	model := NewModel(docker, llm.NewFakeProvider())
+
+	newModel, _ := model.Update(tea.WindowSizeMsg{Width: 120, Height: 40})
+	newModel, _ = newModel.Update(model.checkDockerHealth())
+	m := newModel.(Model)
+	m.activeTab = TabContainers
+
+	if !strings.Contains(m.View(), "Services [1] podman") {
+		t.Error("expected the Services header to name the connected daemon")
+	}
+}
+
+type fakeKube struct{}
+
+func (fakeKube) CheckHealth(context.Context) kube.Health {
+	return kube.Health{
+		Available: true, Context: "kind-dev", Namespace: "shop",
+		Pods:        []kube.PodInfo{{Name: "web-1", Namespace: "shop", Status: "Running", Ready: 1, Total: 1, Container: "web"}},
+		Deployments: []kube.DeploymentInfo{{Name: "web", Namespace: "shop", Replicas: 1, Ready: 1}},
+	}
+}
+
+func (fakeKube) PodLogs(_ context.Context, namespace, pod, container string, _ int) ([]string, error) {
+	return []string{"2026-01-01T00:00:00Z INFO " + namespace + "/" + pod + "/" + container + " listening"}, nil
+}
+
+func TestModel_KubernetesTab(t *testing.T) {
+	model := NewModel(infra.NewFakeDocker(), llm.NewFakeProvider())
+	newModel, _ := model.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("4")})
+	if newModel.(Model).activeTab == TabKubernetes {
+		t.Fatal("expected no Kubernetes tab without a kube context")
+	}
+
+	model = model.WithKube(fakeKube{})
+	model.state = StateMain
+	newModel, _ = model.Update(tea.WindowSizeMsg{Width: 120, Height: 40})
+	newModel, cmd := newModel.Update(runCmd(model.kubeHealthCmd())[0])
+	for _, msg := range runCmd(cmd) {
+		if logs, ok := msg.(podLogsMsg); ok {
+			newModel, _ = newModel.Update(logs)
+		}
+	}
+	newModel, _ = newModel.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("4")})
+	m := newModel.(Model)
+
+	if m.activeTab != TabKubernetes {
+		t.Fatalf("expected 4 to open the Kubernetes tab, got %v", m.activeTab)
+	}
+	view := m.View()
+	for _, want := range []string{"Kubernetes", "Pods [1] kind-dev/shop", "Deployments [1]", "shop/web-1/web listening"} {
+		if !strings.Contains(view, want) {
+			t.Errorf("expected view to contain %q", want)
+		}
+	}
+
+	newModel, _ = m.Update(tea.KeyMsg{Type: tea.KeyTab})
+	if newModel.(Model).activeTab != TabRunbooks {
+		t.Error("expected tab to go from Kubernetes to Runbooks")
+	}
+	newModel, _ = newModel.Update(tea.KeyMsg{Type: tea.KeyTab})
+	if newModel.(Model).activeTab != TabChat {
+		t.Error("expected tab to go from Runbooks to Chat")
+	}
+	newModel, _ = newModel.Update(tea.KeyMsg{Type: tea.KeyTab})
+	if newModel.(Model).activeTab != TabAgent {
+		t.Error("expected tab to wrap from Chat to Agent")
+	}
+}
+
+func TestModel_MergedLogs(t *testing.T) {
+	docker := infra.NewFakeDocker(
+		infra.ContainerInfo{ID: "a1", Name: "web", State: "running"},
+		infra.ContainerInfo{ID: "b2", Name: "db", State: "running"},
+	)
+	docker.Logs["a1"] = []string{"2026-01-01T10:00:01Z GET /", "2026-01-01T10:00:03Z GET /health"}
+	docker.Logs["b2"] = []string{"2026-01-01T10:00:02Z checkpoint complete"}
+	model := NewModel(docker, llm.NewFakeProvider())
+
+	newModel, _ := model.Update(tea.WindowSizeMsg{Width: 120, Height: 40})
+	newModel, _ = newModel.Update(model.checkDockerHealth())
+	m := newModel.(Model)
+	m.state = StateMain
+	m.activeTab = TabContainers
+
+	// press feeds a key and the log messages it leads to back into the app.
+	press := func(m Model, k tea.KeyMsg) Model {
+		newModel, cmd := m.Update(k)
+		for _, msg := range runCmd(cmd) {
+			switch msg.(type) {
+			case monitor.MergeLogsMsg, containerLogsMsg:
+				var next tea.Cmd
+				newModel, next = newModel.Update(msg)
+				for _, msg := range runCmd(next) {
+					if logs, ok := msg.(containerLogsMsg); ok {
+						newModel, _ = newModel.Update(logs)
+					}
+				}
+			}
+		}
+		return newModel.(Model)
+	}
+	space := tea.KeyMsg{Type: tea.KeySpace, Runes: []rune(" ")}
+
+	m = press(m, space)
+	m = press(m, tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("j")})
+	m = press(m, space)
+
+	if targets := m.containers.LogTargets(); len(targets) != 2 || targets[0].Name != "web" || targets[1].Name != "db" {
+		t.Fatalf("expected web and db merged in pick order, got %+v", targets)
+	}
+	want := []string{
+		"web | 2026-01-01T10:00:01Z GET /",
+		"db | 2026-01-01T10:00:02Z checkpoint complete",
+		"web | 2026-01-01T10:00:03Z GET /health",
+	}
+	if lines := m.containers.LogLines(); strings.Join(lines, "\n") != strings.Join(want, "\n") {
+		t.Errorf("expected interleaved logs, got %q", lines)
+	}
+	view := m.View()
+	stamp := time.Date(2026, 1, 1, 10, 0, 2, 0, time.UTC).In(monitor.LogTimeZone).Format("15:04:05")
+	if !strings.Contains(view, "Logs (2 containers)") || !strings.Contains(view, "db  │ "+stamp+" checkpoint complete") {
+		t.Error("expected the logs panel to show tagged, merged lines")
+	}
+
+	m = press(m, tea.KeyMsg{Type: tea.KeyEsc})
+	if m.containers.MergedLogs() {
+		t.Fatal("expected esc to unmerge")
+	}
+	if lines := m.containers.LogLines(); len(lines) != 1 || lines[0] != docker.Logs["b2"][0] {
+		t.Errorf("expected the selected service's own logs back, got %q", lines)
+	}
+}
+
+func TestModel_LogSearch(t *testing.T) {
+	model := NewModel(infra.NewFakeDocker(), llm.NewFakeProvider())
+	newModel, _ := model.Update(tea.WindowSizeMsg{Width: 120, Height: 40})
+	m := newModel.(Model)
+	m.state = StateMain
+	m.activeTab = TabContainers
+
+	var lines []string
+	for i := range 80 {
+		line := fmt.Sprintf("line-%02d INFO ok", i)
+		switch i {
+		case 3:
+			line = "line-03 ERROR upstream timeout"
+		case 70:
+			line = "line-70 WARN db Timed Out"
+		case 75:
+			line = "line-75 INFO timeout, retrying"
+		}
+		lines = append(lines, line)
+	}
+	m.containers = m.containers.SetLogLines(lines)
+
+	send := func(msg tea.KeyMsg) {
+		newModel, _ := m.Update(msg)
+		m = newModel.(Model)
+	}
+	key := func(s string) tea.KeyMsg { return tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(s)} }
+
+	send(key("/"))
+	if !m.containers.SearchOpen() || m.mode != ModeInsert {
+		t.Fatal("expected / to open the log search")
+	}
+	send(key("time"))
+	if view := m.View(); !strings.Contains(view, "3 matches") {
+		t.Errorf("expected the matches to be counted as the query is typed, got:\n%s", view)
+	}
+	send(key("(d out"))
+	if view := m.View(); !strings.Contains(view, "no match (not a regex, matching text)") {
+		t.Errorf("expected an unfinished regex to match as text, got:\n%s", view)
+	}
+	send(key("|out)$"))
+	if view := m.View(); !strings.Contains(view, "2 matches") {
+		t.Errorf("expected the regex to match the lines ending in a timeout, got:\n%s", view)
+	}
+
+	send(tea.KeyMsg{Type: tea.KeyEnter})
+	if m.containers.SearchOpen() || m.mode != ModeNormal {
+		t.Fatal("expected Enter to keep the search")
+	}
+	if view := m.View(); strings.Contains(view, "line-03") || !strings.Contains(view, "n/N next/prev") {
+		t.Errorf("expected the logs to stay at their tail until n/N, got:\n%s", view)
+	}
+
+	send(key("n"))
+	if view := m.View(); !strings.Contains(view, "1/2") || !strings.Contains(view, "line-03 ERROR upstream") || strings.Contains(view, "line-75") {
+		t.Errorf("expected n to move to the first match, got:\n%s", view)
+	}
+	if m.containers.FollowMode() {
+		t.Error("expected moving to a match to stop following")
+	}
+	send(key("n"))
+	if view := m.View(); !strings.Contains(view, "2/2") || !strings.Contains(view, "line-70") {
+		t.Errorf("expected n to move down to the second match, got:\n%s", view)
+	}
+
+	// The level filter narrows what is searched.
+	send(key("l"))
+	if view := m.View(); !strings.Contains(view, "1 match") {
+		t.Errorf("expected the ERROR filter to leave one match, got:\n%s", view)
+	}
+	send(key("l"))
+	send(key("l"))
+	send(key("l"))
+
+	send(tea.KeyMsg{Type: tea.KeyEsc})
+	if m.containers.LogSearch() != "" || strings.Contains(m.View(), "n/N next/prev") {
+		t.Error("expected Esc to clear the search")
+	}
+}
+
+func TestModel_LogTimestampsAndWrap(t *testing.T) {
+	model := NewModel(infra.NewFakeDocker(), llm.NewFakeProvider())
+	newModel, _ := model.Update(tea.WindowSizeMsg{Width: 120, Height: 40})
+	m := newModel.(Model)
+	m.state = StateMain
+	m.activeTab = TabContainers
+
+	long := "request failed " + strings.Repeat("x", 150) + " END"
+	m.containers = m.containers.SetLogLines([]string{
+		"2026-01-01T10:00:01.123456789Z server started",
+		"2026-01-01T10:00:02Z " + long,
+	})
+	stamp := time.Date(2026, 1, 1, 10, 0, 1, 0, time.UTC).In(monitor.LogTimeZone).Format("15:04:05")
+
+	send := func(msg tea.Msg) {
+		newModel, _ := m.Update(msg)
+		m = newModel.(Model)
+	}
+
+	view := m.View()
+	if !strings.Contains(view, stamp+" server started") || strings.Contains(view, "2026-01-01T") {
+		t.Errorf("expected the daemon's timestamps shortened to the time, got:\n%s", view)
+	}
+	if strings.Contains(view, "END") {
+		t.Errorf("expected long lines cut at the panel width, got:\n%s", view)
+	}
+
+	send(tea.KeyMsg{Type: tea.KeyCtrlT})
+	if view := m.View(); m.containers.ShowTimestamps() || strings.Contains(view, stamp) || !strings.Contains(view, "server started") {
+		t.Errorf("expected Ctrl+t to hide the timestamps, got:\n%s", view)
+	}
+	send(tea.KeyMsg{Type: tea.KeyCtrlT})
+	if !m.containers.ShowTimestamps() {
+		t.Error("expected Ctrl+t to show the timestamps again")
+	}
+
+	send(tea.KeyMsg{Type: tea.KeyCtrlW})
+	if !m.containers.WrapLogs() || !strings.Contains(m.View(), "END") {
+		t.Errorf("expected Ctrl+w to wrap long lines, got:\n%s", m.View())
+	}
+
+	// Wrapping outlives a resize, following the new width.
+	send(tea.WindowSizeMsg{Width: 100, Height: 40})
+	view = m.View()
+	if !m.containers.WrapLogs() || !strings.Contains(view, "END") {
+		t.Errorf("expected the lines to stay wrapped after a resize, got:\n%s", view)
+	}
+	for _, line := range strings.Split(view, "\n") {
+		if w := lipgloss.Width(line); w > 100 {
+			t.Errorf("expected wrapped rows to fit the new width, got %d cells: %q", w, line)
+		}
+	}
+}
+
+func TestModel_ZoomPanel(t *testing.T) {
+	model := NewModel(infra.NewFakeDocker(), llm.NewFakeProvider())
+	newModel, _ := model.Update(tea.WindowSizeMsg{Width: 120, Height: 40})
+	m := newModel.(Model)
+	m.state = StateMain
+	m.activeTab = TabContainers
+
+	long := "request failed " + strings.Repeat("x", 80) + " END"
+	m.containers = m.containers.SetLogLines([]string{long}).SetFocus(monitor.FocusLogs)
+
+	send := func(msg tea.Msg) {
+		newModel, _ := m.Update(msg)
+		m = newModel.(Model)
+	}
+
+	view := m.View()
+	if !strings.Contains(view, "Services") || strings.Contains(view, "END") {
+		t.Fatalf("expected the sidebar beside logs cut at the panel width, got:\n%s", view)
+	}
+
+	send(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("Z")})
+	view = m.View()
+	if !m.containers.Zoomed() || strings.Contains(view, "Services") || !strings.Contains(view, "END") {
+		t.Errorf("expected Z to widen the logs panel over the sidebar, got:\n%s", view)
+	}
+	for _, line := range strings.Split(view, "\n") {
+		if w := lipgloss.Width(line); w > 120 {
+			t.Errorf("expected the zoomed panel to fit the width, got %d cells: %q", w, line)
+		}
+	}
+
+	send(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("Z")})
+	view = m.View()
+	if m.containers.Zoomed() || !strings.Contains(view, "Services") || !strings.Contains(view, "Logs") {
+		t.Errorf("expected Z again to bring the sidebar back, got:\n%s", view)
+	}
+
+	// A sidebar panel zooms the same way.
+	m.containers = m.containers.SetFocus(monitor.FocusImages)
+	send(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("Z")})
+	if view := m.View(); !strings.Contains(view, "Images") || strings.Contains(view, "Logs") {
+		t.Errorf("expected Z to zoom the Images panel, got:\n%s", view)
+	}
+}
+
+func TestModel_LogAnalysis(t *testing.T) {
+	ai := llm.NewFakeProvider()
+	ai.Analysis = &llm.LogAnalysisResult{Explanation: "The database refused the connection.", Fix: "docker restart db"}
+	model := NewModel(infra.NewFakeDocker(), ai)
+	newModel, _ := model.Update(tea.WindowSizeMsg{Width: 120, Height: 40})
+	m := newModel.(Model)
+	m.state = StateMain
+	m.activeTab = TabContainers
+
+	var lines []string
+	for i := range 60 {
+		lines = append(lines, fmt.Sprintf("line-%02d INFO ok", i))
+	}
+	lines = append(lines, "line-60 ERROR connect failed password=hunter2hunter2")
+	m.containers = m.containers.SetLogLines(lines).SetFocus(monitor.FocusLogs)
+
+	send := func(msg tea.Msg) tea.Cmd {
+		newModel, cmd := m.Update(msg)
+		m = newModel.(Model)
+		return cmd
+	}
+	key := func(s string) tea.KeyMsg { return tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(s)} }
+
+	cmd := send(key("a"))
+	if !m.containers.AnalysisOpen() || !strings.Contains(m.View(), "Analyzing") {
+		t.Fatalf("expected a on the logs to start an analysis, got:\n%s", m.View())
+	}
+	for _, msg := range runCmd(cmd) {
+		for _, msg := range runCmd(send(msg)) {
+			send(msg)
+		}
+	}
+
+	if strings.Contains(ai.Logs, "hunter2") || !strings.Contains(ai.Logs, "line-60 ERROR connect failed") {
+		t.Errorf("expected the visible lines sent with secrets masked, got %q", ai.Logs)
+	}
+	if strings.Contains(ai.Logs, "line-00") {
+		t.Errorf("expected only the lines the panel shows, got %q", ai.Logs)
+	}
+	view := m.View()
+	for _, want := range []string{"The database refused the connection.", "$ docker restart db", "c Copy fix to Agent"} {
+		if !strings.Contains(view, want) {
+			t.Errorf("expected %q in the analysis, got:\n%s", want, view)
+		}
+	}
+
+	for _, msg := range runCmd(send(key("c"))) {
+		send(msg)
+	}
+	if m.activeTab != TabAgent || m.agent.InputValue() != "docker restart db" || m.mode != ModeInsert {
+		t.Errorf("expected c to put the fix in the Agent's input, got tab %v, input %q", m.activeTab, m.agent.InputValue())
+	}
+	if m.containers.AnalysisOpen() {
+		t.Error("expected the analysis to close once its fix is used")
+	}
+}
+
+func TestModel_BulkActions(t *testing.T) {
+	docker := infra.NewFakeDocker(
+		infra.ContainerInfo{ID: "a1", Name: "web", State: "running"},
+		infra.ContainerInfo{ID: "b2", Name: "db", State: "running"},
+		infra.ContainerInfo{ID: "c3", Name: "cache", State: "exited"},
+	)
+	model := NewModel(docker, llm.NewFakeProvider())
+	newModel, _ := model.Update(tea.WindowSizeMsg{Width: 120, Height: 40})
+	newModel, _ = newModel.Update(model.checkDockerHealth())
+	m := newModel.(Model)
+	m.state = StateMain
+	m.activeTab = TabContainers
+
+	// press feeds a key, and the bulk action it leads to, back into the app.
+	press := func(k tea.KeyMsg) {
+		newModel, cmd := m.Update(k)
+		for _, msg := range runCmd(cmd) {
+			if bulk, ok := msg.(monitor.BulkActionMsg); ok {
+				var next tea.Cmd
+				newModel, next = newModel.Update(bulk)
+				for _, msg := range runCmd(next) {
+					if done, ok := msg.(bulkActionDoneMsg); ok {
+						newModel, _ = newModel.Update(done)
+						newModel, _ = newModel.Update(model.checkDockerHealth())
+					}
+				}
+			}
+		}
+		m = newModel.(Model)
+	}
+	key := func(s string) tea.KeyMsg { return tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(s)} }
+	space := tea.KeyMsg{Type: tea.KeySpace, Runes: []rune(" ")}
+
+	press(space)
+	press(key("j"))
+	press(space)
+	if marked := m.containers.Marked(); len(marked) != 2 || marked[0].Name != "web" || marked[1].Name != "db" {
+		t.Fatalf("expected web and db marked, got %+v", marked)
+	}
+
+	press(key("x"))
+	if view := m.View(); !strings.Contains(view, "Stop 2 containers") || !strings.Contains(view, "y stop") {
+		t.Fatalf("expected a summary before stopping the marked services, got:\n%s", view)
+	}
+	press(key("n"))
+	if m.containers.ModalOpen() || docker.Containers[0].State != "running" {
+		t.Fatal("expected n to cancel without stopping anything")
+	}
+
+	press(key("x"))
+	press(key("y"))
+	for _, c := range docker.Containers {
+		if want := map[string]string{"web": "exited", "db": "exited", "cache": "exited"}[c.Name]; c.State != want {
+			t.Errorf("expected %s %s after the bulk stop, got %s", c.Name, want, c.State)
+		}
+	}
+
+	press(key("r"))
+	press(key("y"))
+	if docker.Containers[0].State != "running" || docker.Containers[1].State != "running" || docker.Containers[2].State != "exited" {
+		t.Errorf("expected only the marked services restarted, got %+v", docker.Containers)
+	}
+
+	press(key("d"))
+	// The toasts of the earlier actions cover part of the app's view.
+	if view := m.containers.View(); !strings.Contains(view, "Remove 2 containers") || !strings.Contains(view, "2 still") {
+		t.Fatalf("expected the removal summary to warn about running containers, got:\n%s", view)
+	}
+	press(key("y"))
+	if len(docker.Containers) != 1 || docker.Containers[0].Name != "cache" {
+		t.Errorf("expected only cache left, got %+v", docker.Containers)
+	}
+	if m.containers.MergedLogs() {
+		t.Error("expected the removed services unmarked")
+	}
+	if notes := m.notifications.Log(); len(notes) < 2 || notes[len(notes)-2].Text != "web removed" || notes[len(notes)-1].Text != "db removed" {
+		t.Errorf("expected a notification for each removed container, got %+v", notes)
+	}
+}
+
+func TestModel_ExpertModeSkipsConfirm(t *testing.T) {
+	t.Setenv("DEV_CLI_EXPERT_MODE", "1")
+	docker := infra.NewFakeDocker(
+		infra.ContainerInfo{ID: "a1", Name: "web", State: "running"},
+		infra.ContainerInfo{ID: "b2", Name: "db", State: "running"},
+	)
+	model := NewModel(docker, llm.NewFakeProvider())
+	newModel, _ := model.Update(tea.WindowSizeMsg{Width: 120, Height: 40})
+	newModel, _ = newModel.Update(model.checkDockerHealth())
+	m := newModel.(Model)
+	m.state = StateMain
+	m.activeTab = TabContainers
+	if !m.containers.ExpertMode() {
+		t.Fatal("expected DEV_CLI_EXPERT_MODE to turn expert mode on")
+	}
+
+	newModel, cmd := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("d")})
+	m = newModel.(Model)
+	if m.containers.ModalOpen() {
+		t.Fatalf("expected no confirmation in expert mode, got:\n%s", m.View())
+	}
+	var bulk *monitor.BulkActionMsg
+	for _, msg := range runCmd(cmd) {
+		if b, ok := msg.(monitor.BulkActionMsg); ok {
+			bulk = &b
+		}
+	}
+	if bulk == nil || bulk.Action != "remove" || len(bulk.Containers) != 1 || bulk.Containers[0].Name != "web" {
+		t.Fatalf("expected d to remove web straight away, got %+v", bulk)
+	}
+}
+
+func TestModel_AnalyzesUnhealthyContainer(t *testing.T) {
+	docker := infra.NewFakeDocker(
+		infra.ContainerInfo{ID: "a1", Name: "web", State: "running", Health: "unhealthy", RestartCount: 3},
+		infra.ContainerInfo{ID: "b2", Name: "db", State: "running", Health: "healthy"},
+	)
+	docker.Logs["a1"] = []string{"panic: connection refused"}
+	ai := llm.NewFakeProvider()
+	model := NewModel(docker, ai)
+
+	newModel, _ := model.Update(tea.WindowSizeMsg{Width: 120, Height: 40})
+	newModel, cmd := newModel.Update(model.checkDockerHealth())
+	m := newModel.(Model)
+	defer m.statsCancel()
+	runCmd(cmd)
+
+	var analysis *pipeline.Block
+	for _, b := range m.agent.Blocks() {
+		if b.Type == pipeline.BlockTypeAI {
+			analysis = &b
+		}
+	}
+	if analysis == nil || !strings.Contains(analysis.Command, "web") {
+		t.Fatalf("expected an AI block analyzing web, got %+v", m.agent.Blocks())
+	}
+	if analysis.Output != "fake analysis" || analysis.AISuggestion != "echo fixed" {
+		t.Errorf("unexpected analysis %q / %q", analysis.Output, analysis.AISuggestion)
+	}
+
+	// Still unhealthy on the next check: no second analysis.
+	newModel, cmd = m.Update(m.checkDockerHealth())
+	m = newModel.(Model)
+	runCmd(cmd)
+	if calls := ai.CallCount("AnalyzeLog"); calls != 1 {
+		t.Errorf("expected one analysis, got %d", calls)
+	}
+
+	m.state = StateMain
+	m.activeTab = TabContainers
+	if view := m.View(); !strings.Contains(view, "♥") || !strings.Contains(view, "↻3") {
+		t.Error("expected health badges and the restart count in the services list")
+	}
+}
+
+func TestModel_FileBrowser(t *testing.T) {
+	docker := infra.NewFakeDocker(infra.ContainerInfo{ID: "a1", Name: "web", State: "running"})
+	docker.Files["a1"] = map[string]string{"/etc/nginx/nginx.conf": "worker_processes 1;"}
+	model := NewModel(docker, llm.NewFakeProvider())
+
+	newModel, _ := model.Update(tea.WindowSizeMsg{Width: 120, Height: 40})
+	newModel, _ = newModel.Update(model.checkDockerHealth())
+	m := newModel.(Model)
+	m.state = StateMain
+	m.activeTab = TabContainers
+	m.cwd = t.TempDir()
+
+	// feed applies msg and then the browser messages it leads to.
+	var feed func(m Model, msg tea.Msg) Model
+	feed = func(m Model, msg tea.Msg) Model {
+		newModel, cmd := m.Update(msg)
+		m = newModel.(Model)
+		for _, next := range runCmd(cmd) {
+			switch next.(type) {
+			case monitor.ListDirMsg, dirListedMsg, monitor.CopyFileMsg, fileCopiedMsg:
+				m = feed(m, next)
+			}
+		}
+		return m
+	}
+	key := func(s string) tea.KeyMsg {
+		switch s {
+		case "enter":
+			return tea.KeyMsg{Type: tea.KeyEnter}
+		case "esc":
+			return tea.KeyMsg{Type: tea.KeyEsc}
+		}
+		return tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(s)}
+	}
+
+	m = feed(m, key("b"))
+	if !m.containers.FilesOpen() || m.mode != ModeInsert {
+		t.Fatal("expected b to open the file browser and take the keyboard")
+	}
+	m = feed(m, key("enter")) // etc/
+	m = feed(m, key("j"))     // past ..
+	m = feed(m, key("enter")) // nginx/
+	m = feed(m, key("j"))
+	if view := m.View(); !strings.Contains(view, "/etc/nginx") || !strings.Contains(view, "nginx.conf") {
+		t.Fatalf("expected the browser to show /etc/nginx, got:\n%s", view)
+	}
+
+	m = feed(m, key("d"))
+	m = feed(m, key("enter"))
+	if got, err := os.ReadFile(filepath.Join(m.cwd, "nginx.conf")); err != nil || string(got) != "worker_processes 1;" {
+		t.Fatalf("expected nginx.conf downloaded to the working directory, got %q, %v", got, err)
+	}
+	if !strings.Contains(m.View(), "Saved /etc/nginx/nginx.conf") {
+		t.Error("expected the copy to be reported")
+	}
+
+	os.WriteFile(filepath.Join(m.cwd, "nginx.conf"), []byte("worker_processes 4;"), 0o644)
+	m = feed(m, key("u"))
+	m = feed(m, key("nginx.conf"))
+	m = feed(m, key("enter"))
+	if got := docker.Files["a1"]["/etc/nginx/nginx.conf"]; got != "worker_processes 4;" {
+		t.Errorf("expected the patched file pushed back, got %q", got)
+	}
+
+	m = feed(m, key("esc"))
+	if m.containers.FilesOpen() || m.mode != ModeNormal {
+		t.Error("expected esc to close the browser")
+	}
+}
+
+func TestModel_VolumeBackupRestore(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+
+	docker := infra.NewFakeDocker(infra.ContainerInfo{ID: "a1", Name: "db", State: "running"})
+	docker.Volumes = []infra.VolumeInfo{{Name: "pgdata", Driver: "local"}}
+	docker.VolumeData["pgdata"] = map[string]string{"PG_VERSION": "16"}
+	model := NewModel(docker, llm.NewFakeProvider())
+
+	// feed applies msg and then the volume messages it leads to.
+	var feed func(m Model, msg tea.Msg) Model
+	feed = func(m Model, msg tea.Msg) Model {
+		newModel, cmd := m.Update(msg)
+		m = newModel.(Model)
+		for _, next := range runCmd(cmd) {
+			switch next.(type) {
+			case volumesMsg, monitor.BackupVolumeMsg, monitor.ListBackupsMsg, backupsMsg, monitor.RestoreVolumeMsg, volumeOpMsg:
+				m = feed(m, next)
+			}
+		}
+		return m
+	}
+
+	m := feed(model, tea.WindowSizeMsg{Width: 120, Height: 40})
+	m = feed(m, model.checkDockerHealth())
+	defer m.statsCancel()
+	m.state = StateMain
+	m.activeTab = TabContainers
+	if !strings.Contains(m.View(), "Volumes [1]") {
+		t.Fatal("expected a Volumes panel")
+	}
+
+	tab := tea.KeyMsg{Type: tea.KeyTab}
+	for m.containers.Focus() != monitor.FocusVolumes {
+		m = feed(m, tab)
+	}
+	m = feed(m, tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("b")})
+	backups, _ := infra.ListVolumeBackups(filepath.Join(home, ".devlogs", "backups"), "pgdata")
+	if len(backups) != 1 {
+		t.Fatalf("expected a backup under ~/.devlogs/backups, got %+v", backups)
+	}
+	if !strings.Contains(m.View(), "Saved pgdata-") {
+		t.Error("expected the backup to be reported")
+	}
+
+	docker.VolumeData["pgdata"]["PG_VERSION"] = "oops"
+	m = feed(m, tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("r")})
+	if !m.containers.RestoreOpen() || !strings.Contains(m.View(), "Restore pgdata") {
+		t.Fatal("expected r to offer the backups to restore")
+	}
+	m = feed(m, tea.KeyMsg{Type: tea.KeyEnter})
+	if got := docker.VolumeData["pgdata"]["PG_VERSION"]; got != "16" {
+		t.Errorf("expected the volume restored, got %q", got)
+	}
+	if m.containers.RestoreOpen() || !strings.Contains(m.View(), "Restored pgdata") {
+		t.Error("expected the picker closed and the restore reported")
+	}
+}
+
+func TestModel_VolumeRemovePrune(t *testing.T) {
+	docker := infra.NewFakeDocker(infra.ContainerInfo{ID: "a1", Name: "db", State: "running"})
+	docker.Volumes = []infra.VolumeInfo{
+		{Name: "pgdata", Size: 300 << 20, Containers: []string{"db"}},
+		{Name: "scratch", Size: 2 << 20},
+		{Name: "old-cache", Size: 40 << 20},
+	}
+	model := NewModel(docker, llm.NewFakeProvider())
+
+	// feed applies msg and then the volume messages it leads to.
+	var feed func(m Model, msg tea.Msg) Model
+	feed = func(m Model, msg tea.Msg) Model {
+		newModel, cmd := m.Update(msg)
+		m = newModel.(Model)
+		for _, next := range runCmd(cmd) {
+			switch next.(type) {
+			case volumesMsg, monitor.RemoveVolumeMsg, volumeOpMsg, monitor.PruneMsg, pruneDoneMsg:
+				m = feed(m, next)
+			}
+		}
+		return m
+	}
+	press := func(m Model, k string) Model { return feed(m, tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(k)}) }
+
+	m := feed(model, tea.WindowSizeMsg{Width: 120, Height: 40})
+	m = feed(m, model.checkDockerHealth())
+	m = feed(m, model.fetchVolumes())
+	defer m.statsCancel()
+	m.state = StateMain
+	m.activeTab = TabContainers
+	for m.containers.Focus() != monitor.FocusVolumes {
+		m = feed(m, tea.KeyMsg{Type: tea.KeyTab})
+	}
+
+	view := m.View()
+	for _, want := range []string{"pgdata", "300.0MB", "↳ db"} {
+		if !strings.Contains(view, want) {
+			t.Errorf("expected %q in the Volumes panel, got:\n%s", want, view)
+		}
+	}
+
+	// A volume in use is explained, not removed.
+	m = press(m, "d")
+	if !strings.Contains(m.View(), "It is used by db") {
+		t.Fatalf("expected the removal to name the users, got:\n%s", m.View())
+	}
+	m = press(m, "y")
+	if len(docker.Volumes) != 3 || !m.containers.ModalOpen() {
+		t.Fatal("expected y to leave a volume in use alone")
+	}
+	m = feed(m, tea.KeyMsg{Type: tea.KeyEsc})
+
+	m = press(m, "j")
+	if !strings.Contains(m.View(), "↳ unused") {
+		t.Errorf("expected scratch shown as unused, got:\n%s", m.View())
+	}
+	m = press(m, "d")
+	if !strings.Contains(m.View(), "⚠ Remove volume scratch") {
+		t.Fatalf("expected d to ask first, got:\n%s", m.View())
+	}
+	m = press(m, "y")
+	if len(docker.Volumes) != 2 || m.containers.ModalOpen() {
+		t.Fatalf("expected scratch removed, got %+v", docker.Volumes)
+	}
+	if len(m.containers.Volumes()) != 2 || !strings.Contains(m.View(), "Removed scratch") {
+		t.Errorf("expected the list refreshed and the removal reported, got:\n%s", m.View())
+	}
+
+	m = press(m, "p")
+	if !m.containers.PruneOpen() || !strings.Contains(m.View(), "Frees up to 40.0 MB of 340.0 MB") {
+		t.Fatalf("expected p to offer pruning the unused volumes, got:\n%s", m.View())
+	}
+	m = press(m, "y")
+	if len(docker.Volumes) != 1 || docker.Volumes[0].Name != "pgdata" {
+		t.Errorf("expected only pgdata left, got %+v", docker.Volumes)
+	}
+}
+
+func TestModel_ImageLayers(t *testing.T) {
+	docker := infra.NewFakeDocker()
+	docker.Images = []infra.ImageInfo{{ID: "abc123", Tags: []string{"web:latest"}, Size: 420 << 20}}
+	docker.History["abc123"] = []infra.ImageLayer{
+		{CreatedBy: "/bin/sh -c #(nop)  CMD [\"./server\"]"},
+		{CreatedBy: "/bin/sh -c apt-get update && apt-get install -y build-essential", Size: 340 << 20},
+		{CreatedBy: "/bin/sh -c #(nop) ADD file:1234 in / ", Size: 80 << 20},
+	}
+	model := NewModel(docker, llm.NewFakeProvider())
+
+	// feed applies msg and then the layer messages it leads to.
+	var feed func(m Model, msg tea.Msg) Model
+	feed = func(m Model, msg tea.Msg) Model {
+		newModel, cmd := m.Update(msg)
+		m = newModel.(Model)
+		for _, next := range runCmd(cmd) {
+			switch next.(type) {
+			case imagesMsg, monitor.ImageHistoryMsg, imageHistoryMsg:
+				m = feed(m, next)
+			}
+		}
+		return m
+	}
+
+	m := feed(model, tea.WindowSizeMsg{Width: 120, Height: 40})
+	m = feed(m, model.checkDockerHealth())
+	m = feed(m, model.fetchImages())
+	m.state = StateMain
+	m.activeTab = TabContainers
+
+	for m.containers.Focus() != monitor.FocusImages {
+		m = feed(m, tea.KeyMsg{Type: tea.KeyTab})
+	}
+	m = feed(m, tea.KeyMsg{Type: tea.KeyEnter})
+	if !m.containers.LayersOpen() || m.mode != ModeInsert {
+		t.Fatal("expected enter on an image to open its layers")
+	}
+	view := m.View()
+	if !strings.Contains(view, "Layers (web:latest)") || !strings.Contains(view, "3 layers") {
+		t.Fatalf("expected the layer view of web:latest, got:\n%s", view)
+	}
+	if !strings.Contains(view, "RUN apt-get update") || !strings.Contains(view, `CMD ["./server"]`) {
+		t.Error("expected the layer commands without the shell wrapper")
+	}
+
+	// The base layer comes first; L jumps to the apt-get layer.
+	m = feed(m, tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("L")})
+	if !strings.Contains(m.View(), "remove /var/lib/apt/lists") {
+		t.Error("expected a hint on the apt-get layer")
+	}
+
+	m = feed(m, tea.KeyMsg{Type: tea.KeyEsc})
+	if m.containers.LayersOpen() || m.mode != ModeNormal {
+		t.Error("expected esc to close the layer view")
+	}
+}
+
+func TestModel_DiskUsagePrune(t *testing.T) {
+	docker := infra.NewFakeDocker(
+		infra.ContainerInfo{ID: "a1", Name: "web", State: "running"},
+		infra.ContainerInfo{ID: "b2", Name: "old", State: "exited"},
+	)
+	docker.Disk = infra.DiskUsage{Categories: []infra.DiskUsageCategory{
+		{Kind: infra.DiskImages, Count: 2, Active: 2, Size: 900 << 20},
+		{Kind: infra.DiskContainers, Count: 2, Active: 1, Size: 60 << 20, Reclaimable: 50 << 20},
+	}}
+	model := NewModel(docker, llm.NewFakeProvider())
+
+	// feed applies msg and then the disk messages it leads to.
+	var feed func(m Model, msg tea.Msg) Model
+	feed = func(m Model, msg tea.Msg) Model {
+		newModel, cmd := m.Update(msg)
+		m = newModel.(Model)
+		for _, next := range runCmd(cmd) {
+			switch next.(type) {
+			case diskUsageMsg, monitor.PruneMsg, pruneDoneMsg, dockerHealthMsg:
+				m = feed(m, next)
+			}
+		}
+		return m
+	}
+	key := func(s string) tea.KeyMsg { return tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(s)} }
+
+	m := feed(model, tea.WindowSizeMsg{Width: 120, Height: 40})
+	m = feed(m, model.checkDockerHealth())
+	defer m.statsCancel()
+	m.state = StateMain
+	m.activeTab = TabContainers
+	if view := m.View(); !strings.Contains(view, "Disk [960.0 MB]") || !strings.Contains(view, "↺50.0MB") {
+		t.Fatalf("expected a Disk panel with the reclaimable space, got:\n%s", view)
+	}
+
+	for m.containers.Focus() != monitor.FocusDisk {
+		m = feed(m, tea.KeyMsg{Type: tea.KeyTab})
+	}
+	m = feed(m, key("p"))
+	if m.containers.PruneOpen() {
+		t.Fatal("expected no prune offered for images with nothing to reclaim")
+	}
+	m = feed(m, key("j"))
+	m = feed(m, key("p"))
+	if !m.containers.PruneOpen() || m.mode != ModeInsert || !strings.Contains(m.View(), "every stopped container") {
+		t.Fatal("expected p to ask before pruning containers")
+	}
+	m = feed(m, key("n"))
+	if m.containers.PruneOpen() || len(docker.Containers) != 2 {
+		t.Fatal("expected n to cancel the prune")
+	}
+
+	m = feed(m, key("p"))
+	m = feed(m, key("y"))
+	if len(docker.Containers) != 1 {
+		t.Errorf("expected the stopped container pruned, got %+v", docker.Containers)
+	}
+	if view := m.View(); !strings.Contains(view, "Freed 50.0 MB") || strings.Contains(view, "↺50.0MB") {
+		t.Errorf("expected the prune reported and the usage refreshed, got:\n%s", view)
+	}
+}
+
+func TestModel_InspectAndUpdateLimits(t *testing.T) {
+	docker := infra.NewFakeDocker(infra.ContainerInfo{ID: "a1", Name: "api", Image: "api:dev", State: "running", RestartCount: 4})
+	docker.Limits["a1"] = infra.ResourceLimits{Memory: 256 << 20}
+	model := NewModel(docker, llm.NewFakeProvider())
+
+	// feed applies msg and then the inspector messages it leads to.
+	var feed func(m Model, msg tea.Msg) Model
+	feed = func(m Model, msg tea.Msg) Model {
+		newModel, cmd := m.Update(msg)
+		m = newModel.(Model)
+		for _, next := range runCmd(cmd) {
+			switch next.(type) {
+			case monitor.InspectContainerMsg, containerInspectedMsg, monitor.UpdateResourcesMsg, resourcesUpdatedMsg:
+				m = feed(m, next)
+			}
+		}
+		return m
+	}
+	key := func(s string) tea.KeyMsg {
+		switch s {
+		case "enter":
+			return tea.KeyMsg{Type: tea.KeyEnter}
+		case "esc":
+			return tea.KeyMsg{Type: tea.KeyEsc}
+		}
+		return tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(s)}
+	}
+
+	m := feed(model, tea.WindowSizeMsg{Width: 120, Height: 40})
+	m = feed(m, model.checkDockerHealth())
+	defer m.statsCancel()
+	m.state = StateMain
+	m.activeTab = TabContainers
+
+	m = feed(m, key("i"))
+	if !m.containers.InspectOpen() || m.mode != ModeInsert {
+		t.Fatal("expected i to open the inspector and take the keyboard")
+	}
+	view := m.View()
+	if !strings.Contains(view, "api:dev") || !strings.Contains(view, "256.0 MB") || !strings.Contains(view, "Restarts") {
+		t.Fatalf("expected the image, memory limit and restarts, got:\n%s", view)
+	}
+
+	m = feed(m, key("m"))
+	if !strings.Contains(m.View(), "256m") {
+		t.Error("expected the prompt prefilled with the current limit")
+	}
+	m = feed(m, tea.KeyMsg{Type: tea.KeyCtrlU})
+	m = feed(m, key("1g"))
+	m = feed(m, key("enter"))
+	if got := docker.Limits["a1"].Memory; got != 1<<30 {
+		t.Fatalf("expected the memory limit raised to 1g, got %d", got)
+	}
+	if view := m.View(); !strings.Contains(view, "Memory limit is now 1.0 GB") || strings.Contains(view, "256.0 MB") {
+		t.Errorf("expected the new limit shown, got:\n%s", view)
+	}
+
+	m = feed(m, key("esc"))
+	if m.containers.InspectOpen() || m.mode != ModeNormal {
+		t.Error("expected esc to close the inspector")
+	}
+}
+
+func TestModel_Processes(t *testing.T) {
+	docker := infra.NewFakeDocker(infra.ContainerInfo{ID: "a1", Name: "web", State: "running"})
+	docker.Processes["a1"] = []infra.ProcessInfo{
+		{PID: "4100", User: "root", CPU: "0.0", Memory: "0.4", Command: "nginx: master process nginx -g daemon off;"},
+		{PID: "4160", User: "nginx", CPU: "12.5", Memory: "1.2", Command: "nginx: worker process"},
+		{PID: "4161", User: "nginx", CPU: "0.3", Memory: "1.1", Command: "nginx: worker process"},
+	}
+	model := NewModel(docker, llm.NewFakeProvider())
+
+	// feed applies msg and then the process messages it leads to.
+	var feed func(m Model, msg tea.Msg) Model
+	feed = func(m Model, msg tea.Msg) Model {
+		newModel, cmd := m.Update(msg)
+		m = newModel.(Model)
+		for _, next := range runCmd(cmd) {
+			switch next.(type) {
+			case monitor.ProcessesMsg, processesMsg, monitor.SignalProcessMsg, processSignaledMsg:
+				m = feed(m, next)
+			}
+		}
+		return m
+	}
+	press := func(m Model, k string) Model { return feed(m, tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(k)}) }
+
+	m := feed(model, tea.WindowSizeMsg{Width: 120, Height: 40})
+	m = feed(m, model.checkDockerHealth())
+	defer m.statsCancel()
+	m.state = StateMain
+	m.activeTab = TabContainers
+
+	m = press(m, "t")
+	if !m.containers.ProcessesOpen() || m.mode != ModeInsert {
+		t.Fatal("expected t to open the process list and take the keyboard")
+	}
+	view := m.View()
+	for _, want := range []string{"⚙ Processes", "3 processes", "4160", "12.5", "nginx: worker process"} {
+		if !strings.Contains(view, want) {
+			t.Errorf("expected %q in the process list, got:\n%s", want, view)
+		}
+	}
+
+	m = press(m, "j")
+	m = press(m, "x")
+	if !strings.Contains(m.View(), "Send SIGTERM to 4160") {
+		t.Fatalf("expected x to ask before sending SIGTERM, got:\n%s", m.View())
+	}
+	m = feed(m, tea.KeyMsg{Type: tea.KeyEsc})
+	if !m.containers.ProcessesOpen() || len(docker.Execs) != 0 {
+		t.Fatal("expected esc to cancel the signal, not close the list")
+	}
+
+	m = press(m, "X")
+	m = press(m, "y")
+	if !slices.Equal(docker.Execs, []string{"kill -s KILL 4160"}) {
+		t.Fatalf("expected SIGKILL sent to 4160, got %v", docker.Execs)
+	}
+	view = m.View()
+	if !strings.Contains(view, "Sent SIGKILL to 4160") || !strings.Contains(view, "2 processes") {
+		t.Errorf("expected the signal reported and the list refreshed, got:\n%s", view)
+	}
+
+	// The open list refreshes itself every few seconds.
+	docker.Processes["a1"] = docker.Processes["a1"][:1]
+	m.topTicks = processesRefreshTicks - 1
+	m = feed(m, spinner.TickMsg{})
+	if !strings.Contains(m.View(), "1 process •") {
+		t.Errorf("expected the list refreshed on the spinner tick, got:\n%s", m.View())
+	}
+
+	m = feed(m, tea.KeyMsg{Type: tea.KeyEsc})
+	if m.containers.ProcessesOpen() || m.containers.ProcessesContainer() != "" {
+		t.Error("expected esc to close the process list")
+	}
+}
+
+func TestModel_InspectDetails(t *testing.T) {
+	docker := infra.NewFakeDocker(infra.ContainerInfo{ID: "a1", Name: "api", Image: "api:dev", State: "running", Status: "Up 2 hours",
+		Ports: []infra.PortMapping{
+			{Private: 9229, Protocol: "tcp"},
+			{Private: 3000, Public: 8080, Protocol: "tcp", HostIP: "0.0.0.0"},
+			{Private: 3000, Public: 8080, Protocol: "tcp", HostIP: "::"},
+		}})
+	env := []string{
+		"POSTGRES_PASSWORD=hunter2",
+		"DATABASE_URL=postgresql://app:hunter2@db:5432/app",
+		"NODE_ENV=production",
+	}
+	for i := range 30 {
+		env = append(env, fmt.Sprintf("FEATURE_%02d=on", i))
+	}
+	docker.Details["a1"] = infra.ContainerDetail{
+		EnvVars: env,
+		Cmd:     []string{"node", "server.js"},
+		Mounts:  []infra.Mount{{Source: "/srv/api/config", Destination: "/app/config", ReadOnly: true}},
+	}
+	model := NewModel(docker, llm.NewFakeProvider())
+
+	// feed applies msg and then the inspector messages it leads to.
+	var feed func(m Model, msg tea.Msg) Model
+	feed = func(m Model, msg tea.Msg) Model {
+		newModel, cmd := m.Update(msg)
+		m = newModel.(Model)
+		for _, next := range runCmd(cmd) {
+			switch next.(type) {
+			case monitor.InspectContainerMsg, containerInspectedMsg:
+				m = feed(m, next)
+			}
+		}
+		return m
+	}
+	press := func(m Model, k string) Model { return feed(m, tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(k)}) }
+
+	m := feed(model, tea.WindowSizeMsg{Width: 120, Height: 40})
+	m = feed(m, model.checkDockerHealth())
+	defer m.statsCancel()
+	m.state = StateMain
+	m.activeTab = TabContainers
+
+	m = press(m, "i")
+	view := m.View()
+	for _, want := range []string{
+		"running for Up 2 hours", "node server.js", "8080 → 3000/tcp", "9229/tcp, not published",
+		"/srv/api/config → /app/config (ro)", "POSTGRES_PASSWORD=[REDACTED]", "postgresql://[user]:[REDACTED]@db",
+		"NODE_ENV=production", "of 33 • j/k scroll",
+	} {
+		if !strings.Contains(view, want) {
+			t.Errorf("expected %q in the inspector, got:\n%s", want, view)
+		}
+	}
+	if strings.Contains(view, "hunter2") {
+		t.Errorf("expected the password masked, got:\n%s", view)
+	}
+	if strings.Count(view, "8080 → 3000/tcp") != 1 {
+		t.Error("expected a port bound on IPv4 and IPv6 shown once")
+	}
+
+	for range 32 {
+		m = press(m, "j")
+	}
+	if view := m.View(); !strings.Contains(view, "FEATURE_29=on") || strings.Contains(view, "NODE_ENV") {
+		t.Errorf("expected j to scroll to the last variables, got:\n%s", view)
+	}
+}
+
+func TestModel_HostStatsStrip(t *testing.T) {
+	docker := infra.NewFakeDocker(infra.ContainerInfo{ID: "a1", Name: "web", State: "running"})
+	model := NewModel(docker, llm.NewFakeProvider())
+	newModel, _ := model.Update(tea.WindowSizeMsg{Width: 160, Height: 40})
+	m := newModel.(Model)
+	m.state = StateMain
+	m.activeTab = TabContainers
+	if strings.Contains(m.View(), "⌂ Host") {
+		t.Fatal("expected no host strip before the first sample")
+	}
+	lines := strings.Count(m.View(), "\n")
+
+	newModel, _ = m.Update(hostStatsMsg{stats: infra.HostStats{
+		CPUPercent: 23, CPUs: 8,
+		MemUsed: 4 << 30, MemTotal: 16 << 30,
+		DiskPath: "/", DiskUsed: 100 << 30, DiskTotal: 500 << 30,
+		Load1: 1.5, Load5: 1, Load15: 0.5,
+	}})
+	m = newModel.(Model)
+	m.containers = m.containers.SetServices(docker.Containers)
+	m.containers = m.containers.SetContainerStats("web", monitor.ContainerStats{CPUHistory: []int{80}, MemUsed: 1024, MemTotal: 16384})
+
+	view := m.View()
+	for _, want := range []string{"⌂ Host", "CPU 23% ×8", "Mem 4.0GB/16.0GB", "Disk 100.0GB/500.0GB", "Load 1.50 1.00 0.50", "web: 6.2% of host mem, 10.0% of CPU"} {
+		if !strings.Contains(view, want) {
+			t.Errorf("expected %q in the host strip, got:\n%s", want, view)
+		}
+	}
+	if got := strings.Count(view, "\n"); got != lines {
+		t.Errorf("expected the panels to shrink to fit the strip, view grew from %d to %d lines", lines, got)
+	}
+}
+
+func TestModel_AgentCompletion(t *testing.T) {
+	bin := t.TempDir()
+	for name, mode := range map[string]os.FileMode{"gitk": 0o755, "gizmo": 0o755, "README": 0o644} {
+		if err := os.WriteFile(filepath.Join(bin, name), nil, mode); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if got := agent.PathExecutables(bin + string(os.PathListSeparator) + bin); !slices.Equal(got, []string{"gitk", "gizmo"}) {
+		t.Fatalf("PathExecutables = %q", got)
+	}
+
+	model := NewModel(infra.NewFakeDocker(), llm.NewFakeProvider())
+	newModel, _ := model.Update(tea.WindowSizeMsg{Width: 120, Height: 40})
+	m := newModel.(Model)
+	m.state = StateMain
+	newModel, _ = m.Update(completionsLoadedMsg{
+		history:     []storage.CommandFrequency{{Command: "git status", Count: 9}, {Command: "git push", Count: 2}},
+		executables: agent.PathExecutables(bin),
+	})
+	m = newModel.(Model)
+
+	key := func(s string) tea.KeyMsg { return tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(s)} }
+	send := func(msg tea.KeyMsg) {
+		newModel, _ := m.Update(msg)
+		m = newModel.(Model)
+	}
+	send(key("i"))
+	send(key("gi"))
+	if !strings.Contains(m.View(), "git status") {
+		t.Fatalf("expected the most run command as a ghost suggestion, got:\n%s", m.View())
+	}
+
+	send(tea.KeyMsg{Type: tea.KeyTab})
+	send(tea.KeyMsg{Type: tea.KeyCtrlF})
+	if got := m.agent.InputValue(); got != "git push" {
+		t.Errorf("expected Tab to cycle to the next candidate and Ctrl+f to accept it, got %q", got)
+	}
+
+	send(tea.KeyMsg{Type: tea.KeyCtrlU})
+	send(key("giz"))
+	send(tea.KeyMsg{Type: tea.KeyTab})
+	if got := m.agent.InputValue(); got != "gizmo" {
+		t.Errorf("expected Tab to complete a lone PATH candidate, got %q", got)
+	}
+}
+
+func TestModel_HistorySearch(t *testing.T) {
+	db, err := storage.OpenDB(filepath.Join(t.TempDir(), "history.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	for _, command := range []string{"docker compose up -d", "kubectl get pods -A", "go test ./..."} {
+		if err := storage.SaveCommand(db, storage.LogEntry{Command: command}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	model := NewModel(infra.NewFakeDocker(), llm.NewFakeProvider())
+	model.db = db
+	newModel, _ := model.Update(tea.WindowSizeMsg{Width: 120, Height: 40})
+	m := newModel.(Model)
+	m.state = StateMain
+	lines := strings.Count(m.View(), "\n")
+
+	// feed applies msg and then the search messages it leads to.
+	var feed func(m Model, msg tea.Msg) Model
+	feed = func(m Model, msg tea.Msg) Model {
+		newModel, cmd := m.Update(msg)
+		m = newModel.(Model)
+		for _, next := range runCmd(cmd) {
+			switch next.(type) {
+			case agent.SearchHistoryMsg, searchHistoryLoadedMsg:
+				m = feed(m, next)
+			}
+		}
+		return m
+	}
+
+	m = feed(m, tea.KeyMsg{Type: tea.KeyCtrlR})
+	if !m.agent.SearchOpen() || m.mode != ModeInsert {
+		t.Fatal("expected Ctrl+R to open the history search")
+	}
+	if view := m.View(); !strings.Contains(view, "⌕ History") || !strings.Contains(view, "3/3") {
+		t.Fatalf("expected the stored history to be searched, got:\n%s", view)
+	} else if got := strings.Count(view, "\n"); got != lines {
+		t.Errorf("expected the search to take the blocks' place, view went from %d to %d lines:\n%s", lines, got, view)
+	}
+
+	m = feed(m, tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("kgp")})
+	m = feed(m, tea.KeyMsg{Type: tea.KeyEnter})
+	if m.agent.SearchOpen() || !m.agent.InsertMode() {
+		t.Fatal("expected Enter to close the search into the input line")
+	}
+	if got := m.agent.InputValue(); got != "kubectl get pods -A" {
+		t.Errorf("expected the fuzzy match on the input line, got %q", got)
+	}
+}
+
+func TestModel_CycleTheme(t *testing.T) {
+	t.Cleanup(func() { theme.Apply(theme.Catppuccin) })
+	t.Setenv("DEV_CLI_THEME", "solarized-light")
+
+	model := NewModel(infra.NewFakeDocker(), llm.NewFakeProvider())
+	if got := theme.Current().Name; got != "solarized-light" {
+		t.Fatalf("expected DEV_CLI_THEME to pick the palette, got %q", got)
+	}
+	model.state = StateMain
+
+	newModel, _ := model.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("T")})
+	m := newModel.(Model)
+	if got := theme.Current().Name; got != "high-contrast" {
+		t.Errorf("expected T to switch to the next theme, got %q", got)
+	}
+	if m.spinner.Style.GetForeground() != theme.Mauve {
+		t.Error("expected the spinner to follow the new theme")
+	}
+
+	m.mode = ModeInsert
+	theme.Apply(theme.Catppuccin)
+	m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("T")})
+	if got := theme.Current().Name; got != "catppuccin" {
+		t.Errorf("expected T to be typed, not switch themes, in insert mode; got %q", got)
+	}
+}
+
+func TestModel_ASCIIMode(t *testing.T) {
+	t.Cleanup(func() { theme.SetASCII(false) })
+	t.Setenv("DEV_CLI_ASCII", "1")
+
+	docker := infra.NewFakeDocker(infra.ContainerInfo{ID: "a1", Name: "web", State: "running"})
+	model := NewModel(docker, llm.NewFakeProvider())
+	newModel, _ := model.Update(tea.WindowSizeMsg{Width: 120, Height: 40})
+	m := newModel.(Model)
+	m.state = StateMain
+
+	m.pipe.State().AddBlock(pipeline.Block{ID: "b1", Type: pipeline.BlockTypeCommand, Command: "make test", Output: "ok\n", Pinned: true})
+	m.pipe.State().AddBlock(pipeline.Block{ID: "b2", Type: pipeline.BlockTypeCommand, Command: "make lint", ExitCode: 2})
+	newModel, _ = m.Update(hostStatsMsg{stats: infra.HostStats{CPUPercent: 23, CPUs: 8, MemUsed: 4 << 30, MemTotal: 16 << 30}})
+	m = newModel.(Model)
+	m.containers = m.containers.SetServices(docker.Containers)
+	m.containers = m.containers.SetContainerStats("web", monitor.ContainerStats{CPUHistory: []int{10, 40, 80}, MemUsed: 1024, MemTotal: 16384})
+
+	for _, tab := range m.tabs() {
+		m.activeTab = tab
+		view := m.View()
+		for _, r := range ansi.Strip(view) {
+			if r > unicode.MaxASCII {
+				t.Errorf("expected only ASCII on tab %v, found %q in:\n%s", tab, r, view)
+				break
+			}
+		}
+	}
+	m.activeTab = TabAgent
+	if view := m.View(); !strings.Contains(view, "[pin]") || !strings.Contains(view, "+-") {
+		t.Errorf("expected labels and ASCII borders, got:\n%s", view)
+	}
+}
+
+func TestModel_CompactLayout(t *testing.T) {
+	docker := infra.NewFakeDocker(infra.ContainerInfo{ID: "a1", Name: "web", State: "running"})
+	model := NewModel(docker, llm.NewFakeProvider())
+	newModel, _ := model.Update(tea.WindowSizeMsg{Width: 70, Height: 30})
+	m := newModel.(Model)
+	m.state = StateMain
+	m.containers = m.containers.SetServices(docker.Containers)
+	press := func(k string) {
+		newModel, _ := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(k)})
+		m = newModel.(Model)
+	}
+
+	for _, tab := range m.tabs() {
+		m.activeTab = tab
+		view := m.View()
+		for _, line := range strings.Split(view, "\n") {
+			if w := lipgloss.Width(line); w > 70 {
+				t.Errorf("expected tab %v to fit 70 columns, got %d cells: %q", tab, w, line)
+			}
+		}
+	}
+
+	m.activeTab = TabHistory
+	if view := m.View(); !strings.Contains(view, "History") || !strings.Contains(view, "Details") || strings.Contains(view, "Containers") {
+		t.Errorf("expected the list stacked over the details and only the active tab named, got:\n%s", view)
+	}
+
+	// The Containers sidebar is a drawer over the logs.
+	m.activeTab = TabContainers
+	if view := m.View(); !strings.Contains(view, "Services") || strings.Contains(view, "Logs") {
+		t.Fatalf("expected the sidebar drawer to start open, got:\n%s", view)
+	}
+	press("S")
+	if view := m.View(); strings.Contains(view, "Services") || !strings.Contains(view, "Logs (web)") {
+		t.Errorf("expected S to close the drawer onto the logs, got:\n%s", view)
+	}
+	press("S")
+	if view := m.View(); !strings.Contains(view, "Services") || m.containers.Focus() != monitor.FocusServices {
+		t.Errorf("expected S to reopen the drawer on the Services panel, got:\n%s", view)
+	}
+
+	// Wide terminals keep the sidebar next to the logs.
+	newModel, _ = m.Update(tea.WindowSizeMsg{Width: 120, Height: 30})
+	m = newModel.(Model)
+	if view := m.View(); !strings.Contains(view, "Services") || !strings.Contains(view, "Logs") {
+		t.Errorf("expected the side-by-side layout back at 120 columns, got:\n%s", view)
+	}
+}
+
+func TestModel_CopyBlock(t *testing.T) {
+	var clipboard []string
+	oldWrite, oldOSC := writeClipboard, oscWriter
+	t.Cleanup(func() { writeClipboard, oscWriter = oldWrite, oldOSC })
+	writeClipboard = func(text string) error {
+		clipboard = append(clipboard, text)
+		return nil
+	}
+	var osc strings.Builder
+	oscWriter = &osc
+	t.Setenv("SSH_TTY", "")
+	t.Setenv("SSH_CONNECTION", "")
+	t.Setenv("TMUX", "")
+
+	model := NewModel(infra.NewFakeDocker(), llm.NewFakeProvider())
+	newModel, _ := model.Update(tea.WindowSizeMsg{Width: 120, Height: 40})
+	m := newModel.(Model)
+	m.state = StateMain
+	m.pipe.State().AddBlock(pipeline.Block{ID: "b1", Command: "go test ./...", Output: "ok  dev-cli\n", AISuggestion: "go test -race ./..."})
+
+	// feed applies msg and then the copy messages it leads to.
+	var feed func(m Model, msg tea.Msg) Model
+	feed = func(m Model, msg tea.Msg) Model {
+		newModel, cmd := m.Update(msg)
+		m = newModel.(Model)
+		for _, next := range runCmd(cmd) {
+			switch next.(type) {
+			case agent.CopyMsg, clipboardCopiedMsg:
+				m = feed(m, next)
+			}
+		}
+		return m
+	}
+	key := func(s string) tea.KeyMsg { return tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(s)} }
+
+	m = feed(m, key("k"))
+	for _, k := range []string{"y", "Y", "c"} {
+		m = feed(m, key(k))
+	}
+	if want := []string{"ok  dev-cli", "go test ./...", "go test -race ./..."}; !slices.Equal(clipboard, want) {
+		t.Errorf("expected y, Y and c to copy output, command and suggestion, got %q", clipboard)
+	}
+	if !strings.Contains(m.View(), "copied suggestion") {
+		t.Errorf("expected the copy to be confirmed, got:\n%s", m.View())
+	}
+
+	t.Setenv("SSH_TTY", "/dev/pts/3")
+	m = feed(m, key("y"))
+	if len(clipboard) != 3 || osc.String() != "\x1b]52;c;b2sgIGRldi1jbGk=\x07" {
+		t.Errorf("expected an SSH session to copy through OSC 52, got %q", osc.String())
+	}
+}
+
+func TestModel_RerunBlock(t *testing.T) {
+	model := NewModel(infra.NewFakeDocker(), llm.NewFakeProvider())
+	newModel, _ := model.Update(tea.WindowSizeMsg{Width: 120, Height: 40})
+	m := newModel.(Model)
+	m.state = StateMain
+	m.pipe.State().AddBlock(pipeline.Block{ID: "b1", Type: pipeline.BlockTypeCommand, Command: "echo again", ExitCode: 1})
+	m.pipe.State().AddBlock(pipeline.Block{ID: "b2", Type: pipeline.BlockTypeAI, Command: "why?", Output: "because"})
+
+	// feed applies msg and then the command it runs, if any.
+	feed := func(msg tea.Msg) {
+		newModel, cmd := m.Update(msg)
+		for _, next := range runCmd(cmd) {
+			if done, ok := next.(agent.CommandExecutedMsg); ok {
+				newModel, _ = newModel.Update(done)
+			}
+		}
+		m = newModel.(Model)
+	}
+	key := func(s string) tea.KeyMsg { return tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(s)} }
+
+	feed(key("k"))
+	feed(key("R"))
+	if len(m.agent.Blocks()) != 2 || !strings.Contains(m.View(), "no command to re-run") {
+		t.Fatalf("expected an AI block to have nothing to re-run, got:\n%s", m.View())
+	}
+
+	feed(key("k"))
+	feed(key("R"))
+	blocks := m.agent.Blocks()
+	if len(blocks) != 3 || blocks[2].Command != "echo again" || !strings.Contains(blocks[2].Output, "again") {
+		t.Fatalf("expected R to run the command again as a new block, got %+v", blocks)
+	}
+
+	feed(key("k"))
+	feed(key("k"))
+	feed(key("E"))
+	if !m.agent.InsertMode() || m.agent.InputValue() != "echo again" {
+		t.Fatalf("expected E to put the command on the input, got %q", m.agent.InputValue())
+	}
+	for _, r := range " edited" {
+		feed(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{r}})
+	}
+	feed(tea.KeyMsg{Type: tea.KeyEnter})
+	blocks = m.agent.Blocks()
+	if len(blocks) != 4 || blocks[3].Command != "echo again edited" || blocks[0].Command != "echo again" {
+		t.Errorf("expected the edited command to run as a new block, got %+v", blocks)
+	}
+}
+
+func TestModel_PinBlock(t *testing.T) {
+	db, err := storage.OpenDB(filepath.Join(t.TempDir(), "history.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	// session starts the UI on db; feed applies a message and the bookmark
+	// messages it leads to.
+	var m Model
+	feed := func(msg tea.Msg) {
+		var next func(msg tea.Msg)
+		next = func(msg tea.Msg) {
+			newModel, cmd := m.Update(msg)
+			m = newModel.(Model)
+			for _, msg := range runCmd(cmd) {
+				switch msg.(type) {
+				case bookmarksLoadedMsg, agent.BookmarkMsg, bookmarkSavedMsg, agent.UnbookmarkMsg:
+					next(msg)
+				}
+			}
+		}
+		next(msg)
+	}
+	session := func() {
+		m = NewModel(infra.NewFakeDocker(), llm.NewFakeProvider())
+		newModel, _ := m.Update(tea.WindowSizeMsg{Width: 120, Height: 40})
+		m = newModel.(Model)
+		m.state = StateMain
+		feed(historyLoadedMsg{db: db})
+	}
+	key := func(s string) tea.KeyMsg { return tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(s)} }
+
+	session()
+	m.pipe.State().AddBlock(pipeline.Block{ID: "b1", Type: pipeline.BlockTypeCommand, Command: "make migrate", Output: "applied 3 migrations\n"})
+	m.pipe.State().AddBlock(pipeline.Block{ID: "b2", Type: pipeline.BlockTypeCommand, Command: "ls"})
+	feed(key("k"))
+	feed(key("k"))
+	feed(key("P"))
+	if !strings.Contains(m.View(), "📌 ❯ make migrate ✓  applied 3 migrations") {
+		t.Errorf("expected the pinned block at the top of the blocks, got:\n%s", m.View())
+	}
+	bookmarks, _ := storage.GetBookmarks(db)
+	if len(bookmarks) != 1 || bookmarks[0].Command != "make migrate" || bookmarks[0].Output != "applied 3 migrations\n" {
+		t.Fatalf("expected the pinned block saved as a bookmark, got %+v", bookmarks)
+	}
+
+	feed(tea.KeyMsg{Type: tea.KeyCtrlL})
+	if blocks := m.agent.Blocks(); len(blocks) != 1 || blocks[0].ID != "b1" {
+		t.Errorf("expected Ctrl+L to keep the pinned block, got %+v", blocks)
+	}
+
+	// A later session brings it back, pinned.
+	session()
+	blocks := m.agent.Blocks()
+	if len(blocks) != 1 || blocks[0].Command != "make migrate" || !blocks[0].Pinned {
+		t.Fatalf("expected the bookmark back as a pinned block, got %+v", blocks)
+	}
+	feed(key("k"))
+	feed(key("P"))
+	if bookmarks, _ := storage.GetBookmarks(db); len(bookmarks) != 0 || m.agent.Blocks()[0].Pinned {
+		t.Errorf("expected P to unpin it and drop the bookmark, got %+v", bookmarks)
+	}
+}
+
+func TestModel_RecordsFixOutcome(t *testing.T) {
+	db, err := storage.OpenDB(filepath.Join(t.TempDir(), "history.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	model := NewModel(infra.NewFakeDocker(), llm.NewFakeProvider())
+	newModel, _ := model.Update(tea.WindowSizeMsg{Width: 120, Height: 40})
+	m := newModel.(Model)
+	m.state = StateMain
+	m.db = db
+	failed := pipeline.Block{ID: "b1", Type: pipeline.BlockTypeCommand, Command: "make build", Output: "missing go.sum entry\n", ExitCode: 2}
+	m.pipe.State().AddBlock(failed)
+
+	// fix runs suggestion as the fix for the selected failed block.
+	fix := func(suggestion string) {
+		m.pipe.State().UpdateBlock("b1", func(b *pipeline.Block) { b.AISuggestion = suggestion })
+		newModel, cmd := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("r")})
+		for _, msg := range runCmd(cmd) {
+			if done, ok := msg.(agent.CommandExecutedMsg); ok {
+				var next tea.Cmd
+				newModel, next = newModel.Update(done)
+				runCmd(next)
+			}
+		}
+		m = newModel.(Model)
+	}
+	// top selects the failed block.
+	top := func() {
+		for m.agent.SelectedBlock() != 0 {
+			newModel, _ := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("k")})
+			m = newModel.(Model)
+		}
+	}
+
+	top()
+	fix("true")
+	top()
+	fix("false")
+
+	signature := storage.GenerateErrorSignature(failed.Command, failed.ExitCode, failed.Output)
+	solutions, err := storage.GetSolutionsForError(db, signature, 5)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(solutions) != 2 || solutions[0].Command != "true" || solutions[0].Successes != 1 ||
+		solutions[1].Command != "false" || solutions[1].Failures != 1 {
+		t.Errorf("expected the fix outcomes counted for the error, got %+v", solutions)
+	}
+}
+
+func TestModel_MultilineEditor(t *testing.T) {
+	model := NewModel(infra.NewFakeDocker(), llm.NewFakeProvider())
+	newModel, _ := model.Update(tea.WindowSizeMsg{Width: 120, Height: 40})
+	m := newModel.(Model)
+	m.state = StateMain
+
+	// feed applies msg and then the command it runs, if any.
+	feed := func(msg tea.Msg) {
+		newModel, cmd := m.Update(msg)
+		for _, next := range runCmd(cmd) {
+			if done, ok := next.(agent.CommandExecutedMsg); ok {
+				newModel, _ = newModel.Update(done)
+			}
+		}
+		m = newModel.(Model)
+	}
+	typeText := func(s string) {
+		for _, r := range s {
+			feed(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{r}})
+		}
+	}
+	enter := tea.KeyMsg{Type: tea.KeyEnter}
+
+	feed(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("i")})
+	typeText("cat <<EOF")
+	feed(tea.KeyMsg{Type: tea.KeyCtrlE})
+	if !m.agent.EditorOpen() || m.agent.InputValue() != "" {
+		t.Fatalf("expected Ctrl+E to move the input into the editor, got %q", m.agent.InputValue())
+	}
+	feed(enter)
+	typeText("hello")
+	feed(enter)
+	typeText("EOF")
+	if view := m.View(); !strings.Contains(view, " 3 EOF") || !strings.Contains(view, "3 lines • Ctrl+s run") {
+		t.Errorf("expected the editor to show the three lines, got:\n%s", view)
+	}
+
+	// Esc goes back to the input line and keeps several lines as a draft.
+	feed(tea.KeyMsg{Type: tea.KeyEsc})
+	if m.agent.EditorOpen() || !m.agent.InsertMode() || len(m.agent.Blocks()) != 0 {
+		t.Fatal("expected Esc to close the editor without running anything")
+	}
+	feed(tea.KeyMsg{Type: tea.KeyCtrlE})
+	feed(tea.KeyMsg{Type: tea.KeyCtrlS})
+	blocks := m.agent.Blocks()
+	if m.agent.EditorOpen() || len(blocks) != 1 || blocks[0].Command != "cat <<EOF\nhello\nEOF" || !strings.HasSuffix(strings.TrimSpace(blocks[0].Output), "hello") {
+		t.Errorf("expected Ctrl+S to run the heredoc as one block, got %+v", blocks)
+	}
+}
+
+func TestModel_AgentScrollback(t *testing.T) {
+	model := NewModel(infra.NewFakeDocker(), llm.NewFakeProvider())
+	newModel, _ := model.Update(tea.WindowSizeMsg{Width: 100, Height: 24})
+	m := newModel.(Model)
+	m.state = StateMain
+	for i := range 20 {
+		m.pipe.State().AddBlock(pipeline.Block{ID: fmt.Sprintf("b%d", i), Command: fmt.Sprintf("echo step-%02d", i), Output: "done"})
+	}
+
+	send := func(msg tea.KeyMsg) {
+		newModel, _ := m.Update(msg)
+		m = newModel.(Model)
+	}
+	key := func(s string) tea.KeyMsg { return tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(s)} }
+
+	if view := m.View(); !strings.Contains(view, "step-19") || strings.Contains(view, "step-00") {
+		t.Fatalf("expected the newest block at the bottom, got:\n%s", view)
+	}
+
+	send(tea.KeyMsg{Type: tea.KeyPgUp})
+	if view := m.View(); strings.Contains(view, "step-19") {
+		t.Errorf("expected PgUp to scroll back, got:\n%s", view)
+	}
+	m.pipe.State().AddBlock(pipeline.Block{ID: "b20", Command: "echo step-20", Output: "done"})
+	if view := m.View(); strings.Contains(view, "step-20") {
+		t.Errorf("expected new output not to yank the scrolled-back view, got:\n%s", view)
+	}
+
+	send(key("g"))
+	send(key("g"))
+	if view := m.View(); !strings.Contains(view, "step-00") {
+		t.Errorf("expected gg to scroll to the first block, got:\n%s", view)
+	}
+	if m.agent.SelectedBlock() != -1 {
+		t.Errorf("expected scrolling to leave the selection alone, got %d", m.agent.SelectedBlock())
+	}
+
+	send(key("G"))
+	if view := m.View(); !strings.Contains(view, "step-20") {
+		t.Errorf("expected G to go back to the newest block, got:\n%s", view)
+	}
+	m.pipe.State().AddBlock(pipeline.Block{ID: "b21", Command: "echo step-21", Output: "done"})
+	if view := m.View(); !strings.Contains(view, "step-21") {
+		t.Errorf("expected the view to follow new output at the bottom, got:\n%s", view)
+	}
+}
+
+func TestModel_AgentFind(t *testing.T) {
+	model := NewModel(infra.NewFakeDocker(), llm.NewFakeProvider())
+	newModel, _ := model.Update(tea.WindowSizeMsg{Width: 100, Height: 24})
+	m := newModel.(Model)
+	m.state = StateMain
+	for i := range 20 {
+		output := "ok"
+		if i == 2 || i == 17 {
+			output = "panic: Needle in the haystack"
+		}
+		m.pipe.State().AddBlock(pipeline.Block{ID: fmt.Sprintf("b%d", i), Command: fmt.Sprintf("echo step-%02d", i), Output: output})
+	}
+
+	send := func(msg tea.KeyMsg) {
+		newModel, _ := m.Update(msg)
+		m = newModel.(Model)
+	}
+	key := func(s string) tea.KeyMsg { return tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(s)} }
+
+	send(key("/"))
+	if !m.agent.FindOpen() || m.mode != ModeInsert {
+		t.Fatal("expected / to open the find prompt")
+	}
+	send(key("needle"))
+	if view := m.View(); !strings.Contains(view, "2 matches") {
+		t.Errorf("expected the matches to be counted as the query is typed, got:\n%s", view)
+	}
+
+	send(tea.KeyMsg{Type: tea.KeyEnter})
+	if m.agent.FindOpen() || m.mode != ModeNormal {
+		t.Fatal("expected Enter to close the prompt and keep the matches")
+	}
+	if view := m.View(); !strings.Contains(view, "2/2") || !strings.Contains(view, "step-17") {
+		t.Errorf("expected Enter to jump to the newest match, got:\n%s", view)
+	}
+
+	send(key("N"))
+	if view := m.View(); !strings.Contains(view, "1/2") || !strings.Contains(view, "step-02") || strings.Contains(view, "step-17") {
+		t.Errorf("expected N to jump up to the older match, got:\n%s", view)
+	}
+	send(key("n"))
+	if view := m.View(); !strings.Contains(view, "2/2") || !strings.Contains(view, "step-17") {
+		t.Errorf("expected n to jump back down, got:\n%s", view)
+	}
+
+	send(tea.KeyMsg{Type: tea.KeyEsc})
+	if view := m.View(); strings.Contains(view, "2/2") {
+		t.Errorf("expected Esc to clear the find, got:\n%s", view)
+	}
+}
+
+func TestModel_CtrlCCancelsCommand(t *testing.T) {
+	model := NewModel(infra.NewFakeDocker(), llm.NewFakeProvider())
+	newModel, _ := model.Update(tea.WindowSizeMsg{Width: 120, Height: 40})
+	m := newModel.(Model)
+	m.state = StateMain
+	m.pipe.State().AddBlock(pipeline.Block{ID: "b1", Command: "npm install", Output: "added 12 packages", Running: true})
+
+	ctrlC := tea.KeyMsg{Type: tea.KeyCtrlC}
+	newModel, _ = m.Update(ctrlC)
+	m = newModel.(Model)
+	if m.quitting {
+		t.Fatal("expected Ctrl+C to interrupt the running command, not quit")
+	}
+	if view := m.View(); !strings.Contains(view, "running") {
+		t.Errorf("expected the running block to show its elapsed time, got:\n%s", view)
+	}
+
+	m.pipe.State().UpdateBlock("b1", func(b *pipeline.Block) {
+		b.Running, b.ExitCode = false, 130
+	})
+	if view := m.View(); !strings.Contains(view, "✗ 130 interrupted") {
+		t.Errorf("expected the block to read as interrupted, got:\n%s", view)
+	}
+	newModel, _ = m.Update(ctrlC)
+	if !newModel.(Model).quitting {
+		t.Error("expected Ctrl+C to quit once nothing runs")
+	}
+}
+
+func TestModel_CollectJobs(t *testing.T) {
+	model := NewModel(infra.NewFakeDocker(), llm.NewFakeProvider())
+	newModel, _ := model.Update(tea.WindowSizeMsg{Width: 120, Height: 40})
+	m := newModel.(Model)
+	m.state = StateMain
+	m.pipe.State().AddJob(pipeline.Job{ID: "j1", Command: "npm run dev", Running: true})
+	m.pipe.State().AddJob(pipeline.Job{ID: "j2", Command: "make build", Output: "built ok"})
+
+	if view := m.View(); !strings.Contains(view, "jobs ◌1 ✓1") {
+		t.Errorf("expected the jobs widget to count a running and a finished job, got:\n%s", view)
+	}
+
+	newModel, _ = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("b")})
+	m = newModel.(Model)
+	blocks := m.pipe.State().GetBlocks()
+	if len(blocks) != 1 || blocks[0].Command != "make build" || blocks[0].Output != "built ok" {
+		t.Fatalf("expected b to bring the finished job back as a block, got %+v", blocks)
+	}
+	if jobs := m.pipe.State().GetJobs(); len(jobs) != 1 || jobs[0].ID != "j1" {
+		t.Errorf("expected the running job to stay in the background, got %+v", jobs)
+	}
+	if view := m.View(); !strings.Contains(view, "jobs ◌1") || strings.Contains(view, "✓1") {
+		t.Errorf("expected only the running job in the widget, got:\n%s", view)
+	}
+}
+
+func TestModel_InteractiveCommand(t *testing.T) {
+	model := NewModel(infra.NewFakeDocker(), llm.NewFakeProvider())
+	newModel, _ := model.Update(tea.WindowSizeMsg{Width: 120, Height: 40})
+	m := newModel.(Model)
+	m.state = StateMain
+
+	newModel, _ = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("i")})
+	m = newModel.(Model)
+	newModel, _ = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("vim notes.md")})
+	m = newModel.(Model)
+	newModel, cmd := m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	m = newModel.(Model)
+
+	var interactive *agent.InteractiveMsg
+	for _, msg := range runCmd(cmd) {
+		if msg, ok := msg.(agent.InteractiveMsg); ok {
+			interactive = &msg
+		}
+	}
+	if interactive == nil || interactive.Command != "vim notes.md" {
+		t.Fatalf("expected vim to be handed the terminal, got %+v", interactive)
+	}
+	if len(m.pipe.State().GetBlocks()) != 0 {
+		t.Error("expected no block while vim owns the terminal")
+	}
+	if _, cmd = m.Update(*interactive); cmd == nil {
+		t.Fatal("expected a command that suspends the TUI")
+	}
+
+	newModel, _ = m.Update(interactiveDoneMsg{result: executor.Result{Command: "vim notes.md", Output: "\"notes.md\" 3L written", Timestamp: time.Now()}})
+	m = newModel.(Model)
+	blocks := m.pipe.State().GetBlocks()
+	if len(blocks) != 1 || blocks[0].Command != "vim notes.md" || blocks[0].Output != "\"notes.md\" 3L written" {
+		t.Fatalf("expected the session recorded as a block, got %+v", blocks)
+	}
+	if m.agent.IsExecuting() {
+		t.Error("expected the agent to be idle once the terminal is back")
+	}
+}
+
+func TestModel_CommandPalette(t *testing.T) {
+	docker := infra.NewFakeDocker(infra.ContainerInfo{ID: "a1", Name: "web", State: "running"})
+	model := NewModel(docker, llm.NewFakeProvider())
+	newModel, _ := model.Update(tea.WindowSizeMsg{Width: 120, Height: 40})
+	newModel, _ = newModel.Update(model.checkDockerHealth())
+	m := newModel.(Model)
+
+	newModel, _ = m.Update(tea.KeyMsg{Type: tea.KeyCtrlP})
+	m = newModel.(Model)
+	if m.palette == nil || m.mode != ModeInsert {
+		t.Fatal("expected Ctrl+P to open the palette for typing")
+	}
+	newModel, _ = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("stop web")})
+	m = newModel.(Model)
+	if view := m.View(); !strings.Contains(view, "Containers Stop web") {
+		t.Fatalf("expected the palette to offer stopping web, got:\n%s", view)
+	}
+
+	newModel, cmd := m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	m = newModel.(Model)
+	if m.palette != nil {
+		t.Error("expected Enter to close the palette")
+	}
+	for _, msg := range runCmd(cmd) {
+		newModel, cmd = m.Update(msg)
+		m = newModel.(Model)
+		for _, msg := range runCmd(cmd) {
+			if done, ok := msg.(containerActionDoneMsg); ok {
+				newModel, _ = m.Update(done)
+				m = newModel.(Model)
+			}
+		}
+	}
+	if state := docker.Containers[0].State; state != "exited" {
+		t.Fatalf("expected web to be stopped, got %q", state)
+	}
+
+	newModel, _ = m.Update(tea.KeyMsg{Type: tea.KeyCtrlP})
+	m = newModel.(Model)
+	newModel, _ = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("go history")})
+	m = newModel.(Model)
+	newModel, _ = m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	m = newModel.(Model)
+	if m.activeTab != TabHistory {
+		t.Errorf("expected the palette to switch to History, got tab %d", m.activeTab)
+	}
+
+	newModel, _ = m.Update(tea.KeyMsg{Type: tea.KeyCtrlP})
+	m = newModel.(Model)
+	newModel, _ = m.Update(tea.KeyMsg{Type: tea.KeyEsc})
+	m = newModel.(Model)
+	if m.palette != nil || m.mode != ModeNormal {
+		t.Error("expected Esc to close the palette")
+	}
+}
+
+func TestModel_HistoryFilters(t *testing.T) {
+	db, err := storage.OpenDB(filepath.Join(t.TempDir(), "history.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	now := time.Now()
+	for _, e := range []storage.LogEntry{
+		{Command: "make old", Cwd: "/srv/api", Timestamp: now.Add(-48 * time.Hour).Format(time.RFC3339)},
+		{Command: "go build", Cwd: "/srv/api", Timestamp: now.Add(-2 * time.Hour).Format(time.RFC3339)},
+		{Command: "go test", ExitCode: 1, Cwd: "/srv/api", Timestamp: now.Add(-30 * time.Minute).Format(time.RFC3339)},
+		{Command: "npm test", ExitCode: 1, Cwd: "/srv/web", Timestamp: now.Add(-5 * time.Minute).Format(time.RFC3339)},
+	} {
+		if err := storage.SaveCommand(db, e); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	model := NewModel(infra.NewFakeDocker(), llm.NewFakeProvider())
+	newModel, _ := model.Update(tea.WindowSizeMsg{Width: 120, Height: 40})
+	items, _ := storage.QueryHistory(db, storage.HistoryFilter{})
+	newModel, _ = newModel.Update(historyLoadedMsg{db: db, history: items})
+	m := newModel.(Model)
+	m.state = StateMain
+	m.activeTab = TabHistory
+
+	press := func(k string) {
+		t.Helper()
+		newModel, cmd := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(k)})
+		m = newModel.(Model)
+		for _, msg := range runCmd(cmd) {
+			if msg, ok := msg.(history.FilterMsg); ok {
+				newModel, cmd = m.Update(msg)
+				m = newModel.(Model)
+				for _, msg := range runCmd(cmd) {
+					newModel, _ = m.Update(msg)
+					m = newModel.(Model)
+				}
+			}
+		}
+	}
+	commands := func() []string {
+		var commands []string
+		for _, item := range m.history.History() {
+			commands = append(commands, item.Command)
+		}
+		return commands
+	}
+
+	press("e")
+	if got := commands(); !slices.Equal(got, []string{"npm test", "go test"}) {
+		t.Fatalf("expected only failed commands, got %v", got)
+	}
+	if !strings.Contains(m.View(), "✕ failed") {
+		t.Error("expected the filter bar to show the exit code filter")
+	}
+
+	// The selected command is npm test, run in /srv/web.
+	press("e")
+	press("e")
+	press("d")
+	if got := commands(); !slices.Equal(got, []string{"npm test"}) {
+		t.Fatalf("expected the selected command's directory, got %v", got)
+	}
+	press("d")
+	press("t")
+	press("t")
+	if got := commands(); !slices.Equal(got, []string{"npm test", "go test", "go build"}) {
+		t.Fatalf("expected the last 24h, got %v", got)
+	}
+	if !strings.Contains(m.View(), "last 24h") {
+		t.Error("expected the filter bar to show the time range")
+	}
+
+	// None of the commands ran in the project the tests run in.
+	press("p")
+	cwd, _ := os.Getwd()
+	if got := commands(); len(got) != 0 {
+		t.Fatalf("expected nothing from this project, got %v", got)
+	}
+	if root := storage.ProjectRoot(cwd); m.history.Filter().Project != root || !strings.Contains(m.View(), "project "+filepath.Base(root)) {
+		t.Errorf("expected the project filter on %s, got %+v", root, m.history.Filter())
+	}
+
+	press("c")
+	if got := commands(); len(got) != 4 || m.history.Filtered() {
+		t.Fatalf("expected clearing to list everything again, got %v", got)
+	}
+	if strings.Contains(m.View(), "⧩") {
+		t.Error("expected no filter bar without filters")
+	}
+}
+
+func TestModel_HistoryStats(t *testing.T) {
+	db, err := storage.OpenDB(filepath.Join(t.TempDir(), "history.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	for _, e := range []storage.LogEntry{
+		{Command: "go test ./...", ExitCode: 1, DurationMs: 2000},
+		{Command: "go test ./...", DurationMs: 4000},
+		{Command: "go test ./...", ExitCode: 1, DurationMs: 3000},
+		{Command: "git status", DurationMs: 20},
+	} {
+		if err := storage.SaveCommand(db, e); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	model := NewModel(infra.NewFakeDocker(), llm.NewFakeProvider())
+	newModel, _ := model.Update(tea.WindowSizeMsg{Width: 120, Height: 40})
+	newModel, _ = newModel.Update(historyLoadedMsg{db: db})
+	m := newModel.(Model)
+	m.state = StateMain
+	m.activeTab = TabHistory
+
+	press := func(k string) {
+		t.Helper()
+		newModel, cmd := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(k)})
+		m = newModel.(Model)
+		for _, msg := range runCmd(cmd) {
+			newModel, cmd = m.Update(msg)
+			m = newModel.(Model)
+			for _, msg := range runCmd(cmd) {
+				newModel, _ = m.Update(msg)
+				m = newModel.(Model)
+			}
+		}
+	}
+
+	press("v")
+	if !m.history.ShowingStats() {
+		t.Fatal("expected v to open the Stats view")
+	}
+	view := m.View()
+	for _, want := range []string{"4 commands · 2 failed", "3× go test ./...", "2/3", "3s  go test ./..."} {
+		if !strings.Contains(view, want) {
+			t.Errorf("expected %q in the Stats view, got:\n%s", want, view)
+		}
+	}
+
+	// Filters apply to the stats too.
+	press("e")
+	if view := m.View(); !strings.Contains(view, "2 commands · 2 failed") {
+		t.Errorf("expected stats of the failed commands only, got:\n%s", view)
+	}
+
+	press("v")
+	if m.history.ShowingStats() || !strings.Contains(m.View(), "Details") {
+		t.Error("expected v to go back to the list")
+	}
+}
+
+func TestModel_HistorySessions(t *testing.T) {
+	db, err := storage.OpenDB(filepath.Join(t.TempDir(), "history.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	model := NewModel(infra.NewFakeDocker(), llm.NewFakeProvider())
+	newModel, _ := model.Update(tea.WindowSizeMsg{Width: 120, Height: 40})
+	m := newModel.(Model)
+	m.state = StateMain
+	update := func(msg tea.Msg) {
+		t.Helper()
+		newModel, cmd := m.Update(msg)
+		m = newModel.(Model)
+		for _, msg := range runCmd(cmd) {
+			newModel, cmd = m.Update(msg)
+			m = newModel.(Model)
+			for _, msg := range runCmd(cmd) {
+				newModel, _ = m.Update(msg)
+				m = newModel.(Model)
+			}
+		}
+	}
+	update(historyLoadedMsg{db: db})
+
+	// A command the Agent runs is recorded under this launch's session.
+	m.pipe.State().AddBlock(pipeline.Block{
+		ID: "b1", Type: pipeline.BlockTypeCommand, Command: "make build", Output: "boom",
+		ExitCode: 2, Timestamp: time.Now(), WorkingDir: "/srv/api",
+	})
+	update(agent.CommandExecutedMsg{BlockID: "b1"})
+	sessions, err := storage.ListSessions(db, 10)
+	if err != nil || len(sessions) != 1 {
+		t.Fatalf("sessions = %+v, %v; want this launch's", sessions, err)
+	}
+	if s := sessions[0]; s.ID != m.session.ID || s.Source != "tui" || s.Commands != 1 || s.Failed != 1 {
+		t.Errorf("session = %+v", s)
+	}
+
+	m.activeTab = TabHistory
+	update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("S")})
+	if !m.history.ShowingSessions() {
+		t.Fatal("expected S to open the Sessions view")
+	}
+	if view := m.View(); !strings.Contains(view, "Sessions") || !strings.Contains(view, "1 command · 1 failed") {
+		t.Errorf("expected the session in the Sessions view, got:\n%s", view)
+	}
+
+	// R replays its commands in the Agent, folded.
+	update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("R")})
+	if m.activeTab != TabAgent {
+		t.Fatalf("expected the replay to show the Agent, got tab %v", m.activeTab)
+	}
+	var replayed *pipeline.Block
+	for _, b := range m.agent.Blocks() {
+		if strings.HasPrefix(b.ID, "history-") {
+			replayed = &b
+		}
+	}
+	if replayed == nil || replayed.Command != "make build" || replayed.Output != "boom" || !replayed.Folded {
+		t.Fatalf("replayed block = %+v", replayed)
+	}
+
+	// Back in History, the Sessions view is still open; Enter lists the
+	// session's commands.
+	m.activeTab = TabHistory
+	m.mode = m.getModeFromTab()
+	update(tea.KeyMsg{Type: tea.KeyEnter})
+	if m.history.ShowingSessions() || m.history.Filter().SessionID != m.session.ID || m.history.HistoryCount() != 1 {
+		t.Errorf("expected Enter to list the session's commands, filter %+v, %d items", m.history.Filter(), m.history.HistoryCount())
+	}
+
+	m.endSession()
+	if sessions, _ := storage.ListSessions(db, 10); len(sessions) != 1 || sessions[0].EndedAt.IsZero() {
+		t.Errorf("expected the session to end, got %+v", sessions)
+	}
+}
+
+func TestModel_DraftWorkflow(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+
+	model := NewModel(infra.NewFakeDocker(), llm.NewFakeProvider())
+	newModel, _ := model.Update(tea.WindowSizeMsg{Width: 120, Height: 40})
+	m := newModel.(Model)
+	m.state = StateMain
+	var update func(msg tea.Msg)
+	update = func(msg tea.Msg) {
+		newModel, cmd := m.Update(msg)
+		m = newModel.(Model)
+		for _, msg := range runCmd(cmd) {
+			switch msg.(type) {
+			case history.DraftWorkflowMsg, agent.DraftWorkflowMsg, workflowDraftedMsg:
+				update(msg)
+			}
+		}
+	}
+	key := func(s string) tea.KeyMsg { return tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(s)} }
+	drafted := func() *workflow.Workflow {
+		t.Helper()
+		files, _ := filepath.Glob(filepath.Join(home, ".devlogs", "workflows", "*.yaml"))
+		if len(files) != 1 {
+			t.Fatalf("expected one drafted workflow, got %v", files)
+		}
+		defer os.Remove(files[0])
+		wf, err := workflow.ParseFile(files[0])
+		if err != nil {
+			t.Fatalf("drafted workflow does not parse: %v", err)
+		}
+		return wf
+	}
+	commands := func(wf *workflow.Workflow) string {
+		var got []string
+		for _, step := range wf.Steps {
+			got = append(got, step.Command)
+		}
+		return strings.Join(got, "; ")
+	}
+
+	// In History, m marks commands that succeeded, and W drafts them
+	// oldest first.
+	now := time.Now()
+	m.history = m.history.SetHistory([]storage.HistoryItem{
+		{ID: 3, Command: "kubectl rollout restart deploy/api", Timestamp: now, Directory: "/srv/api"},
+		{ID: 2, Command: "make deploy", ExitCode: 2, Timestamp: now.Add(-time.Minute), Directory: "/srv/api"},
+		{ID: 1, Command: "git pull", Timestamp: now.Add(-2 * time.Minute), Directory: "/srv/api"},
+	})
+	m.activeTab = TabHistory
+	m.mode = m.getModeFromTab()
+	for _, k := range []string{"m", "j", "m", "j", "m"} {
+		update(key(k))
+	}
+	if m.history.MarkedCount() != 2 || !strings.Contains(m.View(), "2 marked") {
+		t.Fatalf("expected the two successful commands marked, got %d", m.history.MarkedCount())
+	}
+	update(key("W"))
+	if got := commands(drafted()); got != "git pull; kubectl rollout restart deploy/api" {
+		t.Errorf("drafted steps = %q", got)
+	}
+	log := m.notifications.Log()
+	if m.history.MarkedCount() != 0 || len(log) != 1 || !strings.HasPrefix(log[0].Text, "Drafted a 2-step workflow") {
+		t.Errorf("expected the marks cleared and the draft reported, got %d marked, %+v", m.history.MarkedCount(), log)
+	}
+
+	// In the Agent, failed blocks can't be marked.
+	m.activeTab = TabAgent
+	m.mode = m.getModeFromTab()
+	m.pipe.State().AddBlock(pipeline.Block{ID: "b1", Type: pipeline.BlockTypeCommand, Command: "docker compose up -d", WorkingDir: "/srv/api"})
+	m.pipe.State().AddBlock(pipeline.Block{ID: "b2", Type: pipeline.BlockTypeCommand, Command: "curl -f localhost:8080", ExitCode: 7, WorkingDir: "/srv/api"})
+	update(key("k"))
+	update(key("m"))
+	if !strings.Contains(m.View(), "only commands that succeeded") {
+		t.Errorf("expected marking a failed block to be refused, got:\n%s", m.View())
+	}
+	update(key("k"))
+	update(key("m"))
+	if !strings.Contains(m.View(), "◆ marked") {
+		t.Errorf("expected the block shown as marked, got:\n%s", m.View())
+	}
+	update(key("W"))
+	if got := commands(drafted()); got != "docker compose up -d" {
+		t.Errorf("drafted steps = %q", got)
+	}
+}
+
+func TestModel_WorkflowRun(t *testing.T) {
+	t.Setenv("SHELL", "/bin/sh")
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	dir := filepath.Join(home, ".devlogs", "workflows")
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "ship.yaml"), []byte(`
+name: Ship
+steps:
+  - id: build
+    name: Build
+    command: echo compiling; echo built
+  - id: test
+    name: Test
+    command: echo broken test >&2; exit 3
+`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	model := NewModel(infra.NewFakeDocker(), llm.NewFakeProvider())
+	newModel, _ := model.Update(tea.WindowSizeMsg{Width: 120, Height: 40})
+	m := newModel.(Model)
+	m.state = StateMain
+	key := func(s string) tea.KeyMsg { return tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(s)} }
+
+	// The palette runs the workflow in the TUI, in an overlay that follows
+	// its steps as they start and end.
+	i := slices.IndexFunc(m.paletteActions(), func(a paletteAction) bool { return a.title == "Run workflow ship" })
+	if i < 0 {
+		t.Fatal("expected the palette to offer the workflow")
+	}
+	m, cmd := m.paletteActions()[i].run(m)
+	if !m.workflowOpen || cmd == nil {
+		t.Fatal("expected the run to start in the overlay")
+	}
+	var sawRunning bool
+	msg := cmd()
+	for {
+		progress, ok := msg.(workflowProgressMsg)
+		if !ok {
+			t.Fatalf("expected run progress, got %T", msg)
+		}
+		newModel, _ = m.Update(progress)
+		m = newModel.(Model)
+		if progress.done {
+			break
+		}
+		if progress.step != nil && progress.step.Status == workflow.StepRunning && progress.stepID == "build" {
+			view := ansi.Strip(m.View())
+			sawRunning = strings.Contains(view, "⚙ Ship") && strings.Contains(view, "running")
+		}
+		msg = waitWorkflow(progress.ch)()
+	}
+	if !sawRunning {
+		t.Error("expected the overlay to show the run while its first step ran")
+	}
+
+	if log := m.notifications.Log(); len(log) != 1 || !strings.HasPrefix(log[0].Text, "Workflow Ship failed") {
+		t.Errorf("expected the failed run reported, got %+v", log)
+	}
+	m.notifications = m.notifications.Clear()
+
+	// The last step to start is selected, with its output.
+	view := ansi.Strip(m.View())
+	for _, want := range []string{"✗ failed after", "✓ Build", "✗ Test", "exit 3", "broken test", "step failed with exit code 3"} {
+		if !strings.Contains(view, want) {
+			t.Errorf("expected %q in the finished run, got:\n%s", want, view)
+		}
+	}
+
+	// ↑ picks the first step and shows what it wrote.
+	newModel, _ = m.Update(key("k"))
+	m = newModel.(Model)
+	if view := ansi.Strip(m.View()); !strings.Contains(view, "compiling") || strings.Contains(view, "broken test") {
+		t.Errorf("expected the build step's output, got:\n%s", view)
+	}
+
+	// Esc hides the overlay, and the palette brings it back.
+	newModel, _ = m.Update(tea.KeyMsg{Type: tea.KeyEsc})
+	m = newModel.(Model)
+	if m.workflowOpen || strings.Contains(m.View(), "⚙ Ship") {
+		t.Fatal("expected Esc to hide the overlay")
+	}
+	i = slices.IndexFunc(m.paletteActions(), func(a paletteAction) bool { return a.title == "Show the run of workflow Ship" })
+	if i < 0 {
+		t.Fatal("expected the palette to offer the finished run")
+	}
+	m, _ = m.paletteActions()[i].run(m)
+	if !m.workflowOpen {
+		t.Error("expected the overlay shown again")
+	}
+}
+
+func TestModel_QuitPausesRuns(t *testing.T) {
+	t.Setenv("SHELL", "/bin/sh")
+	db, err := storage.OpenDB(filepath.Join(t.TempDir(), "history.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	wfPath := filepath.Join(t.TempDir(), "soak.yaml")
+	if err := os.WriteFile(wfPath, []byte(`
+name: Soak
+steps:
+  - id: wait
+    command: exec sleep 30
+`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	ctrlC := tea.KeyMsg{Type: tea.KeyCtrlC}
+
+	newModel, _ := NewModel(infra.NewFakeDocker(), llm.NewFakeProvider()).Update(historyLoadedMsg{db: db})
+	m := newModel.(Model)
+	m.state = StateMain
+
+	// Quitting during a workflow run pauses it and waits for the
+	// checkpoint before the program ends.
+	m, cmd := runWorkflowFile(wfPath)(m)
+	progress, ok := cmd().(workflowProgressMsg)
+	if !ok {
+		t.Fatal("expected the run to report progress")
+	}
+	newModel, _ = m.Update(progress)
+	newModel, cmd = newModel.Update(ctrlC)
+	m = newModel.(Model)
+	if m.quitting {
+		t.Fatal("expected the quit to wait for the run to pause")
+	}
+	var result *workflow.RunResult
+	for {
+		progress = waitWorkflow(progress.ch)().(workflowProgressMsg)
+		newModel, cmd = m.Update(progress)
+		m = newModel.(Model)
+		if progress.done {
+			result = progress.result
+			break
+		}
+	}
+	if !m.quitting || cmd == nil {
+		t.Fatal("expected the quit once the run paused")
+	}
+	if _, ok := cmd().(tea.QuitMsg); !ok {
+		t.Error("expected the paused run to end the program")
+	}
+	state, err := workflow.NewCheckpointStore(db).LoadRun(result.RunID)
+	if err != nil || state.Status != workflow.StatusPaused {
+		t.Errorf("expected a resumable checkpoint, got %+v, %v", state, err)
+	}
+
+	// Likewise for a runbook waiting on a step's confirmation.
+	newModel, _ = NewModel(infra.NewFakeDocker(), llm.NewFakeProvider()).Update(historyLoadedMsg{db: db})
+	m = newModel.(Model)
+	m.state = StateMain
+	newModel, cmd = m.Update(runbooks.RunMsg{Runbook: storage.Runbook{ID: "rb-quit", Name: "Quit", Steps: []storage.RunbookStep{{ID: "one", Command: "true"}}}})
+	rbProgress, ok := cmd().(runbookProgressMsg)
+	if !ok || rbProgress.asking == "" {
+		t.Fatalf("expected the runbook to ask about its step, got %+v", rbProgress)
+	}
+	newModel, _ = newModel.Update(rbProgress)
+	newModel, _ = newModel.Update(ctrlC)
+	m = newModel.(Model)
+	for !m.quitting {
+		rbProgress = waitRunbook(rbProgress.ch)().(runbookProgressMsg)
+		newModel, cmd = m.Update(rbProgress)
+		m = newModel.(Model)
+		if rbProgress.done {
+			break
+		}
+	}
+	if !m.quitting || rbProgress.result == nil || rbProgress.result.Status != workflow.StatusPaused {
+		t.Errorf("expected the runbook paused before the quit, got %+v", rbProgress.result)
+	}
+}
+
+func TestModel_Runbooks(t *testing.T) {
+	t.Setenv("SHELL", "/bin/sh")
+	db, err := storage.OpenDB(filepath.Join(t.TempDir(), "history.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	if err := storage.SaveRunbook(db, storage.Runbook{
+		ID:        "rb-deps",
+		ProjectID: "proj-nodejs",
+		Name:      "Fix dependencies",
+		Steps: []storage.RunbookStep{
+			{ID: "clean", Name: "Clean", Command: "echo cleaning"},
+			{ID: "install", Name: "Install", Command: "echo installing"},
+		},
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	model := NewModel(infra.NewFakeDocker(), llm.NewFakeProvider())
+	newModel, _ := model.Update(tea.WindowSizeMsg{Width: 120, Height: 40})
+	newModel, _ = newModel.Update(historyLoadedMsg{db: db})
+	newModel, _ = newModel.Update(loadRunbooks(db)())
+	m := newModel.(Model)
+	m.state = StateMain
+
+	// pending is the wait for the run's next message, held back while a
+	// step waits for an answer.
+	var pending tea.Cmd
+	var pump func(cmd tea.Cmd)
+	pump = func(cmd tea.Cmd) {
+		for _, msg := range runCmd(cmd) {
+			newModel, next := m.Update(msg)
+			m = newModel.(Model)
+			if p, ok := msg.(runbookProgressMsg); ok && p.asking != "" {
+				pending = next
+				continue
+			}
+			pump(next)
+		}
+	}
+	press := func(msg tea.KeyMsg) {
+		t.Helper()
+		newModel, cmd := m.Update(msg)
+		m = newModel.(Model)
+		pump(cmd)
+	}
+	typeText := func(s string) {
+		for _, r := range s {
+			press(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{r}})
+		}
+	}
+	keys := func(k string) tea.KeyMsg { return tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(k)} }
+
+	press(keys("4"))
+	if m.activeTab != TabRunbooks {
+		t.Fatalf("expected 4 to open the Runbooks tab without a kube context, got %v", m.activeTab)
+	}
+	view := m.View()
+	for _, want := range []string{"Runbooks", "proj-nodejs", "Fix dependencies", "echo cleaning", "never run"} {
+		if !strings.Contains(view, want) {
+			t.Errorf("expected %q in the Runbooks tab, got:\n%s", want, view)
+		}
+	}
+
+	// Edit the first step inline and add one after it.
+	press(tea.KeyMsg{Type: tea.KeyEnter})
+	press(keys("e"))
+	if m.mode != ModeInsert {
+		t.Fatal("expected editing a step to switch to insert mode")
+	}
+	press(tea.KeyMsg{Type: tea.KeyCtrlU})
+	typeText("echo cleaned")
+	press(tea.KeyMsg{Type: tea.KeyEnter})
+	press(keys("a"))
+	typeText("echo checking")
+	press(tea.KeyMsg{Type: tea.KeyEnter})
+
+	rb, err := storage.GetRunbookByID(db, "rb-deps")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var commands []string
+	for _, s := range rb.Steps {
+		commands = append(commands, s.Command)
+	}
+	if want := []string{"echo cleaned", "echo checking", "echo installing"}; !slices.Equal(commands, want) {
+		t.Fatalf("expected the edits saved as %v, got %v", want, commands)
+	}
+
+	// Run it, confirming the first and last steps and skipping the middle.
+	press(keys("r"))
+	if view := m.View(); !strings.Contains(view, "Run echo cleaned?") {
+		t.Fatalf("expected the first step to be asked about, got:\n%s", view)
+	}
+	for _, answer := range []string{"y", "n", "y"} {
+		press(keys(answer))
+		pump(pending)
+	}
+	view = m.View()
+	for _, want := range []string{"✓ Completed", "⏭", "100% success over 1 runs"} {
+		if !strings.Contains(view, want) {
+			t.Errorf("expected %q after the run, got:\n%s", want, view)
+		}
+	}
+
+	rb, err = storage.GetRunbookByID(db, "rb-deps")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rb.UsageCount != 1 || rb.SuccessRate != 1 {
+		t.Errorf("expected the run counted as a success, got %d uses at %.2f", rb.UsageCount, rb.SuccessRate)
+	}
+}
+
+func TestModel_Chat(t *testing.T) {
+	ai := llm.NewFakeProvider()
+	ai.Models = []llm.ChatModel{
+		{Provider: llm.ProviderOllama, Name: "llama3.2:3b"},
+		{Provider: llm.ProviderPerplexity, Name: "sonar-pro"},
+	}
+	model := NewModel(infra.NewFakeDocker(), ai)
+	newModel, _ := model.Update(tea.WindowSizeMsg{Width: 120, Height: 40})
+	newModel, _ = newModel.Update(loadChatModels(ai)())
+	m := newModel.(Model)
+	m.state = StateMain
+
+	// press leaves the commands of keys alone: the input's cursor blinks.
+	press := func(msg tea.KeyMsg) tea.Cmd {
+		newModel, cmd := m.Update(msg)
+		m = newModel.(Model)
+		return cmd
+	}
+	keys := func(k string) tea.KeyMsg { return tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(k)} }
+	// send sends the typed message and streams the reply in.
+	send := func() {
+		t.Helper()
+		cmd := press(tea.KeyMsg{Type: tea.KeyEnter})
+		for cmd != nil {
+			msgs := runCmd(cmd)
+			cmd = nil
+			for _, msg := range msgs {
+				var next tea.Cmd
+				newModel, next = m.Update(msg)
+				m = newModel.(Model)
+				cmd = tea.Batch(cmd, next)
+			}
+		}
+	}
+
+	press(keys("5"))
+	if m.activeTab != TabChat {
+		t.Fatalf("expected 5 to open the Chat tab without a kube context, got %v", m.activeTab)
+	}
+	press(keys("i"))
+	if m.mode != ModeInsert {
+		t.Fatal("expected i to start typing a message")
+	}
+	press(keys("how do I list ports?"))
+	press(tea.KeyMsg{Type: tea.KeyEnter, Alt: true})
+	press(keys("on linux"))
+	send()
+
+	if got := ai.Calls[len(ai.Calls)-1]; got != "Chat ollama/llama3.2:3b" {
+		t.Errorf("expected the chat to go to the first model, got %q", got)
+	}
+	msgs := m.chat.Messages()
+	if len(msgs) != 2 || msgs[0].Content != "how do I list ports?\non linux" {
+		t.Fatalf("expected the multi-line question and its reply, got %+v", msgs)
+	}
+	if msgs[1].Content != "fake reply to: how do I list ports?\non linux" || m.chat.Streaming() {
+		t.Errorf("expected the whole streamed reply, got %+v", msgs[1])
+	}
+	view := m.View()
+	for _, want := range []string{"Chat", "ollama/llama3.2:3b", "how do I list ports?", "fake reply to"} {
+		if !strings.Contains(view, want) {
+			t.Errorf("expected %q in the Chat tab, got:\n%s", want, view)
+		}
+	}
+	if len(m.agent.Blocks()) != 0 {
+		t.Errorf("expected the conversation kept out of the Agent's blocks, got %+v", m.agent.Blocks())
+	}
+
+	// Switch to Perplexity and follow up with the conversation so far.
+	press(tea.KeyMsg{Type: tea.KeyEsc})
+	press(keys("m"))
+	if !m.chat.PickerOpen() || !strings.Contains(m.View(), "sonar-pro") {
+		t.Fatal("expected m to open the model switcher")
+	}
+	press(keys("j"))
+	press(tea.KeyMsg{Type: tea.KeyEnter})
+	if model, _ := m.chat.SelectedModel(); model.Name != "sonar-pro" {
+		t.Fatalf("expected sonar-pro picked, got %v", model)
+	}
+	press(keys("i"))
+	press(keys("and on macOS?"))
+	send()
+	if got := ai.Calls[len(ai.Calls)-1]; got != "Chat perplexity/sonar-pro" {
+		t.Errorf("expected the follow-up to go to the picked model, got %q", got)
+	}
+	if len(m.chat.Messages()) != 4 {
+		t.Errorf("expected the follow-up in the same conversation, got %+v", m.chat.Messages())
+	}
+
+	// A failed question goes back into the input.
+	ai.Err = fmt.Errorf("connection refused")
+	press(keys("still there?"))
+	send()
+	if len(m.chat.Messages()) != 4 || !strings.Contains(m.View(), "connection refused") {
+		t.Errorf("expected the error shown and the question dropped, got %+v", m.chat.Messages())
+	}
+
+	press(tea.KeyMsg{Type: tea.KeyEsc})
+	press(keys("n"))
+	if len(m.chat.Messages()) != 0 {
+		t.Error("expected n to start a new chat")
+	}
+}
+
+func TestModel_Notifications(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	docker := infra.NewFakeDocker(
+		infra.ContainerInfo{ID: "a1", Name: "web", State: "running", Status: "Up 1 hour"},
+		infra.ContainerInfo{ID: "b2", Name: "db", State: "running", Status: "Up 1 hour", Health: "healthy"},
+	)
+	ai := llm.NewFakeProvider()
+	model := NewModel(docker, ai)
+	newModel, _ := model.Update(tea.WindowSizeMsg{Width: 120, Height: 40})
+	newModel, _ = newModel.Update(model.checkDockerHealth())
+	newModel, _ = newModel.Update(loadChatModels(ai)())
+	m := newModel.(Model)
+	if n := len(m.notifications.Log()); n != 0 {
+		t.Fatalf("expected no notifications for the containers found at start, got %d", n)
+	}
+
+	docker.StopContainer(context.Background(), "a1")
+	docker.Containers[1].Health = "unhealthy"
+	newModel, _ = m.Update(m.checkDockerHealth())
+	m = newModel.(Model)
+	var texts []string
+	for _, note := range m.notifications.Log() {
+		texts = append(texts, note.Text)
+	}
+	if want := []string{"web stopped", "db is unhealthy"}; !slices.Equal(texts, want) {
+		t.Fatalf("expected %v, got %v", want, texts)
+	}
+	if view := m.View(); !strings.Contains(view, "web stopped") || !strings.Contains(view, "db is unhealthy") {
+		t.Errorf("expected the changes as toasts, got:\n%s", view)
+	}
+
+	// Toasts go away on their own, but stay counted until the log is read.
+	for _, note := range m.notifications.Toasts() {
+		newModel, _ = m.Update(toastExpiredMsg{id: note.ID})
+		m = newModel.(Model)
+	}
+	if view := m.View(); strings.Contains(view, "web stopped") || !strings.Contains(view, "● 2") {
+		t.Errorf("expected the toasts gone and 2 unread, got:\n%s", view)
+	}
+
+	// A chat reply arriving on another tab.
+	newModel, _ = m.Update(chatStreamMsg{done: true, reply: "hello"})
+	m = newModel.(Model)
+	if toasts := m.notifications.Toasts(); len(toasts) != 1 || toasts[0].Text != "Chat: ollama/fake-model answered" {
+		t.Errorf("expected a toast for the chat reply, got %+v", toasts)
+	}
+
+	// Recording start and stop.
+	newModel, _ = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("2")})
+	newModel, _ = newModel.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("R")})
+	newModel, _ = newModel.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("R")})
+	m = newModel.(Model)
+	log := m.notifications.Log()
+	if len(log) != 5 || !strings.HasPrefix(log[3].Text, "Recording logs to ") || !strings.HasPrefix(log[4].Text, "Saved the log recording to ") {
+		t.Fatalf("expected recording start and stop notified, got %+v", log)
+	}
+
+	newModel, _ = m.Update(tea.KeyMsg{Type: tea.KeyCtrlG})
+	m = newModel.(Model)
+	view := m.View()
+	if !m.notifications.Open() || !strings.Contains(view, "Notifications") || !strings.Contains(view, "db is unhealthy") {
+		t.Fatalf("expected Ctrl+G to pull the log down, got:\n%s", view)
+	}
+	if strings.Contains(view, "● 5") || len(m.notifications.Toasts()) != 0 {
+		t.Error("expected opening the log to mark everything read")
+	}
+	newModel, cmd := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("q")})
+	m = newModel.(Model)
+	if m.notifications.Open() || m.quitting || cmd != nil {
+		t.Error("expected q to close the log, not quit")
+	}
+}
+
+func TestModel_ConfiguredTabs(t *testing.T) {
+	t.Setenv("DEV_CLI_TABS", "History, agent,bogus,history,kubernetes")
+	model := NewModel(infra.NewFakeDocker(), llm.NewFakeProvider())
+	model.state = StateMain
+
+	if tabs := model.tabs(); !slices.Equal(tabs, []Tab{TabHistory, TabAgent}) {
+		t.Fatalf("expected History then Agent (Kubernetes needs a kube context), got %v", tabs)
+	}
+	if model.activeTab != TabHistory {
+		t.Errorf("expected the first configured tab to open, got %v", model.activeTab)
+	}
+
+	newModel, _ := model.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("2")})
+	if newModel.(Model).activeTab != TabAgent {
+		t.Errorf("expected 2 to pick the second configured tab, got %v", newModel.(Model).activeTab)
+	}
+	newModel, _ = model.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("3")})
+	if newModel.(Model).activeTab != TabHistory {
+		t.Error("expected 3 to do nothing with two tabs")
+	}
+	newModel, _ = model.Update(tea.KeyMsg{Type: tea.KeyShiftTab})
+	if newModel.(Model).activeTab != TabAgent {
+		t.Error("expected Shift+Tab to wrap within the configured tabs")
+	}
+
+	bar := strings.Split(model.View(), "\n")[0]
+	if !strings.Contains(bar, "History") || strings.Contains(bar, "Containers") || strings.Contains(bar, "Chat") {
+		t.Errorf("expected only the configured tabs in the tab bar, got %q", bar)
+	}
+	for _, action := range model.paletteActions() {
+		if action.group == "Containers" || action.group == "Chat" || action.title == "Runbooks" {
+			t.Errorf("expected no palette action for a hidden tab, got %q", action.title)
+		}
+	}
+
+	m := model.WithKube(fakeKube{})
+	if tabs := m.tabs(); !slices.Equal(tabs, []Tab{TabHistory, TabAgent, TabKubernetes}) {
+		t.Errorf("expected Kubernetes once a kube context is there, got %v", tabs)
+	}
+}
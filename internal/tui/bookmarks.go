@@ -0,0 +1,44 @@
+package tui
+
+import (
+	"database/sql"
+
+	"dev-cli/internal/storage"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// loadBookmarks reads the blocks pinned in earlier sessions.
+func loadBookmarks(db *sql.DB) tea.Cmd {
+	if db == nil {
+		return nil
+	}
+	return func() tea.Msg {
+		bookmarks, err := storage.GetBookmarks(db)
+		return bookmarksLoadedMsg{bookmarks: bookmarks, err: err}
+	}
+}
+
+// saveBookmark stores a pinned block; without the history database it
+// stays pinned for this session only.
+func saveBookmark(db *sql.DB, blockID string, b storage.Bookmark) tea.Cmd {
+	if db == nil {
+		return nil
+	}
+	return func() tea.Msg {
+		id, err := storage.AddBookmark(db, b)
+		return bookmarkSavedMsg{blockID: blockID, id: id, err: err}
+	}
+}
+
+// deleteBookmark drops the bookmark of an unpinned block. One that fails
+// to go comes back pinned next session, where P drops it again.
+func deleteBookmark(db *sql.DB, id int64) tea.Cmd {
+	if db == nil {
+		return nil
+	}
+	return func() tea.Msg {
+		_ = storage.DeleteBookmark(db, id)
+		return nil
+	}
+}
@@ -0,0 +1,46 @@
+package tui
+
+import (
+	"fmt"
+
+	"dev-cli/internal/llm"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func loadChatModels(ai llm.LLMProvider) tea.Cmd {
+	if ai == nil {
+		return nil
+	}
+	return func() tea.Msg {
+		models, err := ai.ChatModels()
+		return chatModelsMsg{models: models, err: err}
+	}
+}
+
+// askChat streams the reply of model to the conversation in messages in
+// the background; waitChat delivers it chunk by chunk until a final
+// message with done set.
+func (m Model) askChat(model llm.ChatModel, messages []llm.ChatMessage) tea.Cmd {
+	ai := m.aiClient
+	return func() tea.Msg {
+		if ai == nil {
+			return chatStreamMsg{done: true, err: fmt.Errorf("no AI backend configured")}
+		}
+		ch := make(chan chatStreamMsg, 64)
+		go func() {
+			reply, err := ai.Chat(model, messages, func(delta string) {
+				ch <- chatStreamMsg{ch: ch, delta: delta}
+			})
+			ch <- chatStreamMsg{ch: ch, done: true, reply: reply, err: err}
+			close(ch)
+		}()
+		return waitChat(ch)()
+	}
+}
+
+func waitChat(ch <-chan chatStreamMsg) tea.Cmd {
+	return func() tea.Msg {
+		return <-ch
+	}
+}
@@ -0,0 +1,39 @@
+package tui
+
+import (
+	"encoding/base64"
+	"fmt"
+	"os"
+
+	"github.com/atotto/clipboard"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// maxOSC52Bytes is the most text sent through OSC 52; terminals cap the
+// sequence (xterm at 100,000 bytes) and drop anything longer silently.
+const maxOSC52Bytes = 74_000
+
+// writeClipboard sets the local system clipboard; tests replace it.
+var writeClipboard = clipboard.WriteAll
+
+// remoteSession reports whether dev-cli runs over SSH, where the host's
+// clipboard (if it has one) is not the user's.
+func remoteSession() bool {
+	return os.Getenv("SSH_TTY") != "" || os.Getenv("SSH_CONNECTION") != ""
+}
+
+// copyToClipboardCmd puts text on the clipboard: the system one when it is
+// local and works, else the terminal's through OSC 52, which also reaches
+// the user's machine from an SSH session.
+func copyToClipboardCmd(text, what string) tea.Cmd {
+	return func() tea.Msg {
+		if !remoteSession() && writeClipboard(text) == nil {
+			return clipboardCopiedMsg{what: what}
+		}
+		if len(text) > maxOSC52Bytes {
+			return clipboardCopiedMsg{what: what, err: fmt.Errorf("%d bytes is too much for the terminal clipboard", len(text))}
+		}
+		writeOSC("52;c;" + base64.StdEncoding.EncodeToString([]byte(text)))
+		return clipboardCopiedMsg{what: what}
+	}
+}
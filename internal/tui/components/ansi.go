@@ -0,0 +1,104 @@
+package components
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
+)
+
+var sgrSequence = regexp.MustCompile(`\x1b\[([0-9;]*)m`)
+
+// RestyleANSI renders s, text colored with SGR sequences like a program's
+// output, as lipgloss styles layered on base. Unlike passing the sequences
+// through, the text's resets fall back to base, so a background set there
+// runs under the whole text, and the colors follow the color profile.
+// Other escape sequences are dropped.
+func RestyleANSI(s string, base lipgloss.Style) string {
+	var b strings.Builder
+	style := base
+	render := func(text string) {
+		if text = ansi.Strip(text); text != "" {
+			b.WriteString(style.Render(text))
+		}
+	}
+
+	last := 0
+	for _, loc := range sgrSequence.FindAllStringSubmatchIndex(s, -1) {
+		render(s[last:loc[0]])
+		style = applySGR(style, base, s[loc[2]:loc[3]])
+		last = loc[1]
+	}
+	render(s[last:])
+	return b.String()
+}
+
+// applySGR changes style the way the SGR parameters params would change a
+// terminal's pen; a reset goes back to base.
+func applySGR(style, base lipgloss.Style, params string) lipgloss.Style {
+	var codes []int
+	for _, p := range strings.Split(params, ";") {
+		n, _ := strconv.Atoi(p)
+		codes = append(codes, n)
+	}
+
+	for i := 0; i < len(codes); i++ {
+		switch c := codes[i]; {
+		case c == 0:
+			style = base
+		case c == 1:
+			style = style.Bold(true)
+		case c == 2:
+			style = style.Faint(true)
+		case c == 3:
+			style = style.Italic(true)
+		case c == 4:
+			style = style.Underline(true)
+		case c == 22:
+			style = style.Bold(false).Faint(false)
+		case c == 23:
+			style = style.Italic(false)
+		case c == 24:
+			style = style.Underline(false)
+		case c >= 30 && c <= 37:
+			style = style.Foreground(lipgloss.Color(strconv.Itoa(c - 30)))
+		case c >= 90 && c <= 97:
+			style = style.Foreground(lipgloss.Color(strconv.Itoa(c - 90 + 8)))
+		case c == 39:
+			style = style.Foreground(base.GetForeground())
+		case c >= 40 && c <= 47:
+			style = style.Background(lipgloss.Color(strconv.Itoa(c - 40)))
+		case c >= 100 && c <= 107:
+			style = style.Background(lipgloss.Color(strconv.Itoa(c - 100 + 8)))
+		case c == 49:
+			style = style.Background(base.GetBackground())
+		case c == 38 || c == 48:
+			color, n := extendedColor(codes[i+1:])
+			i += n
+			if color == "" {
+				continue
+			}
+			if c == 38 {
+				style = style.Foreground(color)
+			} else {
+				style = style.Background(color)
+			}
+		}
+	}
+	return style
+}
+
+// extendedColor reads the color after a 38 or 48: 5;n for the 256-color
+// palette or 2;r;g;b for true color. It returns how many codes it took.
+func extendedColor(codes []int) (lipgloss.Color, int) {
+	switch {
+	case len(codes) >= 2 && codes[0] == 5:
+		return lipgloss.Color(strconv.Itoa(codes[1])), 2
+	case len(codes) >= 4 && codes[0] == 2:
+		return lipgloss.Color(fmt.Sprintf("#%02x%02x%02x", codes[1]&255, codes[2]&255, codes[3]&255)), 4
+	}
+	return "", len(codes)
+}
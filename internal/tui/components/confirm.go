@@ -0,0 +1,96 @@
+package components
+
+import (
+	"fmt"
+	"strings"
+
+	"dev-cli/internal/tui/theme"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
+)
+
+// Confirm asks before an operation that can't be undone: what it is, what
+// it touches and what it will do, with y to go ahead. A Blocked one can't
+// run at all, so it only explains why and closes.
+type Confirm struct {
+	Title string
+	// Items lists what the operation touches, one per line; those that
+	// don't fit are counted instead.
+	Items []string
+	// Text says what will happen, a paragraph per entry.
+	Text []string
+	// Verb names the y key, e.g. "remove".
+	Verb string
+	// Danger marks an operation that loses data: red instead of peach.
+	Danger  bool
+	Blocked bool
+}
+
+// ConfirmAnswer is what a key says to a Confirm.
+type ConfirmAnswer int
+
+const (
+	ConfirmPending ConfirmAnswer = iota
+	ConfirmYes
+	ConfirmNo
+)
+
+// Answer reads a key: y goes ahead, n and esc back out, and any other key
+// leaves the question open.
+func (c Confirm) Answer(key string) ConfirmAnswer {
+	switch key {
+	case "y":
+		if !c.Blocked {
+			return ConfirmYes
+		}
+	case "n", "esc":
+		return ConfirmNo
+	}
+	return ConfirmPending
+}
+
+// View renders the question as a panel of the given size.
+func (c Confirm) View(width, height int) string {
+	color := theme.Peach
+	if c.Danger {
+		color = theme.Red
+	}
+	panelStyle := lipgloss.NewStyle().
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(color).
+		Width(width).
+		Height(height).
+		MaxHeight(height).
+		MaxWidth(width)
+
+	headerStyle := lipgloss.NewStyle().Foreground(color).Bold(true)
+	dimStyle := lipgloss.NewStyle().Foreground(theme.Overlay0)
+	textStyle := lipgloss.NewStyle().Foreground(theme.Text).Width(width - 4)
+
+	var content strings.Builder
+	content.WriteString(headerStyle.Render(ansi.Truncate("⚠ "+c.Title, width-4, "…")) + "\n\n")
+
+	if len(c.Items) > 0 {
+		// Leave room for the title, the text and the keys.
+		room := max(height-6-3*len(c.Text), 1)
+		for i, item := range c.Items {
+			if i == room-1 && len(c.Items) > room {
+				content.WriteString(dimStyle.Render(fmt.Sprintf("  … and %d more", len(c.Items)-i)) + "\n")
+				break
+			}
+			content.WriteString(ansi.Truncate("  "+item, width-4, "…") + "\n")
+		}
+		content.WriteString("\n")
+	}
+	for _, text := range c.Text {
+		content.WriteString(textStyle.Render(text) + "\n\n")
+	}
+
+	if c.Blocked {
+		content.WriteString(dimStyle.Render("Esc close"))
+	} else {
+		content.WriteString(dimStyle.Render("y " + c.Verb + " • n/Esc cancel"))
+	}
+	return panelStyle.Render(content.String())
+}
@@ -0,0 +1,202 @@
+package components
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"dev-cli/internal/tui/theme"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
+)
+
+// ToastDuration is how long a notification shows as a toast before it is
+// only in the log.
+const ToastDuration = 5 * time.Second
+
+const (
+	maxToasts        = 3
+	maxNotifications = 100
+	toastWidth       = 44
+)
+
+type NotificationLevel int
+
+const (
+	NotifyInfo NotificationLevel = iota
+	NotifySuccess
+	NotifyWarning
+	NotifyError
+)
+
+type Notification struct {
+	ID    int
+	Level NotificationLevel
+	Text  string
+	Time  time.Time
+}
+
+// Notifications shows what changed in the background as toasts, and keeps
+// them in a log that can be pulled down.
+type Notifications struct {
+	// log is oldest first.
+	log []Notification
+	// toasts holds the IDs of the notifications still shown as toasts.
+	toasts []int
+	nextID int
+	open   bool
+	unread int
+}
+
+// Push adds a notification and shows it as a toast; the returned ID is the
+// one to Expire after ToastDuration.
+func (n Notifications) Push(level NotificationLevel, text string, now time.Time) (Notifications, int) {
+	n.nextID++
+	n.log = append(slices.Clone(n.log), Notification{ID: n.nextID, Level: level, Text: text, Time: now})
+	if len(n.log) > maxNotifications {
+		n.log = n.log[len(n.log)-maxNotifications:]
+	}
+	if !n.open {
+		n.toasts = append(slices.Clone(n.toasts), n.nextID)
+		if len(n.toasts) > maxToasts {
+			n.toasts = n.toasts[len(n.toasts)-maxToasts:]
+		}
+		n.unread++
+	}
+	return n, n.nextID
+}
+
+// Expire stops showing notification id as a toast.
+func (n Notifications) Expire(id int) Notifications {
+	n.toasts = slices.DeleteFunc(slices.Clone(n.toasts), func(t int) bool { return t == id })
+	return n
+}
+
+// Toggle pulls the log down or puts it away. Opening it marks everything
+// read and dismisses the toasts.
+func (n Notifications) Toggle() Notifications {
+	n.open = !n.open
+	if n.open {
+		n.unread = 0
+		n.toasts = nil
+	}
+	return n
+}
+
+// Clear empties the log.
+func (n Notifications) Clear() Notifications {
+	n.log = nil
+	n.toasts = nil
+	n.unread = 0
+	return n
+}
+
+func (n Notifications) Open() bool { return n.open }
+
+// Unread counts the notifications pushed since the log was last opened.
+func (n Notifications) Unread() int { return n.unread }
+
+func (n Notifications) Log() []Notification { return n.log }
+
+// Toasts lists the notifications showing as toasts, oldest first.
+func (n Notifications) Toasts() []Notification {
+	var toasts []Notification
+	for _, note := range n.log {
+		if slices.Contains(n.toasts, note.ID) {
+			toasts = append(toasts, note)
+		}
+	}
+	return toasts
+}
+
+func levelStyle(level NotificationLevel) (string, lipgloss.Color) {
+	switch level {
+	case NotifySuccess:
+		return "✓", theme.Green
+	case NotifyWarning:
+		return "!", theme.Yellow
+	case NotifyError:
+		return "✗", theme.Red
+	}
+	return "•", theme.Blue
+}
+
+// RenderToasts stacks the toasts, newest at the bottom, or returns "" when
+// there are none.
+func (n Notifications) RenderToasts() string {
+	var boxes []string
+	for _, note := range n.Toasts() {
+		icon, color := levelStyle(note.Level)
+		boxes = append(boxes, lipgloss.NewStyle().
+			Border(lipgloss.RoundedBorder()).
+			BorderForeground(color).
+			Foreground(theme.Text).
+			Padding(0, 1).
+			Width(toastWidth).
+			Render(lipgloss.NewStyle().Foreground(color).Bold(true).Render(icon)+" "+note.Text))
+	}
+	return strings.Join(boxes, "\n")
+}
+
+// RenderLog renders the pulled-down log, newest first, cut to height.
+func (n Notifications) RenderLog(width, height int, now time.Time) string {
+	dimStyle := lipgloss.NewStyle().Foreground(theme.Overlay0)
+	textWidth := max(width-4, 10)
+
+	lines := []string{lipgloss.NewStyle().Foreground(theme.Lavender).Bold(true).Render("Notifications")}
+	if len(n.log) == 0 {
+		lines = append(lines, dimStyle.Render("Nothing happened yet."))
+	}
+	for i := len(n.log) - 1; i >= 0 && len(lines) < height-3; i-- {
+		note := n.log[i]
+		icon, color := levelStyle(note.Level)
+		ago := dimStyle.Render(" " + formatAge(now.Sub(note.Time)))
+		text := ansi.Truncate(note.Text, textWidth-2-lipgloss.Width(ago), "…")
+		lines = append(lines, lipgloss.NewStyle().Foreground(color).Render(icon)+" "+text+ago)
+	}
+	lines = append(lines, dimStyle.Render("c clear • Esc close"))
+
+	return lipgloss.NewStyle().
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(theme.Mauve).
+		Padding(0, 1).
+		Width(width).
+		Render(strings.Join(lines, "\n"))
+}
+
+func formatAge(d time.Duration) string {
+	switch {
+	case d < time.Minute:
+		return "just now"
+	case d < time.Hour:
+		return fmt.Sprintf("%dm ago", int(d.Minutes()))
+	case d < 24*time.Hour:
+		return fmt.Sprintf("%dh ago", int(d.Hours()))
+	}
+	return fmt.Sprintf("%dd ago", int(d.Hours()/24))
+}
+
+// Overlay draws fg over bg with its top-left corner at column x, row y,
+// keeping what bg shows around it.
+func Overlay(bg, fg string, x, y int) string {
+	if fg == "" {
+		return bg
+	}
+	bgLines := strings.Split(bg, "\n")
+	for i, line := range strings.Split(fg, "\n") {
+		row := y + i
+		if row < 0 || row >= len(bgLines) {
+			continue
+		}
+		under := bgLines[row]
+		left := ansi.Truncate(under, x, "")
+		if w := lipgloss.Width(left); w < x {
+			left += strings.Repeat(" ", x-w)
+		}
+		right := ansi.TruncateLeft(under, x+lipgloss.Width(line), "")
+		bgLines[row] = left + "\x1b[0m" + line + "\x1b[0m" + right
+	}
+	return strings.Join(bgLines, "\n")
+}
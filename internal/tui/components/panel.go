@@ -65,3 +65,12 @@ func (p Panel) RenderWithHeader(header, content string) string {
 	style := p.Style().Width(p.Width).Height(p.Height)
 	return style.Render(header + "\n" + content)
 }
+
+// CompactWidth is the terminal width below which tabs switch to their
+// compact layout: panels stacked instead of side by side, the Containers
+// sidebar behind a drawer and shorter header widgets.
+const CompactWidth = 80
+
+// Compact reports whether width calls for the compact layout. A zero width,
+// before the first resize, does not.
+func Compact(width int) bool { return width > 0 && width < CompactWidth }
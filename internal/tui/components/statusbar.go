@@ -15,9 +15,6 @@ type StatusBar struct {
 func NewStatusBar() StatusBar {
 	h := help.New()
 	h.ShowAll = false
-	h.Styles.ShortKey = lipgloss.NewStyle().Foreground(theme.Mauve).Bold(true)
-	h.Styles.ShortDesc = lipgloss.NewStyle().Foreground(theme.Overlay0)
-	h.Styles.ShortSeparator = lipgloss.NewStyle().Foreground(theme.Surface2)
 	return StatusBar{
 		help: h,
 	}
@@ -29,6 +26,10 @@ func (s StatusBar) SetWidth(w int) StatusBar {
 }
 
 func (s StatusBar) Render(keys help.KeyMap, focusLabel string) string {
+	// The help styles follow the theme, which can change at runtime.
+	s.help.Styles.ShortKey = lipgloss.NewStyle().Foreground(theme.Mauve).Bold(true)
+	s.help.Styles.ShortDesc = lipgloss.NewStyle().Foreground(theme.Overlay0)
+	s.help.Styles.ShortSeparator = lipgloss.NewStyle().Foreground(theme.Surface2)
 	helpView := s.help.View(keys)
 
 	focusStyle := lipgloss.NewStyle().
@@ -45,6 +46,10 @@ func (s StatusBar) Render(keys help.KeyMap, focusLabel string) string {
 }
 
 func (s StatusBar) RenderWithInfo(keys help.KeyMap, focusLabel string, info string) string {
+	// The help styles follow the theme, which can change at runtime.
+	s.help.Styles.ShortKey = lipgloss.NewStyle().Foreground(theme.Mauve).Bold(true)
+	s.help.Styles.ShortDesc = lipgloss.NewStyle().Foreground(theme.Overlay0)
+	s.help.Styles.ShortSeparator = lipgloss.NewStyle().Foreground(theme.Surface2)
 	helpView := s.help.View(keys)
 
 	focusStyle := lipgloss.NewStyle().
@@ -1,6 +1,7 @@
 package components
 
 import (
+	"fmt"
 	"strings"
 
 	"dev-cli/internal/tui/theme"
@@ -15,6 +16,8 @@ type TabBar struct {
 	ShowMode   bool
 	InsertMode bool
 	Badges     map[int]int
+	// Unread counts the notifications not seen in the log yet.
+	Unread int
 }
 
 type TabItem struct {
@@ -47,6 +50,11 @@ func (t TabBar) SetInsertMode(insert bool) TabBar {
 	return t
 }
 
+func (t TabBar) SetUnread(n int) TabBar {
+	t.Unread = n
+	return t
+}
+
 func (t TabBar) SetBadge(tabIdx, count int) TabBar {
 	if t.Badges == nil {
 		t.Badges = make(map[int]int)
@@ -67,6 +75,10 @@ func (t TabBar) Render() string {
 		}
 
 		content := tab.Icon + " " + tab.Label
+		// Narrow bars name only the active tab.
+		if Compact(t.Width) && i != t.ActiveTab {
+			content = tab.Icon
+		}
 
 		if count, ok := t.Badges[i]; ok && count > 0 {
 			badgeStyle := lipgloss.NewStyle().
@@ -84,13 +96,22 @@ func (t TabBar) Render() string {
 
 	modeStr := ""
 	if t.ShowMode {
+		insert, normal := " INSERT ", " NORMAL "
+		if Compact(t.Width) {
+			insert, normal = "I", "N"
+		}
 		if t.InsertMode {
-			modeStr = theme.ModeIndicator.Render(" INSERT ")
+			modeStr = theme.ModeIndicator.Render(insert)
 		} else {
-			modeStr = theme.NormalModeIndicator.Render(" NORMAL ")
+			modeStr = theme.NormalModeIndicator.Render(normal)
 		}
 	}
 
+	if t.Unread > 0 {
+		unread := lipgloss.NewStyle().Foreground(theme.Peach).Bold(true).Render(fmt.Sprintf("● %d ", t.Unread))
+		modeStr = unread + modeStr
+	}
+
 	spacer := ""
 	spacerWidth := t.Width - lipgloss.Width(row) - lipgloss.Width(modeStr) - 2
 	if spacerWidth > 0 {
@@ -523,14 +523,14 @@ func (c ContextBadge) Render() string {
 	var parts []string
 
 	if c.Commands > 0 {
-		parts = append(parts, fmt.Sprintf("📋 %d commands", c.Commands))
+		parts = append(parts, fmt.Sprintf("%s %d commands", theme.Icon("📋", "*"), c.Commands))
 	}
 	if c.Containers > 0 {
-		parts = append(parts, fmt.Sprintf("🐳 %d containers", c.Containers))
+		parts = append(parts, fmt.Sprintf("%s %d containers", theme.Icon("🐳", "*"), c.Containers))
 	}
 	if c.Errors > 0 {
 		errStyle := lipgloss.NewStyle().Foreground(theme.Red)
-		parts = append(parts, errStyle.Render(fmt.Sprintf("🔴 %d errors", c.Errors)))
+		parts = append(parts, errStyle.Render(fmt.Sprintf("%s %d errors", theme.Icon("🔴", "!"), c.Errors)))
 	}
 
 	if len(parts) == 0 {
@@ -0,0 +1,295 @@
+package tui
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"runtime"
+	"runtime/debug"
+	"strings"
+	"sync"
+	"time"
+
+	"dev-cli/internal/pipeline"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// issueURL is where pre-filled crash issues are opened.
+const issueURL = "https://github.com/opx0/dev-cli/issues/new"
+
+// crashEventCount is how many recent pipeline events go into a crash report.
+const crashEventCount = 20
+
+// CrashReport captures a panic raised inside the dashboard.
+type CrashReport struct {
+	Time     time.Time
+	Panic    string
+	Stack    string
+	Versions []string
+	Events   []pipeline.Event
+	Path     string
+}
+
+// crashState is shared by every copy of the guard so a panic recorded deep
+// inside Update, View or a command is still visible to Run afterwards.
+type crashState struct {
+	mu     sync.Mutex
+	report *CrashReport
+	quit   func()
+}
+
+func (s *crashState) record(r interface{}, stack []byte, bus *pipeline.EventBus) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.report != nil {
+		return
+	}
+	report := &CrashReport{
+		Time:     time.Now(),
+		Panic:    fmt.Sprint(r),
+		Stack:    string(stack),
+		Versions: buildVersions(),
+	}
+	if bus != nil {
+		report.Events = append(report.Events, bus.RecentEvents(crashEventCount)...)
+	}
+	s.report = report
+}
+
+func (s *crashState) crashed() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.report != nil
+}
+
+// crashGuard wraps the app model and recovers panics from Init, Update, View
+// and the commands they return, quitting the program cleanly instead.
+type crashGuard struct {
+	model tea.Model
+	bus   *pipeline.EventBus
+	state *crashState
+}
+
+func (g crashGuard) Init() (cmd tea.Cmd) {
+	defer func() {
+		if r := recover(); r != nil {
+			g.state.record(r, debug.Stack(), g.bus)
+			cmd = tea.Quit
+		}
+	}()
+	return g.wrap(g.model.Init())
+}
+
+func (g crashGuard) Update(msg tea.Msg) (next tea.Model, cmd tea.Cmd) {
+	next = g
+	defer func() {
+		if r := recover(); r != nil {
+			g.state.record(r, debug.Stack(), g.bus)
+			cmd = tea.Quit
+		}
+	}()
+	if g.state.crashed() {
+		return g, tea.Quit
+	}
+	model, cmd := g.model.Update(msg)
+	g.model = model
+	return g, g.wrap(cmd)
+}
+
+func (g crashGuard) View() (view string) {
+	defer func() {
+		if r := recover(); r != nil {
+			g.state.record(r, debug.Stack(), g.bus)
+			// View runs on the event loop, so quitting has to go through
+			// a goroutine to avoid blocking on our own message channel.
+			if g.state.quit != nil {
+				go g.state.quit()
+			}
+			view = ""
+		}
+	}()
+	if g.state.crashed() {
+		return ""
+	}
+	return g.model.View()
+}
+
+// wrap guards cmd, including any commands it fans out into via tea.Batch.
+func (g crashGuard) wrap(cmd tea.Cmd) tea.Cmd {
+	if cmd == nil {
+		return nil
+	}
+	return func() (msg tea.Msg) {
+		defer func() {
+			if r := recover(); r != nil {
+				g.state.record(r, debug.Stack(), g.bus)
+				msg = tea.Quit()
+			}
+		}()
+		msg = cmd()
+		if batch, ok := msg.(tea.BatchMsg); ok {
+			for i := range batch {
+				batch[i] = g.wrap(batch[i])
+			}
+		}
+		return msg
+	}
+}
+
+// Run starts the dashboard with panic recovery. When the program panics the
+// terminal is restored through a normal shutdown and the returned report
+// describes the crash; it has already been written to ~/.devlogs/crashes
+// unless Path is empty.
+func Run(m Model, opts ...tea.ProgramOption) (*CrashReport, error) {
+	state := &crashState{}
+	bus := m.pipe.Bus()
+	// OSC sequences share the program's output; see terminalOutput.
+	opts = append([]tea.ProgramOption{tea.WithOutput(stdout)}, opts...)
+	p := tea.NewProgram(crashGuard{model: m, bus: bus, state: state}, opts...)
+	state.quit = p.Quit
+
+	final, err := p.Run()
+	if g, ok := final.(crashGuard); ok {
+		if app, ok := g.model.(Model); ok {
+			app.endSession()
+		}
+	}
+	if errors.Is(err, tea.ErrProgramPanic) {
+		// Bubble Tea caught a panic we could not wrap (e.g. inside a
+		// tea.Sequence); it already printed the stack to the terminal.
+		state.record("panic caught by bubbletea", nil, bus)
+		err = nil
+	}
+
+	state.mu.Lock()
+	report := state.report
+	state.mu.Unlock()
+	if report == nil {
+		return nil, err
+	}
+
+	if path, werr := WriteCrashReport(report); werr == nil {
+		report.Path = path
+	}
+	return report, err
+}
+
+// WriteCrashReport stores the report as markdown under ~/.devlogs/crashes
+// and returns its path.
+func WriteCrashReport(report *CrashReport) (string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	dir := filepath.Join(home, ".devlogs", "crashes")
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return "", fmt.Errorf("create crash dir: %w", err)
+	}
+
+	path := filepath.Join(dir, "crash-"+report.Time.Format("20060102-150405")+".md")
+	if err := os.WriteFile(path, []byte(report.Markdown()), 0644); err != nil {
+		return "", fmt.Errorf("write crash report: %w", err)
+	}
+	return path, nil
+}
+
+// Markdown renders the full report, including stack and recent events.
+func (r *CrashReport) Markdown() string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "# dev-cli crash report\n\n")
+	fmt.Fprintf(&sb, "**Time:** %s\n\n", r.Time.Format(time.RFC3339))
+	fmt.Fprintf(&sb, "**Panic:** `%s`\n\n", r.Panic)
+
+	sb.WriteString("## Versions\n\n")
+	for _, v := range r.Versions {
+		fmt.Fprintf(&sb, "- %s\n", v)
+	}
+
+	sb.WriteString("\n## Stack\n\n```\n")
+	if r.Stack == "" {
+		sb.WriteString("(not captured)\n")
+	} else {
+		sb.WriteString(r.Stack)
+	}
+	sb.WriteString("```\n")
+
+	sb.WriteString("\n## Last events\n\n")
+	if len(r.Events) == 0 {
+		sb.WriteString("(none)\n")
+	}
+	for _, e := range r.Events {
+		fmt.Fprintf(&sb, "- %s `%s` %s %s\n", e.Timestamp.Format("15:04:05.000"), e.Type, e.Source, truncateEventData(e.Data))
+	}
+	return sb.String()
+}
+
+// IssueURL returns a GitHub new-issue link pre-filled with the panic,
+// versions and the top of the stack. Event payloads are left out since they
+// may contain commands or log lines the user would not want to publish.
+func (r *CrashReport) IssueURL() string {
+	var body strings.Builder
+	fmt.Fprintf(&body, "**Panic:** `%s`\n\n", r.Panic)
+	body.WriteString("**Versions:**\n")
+	for _, v := range r.Versions {
+		fmt.Fprintf(&body, "- %s\n", v)
+	}
+	if r.Stack != "" {
+		lines := strings.Split(strings.TrimSpace(r.Stack), "\n")
+		if len(lines) > 40 {
+			lines = append(lines[:40], "...")
+		}
+		fmt.Fprintf(&body, "\n```\n%s\n```\n", strings.Join(lines, "\n"))
+	}
+	body.WriteString("\n**What were you doing when it crashed?**\n\n")
+
+	q := url.Values{}
+	q.Set("title", "Crash: "+truncate(r.Panic, 80))
+	q.Set("body", body.String())
+	q.Set("labels", "bug,crash")
+	return issueURL + "?" + q.Encode()
+}
+
+func buildVersions() []string {
+	versions := []string{
+		"go: " + runtime.Version(),
+		"os/arch: " + runtime.GOOS + "/" + runtime.GOARCH,
+	}
+	info, ok := debug.ReadBuildInfo()
+	if !ok {
+		return versions
+	}
+	devCli := "dev-cli: " + info.Main.Version
+	for _, s := range info.Settings {
+		if s.Key == "vcs.revision" {
+			devCli += " (" + truncate(s.Value, 12) + ")"
+		}
+	}
+	versions = append([]string{devCli}, versions...)
+	for _, dep := range info.Deps {
+		if dep.Path == "github.com/charmbracelet/bubbletea" {
+			versions = append(versions, "bubbletea: "+dep.Version)
+		}
+	}
+	return versions
+}
+
+func truncateEventData(data interface{}) string {
+	if data == nil {
+		return ""
+	}
+	s := strings.ReplaceAll(fmt.Sprintf("%+v", data), "\n", " ")
+	return truncate(s, 200)
+}
+
+// truncate cuts s to at most n runes, so the cut never splits one and the
+// report stays valid UTF-8.
+func truncate(s string, n int) string {
+	runes := []rune(s)
+	if len(runes) <= n {
+		return s
+	}
+	return string(runes[:max(n, 0)])
+}
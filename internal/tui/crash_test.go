@@ -0,0 +1,123 @@
+package tui
+
+import (
+	"io"
+	"os"
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	"dev-cli/internal/pipeline"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+type panicModel struct {
+	panicOn string
+}
+
+func (p panicModel) Init() tea.Cmd {
+	return func() tea.Msg { panic("boom in cmd") }
+}
+
+func (p panicModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	if s, ok := msg.(string); ok && s == p.panicOn {
+		panic("boom in update")
+	}
+	return p, nil
+}
+
+func (p panicModel) View() string { return "ok" }
+
+func TestCrashGuard_RecoversUpdatePanic(t *testing.T) {
+	bus := pipeline.NewEventBus()
+	bus.Publish(pipeline.Event{Type: pipeline.EventCommandStart, Source: "test", Data: "ls -la"})
+	state := &crashState{}
+	guard := crashGuard{model: panicModel{panicOn: "explode"}, bus: bus, state: state}
+
+	next, cmd := guard.Update("explode")
+	if next == nil {
+		t.Fatal("guard must return a model after a panic")
+	}
+	if cmd == nil {
+		t.Fatal("expected a quit command")
+	}
+	if _, ok := cmd().(tea.QuitMsg); !ok {
+		t.Error("expected tea.QuitMsg after a panic")
+	}
+	if state.report == nil || state.report.Panic != "boom in update" {
+		t.Fatalf("expected recorded panic, got %+v", state.report)
+	}
+	if len(state.report.Events) != 1 {
+		t.Errorf("expected 1 recent event, got %d", len(state.report.Events))
+	}
+	if !strings.Contains(state.report.Markdown(), "ls -la") {
+		t.Error("markdown should include recent event data")
+	}
+}
+
+func TestCrashGuard_RecoversCommandPanic(t *testing.T) {
+	state := &crashState{}
+	guard := crashGuard{model: panicModel{}, state: state}
+
+	cmd := guard.Init()
+	if _, ok := cmd().(tea.QuitMsg); !ok {
+		t.Error("expected panicking command to yield tea.QuitMsg")
+	}
+	if state.report == nil || state.report.Stack == "" {
+		t.Fatal("expected report with stack")
+	}
+}
+
+func TestCrashReport_IssueURL(t *testing.T) {
+	report := &CrashReport{Panic: "index out of range", Stack: "goroutine 1\nmain.go:10", Versions: []string{"go: go1.25"}}
+
+	u := report.IssueURL()
+	if !strings.HasPrefix(u, issueURL+"?") {
+		t.Fatalf("unexpected URL %s", u)
+	}
+	if !strings.Contains(u, "index+out+of+range") {
+		t.Errorf("expected panic in issue title, got %s", u)
+	}
+}
+
+func TestCrashReport_TruncatesOnRunes(t *testing.T) {
+	got := truncate("panic: ünïcödé ✗ everywhere", 9)
+	if got != "panic: ün" || !utf8.ValidString(got) {
+		t.Errorf("expected the cut on a rune boundary, got %q", got)
+	}
+	if got := truncate("short", 80); got != "short" {
+		t.Errorf("expected a short string kept, got %q", got)
+	}
+}
+
+func TestTerminalOutput_CarriesOSC(t *testing.T) {
+	if oscWriter != io.Writer(stdout) {
+		t.Fatal("expected OSC sequences written to the program's output")
+	}
+	// Bubble Tea only sizes and raw-modes the terminal through a term.File.
+	if _, ok := any(stdout).(interface {
+		io.ReadWriteCloser
+		Fd() uintptr
+	}); !ok {
+		t.Fatal("expected the program's output to still be a terminal file")
+	}
+
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+	old := oscWriter
+	t.Cleanup(func() { oscWriter = old })
+	out := &terminalOutput{File: w}
+	oscWriter = out
+	t.Setenv("TMUX", "")
+
+	io.WriteString(out, "frame")
+	writeOSC("7;file:///tmp")
+	w.Close()
+	if data, _ := io.ReadAll(r); string(data) != "frame\x1b]7;file:///tmp\x07" {
+		t.Errorf("unexpected output %q", data)
+	}
+}
@@ -0,0 +1,254 @@
+package tui
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"dev-cli/internal/infra"
+	"dev-cli/internal/llm"
+	"dev-cli/internal/pipeline"
+	"dev-cli/internal/storage"
+	"dev-cli/internal/tui/tabs/monitor"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// demoNow anchors all synthetic timestamps; tests pin it for stable output.
+var demoNow = time.Now
+
+// DemoModel returns a Model backed entirely by synthetic data. It never talks
+// to Docker, Ollama or the history database, which makes it suitable for
+// screenshots, talks, and UI work on machines without the full stack.
+func DemoModel() Model {
+	m := NewModel(demoDocker(), demoAI())
+	m.demo = true
+	m.cwd = "/home/demo/projects/shop-api"
+	m.pipe.State().SetCwd(m.cwd)
+
+	seedDemoBlocks(m.pipe.State())
+
+	for name, stats := range demoContainerStats() {
+		m.containers = m.containers.SetContainerStats(name, stats)
+	}
+	m.containers = m.containers.SetImages(demoImages())
+
+	return m
+}
+
+func (m Model) demoInit() tea.Cmd {
+	return tea.Batch(
+		m.spinner.Tick,
+		m.checkDockerHealth,
+		func() tea.Msg { return gpuStatsMsg{stats: demoGPUStats()} },
+		func() tea.Msg { return historyLoadedMsg{history: demoHistory()} },
+		func() tea.Msg { return starshipLineMsg{line: "shop-api on  main [!?] via 🐹 v1.25.4"} },
+		loadChatModels(m.aiClient),
+	)
+}
+
+// demoDocker scripts a small compose stack on an in-memory daemon.
+func demoDocker() *infra.FakeDocker {
+	health := demoDockerHealth()
+	docker := infra.NewFakeDocker(health.Containers...)
+	docker.Version = health.Version
+	docker.Images = demoImages()
+
+	base := demoNow().Add(-2 * time.Minute)
+	lines := []string{
+		"INFO  server listening on :8080",
+		"INFO  connected to postgres at db:5432",
+		"DEBUG cache warmup complete (412 keys)",
+		"INFO  GET /api/products 200 12ms",
+		"WARN  slow query: SELECT * FROM orders (843ms)",
+		"INFO  POST /api/cart 201 31ms",
+		"ERROR payment gateway timeout after 5000ms",
+		"INFO  retrying payment request (attempt 2/3)",
+		"INFO  POST /api/checkout 200 1204ms",
+	}
+	for i := range lines {
+		lines[i] = base.Add(time.Duration(i)*9*time.Second).Format(time.RFC3339) + " " + lines[i]
+	}
+	for _, c := range health.Containers {
+		docker.Logs[c.ID] = lines
+	}
+	return docker
+}
+
+func demoAI() *llm.FakeProvider {
+	ai := llm.NewFakeProvider()
+	ai.CommitMsg = "fix(payment): raise gateway timeout to 10s"
+	ai.Models = []llm.ChatModel{
+		{Provider: llm.ProviderOllama, Name: "llama3.2:3b"},
+		{Provider: llm.ProviderPerplexity, Name: "sonar-pro"},
+	}
+	ai.Review = &llm.ReviewResult{
+		Summary: "Timeout change looks safe; consider a circuit breaker.",
+		Findings: []llm.ReviewFinding{
+			{File: "internal/payment/client.go", Line: 42, Severity: "warning", Suggestion: "Make the timeout configurable via PAYMENT_TIMEOUT."},
+		},
+	}
+	return ai
+}
+
+func demoDockerHealth() infra.DockerHealth {
+	now := demoNow()
+	return infra.DockerHealth{
+		Available: true,
+		Version:   "28.0.1",
+		Containers: []infra.ContainerInfo{
+			{ID: "a1b2c3d4e5f6", Name: "shop-api", Image: "shop-api:dev", Status: "Up 2 hours", State: "running",
+				Ports: []infra.PortMapping{{Private: 8080, Public: 8080, Protocol: "tcp"}}, Created: now.Add(-2 * time.Hour)},
+			{ID: "b2c3d4e5f6a1", Name: "postgres", Image: "postgres:16", Status: "Up 2 hours (healthy)", State: "running",
+				Ports: []infra.PortMapping{{Private: 5432, Public: 5432, Protocol: "tcp"}}, Created: now.Add(-2 * time.Hour)},
+			{ID: "c3d4e5f6a1b2", Name: "redis", Image: "redis:7-alpine", Status: "Up 2 hours", State: "running",
+				Ports: []infra.PortMapping{{Private: 6379, Public: 6379, Protocol: "tcp"}}, Created: now.Add(-2 * time.Hour)},
+			{ID: "d4e5f6a1b2c3", Name: "ollama", Image: "ollama/ollama", Status: "Up 5 days", State: "running",
+				Ports: []infra.PortMapping{{Private: 11434, Public: 11434, Protocol: "tcp"}}, Created: now.Add(-120 * time.Hour)},
+			{ID: "e5f6a1b2c3d4", Name: "worker", Image: "shop-api:dev", Status: "Exited (1) 3 minutes ago", State: "exited",
+				Created: now.Add(-time.Hour)},
+		},
+	}
+}
+
+func demoImages() []infra.ImageInfo {
+	now := demoNow()
+	return []infra.ImageInfo{
+		{ID: "sha256:1f2e3d", Tags: []string{"shop-api:dev"}, Size: 48 << 20, Created: now.Add(-3 * time.Hour)},
+		{ID: "sha256:2e3d4c", Tags: []string{"postgres:16"}, Size: 432 << 20, Created: now.Add(-240 * time.Hour)},
+		{ID: "sha256:3d4c5b", Tags: []string{"redis:7-alpine"}, Size: 41 << 20, Created: now.Add(-480 * time.Hour)},
+		{ID: "sha256:4c5b6a", Tags: []string{"ollama/ollama:latest"}, Size: 3 << 30, Created: now.Add(-720 * time.Hour)},
+	}
+}
+
+func demoContainerStats() map[string]monitor.ContainerStats {
+	return map[string]monitor.ContainerStats{
+		"shop-api": {CPUHistory: []int{12, 18, 25, 22, 40, 35, 28, 31, 45, 38}, MemUsed: 182, MemTotal: 512, NetIn: 12 << 20, NetOut: 4 << 20},
+		"postgres": {CPUHistory: []int{5, 6, 8, 30, 12, 7, 6, 9, 11, 8}, MemUsed: 256, MemTotal: 1024, NetIn: 3 << 20, NetOut: 9 << 20},
+		"redis":    {CPUHistory: []int{1, 1, 2, 1, 3, 2, 1, 1, 2, 1}, MemUsed: 14, MemTotal: 256, NetIn: 1 << 20, NetOut: 1 << 20},
+		"ollama":   {CPUHistory: []int{2, 2, 60, 85, 70, 10, 3, 2, 2, 2}, MemUsed: 2900, MemTotal: 8192, NetIn: 2 << 10, NetOut: 8 << 10},
+	}
+}
+
+func demoGPUStats() infra.GPUStats {
+	return infra.GPUStats{
+		Available:      true,
+		Vendor:         "nvidia",
+		UsedMemoryMB:   3120,
+		TotalMemoryMB:  8192,
+		UtilizationPct: 37,
+		Temperature:    58,
+	}
+}
+
+func demoHistory() []storage.HistoryItem {
+	now := demoNow()
+	entries := []struct {
+		command  string
+		exitCode int
+		ago      time.Duration
+		duration int64
+		output   string
+	}{
+		{"docker compose up -d", 0, 2 * time.Hour, 4210, "Container postgres Started\nContainer redis Started\nContainer shop-api Started"},
+		{"go test ./...", 1, 90 * time.Minute, 12840, "--- FAIL: TestCheckout (0.02s)\n    checkout_test.go:42: expected 200, got 502"},
+		{"go test ./internal/payment/...", 0, 80 * time.Minute, 3120, "ok  \tshop-api/internal/payment\t0.412s"},
+		{"npm run build", 127, time.Hour, 40, "sh: 1: npm: command not found"},
+		{"kubectl get pods -n staging", 0, 45 * time.Minute, 880, "NAME                        READY   STATUS    RESTARTS\nshop-api-7d9f8b6c4d-x2k9p   1/1     Running   0"},
+		{"psql -h localhost -U shop", 2, 20 * time.Minute, 150, "psql: error: connection to server at \"localhost\", port 5432 failed: FATAL: password authentication failed"},
+		{"git push origin feature/checkout", 0, 5 * time.Minute, 2300, "To github.com:demo/shop-api.git\n * [new branch] feature/checkout -> feature/checkout"},
+	}
+
+	items := make([]storage.HistoryItem, 0, len(entries))
+	for i := len(entries) - 1; i >= 0; i-- {
+		e := entries[i]
+		details, _ := json.Marshal(map[string]string{"output": e.output})
+		items = append(items, storage.HistoryItem{
+			ID:         int64(i + 1),
+			Timestamp:  now.Add(-e.ago),
+			Command:    e.command,
+			ExitCode:   e.exitCode,
+			DurationMs: e.duration,
+			Directory:  "/home/demo/projects/shop-api",
+			SessionID:  "demo",
+			Details:    string(details),
+		})
+	}
+	return items
+}
+
+// demoSessions is the one session the synthetic history ran in.
+func demoSessions() []storage.Session {
+	items := demoHistory()
+	s := storage.Session{
+		ID:         "demo",
+		Source:     "zsh",
+		Directory:  items[0].Directory,
+		StartedAt:  items[len(items)-1].Timestamp,
+		LastActive: items[0].Timestamp,
+		Commands:   len(items),
+	}
+	for _, item := range items {
+		if item.ExitCode != 0 {
+			s.Failed++
+		}
+	}
+	return []storage.Session{s}
+}
+
+// demoHistoryMatching is the synthetic history that passes filter.
+func demoHistoryMatching(filter storage.HistoryFilter) []storage.HistoryItem {
+	var items []storage.HistoryItem
+	for _, item := range demoHistory() {
+		if filter.Matches(item, demoNow()) {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+func seedDemoBlocks(state *pipeline.StateStore) {
+	now := demoNow()
+	cwd := state.Cwd
+
+	state.AddBlock(pipeline.Block{
+		ID:         "demo-1",
+		Type:       pipeline.BlockTypeCommand,
+		Timestamp:  now.Add(-4 * time.Minute),
+		Command:    "docker ps --format '{{.Names}}\\t{{.Status}}'",
+		Output:     "shop-api\tUp 2 hours\npostgres\tUp 2 hours (healthy)\nredis\tUp 2 hours\nollama\tUp 5 days",
+		Duration:   140 * time.Millisecond,
+		WorkingDir: cwd,
+	})
+
+	state.AddBlock(pipeline.Block{
+		ID:         "demo-2",
+		Type:       pipeline.BlockTypeCommand,
+		Timestamp:  now.Add(-3 * time.Minute),
+		Command:    "npm run build",
+		Output:     "sh: 1: npm: command not found",
+		ExitCode:   127,
+		Duration:   40 * time.Millisecond,
+		WorkingDir: cwd,
+	})
+	state.AddAnnotation(pipeline.BlockAnnotation{
+		BlockID:  "demo-2",
+		Source:   "ai",
+		Type:     "fix",
+		Severity: pipeline.SeverityWarning,
+		Text:     "Node.js is not installed in this environment",
+		Command:  "sudo apt install -y nodejs npm",
+	})
+
+	state.AddBlock(pipeline.Block{
+		ID:        "demo-3",
+		Type:      pipeline.BlockTypeAI,
+		Timestamp: now.Add(-2 * time.Minute),
+		Command:   "why is checkout returning 502?",
+		Output: fmt.Sprintf("%s\n%s\n%s",
+			"The shop-api logs show `payment gateway timeout after 5000ms`.",
+			"The upstream call exceeds the 5s client timeout during retries.",
+			"Increase PAYMENT_TIMEOUT or add a circuit breaker around the gateway client."),
+		AIAnalyzed: true,
+	})
+}
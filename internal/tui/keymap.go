@@ -6,15 +6,18 @@ import (
 )
 
 type GlobalKeyMap struct {
-	Quit   key.Binding
-	Tab    key.Binding
-	Insert key.Binding
-	Escape key.Binding
-	Up     key.Binding
-	Down   key.Binding
-	Tab1   key.Binding
-	Tab2   key.Binding
-	Tab3   key.Binding
+	Quit    key.Binding
+	Tab     key.Binding
+	Insert  key.Binding
+	Escape  key.Binding
+	Up      key.Binding
+	Down    key.Binding
+	Tab1    key.Binding
+	Tab2    key.Binding
+	Tab3    key.Binding
+	Tab4    key.Binding
+	Theme   key.Binding
+	Palette key.Binding
 }
 
 func (k GlobalKeyMap) ShortHelp() []key.Binding {
@@ -26,6 +29,7 @@ func (k GlobalKeyMap) FullHelp() [][]key.Binding {
 		{k.Tab1, k.Tab2, k.Tab3},
 		{k.Up, k.Down, k.Tab},
 		{k.Insert, k.Escape, k.Quit},
+		{k.Theme, k.Palette},
 	}
 }
 
@@ -66,6 +70,18 @@ var GlobalKeys = GlobalKeyMap{
 		key.WithKeys("3"),
 		key.WithHelp("3", "history"),
 	),
+	Tab4: key.NewBinding(
+		key.WithKeys("4"),
+		key.WithHelp("4", "kubernetes"),
+	),
+	Theme: key.NewBinding(
+		key.WithKeys("T"),
+		key.WithHelp("T", "next theme"),
+	),
+	Palette: key.NewBinding(
+		key.WithKeys("ctrl+p"),
+		key.WithHelp("Ctrl+p", "command palette"),
+	),
 }
 
 type AgentKeyMap struct {
@@ -74,6 +90,17 @@ type AgentKeyMap struct {
 	Clear    key.Binding
 	ToggleAI key.Binding
 	RunFix   key.Binding
+	Rerun    key.Binding
+	Pin      key.Binding
+	Search   key.Binding
+	Copy     key.Binding
+	Scroll   key.Binding
+	Find     key.Binding
+	Cancel   key.Binding
+	Jobs     key.Binding
+	Terminal key.Binding
+	Editor   key.Binding
+	Workflow key.Binding
 }
 
 func (k AgentKeyMap) ShortHelp() []key.Binding {
@@ -83,8 +110,10 @@ func (k AgentKeyMap) ShortHelp() []key.Binding {
 func (k AgentKeyMap) FullHelp() [][]key.Binding {
 	return [][]key.Binding{
 		{k.Tab1, k.Tab2, k.Tab3},
-		{k.Insert, k.Fold, k.Clear},
-		{k.ToggleAI, k.RunFix},
+		{k.Insert, k.Fold, k.Pin, k.Clear},
+		{k.ToggleAI, k.RunFix, k.Rerun, k.Search},
+		{k.Copy, k.Scroll, k.Find, k.Workflow},
+		{k.Cancel, k.Jobs, k.Terminal, k.Editor},
 		{k.Up, k.Down, k.Quit},
 	}
 }
@@ -107,14 +136,73 @@ var AgentKeys = AgentKeyMap{
 		key.WithKeys("r"),
 		key.WithHelp("r", "run fix"),
 	),
+	Rerun: key.NewBinding(
+		key.WithKeys("R", "E"),
+		key.WithHelp("R/E", "re-run/edit command"),
+	),
+	Pin: key.NewBinding(
+		key.WithKeys("P"),
+		key.WithHelp("P", "pin/unpin block"),
+	),
+	Search: key.NewBinding(
+		key.WithKeys("ctrl+r"),
+		key.WithHelp("Ctrl+r", "search history"),
+	),
+	Copy: key.NewBinding(
+		key.WithKeys("y", "Y"),
+		key.WithHelp("y/Y", "copy output/command"),
+	),
+	Scroll: key.NewBinding(
+		key.WithKeys("pgup", "pgdown"),
+		key.WithHelp("PgUp/PgDn gg/G", "scroll"),
+	),
+	Find: key.NewBinding(
+		key.WithKeys("/"),
+		key.WithHelp("/ n/N", "find"),
+	),
+	Cancel: key.NewBinding(
+		key.WithKeys("ctrl+c"),
+		key.WithHelp("Ctrl+c", "cancel command"),
+	),
+	Jobs: key.NewBinding(
+		key.WithKeys("b", "B"),
+		key.WithHelp("& b/B", "jobs"),
+	),
+	Terminal: key.NewBinding(
+		key.WithKeys("ctrl+o"),
+		key.WithHelp("Ctrl+o", "run on full terminal"),
+	),
+	Editor: key.NewBinding(
+		key.WithKeys("ctrl+e"),
+		key.WithHelp("Ctrl+e", "multi-line editor"),
+	),
+	Workflow: key.NewBinding(
+		key.WithKeys("m", "W"),
+		key.WithHelp("m/W", "mark/draft workflow"),
+	),
 }
 
 type MonitorKeyMap struct {
 	GlobalKeyMap
 	Follow     key.Binding
 	LogLevel   key.Binding
+	Search     key.Binding
 	Actions    key.Binding
+	Exec       key.Binding
+	Pull       key.Binding
+	Run        key.Binding
+	Merge      key.Binding
+	Bulk       key.Binding
+	Files      key.Binding
+	Volumes    key.Binding
+	Layers     key.Binding
+	Prune      key.Binding
+	Inspect    key.Binding
+	Processes  key.Binding
 	ToggleWrap key.Binding
+	Timestamps key.Binding
+	Zoom       key.Binding
+	Drawer     key.Binding
 }
 
 func (k MonitorKeyMap) ShortHelp() []key.Binding {
@@ -124,9 +212,9 @@ func (k MonitorKeyMap) ShortHelp() []key.Binding {
 func (k MonitorKeyMap) FullHelp() [][]key.Binding {
 	return [][]key.Binding{
 		{k.Tab1, k.Tab2, k.Tab3},
-		{k.Up, k.Down, k.Tab},
-		{k.Follow, k.LogLevel, k.ToggleWrap},
-		{k.Actions, k.Quit},
+		{k.Up, k.Down, k.Tab, k.Zoom, k.Drawer},
+		{k.Follow, k.LogLevel, k.Search, k.Merge, k.Bulk, k.ToggleWrap, k.Timestamps},
+		{k.Actions, k.Inspect, k.Processes, k.Exec, k.Files, k.Pull, k.Layers, k.Run, k.Volumes, k.Prune, k.Quit},
 	}
 }
 
@@ -140,19 +228,83 @@ var MonitorKeys = MonitorKeyMap{
 		key.WithKeys("l"),
 		key.WithHelp("L", "filter"),
 	),
+	Search: key.NewBinding(
+		key.WithKeys("/"),
+		key.WithHelp("/ n/N", "search logs"),
+	),
 	Actions: key.NewBinding(
 		key.WithKeys("a", "enter"),
 		key.WithHelp("a", "actions"),
 	),
+	Exec: key.NewBinding(
+		key.WithKeys("e"),
+		key.WithHelp("e", "exec shell"),
+	),
+	Pull: key.NewBinding(
+		key.WithKeys("P"),
+		key.WithHelp("P", "pull image"),
+	),
+	Run: key.NewBinding(
+		key.WithKeys("n"),
+		key.WithHelp("n", "run container"),
+	),
+	Merge: key.NewBinding(
+		key.WithKeys(" "),
+		key.WithHelp("space", "mark (merge logs)"),
+	),
+	Files: key.NewBinding(
+		key.WithKeys("b"),
+		key.WithHelp("b", "browse files"),
+	),
+	Bulk: key.NewBinding(
+		key.WithKeys("x", "r", "d"),
+		key.WithHelp("x/r/d", "stop/restart/remove marked"),
+	),
+	Volumes: key.NewBinding(
+		key.WithKeys("b", "r", "d"),
+		key.WithHelp("b/r/d", "backup/restore/remove volume"),
+	),
+	Layers: key.NewBinding(
+		key.WithKeys("enter"),
+		key.WithHelp("Enter", "image layers"),
+	),
+	Prune: key.NewBinding(
+		key.WithKeys("p"),
+		key.WithHelp("p", "prune disk"),
+	),
+	Inspect: key.NewBinding(
+		key.WithKeys("i"),
+		key.WithHelp("i", "inspect/limits"),
+	),
+	Processes: key.NewBinding(
+		key.WithKeys("t"),
+		key.WithHelp("t", "processes"),
+	),
 	ToggleWrap: key.NewBinding(
 		key.WithKeys("ctrl+w"),
 		key.WithHelp("Ctrl+w", "wrap"),
 	),
+	Timestamps: key.NewBinding(
+		key.WithKeys("ctrl+t"),
+		key.WithHelp("Ctrl+t", "timestamps"),
+	),
+	Zoom: key.NewBinding(
+		key.WithKeys("Z"),
+		key.WithHelp("Z", "zoom panel"),
+	),
+	Drawer: key.NewBinding(
+		key.WithKeys("S"),
+		key.WithHelp("S", "sidebar (narrow)"),
+	),
 }
 
 type HistoryKeyMap struct {
 	GlobalKeyMap
-	Details key.Binding
+	Details  key.Binding
+	Filters  key.Binding
+	Stats    key.Binding
+	Sessions key.Binding
+	Workflow key.Binding
 }
 
 func (k HistoryKeyMap) ShortHelp() []key.Binding {
@@ -163,7 +315,8 @@ func (k HistoryKeyMap) FullHelp() [][]key.Binding {
 	return [][]key.Binding{
 		{k.Tab1, k.Tab2, k.Tab3},
 		{k.Up, k.Down, k.Details},
-		{k.Tab, k.Quit},
+		{k.Filters, k.Stats, k.Sessions},
+		{k.Workflow, k.Tab, k.Quit},
 	}
 }
 
@@ -173,6 +326,139 @@ var HistoryKeys = HistoryKeyMap{
 		key.WithKeys("enter"),
 		key.WithHelp("Enter", "details"),
 	),
+	Filters: key.NewBinding(
+		key.WithKeys("e", "d", "p", "s", "t", "c"),
+		key.WithHelp("e/d/p/s/t/c", "filter exit/dir/project/session/time, clear"),
+	),
+	Stats: key.NewBinding(
+		key.WithKeys("v"),
+		key.WithHelp("v", "stats"),
+	),
+	Sessions: key.NewBinding(
+		key.WithKeys("S"),
+		key.WithHelp("S", "sessions (R replays one)"),
+	),
+	Workflow: key.NewBinding(
+		key.WithKeys("m", "W"),
+		key.WithHelp("m/W", "mark/draft workflow"),
+	),
+}
+
+type KubeKeyMap struct {
+	GlobalKeyMap
+	Top key.Binding
+}
+
+func (k KubeKeyMap) ShortHelp() []key.Binding {
+	return []key.Binding{k.Up, k.Down, k.Tab, k.Top, k.Quit}
+}
+
+func (k KubeKeyMap) FullHelp() [][]key.Binding {
+	return [][]key.Binding{
+		{k.Tab1, k.Tab2, k.Tab3, k.Tab4},
+		{k.Up, k.Down, k.Tab, k.Top},
+		{k.Quit},
+	}
+}
+
+var KubeKeys = KubeKeyMap{
+	GlobalKeyMap: GlobalKeys,
+	Top: key.NewBinding(
+		key.WithKeys("g", "G"),
+		key.WithHelp("g/G", "top/bottom"),
+	),
+}
+
+type RunbookKeyMap struct {
+	GlobalKeyMap
+	Steps   key.Binding
+	Project key.Binding
+	Run     key.Binding
+	Answer  key.Binding
+	Edit    key.Binding
+}
+
+func (k RunbookKeyMap) ShortHelp() []key.Binding {
+	return []key.Binding{k.Up, k.Down, k.Steps, k.Run, k.Quit}
+}
+
+func (k RunbookKeyMap) FullHelp() [][]key.Binding {
+	return [][]key.Binding{
+		{k.Tab1, k.Tab2, k.Tab3},
+		{k.Up, k.Down, k.Steps, k.Project},
+		{k.Run, k.Answer, k.Edit},
+		{k.Tab, k.Quit},
+	}
+}
+
+var RunbookKeys = RunbookKeyMap{
+	GlobalKeyMap: GlobalKeys,
+	Steps: key.NewBinding(
+		key.WithKeys("enter"),
+		key.WithHelp("Enter", "steps"),
+	),
+	Project: key.NewBinding(
+		key.WithKeys("p"),
+		key.WithHelp("p", "project"),
+	),
+	Run: key.NewBinding(
+		key.WithKeys("r"),
+		key.WithHelp("r", "run"),
+	),
+	Answer: key.NewBinding(
+		key.WithKeys("y", "n"),
+		key.WithHelp("y/n/esc", "run/skip step, abort"),
+	),
+	Edit: key.NewBinding(
+		key.WithKeys("e", "a", "x"),
+		key.WithHelp("e/a/x", "edit/add/delete step"),
+	),
+}
+
+type ChatKeyMap struct {
+	GlobalKeyMap
+	Insert key.Binding
+	Send   key.Binding
+	Scroll key.Binding
+	Model  key.Binding
+	New    key.Binding
+}
+
+func (k ChatKeyMap) ShortHelp() []key.Binding {
+	return []key.Binding{k.Insert, k.Send, k.Model, k.New, k.Quit}
+}
+
+func (k ChatKeyMap) FullHelp() [][]key.Binding {
+	return [][]key.Binding{
+		{k.Tab1, k.Tab2, k.Tab3},
+		{k.Insert, k.Send, k.Scroll},
+		{k.Model, k.New},
+		{k.Tab, k.Quit},
+	}
+}
+
+var ChatKeys = ChatKeyMap{
+	GlobalKeyMap: GlobalKeys,
+	Insert: key.NewBinding(
+		key.WithKeys("i"),
+		key.WithHelp("i", "type"),
+	),
+	Send: key.NewBinding(
+		key.WithKeys("enter"),
+		key.WithHelp("Enter/Alt+Enter", "send/new line"),
+	),
+	Scroll: key.NewBinding(
+		key.WithKeys("j", "k"),
+		key.WithHelp("j/k/g/G", "scroll"),
+	),
+	Model: key.NewBinding(
+		key.WithKeys("m"),
+		key.WithHelp("m", "model"),
+	),
+	New: key.NewBinding(
+		key.WithKeys("n"),
+		key.WithHelp("n", "new chat"),
+	),
 }
 
 func NewHelp() help.Model {
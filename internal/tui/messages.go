@@ -3,8 +3,12 @@ package tui
 import (
 	"database/sql"
 
+	"dev-cli/internal/executor"
 	"dev-cli/internal/infra"
+	"dev-cli/internal/infra/kube"
+	"dev-cli/internal/llm"
 	"dev-cli/internal/storage"
+	"dev-cli/internal/workflow"
 )
 
 type dockerHealthMsg struct {
@@ -15,6 +19,11 @@ type gpuStatsMsg struct {
 	stats infra.GPUStats
 }
 
+type hostStatsMsg struct {
+	stats infra.HostStats
+	err   error
+}
+
 type serviceHealthMsg struct {
 	services []infra.ServiceStatus
 }
@@ -24,3 +33,305 @@ type historyLoadedMsg struct {
 	db      *sql.DB
 	err     error
 }
+
+// historyFilteredMsg carries the history matching filter.
+type historyFilteredMsg struct {
+	filter  storage.HistoryFilter
+	history []storage.HistoryItem
+	err     error
+}
+
+// historyStatsMsg carries the stats of the history matching filter.
+type historyStatsMsg struct {
+	filter storage.HistoryFilter
+	stats  storage.HistoryStats
+	err    error
+}
+
+type completionsLoadedMsg struct {
+	history     []storage.CommandFrequency
+	executables []string
+}
+
+type searchHistoryLoadedMsg struct {
+	commands []string
+}
+
+type loadingTimeoutMsg struct{}
+
+type projectActionDoneMsg struct {
+	project string
+	err     error
+}
+
+type containerExecDoneMsg struct {
+	containerID string
+	err         error
+}
+
+type containerActionDoneMsg struct {
+	action      string
+	containerID string
+	err         error
+}
+
+type interactiveDoneMsg struct {
+	result executor.Result
+	err    error
+}
+
+type statsStreamMsg struct {
+	containerID string
+	name        string
+	ch          <-chan infra.ContainerStatsSnapshot
+	err         error
+}
+
+type containerStatsMsg struct {
+	name  string
+	ch    <-chan infra.ContainerStatsSnapshot
+	stats infra.ContainerStatsSnapshot
+	ok    bool
+}
+
+type logStreamMsg struct {
+	// containerID is the stream key from watchLogs: one ID, or several
+	// when logs are merged.
+	containerID string
+	ch          <-chan string
+	err         error
+}
+
+type logLinesMsg struct {
+	ch    <-chan string
+	lines []string
+	ok    bool
+}
+
+type pullProgressMsg struct {
+	ch       <-chan pullProgressMsg
+	progress infra.PullProgress
+	done     bool
+	err      error
+}
+
+type imagesMsg struct {
+	images []infra.ImageInfo
+	err    error
+}
+
+type containerInspectedMsg struct {
+	containerID string
+	detail      *infra.ContainerDetail
+	err         error
+}
+
+type processesMsg struct {
+	containerID string
+	procs       []infra.ProcessInfo
+	err         error
+}
+
+type processSignaledMsg struct {
+	containerID string
+	pid         string
+	signal      string
+	err         error
+}
+
+// bulkActionDoneMsg reports an action on several containers: verb names
+// it ("Stopping"), errs are the containers it failed on.
+type bulkActionDoneMsg struct {
+	verb  string
+	total int
+	errs  []error
+}
+
+// logAnalysisMsg carries the AI's analysis of the visible logs.
+type logAnalysisMsg struct {
+	result *llm.LogAnalysisResult
+	err    error
+}
+
+type resourcesUpdatedMsg struct {
+	err error
+}
+
+type imageRemovedMsg struct {
+	image string
+	err   error
+}
+
+type imageHistoryMsg struct {
+	image  string
+	layers []infra.ImageLayer
+	err    error
+}
+
+type volumesMsg struct {
+	volumes []infra.VolumeInfo
+	err     error
+}
+
+type diskUsageMsg struct {
+	usage infra.DiskUsage
+	err   error
+}
+
+type pruneDoneMsg struct {
+	reclaimed uint64
+	err       error
+}
+
+type backupsMsg struct {
+	volume  string
+	backups []infra.VolumeBackup
+	err     error
+}
+
+type volumeOpMsg struct {
+	status string
+	err    error
+}
+
+type containerRunMsg struct {
+	containerID string
+	err         error
+}
+
+type dirListedMsg struct {
+	containerID string
+	path        string
+	entries     []infra.ContainerFile
+	err         error
+}
+
+type fileCopiedMsg struct {
+	status string
+	err    error
+}
+
+type clipboardCopiedMsg struct {
+	what string
+	err  error
+}
+
+type kubeHealthMsg struct {
+	health kube.Health
+}
+
+type podLogsMsg struct {
+	pod   string
+	lines []string
+	err   error
+}
+
+// runbooksLoadedMsg carries the runbooks stored in the history database.
+type runbooksLoadedMsg struct {
+	runbooks []storage.Runbook
+	err      error
+}
+
+type runbookSavedMsg struct {
+	err error
+}
+
+// bookmarksLoadedMsg carries the Agent blocks pinned in earlier sessions.
+type bookmarksLoadedMsg struct {
+	bookmarks []storage.Bookmark
+	err       error
+}
+
+// bookmarkSavedMsg reports the ID a pinned block was stored under.
+type bookmarkSavedMsg struct {
+	blockID string
+	id      int64
+	err     error
+}
+
+// runbookProgressMsg reports on a runbook run: a step the engine asks to
+// confirm on reply, a step that ended, or, with done set, the whole run.
+type runbookProgressMsg struct {
+	ch <-chan runbookProgressMsg
+	// name is the runbook's.
+	name string
+
+	asking string
+	reply  chan<- bool
+
+	stepID   string
+	status   workflow.StepStatus
+	exitCode int
+
+	done   bool
+	result *workflow.RunResult
+	err    error
+}
+
+// workflowProgressMsg reports on a workflow run started from the palette:
+// a step starting (status running) or ending, a line it wrote, a
+// notification that couldn't be sent, or, with done set, the whole run.
+type workflowProgressMsg struct {
+	ch <-chan workflowProgressMsg
+
+	// stepID is the step's ID, a matrix combination's with the
+	// combination in brackets, and name its name.
+	stepID string
+	name   string
+	step   *workflow.StepResult
+
+	output bool
+	line   string
+
+	warning error
+
+	done   bool
+	result *workflow.RunResult
+	err    error
+}
+
+// chatModelsMsg carries the models the Chat tab can talk to; err explains
+// the ones missing.
+type chatModelsMsg struct {
+	models []llm.ChatModel
+	err    error
+}
+
+// chatStreamMsg carries a chunk of a chat reply, or with done set the
+// whole reply.
+type chatStreamMsg struct {
+	ch <-chan chatStreamMsg
+
+	delta string
+
+	done  bool
+	reply string
+	err   error
+}
+
+// toastExpiredMsg takes a notification's toast down.
+type toastExpiredMsg struct {
+	id int
+}
+
+// sessionsLoadedMsg carries the stored sessions, newest first.
+type sessionsLoadedMsg struct {
+	sessions []storage.Session
+	err      error
+}
+
+// sessionReplayMsg carries the commands of a session to replay, oldest
+// first.
+type sessionReplayMsg struct {
+	sessionID string
+	history   []storage.HistoryItem
+	err       error
+}
+
+// workflowDraftedMsg reports where a workflow drafted from history or
+// Agent blocks was saved.
+type workflowDraftedMsg struct {
+	path  string
+	steps int
+	err   error
+}
@@ -0,0 +1,83 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"dev-cli/internal/infra"
+	"dev-cli/internal/tui/components"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// notify shows text as a toast until components.ToastDuration passes,
+// and keeps it in the notification log.
+func (m Model) notify(level components.NotificationLevel, text string) (Model, tea.Cmd) {
+	var id int
+	m.notifications, id = m.notifications.Push(level, text, time.Now())
+	return m, tea.Tick(components.ToastDuration, func(time.Time) tea.Msg { return toastExpiredMsg{id: id} })
+}
+
+// updateNotifications handles keys while the notification log is pulled
+// down: it closes on Esc or Ctrl+G, and c clears it.
+func (m Model) updateNotifications(msg tea.KeyMsg) (Model, tea.Cmd) {
+	switch msg.String() {
+	case "esc", "ctrl+g", "q":
+		m.notifications = m.notifications.Toggle()
+	case "c":
+		m.notifications = m.notifications.Clear()
+	}
+	return m, nil
+}
+
+// containerChanges describes how the containers changed between two
+// health checks: started, stopped, removed, or turned (un)healthy.
+func containerChanges(prev, next []infra.ContainerInfo) []components.Notification {
+	before := make(map[string]infra.ContainerInfo, len(prev))
+	for _, c := range prev {
+		before[c.ID] = c
+	}
+
+	var changes []components.Notification
+	add := func(level components.NotificationLevel, format string, args ...any) {
+		changes = append(changes, components.Notification{Level: level, Text: fmt.Sprintf(format, args...)})
+	}
+	for _, c := range next {
+		old, ok := before[c.ID]
+		delete(before, c.ID)
+		switch {
+		case !ok && c.State == "running":
+			add(components.NotifySuccess, "%s started", c.Name)
+		case !ok:
+			add(components.NotifyInfo, "%s created", c.Name)
+		case old.State != c.State:
+			switch c.State {
+			case "running":
+				add(components.NotifySuccess, "%s started", c.Name)
+			case "exited":
+				if strings.HasPrefix(c.Status, "Exited (0)") {
+					add(components.NotifyInfo, "%s stopped", c.Name)
+				} else {
+					add(components.NotifyError, "%s exited: %s", c.Name, c.Status)
+				}
+			case "restarting":
+				add(components.NotifyWarning, "%s is restarting", c.Name)
+			case "dead":
+				add(components.NotifyError, "%s is dead", c.Name)
+			default:
+				add(components.NotifyInfo, "%s is %s", c.Name, c.State)
+			}
+		case old.Health != c.Health && c.Health == "unhealthy":
+			add(components.NotifyError, "%s is unhealthy", c.Name)
+		case old.Health == "unhealthy" && c.Health == "healthy":
+			add(components.NotifySuccess, "%s is healthy again", c.Name)
+		}
+	}
+	for _, c := range prev {
+		if _, gone := before[c.ID]; gone {
+			add(components.NotifyInfo, "%s removed", c.Name)
+		}
+	}
+	return changes
+}
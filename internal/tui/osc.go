@@ -0,0 +1,93 @@
+package tui
+
+import (
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// longCommandThreshold is how long a command must run before we send a
+// desktop notification for it when the terminal is unfocused.
+const longCommandThreshold = 10 * time.Second
+
+// terminalOutput is the program's output. The renderer writes each frame
+// in one call, and OSC sequences go through the same lock, so a sequence a
+// command sends from its goroutine never lands inside a frame. It is still
+// the terminal's file, so Bubble Tea sees a TTY.
+type terminalOutput struct {
+	mu sync.Mutex
+	*os.File
+}
+
+func (o *terminalOutput) Write(p []byte) (int, error) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	return o.File.Write(p)
+}
+
+// WriteString keeps io.WriteString from going around the lock to the file.
+func (o *terminalOutput) WriteString(s string) (int, error) {
+	return o.Write([]byte(s))
+}
+
+var stdout = &terminalOutput{File: os.Stdout}
+
+var oscWriter io.Writer = stdout
+
+// writeOSC emits an operating system command sequence, wrapping it in a DCS
+// passthrough when running inside tmux so the outer terminal still sees it.
+func writeOSC(payload string) {
+	seq := "\x1b]" + payload + "\x07"
+	if os.Getenv("TMUX") != "" {
+		seq = "\x1bPtmux;" + strings.ReplaceAll(seq, "\x1b", "\x1b\x1b") + "\x1b\\"
+	}
+	fmt.Fprint(oscWriter, seq)
+}
+
+// notifyCmd sends a desktop notification using both OSC 9 (iTerm2, WezTerm,
+// Windows Terminal) and OSC 777 (rxvt, foot, Ghostty).
+func notifyCmd(title, body string) tea.Cmd {
+	return func() tea.Msg {
+		body = sanitizeOSC(body)
+		writeOSC("9;" + body)
+		writeOSC("777;notify;" + sanitizeOSC(title) + ";" + body)
+		return nil
+	}
+}
+
+// reportCwdCmd emits OSC 7 so terminals and multiplexers can track the
+// working directory dev-cli is operating in.
+func reportCwdCmd(cwd string) tea.Cmd {
+	return func() tea.Msg {
+		if cwd == "" {
+			return nil
+		}
+		host, _ := os.Hostname()
+		u := url.URL{Scheme: "file", Host: host, Path: cwd}
+		writeOSC("7;" + u.String())
+		return nil
+	}
+}
+
+func sanitizeOSC(s string) string {
+	s = strings.Map(func(r rune) rune {
+		if r < 0x20 || r == 0x7f {
+			return ' '
+		}
+		return r
+	}, s)
+	return strings.ReplaceAll(s, ";", ",")
+}
+
+func (m Model) windowTitle() string {
+	if cmd := m.agent.RunningCommand(); cmd != "" {
+		return "dev-cli: " + cmd
+	}
+	return "dev-cli: " + m.getFocusLabel()
+}
@@ -3,26 +3,13 @@ package tui
 import (
 	"fmt"
 
+	"dev-cli/internal/tui/theme"
+
 	"github.com/charmbracelet/bubbles/viewport"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
 )
 
-var (
-	pagerTitleStyle = lipgloss.NewStyle().
-			Bold(true).
-			Foreground(lipgloss.Color("#11111b")).
-			Background(lipgloss.Color("#cba6f7")).
-			Padding(0, 1)
-
-	pagerHelpStyle = lipgloss.NewStyle().
-			Foreground(lipgloss.Color("#6c7086"))
-
-	pagerBorderStyle = lipgloss.NewStyle().
-				Border(lipgloss.RoundedBorder()).
-				BorderForeground(lipgloss.Color("#585b70"))
-)
-
 type PagerModel struct {
 	viewport viewport.Model
 	title    string
@@ -85,18 +72,18 @@ func (m PagerModel) View() string {
 		return "Initializing..."
 	}
 
-	title := pagerTitleStyle.Render(m.title)
+	title := theme.Title.Render(m.title)
 
 	scrollPercent := int(m.viewport.ScrollPercent() * 100)
-	scrollInfo := pagerHelpStyle.Render(" ↑/↓ j/k scroll • g/G top/bottom • q quit ")
+	scrollInfo := theme.Dim.Render(" ↑/↓ j/k scroll • g/G top/bottom • q quit ")
 	percent := lipgloss.NewStyle().
-		Foreground(lipgloss.Color("#b4befe")).
+		Foreground(theme.Lavender).
 		Render(fmt.Sprintf(" %d%% ", scrollPercent))
 
 	header := lipgloss.JoinHorizontal(lipgloss.Center, title, "  ", percent)
 	footer := scrollInfo
 
-	content := pagerBorderStyle.Width(m.width - 2).Render(m.viewport.View())
+	content := lipgloss.NewStyle().Border(lipgloss.RoundedBorder()).BorderForeground(theme.Surface2).Width(m.width - 2).Render(m.viewport.View())
 
 	return lipgloss.JoinVertical(lipgloss.Left,
 		header,
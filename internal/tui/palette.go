@@ -0,0 +1,348 @@
+package tui
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+
+	"dev-cli/internal/infra"
+	"dev-cli/internal/tui/tabs/monitor"
+	"dev-cli/internal/tui/theme"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/sahilm/fuzzy"
+)
+
+// paletteAction is one entry of the command palette, with the key that
+// does the same where there is one.
+type paletteAction struct {
+	group string
+	title string
+	key   string
+	run   func(m Model) (Model, tea.Cmd)
+}
+
+func (a paletteAction) String() string {
+	return a.group + " " + a.title
+}
+
+// commandPalette is the Ctrl+P overlay: every action of every tab, fuzzy
+// matched against the query, so nothing needs its key remembered.
+type commandPalette struct {
+	input   textinput.Model
+	actions []paletteAction
+	matches fuzzy.Matches
+	cursor  int
+}
+
+func newCommandPalette(actions []paletteAction) *commandPalette {
+	ti := textinput.New()
+	ti.Prompt = "> "
+	ti.PromptStyle = lipgloss.NewStyle().Foreground(theme.Mauve).Bold(true)
+	ti.Placeholder = "type an action"
+	ti.Focus()
+	p := &commandPalette{input: ti, actions: actions}
+	p.refilter()
+	return p
+}
+
+func (p *commandPalette) refilter() {
+	names := make([]string, len(p.actions))
+	for i, a := range p.actions {
+		names[i] = a.String()
+	}
+	if query := p.input.Value(); query != "" {
+		p.matches = fuzzy.Find(query, names)
+	} else {
+		p.matches = make(fuzzy.Matches, len(names))
+		for i, name := range names {
+			p.matches[i] = fuzzy.Match{Str: name, Index: i}
+		}
+	}
+	p.cursor = min(p.cursor, max(len(p.matches)-1, 0))
+}
+
+// selected is the highlighted action, nil without a match.
+func (p *commandPalette) selected() *paletteAction {
+	if p.cursor < len(p.matches) {
+		return &p.actions[p.matches[p.cursor].Index]
+	}
+	return nil
+}
+
+// updatePalette handles a key while the palette is open: Enter runs the
+// highlighted action, Esc (or Ctrl+P again) closes it.
+func (m Model) updatePalette(msg tea.KeyMsg) (Model, tea.Cmd) {
+	palette := *m.palette
+	switch msg.String() {
+	case "esc", "ctrl+p":
+		m.palette = nil
+		m.mode = m.getModeFromTab()
+		return m, nil
+	case "enter":
+		m.palette = nil
+		m.mode = m.getModeFromTab()
+		if action := palette.selected(); action != nil {
+			return action.run(m)
+		}
+		return m, nil
+	case "up", "ctrl+k":
+		palette.cursor = max(palette.cursor-1, 0)
+	case "down", "ctrl+j":
+		palette.cursor = min(palette.cursor+1, max(len(palette.matches)-1, 0))
+	default:
+		var cmd tea.Cmd
+		before := palette.input.Value()
+		palette.input, cmd = palette.input.Update(msg)
+		if palette.input.Value() != before {
+			palette.cursor = 0
+			palette.refilter()
+		}
+		m.palette = &palette
+		return m, cmd
+	}
+	m.palette = &palette
+	return m, nil
+}
+
+// view renders the palette with the best match on top.
+func (p *commandPalette) view(width, height int) string {
+	headerStyle := lipgloss.NewStyle().Foreground(theme.Lavender).Bold(true)
+	dimStyle := lipgloss.NewStyle().Foreground(theme.Overlay0)
+	textStyle := lipgloss.NewStyle().Foreground(theme.Text)
+	hitStyle := lipgloss.NewStyle().Foreground(theme.Peach).Bold(true)
+	selectedStyle := lipgloss.NewStyle().Background(theme.Surface1).Foreground(theme.Lavender).Bold(true)
+
+	// Header, query, help and the border take six of the lines.
+	rows := max(height-6, 1)
+	start := max(p.cursor-rows+1, 0)
+	end := min(start+rows, len(p.matches))
+
+	// Without a width the input shows only the placeholder's first letter.
+	input := p.input
+	input.Width = width - 8
+	lines := []string{headerStyle.Render("⌘ Actions"), input.View()}
+	for i := start; i < end; i++ {
+		match := p.matches[i]
+		action := p.actions[match.Index]
+		keyHint := dimStyle.Render(action.key)
+		labelWidth := width - 6 - lipgloss.Width(keyHint)
+
+		if i == p.cursor {
+			label := truncate(action.String(), labelWidth)
+			gap := strings.Repeat(" ", max(labelWidth-lipgloss.Width(label), 1))
+			lines = append(lines, selectedStyle.Width(width-4).Render("▌ "+label+gap+action.key))
+			continue
+		}
+
+		var b strings.Builder
+		for j, r := range []rune(truncate(action.String(), labelWidth)) {
+			switch {
+			case slices.Contains(match.MatchedIndexes, j):
+				b.WriteString(hitStyle.Render(string(r)))
+			case j < len([]rune(action.group)):
+				b.WriteString(dimStyle.Render(string(r)))
+			default:
+				b.WriteString(textStyle.Render(string(r)))
+			}
+		}
+		gap := strings.Repeat(" ", max(labelWidth-lipgloss.Width(b.String()), 1))
+		lines = append(lines, "  "+b.String()+gap+keyHint)
+	}
+	if len(p.matches) == 0 {
+		lines = append(lines, dimStyle.Render("  no matching action"))
+	}
+	lines = append(lines, dimStyle.Render("Enter run • ↑/↓ move • Esc close"))
+
+	return lipgloss.NewStyle().
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(theme.Mauve).
+		Padding(0, 1).
+		Width(width).
+		Render(strings.Join(lines, "\n"))
+}
+
+// paletteActions lists what can be done right now, tab by tab.
+func (m Model) paletteActions() []paletteAction {
+	var actions []paletteAction
+	items := m.tabItems()
+	for i := range m.tabs() {
+		key := strconv.Itoa(i + 1)
+		actions = append(actions, paletteAction{group: "Go", title: items[i].Label, key: key, run: press(m.activeTab, key)})
+	}
+	actions = append(actions, paletteAction{group: "Go", title: "Notifications", key: "ctrl+g", run: press(m.activeTab, "ctrl+g")})
+
+	if m.showsTab(TabAgent) {
+		actions = append(actions,
+			paletteAction{group: "Agent", title: "Search command history", key: "ctrl+r", run: press(TabAgent, "ctrl+r")},
+			paletteAction{group: "Agent", title: "Find in blocks", key: "/", run: press(TabAgent, "/")},
+			paletteAction{group: "Agent", title: "Clear blocks", key: "ctrl+l", run: press(TabAgent, "ctrl+l")},
+			paletteAction{group: "Agent", title: "Run doctor", run: runInAgent("dev-cli doctor")},
+			paletteAction{group: "Agent", title: "Draft a workflow from the marked blocks", key: "W", run: press(TabAgent, "W")},
+		)
+		for _, wf := range workflowFiles() {
+			name := strings.TrimSuffix(filepath.Base(wf), filepath.Ext(wf))
+			actions = append(actions, paletteAction{group: "Agent", title: "Run workflow " + name, run: runWorkflowFile(wf)})
+		}
+		if m.workflowRun != nil && !m.workflowOpen {
+			actions = append(actions, paletteAction{group: "Agent", title: "Show the run of workflow " + m.workflowRun.name, run: showWorkflowRun})
+		}
+		var running, finished bool
+		for _, job := range m.pipe.State().GetJobs() {
+			running = running || job.Running
+			finished = finished || !job.Running
+		}
+		if finished {
+			actions = append(actions, paletteAction{group: "Agent", title: "Bring back finished jobs", key: "b", run: press(TabAgent, "b")})
+		}
+		if running {
+			actions = append(actions, paletteAction{group: "Agent", title: "Stop the newest job", key: "B", run: press(TabAgent, "B")})
+		}
+	}
+
+	if m.showsTab(TabHistory) {
+		actions = append(actions,
+			paletteAction{group: "History", title: "Show failed, succeeded or all commands", key: "e", run: press(TabHistory, "e")},
+			paletteAction{group: "History", title: "Only the selected command's directory", key: "d", run: press(TabHistory, "d")},
+			paletteAction{group: "History", title: "Only this project", key: "p", run: press(TabHistory, "p")},
+			paletteAction{group: "History", title: "Only the selected command's session", key: "s", run: press(TabHistory, "s")},
+			paletteAction{group: "History", title: "Change the time range", key: "t", run: press(TabHistory, "t")},
+			paletteAction{group: "History", title: "Toggle stats", key: "v", run: press(TabHistory, "v")},
+			paletteAction{group: "History", title: "Sessions: list and replay them", key: "S", run: press(TabHistory, "S")},
+			paletteAction{group: "History", title: "Draft a workflow from the marked commands", key: "W", run: press(TabHistory, "W")},
+		)
+		if m.history.Filtered() {
+			actions = append(actions, paletteAction{group: "History", title: "Clear filters", key: "c", run: press(TabHistory, "c")})
+		}
+	}
+
+	if rb := m.runbooks.SelectedRunbook(); rb != nil && !m.runbooks.Running() && m.showsTab(TabRunbooks) {
+		actions = append(actions,
+			paletteAction{group: "Runbooks", title: "Run " + rb.Name, key: "r", run: press(TabRunbooks, "r")},
+			paletteAction{group: "Runbooks", title: "Show another project", key: "p", run: press(TabRunbooks, "p")},
+		)
+	}
+
+	if !m.chat.Streaming() && m.showsTab(TabChat) {
+		actions = append(actions,
+			paletteAction{group: "Chat", title: "Switch the chat model", key: "m", run: press(TabChat, "m")},
+			paletteAction{group: "Chat", title: "New chat", key: "n", run: press(TabChat, "n")},
+		)
+	}
+
+	if m.pipe.State().DockerHealth.Available && m.showsTab(TabContainers) {
+		recording := "Start recording logs of the selected service"
+		if m.containers.IsRecording() {
+			recording = "Stop recording logs"
+		}
+		actions = append(actions,
+			paletteAction{group: "Containers", title: recording, key: "R", run: press(TabContainers, "R")},
+			paletteAction{group: "Containers", title: "Run a new container", key: "n", run: press(TabContainers, "n")},
+		)
+		for _, c := range m.pipe.State().DockerHealth.Containers {
+			if c.State == "running" {
+				actions = append(actions,
+					paletteAction{group: "Containers", title: "Restart " + c.Name, run: containerAction("restart", c.ID)},
+					paletteAction{group: "Containers", title: "Stop " + c.Name, run: containerAction("stop", c.ID)},
+					paletteAction{group: "Containers", title: "Open a shell in " + c.Name, run: execIn(c.ID)},
+					paletteAction{group: "Containers", title: "Show the processes of " + c.Name, run: showProcesses(c)},
+				)
+			} else {
+				actions = append(actions, paletteAction{group: "Containers", title: "Start " + c.Name, run: containerAction("start", c.ID)})
+			}
+		}
+	}
+
+	return append(actions,
+		paletteAction{group: "App", title: "Next theme", key: "T", run: press(m.activeTab, "T")},
+		paletteAction{group: "App", title: "Quit", key: "q", run: press(m.activeTab, "q")},
+	)
+}
+
+// press does what key does on tab, in normal mode, so an action from the
+// palette behaves exactly like its shortcut.
+func press(tab Tab, key string) func(Model) (Model, tea.Cmd) {
+	return func(m Model) (Model, tea.Cmd) {
+		m.activeTab = tab
+		if tab == TabAgent {
+			m.agent = m.agent.SetInsertMode(false)
+		}
+		m.mode = m.getModeFromTab()
+		next, cmd := m.Update(paletteKey(key))
+		return next.(Model), cmd
+	}
+}
+
+// paletteKey turns one of the keys the palette presses into its message.
+func paletteKey(key string) tea.KeyMsg {
+	switch key {
+	case "ctrl+l":
+		return tea.KeyMsg{Type: tea.KeyCtrlL}
+	case "ctrl+r":
+		return tea.KeyMsg{Type: tea.KeyCtrlR}
+	case "ctrl+g":
+		return tea.KeyMsg{Type: tea.KeyCtrlG}
+	}
+	return tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(key)}
+}
+
+// runInAgent runs command in a new block of the Agent tab.
+func runInAgent(command string) func(Model) (Model, tea.Cmd) {
+	return func(m Model) (Model, tea.Cmd) {
+		m.activeTab = TabAgent
+		var cmd tea.Cmd
+		m.agent, cmd = m.agent.Run(command)
+		m.mode = m.getModeFromTab()
+		return m, cmd
+	}
+}
+
+func containerAction(action, containerID string) func(Model) (Model, tea.Cmd) {
+	return func(m Model) (Model, tea.Cmd) {
+		return m, func() tea.Msg {
+			return monitor.ContainerActionMsg{Action: action, ContainerID: containerID}
+		}
+	}
+}
+
+func execIn(containerID string) func(Model) (Model, tea.Cmd) {
+	return func(m Model) (Model, tea.Cmd) {
+		return m, func() tea.Msg { return monitor.ExecContainerMsg{ContainerID: containerID} }
+	}
+}
+
+// showProcesses opens the process list of c in the Containers tab.
+func showProcesses(c infra.ContainerInfo) func(Model) (Model, tea.Cmd) {
+	return func(m Model) (Model, tea.Cmd) {
+		m.activeTab = TabContainers
+		var cmd tea.Cmd
+		m.containers, cmd = m.containers.OpenProcesses(c)
+		m.mode = m.getModeFromTab()
+		return m, cmd
+	}
+}
+
+// workflowFiles lists the workflows saved where workflow generate puts
+// them, ~/.devlogs/workflows.
+func workflowFiles() []string {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return nil
+	}
+	dir := filepath.Join(home, ".devlogs", "workflows")
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil
+	}
+	var files []string
+	for _, e := range entries {
+		if ext := filepath.Ext(e.Name()); !e.IsDir() && (ext == ".yaml" || ext == ".yml") {
+			files = append(files, filepath.Join(dir, e.Name()))
+		}
+	}
+	return files
+}
@@ -0,0 +1,91 @@
+package tui
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"dev-cli/internal/pipeline"
+	"dev-cli/internal/storage"
+	"dev-cli/internal/workflow"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func loadRunbooks(db *sql.DB) tea.Cmd {
+	if db == nil {
+		return nil
+	}
+	return func() tea.Msg {
+		runbooks, err := storage.ListRunbooks(db)
+		return runbooksLoadedMsg{runbooks: runbooks, err: err}
+	}
+}
+
+func saveRunbook(db *sql.DB, rb storage.Runbook) tea.Cmd {
+	return func() tea.Msg {
+		if db == nil {
+			return runbookSavedMsg{err: fmt.Errorf("history database unavailable")}
+		}
+		return runbookSavedMsg{err: storage.SaveRunbook(db, rb)}
+	}
+}
+
+// runRunbook runs rb through the workflow engine in the background, asking
+// before each step; waitRunbook delivers its progress until a final
+// message with done set. Cancelling ctx aborts the run at the step being
+// asked about. A run that ends counts towards the runbook's success rate.
+func runRunbook(ctx context.Context, db *sql.DB, rb storage.Runbook) tea.Cmd {
+	return func() tea.Msg {
+		if db == nil {
+			return runbookProgressMsg{name: rb.Name, done: true, err: fmt.Errorf("history database unavailable")}
+		}
+		wf, err := workflow.FromRunbook(&rb)
+		if err != nil {
+			return runbookProgressMsg{name: rb.Name, done: true, err: fmt.Errorf("invalid runbook: %w", err)}
+		}
+		store := workflow.NewCheckpointStore(db)
+		if err := store.InitSchema(); err != nil {
+			return runbookProgressMsg{name: rb.Name, done: true, err: err}
+		}
+
+		ch := make(chan runbookProgressMsg, 16)
+		bus := pipeline.NewEventBus()
+		bus.Subscribe(pipeline.EventWorkflowStep, func(e pipeline.Event) {
+			data, _ := e.Data.(map[string]interface{})
+			status, _ := data["status"].(string)
+			exitCode, _ := data["exit_code"].(int)
+			ch <- runbookProgressMsg{ch: ch, stepID: e.BlockID, status: workflow.StepStatus(status), exitCode: exitCode}
+		})
+
+		engine := workflow.NewEngine(store, bus)
+		engine.SetStepApproval(func(ctx context.Context, step workflow.Step) bool {
+			reply := make(chan bool, 1)
+			ch <- runbookProgressMsg{ch: ch, asking: step.ID, reply: reply}
+			select {
+			case run := <-reply:
+				return run
+			case <-ctx.Done():
+				return false
+			}
+		})
+
+		go func() {
+			result, err := engine.Run(ctx, wf)
+			if result != nil && result.Status != workflow.StatusPaused {
+				if statsErr := storage.UpdateRunbookStats(db, rb.ID, result.Status == workflow.StatusCompleted); statsErr != nil && err == nil {
+					err = statsErr
+				}
+			}
+			ch <- runbookProgressMsg{ch: ch, name: rb.Name, done: true, result: result, err: err}
+			close(ch)
+		}()
+		return waitRunbook(ch)()
+	}
+}
+
+func waitRunbook(ch <-chan runbookProgressMsg) tea.Cmd {
+	return func() tea.Msg {
+		return <-ch
+	}
+}
@@ -0,0 +1,118 @@
+package tui
+
+import (
+	"database/sql"
+	"slices"
+	"time"
+
+	"dev-cli/internal/config"
+	"dev-cli/internal/pipeline"
+	"dev-cli/internal/storage"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// sessionsLimit bounds the sessions the History tab lists.
+const sessionsLimit = 200
+
+// startSession records this launch as a session once the history
+// database opens, and takes the daily backup when DEV_CLI_AUTO_BACKUP is
+// set.
+func startSession(db *sql.DB, s storage.Session) tea.Cmd {
+	return func() tea.Msg {
+		_ = storage.StartSession(db, s)
+		if cfg := config.Current; cfg.AutoBackup {
+			if dir, err := storage.BackupDir(); err == nil {
+				_, _ = storage.AutoBackup(db, dir, cfg.BackupKeep, time.Now())
+			}
+		}
+		return nil
+	}
+}
+
+// endSession records that this launch ended; it runs after the program
+// quits, so it doesn't go through a tea.Cmd.
+func (m Model) endSession() {
+	if m.db != nil {
+		_ = storage.EndSession(m.db, m.session.ID, time.Now())
+	}
+}
+
+// recordCommand stores a command the Agent ran in the history, under this
+// launch's session, the way the shell hook stores the ones run outside.
+func (m Model) recordCommand(block pipeline.Block) tea.Cmd {
+	db := m.db
+	if db == nil || m.demo || block.Type == pipeline.BlockTypeAI || block.Command == "" {
+		return nil
+	}
+	// SaveCommand keeps the output's tail up to storage.MaxOutputSize.
+	entry := storage.LogEntry{
+		Command:    block.Command,
+		ExitCode:   block.ExitCode,
+		Output:     block.Output,
+		Cwd:        block.WorkingDir,
+		DurationMs: block.Duration.Milliseconds(),
+		Timestamp:  block.Timestamp.Format(time.RFC3339),
+		SessionID:  m.session.ID,
+	}
+	window := config.Current.DedupWindow
+	return func() tea.Msg {
+		_ = storage.SaveCommandCollapsing(db, entry, window)
+		return nil
+	}
+}
+
+// recordFix counts how a fix run from the Agent went for the error it was
+// run for, which ranks the solutions offered when the error comes back.
+// An interrupted fix says nothing either way.
+func (m Model) recordFix(signature string, block pipeline.Block) tea.Cmd {
+	db := m.db
+	if db == nil || m.demo || block.ExitCode == 130 {
+		return nil
+	}
+	return func() tea.Msg {
+		if block.ExitCode == 0 {
+			_ = storage.IncrementSolutionSuccess(db, signature, block.Command)
+		} else {
+			_ = storage.IncrementSolutionFailure(db, signature, block.Command)
+		}
+		return nil
+	}
+}
+
+// querySessions reads the sessions for the History tab; the demo has the
+// one its synthetic history ran in.
+func (m Model) querySessions() tea.Cmd {
+	if m.demo {
+		return func() tea.Msg { return sessionsLoadedMsg{sessions: demoSessions()} }
+	}
+	db := m.db
+	if db == nil {
+		return nil
+	}
+	return func() tea.Msg {
+		sessions, err := storage.ListSessions(db, sessionsLimit)
+		return sessionsLoadedMsg{sessions: sessions, err: err}
+	}
+}
+
+// querySessionReplay reads the commands of session id, oldest first, to
+// replay them in the Agent.
+func (m Model) querySessionReplay(id string) tea.Cmd {
+	filter := storage.HistoryFilter{SessionID: id}
+	if m.demo {
+		items := demoHistoryMatching(filter)
+		slices.Reverse(items)
+		return func() tea.Msg { return sessionReplayMsg{sessionID: id, history: items} }
+	}
+	db := m.db
+	if db == nil {
+		return nil
+	}
+	return func() tea.Msg {
+		filter.Limit = historyLimit
+		items, err := storage.QueryHistory(db, filter)
+		slices.Reverse(items)
+		return sessionReplayMsg{sessionID: id, history: items, err: err}
+	}
+}
@@ -0,0 +1,123 @@
+package agent
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"dev-cli/internal/storage"
+
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/textinput"
+)
+
+// maxCompletionHistory bounds the history commands offered as completions.
+const maxCompletionHistory = 500
+
+// completer ranks what the input offers as completions: commands from
+// history, most run first, then executables on PATH for the first word.
+type completer struct {
+	history     []string
+	counts      map[string]int
+	executables []string
+}
+
+// candidates returns every completion in rank order; the input matches
+// them against what was typed.
+func (c completer) candidates() []string {
+	out := slices.Clone(c.history)
+	for _, exe := range c.executables {
+		if _, ok := c.counts[exe]; !ok {
+			out = append(out, exe)
+		}
+	}
+	return out
+}
+
+// record counts a command run in this session, moving it ahead of the
+// commands it now outnumbers.
+func (c completer) record(command string) completer {
+	command = strings.TrimSpace(command)
+	if command == "" {
+		return c
+	}
+	counts := make(map[string]int, len(c.counts)+1)
+	for k, v := range c.counts {
+		counts[k] = v
+	}
+	counts[command]++
+
+	history := slices.DeleteFunc(slices.Clone(c.history), func(s string) bool { return s == command })
+	// Ties go to the command just run, as the most recent.
+	i := slices.IndexFunc(history, func(s string) bool { return counts[s] <= counts[command] })
+	if i < 0 {
+		i = len(history)
+	}
+	history = slices.Insert(history, i, command)
+	if len(history) > maxCompletionHistory {
+		history = history[:maxCompletionHistory]
+	}
+
+	c.history, c.counts = history, counts
+	return c
+}
+
+// newCompleter builds a completer from history, most run first, and the
+// executables on PATH.
+func newCompleter(history []storage.CommandFrequency, executables []string) completer {
+	c := completer{counts: make(map[string]int, len(history)), executables: executables}
+	for _, h := range history {
+		if _, ok := c.counts[h.Command]; ok || len(c.history) >= maxCompletionHistory {
+			continue
+		}
+		c.history = append(c.history, h.Command)
+		c.counts[h.Command] = h.Count
+	}
+	return c
+}
+
+// PathExecutables lists the executable files in the directories of path
+// (a PATH value), sorted and without duplicates.
+func PathExecutables(path string) []string {
+	seen := make(map[string]bool)
+	var out []string
+	for _, dir := range filepath.SplitList(path) {
+		entries, err := os.ReadDir(dir)
+		if err != nil {
+			continue
+		}
+		for _, e := range entries {
+			name := e.Name()
+			if seen[name] || e.IsDir() {
+				continue
+			}
+			info, err := e.Info()
+			if err != nil {
+				continue
+			}
+			// Symlinks (common in /usr/bin) are followed for the mode.
+			if info.Mode()&os.ModeSymlink != 0 {
+				if info, err = os.Stat(filepath.Join(dir, name)); err != nil || info.IsDir() {
+					continue
+				}
+			}
+			if info.Mode().Perm()&0o111 == 0 {
+				continue
+			}
+			seen[name] = true
+			out = append(out, name)
+		}
+	}
+	slices.Sort(out)
+	return out
+}
+
+// completionKeys binds the input's suggestions fish-style: the ghost text
+// is accepted with → or Ctrl+f, and Tab / Shift+Tab cycle the candidates.
+func completionKeys(km textinput.KeyMap) textinput.KeyMap {
+	km.AcceptSuggestion = key.NewBinding(key.WithKeys("right", "ctrl+f"))
+	km.NextSuggestion = key.NewBinding(key.WithKeys("tab", "ctrl+n"))
+	km.PrevSuggestion = key.NewBinding(key.WithKeys("shift+tab", "ctrl+p"))
+	return km
+}
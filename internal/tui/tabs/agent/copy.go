@@ -0,0 +1,52 @@
+package agent
+
+import (
+	"fmt"
+	"strings"
+
+	"dev-cli/internal/pipeline"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// CopyMsg asks the app to put Text on the system clipboard.
+type CopyMsg struct {
+	Text string
+	// What names what was copied, for the confirmation.
+	What string
+}
+
+// copyCmd asks for text to be copied; nothing is asked for empty text.
+func copyCmd(text, what string) tea.Cmd {
+	if text == "" {
+		return nil
+	}
+	return func() tea.Msg { return CopyMsg{Text: text, What: what} }
+}
+
+// copyBlock copies what the key asks for from block: "y" its output (or
+// the command, when it printed nothing), "Y" its command and "c" the AI's
+// suggested command.
+func copyBlock(block pipeline.Block, key string) tea.Cmd {
+	switch key {
+	case "y":
+		if output := strings.TrimRight(block.Output, "\n"); output != "" {
+			return copyCmd(output, "output")
+		}
+		return copyCmd(block.Command, "command")
+	case "Y":
+		return copyCmd(block.Command, "command")
+	case "c":
+		return copyCmd(block.AISuggestion, "suggestion")
+	}
+	return nil
+}
+
+// Copied reports the outcome of a copy until the next key.
+func (m Model) Copied(what string, err error) Model {
+	m.notice, m.noticeFailed = "✓ copied "+what, false
+	if err != nil {
+		m.notice, m.noticeFailed = fmt.Sprintf("✗ copy failed: %v", err), true
+	}
+	return m
+}
@@ -0,0 +1,63 @@
+package agent
+
+import (
+	"fmt"
+	"slices"
+
+	"dev-cli/internal/pipeline"
+	"dev-cli/internal/workflow"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// DraftWorkflowMsg asks the app to save a workflow drafted from Commands,
+// in the order they ran.
+type DraftWorkflowMsg struct {
+	Commands []workflow.RecordedCommand
+}
+
+// succeeded reports whether block is a command that ran to exit 0, the
+// only kind that goes in a drafted workflow.
+func succeeded(block pipeline.Block) bool {
+	return block.Type == pipeline.BlockTypeCommand && !block.Running && block.ExitCode == 0
+}
+
+// toggleMark marks the selected block for a drafted workflow, or unmarks
+// it when it is marked.
+func (m Model) toggleMark() Model {
+	blocks := m.Blocks()
+	if m.selectedBlock < 0 || m.selectedBlock >= len(blocks) {
+		return m
+	}
+	block := blocks[m.selectedBlock]
+	if i := slices.Index(m.marked, block.ID); i >= 0 {
+		m.marked = slices.Delete(slices.Clone(m.marked), i, i+1)
+		m.notice, m.noticeFailed = fmt.Sprintf("unmarked (%d marked)", len(m.marked)), false
+		return m
+	}
+	if !succeeded(block) {
+		m.notice, m.noticeFailed = "✗ only commands that succeeded go in a workflow", true
+		return m
+	}
+	m.marked = append(m.marked, block.ID)
+	m.notice, m.noticeFailed = fmt.Sprintf("marked (%d) • W drafts a workflow", len(m.marked)), false
+	return m
+}
+
+// draftWorkflow asks for a workflow of the marked blocks, in the order they
+// ran, or of the selected block when none are marked, and clears the marks.
+func (m Model) draftWorkflow() (Model, tea.Cmd) {
+	var commands []workflow.RecordedCommand
+	for i, block := range m.Blocks() {
+		if slices.Contains(m.marked, block.ID) || (len(m.marked) == 0 && i == m.selectedBlock && succeeded(block)) {
+			commands = append(commands, workflow.RecordedCommand{Command: block.Command, Dir: block.WorkingDir})
+		}
+	}
+	if len(commands) == 0 {
+		m.notice, m.noticeFailed = "✗ mark commands that succeeded with m first", true
+		return m, nil
+	}
+	m.marked = nil
+	msg := DraftWorkflowMsg{Commands: commands}
+	return m, func() tea.Msg { return msg }
+}
@@ -0,0 +1,334 @@
+package agent
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+
+	"dev-cli/internal/tui/theme"
+
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/textarea"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
+)
+
+// editorRows bounds the lines the multi-line editor shows at once.
+const editorRows = 10
+
+// commandEditor is the Ctrl+E editor for commands that don't fit a line,
+// like heredocs and long pipelines. Enter breaks the line and Ctrl+S runs
+// the whole text as one block.
+type commandEditor struct {
+	area textarea.Model
+	// top is the first line shown, kept so the cursor stays in view.
+	top int
+}
+
+func newCommandEditor(value string) *commandEditor {
+	ta := textarea.New()
+	// The editor draws the text itself, highlighted; the textarea only
+	// edits it, so it never wraps.
+	ta.SetWidth(4096)
+	ta.MaxHeight = 0
+	ta.CharLimit = 0
+	ta.ShowLineNumbers = false
+	// Ctrl+E closes the editor; Home and End still move along the line.
+	ta.KeyMap.LineEnd = key.NewBinding(key.WithKeys("end"))
+	ta.SetValue(value)
+	ta.Focus()
+	return &commandEditor{area: ta}
+}
+
+// EditorOpen reports whether the multi-line editor has the keyboard.
+func (m Model) EditorOpen() bool { return m.editor != nil }
+
+// openEditor moves the input line, or the draft the editor was closed
+// with, into the multi-line editor.
+func (m Model) openEditor() (Model, tea.Cmd) {
+	value := m.input.Value()
+	if value == "" {
+		value = m.editorDraft
+	}
+	m.editorDraft = ""
+	m.input.SetValue("")
+	m = m.SetInsertMode(true)
+	m.editor = newCommandEditor(value)
+	return m, textarea.Blink
+}
+
+// closeEditor puts a single line back on the input; several lines are
+// kept as a draft for the next Ctrl+E.
+func (m Model) closeEditor() Model {
+	value := strings.TrimRight(m.editor.area.Value(), "\n")
+	m.editor = nil
+	if strings.Contains(value, "\n") {
+		m.editorDraft = value
+		m.notice, m.noticeFailed = "draft kept • Ctrl+e reopens it", false
+		return m
+	}
+	m.input.SetValue(value)
+	m.input.CursorEnd()
+	return m
+}
+
+// updateEditor handles a key while the editor is open.
+func (m Model) updateEditor(msg tea.KeyMsg, keys KeyMap) (Model, tea.Cmd) {
+	switch {
+	case key.Matches(msg, keys.Escape), key.Matches(msg, keys.Editor):
+		return m.closeEditor(), nil
+	case key.Matches(msg, keys.RunEditor):
+		value := strings.TrimSpace(m.editor.area.Value())
+		if value == "" {
+			return m, nil
+		}
+		m.editor = nil
+		return m.submit(value, false, false)
+	}
+
+	e := *m.editor
+	var cmd tea.Cmd
+	e.area, cmd = e.area.Update(msg)
+	row := e.area.Line()
+	e.top = min(e.top, row)
+	e.top = max(e.top, row-editorRows+1)
+	m.editor = &e
+	return m, cmd
+}
+
+// editorHeight is how many rows the editor takes in place of the input
+// line's one.
+func (m Model) editorHeight() int {
+	if m.editor == nil {
+		return 1
+	}
+	return min(max(m.editor.area.LineCount(), 3), editorRows) + 1
+}
+
+// renderEditor draws the editor's lines highlighted, hard-wrapped at
+// width, with the cursor as a reversed cell.
+func (m Model) renderEditor(width int) string {
+	e := m.editor
+	value := e.area.Value()
+	lines := highlightShell(value)
+	plain := strings.Split(value, "\n")
+
+	row := e.area.Line()
+	info := e.area.LineInfo()
+	col := info.StartColumn + info.ColumnOffset
+
+	gutterStyle := lipgloss.NewStyle().Foreground(theme.Overlay0)
+	cursorStyle := lipgloss.NewStyle().Reverse(true)
+	textWidth := max(width-8, 10)
+
+	var rows []string
+	for i := e.top; i < len(lines) && i < e.top+editorRows; i++ {
+		line := lines[i]
+		if i == row {
+			runes := []rune(plain[i])
+			at := ansi.StringWidth(string(runes[:min(col, len(runes))]))
+			under := " "
+			if col < len(runes) {
+				under = string(runes[col])
+			}
+			end := ansi.StringWidth(line)
+			line = ansi.Cut(line, 0, at) + cursorStyle.Render(under) + ansi.Cut(line, at+ansi.StringWidth(under), end)
+		}
+		gutter := gutterStyle.Render(fmt.Sprintf("%2d ", i+1))
+		for first := true; first || ansi.StringWidth(line) > 0; first = false {
+			rows = append(rows, gutter+ansi.Cut(line, 0, textWidth))
+			line = ansi.Cut(line, textWidth, ansi.StringWidth(line))
+			gutter = "   "
+		}
+	}
+	// Wrapped lines only push the last ones out of view.
+	visible := m.editorHeight() - 1
+	if len(rows) > visible {
+		rows = rows[:visible]
+	}
+	for len(rows) < visible {
+		rows = append(rows, "")
+	}
+
+	hintStyle := lipgloss.NewStyle().Foreground(theme.Overlay0).Italic(true)
+	hint := fmt.Sprintf("%d lines • Ctrl+s run • Enter new line • Esc close", len(plain))
+	if len(plain) == 1 {
+		hint = "1 line • Ctrl+s run • Enter new line • Esc close"
+	}
+	rows = append(rows, hintStyle.Render(hint))
+
+	return lipgloss.NewStyle().
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(theme.Green).
+		Width(width).
+		Padding(0, 1).
+		Render(strings.Join(rows, "\n"))
+}
+
+// shellState carries what a line leaves open into the next one: a quote,
+// or the body of a heredoc until its terminator.
+type shellState struct {
+	quote   rune
+	heredoc string
+}
+
+// highlightShell colours shell source line by line: command names, flags,
+// strings, variables, operators and comments. It is a highlighter, not a
+// parser, so odd constructs just stay plain.
+func highlightShell(src string) []string {
+	var state shellState
+	lines := strings.Split(src, "\n")
+	out := make([]string, len(lines))
+	for i, line := range lines {
+		out[i], state = highlightShellLine(line, state)
+	}
+	return out
+}
+
+func highlightShellLine(line string, state shellState) (string, shellState) {
+	commandStyle := lipgloss.NewStyle().Foreground(theme.Blue).Bold(true)
+	flagStyle := lipgloss.NewStyle().Foreground(theme.Yellow)
+	stringStyle := lipgloss.NewStyle().Foreground(theme.Green)
+	varStyle := lipgloss.NewStyle().Foreground(theme.Peach)
+	opStyle := lipgloss.NewStyle().Foreground(theme.Mauve)
+	commentStyle := lipgloss.NewStyle().Foreground(theme.Overlay0).Italic(true)
+	textStyle := lipgloss.NewStyle().Foreground(theme.Text)
+
+	if state.heredoc != "" {
+		if strings.TrimSpace(line) == state.heredoc {
+			state.heredoc = ""
+			return opStyle.Render(line), state
+		}
+		return stringStyle.Render(line), state
+	}
+
+	runes := []rune(line)
+	var b strings.Builder
+	// command is set where the next word names a command.
+	command := state.quote == 0
+	pendingHeredoc := ""
+	i := 0
+	if state.quote != 0 {
+		end := closingQuote(runes, 0, state.quote)
+		if end < 0 {
+			return stringStyle.Render(line), state
+		}
+		b.WriteString(stringStyle.Render(string(runes[:end+1])))
+		state.quote = 0
+		i = end + 1
+	}
+
+	for i < len(runes) {
+		r := runes[i]
+		switch {
+		case unicode.IsSpace(r):
+			b.WriteRune(r)
+			i++
+
+		case r == '#' && (i == 0 || unicode.IsSpace(runes[i-1])):
+			b.WriteString(commentStyle.Render(string(runes[i:])))
+			i = len(runes)
+
+		case r == '\'' || r == '"':
+			end := closingQuote(runes, i+1, r)
+			if end < 0 {
+				b.WriteString(stringStyle.Render(string(runes[i:])))
+				state.quote = r
+				i = len(runes)
+				break
+			}
+			b.WriteString(stringStyle.Render(string(runes[i : end+1])))
+			i = end + 1
+			command = false
+
+		case r == '$':
+			end := i + 1
+			switch {
+			case end < len(runes) && (runes[end] == '{' || runes[end] == '('):
+				closer := map[rune]rune{'{': '}', '(': ')'}[runes[end]]
+				for end < len(runes) && runes[end] != closer {
+					end++
+				}
+				end = min(end+1, len(runes))
+			case end < len(runes) && strings.ContainsRune("?!#@*$-0123456789", runes[end]):
+				end++
+			default:
+				for end < len(runes) && (runes[end] == '_' || unicode.IsLetter(runes[end]) || unicode.IsDigit(runes[end])) {
+					end++
+				}
+			}
+			b.WriteString(varStyle.Render(string(runes[i:end])))
+			i = end
+			command = false
+
+		case strings.ContainsRune("|&;<>()", r):
+			op := string(r)
+			for _, long := range shellOperators {
+				if strings.HasPrefix(string(runes[i:]), long) {
+					op = long
+					break
+				}
+			}
+			b.WriteString(opStyle.Render(op))
+			i += len([]rune(op))
+			if op == "<<" || op == "<<-" {
+				// The next word ends the heredoc, quotes aside.
+				j := i
+				for j < len(runes) && unicode.IsSpace(runes[j]) {
+					j++
+				}
+				k := j
+				for k < len(runes) && !unicode.IsSpace(runes[k]) && !strings.ContainsRune("|&;<>()", runes[k]) {
+					k++
+				}
+				pendingHeredoc = strings.Trim(string(runes[j:k]), `'"\`)
+				b.WriteString(string(runes[i:j]) + opStyle.Render(string(runes[j:k])))
+				i = k
+				continue
+			}
+			command = strings.ContainsAny(op, "|&;(") && !strings.ContainsAny(op, "<>")
+
+		default:
+			end := i
+			for end < len(runes) && !unicode.IsSpace(runes[end]) && !strings.ContainsRune("|&;<>()'\"$", runes[end]) {
+				end++
+			}
+			word := string(runes[i:end])
+			switch {
+			case command && strings.Contains(word, "=") && !strings.HasPrefix(word, "="):
+				// An assignment before the command, like FOO=1 make.
+				b.WriteString(varStyle.Render(word))
+			case command:
+				b.WriteString(commandStyle.Render(word))
+				command = word == "sudo" || word == "time" || word == "env" || word == "exec" || word == "then" || word == "do" || word == "else" || word == "if" || word == "while" || word == "!"
+			case strings.HasPrefix(word, "-"):
+				b.WriteString(flagStyle.Render(word))
+			default:
+				b.WriteString(textStyle.Render(word))
+			}
+			i = end
+		}
+	}
+	state.heredoc = pendingHeredoc
+	return b.String(), state
+}
+
+// shellOperators are the operators longer than a character, longest
+// first.
+var shellOperators = []string{"<<-", "<<<", "&&", "||", ">>", "<<", ";;", "|&", ">&", "&>", "<&"}
+
+// closingQuote finds the quote closing one opened before from, skipping
+// escaped double quotes; -1 when the line leaves it open.
+func closingQuote(runes []rune, from int, quote rune) int {
+	for i := from; i < len(runes); i++ {
+		if quote == '"' && runes[i] == '\\' {
+			i++
+			continue
+		}
+		if runes[i] == quote {
+			return i
+		}
+	}
+	return -1
+}
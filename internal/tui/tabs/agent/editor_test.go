@@ -0,0 +1,49 @@
+package agent
+
+import (
+	"strings"
+	"testing"
+
+	"dev-cli/internal/tui/theme"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/muesli/termenv"
+)
+
+func TestHighlightShell(t *testing.T) {
+	profile := lipgloss.ColorProfile()
+	lipgloss.SetColorProfile(termenv.TrueColor)
+	defer lipgloss.SetColorProfile(profile)
+
+	src := "FOO=1 cat <<'EOF' | grep -v \"$USER\" # note\n$HOME stays text\nEOF\necho 'two\nlines' && ls ${DIR}"
+	lines := highlightShell(src)
+	if got := stripANSI(strings.Join(lines, "\n")); got != src {
+		t.Fatalf("highlighting changed the text:\n%s", got)
+	}
+
+	command := lipgloss.NewStyle().Foreground(theme.Blue).Bold(true)
+	str := lipgloss.NewStyle().Foreground(theme.Green)
+	variable := lipgloss.NewStyle().Foreground(theme.Peach)
+	for _, want := range []struct {
+		line int
+		text string
+	}{
+		{0, variable.Render("FOO=1")},
+		{0, command.Render("cat")},
+		{0, command.Render("grep")},
+		{0, lipgloss.NewStyle().Foreground(theme.Yellow).Render("-v")},
+		{0, str.Render(`"$USER"`)},
+		{0, lipgloss.NewStyle().Foreground(theme.Overlay0).Italic(true).Render("# note")},
+		// The heredoc body is text, variables and all.
+		{1, str.Render("$HOME stays text")},
+		{3, command.Render("echo")},
+		// A quote left open carries over to the next line.
+		{4, str.Render("lines'")},
+		{4, command.Render("ls")},
+		{4, variable.Render("${DIR}")},
+	} {
+		if !strings.Contains(lines[want.line], want.text) {
+			t.Errorf("line %d: expected %q in %q", want.line, want.text, lines[want.line])
+		}
+	}
+}
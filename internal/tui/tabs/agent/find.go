@@ -0,0 +1,177 @@
+package agent
+
+import (
+	"fmt"
+	"regexp"
+
+	"dev-cli/internal/tui/theme"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	"github.com/charmbracelet/bubbles/viewport"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
+)
+
+// blockFind is the "/" search over the blocks area. While the query is
+// typed, matches are highlighted as they come; after Enter, n and N move
+// between them.
+type blockFind struct {
+	input   textinput.Model
+	editing bool
+	pattern *regexp.Regexp
+	// current is the match n/N last moved to, counted from the first.
+	current int
+}
+
+// findMatch is one hit, in cells of a blocks-area line.
+type findMatch struct {
+	line, start, end int
+}
+
+func newBlockFind() *blockFind {
+	ti := textinput.New()
+	ti.Prompt = "/"
+	ti.PromptStyle = lipgloss.NewStyle().Foreground(theme.Peach).Bold(true)
+	ti.Placeholder = "find in blocks"
+	ti.Focus()
+	return &blockFind{input: ti, editing: true, current: -1}
+}
+
+// setQuery matches query as plain text, ignoring case.
+func (f *blockFind) setQuery(query string) {
+	f.pattern = nil
+	if query != "" {
+		f.pattern = regexp.MustCompile("(?i)" + regexp.QuoteMeta(query))
+	}
+	f.current = -1
+}
+
+// matches finds every hit in the rendered lines, top to bottom.
+func (f *blockFind) matches(lines []string) []findMatch {
+	if f.pattern == nil {
+		return nil
+	}
+	var out []findMatch
+	for i, line := range lines {
+		plain := ansi.Strip(line)
+		for _, loc := range f.pattern.FindAllStringIndex(plain, -1) {
+			start := ansi.StringWidth(plain[:loc[0]])
+			out = append(out, findMatch{line: i, start: start, end: start + ansi.StringWidth(plain[loc[0]:loc[1]])})
+		}
+	}
+	return out
+}
+
+// highlight marks the hits in lines, the current one apart from the rest,
+// keeping the styles around them.
+func (f *blockFind) highlight(lines []string) []string {
+	hitStyle := lipgloss.NewStyle().Background(theme.Yellow).Foreground(theme.Crust)
+	currentStyle := lipgloss.NewStyle().Background(theme.Peach).Foreground(theme.Crust).Bold(true)
+
+	matches := f.matches(lines)
+	out := append([]string(nil), lines...)
+	// Going backwards keeps the cell offsets of earlier hits on a line valid.
+	for i := len(matches) - 1; i >= 0; i-- {
+		hit := matches[i]
+		line := out[hit.line]
+		style := hitStyle
+		if i == f.current {
+			style = currentStyle
+		}
+		text := ansi.Strip(ansi.Cut(line, hit.start, hit.end))
+		out[hit.line] = ansi.Cut(line, 0, hit.start) + style.Render(text) + ansi.Cut(line, hit.end, ansi.StringWidth(line))
+	}
+	return out
+}
+
+// status counts the matches, and says which one is current.
+func (f *blockFind) status(total int) string {
+	switch {
+	case total == 0:
+		return "no match"
+	case f.current < 0:
+		return fmt.Sprintf("%d matches", total)
+	}
+	return fmt.Sprintf("%d/%d", f.current+1, total)
+}
+
+// FindOpen reports whether the "/" query is being typed.
+func (m Model) FindOpen() bool { return m.find != nil && m.find.editing }
+
+// openFind starts a new "/" query.
+func (m Model) openFind() (Model, tea.Cmd) {
+	m.find = newBlockFind()
+	return m, textinput.Blink
+}
+
+// updateFind handles a key while the query is typed: Enter keeps the
+// matches to step through, jumping to the newest, and Esc drops them.
+func (m Model) updateFind(msg tea.KeyMsg) (Model, tea.Cmd) {
+	switch msg.String() {
+	case "esc":
+		m.find = nil
+		return m, nil
+	case "enter":
+		if m.find.pattern == nil {
+			m.find = nil
+			return m, nil
+		}
+		find := *m.find
+		find.editing = false
+		find.input.Blur()
+		m.find = &find
+		return m.jumpToMatch(-1), nil
+	}
+
+	find := *m.find
+	var cmd tea.Cmd
+	find.input, cmd = find.input.Update(msg)
+	find.setQuery(find.input.Value())
+	m.find = &find
+	return m, cmd
+}
+
+// jumpToMatch makes a match current and scrolls it into the middle of the
+// blocks area: step 1 is the next match down, wrapping, and -1 the one
+// above. Before the first jump, -1 goes to the newest match.
+func (m Model) jumpToMatch(step int) Model {
+	if m.find == nil {
+		return m
+	}
+	matches := m.findMatches()
+	if len(matches) == 0 {
+		return m
+	}
+
+	find := *m.find
+	if find.current < 0 {
+		find.current = len(matches)
+		if step > 0 {
+			find.current = -1
+		}
+	}
+	find.current = (find.current + step + len(matches)) % len(matches)
+	m.find = &find
+
+	line := matches[find.current].line
+	return m.scrollBlocks(func(vp *viewport.Model) {
+		vp.SetYOffset(line - vp.Height/2)
+	})
+}
+
+// findMatches lists the hits of the query in the blocks area.
+func (m Model) findMatches() []findMatch {
+	width, _ := m.blocksAreaSize()
+	lines, _ := m.blockLines(width)
+	return m.find.matches(lines)
+}
+
+// findHint replaces the input hints while a query is kept or typed.
+func (m Model) findHint() string {
+	status := m.find.status(len(m.findMatches()))
+	if m.find.editing {
+		return status + "  [Enter]keep [Esc]cancel"
+	}
+	return "/" + m.find.input.Value() + " " + status + "  [n/N]next/prev [Esc]clear"
+}
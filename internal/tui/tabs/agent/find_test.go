@@ -0,0 +1,43 @@
+package agent
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/muesli/termenv"
+)
+
+func TestBlockFind_Highlight(t *testing.T) {
+	profile := lipgloss.ColorProfile()
+	lipgloss.SetColorProfile(termenv.TrueColor)
+	defer lipgloss.SetColorProfile(profile)
+
+	green := lipgloss.NewStyle().Foreground(lipgloss.Color("#00ff00"))
+	lines := []string{
+		green.Render("│") + " ❯ " + green.Render("grep Error app.log"),
+		"│ error: disk full, ERROR again",
+		"│ all good",
+	}
+
+	f := newBlockFind()
+	f.setQuery("error")
+	matches := f.matches(lines)
+	if len(matches) != 3 || matches[0] != (findMatch{line: 0, start: 9, end: 14}) || matches[2].line != 1 {
+		t.Fatalf("expected three case-insensitive hits in cells, got %+v", matches)
+	}
+
+	f.current = 1
+	out := f.highlight(lines)
+	for i := range lines {
+		if got, want := stripANSI(out[i]), stripANSI(lines[i]); got != want {
+			t.Errorf("line %d: highlighting changed the text to %q", i, got)
+		}
+	}
+	if out[2] != lines[2] {
+		t.Errorf("expected a line without hits to stay as is, got %q", out[2])
+	}
+	if !strings.Contains(out[1], "\x1b[1;") {
+		t.Errorf("expected the current hit to stand out, got %q", out[1])
+	}
+}
@@ -0,0 +1,245 @@
+package agent
+
+import (
+	"encoding/json"
+	"regexp"
+	"strings"
+
+	"dev-cli/internal/tui/theme"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+// outputFormat is the kind of content a block's output holds, for
+// highlighting.
+type outputFormat int
+
+const (
+	formatPlain outputFormat = iota
+	formatJSON
+	formatYAML
+	formatDiff
+	formatTrace
+)
+
+var (
+	yamlKeyPattern  = regexp.MustCompile(`^[\w.\-"']+:(\s|$)`)
+	yamlLinePattern = regexp.MustCompile(`^\s*(- )?[\w.\-/"']+:(\s|$)|^\s*- \S|^---\s*$|^\s*#`)
+	// traceFramePattern matches a file:line location in a stack frame.
+	traceFramePattern = regexp.MustCompile(`([\w./\\\-]+\.(?:go|py|js|mjs|ts|java|rb|rs|kt)):?(\d+)?`)
+	tracePattern      = regexp.MustCompile(`(?m)^(panic: |goroutine \d+ \[|Traceback \(most recent call last\)|--- FAIL: |\s+at .+\(.+:\d+(:\d+)?\)$|Exception in thread )`)
+)
+
+// detectFormat guesses what output holds from its shape. Anything it is
+// not confident about stays plain.
+func detectFormat(output string) outputFormat {
+	trimmed := strings.TrimSpace(output)
+	if trimmed == "" {
+		return formatPlain
+	}
+
+	if (trimmed[0] == '{' || trimmed[0] == '[') && json.Valid([]byte(trimmed)) {
+		return formatJSON
+	}
+
+	lines := strings.Split(trimmed, "\n")
+	if strings.HasPrefix(lines[0], "diff --git ") ||
+		(strings.Contains(output, "\n+++ ") && strings.Contains(output, "\n@@ ") && strings.HasPrefix(trimmed, "--- ")) {
+		return formatDiff
+	}
+
+	if tracePattern.MatchString(output) {
+		return formatTrace
+	}
+
+	// YAML needs a top-level key first and most lines to look like keys or
+	// list items, so log lines with the odd "Error: ..." stay plain.
+	if len(lines) >= 3 && (lines[0] == "---" || yamlKeyPattern.MatchString(lines[0])) {
+		matched := 0
+		for _, line := range lines {
+			if strings.TrimSpace(line) == "" || yamlLinePattern.MatchString(line) {
+				matched++
+			}
+		}
+		if matched*5 >= len(lines)*4 {
+			return formatYAML
+		}
+	}
+	return formatPlain
+}
+
+// highlightOutput renders each line of output with syntax-aware styles,
+// falling back to base for plain text.
+func highlightOutput(output string, base lipgloss.Style) []string {
+	format := detectFormat(output)
+	lines := strings.Split(output, "\n")
+	out := make([]string, len(lines))
+	for i, line := range lines {
+		switch format {
+		case formatJSON:
+			out[i] = highlightJSONLine(line, base)
+		case formatYAML:
+			out[i] = highlightYAMLLine(line, base)
+		case formatDiff:
+			out[i] = highlightDiffLine(line, base)
+		case formatTrace:
+			out[i] = highlightTraceLine(line, base)
+		default:
+			out[i] = base.Render(line)
+		}
+	}
+	return out
+}
+
+func keyStyle() lipgloss.Style     { return lipgloss.NewStyle().Foreground(theme.Blue) }
+func stringStyle() lipgloss.Style  { return lipgloss.NewStyle().Foreground(theme.Green) }
+func numberStyle() lipgloss.Style  { return lipgloss.NewStyle().Foreground(theme.Peach) }
+func literalStyle() lipgloss.Style { return lipgloss.NewStyle().Foreground(theme.Mauve) }
+func punctStyle() lipgloss.Style   { return lipgloss.NewStyle().Foreground(theme.Overlay1) }
+
+// highlightJSONLine colors one line of (usually pretty-printed) JSON: keys,
+// strings, numbers and literals. A string split across lines is left as is.
+func highlightJSONLine(line string, base lipgloss.Style) string {
+	var b strings.Builder
+	for i := 0; i < len(line); {
+		c := line[i]
+		switch {
+		case c == '"':
+			end := i + 1
+			for end < len(line) && line[end] != '"' {
+				if line[end] == '\\' {
+					end++
+				}
+				end++
+			}
+			end = min(end+1, len(line))
+			style := stringStyle()
+			if strings.HasPrefix(strings.TrimLeft(line[end:], " "), ":") {
+				style = keyStyle()
+			}
+			b.WriteString(style.Render(line[i:end]))
+			i = end
+		case c == '-' || (c >= '0' && c <= '9'):
+			end := i + 1
+			for end < len(line) && strings.IndexByte("0123456789.eE+-", line[end]) >= 0 {
+				end++
+			}
+			b.WriteString(numberStyle().Render(line[i:end]))
+			i = end
+		case strings.HasPrefix(line[i:], "true") || strings.HasPrefix(line[i:], "null"):
+			b.WriteString(literalStyle().Render(line[i : i+4]))
+			i += 4
+		case strings.HasPrefix(line[i:], "false"):
+			b.WriteString(literalStyle().Render(line[i : i+5]))
+			i += 5
+		case strings.IndexByte("{}[],:", c) >= 0:
+			b.WriteString(punctStyle().Render(string(c)))
+			i++
+		default:
+			end := i + 1
+			for end < len(line) && strings.IndexByte("\"-0123456789tfn{}[],:", line[end]) < 0 {
+				end++
+			}
+			b.WriteString(base.Render(line[i:end]))
+			i = end
+		}
+	}
+	return b.String()
+}
+
+// highlightYAMLLine colors a YAML line: comments, list dashes, keys and
+// scalar values.
+func highlightYAMLLine(line string, base lipgloss.Style) string {
+	trimmed := strings.TrimLeft(line, " ")
+	indent := line[:len(line)-len(trimmed)]
+	if strings.HasPrefix(trimmed, "#") || trimmed == "---" {
+		return indent + punctStyle().Render(trimmed)
+	}
+
+	var b strings.Builder
+	b.WriteString(indent)
+	if rest, ok := strings.CutPrefix(trimmed, "- "); ok {
+		b.WriteString(punctStyle().Render("- "))
+		trimmed = rest
+	}
+	key, value, ok := strings.Cut(trimmed, ":")
+	if !ok || strings.ContainsAny(key, " {[") && !strings.HasPrefix(key, `"`) {
+		b.WriteString(yamlScalar(trimmed, base))
+		return b.String()
+	}
+	b.WriteString(keyStyle().Render(key) + punctStyle().Render(":"))
+	if value != "" {
+		space := value[:len(value)-len(strings.TrimLeft(value, " "))]
+		b.WriteString(space + yamlScalar(strings.TrimLeft(value, " "), base))
+	}
+	return b.String()
+}
+
+// yamlScalar colors a YAML value by its type.
+func yamlScalar(value string, base lipgloss.Style) string {
+	switch {
+	case value == "":
+		return ""
+	case value == "true" || value == "false" || value == "null" || value == "~":
+		return literalStyle().Render(value)
+	case strings.Trim(value, "0123456789.-") == "":
+		return numberStyle().Render(value)
+	case value[0] == '"' || value[0] == '\'':
+		return stringStyle().Render(value)
+	case value[0] == '|' || value[0] == '>' || value[0] == '&' || value[0] == '*':
+		return punctStyle().Render(value)
+	}
+	return base.Render(value)
+}
+
+// highlightDiffLine colors a unified diff line by its prefix.
+func highlightDiffLine(line string, base lipgloss.Style) string {
+	switch {
+	case strings.HasPrefix(line, "+++ ") || strings.HasPrefix(line, "--- ") ||
+		strings.HasPrefix(line, "diff ") || strings.HasPrefix(line, "index "):
+		return lipgloss.NewStyle().Foreground(theme.Lavender).Bold(true).Render(line)
+	case strings.HasPrefix(line, "@@"):
+		return lipgloss.NewStyle().Foreground(theme.Teal).Render(line)
+	case strings.HasPrefix(line, "+"):
+		return lipgloss.NewStyle().Foreground(theme.Green).Render(line)
+	case strings.HasPrefix(line, "-"):
+		return lipgloss.NewStyle().Foreground(theme.Red).Render(line)
+	}
+	return base.Render(line)
+}
+
+// highlightTraceLine marks the error lines of a stack trace or test
+// failure, and the file:line of each frame.
+func highlightTraceLine(line string, base lipgloss.Style) string {
+	trimmed := strings.TrimSpace(line)
+	switch {
+	case strings.HasPrefix(trimmed, "panic:") || strings.HasPrefix(trimmed, "--- FAIL") ||
+		strings.HasPrefix(trimmed, "FAIL") || strings.HasPrefix(trimmed, "Traceback") ||
+		strings.HasPrefix(trimmed, "Exception") || isErrorLine(trimmed):
+		return lipgloss.NewStyle().Foreground(theme.Red).Bold(true).Render(line)
+	case strings.HasPrefix(trimmed, "goroutine "):
+		return lipgloss.NewStyle().Foreground(theme.Mauve).Render(line)
+	case strings.HasPrefix(trimmed, "ok ") || strings.HasPrefix(trimmed, "--- PASS") || trimmed == "PASS":
+		return lipgloss.NewStyle().Foreground(theme.Green).Render(line)
+	}
+
+	loc := traceFramePattern.FindStringSubmatchIndex(line)
+	if loc == nil {
+		return base.Render(line)
+	}
+	dim := lipgloss.NewStyle().Foreground(theme.Subtext0)
+	out := dim.Render(line[:loc[2]]) + keyStyle().Render(line[loc[2]:loc[3]])
+	rest := loc[3]
+	if loc[4] >= 0 {
+		out += dim.Render(line[loc[3]:loc[4]]) + numberStyle().Render(line[loc[4]:loc[5]])
+		rest = loc[5]
+	}
+	return out + dim.Render(line[rest:])
+}
+
+// isErrorLine matches the exception line closing a Python or JS trace,
+// like "ValueError: bad input".
+func isErrorLine(line string) bool {
+	name, _, ok := strings.Cut(line, ": ")
+	return ok && !strings.Contains(name, " ") && (strings.HasSuffix(name, "Error") || strings.HasSuffix(name, "Exception"))
+}
@@ -0,0 +1,63 @@
+package agent
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/muesli/termenv"
+)
+
+func TestDetectFormat(t *testing.T) {
+	tests := []struct {
+		name   string
+		output string
+		want   outputFormat
+	}{
+		{"docker inspect", "[\n    {\n        \"Id\": \"4f2a\",\n        \"State\": {\"Running\": true, \"Pid\": 42}\n    }\n]", formatJSON},
+		{"truncated json", "{\"Id\": \"4f2a\",", formatPlain},
+		{"kubectl yaml", "apiVersion: v1\nkind: Pod\nmetadata:\n  name: web\n  labels:\n    app: web\nspec:\n  containers:\n  - image: nginx:1.27\n", formatYAML},
+		{"git diff", "diff --git a/main.go b/main.go\nindex 1..2\n--- a/main.go\n+++ b/main.go\n@@ -1 +1 @@\n-old\n+new", formatDiff},
+		{"go panic", "panic: runtime error: index out of range\n\ngoroutine 1 [running]:\nmain.main()\n\t/src/main.go:12 +0x1d", formatTrace},
+		{"go test", "--- FAIL: TestParse (0.00s)\n    parse_test.go:20: got 1, want 2\nFAIL", formatTrace},
+		{"python", "Traceback (most recent call last):\n  File \"app.py\", line 3, in <module>\nValueError: bad input", formatTrace},
+		{"node", "TypeError: x is undefined\n    at main (/app/index.js:4:11)\n    at Object.<anonymous> (/app/index.js:9:1)", formatTrace},
+		{"log lines", "Starting server\nError: config missing\nretrying in 5s", formatPlain},
+		{"ps", "shop-api\tUp 2 hours\npostgres\tUp 2 hours (healthy)", formatPlain},
+	}
+	for _, tt := range tests {
+		if got := detectFormat(tt.output); got != tt.want {
+			t.Errorf("%s: detectFormat = %d, want %d", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestHighlightOutput_KeepsText(t *testing.T) {
+	profile := lipgloss.ColorProfile()
+	lipgloss.SetColorProfile(termenv.TrueColor)
+	defer lipgloss.SetColorProfile(profile)
+
+	output := "{\n  \"name\": \"web\",\n  \"replicas\": 3,\n  \"ready\": false,\n  \"ports\": [80, 443]\n}"
+	lines := highlightOutput(output, lipgloss.NewStyle())
+	if !strings.Contains(lines[1], "\x1b[") {
+		t.Fatalf("expected the key to be colored, got %q", lines[1])
+	}
+	if got := stripANSI(strings.Join(lines, "\n")); got != output {
+		t.Errorf("highlighting changed the text:\n%s", got)
+	}
+}
+
+// stripANSI drops SGR escape sequences.
+func stripANSI(s string) string {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] == 0x1b {
+			for i < len(s) && s[i] != 'm' {
+				i++
+			}
+			continue
+		}
+		b.WriteByte(s[i])
+	}
+	return b.String()
+}
@@ -0,0 +1,37 @@
+package agent
+
+import (
+	"dev-cli/internal/executor"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// InteractiveMsg asks the app to hand the whole terminal to Command, which
+// would break inside a block, and to report back with InteractiveDone.
+type InteractiveMsg struct {
+	Command string
+}
+
+// runInteractive suspends the TUI for command.
+func (m Model) runInteractive(command string) (Model, tea.Cmd) {
+	m.isExecuting = true
+	m.runningCommand = command
+	m = m.followOutput()
+	return m, func() tea.Msg { return InteractiveMsg{Command: command} }
+}
+
+// InteractiveDone records an interactive command, now that the TUI has the
+// terminal back, as a block with what it left on the screen.
+func (m Model) InteractiveDone(result executor.Result, err error) Model {
+	m.isExecuting = false
+	m.runningCommand = ""
+	if m.cmdPlugin == nil {
+		return m
+	}
+	if err != nil && result.Output == "" {
+		result.Output = err.Error()
+	}
+	m.cmdPlugin.Record(result)
+	m.selectedBlock = len(m.Blocks()) - 1
+	return m.followOutput()
+}
@@ -0,0 +1,95 @@
+package agent
+
+import (
+	"fmt"
+	"strings"
+
+	"dev-cli/internal/tui/theme"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+// backgroundCommand reports whether input ends in a lone "&", as a shell
+// job would, and returns the command without it.
+func backgroundCommand(input string) (string, bool) {
+	trimmed := strings.TrimSpace(input)
+	if !strings.HasSuffix(trimmed, "&") || strings.HasSuffix(trimmed, "&&") {
+		return trimmed, false
+	}
+	command := strings.TrimSpace(strings.TrimSuffix(trimmed, "&"))
+	return command, command != ""
+}
+
+// startJob runs command as a background job, leaving the input free.
+func (m Model) startJob(command string) Model {
+	if m.cmdPlugin == nil || command == "" {
+		return m
+	}
+	m.cmdPlugin.StartJob(command)
+	m.notice = "◌ started in the background"
+	return m
+}
+
+// collectJobs brings every finished job back as a block, oldest first.
+func (m Model) collectJobs() Model {
+	if m.cmdPlugin == nil {
+		return m
+	}
+	collected := 0
+	for _, job := range m.State().GetJobs() {
+		if _, ok := m.cmdPlugin.CollectJob(job.ID); ok {
+			collected++
+		}
+	}
+	if collected > 0 {
+		m.selectedBlock = len(m.Blocks()) - 1
+		m = m.followOutput()
+	}
+	return m
+}
+
+// stopJob interrupts the newest running job.
+func (m Model) stopJob() Model {
+	if m.cmdPlugin == nil {
+		return m
+	}
+	jobs := m.State().GetJobs()
+	for i := len(jobs) - 1; i >= 0; i-- {
+		if jobs[i].Running {
+			m.cmdPlugin.Cancel(jobs[i].ID)
+			return m
+		}
+	}
+	return m
+}
+
+// jobsWidget sums up the background jobs for the header: how many still
+// run and how many finished, fine or failed, waiting to be brought back.
+func (m Model) jobsWidget() string {
+	var running, done, failed int
+	for _, job := range m.State().GetJobs() {
+		switch {
+		case job.Running:
+			running++
+		case job.ExitCode != 0:
+			failed++
+		default:
+			done++
+		}
+	}
+	if running+done+failed == 0 {
+		return ""
+	}
+
+	parts := []string{lipgloss.NewStyle().Foreground(theme.Overlay0).Render("jobs")}
+	if running > 0 {
+		parts = append(parts, lipgloss.NewStyle().Foreground(theme.Yellow).Render(fmt.Sprintf("◌%d", running)))
+	}
+	if done > 0 {
+		parts = append(parts, lipgloss.NewStyle().Foreground(theme.Green).Render(fmt.Sprintf("✓%d", done)))
+	}
+	if failed > 0 {
+		parts = append(parts, lipgloss.NewStyle().Foreground(theme.Red).Render(fmt.Sprintf("✗%d", failed)))
+	}
+	return strings.Join(parts, " ")
+}
@@ -0,0 +1,23 @@
+package agent
+
+import "testing"
+
+func TestBackgroundCommand(t *testing.T) {
+	tests := []struct {
+		input, command string
+		background     bool
+	}{
+		{"npm run dev &", "npm run dev", true},
+		{"go build ./... 2>&1 &  ", "go build ./... 2>&1", true},
+		{"make && make test", "make && make test", false},
+		{"make &&", "make &&", false},
+		{"ls", "ls", false},
+		{" & ", "", false},
+	}
+	for _, tt := range tests {
+		command, background := backgroundCommand(tt.input)
+		if command != tt.command || background != tt.background {
+			t.Errorf("backgroundCommand(%q) = %q, %v; want %q, %v", tt.input, command, background, tt.command, tt.background)
+		}
+	}
+}
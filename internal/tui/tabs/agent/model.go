@@ -1,13 +1,16 @@
 package agent
 
 import (
+	"dev-cli/internal/config"
 	"dev-cli/internal/infra"
 	"dev-cli/internal/pipeline"
 	"dev-cli/internal/plugins/ai"
 	"dev-cli/internal/plugins/command"
+	"dev-cli/internal/storage"
 
 	"github.com/charmbracelet/bubbles/textinput"
 	"github.com/charmbracelet/bubbles/viewport"
+	tea "github.com/charmbracelet/bubbletea"
 )
 
 type Model struct {
@@ -21,9 +24,35 @@ type Model struct {
 	cmdPlugin *command.Plugin
 	aiPlugin  *ai.Plugin
 
-	insertMode    bool
-	isExecuting   bool
-	selectedBlock int
+	insertMode     bool
+	isExecuting    bool
+	runningCommand string
+	selectedBlock  int
+	offline        bool
+
+	// completer feeds the input's ghost suggestions.
+	completer completer
+
+	// search is the Ctrl+R history search while it is open.
+	search *HistorySearch
+	// notice replaces the input hints until the next key, e.g. to confirm
+	// a copy.
+	notice       string
+	noticeFailed bool
+
+	// scrolledBack holds the blocks area where the user scrolled it (the
+	// viewport's offset); otherwise it follows the newest output.
+	scrolledBack bool
+	// pendingG is a first "g" waiting for the second of "gg".
+	pendingG bool
+	// find is the "/" search over the blocks, while it is typed or kept.
+	find *blockFind
+	// editor is the Ctrl+E multi-line editor while it is open, and
+	// editorDraft the text it was closed with.
+	editor      *commandEditor
+	editorDraft string
+	// marked are the IDs of the blocks marked for a drafted workflow.
+	marked []string
 }
 
 func New(pipe *pipeline.Pipeline) Model {
@@ -31,6 +60,8 @@ func New(pipe *pipeline.Pipeline) Model {
 	ti.Placeholder = "command or ?question..."
 	ti.CharLimit = 1024
 	ti.Width = 60
+	ti.ShowSuggestions = true
+	ti.KeyMap = completionKeys(ti.KeyMap)
 
 	vp := viewport.New(0, 0)
 
@@ -55,6 +86,7 @@ func New(pipe *pipeline.Pipeline) Model {
 		cmdPlugin:     cmdPlugin,
 		aiPlugin:      aiPlugin,
 		selectedBlock: -1,
+		offline:       config.Load().Offline,
 	}
 }
 
@@ -74,6 +106,49 @@ func (m Model) SetSize(w, h int) Model {
 	return m
 }
 
+// SetCompletions sets what the input completes from: history commands,
+// most run first, and the executables on PATH.
+func (m Model) SetCompletions(history []storage.CommandFrequency, executables []string) Model {
+	m.completer = newCompleter(history, executables)
+	m.input.SetSuggestions(m.completer.candidates())
+	return m
+}
+
+// SearchOpen reports whether the Ctrl+R history search has the keyboard.
+func (m Model) SearchOpen() bool { return m.search != nil }
+
+// OpenSearch opens the history search over this session's commands and
+// asks for the rest of the history.
+func (m Model) OpenSearch() (Model, tea.Cmd) {
+	s := NewHistorySearch(m.sessionCommands())
+	m.search = &s
+	return m, tea.Batch(textinput.Blink, func() tea.Msg { return SearchHistoryMsg{} })
+}
+
+// SetSearchHistory fills the open search with the stored history, after
+// this session's commands.
+func (m Model) SetSearchHistory(commands []string) Model {
+	if m.search == nil {
+		return m
+	}
+	s := m.search.Loaded(append(m.sessionCommands(), commands...))
+	m.search = &s
+	return m
+}
+
+// sessionCommands are the shell commands run in this session, newest
+// first.
+func (m Model) sessionCommands() []string {
+	blocks := m.Blocks()
+	var commands []string
+	for i := len(blocks) - 1; i >= 0; i-- {
+		if b := blocks[i]; b.Type != pipeline.BlockTypeAI && b.Command != "" {
+			commands = append(commands, b.Command)
+		}
+	}
+	return commands
+}
+
 func (m Model) SetInsertMode(insert bool) Model {
 	m.insertMode = insert
 	if insert {
@@ -92,11 +167,53 @@ func (m Model) IsExecuting() bool {
 	return m.isExecuting
 }
 
+// RunningCommand returns the shell command currently executing, if any.
+func (m Model) RunningCommand() string {
+	if !m.isExecuting {
+		return ""
+	}
+	return m.runningCommand
+}
+
+// runningBlock is the newest block whose command is still executing.
+func (m Model) runningBlock() *pipeline.Block {
+	blocks := m.Blocks()
+	for i := len(blocks) - 1; i >= 0; i-- {
+		if blocks[i].Running {
+			return &blocks[i]
+		}
+	}
+	return nil
+}
+
+// CommandRunning reports whether a shell command is in flight, for Ctrl+C
+// to interrupt rather than quit.
+func (m Model) CommandRunning() bool {
+	return m.cmdPlugin != nil && m.runningBlock() != nil
+}
+
+// cancelCommand interrupts the command in flight; its block then ends with
+// exit code 130 once the process group is gone.
+func (m Model) cancelCommand() Model {
+	if block := m.runningBlock(); block != nil {
+		m.cmdPlugin.Cancel(block.ID)
+	}
+	return m
+}
+
 func (m Model) SetExecuting(exec bool) Model {
 	m.isExecuting = exec
 	return m
 }
 
+// Run runs a shell command in a new block, as if it had been entered.
+func (m Model) Run(command string) (Model, tea.Cmd) {
+	m.isExecuting = true
+	m.runningCommand = command
+	m = m.followOutput()
+	return m, executeCommandPipeline(m.cmdPlugin, command)
+}
+
 func (m Model) ExecuteCommand(cmd string) Model {
 	if m.cmdPlugin != nil {
 		m.cmdPlugin.Execute(cmd)
@@ -150,10 +267,12 @@ func (m Model) ToggleFoldBlock(idx int) Model {
 	return m
 }
 
+// ClearBlocks drops the blocks of the session, keeping the pinned ones.
 func (m Model) ClearBlocks() Model {
 	m.State().ClearBlocks()
 	m.selectedBlock = -1
-	return m
+	m.marked = nil
+	return m.followOutput()
 }
 
 func (m Model) Input() textinput.Model {
@@ -199,6 +318,9 @@ func (m Model) StarshipLine() string {
 }
 
 func (m Model) AIMode() string {
+	if m.offline {
+		return "offline"
+	}
 	return "local"
 }
 
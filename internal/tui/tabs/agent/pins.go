@@ -0,0 +1,172 @@
+package agent
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"dev-cli/internal/pipeline"
+	"dev-cli/internal/storage"
+	"dev-cli/internal/tui/theme"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
+)
+
+// BookmarkMsg asks the app to store a block that was just pinned, and to
+// report its ID with Bookmarked.
+type BookmarkMsg struct {
+	BlockID  string
+	Bookmark storage.Bookmark
+}
+
+// UnbookmarkMsg asks the app to drop the bookmark of an unpinned block.
+type UnbookmarkMsg struct {
+	ID int64
+}
+
+// maxPinRows bounds the pinned strip above the blocks; the rest are
+// counted.
+const maxPinRows = 3
+
+// togglePin pins the selected block, or unpins it when it is pinned.
+func (m Model) togglePin() (Model, tea.Cmd) {
+	blocks := m.Blocks()
+	if m.selectedBlock < 0 || m.selectedBlock >= len(blocks) {
+		return m, nil
+	}
+	block := blocks[m.selectedBlock]
+	if block.Pinned {
+		m.State().UpdateBlock(block.ID, func(b *pipeline.Block) {
+			b.Pinned, b.BookmarkID = false, 0
+		})
+		m.notice, m.noticeFailed = "unpinned", false
+		if id := block.BookmarkID; id != 0 {
+			return m, func() tea.Msg { return UnbookmarkMsg{ID: id} }
+		}
+		return m, nil
+	}
+	if block.Running {
+		m.notice, m.noticeFailed = "✗ wait for the command to finish", true
+		return m, nil
+	}
+
+	m.State().UpdateBlock(block.ID, func(b *pipeline.Block) { b.Pinned = true })
+	m.notice, m.noticeFailed = theme.Icon("📌 pinned", "pinned"), false
+	msg := BookmarkMsg{BlockID: block.ID, Bookmark: storage.Bookmark{
+		Kind:      string(block.Type),
+		Command:   block.Command,
+		Output:    block.Output,
+		ExitCode:  block.ExitCode,
+		Directory: block.WorkingDir,
+		CreatedAt: time.Now(),
+	}}
+	return m, func() tea.Msg { return msg }
+}
+
+// Bookmarked records the ID a pinned block was stored under. If it was
+// unpinned or cleared in the meantime, the bookmark is dropped again.
+func (m Model) Bookmarked(blockID string, id int64, err error) (Model, tea.Cmd) {
+	if err != nil {
+		m.notice, m.noticeFailed = fmt.Sprintf("✗ bookmark not saved: %v", err), true
+		return m, nil
+	}
+	if block := m.State().GetBlock(blockID); block == nil || !block.Pinned {
+		return m, func() tea.Msg { return UnbookmarkMsg{ID: id} }
+	}
+	m.State().UpdateBlock(blockID, func(b *pipeline.Block) { b.BookmarkID = id })
+	return m, nil
+}
+
+// SetBookmarks brings back the blocks pinned in earlier sessions, pinned
+// and folded, ahead of this session's blocks.
+func (m Model) SetBookmarks(bookmarks []storage.Bookmark) Model {
+	var restored []pipeline.Block
+	for _, b := range bookmarks {
+		id := fmt.Sprintf("bookmark-%d", b.ID)
+		if m.State().GetBlock(id) != nil {
+			continue
+		}
+		restored = append(restored, pipeline.Block{
+			ID:         id,
+			Type:       pipeline.BlockType(b.Kind),
+			Timestamp:  b.CreatedAt,
+			Command:    b.Command,
+			Output:     b.Output,
+			ExitCode:   b.ExitCode,
+			WorkingDir: b.Directory,
+			Folded:     true,
+			Pinned:     true,
+			BookmarkID: b.ID,
+		})
+	}
+	m.State().PrependBlocks(restored...)
+	if m.selectedBlock >= 0 {
+		m.selectedBlock += len(restored)
+	}
+	return m
+}
+
+// pinnedBlocks are the pinned blocks, oldest first.
+func (m Model) pinnedBlocks() []pipeline.Block {
+	var out []pipeline.Block
+	for _, block := range m.Blocks() {
+		if block.Pinned {
+			out = append(out, block)
+		}
+	}
+	return out
+}
+
+// pinRows is how many lines the pinned strip takes.
+func (m Model) pinRows() int {
+	n := len(m.pinnedBlocks())
+	if n > maxPinRows {
+		return maxPinRows + 1
+	}
+	return n
+}
+
+// renderPins lists the pinned blocks at the top of the blocks area, one
+// line each: the command, how it ended and its last line of output.
+func (m Model) renderPins(width int) []string {
+	pinned := m.pinnedBlocks()
+	selected := ""
+	if blocks := m.Blocks(); m.selectedBlock >= 0 && m.selectedBlock < len(blocks) {
+		selected = blocks[m.selectedBlock].ID
+	}
+	dimStyle := lipgloss.NewStyle().Foreground(theme.Overlay0)
+	var lines []string
+	for i, block := range pinned {
+		if i == maxPinRows && len(pinned) > maxPinRows {
+			lines = append(lines, dimStyle.Render(fmt.Sprintf("   +%d more pinned", len(pinned)-i)))
+			break
+		}
+		command, _, more := strings.Cut(block.Command, "\n")
+		if more {
+			command += " …"
+		}
+		line := theme.Icon("📌", "[pin]") + " ❯ " + command
+		if block.Type == pipeline.BlockTypeAI {
+			line = theme.Icon("📌", "[pin]") + " ? " + command
+		}
+		status := lipgloss.NewStyle().Foreground(theme.Green).Render(" ✓")
+		if block.ExitCode != 0 {
+			status = lipgloss.NewStyle().Foreground(theme.Red).Render(fmt.Sprintf(" ✗ %d", block.ExitCode))
+		}
+		if block.Type == pipeline.BlockTypeAI {
+			status = ""
+		}
+		last := ""
+		if out := strings.Split(strings.TrimRight(block.Output, "\n"), "\n"); out[len(out)-1] != "" {
+			last = dimStyle.Render("  " + ansi.Strip(out[len(out)-1]))
+		}
+		style := lipgloss.NewStyle().Foreground(theme.Peach)
+		if block.ID == selected {
+			style = style.Bold(true)
+		}
+		lines = append(lines, ansi.Truncate(style.Render(line)+status+last, width, "…"))
+	}
+	return lines
+}
@@ -0,0 +1,50 @@
+package agent
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"dev-cli/internal/pipeline"
+	"dev-cli/internal/storage"
+)
+
+// ReplaySession adds the commands of a stored session, oldest first, as
+// folded blocks with the output stored along with them, so R and E can run
+// them again one by one. Commands already replayed aren't added twice.
+func (m Model) ReplaySession(items []storage.HistoryItem) Model {
+	added := 0
+	for _, item := range items {
+		id := fmt.Sprintf("history-%d", item.ID)
+		if m.State().GetBlock(id) != nil {
+			continue
+		}
+		var details struct {
+			Output string `json:"output"`
+		}
+		_ = json.Unmarshal([]byte(item.Details), &details)
+		m.State().AddBlock(pipeline.Block{
+			ID:         id,
+			Type:       pipeline.BlockTypeCommand,
+			Timestamp:  item.Timestamp,
+			Command:    item.Command,
+			Output:     details.Output,
+			ExitCode:   item.ExitCode,
+			Duration:   time.Duration(item.DurationMs) * time.Millisecond,
+			WorkingDir: item.Directory,
+			Folded:     true,
+		})
+		added++
+	}
+	if added == 0 {
+		m.notice, m.noticeFailed = "nothing to replay from that session", false
+		return m
+	}
+	noun := "commands"
+	if added == 1 {
+		noun = "command"
+	}
+	m.notice, m.noticeFailed = fmt.Sprintf("replayed %d %s • R runs the selected one again", added, noun), false
+	m.selectedBlock = len(m.Blocks()) - 1
+	return m.followOutput()
+}
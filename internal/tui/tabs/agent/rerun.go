@@ -0,0 +1,57 @@
+package agent
+
+import (
+	"strings"
+
+	"dev-cli/internal/executor"
+	"dev-cli/internal/pipeline"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// selectedCommand is the command of the selected block, if it ran one; AI
+// answers have nothing to run again.
+func (m Model) selectedCommand() (string, bool) {
+	blocks := m.Blocks()
+	if m.selectedBlock < 0 || m.selectedBlock >= len(blocks) {
+		return "", false
+	}
+	block := blocks[m.selectedBlock]
+	command := strings.TrimSpace(block.Command)
+	if block.Type != pipeline.BlockTypeCommand || command == "" {
+		return "", false
+	}
+	return command, true
+}
+
+// rerunBlock runs the selected block's command again, as a new block and
+// the way Enter would run it.
+func (m Model) rerunBlock() (Model, tea.Cmd) {
+	command, ok := m.selectedCommand()
+	if !ok {
+		m.notice, m.noticeFailed = "✗ no command to re-run in this block", true
+		return m, nil
+	}
+	m.completer = m.completer.record(command)
+	m.input.SetSuggestions(m.completer.candidates())
+	if executor.IsInteractive(command) {
+		return m.runInteractive(command)
+	}
+	return m.Run(command)
+}
+
+// editBlock puts the selected block's command on the input line, so Enter
+// runs the edited one as a new block.
+func (m Model) editBlock() Model {
+	command, ok := m.selectedCommand()
+	if !ok {
+		m.notice, m.noticeFailed = "✗ no command to edit in this block", true
+		return m
+	}
+	m = m.SetInsertMode(true)
+	m.input.SetValue(command)
+	m.input.CursorEnd()
+	// Refreshing drops a ghost suggestion for what was there before.
+	m.input.SetSuggestions(m.completer.candidates())
+	return m
+}
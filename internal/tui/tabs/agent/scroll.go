@@ -0,0 +1,102 @@
+package agent
+
+import (
+	"strings"
+
+	"dev-cli/internal/tui/theme"
+
+	"github.com/charmbracelet/bubbles/viewport"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// blocksAreaSize is the width and height View gives the blocks area.
+func (m Model) blocksAreaSize() (int, int) {
+	width := max(m.width-2, 40)
+	height := m.height - 8 - (m.editorHeight() - 1)
+	if m.StarshipLine() != "" {
+		height--
+	}
+	return width, height
+}
+
+// blockLines renders every block into the lines of the blocks area, and
+// returns the line each block starts on.
+func (m Model) blockLines(width int) ([]string, []int) {
+	blocks := m.Blocks()
+	var lines []string
+	starts := make([]int, 0, len(blocks))
+	for i, block := range blocks {
+		starts = append(starts, len(lines))
+		lines = append(lines, strings.Split(m.renderBlock(block, i, width-6), "\n")...)
+	}
+
+	// A streaming block shows its own progress.
+	if m.isExecuting && (len(blocks) == 0 || !blocks[len(blocks)-1].Running) {
+		execStyle := lipgloss.NewStyle().
+			Foreground(theme.Yellow).
+			Italic(true)
+		lines = append(lines, execStyle.Render("  ◌ Executing..."))
+	}
+	return lines, starts
+}
+
+// blocksViewport lays the blocks out in the scrollback for an area of the
+// given size, with the "/" matches marked, and returns the line each block
+// starts on. It follows the newest output unless the user scrolled back.
+func (m Model) blocksViewport(width, height int) (viewport.Model, []int) {
+	lines, starts := m.blockLines(width)
+	if m.find != nil {
+		lines = m.find.highlight(lines)
+	}
+
+	vp := m.viewport
+	vp.Width = width
+	// The panel's first line is its header, then come the pinned blocks.
+	vp.Height = max(max(height-4, 3)-1-m.pinRows(), 1)
+	vp.SetContent(strings.Join(lines, "\n"))
+	if m.scrolledBack {
+		vp.SetYOffset(vp.YOffset)
+	} else {
+		vp.GotoBottom()
+	}
+	return vp, starts
+}
+
+// scrollBlocks moves the scrollback with fn, leaving the selection alone.
+// Reaching the bottom pins the view to new output again.
+func (m Model) scrollBlocks(fn func(vp *viewport.Model)) Model {
+	vp, _ := m.blocksViewport(m.blocksAreaSize())
+	fn(&vp)
+	m.viewport = vp
+	m.scrolledBack = !vp.AtBottom()
+	return m
+}
+
+// revealSelected scrolls just enough to show the selected block, as much
+// of it as fits, top first.
+func (m Model) revealSelected() Model {
+	vp, starts := m.blocksViewport(m.blocksAreaSize())
+	if m.selectedBlock < 0 || m.selectedBlock >= len(starts) {
+		return m
+	}
+	top := starts[m.selectedBlock]
+	bottom := vp.TotalLineCount() - 1
+	if m.selectedBlock+1 < len(starts) {
+		bottom = starts[m.selectedBlock+1] - 1
+	}
+	switch {
+	case top < vp.YOffset:
+		vp.SetYOffset(top)
+	case bottom >= vp.YOffset+vp.Height:
+		vp.SetYOffset(min(top, bottom-vp.Height+1))
+	}
+	m.viewport = vp
+	m.scrolledBack = !vp.AtBottom()
+	return m
+}
+
+// followOutput pins the scrollback to the newest output.
+func (m Model) followOutput() Model {
+	m.scrolledBack = false
+	return m
+}
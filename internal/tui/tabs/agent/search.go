@@ -0,0 +1,175 @@
+package agent
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"dev-cli/internal/tui/theme"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/sahilm/fuzzy"
+)
+
+// SearchHistoryMsg asks the app for the command history to search.
+type SearchHistoryMsg struct{}
+
+// HistorySearch is the Ctrl+R overlay: it fuzzy-matches the typed query
+// against past commands, newest first, and hands the chosen one to the
+// input line.
+type HistorySearch struct {
+	input    textinput.Model
+	commands []string
+	matches  fuzzy.Matches
+	cursor   int
+	loading  bool
+}
+
+// NewHistorySearch opens a search over this session's commands until the
+// full history arrives.
+func NewHistorySearch(session []string) HistorySearch {
+	ti := textinput.New()
+	ti.Prompt = "> "
+	ti.PromptStyle = lipgloss.NewStyle().Foreground(theme.Mauve).Bold(true)
+	ti.Placeholder = "search history"
+	ti.Focus()
+	s := HistorySearch{input: ti, loading: true}
+	return s.SetCommands(session)
+}
+
+// SetCommands replaces the searched commands, newest first, dropping
+// repeats of a command.
+func (s HistorySearch) SetCommands(commands []string) HistorySearch {
+	seen := make(map[string]bool, len(commands))
+	s.commands = s.commands[:0:0]
+	for _, c := range commands {
+		if c = strings.TrimSpace(c); c != "" && !seen[c] {
+			seen[c] = true
+			s.commands = append(s.commands, c)
+		}
+	}
+	return s.refilter()
+}
+
+// Loaded records that the full history arrived.
+func (s HistorySearch) Loaded(commands []string) HistorySearch {
+	s.loading = false
+	return s.SetCommands(commands)
+}
+
+func (s HistorySearch) refilter() HistorySearch {
+	query := s.input.Value()
+	if query == "" {
+		s.matches = make(fuzzy.Matches, len(s.commands))
+		for i, c := range s.commands {
+			s.matches[i] = fuzzy.Match{Str: c, Index: i}
+		}
+	} else {
+		s.matches = fuzzy.Find(query, s.commands)
+	}
+	s.cursor = min(s.cursor, max(len(s.matches)-1, 0))
+	return s
+}
+
+// Selected is the highlighted command, "" without a match.
+func (s HistorySearch) Selected() string {
+	if s.cursor < len(s.matches) {
+		return s.matches[s.cursor].Str
+	}
+	return ""
+}
+
+// Update handles a key while the search is open. Choosing a command or
+// cancelling is left to the caller.
+func (s HistorySearch) Update(msg tea.KeyMsg) (HistorySearch, tea.Cmd) {
+	switch msg.String() {
+	case "up", "ctrl+p", "ctrl+k", "ctrl+r":
+		// Ctrl+R again steps to the next (older, or worse) match, as in bash.
+		if s.cursor < len(s.matches)-1 {
+			s.cursor++
+		}
+		return s, nil
+	case "down", "ctrl+n", "ctrl+j":
+		if s.cursor > 0 {
+			s.cursor--
+		}
+		return s, nil
+	}
+
+	before := s.input.Value()
+	var cmd tea.Cmd
+	s.input, cmd = s.input.Update(msg)
+	if s.input.Value() != before {
+		s.cursor = 0
+		s = s.refilter()
+	}
+	return s, cmd
+}
+
+// View renders the search like fzf: the best match at the bottom, just
+// above the query.
+func (s HistorySearch) View(width, height int) string {
+	headerStyle := lipgloss.NewStyle().Foreground(theme.Lavender).Bold(true)
+	dimStyle := lipgloss.NewStyle().Foreground(theme.Overlay0)
+	textStyle := lipgloss.NewStyle().Foreground(theme.Text)
+	hitStyle := lipgloss.NewStyle().Foreground(theme.Peach).Bold(true)
+	selectedStyle := lipgloss.NewStyle().Background(theme.Surface1).Foreground(theme.Lavender).Bold(true)
+
+	count := fmt.Sprintf(" %d/%d", len(s.matches), len(s.commands))
+	if s.loading {
+		count += " loading…"
+	}
+	header := headerStyle.Render("⌕ History") + dimStyle.Render(count)
+
+	// Header, query and help take three of the lines.
+	rows := max(height-3, 1)
+	start := max(s.cursor-rows+1, 0)
+	end := min(start+rows, len(s.matches))
+
+	var lines []string
+	for i := end - 1; i >= start; i-- {
+		m := s.matches[i]
+		line := truncateRunes(m.Str, width-6)
+		if i == s.cursor {
+			lines = append(lines, selectedStyle.Width(width-4).Render("▌ "+line))
+			continue
+		}
+		var b strings.Builder
+		for j, r := range []rune(line) {
+			if slices.Contains(m.MatchedIndexes, j) {
+				b.WriteString(hitStyle.Render(string(r)))
+			} else {
+				b.WriteString(textStyle.Render(string(r)))
+			}
+		}
+		lines = append(lines, "  "+b.String())
+	}
+	if len(s.matches) == 0 && !s.loading {
+		lines = append(lines, dimStyle.Render("  no matching command"))
+	}
+	for len(lines) < rows {
+		lines = append([]string{""}, lines...)
+	}
+
+	in := s.input
+	in.Width = max(width-8, 10)
+	lines = append([]string{header}, lines...)
+	lines = append(lines, in.View(), dimStyle.Render("Enter insert • ↑/↓ or Ctrl+R move • Esc cancel"))
+
+	return lipgloss.NewStyle().
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(theme.Mauve).
+		Width(width).
+		Render(strings.Join(lines, "\n"))
+}
+
+// truncateRunes cuts s to n runes, marking the cut with an ellipsis.
+func truncateRunes(s string, n int) string {
+	r := []rune(s)
+	if n <= 1 || len(r) <= n {
+		return s
+	}
+	return string(r[:n-1]) + "…"
+}
@@ -1,11 +1,16 @@
 package agent
 
 import (
+	"strings"
+
 	"dev-cli/internal/executor"
 	"dev-cli/internal/pipeline"
+	"dev-cli/internal/plugins/ai"
 	"dev-cli/internal/plugins/command"
+	"dev-cli/internal/storage"
 
 	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/viewport"
 	tea "github.com/charmbracelet/bubbletea"
 )
 
@@ -19,7 +24,31 @@ type KeyMap struct {
 	Clear    key.Binding
 	ToggleAI key.Binding
 	RunFix   key.Binding
+	Rerun    key.Binding
+	Edit     key.Binding
+	Pin      key.Binding
 	Dismiss  key.Binding
+	Copy     key.Binding
+	PageUp   key.Binding
+	PageDown key.Binding
+	Bottom   key.Binding
+	Find     key.Binding
+	FindNext key.Binding
+	FindPrev key.Binding
+	Cancel   key.Binding
+	// Background runs the input as a job, like a trailing "&".
+	Background  key.Binding
+	CollectJobs key.Binding
+	StopJob     key.Binding
+	// Interactive runs the input on the whole terminal, for programs it
+	// does not recognise as needing it.
+	Interactive key.Binding
+	// Editor opens the multi-line editor, where RunEditor runs its text.
+	Editor    key.Binding
+	RunEditor key.Binding
+	// Mark picks blocks for a drafted workflow, which Draft asks for.
+	Mark  key.Binding
+	Draft key.Binding
 }
 
 func DefaultKeyMap() KeyMap {
@@ -60,15 +89,86 @@ func DefaultKeyMap() KeyMap {
 			key.WithKeys("r"),
 			key.WithHelp("r", "run fix"),
 		),
+		Rerun: key.NewBinding(
+			key.WithKeys("R"),
+			key.WithHelp("R", "re-run"),
+		),
+		Edit: key.NewBinding(
+			key.WithKeys("E"),
+			key.WithHelp("E", "edit and re-run"),
+		),
+		Pin: key.NewBinding(
+			key.WithKeys("P"),
+			key.WithHelp("P", "pin"),
+		),
 		Dismiss: key.NewBinding(
 			key.WithKeys("d"),
 			key.WithHelp("d", "dismiss"),
 		),
+		Copy: key.NewBinding(
+			key.WithKeys("y", "Y", "c"),
+			key.WithHelp("y/Y", "copy output/command"),
+		),
+		PageUp: key.NewBinding(
+			key.WithKeys("pgup", "ctrl+u"),
+			key.WithHelp("PgUp", "scroll up"),
+		),
+		PageDown: key.NewBinding(
+			key.WithKeys("pgdown", "ctrl+d"),
+			key.WithHelp("PgDn", "scroll down"),
+		),
+		Bottom: key.NewBinding(
+			key.WithKeys("G"),
+			key.WithHelp("gg/G", "top/bottom"),
+		),
+		Find: key.NewBinding(
+			key.WithKeys("/"),
+			key.WithHelp("/", "find"),
+		),
+		FindNext: key.NewBinding(
+			key.WithKeys("n"),
+			key.WithHelp("n/N", "next/prev match"),
+		),
+		FindPrev: key.NewBinding(
+			key.WithKeys("N"),
+		),
+		Cancel: key.NewBinding(
+			key.WithKeys("ctrl+c"),
+		),
+		Background: key.NewBinding(
+			key.WithKeys("alt+enter"),
+		),
+		CollectJobs: key.NewBinding(
+			key.WithKeys("b"),
+		),
+		StopJob: key.NewBinding(
+			key.WithKeys("B"),
+		),
+		Interactive: key.NewBinding(
+			key.WithKeys("ctrl+o"),
+		),
+		Editor: key.NewBinding(
+			key.WithKeys("ctrl+e"),
+		),
+		RunEditor: key.NewBinding(
+			key.WithKeys("ctrl+s"),
+		),
+		Mark: key.NewBinding(
+			key.WithKeys("m"),
+			key.WithHelp("m", "mark for workflow"),
+		),
+		Draft: key.NewBinding(
+			key.WithKeys("W"),
+			key.WithHelp("W", "draft workflow"),
+		),
 	}
 }
 
 type CommandExecutedMsg struct {
 	BlockID string
+	// FixFor is the error signature of the failure the command was run to
+	// fix, so its outcome counts for or against that solution.
+	FixFor string
 }
 
 type AIResponseMsg struct {
@@ -83,6 +183,7 @@ func (m Model) Update(msg tea.Msg, keys KeyMap) (Model, tea.Cmd) {
 	switch msg := msg.(type) {
 	case CommandExecutedMsg:
 		m.isExecuting = false
+		m.runningCommand = ""
 		blocks := m.Blocks()
 		if len(blocks) > 0 {
 			m.selectedBlock = len(blocks) - 1
@@ -94,30 +195,57 @@ func (m Model) Update(msg tea.Msg, keys KeyMap) (Model, tea.Cmd) {
 		return m, nil
 
 	case tea.KeyMsg:
+		m.notice = ""
+		if key.Matches(msg, keys.Cancel) && m.CommandRunning() {
+			return m.cancelCommand(), nil
+		}
+		if m.search != nil {
+			return m.updateSearch(msg)
+		}
+		if m.FindOpen() {
+			return m.updateFind(msg)
+		}
+		if m.editor != nil {
+			return m.updateEditor(msg, keys)
+		}
+		if msg.String() == "ctrl+r" {
+			return m.OpenSearch()
+		}
+
 		if m.insertMode {
 			switch {
 			case key.Matches(msg, keys.Escape):
 				m = m.SetInsertMode(false)
 				return m, nil
 
-			case key.Matches(msg, keys.Enter):
+			case key.Matches(msg, keys.Enter), key.Matches(msg, keys.Background), key.Matches(msg, keys.Interactive):
 				input := m.input.Value()
 				if input == "" {
 					return m, nil
 				}
-
 				m.input.SetValue("")
+				return m.submit(input, key.Matches(msg, keys.Background), key.Matches(msg, keys.Interactive))
 
-				if executor.IsAIQuery(input) {
-					queryType, query := executor.ParseAIQuery(input)
-					return m.handleAIQuery(queryType, query)
-				}
+			case key.Matches(msg, keys.Editor):
+				return m.openEditor()
 
-				m.isExecuting = true
-				return m, executeCommandPipeline(m.cmdPlugin, input)
+			// Ctrl+U and Ctrl+D edit the input here; only the page keys scroll.
+			case msg.Type == tea.KeyPgUp:
+				return m.scrollBlocks(func(vp *viewport.Model) { vp.PageUp() }), nil
+
+			case msg.Type == tea.KeyPgDown:
+				return m.scrollBlocks(func(vp *viewport.Model) { vp.PageDown() }), nil
 
 			case key.Matches(msg, keys.ToggleAI):
 				return m, nil
+
+			case msg.String() == "tab":
+				// A lone candidate completes right away; several cycle.
+				if matches := m.input.MatchedSuggestions(); len(matches) == 1 {
+					m.input.SetValue(matches[0])
+					m.input.CursorEnd()
+					return m, nil
+				}
 			}
 
 			var cmd tea.Cmd
@@ -127,10 +255,28 @@ func (m Model) Update(msg tea.Msg, keys KeyMap) (Model, tea.Cmd) {
 			cmds = append(cmds, cmd)
 
 		} else {
+			pendingG := m.pendingG
+			m.pendingG = false
+
 			switch {
 			case key.Matches(msg, keys.Insert):
 				m = m.SetInsertMode(true)
 
+			case key.Matches(msg, keys.Editor):
+				return m.openEditor()
+
+			case key.Matches(msg, keys.Find):
+				return m.openFind()
+
+			case m.find != nil && key.Matches(msg, keys.FindNext):
+				m = m.jumpToMatch(1)
+
+			case m.find != nil && key.Matches(msg, keys.FindPrev):
+				m = m.jumpToMatch(-1)
+
+			case m.find != nil && key.Matches(msg, keys.Escape):
+				m.find = nil
+
 			case key.Matches(msg, keys.Up):
 				blocks := m.Blocks()
 				if len(blocks) > 0 {
@@ -139,12 +285,36 @@ func (m Model) Update(msg tea.Msg, keys KeyMap) (Model, tea.Cmd) {
 					} else if m.selectedBlock == -1 {
 						m.selectedBlock = len(blocks) - 1
 					}
+					m = m.revealSelected()
 				}
 
 			case key.Matches(msg, keys.Down):
 				blocks := m.Blocks()
 				if len(blocks) > 0 && m.selectedBlock < len(blocks)-1 {
 					m.selectedBlock++
+					m = m.revealSelected()
+				}
+
+			case key.Matches(msg, keys.PageUp):
+				m = m.scrollBlocks(func(vp *viewport.Model) { vp.PageUp() })
+
+			case key.Matches(msg, keys.PageDown):
+				m = m.scrollBlocks(func(vp *viewport.Model) { vp.PageDown() })
+
+			case key.Matches(msg, keys.Bottom):
+				m = m.followOutput()
+
+			case key.Matches(msg, keys.CollectJobs):
+				m = m.collectJobs()
+
+			case key.Matches(msg, keys.StopJob):
+				m = m.stopJob()
+
+			case msg.String() == "g":
+				if pendingG {
+					m = m.scrollBlocks(func(vp *viewport.Model) { vp.GotoTop() })
+				} else {
+					m.pendingG = true
 				}
 
 			case key.Matches(msg, keys.Fold):
@@ -156,18 +326,22 @@ func (m Model) Update(msg tea.Msg, keys KeyMap) (Model, tea.Cmd) {
 			case key.Matches(msg, keys.RunFix):
 				blocks := m.Blocks()
 				if m.selectedBlock >= 0 && m.selectedBlock < len(blocks) {
-					block := blocks[m.selectedBlock]
-					if block.AISuggestion != "" {
-						m.isExecuting = true
-						return m, executeCommandPipeline(m.cmdPlugin, block.AISuggestion)
-					}
-					suggestions := m.State().GetSuggestionsForBlock(block.ID)
-					if len(suggestions) > 0 && suggestions[0].Command != "" {
+					if fix := m.fixFor(blocks[m.selectedBlock]); fix != "" {
 						m.isExecuting = true
-						return m, executeCommandPipeline(m.cmdPlugin, suggestions[0].Command)
+						m.runningCommand = fix
+						return m, executeFix(m.cmdPlugin, m.failureBefore(m.selectedBlock), fix)
 					}
 				}
 
+			case key.Matches(msg, keys.Rerun):
+				return m.rerunBlock()
+
+			case key.Matches(msg, keys.Edit):
+				return m.editBlock(), nil
+
+			case key.Matches(msg, keys.Pin):
+				return m.togglePin()
+
 			case key.Matches(msg, keys.Dismiss):
 				blocks := m.Blocks()
 				if m.selectedBlock >= 0 && m.selectedBlock < len(blocks) {
@@ -175,18 +349,19 @@ func (m Model) Update(msg tea.Msg, keys KeyMap) (Model, tea.Cmd) {
 					m.State().UpdateBlock(block.ID, func(b *pipeline.Block) {
 						b.AISuggestion = ""
 					})
+					m.State().ClearAnnotations(block.ID, "")
 				}
 
-			case msg.String() == "g":
-				blocks := m.Blocks()
-				if len(blocks) > 0 {
-					m.selectedBlock = 0
-				}
+			case key.Matches(msg, keys.Mark):
+				m = m.toggleMark()
 
-			case msg.String() == "G":
+			case key.Matches(msg, keys.Draft):
+				return m.draftWorkflow()
+
+			case key.Matches(msg, keys.Copy):
 				blocks := m.Blocks()
-				if len(blocks) > 0 {
-					m.selectedBlock = len(blocks) - 1
+				if m.selectedBlock >= 0 && m.selectedBlock < len(blocks) {
+					return m, copyBlock(blocks[m.selectedBlock], msg.String())
 				}
 
 			case msg.String() == "?":
@@ -195,18 +370,95 @@ func (m Model) Update(msg tea.Msg, keys KeyMap) (Model, tea.Cmd) {
 				var cmd tea.Cmd
 				m.input, cmd = m.input.Update(msg)
 				return m, cmd
+
+			default:
+				blocks := m.Blocks()
+				if m.selectedBlock >= 0 && m.selectedBlock < len(blocks) {
+					for _, a := range m.State().GetAnnotationsForBlock(blocks[m.selectedBlock].ID) {
+						if a.Key != "" && a.Key == msg.String() && a.Command != "" {
+							m.isExecuting = true
+							m.runningCommand = a.Command
+							return m, executeCommandPipeline(m.cmdPlugin, a.Command)
+						}
+					}
+				}
 			}
 		}
 	}
 
-	var vpCmd tea.Cmd
-	m.viewport, vpCmd = m.viewport.Update(msg)
-	cmds = append(cmds, vpCmd)
-
 	return m, tea.Batch(cmds...)
 }
 
+// submit runs what was entered: an AI query, a background job, a program
+// that needs the whole terminal, or a command in a block.
+func (m Model) submit(input string, background, interactive bool) (Model, tea.Cmd) {
+	isAI := executor.IsAIQuery(input)
+	// Multi-line commands from the editor make poor one-line suggestions.
+	if !isAI && !strings.Contains(input, "\n") {
+		m.completer = m.completer.record(input)
+	}
+	// Refreshing also drops the ghost of what was just entered.
+	m.input.SetSuggestions(m.completer.candidates())
+
+	if isAI {
+		queryType, query := executor.ParseAIQuery(input)
+		return m.handleAIQuery(queryType, query)
+	}
+
+	if command, ok := backgroundCommand(input); ok || background {
+		return m.startJob(command), nil
+	}
+	if executor.IsInteractive(input) || interactive {
+		return m.runInteractive(input)
+	}
+
+	return m.Run(input)
+}
+
+// updateSearch handles a key while the history search is open: Enter puts
+// the chosen command on the input line, to edit or run, and Esc cancels.
+func (m Model) updateSearch(msg tea.KeyMsg) (Model, tea.Cmd) {
+	switch msg.String() {
+	case "esc":
+		m.search = nil
+		return m, nil
+	case "enter":
+		selected := m.search.Selected()
+		m.search = nil
+		if selected == "" {
+			return m, nil
+		}
+		m = m.SetInsertMode(true)
+		m.input.SetValue(selected)
+		m.input.CursorEnd()
+		// Refreshing drops a ghost suggestion left from before the search.
+		m.input.SetSuggestions(m.completer.candidates())
+		return m, nil
+	}
+
+	s, cmd := m.search.Update(msg)
+	m.search = &s
+	return m, cmd
+}
+
 func (m Model) handleAIQuery(queryType, query string) (Model, tea.Cmd) {
+	// Everything but free-form questions needs the local model; answer with
+	// the setup prompt right away instead of waiting out a request timeout.
+	if ollama := m.State().Availability(pipeline.SubsystemOllama); ollama.Missing() && queryType != "question" {
+		if m.cmdPlugin != nil {
+			title := "@" + queryType
+			if query != "" {
+				title += " " + query
+			}
+			block := m.cmdPlugin.ExecuteAI(title)
+			m.State().UpdateBlock(block.ID, func(b *pipeline.Block) {
+				b.Output = ollama.Hint
+			})
+			m.selectedBlock = len(m.Blocks()) - 1
+		}
+		return m, nil
+	}
+
 	m.isExecuting = true
 
 	switch queryType {
@@ -214,7 +466,7 @@ func (m Model) handleAIQuery(queryType, query string) (Model, tea.Cmd) {
 		blocks := m.Blocks()
 		for i := len(blocks) - 1; i >= 0; i-- {
 			if blocks[i].Type == pipeline.BlockTypeCommand && blocks[i].ExitCode != 0 {
-				return m, requestAIFix(m.cmdPlugin, blocks[i])
+				return m, requestAIFix(m.cmdPlugin, m.aiPlugin, blocks[i])
 			}
 		}
 		m = m.ExecuteAIQuery("No previous error to fix")
@@ -225,13 +477,19 @@ func (m Model) handleAIQuery(queryType, query string) (Model, tea.Cmd) {
 		blocks := m.Blocks()
 		for i := len(blocks) - 1; i >= 0; i-- {
 			if blocks[i].Type == pipeline.BlockTypeCommand {
-				return m, requestAIExplain(m.cmdPlugin, blocks[i])
+				return m, requestAIExplain(m.cmdPlugin, m.aiPlugin, blocks[i])
 			}
 		}
 		m = m.ExecuteAIQuery("No previous command to explain")
 		m.isExecuting = false
 		return m, nil
 
+	case "commit":
+		return m, requestAICommitMsg(m.cmdPlugin, m.aiPlugin)
+
+	case "review":
+		return m, requestAIReview(m.cmdPlugin, m.aiPlugin, query)
+
 	case "question":
 		return m, requestAIQuestion(m.cmdPlugin, query)
 
@@ -250,6 +508,56 @@ func executeCommandPipeline(cmdPlugin *command.Plugin, cmd string) tea.Cmd {
 	}
 }
 
+// fixFor is the fix suggested for block: the AI's, then the first
+// suggestion or annotation that comes with a command.
+func (m Model) fixFor(block pipeline.Block) string {
+	if block.AISuggestion != "" {
+		return block.AISuggestion
+	}
+	for _, s := range m.State().GetSuggestionsForBlock(block.ID) {
+		if s.Command != "" {
+			return s.Command
+		}
+	}
+	for _, a := range m.State().GetAnnotationsForBlock(block.ID) {
+		if a.Command != "" {
+			return a.Command
+		}
+	}
+	return ""
+}
+
+// failureBefore is the failed command a fix offered on block i is for:
+// the block itself, or for an AI answer the command right before it. It
+// returns nil when that command didn't fail.
+func (m Model) failureBefore(i int) *pipeline.Block {
+	blocks := m.Blocks()
+	for ; i >= 0 && i < len(blocks); i-- {
+		if b := blocks[i]; b.Type != pipeline.BlockTypeAI {
+			if b.ExitCode == 0 || b.ExitCode == 130 {
+				return nil
+			}
+			return &b
+		}
+	}
+	return nil
+}
+
+// executeFix runs fix, marking the result with the error signature of
+// the failure it is for, if known.
+func executeFix(cmdPlugin *command.Plugin, failed *pipeline.Block, fix string) tea.Cmd {
+	run := executeCommandPipeline(cmdPlugin, fix)
+	if failed == nil {
+		return run
+	}
+	signature := storage.GenerateErrorSignature(failed.Command, failed.ExitCode, failed.Output)
+	return func() tea.Msg {
+		msg := run().(CommandExecutedMsg)
+		msg.FixFor = signature
+		return msg
+	}
+}
+
 func requestAIQuestion(cmdPlugin *command.Plugin, query string) tea.Cmd {
 	return func() tea.Msg {
 		if cmdPlugin != nil {
@@ -260,22 +568,58 @@ func requestAIQuestion(cmdPlugin *command.Plugin, query string) tea.Cmd {
 	}
 }
 
-func requestAIFix(cmdPlugin *command.Plugin, block pipeline.Block) tea.Cmd {
+// requestAIFix answers from the explanation the AI plugin prefetched when
+// the command failed, so it is usually instant.
+func requestAIFix(cmdPlugin *command.Plugin, aiPlugin *ai.Plugin, block pipeline.Block) tea.Cmd {
 	return func() tea.Msg {
-		if cmdPlugin != nil {
-			b := cmdPlugin.ExecuteAI("Fix: " + block.Command + "\nError: " + block.Output)
+		if cmdPlugin == nil {
+			return AIResponseMsg{BlockID: ""}
+		}
+		b := cmdPlugin.ExecuteAI("Fix: " + block.Command + "\nError: " + block.Output)
+		if aiPlugin == nil {
 			return AIResponseMsg{BlockID: b.ID}
 		}
-		return AIResponseMsg{BlockID: ""}
+		resp, err := aiPlugin.ExplainBlock(b.ID, block)
+		return AIResponseMsg{BlockID: b.ID, Response: resp, Error: err}
 	}
 }
 
-func requestAIExplain(cmdPlugin *command.Plugin, block pipeline.Block) tea.Cmd {
+func requestAIExplain(cmdPlugin *command.Plugin, aiPlugin *ai.Plugin, block pipeline.Block) tea.Cmd {
 	return func() tea.Msg {
-		if cmdPlugin != nil {
-			b := cmdPlugin.ExecuteAI("Explain: " + block.Command + "\nOutput: " + block.Output)
+		if cmdPlugin == nil {
+			return AIResponseMsg{BlockID: ""}
+		}
+		b := cmdPlugin.ExecuteAI("Explain: " + block.Command + "\nOutput: " + block.Output)
+		if aiPlugin == nil {
 			return AIResponseMsg{BlockID: b.ID}
 		}
-		return AIResponseMsg{BlockID: ""}
+		resp, err := aiPlugin.ExplainBlock(b.ID, block)
+		return AIResponseMsg{BlockID: b.ID, Response: resp, Error: err}
+	}
+}
+
+func requestAICommitMsg(cmdPlugin *command.Plugin, aiPlugin *ai.Plugin) tea.Cmd {
+	return func() tea.Msg {
+		if cmdPlugin == nil || aiPlugin == nil {
+			return AIResponseMsg{BlockID: ""}
+		}
+		b := cmdPlugin.ExecuteAI("Draft commit message for staged changes")
+		msg, err := aiPlugin.DraftCommitMessage(b.ID)
+		return AIResponseMsg{BlockID: b.ID, Response: msg, Error: err}
+	}
+}
+
+func requestAIReview(cmdPlugin *command.Plugin, aiPlugin *ai.Plugin, ref string) tea.Cmd {
+	return func() tea.Msg {
+		if cmdPlugin == nil || aiPlugin == nil {
+			return AIResponseMsg{BlockID: ""}
+		}
+		title := "Review changes"
+		if ref != "" {
+			title += " against " + ref
+		}
+		b := cmdPlugin.ExecuteAI(title)
+		resp, err := aiPlugin.ReviewChanges(b.ID, ref)
+		return AIResponseMsg{BlockID: b.ID, Response: resp, Error: err}
 	}
 }
@@ -3,37 +3,40 @@ package agent
 import (
 	"fmt"
 	"os"
+	"slices"
 	"strings"
+	"time"
 
 	"dev-cli/internal/pipeline"
+	"dev-cli/internal/tui/components"
 	"dev-cli/internal/tui/theme"
 
 	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
 )
 
 func (m Model) View() string {
-	contentWidth := m.width - 2
-	if contentWidth < 40 {
-		contentWidth = 40
-	}
+	contentWidth, blocksHeight := m.blocksAreaSize()
 
 	var content strings.Builder
 
 	content.WriteString(m.renderHeaderBar(contentWidth) + "\n")
 
-	starshipHeight := 0
-	if m.StarshipLine() != "" {
-		starshipHeight = 1
+	if m.search != nil {
+		content.WriteString(m.search.View(contentWidth, max(blocksHeight-4, 3)) + "\n")
+	} else {
+		content.WriteString(m.renderBlocksArea(contentWidth, blocksHeight) + "\n")
 	}
-	blocksHeight := m.height - 8 - starshipHeight
-
-	content.WriteString(m.renderBlocksArea(contentWidth, blocksHeight) + "\n")
 
 	if m.StarshipLine() != "" {
 		content.WriteString(m.renderStarshipBar(contentWidth) + "\n")
 	}
 
-	content.WriteString(m.renderInputArea(contentWidth))
+	if m.editor != nil {
+		content.WriteString(m.renderEditor(contentWidth))
+	} else {
+		content.WriteString(m.renderInputArea(contentWidth))
+	}
 
 	return content.String()
 }
@@ -43,7 +46,12 @@ func (m Model) renderHeaderBar(width int) string {
 		Bold(true).
 		Foreground(theme.Lavender)
 
+	// Narrow terminals keep the icon, a shorter path and tighter widgets.
+	compact := components.Compact(m.width)
 	title := titleStyle.Render("◈ Agent")
+	if compact {
+		title = titleStyle.Render("◈")
+	}
 
 	cwdStyle := lipgloss.NewStyle().
 		Foreground(theme.Overlay0).
@@ -54,6 +62,9 @@ func (m Model) renderHeaderBar(width int) string {
 		cwdDisplay = "~" + cwdDisplay[len(home):]
 	}
 	maxCwdLen := 30
+	if compact {
+		maxCwdLen = 16
+	}
 	if len(cwdDisplay) > maxCwdLen {
 		cwdDisplay = "..." + cwdDisplay[len(cwdDisplay)-maxCwdLen+3:]
 	}
@@ -70,7 +81,9 @@ func (m Model) renderHeaderBar(width int) string {
 			}
 		}
 		dockerStyle := lipgloss.NewStyle().Foreground(theme.Green)
-		widgets = append(widgets, dockerStyle.Render(fmt.Sprintf("🐳 %d", running)))
+		widgets = append(widgets, dockerStyle.Render(fmt.Sprintf("%s %d", theme.Icon("🐳", "docker"), running)))
+	} else if m.State().Availability(pipeline.SubsystemDocker).Missing() {
+		widgets = append(widgets, lipgloss.NewStyle().Foreground(theme.Overlay0).Render(theme.Icon("🐳", "docker")+" off"))
 	}
 
 	gpuStats := m.GPUStats()
@@ -82,13 +95,29 @@ func (m Model) renderHeaderBar(width int) string {
 		widgets = append(widgets, gpuStyle.Render(fmt.Sprintf("▮ %d%%", gpuStats.UtilizationPct)))
 	}
 
+	if jobs := m.jobsWidget(); jobs != "" {
+		widgets = append(widgets, jobs)
+	}
+
 	aiStyle := lipgloss.NewStyle().
 		Background(theme.Surface0).
 		Foreground(theme.Green).
 		Padding(0, 1)
-	widgets = append(widgets, aiStyle.Render(m.AIMode()+" ●"))
+	aiDot := " ●"
+	if m.offline {
+		aiStyle = aiStyle.Foreground(theme.Peach)
+	}
+	if m.State().Availability(pipeline.SubsystemOllama).Missing() {
+		aiStyle = aiStyle.Foreground(theme.Red)
+		aiDot = " ○"
+	}
+	widgets = append(widgets, aiStyle.Render(m.AIMode()+aiDot))
 
-	widgetStr := strings.Join(widgets, " │ ")
+	separator := " │ "
+	if compact {
+		separator = " "
+	}
+	widgetStr := strings.Join(widgets, separator)
 
 	leftSide := title + cwd
 	leftWidth := lipgloss.Width(leftSide)
@@ -152,41 +181,14 @@ func (m Model) renderBlocksArea(width, height int) string {
 		return panelStyle.Render(strings.Join(lines[:maxLines], "\n"))
 	}
 
-	blockWidth := width - 6
-	var allLines []string
-
-	for i, block := range blocks {
-		blockStr := m.renderBlock(block, i, blockWidth)
-		for _, line := range strings.Split(blockStr, "\n") {
-			allLines = append(allLines, line)
-		}
-	}
-
-	if m.isExecuting {
-		execStyle := lipgloss.NewStyle().
-			Foreground(theme.Yellow).
-			Italic(true)
-		allLines = append(allLines, execStyle.Render("  ◌ Executing..."))
-	}
-
-	visibleMax := maxLines - 1
-	start := 0
-	if len(allLines) > visibleMax {
-		start = len(allLines) - visibleMax
-		pct := (start * 100) / len(allLines)
+	vp, _ := m.blocksViewport(width, height)
+	if total := vp.TotalLineCount(); total > vp.Height {
+		pct := (vp.YOffset * 100) / total
 		header += lipgloss.NewStyle().Foreground(theme.Overlay0).Render(fmt.Sprintf(" [%d%%]", pct))
 	}
 
-	displayLines := make([]string, 0, maxLines)
-	displayLines = append(displayLines, header)
-	displayLines = append(displayLines, allLines[start:]...)
-
-	for len(displayLines) < maxLines {
-		displayLines = append(displayLines, "")
-	}
-	if len(displayLines) > maxLines {
-		displayLines = displayLines[:maxLines]
-	}
+	displayLines := append([]string{header}, m.renderPins(width)...)
+	displayLines = append(displayLines, strings.Split(vp.View(), "\n")...)
 
 	panelStyle := lipgloss.NewStyle().
 		Border(lipgloss.RoundedBorder()).
@@ -210,6 +212,11 @@ func (m Model) renderBlock(block pipeline.Block, index int, width int) string {
 			Border(lipgloss.Border{Left: "│"}).
 			BorderForeground(theme.Blue).
 			PaddingLeft(1)
+	} else if block.Running {
+		borderStyle = lipgloss.NewStyle().
+			Border(lipgloss.Border{Left: "│"}).
+			BorderForeground(theme.Yellow).
+			PaddingLeft(1)
 	} else if block.ExitCode != 0 {
 		borderStyle = lipgloss.NewStyle().
 			Border(lipgloss.Border{Left: "│"}).
@@ -227,6 +234,9 @@ func (m Model) renderBlock(block pipeline.Block, index int, width int) string {
 	if block.Type == pipeline.BlockTypeAI {
 		queryStyle := lipgloss.NewStyle().Foreground(theme.Blue).Bold(true)
 		blockContent.WriteString(queryStyle.Render("? " + block.Command))
+		if block.AIModel != "" {
+			blockContent.WriteString(lipgloss.NewStyle().Foreground(theme.Overlay0).Render("  via " + block.AIModel))
+		}
 	} else {
 		promptStyle := lipgloss.NewStyle().Foreground(theme.Green).Bold(true)
 		cmdStyle := lipgloss.NewStyle().Foreground(theme.Text).Bold(true)
@@ -238,15 +248,29 @@ func (m Model) renderBlock(block pipeline.Block, index int, width int) string {
 		if block.ExitCode != 0 {
 			exitStyle := lipgloss.NewStyle().Foreground(theme.Red).Bold(true)
 			meta += " " + exitStyle.Render(fmt.Sprintf("✗ %d", block.ExitCode))
+			if block.ExitCode == 130 {
+				meta += metaStyle.Render(" interrupted")
+			}
 		}
 
-		if block.Duration > 0 {
+		if block.Running {
+			runningStyle := lipgloss.NewStyle().Foreground(theme.Yellow).Italic(true)
+			elapsed := time.Since(block.Timestamp).Truncate(time.Second)
+			meta += " " + runningStyle.Render(fmt.Sprintf("◌ running %s", elapsed))
+		} else if block.Duration > 0 {
 			meta += metaStyle.Render(fmt.Sprintf(" (%dms)", block.Duration.Milliseconds()))
 		}
 
 		blockContent.WriteString(meta)
 	}
 
+	if block.Pinned {
+		blockContent.WriteString(" " + theme.Icon("📌", "[pin]"))
+	}
+	if slices.Contains(m.marked, block.ID) {
+		blockContent.WriteString(" " + lipgloss.NewStyle().Foreground(theme.Mauve).Render(theme.Icon("◆ marked", "[marked]")))
+	}
+
 	if block.Folded {
 		foldStyle := lipgloss.NewStyle().Foreground(theme.Overlay0)
 		blockContent.WriteString(foldStyle.Render(" ▸"))
@@ -262,14 +286,28 @@ func (m Model) renderBlock(block pipeline.Block, index int, width int) string {
 
 		lines := strings.Split(block.Output, "\n")
 		maxLines := 50
-		if len(lines) > maxLines {
+		if block.Type == pipeline.BlockTypeAI {
+			for i, line := range lines {
+				lines[i] = outputStyle.Render(line)
+			}
+		} else {
+			// Highlighting looks at the whole output, so a long JSON
+			// document is still recognised when only its head is shown.
+			lines = highlightOutput(block.Output, outputStyle)
+		}
+		if block.Running && len(lines) > maxLines {
+			// A running command is followed at its newest output.
+			moreStyle := lipgloss.NewStyle().Foreground(theme.Yellow).Italic(true)
+			blockContent.WriteString(moreStyle.Render(fmt.Sprintf("... %d earlier lines", len(lines)-maxLines)) + "\n")
+			blockContent.WriteString(strings.Join(lines[len(lines)-maxLines:], "\n") + "\n")
+		} else if len(lines) > maxLines {
 			for _, line := range lines[:maxLines] {
-				blockContent.WriteString(outputStyle.Render(line) + "\n")
+				blockContent.WriteString(line + "\n")
 			}
 			moreStyle := lipgloss.NewStyle().Foreground(theme.Yellow).Italic(true)
 			blockContent.WriteString(moreStyle.Render(fmt.Sprintf("... +%d lines (press z to fold)", len(lines)-maxLines)) + "\n")
 		} else {
-			blockContent.WriteString(outputStyle.Render(block.Output) + "\n")
+			blockContent.WriteString(strings.Join(lines, "\n") + "\n")
 		}
 	}
 
@@ -280,29 +318,51 @@ func (m Model) renderBlock(block pipeline.Block, index int, width int) string {
 			Padding(0, 1)
 
 		blockContent.WriteString("\n")
-		blockContent.WriteString(lipgloss.NewStyle().Foreground(theme.Yellow).Render("💡 AI: "))
+		blockContent.WriteString(lipgloss.NewStyle().Foreground(theme.Yellow).Render(theme.Icon("💡", "*") + " AI: "))
 		blockContent.WriteString(fixStyle.Render(block.AISuggestion))
 
 		actionsStyle := lipgloss.NewStyle().Foreground(theme.Mauve).Bold(true)
 		blockContent.WriteString("\n   " + actionsStyle.Render("[r]un") + " " + actionsStyle.Render("[c]opy") + " " + actionsStyle.Render("[d]ismiss"))
 	}
 
-	suggestions := m.State().GetSuggestionsForBlock(block.ID)
-	if len(suggestions) > 0 && block.AISuggestion == "" {
-		sug := suggestions[0]
-		sugStyle := lipgloss.NewStyle().
-			Background(theme.Surface0).
-			Foreground(theme.Yellow).
-			Padding(0, 1)
-
-		blockContent.WriteString("\n")
-		blockContent.WriteString(lipgloss.NewStyle().Foreground(theme.Yellow).Render("💡 "))
-		blockContent.WriteString(sugStyle.Render(sug.Explanation))
+	if block.AISuggestion == "" {
+		for _, a := range m.State().GetAnnotationsForBlock(block.ID) {
+			blockContent.WriteString("\n" + renderAnnotation(a))
+		}
 	}
 
 	return borderStyle.Width(width).Render(blockContent.String())
 }
 
+func renderAnnotation(a pipeline.BlockAnnotation) string {
+	color, icon := theme.Blue, "ℹ"
+	switch a.Severity {
+	case pipeline.SeveritySuccess:
+		color, icon = theme.Green, "✓"
+	case pipeline.SeverityWarning:
+		color, icon = theme.Yellow, theme.Icon("💡", "!")
+	case pipeline.SeverityError:
+		color, icon = theme.Red, "✗"
+	}
+
+	textStyle := lipgloss.NewStyle().
+		Background(theme.Surface0).
+		Foreground(color).
+		Padding(0, 1)
+
+	line := lipgloss.NewStyle().Foreground(color).Render(icon+" ") + textStyle.Render(a.Text)
+	if a.Command != "" {
+		cmdStyle := lipgloss.NewStyle().Foreground(theme.Text).Bold(true)
+		actionsStyle := lipgloss.NewStyle().Foreground(theme.Mauve).Bold(true)
+		action := actionsStyle.Render("[r]un") + " " + actionsStyle.Render("[d]ismiss")
+		if a.Key != "" {
+			action = actionsStyle.Render("[" + a.Key + "] " + a.Action)
+		}
+		line += "\n   " + cmdStyle.Render("❯ "+a.Command) + " " + action
+	}
+	return line
+}
+
 func (m Model) renderInputArea(width int) string {
 	inputStyle := lipgloss.NewStyle().
 		Border(lipgloss.RoundedBorder()).
@@ -322,13 +382,27 @@ func (m Model) renderInputArea(width int) string {
 		Italic(true)
 
 	hint := ""
-	if !m.insertMode {
+	if m.notice != "" {
+		color := theme.Green
+		if m.noticeFailed {
+			color = theme.Red
+		}
+		hint = lipgloss.NewStyle().Foreground(color).Render("  " + m.notice)
+	} else if m.find != nil && !m.insertMode {
+		hint = lipgloss.NewStyle().Foreground(theme.Peach).Render("  " + m.findHint())
+	} else if !m.insertMode {
 		hint = hintStyle.Render("  [i]nsert [?]AI [j/k]nav [z]fold")
 	} else {
 		hint = hintStyle.Render("  [Enter]run [Esc]normal [?]ask AI")
 	}
 
 	inputRow := prompt + m.input.View()
+	if m.FindOpen() {
+		// Unlike the command input, the query leaves room for its hint.
+		in := m.find.input
+		in.Width = max(width-lipgloss.Width(hint)-8, 10)
+		inputRow = in.View()
+	}
 
 	inputWidth := lipgloss.Width(inputRow)
 	hintWidth := lipgloss.Width(hint)
@@ -348,5 +422,8 @@ func (m Model) renderStarshipBar(width int) string {
 		Width(width).
 		Padding(0, 1)
 
-	return statusStyle.Render(m.StarshipLine())
+	// Starship's own colors are kept, on the bar's background.
+	base := lipgloss.NewStyle().Background(theme.Surface0).Foreground(theme.Text)
+	line := ansi.Truncate(m.StarshipLine(), width-2, "…")
+	return statusStyle.Render(components.RestyleANSI(line, base))
 }
@@ -0,0 +1,158 @@
+package chat
+
+import (
+	"slices"
+
+	"dev-cli/internal/llm"
+	"dev-cli/internal/tui/theme"
+
+	"github.com/charmbracelet/bubbles/textarea"
+	"github.com/charmbracelet/bubbles/viewport"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// inputHeight is how many lines of a message show while typing it.
+const inputHeight = 3
+
+// SendMsg asks for the reply of Model to the conversation in Messages,
+// which ends with the new question.
+type SendMsg struct {
+	Model    llm.ChatModel
+	Messages []llm.ChatMessage
+}
+
+// ModelsMsg asks for the models to chat with.
+type ModelsMsg struct{}
+
+// Model is the Chat tab: a conversation with a model of choice, kept apart
+// from the Agent's command blocks.
+type Model struct {
+	width  int
+	height int
+
+	input      textarea.Model
+	insertMode bool
+	viewport   viewport.Model
+
+	messages []llm.ChatMessage
+	// reply is the answer streaming in while streaming is set.
+	reply     string
+	streaming bool
+	err       string
+
+	models []llm.ChatModel
+	model  int
+	// picker is the cursor of the open model switcher, -1 when closed.
+	picker    int
+	modelsErr string
+}
+
+func New() Model {
+	ta := textarea.New()
+	ta.Placeholder = "Ask anything..."
+	ta.ShowLineNumbers = false
+	ta.Prompt = ""
+	ta.CharLimit = 0
+	ta.FocusedStyle.CursorLine = lipgloss.NewStyle()
+	ta.FocusedStyle.Placeholder = lipgloss.NewStyle().Foreground(theme.Overlay0)
+	ta.BlurredStyle.Placeholder = lipgloss.NewStyle().Foreground(theme.Overlay0)
+	// Enter sends the message, so new lines take Alt+Enter.
+	ta.KeyMap.InsertNewline.SetKeys("alt+enter", "ctrl+j")
+	ta.SetHeight(inputHeight)
+
+	return Model{
+		input:    ta,
+		viewport: viewport.New(0, 0),
+		picker:   -1,
+	}
+}
+
+func (m Model) SetSize(w, h int) Model {
+	m.width = w
+	m.height = h
+	m.input.SetWidth(max(w-6, 10))
+	m.viewport.Width = max(w-4, 10)
+	m.viewport.Height = max(m.conversationHeight()-1, 1)
+	m.refresh()
+	return m
+}
+
+// conversationHeight is what the conversation panel gets inside its
+// border, the input box taking the rest.
+func (m Model) conversationHeight() int {
+	return max(m.height-2-(inputHeight+2), 5)
+}
+
+// SetModels offers models to chat with, keeping the chosen one when it is
+// still there; err explains a partial or empty list.
+func (m Model) SetModels(models []llm.ChatModel, err error) Model {
+	var chosen llm.ChatModel
+	if m.model < len(m.models) {
+		chosen = m.models[m.model]
+	}
+	m.models = models
+	m.model = max(slices.Index(models, chosen), 0)
+	m.modelsErr = ""
+	if err != nil && len(models) == 0 {
+		m.modelsErr = err.Error()
+	}
+	return m
+}
+
+// AppendReply adds a chunk of the reply streaming in.
+func (m Model) AppendReply(delta string) Model {
+	if m.streaming {
+		m.reply += delta
+		m.refresh()
+	}
+	return m
+}
+
+// FinishReply ends the reply. A failed question goes back into the input
+// to be sent again.
+func (m Model) FinishReply(reply string, err error) Model {
+	m.streaming = false
+	m.reply = ""
+	if err != nil {
+		m.err = err.Error()
+		if n := len(m.messages); n > 0 && m.messages[n-1].Role == "user" {
+			m.input.SetValue(m.messages[n-1].Content)
+			m.messages = m.messages[:n-1]
+		}
+	} else {
+		m.messages = append(m.messages, llm.ChatMessage{Role: "assistant", Content: reply})
+	}
+	m.refresh()
+	return m
+}
+
+// Streaming reports whether a reply is on its way.
+func (m Model) Streaming() bool { return m.streaming }
+
+// InsertMode reports whether keys go to the message being typed.
+func (m Model) InsertMode() bool { return m.insertMode }
+
+// PickerOpen reports whether the model switcher is open.
+func (m Model) PickerOpen() bool { return m.picker >= 0 }
+
+// Messages is the conversation so far.
+func (m Model) Messages() []llm.ChatMessage { return m.messages }
+
+// SelectedModel is the model questions go to; ok is false until models
+// are known.
+func (m Model) SelectedModel() (model llm.ChatModel, ok bool) {
+	if m.model < len(m.models) {
+		return m.models[m.model], true
+	}
+	return llm.ChatModel{}, false
+}
+
+// refresh re-renders the conversation, following it down unless it was
+// scrolled up.
+func (m *Model) refresh() {
+	atBottom := m.viewport.AtBottom()
+	m.viewport.SetContent(m.renderConversation(m.viewport.Width))
+	if atBottom {
+		m.viewport.GotoBottom()
+	}
+}
@@ -0,0 +1,191 @@
+package chat
+
+import (
+	"slices"
+	"strings"
+
+	"dev-cli/internal/llm"
+
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+type KeyMap struct {
+	Insert   key.Binding
+	Normal   key.Binding
+	Send     key.Binding
+	Up       key.Binding
+	Down     key.Binding
+	PageUp   key.Binding
+	PageDown key.Binding
+	Top      key.Binding
+	Bottom   key.Binding
+	Model    key.Binding
+	New      key.Binding
+	Pick     key.Binding
+	Close    key.Binding
+}
+
+func DefaultKeyMap() KeyMap {
+	return KeyMap{
+		Insert: key.NewBinding(
+			key.WithKeys("i", "enter"),
+			key.WithHelp("i", "type"),
+		),
+		Normal: key.NewBinding(
+			key.WithKeys("esc"),
+			key.WithHelp("Esc", "normal"),
+		),
+		Send: key.NewBinding(
+			key.WithKeys("enter"),
+			key.WithHelp("Enter", "send"),
+		),
+		Up: key.NewBinding(
+			key.WithKeys("up", "k"),
+			key.WithHelp("j/k", "scroll"),
+		),
+		Down: key.NewBinding(
+			key.WithKeys("down", "j"),
+			key.WithHelp("", ""),
+		),
+		PageUp: key.NewBinding(
+			key.WithKeys("pgup", "ctrl+u"),
+			key.WithHelp("PgUp", "page up"),
+		),
+		PageDown: key.NewBinding(
+			key.WithKeys("pgdown", "ctrl+d"),
+			key.WithHelp("PgDn", "page down"),
+		),
+		Top: key.NewBinding(
+			key.WithKeys("g"),
+			key.WithHelp("g/G", "top/bottom"),
+		),
+		Bottom: key.NewBinding(
+			key.WithKeys("G"),
+			key.WithHelp("", ""),
+		),
+		Model: key.NewBinding(
+			key.WithKeys("m"),
+			key.WithHelp("m", "model"),
+		),
+		New: key.NewBinding(
+			key.WithKeys("n"),
+			key.WithHelp("n", "new chat"),
+		),
+		Pick: key.NewBinding(
+			key.WithKeys("enter"),
+			key.WithHelp("Enter", "use model"),
+		),
+		Close: key.NewBinding(
+			key.WithKeys("esc", "m"),
+			key.WithHelp("Esc", "close"),
+		),
+	}
+}
+
+func (m Model) Update(msg tea.Msg, keys KeyMap) (Model, tea.Cmd) {
+	km, ok := msg.(tea.KeyMsg)
+	if !ok {
+		return m, nil
+	}
+
+	if m.PickerOpen() {
+		return m.updatePicker(km, keys), nil
+	}
+	if m.insertMode {
+		return m.updateInput(km, keys)
+	}
+
+	switch {
+	case key.Matches(km, keys.Insert):
+		m.insertMode = true
+		return m, m.input.Focus()
+	case key.Matches(km, keys.Up):
+		m.viewport.ScrollUp(1)
+	case key.Matches(km, keys.Down):
+		m.viewport.ScrollDown(1)
+	case key.Matches(km, keys.PageUp):
+		m.viewport.HalfPageUp()
+	case key.Matches(km, keys.PageDown):
+		m.viewport.HalfPageDown()
+	case key.Matches(km, keys.Top):
+		m.viewport.GotoTop()
+	case key.Matches(km, keys.Bottom):
+		m.viewport.GotoBottom()
+	case key.Matches(km, keys.Model):
+		m.picker = m.model
+		if len(m.models) == 0 {
+			return m, func() tea.Msg { return ModelsMsg{} }
+		}
+	case key.Matches(km, keys.New):
+		if !m.streaming {
+			m.messages = nil
+			m.err = ""
+			m.refresh()
+		}
+	}
+	return m, nil
+}
+
+// updateInput handles keys while a message is typed: Enter sends it, Esc
+// goes back to scrolling the conversation.
+func (m Model) updateInput(km tea.KeyMsg, keys KeyMap) (Model, tea.Cmd) {
+	switch {
+	case key.Matches(km, keys.Normal):
+		m.insertMode = false
+		m.input.Blur()
+		return m, nil
+	case key.Matches(km, keys.Send):
+		return m.send()
+	}
+
+	var cmd tea.Cmd
+	m.input, cmd = m.input.Update(km)
+	return m, cmd
+}
+
+// send asks the chosen model about the typed message, with the whole
+// conversation for context.
+func (m Model) send() (Model, tea.Cmd) {
+	text := strings.TrimSpace(m.input.Value())
+	if text == "" || m.streaming {
+		return m, nil
+	}
+	model, ok := m.SelectedModel()
+	if !ok {
+		m.err = "No model to chat with yet"
+		if m.modelsErr != "" {
+			m.err += ": " + m.modelsErr
+		}
+		m.refresh()
+		return m, func() tea.Msg { return ModelsMsg{} }
+	}
+
+	m.messages = append(m.messages, llm.ChatMessage{Role: "user", Content: text})
+	m.input.Reset()
+	m.streaming = true
+	m.reply = ""
+	m.err = ""
+	m.viewport.GotoBottom()
+	m.refresh()
+
+	messages := slices.Clone(m.messages)
+	return m, func() tea.Msg { return SendMsg{Model: model, Messages: messages} }
+}
+
+func (m Model) updatePicker(km tea.KeyMsg, keys KeyMap) Model {
+	switch {
+	case key.Matches(km, keys.Close):
+		m.picker = -1
+	case key.Matches(km, keys.Pick):
+		if m.picker < len(m.models) {
+			m.model = m.picker
+		}
+		m.picker = -1
+	case key.Matches(km, keys.Up):
+		m.picker = max(m.picker-1, 0)
+	case key.Matches(km, keys.Down):
+		m.picker = min(m.picker+1, max(len(m.models)-1, 0))
+	}
+	return m
+}
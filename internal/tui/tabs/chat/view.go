@@ -0,0 +1,165 @@
+package chat
+
+import (
+	"strings"
+
+	"dev-cli/internal/llm"
+	"dev-cli/internal/tui/theme"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
+)
+
+func (m Model) View() string {
+	width := max(m.width-2, 20)
+	height := m.conversationHeight()
+
+	panelStyle := lipgloss.NewStyle().
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(theme.Mauve).
+		Width(width).
+		Height(height)
+	if m.insertMode {
+		panelStyle = panelStyle.BorderForeground(theme.Surface2)
+	}
+
+	body := m.viewport.View()
+	if m.PickerOpen() {
+		body = m.renderPicker(width-2, height-1)
+	}
+	conversation := panelStyle.Render(m.renderHeader(width-2) + "\n" + body)
+
+	return lipgloss.JoinVertical(lipgloss.Left, conversation, m.renderInput(width))
+}
+
+// renderHeader names the chat and the model it talks to.
+func (m Model) renderHeader(width int) string {
+	headerStyle := lipgloss.NewStyle().Foreground(theme.Lavender).Bold(true)
+	dimStyle := lipgloss.NewStyle().Foreground(theme.Overlay0)
+
+	header := headerStyle.Render(" ◇ Chat")
+	if model, ok := m.SelectedModel(); ok {
+		header += dimStyle.Render("  " + model.String())
+	}
+	if m.streaming {
+		header += lipgloss.NewStyle().Foreground(theme.Yellow).Render("  ◌ answering")
+	}
+	return ansi.Truncate(header, width, "…")
+}
+
+func (m Model) renderInput(width int) string {
+	style := lipgloss.NewStyle().
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(theme.Surface2).
+		Width(width).
+		Padding(0, 1)
+	if m.insertMode {
+		style = style.BorderForeground(theme.Green)
+	}
+	return style.Render(m.input.View())
+}
+
+// renderConversation renders the questions and answers, the reply
+// streaming in, and the last error.
+func (m Model) renderConversation(width int) string {
+	dimStyle := lipgloss.NewStyle().Foreground(theme.Overlay0)
+
+	if len(m.messages) == 0 && m.err == "" {
+		return dimStyle.Width(width).Padding(1, 1).Render(
+			"Ask anything: i to type, Enter to send, Alt+Enter for a new line. " +
+				"m switches the model and n starts over. " +
+				"The conversation stays here, out of the Agent's command blocks.")
+	}
+
+	var parts []string
+	for _, msg := range m.messages {
+		parts = append(parts, renderMessage(msg, width))
+	}
+	if m.streaming {
+		reply := m.reply + "▍"
+		if m.reply == "" {
+			reply = dimStyle.Render("thinking...")
+		}
+		parts = append(parts, renderMessage(llm.ChatMessage{Role: "assistant", Content: reply}, width))
+	}
+	if m.err != "" {
+		parts = append(parts, lipgloss.NewStyle().Foreground(theme.Red).Width(width).PaddingLeft(1).Render("✗ "+m.err))
+	}
+	return strings.Join(parts, "\n\n")
+}
+
+// renderMessage renders one turn behind a bar in the speaker's colour,
+// with code blocks set apart.
+func renderMessage(msg llm.ChatMessage, width int) string {
+	who, color := "you", theme.Green
+	if msg.Role == "assistant" {
+		who, color = "assistant", theme.Blue
+	}
+	textStyle := lipgloss.NewStyle().Foreground(theme.Text)
+	codeStyle := lipgloss.NewStyle().Foreground(theme.Peach)
+	fenceStyle := lipgloss.NewStyle().Foreground(theme.Overlay0)
+
+	lines := []string{lipgloss.NewStyle().Foreground(color).Bold(true).Render(who)}
+	inCode := false
+	for _, line := range strings.Split(strings.TrimRight(msg.Content, "\n"), "\n") {
+		if strings.HasPrefix(strings.TrimSpace(line), "```") {
+			inCode = !inCode
+			lines = append(lines, fenceStyle.Render(line))
+			continue
+		}
+		if inCode {
+			// Code keeps its lines; wrapping would break copied commands.
+			lines = append(lines, codeStyle.Render(ansi.Truncate(line, width-3, "…")))
+			continue
+		}
+		lines = append(lines, textStyle.Width(width-3).Render(line))
+	}
+
+	return lipgloss.NewStyle().
+		Border(lipgloss.Border{Left: "│"}, false, false, false, true).
+		BorderForeground(color).
+		MarginLeft(1).
+		PaddingLeft(1).
+		Render(strings.Join(lines, "\n"))
+}
+
+// renderPicker lists the models to switch to, scrolled to the cursor.
+func (m Model) renderPicker(width, height int) string {
+	dimStyle := lipgloss.NewStyle().Foreground(theme.Overlay0)
+	selectedStyle := lipgloss.NewStyle().Background(theme.Surface1).Foreground(theme.Lavender).Bold(true)
+	textStyle := lipgloss.NewStyle().Foreground(theme.Text)
+
+	lines := []string{lipgloss.NewStyle().Foreground(theme.Lavender).Render(" Switch model"), ""}
+	if len(m.models) == 0 {
+		msg := "Looking for models..."
+		if m.modelsErr != "" {
+			msg = "No models: " + m.modelsErr
+		}
+		return strings.Join(append(lines, dimStyle.Render("  "+ansi.Truncate(msg, width-2, "…"))), "\n")
+	}
+
+	provider := ""
+	cursorLine := 0
+	for i, model := range m.models {
+		if model.Provider != provider {
+			provider = model.Provider
+			lines = append(lines, dimStyle.Render(" "+provider))
+		}
+		marker := "  "
+		if i == m.model {
+			marker = "● "
+		}
+		if i == m.picker {
+			cursorLine = len(lines)
+			lines = append(lines, selectedStyle.Width(width).Render("▌"+marker+model.Name))
+			continue
+		}
+		lines = append(lines, " "+textStyle.Render(marker+model.Name))
+	}
+	lines = append(lines, "", dimStyle.Render(" Enter use · Esc close"))
+	if len(lines) > height {
+		start := min(max(cursorLine-height/2, 0), len(lines)-height)
+		lines = lines[start : start+height]
+	}
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,326 @@
+package cluster
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"dev-cli/internal/infra/kube"
+	"dev-cli/internal/tui/components"
+	"dev-cli/internal/tui/theme"
+
+	"github.com/charmbracelet/bubbles/list"
+	"github.com/charmbracelet/bubbles/viewport"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+type FocusPanel int
+
+const (
+	FocusPods FocusPanel = iota
+	FocusDeployments
+	FocusLogs
+)
+
+// Pod item for bubbles/list
+type podItem struct {
+	info kube.PodInfo
+}
+
+func (i podItem) Title() string       { return i.info.Name }
+func (i podItem) Description() string { return i.info.Status }
+func (i podItem) FilterValue() string { return i.info.Name }
+
+// Deployment item for bubbles/list
+type deploymentItem struct {
+	info kube.DeploymentInfo
+}
+
+func (i deploymentItem) Title() string       { return i.info.Name }
+func (i deploymentItem) FilterValue() string { return i.info.Name }
+
+// Description is the ready/desired replica count.
+func (i deploymentItem) Description() string {
+	return fmt.Sprintf("%d/%d", i.info.Ready, i.info.Replicas)
+}
+
+// Custom delegate for pod list
+type podDelegate struct{}
+
+func (d podDelegate) Height() int                             { return 1 }
+func (d podDelegate) Spacing() int                            { return 0 }
+func (d podDelegate) Update(_ tea.Msg, _ *list.Model) tea.Cmd { return nil }
+
+func (d podDelegate) Render(w io.Writer, m list.Model, index int, listItem list.Item) {
+	i, ok := listItem.(podItem)
+	if !ok {
+		return
+	}
+
+	status := "●"
+	statusColor := theme.Green
+	switch {
+	case i.info.Status == "Pending" || i.info.Status == "ContainerCreating":
+		status = "◐"
+		statusColor = theme.Yellow
+	case !i.info.Healthy():
+		status = "○"
+		statusColor = theme.Red
+	}
+
+	restarts := ""
+	if i.info.Restarts > 0 {
+		restarts = fmt.Sprintf("↻%d", i.info.Restarts)
+	}
+
+	name := i.info.Name
+	maxWidth := m.Width() - 6 - len([]rune(restarts))
+	if maxWidth < 5 {
+		maxWidth = 5
+	}
+	if len(name) > maxWidth {
+		name = name[:maxWidth-1] + "…"
+	}
+
+	statusStyle := lipgloss.NewStyle().Foreground(statusColor)
+	textStyle := lipgloss.NewStyle().Foreground(theme.Text)
+	restartStyle := lipgloss.NewStyle().Foreground(theme.Peach)
+
+	line := fmt.Sprintf(" %s %s", statusStyle.Render(status), textStyle.Render(name))
+	if restarts != "" {
+		line += " " + restartStyle.Render(restarts)
+	}
+
+	if index == m.Index() {
+		line = lipgloss.NewStyle().
+			Background(theme.Surface1).
+			Foreground(theme.Lavender).
+			Bold(true).
+			Width(m.Width()).
+			Render(line)
+	}
+
+	fmt.Fprint(w, line)
+}
+
+// Custom delegate for deployment list
+type deploymentDelegate struct{}
+
+func (d deploymentDelegate) Height() int                             { return 1 }
+func (d deploymentDelegate) Spacing() int                            { return 0 }
+func (d deploymentDelegate) Update(_ tea.Msg, _ *list.Model) tea.Cmd { return nil }
+
+func (d deploymentDelegate) Render(w io.Writer, m list.Model, index int, listItem list.Item) {
+	i, ok := listItem.(deploymentItem)
+	if !ok {
+		return
+	}
+
+	status := "●"
+	statusColor := theme.Green
+	switch {
+	case i.info.Replicas == 0:
+		status = "○"
+		statusColor = theme.Overlay0
+	case i.info.Ready == 0:
+		status = "○"
+		statusColor = theme.Red
+	case i.info.Ready < i.info.Replicas:
+		status = "◐"
+		statusColor = theme.Yellow
+	}
+
+	count := i.Description()
+	name := i.info.Name
+	maxWidth := m.Width() - 6 - len(count)
+	if maxWidth < 5 {
+		maxWidth = 5
+	}
+	if len(name) > maxWidth {
+		name = name[:maxWidth-1] + "…"
+	}
+
+	statusStyle := lipgloss.NewStyle().Foreground(statusColor)
+	textStyle := lipgloss.NewStyle().Foreground(theme.Text)
+	countStyle := lipgloss.NewStyle().Foreground(theme.Overlay0)
+
+	line := fmt.Sprintf(" %s %s %s", statusStyle.Render(status), textStyle.Render(name), countStyle.Render(count))
+
+	if index == m.Index() {
+		line = lipgloss.NewStyle().
+			Background(theme.Surface1).
+			Foreground(theme.Lavender).
+			Bold(true).
+			Width(m.Width()).
+			Render(line)
+	}
+
+	fmt.Fprint(w, line)
+}
+
+// Model is the Kubernetes tab: pods and deployments of the current
+// context's namespace on the left, the selected pod's logs on the right.
+type Model struct {
+	width  int
+	height int
+	focus  FocusPanel
+
+	podsList        list.Model
+	deploymentsList list.Model
+	viewport        viewport.Model
+
+	// Data
+	context     string
+	namespace   string
+	pods        []kube.PodInfo
+	deployments []kube.DeploymentInfo
+	err         string
+	logLines    []string
+	logErr      string
+}
+
+func New() Model {
+	pList := list.New([]list.Item{}, podDelegate{}, 0, 0)
+	pList.SetShowHelp(false)
+	pList.SetShowTitle(false)
+	pList.SetShowStatusBar(false)
+	pList.SetFilteringEnabled(false)
+	pList.DisableQuitKeybindings()
+
+	dList := list.New([]list.Item{}, deploymentDelegate{}, 0, 0)
+	dList.SetShowHelp(false)
+	dList.SetShowTitle(false)
+	dList.SetShowStatusBar(false)
+	dList.SetFilteringEnabled(false)
+	dList.DisableQuitKeybindings()
+
+	return Model{
+		podsList:        pList,
+		deploymentsList: dList,
+		viewport:        viewport.New(0, 0),
+		focus:           FocusPods,
+	}
+}
+
+// layout returns the sidebar width and the pods/deployments panel heights,
+// matching the Containers tab.
+func (m Model) layout() (sidebarWidth, podsHeight, deploymentsHeight int) {
+	sidebarWidth = 28
+	if m.width < 100 {
+		sidebarWidth = 24
+	}
+	panelHeight := m.height - 4
+	if components.Compact(m.width) {
+		// Stacked above the logs, each list takes a quarter.
+		return m.width - 4, max(panelHeight/4, 5), max(panelHeight/4, 5)
+	}
+	podsHeight = max(panelHeight/2, 5)
+	deploymentsHeight = max(panelHeight-podsHeight, 5)
+	return sidebarWidth, podsHeight, deploymentsHeight
+}
+
+func (m Model) SetSize(w, h int) Model {
+	m.width = w
+	m.height = h
+
+	sidebarWidth, podsHeight, deploymentsHeight := m.layout()
+	m.podsList.SetWidth(sidebarWidth - 4)
+	m.podsList.SetHeight(podsHeight - 2)
+	m.deploymentsList.SetWidth(sidebarWidth - 4)
+	m.deploymentsList.SetHeight(deploymentsHeight - 2)
+
+	logWidth := max(w-sidebarWidth-4, 40)
+	logHeight := h - 4
+	if components.Compact(w) {
+		logWidth = w - 2
+		logHeight -= podsHeight + deploymentsHeight
+	}
+	m.viewport.Width = logWidth - 4
+	m.viewport.Height = max(logHeight-3, 5)
+
+	return m.refreshLogs()
+}
+
+// SetHealth replaces the pods and deployments with a fresh listing, keeping
+// the selected pod when it still exists.
+func (m Model) SetHealth(h kube.Health) Model {
+	m.context = h.Context
+	m.namespace = h.Namespace
+	m.err = ""
+	if h.Error != nil {
+		m.err = h.Error.Error()
+		return m
+	}
+
+	selected := ""
+	if pod := m.SelectedPod(); pod != nil {
+		selected = pod.Name
+	}
+
+	m.pods = h.Pods
+	items := make([]list.Item, len(h.Pods))
+	cursor := 0
+	for i, p := range h.Pods {
+		items[i] = podItem{info: p}
+		if p.Name == selected {
+			cursor = i
+		}
+	}
+	m.podsList.SetItems(items)
+	m.podsList.Select(cursor)
+
+	m.deployments = h.Deployments
+	dItems := make([]list.Item, len(h.Deployments))
+	for i, d := range h.Deployments {
+		dItems[i] = deploymentItem{info: d}
+	}
+	m.deploymentsList.SetItems(dItems)
+
+	return m
+}
+
+// SetLogLines shows the selected pod's logs; a nil error clears the last one.
+func (m Model) SetLogLines(lines []string, err error) Model {
+	m.logLines = lines
+	m.logErr = ""
+	if err != nil {
+		m.logErr = err.Error()
+	}
+	m = m.refreshLogs()
+	m.viewport.GotoBottom()
+	return m
+}
+
+// refreshLogs re-renders the log lines into the viewport at its width.
+func (m Model) refreshLogs() Model {
+	rendered := make([]string, len(m.logLines))
+	for i, line := range m.logLines {
+		rendered[i] = components.NewLogLine(truncateLine(line, m.viewport.Width)).Render()
+	}
+	m.viewport.SetContent(strings.Join(rendered, "\n"))
+	return m
+}
+
+func (m Model) SelectedPod() *kube.PodInfo {
+	if item, ok := m.podsList.SelectedItem().(podItem); ok {
+		return &item.info
+	}
+	return nil
+}
+
+func (m Model) SelectedDeployment() *kube.DeploymentInfo {
+	if item, ok := m.deploymentsList.SelectedItem().(deploymentItem); ok {
+		return &item.info
+	}
+	return nil
+}
+
+func (m Model) Focus() FocusPanel                  { return m.focus }
+func (m Model) SetFocus(f FocusPanel) Model        { m.focus = f; return m }
+func (m Model) Context() string                    { return m.context }
+func (m Model) Namespace() string                  { return m.namespace }
+func (m Model) Pods() []kube.PodInfo               { return m.pods }
+func (m Model) Deployments() []kube.DeploymentInfo { return m.deployments }
+func (m Model) PodsList() list.Model               { return m.podsList }
+func (m Model) LogLines() []string                 { return m.logLines }
@@ -0,0 +1,96 @@
+package cluster
+
+import (
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+type KeyMap struct {
+	Up     key.Binding
+	Down   key.Binding
+	Tab    key.Binding
+	Top    key.Binding
+	Bottom key.Binding
+}
+
+func DefaultKeyMap() KeyMap {
+	return KeyMap{
+		Up: key.NewBinding(
+			key.WithKeys("up", "k"),
+			key.WithHelp("j/k", "nav"),
+		),
+		Down: key.NewBinding(
+			key.WithKeys("down", "j"),
+			key.WithHelp("", ""),
+		),
+		Tab: key.NewBinding(
+			key.WithKeys("tab"),
+			key.WithHelp("Tab", "panel"),
+		),
+		Top: key.NewBinding(
+			key.WithKeys("g"),
+			key.WithHelp("g/G", "top/bottom"),
+		),
+		Bottom: key.NewBinding(
+			key.WithKeys("G"),
+			key.WithHelp("", ""),
+		),
+	}
+}
+
+func (m Model) Update(msg tea.Msg, keys KeyMap) (Model, tea.Cmd) {
+	var cmd tea.Cmd
+
+	km, ok := msg.(tea.KeyMsg)
+	if !ok {
+		return m, nil
+	}
+
+	switch {
+	case key.Matches(km, keys.Tab):
+		switch m.focus {
+		case FocusPods:
+			m.focus = FocusLogs
+		case FocusLogs:
+			m.focus = FocusDeployments
+		case FocusDeployments:
+			m.focus = FocusPods
+		}
+
+	case key.Matches(km, keys.Up), key.Matches(km, keys.Down):
+		switch m.focus {
+		case FocusPods:
+			m.podsList, cmd = m.podsList.Update(km)
+		case FocusDeployments:
+			m.deploymentsList, cmd = m.deploymentsList.Update(km)
+		case FocusLogs:
+			if key.Matches(km, keys.Up) {
+				m.viewport.ScrollUp(1)
+			} else {
+				m.viewport.ScrollDown(1)
+			}
+		}
+
+	case key.Matches(km, keys.Top):
+		switch m.focus {
+		case FocusPods:
+			m.podsList.Select(0)
+		case FocusDeployments:
+			m.deploymentsList.Select(0)
+		case FocusLogs:
+			m.viewport.GotoTop()
+		}
+
+	case key.Matches(km, keys.Bottom):
+		switch m.focus {
+		case FocusPods:
+			m.podsList.Select(max(len(m.pods)-1, 0))
+		case FocusDeployments:
+			m.deploymentsList.Select(max(len(m.deployments)-1, 0))
+		case FocusLogs:
+			m.viewport.GotoBottom()
+		}
+	}
+
+	return m, cmd
+}
@@ -0,0 +1,134 @@
+package cluster
+
+import (
+	"fmt"
+	"strings"
+
+	"dev-cli/internal/tui/components"
+	"dev-cli/internal/tui/theme"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+func (m Model) View() string {
+	sidebarWidth, podsHeight, deploymentsHeight := m.layout()
+
+	logWidth := m.width - sidebarWidth - 4
+	if logWidth < 40 {
+		logWidth = 40
+	}
+
+	leftColumn := lipgloss.JoinVertical(lipgloss.Left,
+		m.renderPodsPanel(sidebarWidth, podsHeight),
+		m.renderDeploymentsPanel(sidebarWidth, deploymentsHeight),
+	)
+	if components.Compact(m.width) {
+		// Narrow terminals stack the lists above the logs.
+		logsPanel := m.renderLogsPanel(m.width-2, m.height-4-podsHeight-deploymentsHeight)
+		return lipgloss.JoinVertical(lipgloss.Left, leftColumn, logsPanel)
+	}
+	logsPanel := m.renderLogsPanel(logWidth, m.height-4)
+
+	return lipgloss.JoinHorizontal(lipgloss.Top, leftColumn, logsPanel)
+}
+
+func panelStyle(focused bool, width, height int) lipgloss.Style {
+	borderColor := theme.Surface2
+	if focused {
+		borderColor = theme.Mauve
+	}
+	return lipgloss.NewStyle().
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(borderColor).
+		Width(width).
+		Height(height).
+		MaxHeight(height)
+}
+
+func headerStyle() lipgloss.Style { return lipgloss.NewStyle().Foreground(theme.Lavender).Bold(true) }
+func dimStyle() lipgloss.Style    { return lipgloss.NewStyle().Foreground(theme.Overlay0) }
+func errStyle() lipgloss.Style    { return lipgloss.NewStyle().Foreground(theme.Red) }
+
+func (m Model) renderPodsPanel(width, height int) string {
+	header := headerStyle().Render("☸ Pods")
+	if len(m.pods) > 0 {
+		header += dimStyle().Render(fmt.Sprintf(" [%d]", len(m.pods)))
+	}
+	if m.context != "" {
+		where := truncateLine(m.context+"/"+m.namespace, width-lipgloss.Width(header)-2)
+		if where != "" {
+			header += dimStyle().Render(" " + where)
+		}
+	}
+
+	var content strings.Builder
+	content.WriteString(header + "\n")
+
+	switch {
+	case m.err != "":
+		content.WriteString(errStyle().Width(width - 2).Render(m.err))
+	case len(m.pods) == 0:
+		content.WriteString(dimStyle().Render("No pods in namespace"))
+	default:
+		content.WriteString(m.podsList.View())
+	}
+
+	return panelStyle(m.focus == FocusPods, width, height).Render(content.String())
+}
+
+func (m Model) renderDeploymentsPanel(width, height int) string {
+	header := headerStyle().Render("⎈ Deployments")
+	if len(m.deployments) > 0 {
+		header += dimStyle().Render(fmt.Sprintf(" [%d]", len(m.deployments)))
+	}
+
+	var content strings.Builder
+	content.WriteString(header + "\n")
+
+	if len(m.deployments) == 0 {
+		content.WriteString(dimStyle().Render("No deployments"))
+	} else {
+		content.WriteString(m.deploymentsList.View())
+	}
+
+	return panelStyle(m.focus == FocusDeployments, width, height).Render(content.String())
+}
+
+func (m Model) renderLogsPanel(width, height int) string {
+	header := headerStyle().Render("≡ Logs")
+	if pod := m.SelectedPod(); pod != nil {
+		name := pod.Name
+		if len(name) > 30 {
+			name = name[:29] + "…"
+		}
+		header += dimStyle().Render(" (" + name + ")")
+	}
+
+	var content strings.Builder
+	content.WriteString(header + "\n")
+
+	switch {
+	case m.logErr != "":
+		content.WriteString(errStyle().Render(truncateLine(m.logErr, width-6)))
+	case len(m.logLines) == 0:
+		content.WriteString(dimStyle().Render("No logs available") + "\n")
+		content.WriteString(dimStyle().Render("Select a pod to view logs"))
+	default:
+		content.WriteString(m.viewport.View())
+	}
+
+	return panelStyle(m.focus == FocusLogs, width, height).MaxWidth(width).Render(content.String())
+}
+
+func truncateLine(line string, maxWidth int) string {
+	if maxWidth <= 0 {
+		return ""
+	}
+
+	runes := []rune(line)
+	if len(runes) <= maxWidth {
+		return line
+	}
+
+	return string(runes[:maxWidth-1]) + "…"
+}
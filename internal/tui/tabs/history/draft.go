@@ -0,0 +1,59 @@
+package history
+
+import (
+	"cmp"
+	"maps"
+	"slices"
+
+	"dev-cli/internal/storage"
+	"dev-cli/internal/workflow"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// DraftWorkflowMsg asks the app to save a workflow drafted from Commands,
+// in the order they ran.
+type DraftWorkflowMsg struct {
+	Commands []workflow.RecordedCommand
+}
+
+// MarkedCount is how many commands are marked for a drafted workflow.
+func (m Model) MarkedCount() int { return len(m.marked) }
+
+// toggleMark marks the selected command for a drafted workflow, or unmarks
+// it. Failed commands can't be marked.
+func (m *Model) toggleMark() {
+	item := m.SelectedItem()
+	if item == nil {
+		return
+	}
+	if _, ok := m.marked[item.ID]; ok {
+		delete(m.marked, item.ID)
+	} else if item.ExitCode == 0 {
+		m.marked[item.ID] = *item
+	}
+}
+
+// draftWorkflow asks for a workflow of the marked commands, oldest first,
+// or of the selected one when none are marked, and clears the marks.
+func (m Model) draftWorkflow() (Model, tea.Cmd) {
+	items := slices.Collect(maps.Values(m.marked))
+	if len(items) == 0 {
+		if item := m.SelectedItem(); item != nil && item.ExitCode == 0 {
+			items = append(items, *item)
+		}
+	}
+	if len(items) == 0 {
+		return m, nil
+	}
+	slices.SortFunc(items, func(a, b storage.HistoryItem) int {
+		return cmp.Or(a.Timestamp.Compare(b.Timestamp), cmp.Compare(a.ID, b.ID))
+	})
+	commands := make([]workflow.RecordedCommand, len(items))
+	for i, item := range items {
+		commands[i] = workflow.RecordedCommand{Command: item.Command, Dir: item.Directory}
+	}
+	clear(m.marked)
+	msg := DraftWorkflowMsg{Commands: commands}
+	return m, func() tea.Msg { return msg }
+}
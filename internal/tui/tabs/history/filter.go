@@ -0,0 +1,163 @@
+package history
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"dev-cli/internal/storage"
+	"dev-cli/internal/tui/theme"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// FilterMsg asks for the history matching Filter, which replaces the list.
+type FilterMsg struct {
+	Filter storage.HistoryFilter
+}
+
+// timeRanges are the windows t cycles through; zero is all time.
+var timeRanges = []time.Duration{0, time.Hour, 24 * time.Hour, 7 * 24 * time.Hour, 30 * 24 * time.Hour}
+
+// Filter is what the list currently shows.
+func (m Model) Filter() storage.HistoryFilter { return m.filter }
+
+// Filtered reports whether any filter narrows the list.
+func (m Model) Filtered() bool { return m.filter != storage.HistoryFilter{} }
+
+// setFilter applies f and asks for the matching history, and its stats
+// while they show.
+func (m Model) setFilter(f storage.HistoryFilter) (Model, tea.Cmd) {
+	m.filter = f
+	m = m.SetSize(m.width, m.height)
+	cmd := func() tea.Msg { return FilterMsg{Filter: f} }
+	if m.showStats {
+		return m, tea.Batch(cmd, m.requestStats())
+	}
+	return m, cmd
+}
+
+// cycleStatus steps the exit code filter: all, failed, succeeded.
+func (m Model) cycleStatus() (Model, tea.Cmd) {
+	f := m.filter
+	f.Status = (f.Status + 1) % 3
+	return m.setFilter(f)
+}
+
+// toggleDirectory keeps to the selected command's directory, or drops
+// that filter when it is set.
+func (m Model) toggleDirectory() (Model, tea.Cmd) {
+	f := m.filter
+	if f.Directory != "" {
+		f.Directory = ""
+	} else if item := m.SelectedItem(); item != nil && item.Directory != "" {
+		f.Directory = item.Directory
+	} else {
+		return m, nil
+	}
+	return m.setFilter(f)
+}
+
+// toggleProject keeps to the project the TUI was started in, this
+// directory and everything below its root, or drops that filter when it is
+// set.
+func (m Model) toggleProject() (Model, tea.Cmd) {
+	f := m.filter
+	if f.Project != "" {
+		f.Project = ""
+	} else if cwd, err := os.Getwd(); err == nil {
+		f.Project = storage.ProjectRoot(cwd)
+	} else {
+		return m, nil
+	}
+	return m.setFilter(f)
+}
+
+// toggleSession keeps to the selected command's shell session, or drops
+// that filter when it is set.
+func (m Model) toggleSession() (Model, tea.Cmd) {
+	f := m.filter
+	if f.SessionID != "" {
+		f.SessionID = ""
+	} else if item := m.SelectedItem(); item != nil && item.SessionID != "" {
+		f.SessionID = item.SessionID
+	} else {
+		return m, nil
+	}
+	return m.setFilter(f)
+}
+
+// cycleSince steps the time range through timeRanges.
+func (m Model) cycleSince() (Model, tea.Cmd) {
+	f := m.filter
+	next := 0
+	for i, r := range timeRanges {
+		if r == f.Since {
+			next = (i + 1) % len(timeRanges)
+		}
+	}
+	f.Since = timeRanges[next]
+	return m.setFilter(f)
+}
+
+func (m Model) clearFilter() (Model, tea.Cmd) {
+	if !m.Filtered() {
+		return m, nil
+	}
+	return m.setFilter(storage.HistoryFilter{})
+}
+
+// filterBar lists the active filters; it is empty without any.
+func (m Model) filterBar(width int) string {
+	if !m.Filtered() {
+		return ""
+	}
+
+	var parts []string
+	switch m.filter.Status {
+	case storage.FailedExit:
+		parts = append(parts, "✕ failed")
+	case storage.SucceededExit:
+		parts = append(parts, "✓ succeeded")
+	}
+	if m.filter.Directory != "" {
+		parts = append(parts, "in "+shortenHome(m.filter.Directory))
+	}
+	if m.filter.Project != "" {
+		parts = append(parts, "project "+filepath.Base(m.filter.Project))
+	}
+	if m.filter.SessionID != "" {
+		session := m.filter.SessionID
+		if len(session) > 8 {
+			session = session[:8]
+		}
+		parts = append(parts, "session "+session)
+	}
+	if m.filter.Since > 0 {
+		parts = append(parts, "last "+formatRange(m.filter.Since))
+	}
+
+	return lipgloss.NewStyle().
+		Foreground(theme.Peach).
+		MaxWidth(width).
+		Render(" ⧩ " + strings.Join(parts, " · "))
+}
+
+func formatRange(d time.Duration) string {
+	if d > 24*time.Hour {
+		return fmt.Sprintf("%dd", d/(24*time.Hour))
+	}
+	return fmt.Sprintf("%dh", d/time.Hour)
+}
+
+func shortenHome(dir string) string {
+	if home, err := os.UserHomeDir(); err == nil && home != "" {
+		if rel, err := filepath.Rel(home, dir); err == nil && !strings.HasPrefix(rel, "..") {
+			return filepath.Join("~", rel)
+		}
+	}
+	return dir
+}
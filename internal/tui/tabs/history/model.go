@@ -6,7 +6,9 @@ import (
 	"strings"
 	"time"
 
+	"dev-cli/internal/pipeline"
 	"dev-cli/internal/storage"
+	"dev-cli/internal/tui/components"
 	"dev-cli/internal/tui/theme"
 
 	"github.com/charmbracelet/bubbles/list"
@@ -30,7 +32,11 @@ func (i historyItem) Title() string       { return i.Command }
 func (i historyItem) Description() string { return i.Timestamp.Format("15:04:05") }
 func (i historyItem) FilterValue() string { return i.Command }
 
-type itemDelegate struct{}
+// itemDelegate renders a history row. marked is the model's, so rows
+// marked for a drafted workflow show it.
+type itemDelegate struct {
+	marked map[int64]storage.HistoryItem
+}
 
 func (d itemDelegate) Height() int                             { return 1 }
 func (d itemDelegate) Spacing() int                            { return 0 }
@@ -49,8 +55,13 @@ func (d itemDelegate) Render(w io.Writer, m list.Model, index int, listItem list
 		iconColor = theme.Red
 	}
 
+	repeats := ""
+	if i.Repeats > 1 {
+		repeats = fmt.Sprintf(" ×%d", i.Repeats)
+	}
+
 	cmd := i.Command
-	maxWidth := m.Width() - 8
+	maxWidth := m.Width() - 8 - len([]rune(repeats))
 	if maxWidth < 10 {
 		maxWidth = 10
 	}
@@ -60,7 +71,14 @@ func (d itemDelegate) Render(w io.Writer, m list.Model, index int, listItem list
 
 	iconStyle := lipgloss.NewStyle().Foreground(iconColor)
 	textStyle := lipgloss.NewStyle().Foreground(theme.Text)
-	line := fmt.Sprintf(" %s %s", iconStyle.Render(icon), textStyle.Render(cmd))
+	mark := " "
+	if _, ok := d.marked[i.ID]; ok {
+		mark = lipgloss.NewStyle().Foreground(theme.Mauve).Render(theme.Icon("◆", "*"))
+	}
+	line := fmt.Sprintf("%s%s %s", mark, iconStyle.Render(icon), textStyle.Render(cmd))
+	if repeats != "" {
+		line += lipgloss.NewStyle().Foreground(theme.Overlay0).Render(repeats)
+	}
 
 	if index == m.Index() {
 		line = lipgloss.NewStyle().
@@ -81,10 +99,27 @@ type Model struct {
 	list     list.Model
 	viewport viewport.Model
 	history  []storage.HistoryItem
+	store    pipeline.Availability
+	filter   storage.HistoryFilter
+
+	// The Stats view replaces the list while showStats is set; stats is
+	// nil until they arrive.
+	showStats bool
+	stats     *storage.HistoryStats
+
+	// The Sessions view replaces the list while showSessions is set.
+	showSessions   bool
+	sessions       []storage.Session
+	sessionsLoaded bool
+	sessionCursor  int
+
+	// marked are the commands marked for a drafted workflow, by ID. They
+	// stay marked when a filter hides them. The delegate shares the map.
+	marked map[int64]storage.HistoryItem
 }
 
 func New() Model {
-	delegate := itemDelegate{}
+	delegate := itemDelegate{marked: make(map[int64]storage.HistoryItem)}
 	l := list.New([]list.Item{}, delegate, 0, 0)
 	l.SetShowHelp(false)
 	l.SetShowTitle(false)
@@ -99,6 +134,7 @@ func New() Model {
 		list:     l,
 		viewport: vp,
 		focus:    FocusSidebar,
+		marked:   delegate.marked,
 	}
 }
 
@@ -124,10 +160,23 @@ func (m Model) SetSize(w, h int) Model {
 		panelHeight = 10
 	}
 
+	detailsHeight := panelHeight
+	if components.Compact(w) {
+		sidebarWidth = w - 2
+		detailsWidth = w - 4
+		detailsHeight = panelHeight - stackedListHeight(panelHeight) - 2
+		panelHeight = stackedListHeight(panelHeight)
+	}
+
 	m.list.SetWidth(sidebarWidth - 2)
-	m.list.SetHeight(panelHeight - 4)
+	listHeight := panelHeight - 4
+	if m.Filtered() {
+		// The filter bar takes a line above the list.
+		listHeight--
+	}
+	m.list.SetHeight(listHeight)
 	m.viewport.Width = detailsWidth - 4
-	m.viewport.Height = panelHeight - 4
+	m.viewport.Height = detailsHeight - 4
 
 	m.updateDetailsContent()
 	return m
@@ -138,6 +187,13 @@ func (m Model) SetFocus(f FocusPanel) Model {
 	return m
 }
 
+// SetAvailability records whether the history database opened, so the list
+// can show a setup prompt instead of looking empty.
+func (m Model) SetAvailability(a pipeline.Availability) Model {
+	m.store = a
+	return m
+}
+
 func (m Model) SetHistory(items []storage.HistoryItem) Model {
 	m.history = items
 
@@ -186,7 +242,12 @@ func (m Model) formatDetails(item storage.HistoryItem) string {
 	b.WriteString(labelStyle.Render("Duration"))
 	b.WriteString(valueStyle.Render(fmt.Sprintf("%dms", item.DurationMs)) + "\n")
 	b.WriteString(labelStyle.Render("Exit Code"))
-	b.WriteString(exitStyle.Render(fmt.Sprintf("%d", item.ExitCode)) + "\n\n")
+	b.WriteString(exitStyle.Render(fmt.Sprintf("%d", item.ExitCode)) + "\n")
+	if item.Repeats > 1 {
+		b.WriteString(labelStyle.Render("Repeats"))
+		b.WriteString(valueStyle.Render(fmt.Sprintf("%d runs in a row (the latest shown)", item.Repeats)) + "\n")
+	}
+	b.WriteString("\n")
 	b.WriteString(labelStyle.Render("Command") + "\n")
 	b.WriteString(codeStyle.Render(item.Command) + "\n")
 	if item.Details != "" {
@@ -0,0 +1,173 @@
+package history
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"dev-cli/internal/storage"
+	"dev-cli/internal/tui/theme"
+
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
+)
+
+// SessionsMsg asks for the stored sessions, for the Sessions view.
+type SessionsMsg struct{}
+
+// ReplayMsg asks to replay the commands of session SessionID in the Agent.
+type ReplayMsg struct {
+	SessionID string
+}
+
+// ShowingSessions reports whether the Sessions view replaces the list.
+func (m Model) ShowingSessions() bool { return m.showSessions }
+
+// SetSessions shows sessions, newest first, in the Sessions view.
+func (m Model) SetSessions(sessions []storage.Session) Model {
+	m.sessions = sessions
+	m.sessionsLoaded = true
+	m.sessionCursor = min(m.sessionCursor, max(len(sessions)-1, 0))
+	return m
+}
+
+// toggleSessions switches between the list and the Sessions view, which
+// is reloaded each time it opens.
+func (m Model) toggleSessions() (Model, tea.Cmd) {
+	m.showSessions = !m.showSessions
+	if !m.showSessions {
+		return m, nil
+	}
+	m.showStats = false
+	m.sessions, m.sessionsLoaded, m.sessionCursor = nil, false, 0
+	return m, func() tea.Msg { return SessionsMsg{} }
+}
+
+// selectedSession is the session under the cursor, if any.
+func (m Model) selectedSession() *storage.Session {
+	if m.sessionCursor < 0 || m.sessionCursor >= len(m.sessions) {
+		return nil
+	}
+	return &m.sessions[m.sessionCursor]
+}
+
+// updateSessions handles a key while the Sessions view shows: Enter lists
+// the selected session's commands and R replays them in the Agent.
+func (m Model) updateSessions(msg tea.KeyMsg, keys KeyMap) (Model, tea.Cmd) {
+	switch {
+	case key.Matches(msg, keys.Up):
+		m.sessionCursor = max(m.sessionCursor-1, 0)
+	case key.Matches(msg, keys.Down):
+		m.sessionCursor = min(m.sessionCursor+1, max(len(m.sessions)-1, 0))
+	case key.Matches(msg, keys.Details):
+		s := m.selectedSession()
+		if s == nil {
+			return m, nil
+		}
+		m.showSessions = false
+		f := m.filter
+		f.SessionID = s.ID
+		return m.setFilter(f)
+	case key.Matches(msg, keys.Replay):
+		if s := m.selectedSession(); s != nil {
+			id := s.ID
+			return m, func() tea.Msg { return ReplayMsg{SessionID: id} }
+		}
+	case key.Matches(msg, keys.Sessions), msg.String() == "esc":
+		m.showSessions = false
+	case key.Matches(msg, keys.Stats):
+		m.showSessions = false
+		return m.toggleStats()
+	}
+	return m, nil
+}
+
+func (m Model) renderSessions(width, height int) string {
+	panelStyle := lipgloss.NewStyle().
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(theme.Mauve).
+		Width(width).
+		Height(height)
+	headerStyle := lipgloss.NewStyle().Foreground(theme.Lavender).Bold(true)
+	dimStyle := lipgloss.NewStyle().Foreground(theme.Overlay0)
+
+	header := headerStyle.Render(" ◫ Sessions")
+	switch {
+	case m.store.Missing():
+		return panelStyle.Render(header + "\n" + lipgloss.NewStyle().
+			Foreground(theme.Peach).
+			Width(width-2).
+			Padding(1).
+			Render(m.store.Hint))
+	case !m.sessionsLoaded:
+		return panelStyle.Render(header + "\n" + dimStyle.Padding(1).Render("Loading sessions..."))
+	case len(m.sessions) == 0:
+		return panelStyle.Render(header + "\n" + dimStyle.Padding(1).Render("No sessions yet: the TUI and shells with the hook loaded each start one"))
+	}
+
+	header += formatCount(m.sessionCursor+1, len(m.sessions))
+	hint := dimStyle.Render(" Enter list its commands • R replay in Agent • S back")
+	visible := max(height-2, 1)
+	top := max(m.sessionCursor-visible+1, 0)
+
+	lines := []string{header}
+	for i := top; i < len(m.sessions) && i < top+visible; i++ {
+		lines = append(lines, sessionRow(m.sessions[i], width-2, i == m.sessionCursor))
+	}
+	for len(lines) < visible+1 {
+		lines = append(lines, "")
+	}
+	lines = append(lines, hint)
+	return panelStyle.Render(strings.Join(lines, "\n"))
+}
+
+// sessionRow is one line of the Sessions view: where the session ran,
+// when and for how long, and how its commands went.
+func sessionRow(s storage.Session, width int, selected bool) string {
+	sourceStyle := lipgloss.NewStyle().Foreground(theme.Blue)
+	textStyle := lipgloss.NewStyle().Foreground(theme.Text)
+	dimStyle := lipgloss.NewStyle().Foreground(theme.Overlay0)
+
+	end := s.LastSeen()
+	when := s.StartedAt.Format("Jan 02 15:04") + "–" + end.Format("15:04")
+	if end.YearDay() != s.StartedAt.YearDay() || end.Year() != s.StartedAt.Year() {
+		when = s.StartedAt.Format("Jan 02 15:04") + "–" + end.Format("Jan 02 15:04")
+	}
+	noun := "commands"
+	if s.Commands == 1 {
+		noun = "command"
+	}
+
+	line := " " + sourceStyle.Render(fmt.Sprintf("%-4s", s.Source)) + " " +
+		textStyle.Render(when) + dimStyle.Render(" ("+formatSpan(end.Sub(s.StartedAt))+")") +
+		"  " + textStyle.Render(fmt.Sprintf("%d %s", s.Commands, noun))
+	if s.Failed > 0 {
+		line += lipgloss.NewStyle().Foreground(theme.Red).Render(fmt.Sprintf(" · %d failed", s.Failed))
+	}
+	if s.Directory != "" {
+		line += dimStyle.Render("  " + shortenHome(s.Directory))
+	}
+	line = ansi.Truncate(line, width, "…")
+
+	if selected {
+		return lipgloss.NewStyle().
+			Background(theme.Surface1).
+			Bold(true).
+			Width(width).
+			Render(line)
+	}
+	return line
+}
+
+// formatSpan is a session's length, to the minute.
+func formatSpan(d time.Duration) string {
+	switch {
+	case d < time.Minute:
+		return "<1m"
+	case d < time.Hour:
+		return fmt.Sprintf("%dm", d/time.Minute)
+	}
+	return fmt.Sprintf("%dh%02dm", d/time.Hour, d%time.Hour/time.Minute)
+}
@@ -0,0 +1,178 @@
+package history
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"dev-cli/internal/storage"
+	"dev-cli/internal/tui/components"
+	"dev-cli/internal/tui/theme"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
+)
+
+// StatsMsg asks for the stats of the history matching Filter.
+type StatsMsg struct {
+	Filter storage.HistoryFilter
+}
+
+// ShowingStats reports whether the Stats view replaces the list.
+func (m Model) ShowingStats() bool { return m.showStats }
+
+// SetStats shows stats in the Stats view.
+func (m Model) SetStats(stats storage.HistoryStats) Model {
+	m.stats = &stats
+	return m
+}
+
+// toggleStats switches between the list and the Stats view, which is
+// reloaded each time it opens.
+func (m Model) toggleStats() (Model, tea.Cmd) {
+	m.showStats = !m.showStats
+	if !m.showStats {
+		return m, nil
+	}
+	m.showSessions = false
+	m.stats = nil
+	return m, m.requestStats()
+}
+
+func (m Model) requestStats() tea.Cmd {
+	filter := m.filter
+	return func() tea.Msg { return StatsMsg{Filter: filter} }
+}
+
+func (m Model) renderStats(width, height int) string {
+	panelStyle := lipgloss.NewStyle().
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(theme.Mauve).
+		Width(width).
+		Height(height)
+	headerStyle := lipgloss.NewStyle().Foreground(theme.Lavender).Bold(true)
+	dimStyle := lipgloss.NewStyle().Foreground(theme.Overlay0)
+
+	header := headerStyle.Render(" ≋ Stats")
+	lines := []string{header}
+	if bar := m.filterBar(width - 2); bar != "" {
+		lines = append(lines, bar)
+	}
+
+	switch {
+	case m.store.Missing():
+		lines = append(lines, lipgloss.NewStyle().
+			Foreground(theme.Peach).
+			Width(width-2).
+			Padding(1).
+			Render(m.store.Hint))
+		return panelStyle.Render(strings.Join(lines, "\n"))
+	case m.stats == nil:
+		lines = append(lines, dimStyle.Padding(1).Render("Summing up history..."))
+		return panelStyle.Render(strings.Join(lines, "\n"))
+	case m.stats.Total == 0:
+		lines = append(lines, dimStyle.Padding(1).Render("No commands to sum up"))
+		return panelStyle.Render(strings.Join(lines, "\n"))
+	}
+
+	stats := m.stats
+	lines[0] = header + dimStyle.Render(fmt.Sprintf("  %d commands · %d failed (%d%%)",
+		stats.Total, stats.Failed, stats.Failed*100/stats.Total))
+
+	// Narrow terminals stack the two columns.
+	compact := components.Compact(m.width)
+	colWidth := (width - 4) / 2
+	if compact {
+		colWidth = width - 2
+	}
+	left := strings.Join(append(m.mostRun(colWidth), append([]string{""}, m.slowest(colWidth)...)...), "\n")
+	right := strings.Join(append(m.failureRates(colWidth), append([]string{""}, m.busiestHours(colWidth)...)...), "\n")
+	columns := lipgloss.JoinHorizontal(lipgloss.Top,
+		lipgloss.NewStyle().Width(colWidth+2).Render(left), right)
+	if compact {
+		columns = left + "\n\n" + right
+		panelStyle = panelStyle.MaxHeight(height + 2)
+	}
+
+	lines = append(lines, "", columns)
+	return panelStyle.Render(strings.Join(lines, "\n"))
+}
+
+func sectionTitle(title string) string {
+	return lipgloss.NewStyle().Foreground(theme.Lavender).Bold(true).Render(" " + title)
+}
+
+// mostRun lists the most run commands with how often and how long they run.
+func (m Model) mostRun(width int) []string {
+	lines := []string{sectionTitle("Most run")}
+	if len(m.stats.MostRun) == 0 {
+		return lines
+	}
+	top := m.stats.MostRun[0].Runs
+	for _, c := range m.stats.MostRun {
+		bar := components.NewProgressBar(c.Runs, top).SetWidth(10)
+		bar.ShowPct = false
+		prefix := fmt.Sprintf(" %4d× ", c.Runs)
+		suffix := " " + bar.Render() + " " + fmt.Sprintf("%6s", formatAvg(c.AvgDuration()))
+		lines = append(lines, prefix+commandCell(c.Command, width-lipgloss.Width(prefix)-lipgloss.Width(suffix))+suffix)
+	}
+	return lines
+}
+
+// failureRates lists the commands that fail most often, for their runs.
+func (m Model) failureRates(width int) []string {
+	lines := []string{sectionTitle("Failure rate")}
+	if len(m.stats.FailureRates) == 0 {
+		return append(lines, lipgloss.NewStyle().Foreground(theme.Overlay0).Render("  nothing fails repeatedly"))
+	}
+	for _, c := range m.stats.FailureRates {
+		bar := components.NewProgressBar(c.Failures, c.Runs).SetWidth(10).Render()
+		suffix := " " + bar + fmt.Sprintf(" %5s", fmt.Sprintf("%d/%d", c.Failures, c.Runs))
+		lines = append(lines, " "+commandCell(c.Command, width-1-lipgloss.Width(suffix))+suffix)
+	}
+	return lines
+}
+
+// slowest lists the commands that take longest on average.
+func (m Model) slowest(width int) []string {
+	lines := []string{sectionTitle("Slowest on average")}
+	for _, c := range m.stats.Slowest {
+		prefix := fmt.Sprintf(" %7s  ", formatAvg(c.AvgDuration()))
+		lines = append(lines, prefix+commandCell(c.Command, width-lipgloss.Width(prefix)))
+	}
+	return lines
+}
+
+// busiestHours charts the commands per hour of the day.
+func (m Model) busiestHours(width int) []string {
+	peak, peakHour := 0, 0
+	for hour, n := range m.stats.Hours {
+		if n > peak {
+			peak, peakHour = n, hour
+		}
+	}
+	spark := components.NewSparkline(m.stats.Hours[:], peak).SetWidth(len(m.stats.Hours)).Render()
+	dimStyle := lipgloss.NewStyle().Foreground(theme.Overlay0)
+	return []string{
+		sectionTitle("Busiest hours"),
+		" " + spark,
+		dimStyle.Render(" 0     6     12    18   23"),
+		ansi.Truncate(dimStyle.Render(fmt.Sprintf(" peak %02d:00–%02d:00, %d commands", peakHour, (peakHour+1)%24, peak)), width, "…"),
+	}
+}
+
+// commandCell fits command into width cells, padding short ones.
+func commandCell(command string, width int) string {
+	width = max(width, 4)
+	command = ansi.Truncate(command, width, "…")
+	return lipgloss.NewStyle().Foreground(theme.Text).Render(command) +
+		strings.Repeat(" ", width-lipgloss.Width(command))
+}
+
+func formatAvg(d time.Duration) string {
+	if d < time.Second {
+		return fmt.Sprintf("%dms", d.Milliseconds())
+	}
+	return d.Round(100 * time.Millisecond).String()
+}
@@ -12,6 +12,22 @@ type KeyMap struct {
 	Details  key.Binding
 	PageUp   key.Binding
 	PageDown key.Binding
+
+	// Filters
+	Status      key.Binding
+	Directory   key.Binding
+	Project     key.Binding
+	Session     key.Binding
+	Since       key.Binding
+	ClearFilter key.Binding
+
+	Stats    key.Binding
+	Sessions key.Binding
+	Replay   key.Binding
+
+	// Mark picks commands for a drafted workflow, which Draft asks for.
+	Mark  key.Binding
+	Draft key.Binding
 }
 
 func DefaultKeyMap() KeyMap {
@@ -40,6 +56,50 @@ func DefaultKeyMap() KeyMap {
 			key.WithKeys("pgdown", "ctrl+d"),
 			key.WithHelp("PgDn", "page down"),
 		),
+		Status: key.NewBinding(
+			key.WithKeys("e"),
+			key.WithHelp("e", "failed/succeeded"),
+		),
+		Directory: key.NewBinding(
+			key.WithKeys("d"),
+			key.WithHelp("d", "this directory"),
+		),
+		Project: key.NewBinding(
+			key.WithKeys("p"),
+			key.WithHelp("p", "this project"),
+		),
+		Session: key.NewBinding(
+			key.WithKeys("s"),
+			key.WithHelp("s", "this session"),
+		),
+		Since: key.NewBinding(
+			key.WithKeys("t"),
+			key.WithHelp("t", "time range"),
+		),
+		ClearFilter: key.NewBinding(
+			key.WithKeys("c"),
+			key.WithHelp("c", "clear filters"),
+		),
+		Stats: key.NewBinding(
+			key.WithKeys("v"),
+			key.WithHelp("v", "stats"),
+		),
+		Sessions: key.NewBinding(
+			key.WithKeys("S"),
+			key.WithHelp("S", "sessions"),
+		),
+		Replay: key.NewBinding(
+			key.WithKeys("R"),
+			key.WithHelp("R", "replay session"),
+		),
+		Mark: key.NewBinding(
+			key.WithKeys("m"),
+			key.WithHelp("m", "mark for workflow"),
+		),
+		Draft: key.NewBinding(
+			key.WithKeys("W"),
+			key.WithHelp("W", "draft workflow"),
+		),
 	}
 }
 
@@ -48,6 +108,9 @@ func (m Model) Update(msg tea.Msg, keys KeyMap) (Model, tea.Cmd) {
 
 	switch msg := msg.(type) {
 	case tea.KeyMsg:
+		if m.showSessions {
+			return m.updateSessions(msg, keys)
+		}
 		switch {
 		case key.Matches(msg, keys.Tab):
 			if m.focus == FocusSidebar {
@@ -97,6 +160,36 @@ func (m Model) Update(msg tea.Msg, keys KeyMap) (Model, tea.Cmd) {
 			if m.focus == FocusSidebar {
 				m.focus = FocusMain
 			}
+
+		case key.Matches(msg, keys.Status):
+			return m.cycleStatus()
+
+		case key.Matches(msg, keys.Directory):
+			return m.toggleDirectory()
+
+		case key.Matches(msg, keys.Project):
+			return m.toggleProject()
+
+		case key.Matches(msg, keys.Session):
+			return m.toggleSession()
+
+		case key.Matches(msg, keys.Since):
+			return m.cycleSince()
+
+		case key.Matches(msg, keys.ClearFilter):
+			return m.clearFilter()
+
+		case key.Matches(msg, keys.Stats):
+			return m.toggleStats()
+
+		case key.Matches(msg, keys.Sessions):
+			return m.toggleSessions()
+
+		case key.Matches(msg, keys.Mark):
+			m.toggleMark()
+
+		case key.Matches(msg, keys.Draft):
+			return m.draftWorkflow()
 		}
 	}
 
@@ -3,6 +3,7 @@ package history
 import (
 	"fmt"
 
+	"dev-cli/internal/tui/components"
 	"dev-cli/internal/tui/theme"
 
 	"github.com/charmbracelet/lipgloss"
@@ -28,12 +29,33 @@ func (m Model) View() string {
 		panelHeight = 10
 	}
 
+	if m.showSessions {
+		return m.renderSessions(m.width-2, panelHeight)
+	}
+	if m.showStats {
+		return m.renderStats(m.width-2, panelHeight)
+	}
+
+	if components.Compact(m.width) {
+		// Narrow terminals stack the list above the details.
+		listHeight := stackedListHeight(panelHeight)
+		return lipgloss.JoinVertical(lipgloss.Left,
+			m.renderHistoryList(m.width-2, listHeight),
+			m.renderDetailsPanel(m.width-2, panelHeight-listHeight-2))
+	}
+
 	sidebar := m.renderHistoryList(sidebarWidth, panelHeight)
 	details := m.renderDetailsPanel(detailsWidth, panelHeight)
 
 	return lipgloss.JoinHorizontal(lipgloss.Top, sidebar, details)
 }
 
+// stackedListHeight is the list's share of the compact layout, which
+// stacks it above the details.
+func stackedListHeight(panelHeight int) int {
+	return max(panelHeight*2/5, 6)
+}
+
 func (m Model) renderHistoryList(width, height int) string {
 
 	borderColor := theme.Surface2
@@ -58,10 +80,23 @@ func (m Model) renderHistoryList(width, height int) string {
 	if len(m.history) > 0 {
 		header += countStyle.Render(" " + formatCount(m.list.Index()+1, len(m.history)))
 	}
+	if len(m.marked) > 0 {
+		header += lipgloss.NewStyle().Foreground(theme.Mauve).Render(fmt.Sprintf(" · %d marked, W drafts a workflow", len(m.marked)))
+	}
 
 	listContent := m.list.View()
+	if m.store.Missing() {
+		listContent = lipgloss.NewStyle().
+			Foreground(theme.Peach).
+			Width(width - 2).
+			Padding(1).
+			Render(m.store.Hint)
+	}
 
 	content := header + "\n" + listContent
+	if bar := m.filterBar(width - 2); bar != "" {
+		content = header + "\n" + bar + "\n" + listContent
+	}
 
 	return panelStyle.Render(content)
 }
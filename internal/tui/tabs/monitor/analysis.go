@@ -0,0 +1,141 @@
+package monitor
+
+import (
+	"fmt"
+	"strings"
+
+	"dev-cli/internal/llm"
+	"dev-cli/internal/tui/theme"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// AnalyzeLogsMsg asks the app for an AI analysis of the log lines the logs
+// panel shows; Source names where they come from.
+type AnalyzeLogsMsg struct {
+	Source string
+	Lines  []string
+}
+
+// UseFixMsg asks the app to put a suggested fix in the Agent's input.
+type UseFixMsg struct {
+	Command string
+}
+
+// LogAnalysisView shows what the AI makes of the visible logs: what went
+// wrong, and a fix that c puts in the Agent's input.
+type LogAnalysisView struct {
+	source  string
+	lines   int
+	loading bool
+	result  *llm.LogAnalysisResult
+	err     string
+}
+
+// AnalysisOpen reports whether the analysis of the logs is shown.
+func (m Model) AnalysisOpen() bool { return m.analysis != nil }
+
+// OpenAnalysis sends the log lines the panel shows for analysis; with none
+// there is nothing to ask about.
+func (m Model) OpenAnalysis() (Model, tea.Cmd) {
+	lines := m.VisibleLogLines()
+	if len(lines) == 0 {
+		return m, nil
+	}
+	source := "logs"
+	if m.MergedLogs() {
+		source = fmt.Sprintf("%d containers", len(m.mergedLogs))
+	} else if svc := m.SelectedService(); svc != nil {
+		source = svc.Name
+	}
+	m.analysis = &LogAnalysisView{source: source, lines: len(lines), loading: true}
+	return m, func() tea.Msg { return AnalyzeLogsMsg{Source: source, Lines: lines} }
+}
+
+func (m Model) CloseAnalysis() Model {
+	m.analysis = nil
+	return m
+}
+
+// SetAnalysis shows the outcome of the analysis, unless it was closed
+// while the AI was thinking.
+func (m Model) SetAnalysis(result *llm.LogAnalysisResult, err error) Model {
+	if m.analysis == nil {
+		return m
+	}
+	v := *m.analysis
+	v.loading = false
+	v.result = result
+	v.err = ""
+	if err != nil {
+		v.err = err.Error()
+	}
+	m.analysis = &v
+	return m
+}
+
+// Update handles a key while the analysis is shown. Closing it (esc) is
+// left to the caller.
+func (v LogAnalysisView) Update(msg tea.KeyMsg) (LogAnalysisView, tea.Cmd) {
+	if msg.String() == "c" && v.result != nil && strings.TrimSpace(v.result.Fix) != "" {
+		fix := strings.TrimSpace(v.result.Fix)
+		return v, func() tea.Msg { return UseFixMsg{Command: fix} }
+	}
+	return v, nil
+}
+
+func (v LogAnalysisView) View(width, height int) string {
+	panelStyle := lipgloss.NewStyle().
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(theme.Mauve).
+		Width(width).
+		Height(height).
+		MaxHeight(height).
+		MaxWidth(width)
+
+	headerStyle := lipgloss.NewStyle().Foreground(theme.Lavender).Bold(true)
+	dimStyle := lipgloss.NewStyle().Foreground(theme.Overlay0)
+	labelStyle := lipgloss.NewStyle().Foreground(theme.Blue).Bold(true)
+	textStyle := lipgloss.NewStyle().Foreground(theme.Text).Width(width - 4)
+
+	var content strings.Builder
+	content.WriteString(headerStyle.Render("✦ Log analysis") + dimStyle.Render(" ("+v.source+")") + "\n")
+	lines := fmt.Sprintf("%d lines", v.lines)
+	if v.lines == 1 {
+		lines = "1 line"
+	}
+	content.WriteString(dimStyle.Render(lines+" as shown, secrets masked") + "\n\n")
+
+	switch {
+	case v.loading:
+		content.WriteString(lipgloss.NewStyle().Foreground(theme.Yellow).Render("Analyzing…") + "\n\n")
+		content.WriteString(dimStyle.Render("Esc close"))
+	case v.err != "":
+		content.WriteString(lipgloss.NewStyle().Foreground(theme.Red).Width(width-4).Render("✗ "+v.err) + "\n\n")
+		content.WriteString(dimStyle.Render("Esc close"))
+	default:
+		content.WriteString(labelStyle.Render("What happened") + "\n")
+		content.WriteString(textStyle.Render(strings.TrimSpace(v.result.Explanation)) + "\n\n")
+		fix := strings.TrimSpace(v.result.Fix)
+		if fix == "" {
+			content.WriteString(dimStyle.Render("No fix suggested.") + "\n\n")
+			content.WriteString(dimStyle.Render("Esc close"))
+			break
+		}
+		content.WriteString(labelStyle.Render("Fix") + "\n")
+		content.WriteString(lipgloss.NewStyle().Foreground(theme.Green).Render("$ "+truncateLine(fix, width-6)) + "\n\n")
+		button := lipgloss.NewStyle().
+			Background(theme.Mauve).
+			Foreground(theme.Crust).
+			Bold(true).
+			Padding(0, 1).
+			Render("c Copy fix to Agent")
+		content.WriteString(button + dimStyle.Render("  Esc close"))
+		if v.result.Model != "" {
+			content.WriteString(dimStyle.Render(" • by " + v.result.Model))
+		}
+	}
+
+	return panelStyle.Render(content.String())
+}
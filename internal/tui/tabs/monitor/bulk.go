@@ -0,0 +1,101 @@
+package monitor
+
+import (
+	"fmt"
+	"strings"
+
+	"dev-cli/internal/infra"
+	"dev-cli/internal/tui/components"
+	"dev-cli/internal/tui/theme"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// BulkActionMsg asks the app to "restart", "stop" or "remove" several
+// containers, one after another.
+type BulkActionMsg struct {
+	Action     string
+	Containers []infra.ContainerInfo
+}
+
+// BulkConfirm sums up an action on the marked services before it runs; y
+// confirms, n or esc cancels.
+type BulkConfirm struct {
+	action     string
+	containers []infra.ContainerInfo
+}
+
+// Marked returns the services marked with space, in pick order; their logs
+// are the merged ones.
+func (m Model) Marked() []infra.ContainerInfo {
+	if len(m.mergedLogs) == 0 {
+		return nil
+	}
+	return m.LogTargets()
+}
+
+// OpenBulk asks before applying action to containers; in expert mode it
+// applies it straight away.
+func (m Model) OpenBulk(action string, containers []infra.ContainerInfo) (Model, tea.Cmd) {
+	if len(containers) == 0 {
+		return m, nil
+	}
+	m.bulk = &BulkConfirm{action: action, containers: containers}
+	if m.expert {
+		return m.acceptBulk()
+	}
+	return m, nil
+}
+
+func (m Model) acceptBulk() (Model, tea.Cmd) {
+	send := BulkActionMsg{Action: m.bulk.action, Containers: m.bulk.containers}
+	m.bulk = nil
+	return m, func() tea.Msg { return send }
+}
+
+func (m Model) CloseBulk() Model {
+	m.bulk = nil
+	return m
+}
+
+func (c BulkConfirm) confirm() components.Confirm {
+	what := fmt.Sprintf("%d containers", len(c.containers))
+	if len(c.containers) == 1 {
+		what = c.containers[0].Name
+	}
+	var items []string
+	running := 0
+	for _, svc := range c.containers {
+		dot := lipgloss.NewStyle().Foreground(theme.Red).Render("○")
+		if svc.State == "running" {
+			dot = lipgloss.NewStyle().Foreground(theme.Green).Render("●")
+			running++
+		}
+		items = append(items, dot+" "+svc.Name+lipgloss.NewStyle().Foreground(theme.Overlay0).Render(" "+svc.State))
+	}
+
+	var text string
+	switch c.action {
+	case "restart":
+		text = "Restarts each one in turn, like docker restart."
+	case "stop":
+		text = "Stops each one in turn; they keep their filesystem and can be started again."
+	case "remove":
+		text = "Deletes each container and its writable layer; volumes and images stay."
+		if running > 0 {
+			text += fmt.Sprintf(" %d still running will be killed first.", running)
+		}
+	}
+	return components.Confirm{
+		Title:  strings.ToUpper(c.action[:1]) + c.action[1:] + " " + what,
+		Items:  items,
+		Text:   []string{text},
+		Verb:   c.action,
+		Danger: c.action == "remove",
+	}
+}
+
+func (c BulkConfirm) View(width, height int) string {
+	return c.confirm().View(width, height)
+}
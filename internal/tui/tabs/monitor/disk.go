@@ -0,0 +1,52 @@
+package monitor
+
+import (
+	"fmt"
+
+	"dev-cli/internal/infra"
+	"dev-cli/internal/tui/components"
+)
+
+// PruneMsg asks the app to prune a disk usage category.
+type PruneMsg struct {
+	Kind infra.DiskKind
+}
+
+// diskLabels are the row labels of the Disk panel.
+var diskLabels = map[infra.DiskKind]string{
+	infra.DiskImages:     "Images",
+	infra.DiskContainers: "Ctnrs",
+	infra.DiskVolumes:    "Volumes",
+	infra.DiskBuildCache: "Cache",
+}
+
+// pruneTargets say what pruning a category removes, for the confirmation.
+var pruneTargets = map[infra.DiskKind]string{
+	infra.DiskImages:     "every image no container uses, tagged or not",
+	infra.DiskContainers: "every stopped container",
+	infra.DiskVolumes:    "every volume no container mounts, named ones included",
+	infra.DiskBuildCache: "all build cache that is not in use",
+}
+
+// PruneConfirm asks before a prune; y confirms, n or esc cancels.
+type PruneConfirm struct {
+	category infra.DiskUsageCategory
+}
+
+func (p PruneConfirm) confirm() components.Confirm {
+	c := p.category
+	return components.Confirm{
+		Title: "Prune " + string(c.Kind),
+		Text: []string{
+			"This removes " + pruneTargets[c.Kind] + ".",
+			fmt.Sprintf("Frees up to %s of %s.", formatSize(c.Reclaimable), formatSize(c.Size)),
+		},
+		Verb: "prune",
+		// Volumes hold data; the rest can be pulled or built again.
+		Danger: c.Kind == infra.DiskVolumes,
+	}
+}
+
+func (p PruneConfirm) View(width, height int) string {
+	return p.confirm().View(width, height)
+}
@@ -0,0 +1,254 @@
+package monitor
+
+import (
+	"path"
+	"strings"
+
+	"dev-cli/internal/infra"
+	"dev-cli/internal/tui/theme"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// ListDirMsg asks the app to list a directory inside a container.
+type ListDirMsg struct {
+	ContainerID string
+	Path        string
+}
+
+// CopyFileMsg asks the app to copy between a container and the host.
+// Upload copies HostPath into the container at ContainerPath; otherwise
+// ContainerPath is copied out to HostPath.
+type CopyFileMsg struct {
+	ContainerID   string
+	ContainerPath string
+	HostPath      string
+	Upload        bool
+}
+
+// FileBrowser walks a container's filesystem one directory at a time. d
+// copies the selected entry to the host and u pushes a host file into the
+// current directory, both through a one-line path prompt.
+type FileBrowser struct {
+	containerID string
+	container   string
+
+	dir     string
+	entries []infra.ContainerFile
+	cursor  int
+	loading bool
+	err     string
+	status  string
+
+	// prompt asks for the host path while a copy is being set up.
+	prompt *textinput.Model
+	upload bool
+}
+
+func NewFileBrowser(svc infra.ContainerInfo) FileBrowser {
+	return FileBrowser{containerID: svc.ID, container: svc.Name, dir: "/", loading: true}
+}
+
+// list asks for dir's entries; the browser moves there once they arrive.
+func (b FileBrowser) list(dir string) (FileBrowser, tea.Cmd) {
+	b.loading = true
+	b.err = ""
+	id := b.containerID
+	return b, func() tea.Msg { return ListDirMsg{ContainerID: id, Path: dir} }
+}
+
+// Open lists the container's root directory.
+func (b FileBrowser) Open() (FileBrowser, tea.Cmd) {
+	return b.list("/")
+}
+
+// SetEntries shows a listing of dir; a failed listing keeps the current one.
+func (b FileBrowser) SetEntries(dir string, entries []infra.ContainerFile, err error) FileBrowser {
+	b.loading = false
+	if err != nil {
+		b.err = err.Error()
+		return b
+	}
+	b.err = ""
+	b.dir = dir
+	b.entries = entries
+	b.cursor = 0
+	return b
+}
+
+// Copied reports the outcome of a copy.
+func (b FileBrowser) Copied(status string, err error) FileBrowser {
+	b.loading = false
+	b.status = status
+	b.err = ""
+	if err != nil {
+		b.status = ""
+		b.err = err.Error()
+	}
+	return b
+}
+
+// Prompting reports whether the host path prompt has the keyboard.
+func (b FileBrowser) Prompting() bool { return b.prompt != nil }
+
+// rows are the entries with ".." first below the root.
+func (b FileBrowser) rows() []infra.ContainerFile {
+	if b.dir == "/" {
+		return b.entries
+	}
+	return append([]infra.ContainerFile{{Name: "..", Dir: true}}, b.entries...)
+}
+
+func (b FileBrowser) selected() *infra.ContainerFile {
+	rows := b.rows()
+	if b.cursor < 0 || b.cursor >= len(rows) {
+		return nil
+	}
+	return &rows[b.cursor]
+}
+
+func (b FileBrowser) openPrompt(value string, upload bool) (FileBrowser, tea.Cmd) {
+	ti := textinput.New()
+	ti.Prompt = ""
+	ti.PlaceholderStyle = lipgloss.NewStyle().Foreground(theme.Overlay0)
+	ti.TextStyle = lipgloss.NewStyle().Foreground(theme.Text)
+	ti.Placeholder = "./path/on/host"
+	ti.SetValue(value)
+	ti.Focus()
+	b.prompt = &ti
+	b.upload = upload
+	b.status, b.err = "", ""
+	return b, textinput.Blink
+}
+
+// Update handles a key while the browser is open. Closing it (esc outside
+// the prompt) is left to the caller.
+func (b FileBrowser) Update(msg tea.KeyMsg) (FileBrowser, tea.Cmd) {
+	if b.prompt != nil {
+		return b.updatePrompt(msg)
+	}
+	if b.loading {
+		return b, nil
+	}
+
+	switch msg.String() {
+	case "up", "k":
+		b.cursor = max(b.cursor-1, 0)
+	case "down", "j":
+		b.cursor = min(b.cursor+1, max(len(b.rows())-1, 0))
+	case "g":
+		b.cursor = 0
+	case "G":
+		b.cursor = max(len(b.rows())-1, 0)
+	case "backspace", "h", "left":
+		if b.dir != "/" {
+			return b.list(path.Dir(b.dir))
+		}
+	case "enter", "l", "right":
+		if f := b.selected(); f != nil && f.Dir {
+			if f.Name == ".." {
+				return b.list(path.Dir(b.dir))
+			}
+			return b.list(path.Join(b.dir, f.Name))
+		}
+	case "d":
+		if f := b.selected(); f != nil && f.Name != ".." {
+			return b.openPrompt("./"+f.Name, false)
+		}
+	case "u":
+		return b.openPrompt("", true)
+	}
+	return b, nil
+}
+
+func (b FileBrowser) updatePrompt(msg tea.KeyMsg) (FileBrowser, tea.Cmd) {
+	switch msg.String() {
+	case "esc":
+		b.prompt = nil
+		return b, nil
+	case "enter":
+		host := strings.TrimSpace(b.prompt.Value())
+		if host == "" {
+			return b, nil
+		}
+		copyMsg := CopyFileMsg{ContainerID: b.containerID, HostPath: host, Upload: b.upload}
+		if b.upload {
+			copyMsg.ContainerPath = b.dir
+		} else if f := b.selected(); f != nil {
+			copyMsg.ContainerPath = path.Join(b.dir, f.Name)
+		}
+		b.prompt = nil
+		b.loading = true
+		return b, func() tea.Msg { return copyMsg }
+	}
+
+	ti, cmd := b.prompt.Update(msg)
+	b.prompt = &ti
+	return b, cmd
+}
+
+func (b FileBrowser) View(width, height int) string {
+	panelStyle := lipgloss.NewStyle().
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(theme.Mauve).
+		Width(width).
+		Height(height).
+		MaxHeight(height).
+		MaxWidth(width)
+
+	headerStyle := lipgloss.NewStyle().Foreground(theme.Lavender).Bold(true)
+	dimStyle := lipgloss.NewStyle().Foreground(theme.Overlay0)
+	dirStyle := lipgloss.NewStyle().Foreground(theme.Blue)
+	fileStyle := lipgloss.NewStyle().Foreground(theme.Text)
+	selectedStyle := lipgloss.NewStyle().Background(theme.Surface1).Foreground(theme.Lavender).Bold(true).Width(width - 2)
+
+	var content strings.Builder
+	content.WriteString(headerStyle.Render("▤ Files") + dimStyle.Render(" ("+b.container+")") + "\n")
+	content.WriteString(dimStyle.Render(truncateLine(b.dir, width-4)) + "\n\n")
+
+	// Header, path, blank line and the two footer lines.
+	visible := max(height-6, 1)
+	rows := b.rows()
+	start := 0
+	if b.cursor >= visible {
+		start = b.cursor - visible + 1
+	}
+	for i := start; i < len(rows) && i < start+visible; i++ {
+		f := rows[i]
+		line := fileStyle.Render(" " + truncateLine(f.Name, width-6))
+		if f.Dir {
+			line = dirStyle.Render(" " + truncateLine(f.Name+"/", width-6))
+		}
+		if i == b.cursor {
+			line = selectedStyle.Render(" " + truncateLine(f.Name, width-6))
+		}
+		content.WriteString(line + "\n")
+	}
+	if len(rows) == 0 && !b.loading && b.err == "" {
+		content.WriteString(dimStyle.Render(" (empty)") + "\n")
+	}
+	content.WriteString("\n")
+
+	switch {
+	case b.prompt != nil:
+		label := "Save to "
+		if b.upload {
+			label = "Upload "
+		}
+		in := *b.prompt
+		in.Width = max(width-len(label)-6, 10)
+		content.WriteString(lipgloss.NewStyle().Foreground(theme.Mauve).Bold(true).Render(label) + in.View())
+	case b.loading:
+		content.WriteString(lipgloss.NewStyle().Foreground(theme.Yellow).Render("Working…"))
+	case b.err != "":
+		content.WriteString(lipgloss.NewStyle().Foreground(theme.Red).Render(truncateLine(b.err, width-4)))
+	case b.status != "":
+		content.WriteString(lipgloss.NewStyle().Foreground(theme.Green).Render(truncateLine(b.status, width-4)))
+	default:
+		content.WriteString(dimStyle.Render("Enter open • ⌫ up • d download • u upload • Esc close"))
+	}
+
+	return panelStyle.Render(content.String())
+}
@@ -0,0 +1,95 @@
+package monitor
+
+import (
+	"fmt"
+	"strings"
+
+	"dev-cli/internal/infra"
+	"dev-cli/internal/tui/theme"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+// SetHostStats shows the host's capacity above the panels; the strip stays
+// hidden until the first sample arrives.
+func (m Model) SetHostStats(stats infra.HostStats) Model {
+	resize := m.host == nil
+	m.host = &stats
+	if resize {
+		return m.SetSize(m.width, m.height)
+	}
+	return m
+}
+
+// hostHeight is the height of the host strip, 0 while it is hidden.
+func (m Model) hostHeight() int {
+	if m.host == nil {
+		return 0
+	}
+	return 1
+}
+
+// hostShare is the selected container's usage as a share of the host: its
+// memory against host memory and its CPU (100% per core) against all cores.
+func (m Model) hostShare() string {
+	svc := m.SelectedService()
+	if svc == nil || m.host == nil || m.host.MemTotal == 0 {
+		return ""
+	}
+	stats, ok := m.containerStats[svc.Name]
+	if !ok || stats.MemTotal == 0 {
+		return ""
+	}
+	memPct := float64(uint64(stats.MemUsed)<<20) * 100 / float64(m.host.MemTotal)
+	share := fmt.Sprintf("%s: %.1f%% of host mem", svc.Name, memPct)
+	if n := len(stats.CPUHistory); n > 0 && m.host.CPUs > 0 {
+		share += fmt.Sprintf(", %.1f%% of CPU", float64(stats.CPUHistory[n-1])/float64(m.host.CPUs))
+	}
+	return share
+}
+
+// renderHostStrip renders the one-line host overview: CPU, memory, disk
+// and load, then the selected container's share of it.
+func (m Model) renderHostStrip(width int) string {
+	h := m.host
+	labelStyle := lipgloss.NewStyle().Foreground(theme.Overlay0)
+	sep := labelStyle.Render("  •  ")
+
+	level := func(pct float64) lipgloss.Style {
+		switch {
+		case pct >= 90:
+			return lipgloss.NewStyle().Foreground(theme.Red).Bold(true)
+		case pct >= 70:
+			return lipgloss.NewStyle().Foreground(theme.Peach)
+		default:
+			return lipgloss.NewStyle().Foreground(theme.Text)
+		}
+	}
+	size := func(n uint64) string {
+		return strings.Replace(formatSize(int64(n)), " ", "", 1)
+	}
+
+	parts := []string{
+		lipgloss.NewStyle().Foreground(theme.Lavender).Bold(true).Render("⌂ Host"),
+		labelStyle.Render("CPU ") + level(h.CPUPercent).Render(fmt.Sprintf("%.0f%%", h.CPUPercent)) +
+			labelStyle.Render(fmt.Sprintf(" ×%d", h.CPUs)),
+		labelStyle.Render("Mem ") + level(h.MemPercent()).Render(size(h.MemUsed)+"/"+size(h.MemTotal)),
+	}
+	if h.DiskTotal > 0 {
+		parts = append(parts, labelStyle.Render("Disk ")+level(h.DiskPercent()).Render(size(h.DiskUsed)+"/"+size(h.DiskTotal)))
+	}
+	if h.Load1 > 0 || h.Load5 > 0 || h.Load15 > 0 {
+		loadPct := 0.0
+		if h.CPUs > 0 {
+			loadPct = h.Load1 * 100 / float64(h.CPUs)
+		}
+		parts = append(parts, labelStyle.Render("Load ")+
+			level(loadPct).Render(fmt.Sprintf("%.2f %.2f %.2f", h.Load1, h.Load5, h.Load15)))
+	}
+	if share := m.hostShare(); share != "" {
+		parts = append(parts, lipgloss.NewStyle().Foreground(theme.Teal).Render(share))
+	}
+
+	line := " " + parts[0] + "  " + strings.Join(parts[1:], sep)
+	return lipgloss.NewStyle().MaxWidth(width).Render(line)
+}
@@ -0,0 +1,210 @@
+package monitor
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"dev-cli/internal/infra"
+	"dev-cli/internal/tui/components"
+	"dev-cli/internal/tui/theme"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// RemoveImageMsg asks the app to remove an image; Force removes it even
+// when containers use it.
+type RemoveImageMsg struct {
+	Image string
+	Force bool
+}
+
+// ImageMenu lists what can be done with an image; removals ask first.
+type ImageMenu struct {
+	image infra.ImageInfo
+	// users are the containers created from the image.
+	users  []string
+	cursor int
+	// confirm is the removal waiting for y: "remove" or "force".
+	confirm string
+}
+
+// ref is how the image is named to the daemon: its first tag, or its ID
+// when untagged.
+func (v ImageMenu) ref() string {
+	if len(v.image.Tags) > 0 && v.image.Tags[0] != "<none>:<none>" {
+		return v.image.Tags[0]
+	}
+	return v.image.ID
+}
+
+func (v ImageMenu) tagged() bool { return v.ref() != v.image.ID }
+
+// items are the menu's actions; an untagged image has nothing to pull.
+func (v ImageMenu) items() []components.ActionMenuItem {
+	items := []components.ActionMenuItem{
+		{Key: "i", Label: "Inspect layers"},
+		{Key: "d", Label: "Remove"},
+		{Key: "D", Label: "Force remove"},
+	}
+	if v.tagged() {
+		items = append(items, components.ActionMenuItem{Key: "u", Label: "Pull newer"})
+	}
+	return append(items, components.ActionMenuItem{Key: "n", Label: "Run"})
+}
+
+// ImageMenuOpen reports whether the image menu has the keyboard.
+func (m Model) ImageMenuOpen() bool { return m.imageMenu != nil }
+
+// OpenImageMenu shows the actions of img.
+func (m Model) OpenImageMenu(img infra.ImageInfo) Model {
+	v := ImageMenu{image: img}
+	for _, svc := range m.services {
+		if svc.Image == img.ID || slices.Contains(img.Tags, svc.Image) {
+			v.users = append(v.users, svc.Name)
+		}
+	}
+	m.imageMenu = &v
+	return m
+}
+
+func (m Model) CloseImageMenu() Model {
+	m.imageMenu = nil
+	return m
+}
+
+// updateImageMenu picks an action by its key or with j/k and Enter, and
+// asks y/n before a removal.
+func (m Model) updateImageMenu(km tea.KeyMsg) (Model, tea.Cmd) {
+	v := *m.imageMenu
+	if v.confirm != "" {
+		switch v.confirmation().Answer(km.String()) {
+		case components.ConfirmYes:
+			return m.removeImage(v)
+		case components.ConfirmNo:
+			v.confirm = ""
+			m.imageMenu = &v
+		}
+		return m, nil
+	}
+
+	items := v.items()
+	action := ""
+	switch km.String() {
+	case "esc", "q":
+		return m.CloseImageMenu(), nil
+	case "up", "k":
+		v.cursor = max(v.cursor-1, 0)
+	case "down", "j":
+		v.cursor = min(v.cursor+1, len(items)-1)
+	case "enter":
+		action = items[v.cursor].Key
+	default:
+		if slices.ContainsFunc(items, func(item components.ActionMenuItem) bool { return item.Key == km.String() }) {
+			action = km.String()
+		}
+	}
+	m.imageMenu = &v
+
+	switch action {
+	case "i":
+		m.imageMenu = nil
+		return m.OpenLayers(v.image)
+	case "d", "D":
+		v.confirm = "remove"
+		if action == "D" {
+			v.confirm = "force"
+		}
+		if m.expert {
+			return m.removeImage(v)
+		}
+		m.imageMenu = &v
+	case "u":
+		if m.pull == nil {
+			m.imageMenu = nil
+			ref := v.ref()
+			return m, func() tea.Msg { return PullImageMsg{Ref: ref} }
+		}
+	case "n":
+		m.imageMenu = nil
+		return m.OpenWizard(v.ref()), textinput.Blink
+	}
+	return m, nil
+}
+
+// removeImage closes the menu and removes its image, forced if v.confirm
+// says so.
+func (m Model) removeImage(v ImageMenu) (Model, tea.Cmd) {
+	msg := RemoveImageMsg{Image: v.ref(), Force: v.confirm == "force"}
+	m.imageMenu = nil
+	m.removing = msg.Image
+	m.imageErr = ""
+	return m, func() tea.Msg { return msg }
+}
+
+func (v ImageMenu) confirmation() components.Confirm {
+	items := []string{fmt.Sprintf("%s • %s", formatSize(v.image.Size), v.image.ID)}
+	if len(v.users) > 0 {
+		items = append(items, "Used by "+strings.Join(v.users, ", "))
+	}
+	if v.confirm == "force" {
+		text := "This removes the image even if containers use it."
+		if len(v.users) > 0 {
+			text += " " + strings.Join(v.users, ", ") + " keep running, but can't be recreated from it."
+		}
+		return components.Confirm{
+			Title:  "Force remove " + v.ref(),
+			Items:  items,
+			Text:   []string{text},
+			Verb:   "remove",
+			Danger: true,
+		}
+	}
+	text := "This removes the image, or only this tag when it has others."
+	if len(v.users) > 0 {
+		text += " Docker refuses while containers use it; force remove takes it anyway."
+	}
+	return components.Confirm{
+		Title: "Remove " + v.ref(),
+		Items: items,
+		Text:  []string{text},
+		Verb:  "remove",
+	}
+}
+
+func (v ImageMenu) View(width, height int) string {
+	if v.confirm != "" {
+		return v.confirmation().View(width, height)
+	}
+	panelStyle := lipgloss.NewStyle().
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(theme.Mauve).
+		Width(width).
+		Height(height).
+		MaxHeight(height).
+		MaxWidth(width)
+
+	headerStyle := lipgloss.NewStyle().Foreground(theme.Lavender).Bold(true)
+	dimStyle := lipgloss.NewStyle().Foreground(theme.Overlay0)
+
+	var content strings.Builder
+	content.WriteString(headerStyle.Render("⬡ "+truncateLine(v.ref(), width-6)) + "\n")
+	details := fmt.Sprintf("%s • %s", formatSize(v.image.Size), v.image.ID)
+	if !v.image.Created.IsZero() {
+		details += " • created " + v.image.Created.Format("2006-01-02")
+	}
+	content.WriteString(dimStyle.Render(truncateLine(details, width-4)) + "\n")
+	used := "Not used by any container"
+	if len(v.users) > 0 {
+		used = "Used by " + strings.Join(v.users, ", ")
+	}
+	content.WriteString(dimStyle.Render(truncateLine(used, width-4)) + "\n\n")
+
+	menu := components.NewActionMenu("Actions", v.items()...).SetSelected(v.cursor).SetWidth(min(width-4, 30))
+	content.WriteString(menu.Render() + "\n")
+	content.WriteString(dimStyle.Render("key or Enter • Esc close"))
+
+	return panelStyle.Render(content.String())
+}
@@ -0,0 +1,312 @@
+package monitor
+
+import (
+	"cmp"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+
+	"dev-cli/internal/infra"
+	"dev-cli/internal/llm"
+	"dev-cli/internal/tui/theme"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// InspectContainerMsg asks the app to inspect a container.
+type InspectContainerMsg struct {
+	ContainerID string
+}
+
+// UpdateResourcesMsg asks the app to change a container's limits in place.
+type UpdateResourcesMsg struct {
+	ContainerID string
+	Limits      infra.ResourceLimits
+}
+
+// Inspector shows a container's configuration, with secrets in its
+// environment masked, and lets its memory (m) and CPU (c) limits be
+// changed without recreating it, e.g. to give an OOM-looping container
+// more room.
+type Inspector struct {
+	containerID string
+	container   string
+
+	detail  *infra.ContainerDetail
+	loading bool
+	err     string
+	status  string
+
+	// prompt edits the limit named by field ("memory" or "cpus");
+	// pending is the change sent to the daemon.
+	prompt  *textinput.Model
+	field   string
+	pending infra.ResourceLimits
+
+	// envOffset is the first environment variable shown; j/k scroll them.
+	envOffset int
+}
+
+func NewInspector(svc infra.ContainerInfo) Inspector {
+	return Inspector{containerID: svc.ID, container: svc.Name, loading: true}
+}
+
+func (v Inspector) inspect() tea.Cmd {
+	id := v.containerID
+	return func() tea.Msg { return InspectContainerMsg{ContainerID: id} }
+}
+
+// SetDetail shows the inspected container; a failed inspect keeps the last
+// one on screen.
+func (v Inspector) SetDetail(detail *infra.ContainerDetail, err error) Inspector {
+	v.loading = false
+	if err != nil {
+		v.err = err.Error()
+		return v
+	}
+	v.detail = detail
+	return v
+}
+
+// Updated reports the outcome of a limit change.
+func (v Inspector) Updated(err error) Inspector {
+	v.loading = false
+	v.status, v.err = "", ""
+	switch {
+	case err != nil:
+		v.err = err.Error()
+	case v.pending.Memory > 0:
+		v.status = "Memory limit is now " + formatSize(v.pending.Memory)
+	case v.pending.NanoCPUs > 0:
+		v.status = "CPU limit is now " + strconv.FormatFloat(v.pending.CPUs(), 'f', -1, 64)
+	}
+	return v
+}
+
+// Prompting reports whether a limit prompt has the keyboard.
+func (v Inspector) Prompting() bool { return v.prompt != nil }
+
+func (v Inspector) openPrompt(field, value string) (Inspector, tea.Cmd) {
+	ti := textinput.New()
+	ti.Prompt = ""
+	ti.PlaceholderStyle = lipgloss.NewStyle().Foreground(theme.Overlay0)
+	ti.TextStyle = lipgloss.NewStyle().Foreground(theme.Text)
+	ti.Placeholder = "1g"
+	if field == "cpus" {
+		ti.Placeholder = "1.5"
+	}
+	ti.SetValue(value)
+	ti.Focus()
+	v.prompt = &ti
+	v.field = field
+	v.status, v.err = "", ""
+	return v, textinput.Blink
+}
+
+// Update handles a key while the inspector is open. Closing it (esc
+// outside the prompt) is left to the caller.
+func (v Inspector) Update(msg tea.KeyMsg) (Inspector, tea.Cmd) {
+	if v.prompt != nil {
+		return v.updatePrompt(msg)
+	}
+	if v.loading || v.detail == nil {
+		return v, nil
+	}
+
+	limits := v.detail.Limits
+	switch msg.String() {
+	case "m":
+		value := ""
+		if limits.Memory > 0 {
+			value = fmt.Sprintf("%dm", limits.Memory>>20)
+		}
+		return v.openPrompt("memory", value)
+	case "c":
+		value := ""
+		if limits.NanoCPUs > 0 {
+			value = strconv.FormatFloat(limits.CPUs(), 'f', -1, 64)
+		}
+		return v.openPrompt("cpus", value)
+	case "down", "j":
+		v.envOffset = min(v.envOffset+1, max(len(v.detail.EnvVars)-1, 0))
+	case "up", "k":
+		v.envOffset = max(v.envOffset-1, 0)
+	}
+	return v, nil
+}
+
+func (v Inspector) updatePrompt(msg tea.KeyMsg) (Inspector, tea.Cmd) {
+	switch msg.String() {
+	case "esc":
+		v.prompt = nil
+		return v, nil
+	case "enter":
+		var limits infra.ResourceLimits
+		var err error
+		if v.field == "memory" {
+			limits.Memory, err = infra.ParseMemoryLimit(v.prompt.Value())
+		} else {
+			limits.NanoCPUs, err = infra.ParseCPULimit(v.prompt.Value())
+		}
+		if err != nil {
+			v.err = err.Error()
+			return v, nil
+		}
+		v.prompt = nil
+		v.loading = true
+		v.err = ""
+		v.pending = limits
+		update := UpdateResourcesMsg{ContainerID: v.containerID, Limits: limits}
+		return v, func() tea.Msg { return update }
+	}
+
+	ti, cmd := v.prompt.Update(msg)
+	v.prompt = &ti
+	return v, cmd
+}
+
+// sortedPorts orders ports by container port, published ones first, and
+// drops duplicates.
+func sortedPorts(ports []infra.PortMapping) []infra.PortMapping {
+	ports = slices.Clone(ports)
+	slices.SortStableFunc(ports, func(a, b infra.PortMapping) int {
+		if (a.Public > 0) != (b.Public > 0) {
+			if a.Public > 0 {
+				return -1
+			}
+			return 1
+		}
+		return cmp.Or(cmp.Compare(a.Private, b.Private), cmp.Compare(a.Public, b.Public))
+	})
+	// The daemon binds IPv4 and IPv6 separately; show the port once.
+	return slices.CompactFunc(ports, func(a, b infra.PortMapping) bool {
+		return a.Private == b.Private && a.Public == b.Public && a.Protocol == b.Protocol
+	})
+}
+
+// View renders the inspector; stats are the container's live usage, to
+// judge the limits against.
+func (v Inspector) View(width, height int, stats ContainerStats) string {
+	panelStyle := lipgloss.NewStyle().
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(theme.Mauve).
+		Width(width).
+		Height(height).
+		MaxHeight(height).
+		MaxWidth(width)
+
+	headerStyle := lipgloss.NewStyle().Foreground(theme.Lavender).Bold(true)
+	dimStyle := lipgloss.NewStyle().Foreground(theme.Overlay0)
+	labelStyle := lipgloss.NewStyle().Foreground(theme.Overlay0).Width(10)
+	textStyle := lipgloss.NewStyle().Foreground(theme.Text)
+
+	var content strings.Builder
+	content.WriteString(headerStyle.Render("ⓘ "+v.container) + "\n\n")
+
+	row := func(label, value string) {
+		content.WriteString(labelStyle.Render(label) + textStyle.Render(truncateLine(value, width-14)) + "\n")
+	}
+
+	if d := v.detail; d != nil {
+		row("Image", d.Image)
+		state := d.State
+		if d.Uptime != "" && d.State == "running" {
+			state += " for " + d.Uptime
+		}
+		row("State", state)
+		if d.Health != "" {
+			row("Health", d.Health)
+		}
+		if d.RestartCount > 0 {
+			row("Restarts", strconv.Itoa(d.RestartCount))
+		}
+		if d.OOMKilled {
+			content.WriteString(lipgloss.NewStyle().Foreground(theme.Red).Bold(true).
+				Render(truncateLine("Last exit: killed for going over its memory limit", width-4)) + "\n")
+		}
+		content.WriteString("\n")
+
+		memory := "unlimited"
+		if d.Limits.Memory > 0 {
+			memory = formatSize(d.Limits.Memory)
+		}
+		if stats.MemTotal > 0 {
+			memory = fmt.Sprintf("%d MB used of %s", stats.MemUsed, memory)
+		}
+		row("Memory", memory)
+		cpus := "unlimited"
+		if d.Limits.NanoCPUs > 0 {
+			cpus = strconv.FormatFloat(d.Limits.CPUs(), 'f', -1, 64)
+		}
+		row("CPUs", cpus)
+		content.WriteString("\n")
+
+		if len(d.Cmd) > 0 {
+			row("Cmd", strings.Join(d.Cmd, " "))
+		}
+		for _, p := range sortedPorts(d.Ports) {
+			if p.Public > 0 {
+				row("Port", fmt.Sprintf("%d → %d/%s", p.Public, p.Private, p.Protocol))
+			} else {
+				row("Port", fmt.Sprintf("%d/%s, not published", p.Private, p.Protocol))
+			}
+		}
+		for _, mnt := range d.Mounts {
+			target := mnt.Source + " → " + mnt.Destination
+			if mnt.ReadOnly {
+				target += " (ro)"
+			}
+			row("Mount", target)
+		}
+		if d.NetworkID != "" {
+			row("Network", d.NetworkID)
+		}
+		content.WriteString("\n")
+
+		// The environment takes what is left above the status line.
+		if n := len(d.EnvVars); n > 0 {
+			room := max(height-2-strings.Count(content.String(), "\n")-2, 1)
+			start := min(v.envOffset, n-1)
+			end := min(start+room, n)
+			if end < n || start > 0 {
+				end = min(start+room-1, n)
+			}
+			label := "Env"
+			for _, env := range d.EnvVars[start:end] {
+				row(label, llm.SanitizeEnv(env))
+				label = ""
+			}
+			if end < n || start > 0 {
+				content.WriteString(labelStyle.Render(label) + dimStyle.Render(fmt.Sprintf("%d-%d of %d • j/k scroll", start+1, end, n)) + "\n")
+			}
+			content.WriteString("\n")
+		}
+	}
+
+	switch {
+	case v.prompt != nil:
+		label := "Memory limit "
+		if v.field == "cpus" {
+			label = "CPU limit "
+		}
+		in := *v.prompt
+		in.Width = max(width-len(label)-6, 10)
+		content.WriteString(lipgloss.NewStyle().Foreground(theme.Mauve).Bold(true).Render(label) + in.View())
+		if v.err != "" {
+			content.WriteString("\n" + lipgloss.NewStyle().Foreground(theme.Red).Render(truncateLine(v.err, width-4)))
+		}
+	case v.loading:
+		content.WriteString(lipgloss.NewStyle().Foreground(theme.Yellow).Render("Working…"))
+	case v.err != "":
+		content.WriteString(lipgloss.NewStyle().Foreground(theme.Red).Render(truncateLine(v.err, width-4)))
+	case v.status != "":
+		content.WriteString(lipgloss.NewStyle().Foreground(theme.Green).Render(truncateLine(v.status, width-4)))
+	default:
+		content.WriteString(dimStyle.Render("m memory limit • c CPU limit • Esc close"))
+	}
+
+	return panelStyle.Render(content.String())
+}
@@ -0,0 +1,138 @@
+package monitor
+
+import (
+	"fmt"
+	"strings"
+
+	"dev-cli/internal/infra"
+	"dev-cli/internal/tui/theme"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// ImageHistoryMsg asks the app for the layers of an image.
+type ImageHistoryMsg struct {
+	Image string
+}
+
+// LayerView drills down from an image into its layers, base image first,
+// with the size each one adds. enter shows the full command of the
+// selected layer.
+type LayerView struct {
+	image    string
+	analysis infra.ImageAnalysis
+	cursor   int
+	expanded bool
+	loading  bool
+	err      string
+}
+
+// Update handles a key while the view is open. Closing it (esc) is left
+// to the caller.
+func (v LayerView) Update(msg tea.KeyMsg) (LayerView, tea.Cmd) {
+	last := max(len(v.analysis.Layers)-1, 0)
+	switch msg.String() {
+	case "up", "k":
+		v.cursor = max(v.cursor-1, 0)
+	case "down", "j":
+		v.cursor = min(v.cursor+1, last)
+	case "g":
+		v.cursor = 0
+	case "G":
+		v.cursor = last
+	case "L":
+		// Jump to the layer worth looking at first.
+		v.cursor = max(v.analysis.Largest, 0)
+	case "enter":
+		v.expanded = !v.expanded
+	}
+	return v, nil
+}
+
+func (v LayerView) View(width, height int) string {
+	panelStyle := lipgloss.NewStyle().
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(theme.Mauve).
+		Width(width).
+		Height(height).
+		MaxHeight(height).
+		MaxWidth(width)
+
+	headerStyle := lipgloss.NewStyle().Foreground(theme.Lavender).Bold(true)
+	dimStyle := lipgloss.NewStyle().Foreground(theme.Overlay0)
+	textStyle := lipgloss.NewStyle().Foreground(theme.Text)
+	hintStyle := lipgloss.NewStyle().Foreground(theme.Peach)
+	selectedStyle := lipgloss.NewStyle().Background(theme.Surface1).Foreground(theme.Lavender).Bold(true).Width(width - 2)
+
+	a := v.analysis
+	var content strings.Builder
+	content.WriteString(headerStyle.Render("▦ Layers") + dimStyle.Render(" ("+v.image+")") + "\n")
+	switch {
+	case v.loading:
+		content.WriteString(lipgloss.NewStyle().Foreground(theme.Yellow).Render("Loading…") + "\n")
+	case v.err != "":
+		content.WriteString(lipgloss.NewStyle().Foreground(theme.Red).Render(truncateLine(v.err, width-4)) + "\n")
+	default:
+		content.WriteString(dimStyle.Render(fmt.Sprintf("%d layers • %s", len(a.Layers), formatSize(a.Total))) + "\n")
+	}
+	content.WriteString("\n")
+
+	// Size, share bar and command; the bar is 10 cells wide.
+	const barWidth = 10
+	cmdWidth := max(width-barWidth-20, 10)
+
+	// Header, summary, blank line, the detail of the selected layer and
+	// the footer.
+	detail := v.detail(width - 4)
+	visible := max(height-6-len(detail), 1)
+	start := max(v.cursor-visible+1, 0)
+	for i := start; i < len(a.Layers) && i < start+visible; i++ {
+		l := a.Layers[i]
+		share := a.Share(i)
+		filled := int(share*barWidth + 0.5)
+		bar := strings.Repeat("█", filled) + strings.Repeat("░", barWidth-filled)
+		line := fmt.Sprintf(" %9s %s %s", formatSize(l.Size), bar, truncateLine(l.Command(), cmdWidth))
+
+		switch {
+		case i == v.cursor:
+			content.WriteString(selectedStyle.Render(line) + "\n")
+		case a.Hints[i] != "" || i == a.Largest && share >= 0.25:
+			content.WriteString(hintStyle.Render(line) + "\n")
+		case l.Size == 0:
+			content.WriteString(dimStyle.Render(line) + "\n")
+		default:
+			content.WriteString(textStyle.Render(line) + "\n")
+		}
+	}
+
+	for _, line := range detail {
+		content.WriteString(line + "\n")
+	}
+	content.WriteString("\n")
+	content.WriteString(dimStyle.Render("Enter details • L largest • j/k move • Esc close"))
+
+	return panelStyle.Render(content.String())
+}
+
+// detail is the full command of the selected layer when expanded, and its
+// hint, if any.
+func (v LayerView) detail(width int) []string {
+	if v.cursor >= len(v.analysis.Layers) {
+		return nil
+	}
+	var lines []string
+	if hint := v.analysis.Hints[v.cursor]; hint != "" {
+		lines = append(lines, lipgloss.NewStyle().Foreground(theme.Peach).Render(truncateLine("↳ "+hint, width)))
+	}
+	if v.expanded {
+		l := v.analysis.Layers[v.cursor]
+		wrapped := lipgloss.NewStyle().Foreground(theme.Text).Width(width).Render(l.Command())
+		lines = append(lines, "")
+		lines = append(lines, strings.Split(wrapped, "\n")...)
+		if !l.Created.IsZero() {
+			lines = append(lines, lipgloss.NewStyle().Foreground(theme.Overlay0).Render("created "+l.Created.Format("2006-01-02 15:04")))
+		}
+	}
+	return lines
+}
@@ -5,9 +5,12 @@ import (
 	"io"
 	"os"
 	"path/filepath"
+	"slices"
 	"time"
 
 	"dev-cli/internal/infra"
+	"dev-cli/internal/pipeline"
+	"dev-cli/internal/tui/components"
 	"dev-cli/internal/tui/theme"
 
 	"github.com/charmbracelet/bubbles/list"
@@ -23,6 +26,9 @@ const (
 	FocusImages
 	FocusLogs
 	FocusStats
+	FocusProjects
+	FocusVolumes
+	FocusDisk
 )
 
 // Service item for bubbles/list
@@ -48,8 +54,20 @@ func (i imageItem) Title() string {
 func (i imageItem) Description() string { return formatSize(i.info.Size) }
 func (i imageItem) FilterValue() string { return i.Title() }
 
-// Custom delegate for service list
-type serviceDelegate struct{}
+// Project item for bubbles/list
+type projectItem struct {
+	info infra.ComposeProject
+}
+
+func (i projectItem) Title() string       { return i.info.Name }
+func (i projectItem) Description() string { return i.info.Status() }
+func (i projectItem) FilterValue() string { return i.info.Name }
+
+// Custom delegate for service list. merged maps the containers whose
+// logs are merged to their log colour.
+type serviceDelegate struct {
+	merged map[string]lipgloss.Color
+}
 
 func (d serviceDelegate) Height() int                             { return 1 }
 func (d serviceDelegate) Spacing() int                            { return 0 }
@@ -68,8 +86,14 @@ func (d serviceDelegate) Render(w io.Writer, m list.Model, index int, listItem l
 		statusColor = theme.Red
 	}
 
+	badge := healthBadge(i.info.Health)
+	restarts := ""
+	if i.info.RestartCount > 0 {
+		restarts = fmt.Sprintf("↻%d", i.info.RestartCount)
+	}
+
 	name := i.info.Name
-	maxWidth := m.Width() - 6
+	maxWidth := m.Width() - 6 - lipgloss.Width(badge) - len([]rune(restarts))
 	if maxWidth < 5 {
 		maxWidth = 5
 	}
@@ -79,8 +103,19 @@ func (d serviceDelegate) Render(w io.Writer, m list.Model, index int, listItem l
 
 	statusStyle := lipgloss.NewStyle().Foreground(statusColor)
 	textStyle := lipgloss.NewStyle().Foreground(theme.Text)
+	restartStyle := lipgloss.NewStyle().Foreground(theme.Peach)
 
-	line := fmt.Sprintf(" %s %s", statusStyle.Render(status), textStyle.Render(name))
+	mark := " "
+	if color, ok := d.merged[i.info.ID]; ok {
+		mark = lipgloss.NewStyle().Foreground(color).Render("▌")
+	}
+	line := fmt.Sprintf("%s%s %s", mark, statusStyle.Render(status), textStyle.Render(name))
+	if badge != "" {
+		line += " " + badge
+	}
+	if restarts != "" {
+		line += " " + restartStyle.Render(restarts)
+	}
 
 	if index == m.Index() {
 		line = lipgloss.NewStyle().
@@ -94,6 +129,23 @@ func (d serviceDelegate) Render(w io.Writer, m list.Model, index int, listItem l
 	fmt.Fprint(w, line)
 }
 
+// healthBadge renders a container's healthcheck status; containers
+// without a healthcheck get none.
+func healthBadge(health string) string {
+	var color lipgloss.Color
+	switch health {
+	case "healthy":
+		color = theme.Green
+	case "starting":
+		color = theme.Yellow
+	case "unhealthy":
+		color = theme.Red
+	default:
+		return ""
+	}
+	return lipgloss.NewStyle().Foreground(color).Render("♥")
+}
+
 // Custom delegate for image list
 type imageDelegate struct{}
 
@@ -131,6 +183,58 @@ func (d imageDelegate) Render(w io.Writer, m list.Model, index int, listItem lis
 	fmt.Fprint(w, line)
 }
 
+// Custom delegate for project list
+type projectDelegate struct{}
+
+func (d projectDelegate) Height() int                             { return 1 }
+func (d projectDelegate) Spacing() int                            { return 0 }
+func (d projectDelegate) Update(_ tea.Msg, _ *list.Model) tea.Cmd { return nil }
+
+func (d projectDelegate) Render(w io.Writer, m list.Model, index int, listItem list.Item) {
+	i, ok := listItem.(projectItem)
+	if !ok {
+		return
+	}
+
+	status := "●"
+	statusColor := theme.Green
+	switch i.info.Status() {
+	case "partial":
+		status = "◐"
+		statusColor = theme.Yellow
+	case "stopped", "down":
+		status = "○"
+		statusColor = theme.Red
+	}
+
+	count := fmt.Sprintf("%d/%d", i.info.Running, i.info.Total())
+	name := i.info.Name
+	maxWidth := m.Width() - 6 - len(count)
+	if maxWidth < 5 {
+		maxWidth = 5
+	}
+	if len(name) > maxWidth {
+		name = name[:maxWidth-1] + "…"
+	}
+
+	statusStyle := lipgloss.NewStyle().Foreground(statusColor)
+	textStyle := lipgloss.NewStyle().Foreground(theme.Text)
+	countStyle := lipgloss.NewStyle().Foreground(theme.Overlay0)
+
+	line := fmt.Sprintf(" %s %s %s", statusStyle.Render(status), textStyle.Render(name), countStyle.Render(count))
+
+	if index == m.Index() {
+		line = lipgloss.NewStyle().
+			Background(theme.Surface1).
+			Foreground(theme.Lavender).
+			Bold(true).
+			Width(m.Width()).
+			Render(line)
+	}
+
+	fmt.Fprint(w, line)
+}
+
 // Stats for display
 type ContainerStats struct {
 	CPUHistory []int
@@ -148,13 +252,68 @@ type Model struct {
 	// Lists (using bubbles/list like history sidebar)
 	servicesList list.Model
 	imagesList   list.Model
+	projectsList list.Model
 	viewport     viewport.Model
 
 	// Data
-	services       []infra.ContainerInfo
-	images         []infra.ImageInfo
+	services   []infra.ContainerInfo
+	images     []infra.ImageInfo
+	projects   []infra.ComposeProject
+	projectErr string
+	execErr    string
+	pull       *infra.PullProgress
+	// removing is the image being removed; imageErr says why the last
+	// pull or removal failed.
+	removing       string
+	imageErr       string
+	imageMenu      *ImageMenu
+	wizard         *RunWizard
 	logLines       []string
 	containerStats map[string]ContainerStats
+	// mergedLogs lists the containers whose logs are interleaved in the
+	// logs panel, in the order they were picked; empty shows the selected
+	// service alone.
+	mergedLogs []string
+	// files is the container file browser while it is open.
+	files *FileBrowser
+
+	// Volumes, with the outcome of the last backup or restore.
+	volumesList list.Model
+	volumes     []infra.VolumeInfo
+	volumeOp    string
+	volumeErr   string
+	volumeBusy  bool
+	// removeVolume asks before a volume is removed.
+	removeVolume *VolumeRemoveConfirm
+	restore      *RestorePicker
+
+	// Disk usage, with the outcome of the last prune.
+	disk       infra.DiskUsage
+	diskCursor int
+	diskOp     string
+	diskErr    string
+	diskBusy   bool
+	prune      *PruneConfirm
+
+	// inspect is the container inspector while it is open.
+	inspect *Inspector
+
+	// processes lists a container's processes while it is open.
+	processes *ProcessView
+
+	// analysis is the AI's take on the visible logs while it is shown.
+	analysis *LogAnalysisView
+
+	// bulk asks before an action on several services.
+	bulk *BulkConfirm
+	// expert skips the questions before removals, kills and prunes.
+	expert bool
+
+	// layers is the layer drill-down of an image while it is open.
+	layers *LayerView
+
+	// host is the latest host sample, nil until one arrives.
+	host *infra.HostStats
 
 	// Log recording
 	isRecording   bool
@@ -164,6 +323,19 @@ type Model struct {
 	// UI state
 	followMode     bool
 	logLevelFilter string
+	// search is the "/" search over the logs, while it is typed or kept.
+	search *logSearch
+	// wrapLogs breaks long log lines into rows instead of cutting them.
+	wrapLogs bool
+	// hideTimestamps drops the time the daemon puts before each line.
+	hideTimestamps bool
+	docker         pipeline.Availability
+	daemon         string
+	// zoomed draws the focused panel alone across the tab; see ToggleZoom.
+	zoomed bool
+	// drawerFocus is the sidebar panel the drawer goes back to; see
+	// ToggleDrawer.
+	drawerFocus FocusPanel
 }
 
 func New() Model {
@@ -186,11 +358,29 @@ func New() Model {
 	iList.DisableQuitKeybindings()
 	iList.Styles.NoItems = lipgloss.NewStyle().Foreground(theme.Overlay0).Padding(1)
 
+	pList := list.New([]list.Item{}, projectDelegate{}, 0, 0)
+	pList.SetShowHelp(false)
+	pList.SetShowTitle(false)
+	pList.SetShowStatusBar(false)
+	pList.SetShowPagination(false)
+	pList.SetFilteringEnabled(false)
+	pList.DisableQuitKeybindings()
+
+	vList := list.New([]list.Item{}, volumeDelegate{}, 0, 0)
+	vList.SetShowHelp(false)
+	vList.SetShowTitle(false)
+	vList.SetShowStatusBar(false)
+	vList.SetShowPagination(false)
+	vList.SetFilteringEnabled(false)
+	vList.DisableQuitKeybindings()
+
 	vp := viewport.New(0, 0)
 
 	return Model{
 		servicesList:   sList,
 		imagesList:     iList,
+		projectsList:   pList,
+		volumesList:    vList,
 		viewport:       vp,
 		focus:          FocusServices,
 		containerStats: make(map[string]ContainerStats),
@@ -206,7 +396,11 @@ func (m Model) SetSize(w, h int) Model {
 		sidebarWidth = 24
 	}
 
-	panelHeight := h - 4
+	panelHeight := h - 4 - m.hostHeight()
+	compact := components.Compact(w)
+	if compact {
+		sidebarWidth = w - 4
+	}
 	servicesHeight := (panelHeight - 8) / 2
 	imagesHeight := (panelHeight - 8) / 2
 	_ = 6
@@ -218,6 +412,20 @@ func (m Model) SetSize(w, h int) Model {
 		imagesHeight = 5
 	}
 
+	if ph := m.projectsHeight(); ph > 0 {
+		m.projectsList.SetWidth(sidebarWidth - 4)
+		m.projectsList.SetHeight(min(len(m.projects), maxProjectRows))
+		servicesHeight = max(servicesHeight-ph, 5)
+	}
+	if vh := m.volumesHeight(); vh > 0 {
+		m.volumesList.SetWidth(sidebarWidth - 4)
+		m.volumesList.SetHeight(min(len(m.volumes), maxVolumeRows))
+		imagesHeight = max(imagesHeight-vh, 5)
+	}
+	if dh := m.diskHeight(); dh > 0 {
+		imagesHeight = max(imagesHeight-dh, 5)
+	}
+
 	m.servicesList.SetWidth(sidebarWidth - 4)
 	m.servicesList.SetHeight(servicesHeight - 2)
 	m.imagesList.SetWidth(sidebarWidth - 4)
@@ -227,6 +435,28 @@ func (m Model) SetSize(w, h int) Model {
 	if logWidth < 40 {
 		logWidth = 40
 	}
+
+	if compact && m.zoomedPanel() == FocusLogs {
+		logWidth = w - 2
+	}
+	if m.zoomed {
+		switch m.zoomedPanel() {
+		case FocusLogs:
+			logWidth = w - 2
+		case FocusProjects:
+			m.projectsList.SetWidth(w - 8)
+			m.projectsList.SetHeight(panelHeight - 3)
+		case FocusServices:
+			m.servicesList.SetWidth(w - 8)
+			m.servicesList.SetHeight(panelHeight - 2)
+		case FocusImages:
+			m.imagesList.SetWidth(w - 8)
+			m.imagesList.SetHeight(panelHeight - 2)
+		case FocusVolumes:
+			m.volumesList.SetWidth(w - 8)
+			m.volumesList.SetHeight(panelHeight - 4)
+		}
+	}
 	m.viewport.Width = logWidth - 4
 	m.viewport.Height = panelHeight - 4
 
@@ -237,6 +467,14 @@ func (m Model) SetSize(w, h int) Model {
 func (m Model) SetServices(containers []infra.ContainerInfo) Model {
 	m.services = containers
 
+	var merged []string
+	for _, id := range m.mergedLogs {
+		if slices.ContainsFunc(containers, func(c infra.ContainerInfo) bool { return c.ID == id }) {
+			merged = append(merged, id)
+		}
+	}
+	m = m.setMergedLogs(merged)
+
 	items := make([]list.Item, len(containers))
 	for i, c := range containers {
 		items[i] = serviceItem{info: c}
@@ -246,6 +484,475 @@ func (m Model) SetServices(containers []infra.ContainerInfo) Model {
 	return m
 }
 
+// maxProjectRows caps the Projects panel so it never crowds out services.
+const maxProjectRows = 3
+
+// projectsHeight is the rendered height of the Projects panel (top border,
+// header and rows), or 0 when there are no compose projects to show.
+func (m Model) projectsHeight() int {
+	if len(m.projects) == 0 {
+		return 0
+	}
+	rows := min(len(m.projects), maxProjectRows)
+	if m.projectErr != "" {
+		rows++
+	}
+	return rows + 2
+}
+
+// SetProjects updates the compose projects list. The Projects panel is only
+// shown while there is at least one project.
+func (m Model) SetProjects(projects []infra.ComposeProject) Model {
+	m.projects = projects
+
+	items := make([]list.Item, len(projects))
+	for i, p := range projects {
+		items[i] = projectItem{info: p}
+	}
+	m.projectsList.SetItems(items)
+
+	if len(projects) == 0 && m.focus == FocusProjects {
+		m.focus = FocusServices
+	}
+	return m.SetSize(m.width, m.height)
+}
+
+// SetProjectError shows the outcome of the last compose action; nil clears it.
+func (m Model) SetProjectError(err error) Model {
+	m.projectErr = ""
+	if err != nil {
+		m.projectErr = err.Error()
+	}
+	return m.SetSize(m.width, m.height)
+}
+
+// maxVolumeRows caps the Volumes panel so it never crowds out images.
+const maxVolumeRows = 3
+
+// volumesHeight is the rendered height of the Volumes panel (top border,
+// header, rows and a status line with the outcome of the last volume
+// operation or who uses the selected volume), or 0 when there are no
+// volumes to show.
+func (m Model) volumesHeight() int {
+	if len(m.volumes) == 0 {
+		return 0
+	}
+	return min(len(m.volumes), maxVolumeRows) + 3
+}
+
+// SetVolumes updates the volumes list. The Volumes panel is only shown
+// while there is at least one volume.
+func (m Model) SetVolumes(volumes []infra.VolumeInfo) Model {
+	m.volumes = volumes
+
+	items := make([]list.Item, len(volumes))
+	for i, v := range volumes {
+		items[i] = volumeItem{info: v}
+	}
+	m.volumesList.SetItems(items)
+
+	if len(volumes) == 0 && m.focus == FocusVolumes {
+		m.focus = FocusImages
+	}
+	return m.SetSize(m.width, m.height)
+}
+
+// diskHeight is the rendered height of the Disk panel (top border, header,
+// one row per category and the outcome of the last prune), or 0 before
+// the first disk usage report.
+func (m Model) diskHeight() int {
+	if len(m.disk.Categories) == 0 {
+		return 0
+	}
+	rows := len(m.disk.Categories)
+	if m.diskOp != "" || m.diskErr != "" {
+		rows++
+	}
+	return rows + 2
+}
+
+// SetDiskUsage updates the Disk panel.
+func (m Model) SetDiskUsage(u infra.DiskUsage) Model {
+	m.disk = u
+	m.diskCursor = min(m.diskCursor, max(len(u.Categories)-1, 0))
+	if len(u.Categories) == 0 && m.focus == FocusDisk {
+		m.focus = FocusImages
+	}
+	return m.SetSize(m.width, m.height)
+}
+
+// SelectedDiskCategory returns the category under the Disk panel cursor.
+func (m Model) SelectedDiskCategory() *infra.DiskUsageCategory {
+	if m.diskCursor < len(m.disk.Categories) {
+		return &m.disk.Categories[m.diskCursor]
+	}
+	return nil
+}
+
+// PruneOpen reports whether a prune is waiting for confirmation.
+func (m Model) PruneOpen() bool { return m.prune != nil }
+
+// OpenPrune asks to confirm pruning c.
+func (m Model) OpenPrune(c infra.DiskUsageCategory) (Model, tea.Cmd) {
+	m.prune = &PruneConfirm{category: c}
+	if m.expert {
+		return m.acceptPrune()
+	}
+	return m, nil
+}
+
+// acceptPrune starts the prune waiting for confirmation.
+func (m Model) acceptPrune() (Model, tea.Cmd) {
+	kind := m.prune.category.Kind
+	m.prune = nil
+	m.diskBusy = true
+	m.diskOp = "Pruning " + string(kind) + "…"
+	m.diskErr = ""
+	return m.SetSize(m.width, m.height), func() tea.Msg { return PruneMsg{Kind: kind} }
+}
+
+func (m Model) ClosePrune() Model {
+	m.prune = nil
+	return m
+}
+
+// PruneDone shows how much a prune freed.
+func (m Model) PruneDone(reclaimed uint64, err error) Model {
+	m.diskBusy = false
+	m.diskOp = "Freed " + formatSize(int64(reclaimed))
+	m.diskErr = ""
+	if err != nil {
+		m.diskOp = ""
+		m.diskErr = err.Error()
+	}
+	return m.SetSize(m.width, m.height)
+}
+
+// RestoreOpen reports whether the restore picker has the keyboard.
+func (m Model) RestoreOpen() bool { return m.restore != nil }
+
+// OpenRestore shows the backups of volume to pick one to restore; a failed
+// listing is shown in the Volumes panel instead.
+func (m Model) OpenRestore(volume string, backups []infra.VolumeBackup, err error) Model {
+	if err != nil {
+		return m.VolumeOpDone("", err)
+	}
+	m.restore = &RestorePicker{volume: volume, backups: backups}
+	return m
+}
+
+func (m Model) CloseRestore() Model {
+	m.restore = nil
+	return m
+}
+
+// OpenVolumeRemove asks to confirm removing v.
+func (m Model) OpenVolumeRemove(v infra.VolumeInfo) (Model, tea.Cmd) {
+	m.removeVolume = &VolumeRemoveConfirm{volume: v}
+	if m.expert {
+		return m.acceptVolumeRemove()
+	}
+	return m, nil
+}
+
+// acceptVolumeRemove removes the volume waiting for confirmation. The
+// daemon refuses a volume in use anyway, so that one stays open to say so.
+func (m Model) acceptVolumeRemove() (Model, tea.Cmd) {
+	v := m.removeVolume.volume
+	if len(v.Containers) > 0 {
+		return m, nil
+	}
+	m.removeVolume = nil
+	m = m.setVolumeOp("Removing " + v.Name + "…")
+	return m, func() tea.Msg { return RemoveVolumeMsg{Volume: v.Name} }
+}
+
+func (m Model) CloseVolumeRemove() Model {
+	m.removeVolume = nil
+	return m
+}
+
+// volumesPrune is what pruning volumes frees, counted from the listed
+// volumes: every volume no container uses.
+func (m Model) volumesPrune() infra.DiskUsageCategory {
+	c := infra.DiskUsageCategory{Kind: infra.DiskVolumes}
+	for _, v := range m.volumes {
+		c.Count++
+		if len(v.Containers) > 0 {
+			c.Active++
+		}
+		if v.Size < 0 {
+			continue
+		}
+		c.Size += v.Size
+		if len(v.Containers) == 0 {
+			c.Reclaimable += v.Size
+		}
+	}
+	return c
+}
+
+// setVolumeOp shows a volume operation as in progress.
+func (m Model) setVolumeOp(status string) Model {
+	m.volumeOp = status
+	m.volumeErr = ""
+	m.volumeBusy = true
+	return m.SetSize(m.width, m.height)
+}
+
+// VolumeOpDone shows the outcome of a backup, restore or removal and
+// closes the restore picker.
+func (m Model) VolumeOpDone(status string, err error) Model {
+	m.restore = nil
+	m.volumeBusy = false
+	m.volumeOp = status
+	m.volumeErr = ""
+	if err != nil {
+		m.volumeOp = ""
+		m.volumeErr = err.Error()
+	}
+	return m.SetSize(m.width, m.height)
+}
+
+// SetExecError shows why the last exec session or container action
+// failed; nil clears it.
+func (m Model) SetExecError(err error) Model {
+	m.execErr = ""
+	if err != nil {
+		m.execErr = err.Error()
+	}
+	return m
+}
+
+// SetPullProgress shows an image pull in progress in the Images panel.
+func (m Model) SetPullProgress(p infra.PullProgress) Model {
+	m.pull = &p
+	m.imageErr = ""
+	return m
+}
+
+// FinishPull clears the pull progress, keeping err on screen if it failed.
+func (m Model) FinishPull(err error) Model {
+	m.pull = nil
+	m.imageErr = ""
+	if err != nil {
+		m.imageErr = err.Error()
+	}
+	return m
+}
+
+// ImageRemoved ends the removal of image, keeping err on screen if it
+// failed.
+func (m Model) ImageRemoved(image string, err error) Model {
+	if m.removing == image {
+		m.removing = ""
+	}
+	m.imageErr = ""
+	if err != nil {
+		m.imageErr = err.Error()
+	}
+	return m
+}
+
+// Pulling reports whether an image pull is in progress.
+func (m Model) Pulling() bool { return m.pull != nil }
+
+// WizardOpen reports whether the run wizard has the keyboard.
+func (m Model) WizardOpen() bool { return m.wizard != nil }
+
+// OpenWizard shows the run wizard, prefilled with image if it is not empty.
+func (m Model) OpenWizard(image string) Model {
+	w := NewRunWizard(image)
+	m.wizard = &w
+	return m
+}
+
+func (m Model) CloseWizard() Model {
+	m.wizard = nil
+	return m
+}
+
+// WizardFailed reopens the wizard for editing after a failed run.
+func (m Model) WizardFailed(err error) Model {
+	if m.wizard != nil {
+		w := *m.wizard
+		w.running = false
+		w.err = err.Error()
+		m.wizard = &w
+	}
+	return m
+}
+
+// FilesOpen reports whether the file browser has the keyboard.
+func (m Model) FilesOpen() bool { return m.files != nil }
+
+// OpenFiles shows the file browser for svc and lists its root.
+func (m Model) OpenFiles(svc infra.ContainerInfo) (Model, tea.Cmd) {
+	b, cmd := NewFileBrowser(svc).Open()
+	m.files = &b
+	return m, cmd
+}
+
+func (m Model) CloseFiles() Model {
+	m.files = nil
+	return m
+}
+
+// SetDirEntries shows a directory listing in the file browser, if it is
+// still open on that container.
+func (m Model) SetDirEntries(containerID, dir string, entries []infra.ContainerFile, err error) Model {
+	if m.files != nil && m.files.containerID == containerID {
+		b := m.files.SetEntries(dir, entries, err)
+		m.files = &b
+	}
+	return m
+}
+
+// FileCopied reports the outcome of a copy in the file browser.
+func (m Model) FileCopied(status string, err error) Model {
+	if m.files != nil {
+		b := m.files.Copied(status, err)
+		m.files = &b
+	}
+	return m
+}
+
+// ModalOpen reports whether a dialog over the logs panel, or the log
+// search being typed, has the keyboard, so the app's global keys must not
+// fire.
+func (m Model) ModalOpen() bool {
+	return m.SearchOpen() || m.wizard != nil || m.files != nil || m.restore != nil || m.layers != nil || m.prune != nil || m.inspect != nil || m.imageMenu != nil || m.removeVolume != nil || m.processes != nil || m.analysis != nil || m.bulk != nil
+}
+
+// ProcessesOpen reports whether the process list has the keyboard.
+func (m Model) ProcessesOpen() bool { return m.processes != nil }
+
+// ProcessesContainer is the container whose processes are shown, "" when
+// the list is closed; the app refreshes it.
+func (m Model) ProcessesContainer() string {
+	if m.processes == nil {
+		return ""
+	}
+	return m.processes.containerID
+}
+
+// OpenProcesses shows the processes of svc and asks for them.
+func (m Model) OpenProcesses(svc infra.ContainerInfo) (Model, tea.Cmd) {
+	m.processes = &ProcessView{containerID: svc.ID, container: svc.Name, loading: true, expert: m.expert}
+	id := svc.ID
+	return m, func() tea.Msg { return ProcessesMsg{ContainerID: id} }
+}
+
+func (m Model) CloseProcesses() Model {
+	m.processes = nil
+	return m
+}
+
+// SetProcesses shows a container's processes, if the list is still open
+// on that container.
+func (m Model) SetProcesses(containerID string, procs []infra.ProcessInfo, err error) Model {
+	if m.processes != nil && m.processes.containerID == containerID {
+		v := m.processes.SetProcesses(procs, err)
+		m.processes = &v
+	}
+	return m
+}
+
+// ProcessSignaled reports the outcome of a signal sent to a process of
+// containerID.
+func (m Model) ProcessSignaled(containerID, pid, signal string, err error) Model {
+	if m.processes != nil && m.processes.containerID == containerID {
+		v := m.processes.Signaled(pid, signal, err)
+		m.processes = &v
+	}
+	return m
+}
+
+// InspectOpen reports whether the inspector has the keyboard.
+func (m Model) InspectOpen() bool { return m.inspect != nil }
+
+// OpenInspect shows the inspector for svc and asks for its details.
+func (m Model) OpenInspect(svc infra.ContainerInfo) (Model, tea.Cmd) {
+	v := NewInspector(svc)
+	m.inspect = &v
+	return m, v.inspect()
+}
+
+func (m Model) CloseInspect() Model {
+	m.inspect = nil
+	return m
+}
+
+// SetInspectDetail shows a container's details in the inspector, if it is
+// still open on that container.
+func (m Model) SetInspectDetail(containerID string, detail *infra.ContainerDetail, err error) Model {
+	if m.inspect != nil && m.inspect.containerID == containerID {
+		v := m.inspect.SetDetail(detail, err)
+		m.inspect = &v
+	}
+	return m
+}
+
+// ResourcesUpdated reports the outcome of a limit change in the inspector
+// and, when it is still open, inspects the container again to show the
+// limits now in effect.
+func (m Model) ResourcesUpdated(err error) (Model, tea.Cmd) {
+	if m.inspect == nil {
+		return m, nil
+	}
+	v := m.inspect.Updated(err)
+	m.inspect = &v
+	return m, v.inspect()
+}
+
+// LayersOpen reports whether the layer view has the keyboard.
+func (m Model) LayersOpen() bool { return m.layers != nil }
+
+// OpenLayers shows the layer view of img and asks for its history.
+func (m Model) OpenLayers(img infra.ImageInfo) (Model, tea.Cmd) {
+	ref := img.ID
+	if len(img.Tags) > 0 && img.Tags[0] != "<none>:<none>" {
+		ref = img.Tags[0]
+	}
+	m.layers = &LayerView{image: ref, loading: true}
+	return m, func() tea.Msg { return ImageHistoryMsg{Image: ref} }
+}
+
+func (m Model) CloseLayers() Model {
+	m.layers = nil
+	return m
+}
+
+// SetLayers shows the history of image in the layer view, if it is still
+// open on that image.
+func (m Model) SetLayers(image string, layers []infra.ImageLayer, err error) Model {
+	if m.layers == nil || m.layers.image != image {
+		return m
+	}
+	v := *m.layers
+	v.loading = false
+	if err != nil {
+		v.err = err.Error()
+	} else {
+		v.analysis = infra.AnalyzeImage(layers)
+	}
+	m.layers = &v
+	return m
+}
+
+// SetAvailability records whether the Docker daemon answered, so the panels
+// can show a setup prompt instead of an empty list.
+func (m Model) SetAvailability(a pipeline.Availability) Model {
+	m.docker = a
+	return m
+}
+
+// SetDaemon sets the engine/endpoint label shown in the Services header.
+func (m Model) SetDaemon(label string) Model {
+	m.daemon = label
+	return m
+}
+
 // SetImages updates the images list
 func (m Model) SetImages(images []infra.ImageInfo) Model {
 	m.images = images
@@ -272,6 +979,32 @@ func (m Model) SetLogLines(lines []string) Model {
 	return m
 }
 
+// maxLogLines bounds the log buffer while following a container.
+const maxLogLines = 1000
+
+// AppendLogLines adds streamed lines to the log content, dropping the
+// oldest once the buffer is full.
+func (m Model) AppendLogLines(lines ...string) Model {
+	// Copies of the model share the backing array; clone so appending
+	// here can't overwrite lines another copy appended.
+	logLines := append(slices.Clone(m.logLines), lines...)
+	if len(logLines) > maxLogLines {
+		logLines = logLines[len(logLines)-maxLogLines:]
+	}
+	m.logLines = logLines
+
+	if m.isRecording && m.recordingFile != nil {
+		for _, line := range lines {
+			m.recordingFile.WriteString(line + "\n")
+		}
+	}
+	if m.followMode {
+		m.viewport.GotoBottom()
+	}
+
+	return m
+}
+
 // Recording methods
 func (m Model) StartRecording() Model {
 	if m.isRecording {
@@ -357,6 +1090,10 @@ func (m Model) ImagesList() list.Model          { return m.imagesList }
 func (m Model) FollowMode() bool                { return m.followMode }
 func (m Model) LogLevelFilter() string          { return m.logLevelFilter }
 
+func (m Model) Projects() []infra.ComposeProject { return m.projects }
+func (m Model) Volumes() []infra.VolumeInfo      { return m.volumes }
+func (m Model) ProjectsList() list.Model         { return m.projectsList }
+
 func (m Model) SetViewport(vp viewport.Model) Model {
 	m.viewport = vp
 	return m
@@ -376,14 +1113,82 @@ func (m Model) SetFollowMode(f bool) Model {
 	m.followMode = f
 	if f {
 		m.viewport.GotoBottom()
+		// Following goes back to the tail, off the current match.
+		if m.search != nil {
+			search := *m.search
+			search.current = -1
+			m.search = &search
+		}
 	}
 	return m
 }
 
+// SetExpertMode makes removals, kills and prunes run without asking y/n
+// first.
+func (m Model) SetExpertMode(on bool) Model {
+	m.expert = on
+	return m
+}
+
+func (m Model) ExpertMode() bool { return m.expert }
+
 func (m Model) ToggleFollowMode() Model {
 	return m.SetFollowMode(!m.followMode)
 }
 
+func (m Model) WrapLogs() bool       { return m.wrapLogs }
+func (m Model) ShowTimestamps() bool { return !m.hideTimestamps }
+
+// ToggleWrapLogs switches the logs panel between wrapping long lines and
+// cutting them at its width; the rows follow the width on resize.
+func (m Model) ToggleWrapLogs() Model {
+	m.wrapLogs = !m.wrapLogs
+	return m
+}
+
+// ToggleZoom maximizes the focused panel to the whole tab, or puts the
+// sidebar back. Tab moves the zoom along with the focus.
+func (m Model) ToggleZoom() Model {
+	m.zoomed = !m.zoomed
+	return m.SetSize(m.width, m.height)
+}
+
+// ToggleDrawer switches the compact layout between the sidebar, as a drawer
+// over the whole tab, and the logs, by moving the focus between them.
+func (m Model) ToggleDrawer() Model {
+	if !components.Compact(m.width) {
+		return m
+	}
+	if m.focus == FocusLogs {
+		m.focus = m.drawerFocus
+	} else {
+		m.drawerFocus, m.focus = m.focus, FocusLogs
+	}
+	return m.SetSize(m.width, m.height)
+}
+
+// DrawerOpen reports whether the compact layout shows the sidebar.
+func (m Model) DrawerOpen() bool {
+	return components.Compact(m.width) && m.zoomedPanel() != FocusLogs
+}
+
+// Zoomed reports whether a panel is maximized.
+func (m Model) Zoomed() bool { return m.zoomed }
+
+// zoomedPanel is the panel a zoom shows: the focused one, or the logs
+// panel while a dialog is open over it.
+func (m Model) zoomedPanel() FocusPanel {
+	if m.ModalOpen() && !m.SearchOpen() {
+		return FocusLogs
+	}
+	return m.focus
+}
+
+func (m Model) ToggleTimestamps() Model {
+	m.hideTimestamps = !m.hideTimestamps
+	return m
+}
+
 func (m Model) SetLogLevelFilter(level string) Model {
 	m.logLevelFilter = level
 	return m
@@ -403,6 +1208,53 @@ func (m Model) CycleLogLevelFilter() Model {
 	return m
 }
 
+// logColors tell merged containers apart, in pick order.
+func logColors() []lipgloss.Color {
+	return []lipgloss.Color{theme.Blue, theme.Green, theme.Peach, theme.Mauve, theme.Teal, theme.Yellow, theme.Pink, theme.Lavender}
+}
+
+func (m Model) setMergedLogs(ids []string) Model {
+	m.mergedLogs = ids
+	palette := logColors()
+	colors := make(map[string]lipgloss.Color, len(ids))
+	for i, id := range ids {
+		colors[id] = palette[i%len(palette)]
+	}
+	m.servicesList.SetDelegate(serviceDelegate{merged: colors})
+	return m
+}
+
+// toggleMergedLog adds a container to the merged logs, or takes it out.
+func (m Model) toggleMergedLog(id string) Model {
+	if i := slices.Index(m.mergedLogs, id); i >= 0 {
+		return m.setMergedLogs(slices.Delete(slices.Clone(m.mergedLogs), i, i+1))
+	}
+	return m.setMergedLogs(append(slices.Clone(m.mergedLogs), id))
+}
+
+// MergedLogs reports whether the logs panel interleaves several containers.
+func (m Model) MergedLogs() bool { return len(m.mergedLogs) > 0 }
+
+// LogTargets returns the containers whose logs belong in the logs panel:
+// the merged ones in pick order, or else the selected service.
+func (m Model) LogTargets() []infra.ContainerInfo {
+	if len(m.mergedLogs) == 0 {
+		if svc := m.SelectedService(); svc != nil {
+			return []infra.ContainerInfo{*svc}
+		}
+		return nil
+	}
+	var targets []infra.ContainerInfo
+	for _, id := range m.mergedLogs {
+		for _, c := range m.services {
+			if c.ID == id {
+				targets = append(targets, c)
+			}
+		}
+	}
+	return targets
+}
+
 // Selected items
 func (m Model) SelectedService() *infra.ContainerInfo {
 	if sel := m.servicesList.SelectedItem(); sel != nil {
@@ -413,6 +1265,22 @@ func (m Model) SelectedService() *infra.ContainerInfo {
 	return nil
 }
 
+func (m Model) SelectedVolume() *infra.VolumeInfo {
+	if item, ok := m.volumesList.SelectedItem().(volumeItem); ok {
+		return &item.info
+	}
+	return nil
+}
+
+func (m Model) SelectedProject() *infra.ComposeProject {
+	if sel := m.projectsList.SelectedItem(); sel != nil {
+		if p, ok := sel.(projectItem); ok {
+			return &p.info
+		}
+	}
+	return nil
+}
+
 func (m Model) SelectedImage() *infra.ImageInfo {
 	if sel := m.imagesList.SelectedItem(); sel != nil {
 		if i, ok := sel.(imageItem); ok {
@@ -435,6 +1303,26 @@ func (m Model) SetContainerStats(name string, stats ContainerStats) Model {
 	return m
 }
 
+// statsHistoryLen bounds the CPU sparkline history kept per container.
+const statsHistoryLen = 30
+
+// AddStatsSample folds one streamed stats snapshot into the container's
+// history, so the sparkline advances with every frame.
+func (m Model) AddStatsSample(name string, snap infra.ContainerStatsSnapshot) Model {
+	stats := m.containerStats[name]
+	history := append(stats.CPUHistory, int(snap.CPUPercent+0.5))
+	if len(history) > statsHistoryLen {
+		history = history[len(history)-statsHistoryLen:]
+	}
+	return m.SetContainerStats(name, ContainerStats{
+		CPUHistory: history,
+		MemUsed:    int(snap.MemUsed >> 20),
+		MemTotal:   int(snap.MemLimit >> 20),
+		NetIn:      int64(snap.NetRx),
+		NetOut:     int64(snap.NetTx),
+	})
+}
+
 func (m Model) GetSelectedServiceStats() ContainerStats {
 	if svc := m.SelectedService(); svc != nil {
 		if stats, ok := m.containerStats[svc.Name]; ok {
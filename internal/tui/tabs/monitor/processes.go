@@ -0,0 +1,213 @@
+package monitor
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"dev-cli/internal/infra"
+	"dev-cli/internal/tui/components"
+	"dev-cli/internal/tui/theme"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// ProcessesMsg asks the app for the processes of a container.
+type ProcessesMsg struct {
+	ContainerID string
+}
+
+// SignalProcessMsg asks the app to send Signal (infra.SignalTerm or
+// infra.SignalKill) to a process of a container.
+type SignalProcessMsg struct {
+	ContainerID string
+	PID         string
+	Signal      string
+}
+
+// ProcessView lists the processes of a container, like `docker top`,
+// refreshed by the app while it is open. x sends SIGTERM to the selected
+// process and X SIGKILL, after a confirmation unless in expert mode.
+type ProcessView struct {
+	containerID string
+	container   string
+
+	procs   []infra.ProcessInfo
+	loading bool
+	err     string
+	status  string
+	cursor  int
+	// confirm is the signal waiting for y.
+	confirm string
+	expert  bool
+}
+
+// SetProcesses shows a fresh listing, keeping the cursor on the same
+// process while it runs; a failed refresh keeps the last one on screen.
+func (v ProcessView) SetProcesses(procs []infra.ProcessInfo, err error) ProcessView {
+	v.loading = false
+	if err != nil {
+		v.err = err.Error()
+		return v
+	}
+	v.err = ""
+	if p, ok := v.selected(); ok {
+		if i := slices.IndexFunc(procs, func(q infra.ProcessInfo) bool { return q.PID == p.PID }); i >= 0 {
+			v.cursor = i
+		}
+	}
+	v.procs = procs
+	v.cursor = min(v.cursor, max(len(procs)-1, 0))
+	return v
+}
+
+// Signaled reports the outcome of a signal sent to pid.
+func (v ProcessView) Signaled(pid, signal string, err error) ProcessView {
+	v.status, v.err = "", ""
+	if err != nil {
+		v.err = err.Error()
+		return v
+	}
+	v.status = "Sent SIG" + signal + " to " + pid
+	return v
+}
+
+func (v ProcessView) selected() (infra.ProcessInfo, bool) {
+	if v.cursor < len(v.procs) {
+		return v.procs[v.cursor], true
+	}
+	return infra.ProcessInfo{}, false
+}
+
+// Confirming reports whether a signal waits for y, so esc cancels it
+// instead of closing the view.
+func (v ProcessView) Confirming() bool { return v.confirm != "" }
+
+// Update handles a key while the view is open. Closing it (esc) is left
+// to the caller.
+func (v ProcessView) Update(msg tea.KeyMsg) (ProcessView, tea.Cmd) {
+	if v.confirm != "" {
+		switch v.confirmation().Answer(msg.String()) {
+		case components.ConfirmYes:
+			return v.send()
+		case components.ConfirmNo:
+			v.confirm = ""
+		}
+		return v, nil
+	}
+
+	last := max(len(v.procs)-1, 0)
+	switch msg.String() {
+	case "up", "k":
+		v.cursor = max(v.cursor-1, 0)
+	case "down", "j":
+		v.cursor = min(v.cursor+1, last)
+	case "g":
+		v.cursor = 0
+	case "G":
+		v.cursor = last
+	case "x":
+		if _, ok := v.selected(); ok {
+			v.confirm = infra.SignalTerm
+		}
+	case "X":
+		if _, ok := v.selected(); ok {
+			v.confirm = infra.SignalKill
+		}
+	}
+	if v.confirm != "" && v.expert {
+		return v.send()
+	}
+	return v, nil
+}
+
+// send sends the signal waiting for y to the selected process.
+func (v ProcessView) send() (ProcessView, tea.Cmd) {
+	p, ok := v.selected()
+	signal := v.confirm
+	v.confirm = ""
+	if !ok {
+		return v, nil
+	}
+	v.status, v.err = "", ""
+	msg := SignalProcessMsg{ContainerID: v.containerID, PID: p.PID, Signal: signal}
+	return v, func() tea.Msg { return msg }
+}
+
+func (v ProcessView) confirmation() components.Confirm {
+	p, _ := v.selected()
+	text := "SIGTERM asks the process to exit; it may clean up first, or ignore it."
+	if v.confirm == infra.SignalKill {
+		text = "SIGKILL ends the process at once, without a chance to clean up. Killing PID 1 stops the container."
+	}
+	return components.Confirm{
+		Title:  fmt.Sprintf("Send SIG%s to %s", v.confirm, p.PID),
+		Items:  []string{p.Command},
+		Text:   []string{text},
+		Verb:   "send",
+		Danger: v.confirm == infra.SignalKill,
+	}
+}
+
+func (v ProcessView) View(width, height int) string {
+	if v.confirm != "" {
+		return v.confirmation().View(width, height)
+	}
+	panelStyle := lipgloss.NewStyle().
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(theme.Mauve).
+		Width(width).
+		Height(height).
+		MaxHeight(height).
+		MaxWidth(width)
+
+	headerStyle := lipgloss.NewStyle().Foreground(theme.Lavender).Bold(true)
+	dimStyle := lipgloss.NewStyle().Foreground(theme.Overlay0)
+	textStyle := lipgloss.NewStyle().Foreground(theme.Text)
+	selectedStyle := lipgloss.NewStyle().Background(theme.Surface1).Foreground(theme.Lavender).Bold(true).Width(width - 2)
+
+	var content strings.Builder
+	content.WriteString(headerStyle.Render("⚙ Processes") + dimStyle.Render(" ("+v.container+")") + "\n")
+	switch {
+	case v.loading:
+		content.WriteString(lipgloss.NewStyle().Foreground(theme.Yellow).Render("Loading…") + "\n")
+	default:
+		count := fmt.Sprintf("%d processes", len(v.procs))
+		if len(v.procs) == 1 {
+			count = "1 process"
+		}
+		content.WriteString(dimStyle.Render(count+" • host PIDs, refreshed every few seconds") + "\n")
+	}
+	content.WriteString("\n")
+
+	row := func(pid, user, cpu, mem, cmd string) string {
+		line := fmt.Sprintf(" %-7s %-9s %5s %5s  %s", pid, truncateLine(user, 9), cpu, mem, cmd)
+		return truncateLine(line, width-2)
+	}
+	content.WriteString(dimStyle.Render(row("PID", "USER", "CPU%", "MEM%", "COMMAND")) + "\n")
+
+	visible := max(height-8, 1)
+	start := max(v.cursor-visible+1, 0)
+	for i := start; i < len(v.procs) && i < start+visible; i++ {
+		p := v.procs[i]
+		line := row(p.PID, p.User, p.CPU, p.Memory, p.Command)
+		if i == v.cursor {
+			content.WriteString(selectedStyle.Render(line) + "\n")
+		} else {
+			content.WriteString(textStyle.Render(line) + "\n")
+		}
+	}
+	content.WriteString("\n")
+
+	switch {
+	case v.err != "":
+		content.WriteString(lipgloss.NewStyle().Foreground(theme.Red).Render(truncateLine(v.err, width-4)))
+	case v.status != "":
+		content.WriteString(lipgloss.NewStyle().Foreground(theme.Green).Render(truncateLine(v.status, width-4)))
+	default:
+		content.WriteString(dimStyle.Render("x SIGTERM • X SIGKILL • j/k move • Esc close"))
+	}
+
+	return panelStyle.Render(content.String())
+}
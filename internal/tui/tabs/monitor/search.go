@@ -0,0 +1,195 @@
+package monitor
+
+import (
+	"fmt"
+	"regexp"
+
+	"dev-cli/internal/tui/theme"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
+)
+
+// logSearch is the "/" search over the logs panel. The query is a regular
+// expression, ignoring case; one that doesn't compile yet, like "foo(" while
+// it is typed, matches as plain text. After Enter, n and N move between the
+// matches on the logs panel.
+type logSearch struct {
+	input   textinput.Model
+	editing bool
+	pattern *regexp.Regexp
+	// literal is set when the query is not a valid regular expression.
+	literal bool
+	// current is the match n/N last moved to, counted from the first; -1
+	// keeps the logs at their tail.
+	current int
+}
+
+// logMatch is one hit, in cells of a log line's message.
+type logMatch struct {
+	line, start, end int
+}
+
+func newLogSearch() *logSearch {
+	ti := textinput.New()
+	ti.Prompt = "/"
+	ti.PromptStyle = lipgloss.NewStyle().Foreground(theme.Peach).Bold(true)
+	ti.Placeholder = "search logs (regex)"
+	ti.Focus()
+	return &logSearch{input: ti, editing: true, current: -1}
+}
+
+func (s *logSearch) setQuery(query string) {
+	s.pattern, s.literal = nil, false
+	s.current = -1
+	if query == "" {
+		return
+	}
+	pattern, err := regexp.Compile("(?i)" + query)
+	if err != nil {
+		pattern, s.literal = regexp.MustCompile("(?i)"+regexp.QuoteMeta(query)), true
+	}
+	s.pattern = pattern
+}
+
+// find lists the hits in one line, skipping empty matches like those of "a*".
+func (s *logSearch) find(i int, line string) []logMatch {
+	if s == nil || s.pattern == nil {
+		return nil
+	}
+	var out []logMatch
+	plain := ansi.Strip(line)
+	for _, loc := range s.pattern.FindAllStringIndex(plain, -1) {
+		if loc[0] == loc[1] {
+			continue
+		}
+		start := ansi.StringWidth(plain[:loc[0]])
+		out = append(out, logMatch{line: i, start: start, end: start + ansi.StringWidth(plain[loc[0]:loc[1]])})
+	}
+	return out
+}
+
+// highlight marks the hits in a rendered line, current among them apart
+// from the rest, keeping the styles around them.
+func highlight(line string, hits []logMatch, current int) string {
+	hitStyle := lipgloss.NewStyle().Background(theme.Yellow).Foreground(theme.Crust)
+	currentStyle := lipgloss.NewStyle().Background(theme.Peach).Foreground(theme.Crust).Bold(true)
+
+	width := ansi.StringWidth(line)
+	// Going backwards keeps the cell offsets of earlier hits valid.
+	for i := len(hits) - 1; i >= 0; i-- {
+		hit := hits[i]
+		if hit.start >= width {
+			continue
+		}
+		end := min(hit.end, width)
+		style := hitStyle
+		if i == current {
+			style = currentStyle
+		}
+		text := ansi.Strip(ansi.Cut(line, hit.start, end))
+		line = ansi.Cut(line, 0, hit.start) + style.Render(text) + ansi.Cut(line, end, width)
+	}
+	return line
+}
+
+// status counts the matches, and says which one is current.
+func (s *logSearch) status(total int) string {
+	status := fmt.Sprintf("%d matches", total)
+	switch {
+	case total == 0:
+		status = "no match"
+	case total == 1:
+		status = "1 match"
+	}
+	if s.current >= 0 && s.current < total {
+		status = fmt.Sprintf("%d/%d", s.current+1, total)
+	}
+	if s.literal {
+		status += " (not a regex, matching text)"
+	}
+	return status
+}
+
+// SearchOpen reports whether the "/" query is being typed.
+func (m Model) SearchOpen() bool { return m.search != nil && m.search.editing }
+
+// LogSearch is the kept or typed query, "" without one.
+func (m Model) LogSearch() string {
+	if m.search == nil {
+		return ""
+	}
+	return m.search.input.Value()
+}
+
+// openSearch starts a new "/" query over the logs panel.
+func (m Model) openSearch() (Model, tea.Cmd) {
+	m.search = newLogSearch()
+	m.focus = FocusLogs
+	return m, textinput.Blink
+}
+
+// updateSearch handles a key while the query is typed: Enter keeps the
+// matches to step through, and Esc drops them.
+func (m Model) updateSearch(msg tea.KeyMsg) (Model, tea.Cmd) {
+	switch msg.String() {
+	case "esc":
+		m.search = nil
+		return m, nil
+	case "enter":
+		if m.search.pattern == nil {
+			m.search = nil
+			return m, nil
+		}
+		search := *m.search
+		search.editing = false
+		search.input.Blur()
+		m.search = &search
+		return m, nil
+	}
+
+	search := *m.search
+	var cmd tea.Cmd
+	search.input, cmd = search.input.Update(msg)
+	search.setQuery(search.input.Value())
+	m.search = &search
+	return m, cmd
+}
+
+// searchMatches lists the hits of the query in the lines the level filter
+// lets through, top to bottom.
+func (m Model) searchMatches(lines []string) []logMatch {
+	if m.search == nil || m.search.pattern == nil {
+		return nil
+	}
+	prefix := m.mergedLogPrefixer()
+	var out []logMatch
+	for i, line := range lines {
+		_, text := m.splitLogLine(line, prefix)
+		out = append(out, m.search.find(i, text)...)
+	}
+	return out
+}
+
+// jumpToMatch makes the next match current (step 1, wrapping) or the
+// previous one (-1), and stops following so it stays on screen. Before
+// the first jump, -1 goes to the newest match.
+func (m Model) jumpToMatch(step int) Model {
+	matches := m.searchMatches(m.filterLogLines())
+	if len(matches) == 0 {
+		return m
+	}
+	search := *m.search
+	if search.current < 0 {
+		search.current = len(matches)
+		if step > 0 {
+			search.current = -1
+		}
+	}
+	search.current = (search.current + step + len(matches)) % len(matches)
+	m.search = &search
+	m.followMode = false
+	return m
+}
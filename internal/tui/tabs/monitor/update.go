@@ -1,22 +1,45 @@
 package monitor
 
 import (
+	"slices"
+
+	"dev-cli/internal/infra"
+	"dev-cli/internal/tui/components"
+
 	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/textinput"
 	tea "github.com/charmbracelet/bubbletea"
 )
 
 type KeyMap struct {
-	Up       key.Binding
-	Down     key.Binding
-	Tab      key.Binding
-	Follow   key.Binding
-	LogLevel key.Binding
-	Record   key.Binding
-	Start    key.Binding
-	Stop     key.Binding
-	Restart  key.Binding
-	Top      key.Binding
-	Bottom   key.Binding
+	Up        key.Binding
+	Down      key.Binding
+	Tab       key.Binding
+	Follow    key.Binding
+	LogLevel  key.Binding
+	Record    key.Binding
+	Start     key.Binding
+	Stop      key.Binding
+	Restart   key.Binding
+	Exec      key.Binding
+	Pull      key.Binding
+	Run       key.Binding
+	Merge     key.Binding
+	Unmerge   key.Binding
+	Files     key.Binding
+	Layers    key.Binding
+	Prune     key.Binding
+	Inspect   key.Binding
+	Actions   key.Binding
+	Remove    key.Binding
+	Processes key.Binding
+	Search    key.Binding
+	Wrap      key.Binding
+	Timestamp key.Binding
+	Top       key.Binding
+	Bottom    key.Binding
+	Zoom      key.Binding
+	Drawer    key.Binding
 }
 
 func DefaultKeyMap() KeyMap {
@@ -57,6 +80,66 @@ func DefaultKeyMap() KeyMap {
 			key.WithKeys("r"),
 			key.WithHelp("r", "restart"),
 		),
+		Exec: key.NewBinding(
+			key.WithKeys("e"),
+			key.WithHelp("e", "exec"),
+		),
+		Pull: key.NewBinding(
+			key.WithKeys("P"),
+			key.WithHelp("P", "pull"),
+		),
+		Run: key.NewBinding(
+			key.WithKeys("n"),
+			key.WithHelp("n", "run"),
+		),
+		Merge: key.NewBinding(
+			key.WithKeys(" "),
+			key.WithHelp("space", "mark/merge logs"),
+		),
+		Unmerge: key.NewBinding(
+			key.WithKeys("esc"),
+			key.WithHelp("esc", "unmerge"),
+		),
+		Files: key.NewBinding(
+			key.WithKeys("b"),
+			key.WithHelp("b", "browse files"),
+		),
+		Layers: key.NewBinding(
+			key.WithKeys("enter"),
+			key.WithHelp("enter", "image layers"),
+		),
+		Prune: key.NewBinding(
+			key.WithKeys("p"),
+			key.WithHelp("p", "prune"),
+		),
+		Inspect: key.NewBinding(
+			key.WithKeys("i"),
+			key.WithHelp("i", "inspect"),
+		),
+		Actions: key.NewBinding(
+			key.WithKeys("a"),
+			key.WithHelp("a", "image actions/analyze logs"),
+		),
+		Remove: key.NewBinding(
+			key.WithKeys("d"),
+			key.WithHelp("d", "remove"),
+		),
+		Processes: key.NewBinding(
+			key.WithKeys("t"),
+			key.WithHelp("t", "processes"),
+		),
+		Search: key.NewBinding(
+			key.WithKeys("/"),
+			key.WithHelp("/", "search logs"),
+		),
+		Wrap: key.NewBinding(
+			key.WithKeys("ctrl+w"),
+			key.WithHelp("Ctrl+w", "wrap"),
+		),
+		Timestamp: key.NewBinding(
+			key.WithKeys("ctrl+t"),
+			key.WithHelp("Ctrl+t", "timestamps"),
+		),
 		Top: key.NewBinding(
 			key.WithKeys("g"),
 			key.WithHelp("g/G", "top/bottom"),
@@ -65,6 +148,14 @@ func DefaultKeyMap() KeyMap {
 			key.WithKeys("G"),
 			key.WithHelp("", ""),
 		),
+		Zoom: key.NewBinding(
+			key.WithKeys("Z"),
+			key.WithHelp("Z", "zoom panel"),
+		),
+		Drawer: key.NewBinding(
+			key.WithKeys("S"),
+			key.WithHelp("S", "sidebar (narrow)"),
+		),
 	}
 }
 
@@ -76,30 +167,180 @@ type ContainerActionMsg struct {
 	Error       error
 }
 
+// ExecContainerMsg asks the app to suspend and open a shell in a container.
+type ExecContainerMsg struct {
+	ContainerID string
+}
+
+// PullImageMsg asks the app to pull an image, e.g. to update a tag.
+type PullImageMsg struct {
+	Ref string
+}
+
+// MergeLogsMsg tells the app that the set of merged containers changed;
+// see Model.LogTargets.
+type MergeLogsMsg struct{}
+
+// ProjectActionMsg asks the app to bring a compose project "up" or "down".
+type ProjectActionMsg struct {
+	Action  string
+	Project string
+}
+
 type RefreshContainersMsg struct{}
 type RefreshImagesMsg struct{}
 
 func (m Model) Update(msg tea.Msg, keys KeyMap) (Model, tea.Cmd) {
 	var cmds []tea.Cmd
 
+	if km, ok := msg.(tea.KeyMsg); ok && m.SearchOpen() {
+		return m.updateSearch(km)
+	}
+	// A kept search takes n/N and Esc on the logs panel.
+	if km, ok := msg.(tea.KeyMsg); ok && m.search != nil && m.focus == FocusLogs && !m.ModalOpen() {
+		switch km.String() {
+		case "n":
+			return m.jumpToMatch(1), nil
+		case "N":
+			return m.jumpToMatch(-1), nil
+		case "esc":
+			m.search = nil
+			return m, nil
+		}
+	}
+	if km, ok := msg.(tea.KeyMsg); ok && m.wizard != nil {
+		if km.String() == "esc" {
+			return m.CloseWizard(), nil
+		}
+		w, cmd := m.wizard.Update(km)
+		m.wizard = &w
+		return m, cmd
+	}
+	if km, ok := msg.(tea.KeyMsg); ok && m.restore != nil {
+		if km.String() == "esc" {
+			return m.CloseRestore(), nil
+		}
+		p, cmd := m.restore.Update(km)
+		m.restore = &p
+		if cmd != nil {
+			m.restore = nil
+			m = m.setVolumeOp("Restoring " + p.volume + "…")
+		}
+		return m, cmd
+	}
+	if km, ok := msg.(tea.KeyMsg); ok && m.prune != nil {
+		switch m.prune.confirm().Answer(km.String()) {
+		case components.ConfirmYes:
+			return m.acceptPrune()
+		case components.ConfirmNo:
+			return m.ClosePrune(), nil
+		}
+		return m, nil
+	}
+	if km, ok := msg.(tea.KeyMsg); ok && m.removeVolume != nil {
+		switch m.removeVolume.confirm().Answer(km.String()) {
+		case components.ConfirmYes:
+			return m.acceptVolumeRemove()
+		case components.ConfirmNo:
+			return m.CloseVolumeRemove(), nil
+		}
+		return m, nil
+	}
+	if km, ok := msg.(tea.KeyMsg); ok && m.bulk != nil {
+		switch m.bulk.confirm().Answer(km.String()) {
+		case components.ConfirmYes:
+			return m.acceptBulk()
+		case components.ConfirmNo:
+			return m.CloseBulk(), nil
+		}
+		return m, nil
+	}
+	if km, ok := msg.(tea.KeyMsg); ok && m.imageMenu != nil {
+		return m.updateImageMenu(km)
+	}
+	if km, ok := msg.(tea.KeyMsg); ok && m.layers != nil {
+		if km.String() == "esc" {
+			return m.CloseLayers(), nil
+		}
+		v, cmd := m.layers.Update(km)
+		m.layers = &v
+		return m, cmd
+	}
+	if km, ok := msg.(tea.KeyMsg); ok && m.processes != nil {
+		if km.String() == "esc" && !m.processes.Confirming() {
+			return m.CloseProcesses(), nil
+		}
+		v, cmd := m.processes.Update(km)
+		m.processes = &v
+		return m, cmd
+	}
+	if km, ok := msg.(tea.KeyMsg); ok && m.analysis != nil {
+		if km.String() == "esc" {
+			return m.CloseAnalysis(), nil
+		}
+		v, cmd := m.analysis.Update(km)
+		m.analysis = &v
+		return m, cmd
+	}
+	if km, ok := msg.(tea.KeyMsg); ok && m.inspect != nil {
+		if km.String() == "esc" && !m.inspect.Prompting() {
+			return m.CloseInspect(), nil
+		}
+		v, cmd := m.inspect.Update(km)
+		m.inspect = &v
+		return m, cmd
+	}
+	if km, ok := msg.(tea.KeyMsg); ok && m.files != nil {
+		if km.String() == "esc" && !m.files.Prompting() {
+			return m.CloseFiles(), nil
+		}
+		b, cmd := m.files.Update(km)
+		m.files = &b
+		return m, cmd
+	}
+
 	switch msg := msg.(type) {
 	case tea.KeyMsg:
 		switch {
 		case key.Matches(msg, keys.Tab):
 
 			switch m.focus {
+			case FocusProjects:
+				m.focus = FocusServices
 			case FocusServices:
 				m.focus = FocusLogs
 			case FocusLogs:
 				m.focus = FocusImages
 			case FocusImages:
 				m.focus = FocusStats
+				if len(m.volumes) > 0 {
+					m.focus = FocusVolumes
+				} else if len(m.disk.Categories) > 0 {
+					m.focus = FocusDisk
+				}
+			case FocusVolumes:
+				m.focus = FocusStats
+				if len(m.disk.Categories) > 0 {
+					m.focus = FocusDisk
+				}
+			case FocusDisk:
+				m.focus = FocusStats
 			case FocusStats:
 				m.focus = FocusServices
+				if len(m.projects) > 0 {
+					m.focus = FocusProjects
+				}
+			}
+			if m.zoomed {
+				m = m.SetSize(m.width, m.height)
 			}
 
 		case key.Matches(msg, keys.Up):
 			switch m.focus {
+			case FocusProjects:
+				var cmd tea.Cmd
+				m.projectsList, cmd = m.projectsList.Update(msg)
+				cmds = append(cmds, cmd)
 			case FocusServices:
 				var cmd tea.Cmd
 				m.servicesList, cmd = m.servicesList.Update(msg)
@@ -108,6 +349,12 @@ func (m Model) Update(msg tea.Msg, keys KeyMap) (Model, tea.Cmd) {
 				var cmd tea.Cmd
 				m.imagesList, cmd = m.imagesList.Update(msg)
 				cmds = append(cmds, cmd)
+			case FocusVolumes:
+				var cmd tea.Cmd
+				m.volumesList, cmd = m.volumesList.Update(msg)
+				cmds = append(cmds, cmd)
+			case FocusDisk:
+				m.diskCursor = max(m.diskCursor-1, 0)
 			case FocusLogs:
 				m.viewport.ScrollUp(1)
 				m.followMode = false
@@ -115,6 +362,10 @@ func (m Model) Update(msg tea.Msg, keys KeyMap) (Model, tea.Cmd) {
 
 		case key.Matches(msg, keys.Down):
 			switch m.focus {
+			case FocusProjects:
+				var cmd tea.Cmd
+				m.projectsList, cmd = m.projectsList.Update(msg)
+				cmds = append(cmds, cmd)
 			case FocusServices:
 				var cmd tea.Cmd
 				m.servicesList, cmd = m.servicesList.Update(msg)
@@ -123,6 +374,12 @@ func (m Model) Update(msg tea.Msg, keys KeyMap) (Model, tea.Cmd) {
 				var cmd tea.Cmd
 				m.imagesList, cmd = m.imagesList.Update(msg)
 				cmds = append(cmds, cmd)
+			case FocusVolumes:
+				var cmd tea.Cmd
+				m.volumesList, cmd = m.volumesList.Update(msg)
+				cmds = append(cmds, cmd)
+			case FocusDisk:
+				m.diskCursor = min(m.diskCursor+1, max(len(m.disk.Categories)-1, 0))
 			case FocusLogs:
 				m.viewport.ScrollDown(1)
 			}
@@ -133,6 +390,21 @@ func (m Model) Update(msg tea.Msg, keys KeyMap) (Model, tea.Cmd) {
 		case key.Matches(msg, keys.LogLevel):
 			m = m.CycleLogLevelFilter()
 
+		case key.Matches(msg, keys.Search):
+			return m.openSearch()
+
+		case key.Matches(msg, keys.Wrap):
+			m = m.ToggleWrapLogs()
+
+		case key.Matches(msg, keys.Timestamp):
+			m = m.ToggleTimestamps()
+
+		case key.Matches(msg, keys.Zoom):
+			m = m.ToggleZoom()
+
+		case key.Matches(msg, keys.Drawer):
+			m = m.ToggleDrawer()
+
 		case key.Matches(msg, keys.Record):
 			m = m.ToggleRecording()
 
@@ -144,6 +416,12 @@ func (m Model) Update(msg tea.Msg, keys KeyMap) (Model, tea.Cmd) {
 				m.servicesList.Select(0)
 			case FocusImages:
 				m.imagesList.Select(0)
+			case FocusProjects:
+				m.projectsList.Select(0)
+			case FocusVolumes:
+				m.volumesList.Select(0)
+			case FocusDisk:
+				m.diskCursor = 0
 			}
 
 		case key.Matches(msg, keys.Bottom):
@@ -158,9 +436,26 @@ func (m Model) Update(msg tea.Msg, keys KeyMap) (Model, tea.Cmd) {
 				if len(m.images) > 0 {
 					m.imagesList.Select(len(m.images) - 1)
 				}
+			case FocusProjects:
+				if len(m.projects) > 0 {
+					m.projectsList.Select(len(m.projects) - 1)
+				}
+			case FocusVolumes:
+				if len(m.volumes) > 0 {
+					m.volumesList.Select(len(m.volumes) - 1)
+				}
+			case FocusDisk:
+				m.diskCursor = max(len(m.disk.Categories)-1, 0)
 			}
 
 		case key.Matches(msg, keys.Start):
+			if m.focus == FocusProjects {
+				if p := m.SelectedProject(); p != nil {
+					return m, func() tea.Msg {
+						return ProjectActionMsg{Action: "up", Project: p.Name}
+					}
+				}
+			}
 
 			if m.focus == FocusServices {
 				if svc := m.SelectedService(); svc != nil {
@@ -174,7 +469,18 @@ func (m Model) Update(msg tea.Msg, keys KeyMap) (Model, tea.Cmd) {
 			}
 
 		case key.Matches(msg, keys.Stop):
+			if m.focus == FocusProjects {
+				if p := m.SelectedProject(); p != nil {
+					return m, func() tea.Msg {
+						return ProjectActionMsg{Action: "down", Project: p.Name}
+					}
+				}
+			}
+
 			if m.focus == FocusServices {
+				if marked := m.Marked(); len(marked) > 0 {
+					return m.OpenBulk("stop", marked)
+				}
 				if svc := m.SelectedService(); svc != nil {
 					return m, func() tea.Msg {
 						return ContainerActionMsg{
@@ -185,8 +491,151 @@ func (m Model) Update(msg tea.Msg, keys KeyMap) (Model, tea.Cmd) {
 				}
 			}
 
+		case key.Matches(msg, keys.Exec):
+			if m.focus == FocusServices {
+				if svc := m.SelectedService(); svc != nil && svc.State == "running" {
+					return m, func() tea.Msg {
+						return ExecContainerMsg{ContainerID: svc.ID}
+					}
+				}
+			}
+
+		case key.Matches(msg, keys.Inspect):
+			if m.focus == FocusServices {
+				if svc := m.SelectedService(); svc != nil {
+					return m.OpenInspect(*svc)
+				}
+			}
+
+		case key.Matches(msg, keys.Processes):
+			if m.focus == FocusServices {
+				if svc := m.SelectedService(); svc != nil && svc.State == "running" {
+					return m.OpenProcesses(*svc)
+				}
+			}
+
+		case key.Matches(msg, keys.Files):
+			if m.focus == FocusServices {
+				if svc := m.SelectedService(); svc != nil && svc.State == "running" {
+					return m.OpenFiles(*svc)
+				}
+			}
+
+			// On a volume, b backs it up.
+			if m.focus == FocusVolumes && !m.volumeBusy {
+				if v := m.SelectedVolume(); v != nil {
+					m = m.setVolumeOp("Backing up " + v.Name + "…")
+					return m, func() tea.Msg { return BackupVolumeMsg{Volume: v.Name} }
+				}
+			}
+
+		case key.Matches(msg, keys.Pull):
+			if m.focus == FocusImages && m.pull == nil {
+				if img := m.SelectedImage(); img != nil && len(img.Tags) > 0 && img.Tags[0] != "<none>:<none>" {
+					return m, func() tea.Msg {
+						return PullImageMsg{Ref: img.Tags[0]}
+					}
+				}
+			}
+
+		case key.Matches(msg, keys.Prune):
+			if m.focus == FocusDisk && !m.diskBusy {
+				if c := m.SelectedDiskCategory(); c != nil && c.Reclaimable > 0 {
+					return m.OpenPrune(*c)
+				}
+			}
+			// On the Volumes panel, p prunes the volumes no container uses.
+			if m.focus == FocusVolumes && !m.diskBusy {
+				if c := m.volumesPrune(); c.Active < c.Count {
+					return m.OpenPrune(c)
+				}
+			}
+
+		case key.Matches(msg, keys.Remove):
+			// On the services, d removes the marked ones, or the selected one.
+			if m.focus == FocusServices {
+				targets := m.Marked()
+				if svc := m.SelectedService(); len(targets) == 0 && svc != nil {
+					targets = []infra.ContainerInfo{*svc}
+				}
+				return m.OpenBulk("remove", targets)
+			}
+			if m.focus == FocusVolumes && !m.volumeBusy {
+				if v := m.SelectedVolume(); v != nil {
+					return m.OpenVolumeRemove(*v)
+				}
+			}
+
+		case key.Matches(msg, keys.Actions):
+			// On the logs, a asks the AI about the lines shown.
+			if m.focus == FocusLogs {
+				return m.OpenAnalysis()
+			}
+			if m.focus == FocusImages {
+				if img := m.SelectedImage(); img != nil {
+					return m.OpenImageMenu(*img), nil
+				}
+			}
+
+		case key.Matches(msg, keys.Layers):
+			if m.focus == FocusImages {
+				if img := m.SelectedImage(); img != nil {
+					return m.OpenLayers(*img)
+				}
+			}
+
+		case key.Matches(msg, keys.Merge):
+			switch m.focus {
+			case FocusServices:
+				if svc := m.SelectedService(); svc != nil {
+					m = m.toggleMergedLog(svc.ID)
+					return m, func() tea.Msg { return MergeLogsMsg{} }
+				}
+			case FocusProjects:
+				// A project replaces the merged set, like `docker compose logs`.
+				if p := m.SelectedProject(); p != nil {
+					var ids []string
+					for _, s := range p.Services {
+						if s.ContainerID != "" {
+							ids = append(ids, s.ContainerID)
+						}
+					}
+					if slices.Equal(ids, m.mergedLogs) {
+						ids = nil
+					}
+					m = m.setMergedLogs(ids)
+					return m, func() tea.Msg { return MergeLogsMsg{} }
+				}
+			}
+
+		case key.Matches(msg, keys.Unmerge):
+			if len(m.mergedLogs) > 0 {
+				m = m.setMergedLogs(nil)
+				return m, func() tea.Msg { return MergeLogsMsg{} }
+			}
+
+		case key.Matches(msg, keys.Run):
+			image := ""
+			if m.focus == FocusImages {
+				if img := m.SelectedImage(); img != nil && len(img.Tags) > 0 && img.Tags[0] != "<none>:<none>" {
+					image = img.Tags[0]
+				}
+			}
+			m = m.OpenWizard(image)
+			return m, textinput.Blink
+
 		case key.Matches(msg, keys.Restart):
+			// On a volume, r picks a backup to restore.
+			if m.focus == FocusVolumes && !m.volumeBusy {
+				if v := m.SelectedVolume(); v != nil {
+					return m, func() tea.Msg { return ListBackupsMsg{Volume: v.Name} }
+				}
+			}
+
 			if m.focus == FocusServices {
+				if marked := m.Marked(); len(marked) > 0 {
+					return m.OpenBulk("restart", marked)
+				}
 				if svc := m.SelectedService(); svc != nil {
 					return m, func() tea.Msg {
 						return ContainerActionMsg{
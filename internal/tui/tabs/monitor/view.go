@@ -3,13 +3,20 @@ package monitor
 import (
 	"fmt"
 	"strings"
+	"time"
 
+	"dev-cli/internal/infra"
 	"dev-cli/internal/tui/components"
 	"dev-cli/internal/tui/theme"
 
 	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
 )
 
+// LogTimeZone is the zone log timestamps are shown in. Snapshot tests pin
+// it so their output doesn't depend on the machine's zone.
+var LogTimeZone = time.Local
+
 func (m Model) View() string {
 
 	sidebarWidth := 28
@@ -22,7 +29,18 @@ func (m Model) View() string {
 		logWidth = 40
 	}
 
-	panelHeight := m.height - 4
+	panelHeight := m.height - 4 - m.hostHeight()
+	if m.zoomed {
+		return m.withHostStrip(m.renderZoomed(panelHeight))
+	}
+	// Narrow terminals show either the sidebar, full width, or the logs.
+	compact := components.Compact(m.width)
+	if compact && !m.DrawerOpen() {
+		return m.withHostStrip(m.renderMainPanel(m.width-2, panelHeight))
+	}
+	if compact {
+		sidebarWidth = m.width - 4
+	}
 
 	servicesHeight := (panelHeight - 8) / 2
 	imagesHeight := (panelHeight - 8) / 2
@@ -35,15 +53,243 @@ func (m Model) View() string {
 		imagesHeight = 5
 	}
 
+	var panels []string
+	if ph := m.projectsHeight(); ph > 0 {
+		panels = append(panels, m.renderProjectsPanel(sidebarWidth, ph))
+		servicesHeight = max(servicesHeight-ph, 5)
+	}
+
+	volumesHeight := m.volumesHeight()
+	if volumesHeight > 0 {
+		imagesHeight = max(imagesHeight-volumesHeight, 5)
+	}
+	diskHeight := m.diskHeight()
+	if diskHeight > 0 {
+		imagesHeight = max(imagesHeight-diskHeight, 5)
+	}
+
 	servicesPanel := m.renderServicesPanel(sidebarWidth, servicesHeight)
 	imagesPanel := m.renderImagesPanel(sidebarWidth, imagesHeight)
 	statsPanel := m.renderStatsPanel(sidebarWidth, statsHeight)
 
-	leftColumn := lipgloss.JoinVertical(lipgloss.Left, servicesPanel, imagesPanel, statsPanel)
+	panels = append(panels, servicesPanel, imagesPanel)
+	if volumesHeight > 0 {
+		panels = append(panels, m.renderVolumesPanel(sidebarWidth, volumesHeight))
+	}
+	if diskHeight > 0 {
+		panels = append(panels, m.renderDiskPanel(sidebarWidth, diskHeight))
+	}
+	panels = append(panels, statsPanel)
+	leftColumn := lipgloss.JoinVertical(lipgloss.Left, panels...)
+	if compact {
+		return m.withHostStrip(leftColumn)
+	}
+
+	logsPanel := m.renderMainPanel(logWidth, panelHeight)
+	return m.withHostStrip(lipgloss.JoinHorizontal(lipgloss.Top, leftColumn, logsPanel))
+}
+
+// withHostStrip puts the host strip, when shown, above the panels.
+func (m Model) withHostStrip(panels string) string {
+	if m.host != nil {
+		return lipgloss.JoinVertical(lipgloss.Left, m.renderHostStrip(m.width), panels)
+	}
+	return panels
+}
+
+// renderMainPanel is the logs panel, or the dialog open over it.
+func (m Model) renderMainPanel(width, height int) string {
+	switch {
+	case m.wizard != nil:
+		return m.wizard.View(width, height)
+	case m.files != nil:
+		return m.files.View(width, height)
+	case m.restore != nil:
+		return m.restore.View(width, height)
+	case m.layers != nil:
+		return m.layers.View(width, height)
+	case m.prune != nil:
+		return m.prune.View(width, height)
+	case m.inspect != nil:
+		return m.inspect.View(width, height, m.containerStats[m.inspect.container])
+	case m.imageMenu != nil:
+		return m.imageMenu.View(width, height)
+	case m.removeVolume != nil:
+		return m.removeVolume.View(width, height)
+	case m.processes != nil:
+		return m.processes.View(width, height)
+	case m.analysis != nil:
+		return m.analysis.View(width, height)
+	case m.bulk != nil:
+		return m.bulk.View(width, height)
+	}
+	return m.renderLogsPanel(width, height)
+}
+
+// renderZoomed draws the panel a zoom shows across the whole tab. Sidebar
+// panels count their borders outside the width, the logs panel inside.
+func (m Model) renderZoomed(height int) string {
+	width := m.width - 4
+	switch m.zoomedPanel() {
+	case FocusProjects:
+		return m.renderProjectsPanel(width, height)
+	case FocusServices:
+		return m.renderServicesPanel(width, height)
+	case FocusImages:
+		return m.renderImagesPanel(width, height)
+	case FocusVolumes:
+		return m.renderVolumesPanel(width, height)
+	case FocusDisk:
+		return m.renderDiskPanel(width, height)
+	case FocusStats:
+		return m.renderStatsPanel(width, height)
+	}
+	return m.renderMainPanel(m.width-2, height)
+}
+
+func (m Model) renderProjectsPanel(width, height int) string {
+	borderColor := theme.Surface2
+	if m.focus == FocusProjects {
+		borderColor = theme.Mauve
+	}
+
+	panelStyle := lipgloss.NewStyle().
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(borderColor).
+		Width(width).
+		Height(height).
+		MaxHeight(height)
+
+	headerStyle := lipgloss.NewStyle().
+		Foreground(theme.Lavender).
+		Bold(true)
+
+	countStyle := lipgloss.NewStyle().
+		Foreground(theme.Overlay0)
+
+	header := headerStyle.Render("◇ Projects") + countStyle.Render(fmt.Sprintf(" [%d]", len(m.projects)))
+
+	var content strings.Builder
+	content.WriteString(header + "\n")
+	content.WriteString(m.projectsList.View())
+
+	if m.projectErr != "" {
+		errLine := lipgloss.NewStyle().
+			Foreground(theme.Red).
+			Render(truncateLine(m.projectErr, width-2))
+		content.WriteString("\n" + errLine)
+	}
+
+	return panelStyle.Render(content.String())
+}
+
+func (m Model) renderVolumesPanel(width, height int) string {
+	borderColor := theme.Surface2
+	if m.focus == FocusVolumes {
+		borderColor = theme.Mauve
+	}
+
+	panelStyle := lipgloss.NewStyle().
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(borderColor).
+		Width(width).
+		Height(height).
+		MaxHeight(height)
+
+	headerStyle := lipgloss.NewStyle().
+		Foreground(theme.Lavender).
+		Bold(true)
+
+	countStyle := lipgloss.NewStyle().
+		Foreground(theme.Overlay0)
+
+	header := headerStyle.Render("◫ Volumes") + countStyle.Render(fmt.Sprintf(" [%d]", len(m.volumes)))
+
+	var content strings.Builder
+	content.WriteString(header + "\n")
+	content.WriteString(m.volumesList.View())
+
+	switch {
+	case m.volumeErr != "":
+		content.WriteString("\n" + lipgloss.NewStyle().Foreground(theme.Red).Render(truncateLine(m.volumeErr, width-2)))
+	case m.volumeOp != "":
+		color := theme.Green
+		if m.volumeBusy {
+			color = theme.Yellow
+		}
+		content.WriteString("\n" + lipgloss.NewStyle().Foreground(color).Render(truncateLine(m.volumeOp, width-2)))
+	default:
+		if v := m.SelectedVolume(); v != nil {
+			content.WriteString("\n" + countStyle.Render(truncateLine(volumeUsage(*v), width-2)))
+		}
+	}
+
+	return panelStyle.Render(content.String())
+}
+
+func (m Model) renderDiskPanel(width, height int) string {
+	borderColor := theme.Surface2
+	if m.focus == FocusDisk {
+		borderColor = theme.Mauve
+	}
+
+	panelStyle := lipgloss.NewStyle().
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(borderColor).
+		Width(width).
+		Height(height).
+		MaxHeight(height)
+
+	headerStyle := lipgloss.NewStyle().
+		Foreground(theme.Lavender).
+		Bold(true)
+
+	countStyle := lipgloss.NewStyle().
+		Foreground(theme.Overlay0)
 
-	logsPanel := m.renderLogsPanel(logWidth, panelHeight)
+	var content strings.Builder
+	content.WriteString(headerStyle.Render("⛁ Disk") + countStyle.Render(" ["+formatSize(m.disk.Total())+"]") + "\n")
+
+	for i, c := range m.disk.Categories {
+		// Sizes drop the unit space to fit the sidebar.
+		size := fmt.Sprintf(" %-7s %8s", diskLabels[c.Kind], strings.Replace(formatSize(c.Size), " ", "", 1))
+		reclaim := ""
+		if c.Reclaimable > 0 {
+			reclaim = " ↺" + strings.Replace(formatSize(c.Reclaimable), " ", "", 1)
+		}
+		line := truncateLine(size+reclaim, width-2)
+
+		if m.focus == FocusDisk && i == m.diskCursor {
+			line = lipgloss.NewStyle().
+				Background(theme.Surface1).
+				Foreground(theme.Lavender).
+				Bold(true).
+				Width(width - 2).
+				Render(line)
+		} else if reclaim != "" {
+			line = lipgloss.NewStyle().Foreground(theme.Text).Render(size) +
+				lipgloss.NewStyle().Foreground(theme.Peach).Render(strings.TrimPrefix(line, size))
+		} else {
+			line = lipgloss.NewStyle().Foreground(theme.Text).Render(line)
+		}
+		content.WriteString(line)
+		if i < len(m.disk.Categories)-1 {
+			content.WriteString("\n")
+		}
+	}
 
-	return lipgloss.JoinHorizontal(lipgloss.Top, leftColumn, logsPanel)
+	switch {
+	case m.diskErr != "":
+		content.WriteString("\n" + lipgloss.NewStyle().Foreground(theme.Red).Render(truncateLine(m.diskErr, width-2)))
+	case m.diskOp != "":
+		color := theme.Green
+		if m.diskBusy {
+			color = theme.Yellow
+		}
+		content.WriteString("\n" + lipgloss.NewStyle().Foreground(color).Render(truncateLine(m.diskOp, width-2)))
+	}
+
+	return panelStyle.Render(content.String())
 }
 
 func (m Model) renderServicesPanel(width, height int) string {
@@ -70,11 +316,20 @@ func (m Model) renderServicesPanel(width, height int) string {
 	if len(m.services) > 0 {
 		header += countStyle.Render(fmt.Sprintf(" [%d]", len(m.services)))
 	}
+	if daemon := truncateLine(m.daemon, width-lipgloss.Width(header)-2); daemon != "" {
+		header += countStyle.Render(" " + daemon)
+	}
 
 	var content strings.Builder
 	content.WriteString(header + "\n")
 
-	if len(m.services) == 0 {
+	if len(m.services) == 0 && m.docker.Missing() {
+		hint := lipgloss.NewStyle().
+			Foreground(theme.Peach).
+			Width(width - 2).
+			Render(m.docker.Hint)
+		content.WriteString(hint)
+	} else if len(m.services) == 0 {
 		noItems := lipgloss.NewStyle().
 			Foreground(theme.Overlay0).
 			Render("No services running")
@@ -106,7 +361,7 @@ func (m Model) renderImagesPanel(width, height int) string {
 	countStyle := lipgloss.NewStyle().
 		Foreground(theme.Overlay0)
 
-	header := headerStyle.Render("📦 Images")
+	header := headerStyle.Render(theme.Icon("📦", "*") + " Images")
 	if len(m.images) > 0 {
 		header += countStyle.Render(fmt.Sprintf(" [%d]", len(m.images)))
 	}
@@ -114,6 +369,20 @@ func (m Model) renderImagesPanel(width, height int) string {
 	var content strings.Builder
 	content.WriteString(header + "\n")
 
+	if m.pull != nil {
+		content.WriteString(m.renderPullProgress(width-2) + "\n")
+	} else if m.removing != "" {
+		removing := lipgloss.NewStyle().
+			Foreground(theme.Yellow).
+			Render(truncateLine("Removing "+m.removing+"…", width-2))
+		content.WriteString(removing + "\n")
+	} else if m.imageErr != "" {
+		errLine := lipgloss.NewStyle().
+			Foreground(theme.Red).
+			Render(truncateLine(m.imageErr, width-2))
+		content.WriteString(errLine + "\n")
+	}
+
 	if len(m.images) == 0 {
 		noItems := lipgloss.NewStyle().
 			Foreground(theme.Overlay0).
@@ -126,6 +395,22 @@ func (m Model) renderImagesPanel(width, height int) string {
 	return panelStyle.Render(content.String())
 }
 
+// renderPullProgress shows the pulled ref with its layer count and a bar
+// for the bytes downloaded so far.
+func (m Model) renderPullProgress(width int) string {
+	dimStyle := lipgloss.NewStyle().Foreground(theme.Overlay0)
+
+	label := "↓ " + truncateLine(m.pull.Ref, width-8)
+	if len(m.pull.Layers) > 0 {
+		label += dimStyle.Render(fmt.Sprintf(" %d/%d", m.pull.LayersDone(), len(m.pull.Layers)))
+	}
+
+	current, total := m.pull.Bytes()
+	bar := components.NewProgressBar(int(current>>10), int(total>>10)).
+		SetWidth(max(width-6, 5))
+	return label + "\n" + bar.Render()
+}
+
 func (m Model) renderStatsPanel(width, height int) string {
 	borderColor := theme.Surface2
 	if m.focus == FocusStats {
@@ -212,7 +497,9 @@ func (m Model) renderLogsPanel(width, height int) string {
 
 	header := headerStyle.Render("≡ Logs")
 
-	if svc := m.SelectedService(); svc != nil {
+	if m.MergedLogs() {
+		header += dimStyle.Render(fmt.Sprintf(" (%d containers)", len(m.mergedLogs)))
+	} else if svc := m.SelectedService(); svc != nil {
 		serviceName := svc.Name
 		if len(serviceName) > 15 {
 			serviceName = serviceName[:12] + "…"
@@ -239,6 +526,19 @@ func (m Model) renderLogsPanel(width, height int) string {
 		header += " " + followBadge
 	}
 
+	if m.wrapLogs {
+		wrapBadge := lipgloss.NewStyle().
+			Background(theme.Surface0).
+			Foreground(theme.Text).
+			Padding(0, 1).
+			Render("W")
+		header += " " + wrapBadge
+	}
+
+	if m.zoomed {
+		header += dimStyle.Render(" • Z restores")
+	}
+
 	if m.logLevelFilter != "" {
 		filterBadge := lipgloss.NewStyle().
 			Background(theme.Surface0).
@@ -248,36 +548,35 @@ func (m Model) renderLogsPanel(width, height int) string {
 		header += " " + filterBadge
 	}
 
-	contentWidth := width - 6
-	if contentWidth < 20 {
-		contentWidth = 20
-	}
-
-	contentHeight := height - 4
-	if contentHeight < 5 {
-		contentHeight = 5
-	}
+	contentWidth, contentHeight := m.logContentSize(width, height)
 
 	var displayLines []string
+	if m.execErr != "" {
+		errStyle := lipgloss.NewStyle().Foreground(theme.Red)
+		displayLines = append(displayLines, errStyle.Render(truncateLine(m.execErr, contentWidth)))
+	}
+	searchLine := ""
 	if len(m.logLines) > 0 {
 		filteredLines := m.filterLogLines()
-
-		startIdx := 0
-		if len(filteredLines) > contentHeight {
-			startIdx = len(filteredLines) - contentHeight
+		matches := m.searchMatches(filteredLines)
+		current := -1
+		if m.search != nil && m.search.current < len(matches) {
+			current = m.search.current
 		}
-		visibleLines := filteredLines[startIdx:]
-
-		for _, line := range visibleLines {
-			truncatedLine := truncateLine(line, contentWidth)
-			logLine := components.NewLogLine(truncatedLine)
-			displayLines = append(displayLines, logLine.Render())
+		rows, _, _ := m.logRows(filteredLines, matches, current, contentWidth, contentHeight)
+		displayLines = append(displayLines, rows...)
+		if m.search != nil {
+			searchLine = m.search.status(len(matches))
 		}
 	} else {
 		displayLines = append(displayLines, dimStyle.Render("No logs available"))
 		displayLines = append(displayLines, dimStyle.Render("Select a service to view logs"))
 	}
 
+	if m.search != nil {
+		displayLines = append(displayLines, m.renderSearch(searchLine, contentWidth))
+	}
+
 	var contentBuilder strings.Builder
 	contentBuilder.WriteString(header + "\n")
 	contentBuilder.WriteString(strings.Join(displayLines, "\n"))
@@ -285,6 +584,204 @@ func (m Model) renderLogsPanel(width, height int) string {
 	return panelStyle.Render(contentBuilder.String())
 }
 
+// logContentSize is the room for log lines in a logs panel of the given
+// size, without the lines for the exec error and the search.
+func (m Model) logContentSize(width, height int) (int, int) {
+	contentWidth := max(width-6, 20)
+	contentHeight := max(height-4, 5)
+	if m.execErr != "" {
+		contentHeight--
+	}
+	if m.search != nil {
+		contentHeight--
+	}
+	return contentWidth, contentHeight
+}
+
+// VisibleLogLines are the log lines the logs panel shows: those the level
+// filter lets through, at the tail or around the current search match.
+func (m Model) VisibleLogLines() []string {
+	lines := m.filterLogLines()
+	matches := m.searchMatches(lines)
+	current := -1
+	if m.search != nil && m.search.current < len(matches) {
+		current = m.search.current
+	}
+	// The viewport is kept at the size of the panel's inside.
+	width, height := m.logContentSize(m.viewport.Width+4, m.viewport.Height+4)
+	_, first, end := m.logRows(lines, matches, current, width, height)
+	return lines[first:end]
+}
+
+// logRows renders as many of lines as fit in height rows: the newest, or
+// those around the current search match once n/N moved to one. A wrapped
+// line takes a row per piece. first and end bound the lines shown.
+func (m Model) logRows(lines []string, matches []logMatch, current, width, height int) (rows []string, first, end int) {
+	prefix := m.mergedLogPrefixer()
+	rowsOf := func(i int) []string {
+		var hits []logMatch
+		lineCurrent := -1
+		for j, hit := range matches {
+			if hit.line == i {
+				if j == current {
+					lineCurrent = len(hits)
+				}
+				hits = append(hits, hit)
+			}
+		}
+		return m.renderLogLine(lines[i], prefix, hits, lineCurrent, width)
+	}
+	// window cuts height rows out of rows, from row start, and names the
+	// lines they show.
+	var lineOf []int
+	window := func(start int) ([]string, int, int) {
+		return rows[start : start+height], lineOf[start], lineOf[start+height-1] + 1
+	}
+
+	if current >= 0 {
+		// Start half a panel above the match, then fill down.
+		line := matches[current].line
+		from, above := line, 0
+		for from > 0 && above < height/2 {
+			from--
+			above += len(rowsOf(from))
+		}
+		matchRow := 0
+		for i := from; i < len(lines) && (i <= line || len(rows) < matchRow+height); i++ {
+			if i == line {
+				matchRow = len(rows)
+			}
+			block := rowsOf(i)
+			rows = append(rows, block...)
+			for range block {
+				lineOf = append(lineOf, i)
+			}
+		}
+		// Near the end, the tail below shows the match as well.
+		if len(rows) >= height {
+			return window(min(max(matchRow-height/2, 0), len(rows)-height))
+		}
+		rows, lineOf = nil, nil
+	}
+
+	// The tail: walk up from the newest line until the rows are filled.
+	var blocks [][]string
+	count := 0
+	for i := len(lines) - 1; i >= 0 && count < height; i-- {
+		block := rowsOf(i)
+		blocks = append(blocks, block)
+		count += len(block)
+	}
+	for i := len(blocks) - 1; i >= 0; i-- {
+		rows = append(rows, blocks[i]...)
+		for range blocks[i] {
+			lineOf = append(lineOf, len(lines)-1-i)
+		}
+	}
+	if len(rows) == 0 {
+		return nil, 0, 0
+	}
+	height = min(height, len(rows))
+	return window(len(rows) - height)
+}
+
+// splitLogLine splits a log line into what goes before its message - the
+// container tag of merged logs, and the time unless timestamps are hidden
+// - and the message, which is what the search looks at.
+func (m Model) splitLogLine(line string, prefix func(string) (string, string)) (lead, text string) {
+	text = line
+	if prefix != nil {
+		lead, text = prefix(line)
+	}
+	if at, rest, ok := infra.SplitLogTime(text); ok {
+		text = rest
+		if !m.hideTimestamps {
+			lead += lipgloss.NewStyle().Foreground(theme.Overlay0).Render(at.In(LogTimeZone).Format("15:04:05") + " ")
+		}
+	}
+	return lead, text
+}
+
+// renderLogLine renders one log line, cut to width or, when wrapping,
+// broken into rows that keep clear of the container tag and time.
+func (m Model) renderLogLine(line string, prefix func(string) (string, string), hits []logMatch, current, width int) []string {
+	lead, text := m.splitLogLine(line, prefix)
+	leadWidth := lipgloss.Width(lead)
+	textWidth := max(width-leadWidth, 1)
+	if !m.wrapLogs {
+		return []string{lead + highlight(components.NewLogLine(truncateLine(text, textWidth)).Render(), hits, current)}
+	}
+
+	// Pieces are cut at the width, not at words, so the search hits keep
+	// their offsets; every piece keeps the colour of the line's level.
+	level := components.NewLogLine(text).Level
+	indent := strings.Repeat(" ", leadWidth)
+	var rows []string
+	total := ansi.StringWidth(text)
+	for start := 0; start == 0 || start < total; start += textWidth {
+		end := start + textWidth
+		var pieceHits []logMatch
+		pieceCurrent := -1
+		for i, hit := range hits {
+			if hit.end > start && hit.start < end {
+				if i == current {
+					pieceCurrent = len(pieceHits)
+				}
+				pieceHits = append(pieceHits, logMatch{line: hit.line, start: max(hit.start, start) - start, end: min(hit.end, end) - start})
+			}
+		}
+		piece := components.LogLine{Content: ansi.Cut(text, start, end), Level: level}.Render()
+		row := indent
+		if start == 0 {
+			row = lead
+		}
+		rows = append(rows, row+highlight(piece, pieceHits, pieceCurrent))
+	}
+	return rows
+}
+
+// renderSearch is the line under the logs with the query and its matches:
+// the input while it is typed, the keys to move between matches once kept.
+func (m Model) renderSearch(status string, width int) string {
+	dimStyle := lipgloss.NewStyle().Foreground(theme.Overlay0)
+	if status == "" {
+		status = "no match"
+	}
+	if m.search.editing {
+		hint := dimStyle.Render("  " + status + " • Enter keep • Esc cancel")
+		return ansi.Truncate(m.search.input.View()+hint, width, "…")
+	}
+	query := lipgloss.NewStyle().Foreground(theme.Peach).Bold(true).Render("/" + m.search.input.Value())
+	return ansi.Truncate(query+dimStyle.Render(" "+status+" • n/N next/prev • Esc clear"), width, "…")
+}
+
+// mergedLogPrefixer returns a func that splits a merged log line into its
+// coloured, padded container tag and the line itself; nil when the logs
+// are not merged.
+func (m Model) mergedLogPrefixer() func(string) (string, string) {
+	if !m.MergedLogs() {
+		return nil
+	}
+
+	targets := m.LogTargets()
+	styles := make(map[string]lipgloss.Style, len(targets))
+	palette := logColors()
+	width := 0
+	for i, c := range targets {
+		styles[c.Name] = lipgloss.NewStyle().Foreground(palette[i%len(palette)])
+		width = max(width, len(c.Name))
+	}
+
+	return func(line string) (string, string) {
+		name, rest, ok := infra.SplitLogPrefix(line)
+		style, known := styles[name]
+		if !ok || !known {
+			return "", line
+		}
+		return style.Render(fmt.Sprintf("%-*s │ ", width, name)), rest
+	}
+}
+
 func (m Model) filterLogLines() []string {
 	if m.logLevelFilter == "" {
 		return m.logLines
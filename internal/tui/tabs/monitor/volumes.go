@@ -0,0 +1,191 @@
+package monitor
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"dev-cli/internal/infra"
+	"dev-cli/internal/tui/components"
+	"dev-cli/internal/tui/theme"
+
+	"github.com/charmbracelet/bubbles/list"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// Volume item for bubbles/list
+type volumeItem struct {
+	info infra.VolumeInfo
+}
+
+func (i volumeItem) Title() string       { return i.info.Name }
+func (i volumeItem) Description() string { return i.info.Driver }
+func (i volumeItem) FilterValue() string { return i.info.Name }
+
+// Custom delegate for volume list
+type volumeDelegate struct{}
+
+func (d volumeDelegate) Height() int                             { return 1 }
+func (d volumeDelegate) Spacing() int                            { return 0 }
+func (d volumeDelegate) Update(_ tea.Msg, _ *list.Model) tea.Cmd { return nil }
+
+func (d volumeDelegate) Render(w io.Writer, m list.Model, index int, listItem list.Item) {
+	i, ok := listItem.(volumeItem)
+	if !ok {
+		return
+	}
+
+	// In-use volumes get a blue icon, unused (prunable) ones a dim one.
+	iconColor := theme.Overlay0
+	if len(i.info.Containers) > 0 {
+		iconColor = theme.Blue
+	}
+	size := ""
+	if i.info.Size >= 0 {
+		size = strings.Replace(formatSize(i.info.Size), " ", "", 1)
+	}
+	name := truncateLine(i.info.Name, max(m.Width()-4-len(size), 5))
+	gap := max(m.Width()-3-lipgloss.Width(name)-len(size), 1)
+	line := fmt.Sprintf(" %s %s%s%s",
+		lipgloss.NewStyle().Foreground(iconColor).Render("◫"),
+		lipgloss.NewStyle().Foreground(theme.Text).Render(name),
+		strings.Repeat(" ", gap),
+		lipgloss.NewStyle().Foreground(theme.Overlay0).Render(size))
+
+	if index == m.Index() {
+		line = lipgloss.NewStyle().
+			Background(theme.Surface1).
+			Foreground(theme.Lavender).
+			Bold(true).
+			Width(m.Width()).
+			Render(line)
+	}
+
+	fmt.Fprint(w, line)
+}
+
+// volumeUsage says which containers mount v, for the Volumes panel.
+func volumeUsage(v infra.VolumeInfo) string {
+	if len(v.Containers) == 0 {
+		return "↳ unused"
+	}
+	return "↳ " + strings.Join(v.Containers, ", ")
+}
+
+// RemoveVolumeMsg asks the app to remove a volume.
+type RemoveVolumeMsg struct {
+	Volume string
+}
+
+// VolumeRemoveConfirm asks before a volume is removed; y confirms, n or
+// esc cancels. A volume in use can't be removed, so it only explains why.
+type VolumeRemoveConfirm struct {
+	volume infra.VolumeInfo
+}
+
+func (c VolumeRemoveConfirm) confirm() components.Confirm {
+	v := c.volume
+	if len(v.Containers) > 0 {
+		return components.Confirm{
+			Title: "Remove volume " + v.Name,
+			Text: []string{"It is used by " + strings.Join(v.Containers, ", ") +
+				". Docker keeps a volume while any container, running or stopped, mounts it: remove those first."},
+			Blocked: true,
+		}
+	}
+	text := "This deletes the volume and every file in it; no container uses it."
+	if v.Size > 0 {
+		text += " It frees " + formatSize(v.Size) + "."
+	}
+	return components.Confirm{
+		Title:  "Remove volume " + v.Name,
+		Text:   []string{text, "Back it up first with b to keep a copy."},
+		Verb:   "remove",
+		Danger: true,
+	}
+}
+
+func (c VolumeRemoveConfirm) View(width, height int) string {
+	return c.confirm().View(width, height)
+}
+
+// BackupVolumeMsg asks the app to back a volume up.
+type BackupVolumeMsg struct {
+	Volume string
+}
+
+// ListBackupsMsg asks the app for a volume's backups, to pick one to
+// restore.
+type ListBackupsMsg struct {
+	Volume string
+}
+
+// RestoreVolumeMsg asks the app to restore a volume from a backup.
+type RestoreVolumeMsg struct {
+	Volume string
+	Backup string
+}
+
+// RestorePicker lists a volume's backups, newest first; enter restores the
+// selected one.
+type RestorePicker struct {
+	volume  string
+	backups []infra.VolumeBackup
+	cursor  int
+}
+
+// Update handles a key while the picker is open. Closing it (esc) is left
+// to the caller.
+func (p RestorePicker) Update(msg tea.KeyMsg) (RestorePicker, tea.Cmd) {
+	switch msg.String() {
+	case "up", "k":
+		p.cursor = max(p.cursor-1, 0)
+	case "down", "j":
+		p.cursor = min(p.cursor+1, max(len(p.backups)-1, 0))
+	case "enter":
+		if p.cursor < len(p.backups) {
+			volume, backup := p.volume, p.backups[p.cursor].Path
+			return p, func() tea.Msg { return RestoreVolumeMsg{Volume: volume, Backup: backup} }
+		}
+	}
+	return p, nil
+}
+
+func (p RestorePicker) View(width, height int) string {
+	panelStyle := lipgloss.NewStyle().
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(theme.Mauve).
+		Width(width).
+		Height(height).
+		MaxHeight(height).
+		MaxWidth(width)
+
+	headerStyle := lipgloss.NewStyle().Foreground(theme.Lavender).Bold(true)
+	dimStyle := lipgloss.NewStyle().Foreground(theme.Overlay0)
+	textStyle := lipgloss.NewStyle().Foreground(theme.Text)
+	selectedStyle := lipgloss.NewStyle().Background(theme.Surface1).Foreground(theme.Lavender).Bold(true).Width(width - 2)
+
+	var content strings.Builder
+	content.WriteString(headerStyle.Render("↺ Restore "+p.volume) + "\n\n")
+
+	if len(p.backups) == 0 {
+		content.WriteString(dimStyle.Render("No backups yet; press b on the volume to make one") + "\n")
+	}
+	visible := max(height-5, 1)
+	start := max(p.cursor-visible+1, 0)
+	for i := start; i < len(p.backups) && i < start+visible; i++ {
+		b := p.backups[i]
+		line := fmt.Sprintf(" %s  %s", b.Created.Format("2006-01-02 15:04:05"), formatSize(b.Size))
+		if i == p.cursor {
+			content.WriteString(selectedStyle.Render(line) + "\n")
+		} else {
+			content.WriteString(textStyle.Render(line) + "\n")
+		}
+	}
+	content.WriteString("\n")
+	content.WriteString(lipgloss.NewStyle().Foreground(theme.Peach).Render("Restoring overwrites the volume's files") + "\n")
+	content.WriteString(dimStyle.Render("Enter restore • j/k move • Esc cancel"))
+
+	return panelStyle.Render(content.String())
+}
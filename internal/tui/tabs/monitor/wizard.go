@@ -0,0 +1,160 @@
+package monitor
+
+import (
+	"slices"
+	"strings"
+
+	"dev-cli/internal/infra"
+	"dev-cli/internal/tui/theme"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// Run wizard fields, in the order they are filled in.
+const (
+	wizardImage = iota
+	wizardName
+	wizardPorts
+	wizardEnv
+	wizardVolumes
+)
+
+var wizardFields = []struct {
+	label       string
+	placeholder string
+}{
+	{"Image", "nginx:alpine"},
+	{"Name", "optional"},
+	{"Ports", "8080:80, 5432"},
+	{"Env", "KEY=value, ..."},
+	{"Volumes", "./data:/data, cache:/cache"},
+}
+
+// RunContainerMsg asks the app to create and start a container.
+type RunContainerMsg struct {
+	Spec infra.RunSpec
+}
+
+// RunWizard is the "run container" form: one line per docker run option.
+// Enter moves to the next field and submits from the last one.
+type RunWizard struct {
+	inputs  []textinput.Model
+	focus   int
+	err     string
+	running bool
+}
+
+func NewRunWizard(image string) RunWizard {
+	inputs := make([]textinput.Model, len(wizardFields))
+	for i, f := range wizardFields {
+		ti := textinput.New()
+		ti.Prompt = ""
+		ti.Placeholder = f.placeholder
+		ti.PlaceholderStyle = lipgloss.NewStyle().Foreground(theme.Overlay0)
+		ti.TextStyle = lipgloss.NewStyle().Foreground(theme.Text)
+		inputs[i] = ti
+	}
+	inputs[wizardImage].SetValue(image)
+
+	w := RunWizard{inputs: inputs}
+	if image != "" {
+		w.focus = wizardName
+	}
+	w.inputs[w.focus].Focus()
+	return w
+}
+
+// Spec validates the form.
+func (w RunWizard) Spec() (infra.RunSpec, error) {
+	return infra.ParseRunSpec(
+		w.inputs[wizardImage].Value(),
+		w.inputs[wizardName].Value(),
+		w.inputs[wizardPorts].Value(),
+		w.inputs[wizardEnv].Value(),
+		w.inputs[wizardVolumes].Value(),
+	)
+}
+
+// setFocus moves the focus to field i. Like Update, it works on a copy of
+// the inputs so other copies of the wizard keep theirs.
+func (w RunWizard) setFocus(i int) RunWizard {
+	w.inputs = slices.Clone(w.inputs)
+	w.inputs[w.focus].Blur()
+	w.focus = (i + len(w.inputs)) % len(w.inputs)
+	w.inputs[w.focus].Focus()
+	return w
+}
+
+// Update handles a key while the wizard is open. Closing it (esc) is left
+// to the caller.
+func (w RunWizard) Update(msg tea.KeyMsg) (RunWizard, tea.Cmd) {
+	if w.running {
+		return w, nil
+	}
+
+	switch msg.String() {
+	case "tab", "down":
+		return w.setFocus(w.focus + 1), nil
+	case "shift+tab", "up":
+		return w.setFocus(w.focus - 1), nil
+	case "enter":
+		if w.focus < len(w.inputs)-1 {
+			return w.setFocus(w.focus + 1), nil
+		}
+		spec, err := w.Spec()
+		if err != nil {
+			w.err = err.Error()
+			return w, nil
+		}
+		w.err = ""
+		w.running = true
+		return w, func() tea.Msg { return RunContainerMsg{Spec: spec} }
+	}
+
+	var cmd tea.Cmd
+	w.inputs = slices.Clone(w.inputs)
+	w.inputs[w.focus], cmd = w.inputs[w.focus].Update(msg)
+	return w, cmd
+}
+
+func (w RunWizard) View(width, height int) string {
+	panelStyle := lipgloss.NewStyle().
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(theme.Mauve).
+		Width(width).
+		Height(height).
+		MaxHeight(height).
+		MaxWidth(width)
+
+	headerStyle := lipgloss.NewStyle().Foreground(theme.Lavender).Bold(true)
+	labelStyle := lipgloss.NewStyle().Foreground(theme.Overlay0).Width(9)
+	activeLabel := labelStyle.Foreground(theme.Mauve).Bold(true)
+	dimStyle := lipgloss.NewStyle().Foreground(theme.Overlay0)
+
+	var content strings.Builder
+	content.WriteString(headerStyle.Render("▶ Run container") + "\n\n")
+
+	for i, f := range wizardFields {
+		label := labelStyle.Render(f.label)
+		if i == w.focus {
+			label = activeLabel.Render(f.label)
+		}
+		in := w.inputs[i]
+		in.Width = max(width-14, 10)
+		content.WriteString(label + " " + in.View() + "\n")
+	}
+	content.WriteString("\n")
+
+	switch {
+	case w.running:
+		content.WriteString(lipgloss.NewStyle().Foreground(theme.Yellow).Render("Starting container…"))
+	case w.err != "":
+		content.WriteString(lipgloss.NewStyle().Foreground(theme.Red).Render(truncateLine(w.err, width-4)))
+	default:
+		content.WriteString(dimStyle.Render("Enter next/run • Tab move • Esc cancel"))
+	}
+
+	return panelStyle.Render(content.String())
+}
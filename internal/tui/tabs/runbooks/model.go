@@ -0,0 +1,265 @@
+package runbooks
+
+import (
+	"fmt"
+	"slices"
+
+	"dev-cli/internal/pipeline"
+	"dev-cli/internal/storage"
+	"dev-cli/internal/tui/theme"
+	"dev-cli/internal/workflow"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	"github.com/charmbracelet/lipgloss"
+)
+
+type FocusPanel int
+
+const (
+	FocusList FocusPanel = iota
+	FocusSteps
+)
+
+// RunMsg asks to execute Runbook through the workflow engine, confirming
+// each step.
+type RunMsg struct {
+	Runbook storage.Runbook
+}
+
+// AnswerMsg answers the engine's question about the next step: run it or
+// skip it.
+type AnswerMsg struct {
+	Run bool
+}
+
+// AbortMsg stops the run at the step being asked about.
+type AbortMsg struct{}
+
+// SaveMsg asks to store an edited Runbook.
+type SaveMsg struct {
+	Runbook storage.Runbook
+}
+
+// stepState is how far a step of the run got.
+type stepState struct {
+	status   workflow.StepStatus
+	exitCode int
+	output   string
+}
+
+// runState follows a run of one runbook, and stays on show once it ends.
+type runState struct {
+	runbookID string
+	steps     map[string]stepState
+	// asking is the step the engine waits to have confirmed.
+	asking string
+	done   bool
+	result workflow.RunStatus
+	err    string
+}
+
+// stepEdit edits a step's command inline; adding inserts a new step after
+// index instead.
+type stepEdit struct {
+	input  textinput.Model
+	index  int
+	adding bool
+}
+
+// Model is the Runbooks tab: the stored runbooks by project on the left,
+// the selected one's steps on the right, where it is run and edited.
+type Model struct {
+	width  int
+	height int
+	focus  FocusPanel
+	store  pipeline.Availability
+
+	runbooks []storage.Runbook
+	projects []string
+	// project indexes projects; -1 shows every project.
+	project int
+	cursor  int
+	step    int
+
+	run  *runState
+	edit *stepEdit
+	err  string
+}
+
+func New() Model {
+	return Model{project: -1}
+}
+
+func (m Model) SetSize(w, h int) Model {
+	m.width = w
+	m.height = h
+	return m
+}
+
+// SetAvailability records whether the history database, which holds the
+// runbooks, opened.
+func (m Model) SetAvailability(a pipeline.Availability) Model {
+	m.store = a
+	return m
+}
+
+// SetRunbooks replaces the runbooks, keeping the selection where it can.
+func (m Model) SetRunbooks(runbooks []storage.Runbook) Model {
+	var selected, project string
+	if rb := m.SelectedRunbook(); rb != nil {
+		selected = rb.ID
+	}
+	if m.project >= 0 {
+		project = m.projects[m.project]
+	}
+
+	m.runbooks = runbooks
+	m.projects = nil
+	for _, rb := range runbooks {
+		if !slices.Contains(m.projects, rb.ProjectID) {
+			m.projects = append(m.projects, rb.ProjectID)
+		}
+	}
+	m.project = slices.Index(m.projects, project)
+	if project == "" && m.project >= 0 && len(m.visible()) == 0 {
+		m.project = -1
+	}
+
+	m.cursor = 0
+	for i, rb := range m.visible() {
+		if rb.ID == selected {
+			m.cursor = i
+		}
+	}
+	m.clampStep()
+	return m
+}
+
+// SetError shows why the last save failed; nil clears it.
+func (m Model) SetError(err error) Model {
+	m.err = ""
+	if err != nil {
+		m.err = err.Error()
+	}
+	return m
+}
+
+// StepAsked marks stepID as waiting for confirmation.
+func (m Model) StepAsked(stepID string) Model {
+	if m.run != nil {
+		m.run.asking = stepID
+	}
+	return m
+}
+
+// StepDone records how stepID ended.
+func (m Model) StepDone(stepID string, status workflow.StepStatus, exitCode int) Model {
+	if m.run != nil {
+		st := m.run.steps[stepID]
+		st.status, st.exitCode = status, exitCode
+		m.run.steps[stepID] = st
+	}
+	return m
+}
+
+// RunDone ends the run with its result; err explains a run that could not
+// start or was cut short.
+func (m Model) RunDone(result *workflow.RunResult, err error) Model {
+	if m.run == nil {
+		return m
+	}
+	m.run.done = true
+	m.run.asking = ""
+	if result != nil {
+		m.run.result = result.Status
+		for id, r := range result.StepResults {
+			m.run.steps[id] = stepState{status: r.Status, exitCode: r.ExitCode, output: r.Output}
+		}
+		if result.Error != "" {
+			m.run.err = result.Error
+		}
+	}
+	if err != nil && m.run.err == "" {
+		m.run.err = err.Error()
+	}
+	return m
+}
+
+// Running reports whether a run is in progress.
+func (m Model) Running() bool { return m.run != nil && !m.run.done }
+
+// Editing reports whether a step is being edited, so keys go to its input.
+func (m Model) Editing() bool { return m.edit != nil }
+
+func (m Model) Focus() FocusPanel { return m.focus }
+
+func (m Model) Runbooks() []storage.Runbook { return m.runbooks }
+
+// SelectedRunbook is the runbook under the cursor, nil without any.
+func (m Model) SelectedRunbook() *storage.Runbook {
+	visible := m.visible()
+	if m.cursor < len(visible) {
+		rb := visible[m.cursor]
+		return &rb
+	}
+	return nil
+}
+
+// visible lists the runbooks of the chosen project, or all of them.
+func (m Model) visible() []storage.Runbook {
+	if m.project < 0 {
+		return m.runbooks
+	}
+	var runbooks []storage.Runbook
+	for _, rb := range m.runbooks {
+		if rb.ProjectID == m.projects[m.project] {
+			runbooks = append(runbooks, rb)
+		}
+	}
+	return runbooks
+}
+
+// clampStep keeps the step cursor on a step of the selected runbook.
+func (m *Model) clampStep() {
+	steps := 0
+	if rb := m.SelectedRunbook(); rb != nil {
+		steps = len(rb.Steps)
+	}
+	m.step = max(min(m.step, steps-1), 0)
+}
+
+// replace swaps in the edited copy of a runbook.
+func (m *Model) replace(rb storage.Runbook) {
+	for i := range m.runbooks {
+		if m.runbooks[i].ID == rb.ID {
+			m.runbooks[i] = rb
+		}
+	}
+}
+
+func newStepInput(value string) textinput.Model {
+	ti := textinput.New()
+	ti.Prompt = "$ "
+	ti.PromptStyle = lipgloss.NewStyle().Foreground(theme.Mauve).Bold(true)
+	ti.Placeholder = "command"
+	ti.SetValue(value)
+	ti.Focus()
+	return ti
+}
+
+// newStepID is an ID no step of rb uses yet.
+func newStepID(rb storage.Runbook) string {
+	for n := len(rb.Steps) + 1; ; n++ {
+		id := fmt.Sprintf("step_%d", n)
+		if !slices.ContainsFunc(rb.Steps, func(s storage.RunbookStep) bool { return s.ID == id }) {
+			return id
+		}
+	}
+}
+
+func projectLabel(project string) string {
+	if project == "" {
+		return "no project"
+	}
+	return project
+}
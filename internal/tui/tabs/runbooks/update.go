@@ -0,0 +1,250 @@
+package runbooks
+
+import (
+	"slices"
+	"strings"
+
+	"dev-cli/internal/storage"
+	"dev-cli/internal/workflow"
+
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+type KeyMap struct {
+	Up      key.Binding
+	Down    key.Binding
+	Steps   key.Binding
+	Back    key.Binding
+	Project key.Binding
+	Run     key.Binding
+
+	// While a step waits for confirmation
+	Yes   key.Binding
+	No    key.Binding
+	Abort key.Binding
+
+	// Editing the steps
+	Edit   key.Binding
+	Add    key.Binding
+	Delete key.Binding
+	Save   key.Binding
+	Cancel key.Binding
+}
+
+func DefaultKeyMap() KeyMap {
+	return KeyMap{
+		Up: key.NewBinding(
+			key.WithKeys("up", "k"),
+			key.WithHelp("j/k", "nav"),
+		),
+		Down: key.NewBinding(
+			key.WithKeys("down", "j"),
+			key.WithHelp("", ""),
+		),
+		Steps: key.NewBinding(
+			key.WithKeys("enter", "l", "right"),
+			key.WithHelp("Enter", "steps"),
+		),
+		Back: key.NewBinding(
+			key.WithKeys("esc", "h", "left"),
+			key.WithHelp("Esc", "back"),
+		),
+		Project: key.NewBinding(
+			key.WithKeys("p"),
+			key.WithHelp("p", "project"),
+		),
+		Run: key.NewBinding(
+			key.WithKeys("r"),
+			key.WithHelp("r", "run"),
+		),
+		Yes: key.NewBinding(
+			key.WithKeys("y"),
+			key.WithHelp("y", "run step"),
+		),
+		No: key.NewBinding(
+			key.WithKeys("n"),
+			key.WithHelp("n", "skip step"),
+		),
+		Abort: key.NewBinding(
+			key.WithKeys("esc"),
+			key.WithHelp("Esc", "abort"),
+		),
+		Edit: key.NewBinding(
+			key.WithKeys("e"),
+			key.WithHelp("e", "edit step"),
+		),
+		Add: key.NewBinding(
+			key.WithKeys("a"),
+			key.WithHelp("a", "add step"),
+		),
+		Delete: key.NewBinding(
+			key.WithKeys("x"),
+			key.WithHelp("x", "delete step"),
+		),
+		Save: key.NewBinding(
+			key.WithKeys("enter"),
+			key.WithHelp("Enter", "save"),
+		),
+		Cancel: key.NewBinding(
+			key.WithKeys("esc"),
+			key.WithHelp("Esc", "cancel"),
+		),
+	}
+}
+
+func (m Model) Update(msg tea.Msg, keys KeyMap) (Model, tea.Cmd) {
+	km, ok := msg.(tea.KeyMsg)
+	if !ok {
+		return m, nil
+	}
+
+	if m.edit != nil {
+		return m.updateEdit(km, keys)
+	}
+	if m.run != nil && m.run.asking != "" {
+		return m.updateAsking(km, keys)
+	}
+
+	switch {
+	case key.Matches(km, keys.Up), key.Matches(km, keys.Down):
+		delta := 1
+		if key.Matches(km, keys.Up) {
+			delta = -1
+		}
+		if m.focus == FocusSteps {
+			m.step += delta
+			m.clampStep()
+		} else if !m.Running() {
+			m.cursor = max(min(m.cursor+delta, len(m.visible())-1), 0)
+			m.step = 0
+		}
+
+	case key.Matches(km, keys.Steps):
+		if m.SelectedRunbook() != nil {
+			m.focus = FocusSteps
+		}
+
+	case key.Matches(km, keys.Back):
+		m.focus = FocusList
+
+	case key.Matches(km, keys.Project):
+		if !m.Running() && len(m.projects) > 1 {
+			m.project++
+			if m.project == len(m.projects) {
+				m.project = -1
+			}
+			m.cursor, m.step = 0, 0
+		}
+
+	case key.Matches(km, keys.Run):
+		return m.startRun()
+
+	case key.Matches(km, keys.Edit), key.Matches(km, keys.Add):
+		rb := m.SelectedRunbook()
+		if m.focus != FocusSteps || m.Running() || rb == nil {
+			return m, nil
+		}
+		if key.Matches(km, keys.Add) {
+			m.edit = &stepEdit{input: newStepInput(""), index: m.step, adding: true}
+		} else if m.step < len(rb.Steps) {
+			m.edit = &stepEdit{input: newStepInput(rb.Steps[m.step].Command), index: m.step}
+		}
+
+	case key.Matches(km, keys.Delete):
+		rb := m.SelectedRunbook()
+		if m.focus != FocusSteps || m.Running() || rb == nil || m.step >= len(rb.Steps) {
+			return m, nil
+		}
+		edited := *rb
+		edited.Steps = slices.Delete(slices.Clone(rb.Steps), m.step, m.step+1)
+		return m.save(edited)
+	}
+
+	return m, nil
+}
+
+// updateEdit handles keys while a step's command is being typed.
+func (m Model) updateEdit(km tea.KeyMsg, keys KeyMap) (Model, tea.Cmd) {
+	switch {
+	case key.Matches(km, keys.Cancel):
+		m.edit = nil
+		return m, nil
+
+	case key.Matches(km, keys.Save):
+		edit := m.edit
+		m.edit = nil
+		command := strings.TrimSpace(edit.input.Value())
+		rb := m.SelectedRunbook()
+		if command == "" || rb == nil {
+			return m, nil
+		}
+
+		edited := *rb
+		edited.Steps = slices.Clone(rb.Steps)
+		if edit.adding {
+			at := min(edit.index+1, len(edited.Steps))
+			edited.Steps = slices.Insert(edited.Steps, at, storage.RunbookStep{ID: newStepID(*rb), Command: command})
+			m.step = at
+		} else {
+			edited.Steps[edit.index].Command = command
+		}
+		return m.save(edited)
+	}
+
+	edit := *m.edit
+	var cmd tea.Cmd
+	edit.input, cmd = edit.input.Update(km)
+	m.edit = &edit
+	return m, cmd
+}
+
+// updateAsking handles keys while the engine waits for a step to be
+// confirmed.
+func (m Model) updateAsking(km tea.KeyMsg, keys KeyMap) (Model, tea.Cmd) {
+	switch {
+	case key.Matches(km, keys.Yes), key.Matches(km, keys.No):
+		run := key.Matches(km, keys.Yes)
+		if run {
+			m.run.steps[m.run.asking] = stepState{status: workflow.StepRunning}
+		}
+		m.run.asking = ""
+		return m, func() tea.Msg { return AnswerMsg{Run: run} }
+
+	case key.Matches(km, keys.Abort):
+		m.run.asking = ""
+		return m, func() tea.Msg { return AbortMsg{} }
+
+	case key.Matches(km, keys.Up), key.Matches(km, keys.Down):
+		// The steps can still be looked through before answering.
+		m.focus = FocusSteps
+		if key.Matches(km, keys.Up) {
+			m.step--
+		} else {
+			m.step++
+		}
+		m.clampStep()
+	}
+	return m, nil
+}
+
+// startRun runs the selected runbook, unless one is already running.
+func (m Model) startRun() (Model, tea.Cmd) {
+	rb := m.SelectedRunbook()
+	if rb == nil || m.Running() || m.store.Missing() {
+		return m, nil
+	}
+	m.run = &runState{runbookID: rb.ID, steps: make(map[string]stepState)}
+	m.focus = FocusSteps
+	m.step = 0
+	runbook := *rb
+	return m, func() tea.Msg { return RunMsg{Runbook: runbook} }
+}
+
+// save shows edited right away and asks to store it.
+func (m Model) save(edited storage.Runbook) (Model, tea.Cmd) {
+	m.replace(edited)
+	m.clampStep()
+	m.err = ""
+	return m, func() tea.Msg { return SaveMsg{Runbook: edited} }
+}
@@ -0,0 +1,344 @@
+package runbooks
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"dev-cli/internal/storage"
+	"dev-cli/internal/tui/components"
+	"dev-cli/internal/tui/theme"
+	"dev-cli/internal/workflow"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
+)
+
+func (m Model) View() string {
+	listWidth := 40
+	if m.width < 100 {
+		listWidth = m.width / 3
+	}
+	if listWidth < 25 {
+		listWidth = 25
+	}
+
+	stepsWidth := m.width - listWidth - 4
+	panelHeight := m.height - 4
+
+	if stepsWidth < 30 {
+		stepsWidth = 30
+	}
+	if panelHeight < 10 {
+		panelHeight = 10
+	}
+
+	if components.Compact(m.width) {
+		// Narrow terminals stack the list above the steps.
+		listHeight := max(panelHeight*2/5, 6)
+		return lipgloss.JoinVertical(lipgloss.Left,
+			m.renderList(m.width-2, listHeight),
+			m.renderRunbook(m.width-2, panelHeight-listHeight-2))
+	}
+
+	list := m.renderList(listWidth, panelHeight)
+	steps := m.renderRunbook(stepsWidth, panelHeight)
+
+	return lipgloss.JoinHorizontal(lipgloss.Top, list, steps)
+}
+
+func panelStyle(focused bool, width, height int) lipgloss.Style {
+	borderColor := theme.Surface2
+	if focused {
+		borderColor = theme.Mauve
+	}
+	return lipgloss.NewStyle().
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(borderColor).
+		Width(width).
+		Height(height)
+}
+
+// renderList lists the runbooks, under a heading per project when every
+// project shows.
+func (m Model) renderList(width, height int) string {
+	headerStyle := lipgloss.NewStyle().Foreground(theme.Lavender).Bold(true)
+	dimStyle := lipgloss.NewStyle().Foreground(theme.Overlay0)
+	projectStyle := lipgloss.NewStyle().Foreground(theme.Peach)
+	selectedStyle := lipgloss.NewStyle().Background(theme.Surface1).Foreground(theme.Lavender).Bold(true)
+	textStyle := lipgloss.NewStyle().Foreground(theme.Text)
+
+	header := headerStyle.Render(" ▤ Runbooks")
+	if m.project >= 0 {
+		header += dimStyle.Render(" · ") + projectStyle.Render(projectLabel(m.projects[m.project]))
+	}
+	lines := []string{header}
+	style := panelStyle(m.focus == FocusList, width, height)
+
+	switch {
+	case m.store.Missing():
+		lines = append(lines, lipgloss.NewStyle().
+			Foreground(theme.Peach).
+			Width(width-2).
+			Padding(1).
+			Render(m.store.Hint))
+		return style.Render(strings.Join(lines, "\n"))
+	case len(m.runbooks) == 0:
+		lines = append(lines, dimStyle.Width(width-2).Padding(1).Render("No runbooks saved yet"))
+		return style.Render(strings.Join(lines, "\n"))
+	}
+
+	var rows []string
+	selectedRow := 0
+	project := "\x00"
+	for i, rb := range m.visible() {
+		if m.project < 0 && rb.ProjectID != project {
+			project = rb.ProjectID
+			rows = append(rows, projectStyle.Render(" "+projectLabel(project)))
+		}
+
+		stats := fmt.Sprintf("%3.0f%% ·%d", rb.SuccessRate*100, rb.UsageCount)
+		if rb.UsageCount == 0 {
+			stats = "new"
+		}
+		nameWidth := width - 6 - lipgloss.Width(stats)
+		name := ansi.Truncate(rb.Name, nameWidth, "…")
+		gap := strings.Repeat(" ", max(nameWidth-lipgloss.Width(name), 0)+1)
+
+		if i == m.cursor {
+			selectedRow = len(rows)
+			rows = append(rows, selectedStyle.Width(width-2).Render("▌ "+name+gap+stats))
+			continue
+		}
+		rows = append(rows, "  "+textStyle.Render(name)+gap+rateStyle(rb).Render(stats))
+	}
+
+	lines = append(lines, scrollTo(rows, selectedRow, height-1)...)
+	return style.Render(strings.Join(lines, "\n"))
+}
+
+func rateStyle(rb storage.Runbook) lipgloss.Style {
+	switch {
+	case rb.UsageCount == 0:
+		return lipgloss.NewStyle().Foreground(theme.Overlay0)
+	case rb.SuccessRate >= 0.8:
+		return lipgloss.NewStyle().Foreground(theme.Green)
+	case rb.SuccessRate >= 0.5:
+		return lipgloss.NewStyle().Foreground(theme.Yellow)
+	}
+	return lipgloss.NewStyle().Foreground(theme.Red)
+}
+
+// renderRunbook shows the selected runbook: what it is for, how it has
+// fared, and its steps with the progress of a run.
+func (m Model) renderRunbook(width, height int) string {
+	headerStyle := lipgloss.NewStyle().Foreground(theme.Lavender).Bold(true)
+	dimStyle := lipgloss.NewStyle().Foreground(theme.Overlay0)
+	style := panelStyle(m.focus == FocusSteps, width, height)
+
+	rb := m.SelectedRunbook()
+	if rb == nil {
+		return style.Render(headerStyle.Render(" Steps"))
+	}
+
+	lines := []string{headerStyle.Render(" " + ansi.Truncate(rb.Name, width-2, "…"))}
+	if rb.Description != "" {
+		lines = append(lines, lipgloss.NewStyle().Foreground(theme.Subtext0).Width(width-2).PaddingLeft(1).Render(rb.Description))
+	}
+	lines = append(lines, dimStyle.Render(" "+ansi.Truncate(runbookStats(*rb), width-2, "…")), "")
+
+	var rows []string
+	selectedRow := 0
+	for i, step := range rb.Steps {
+		if i == m.step {
+			selectedRow = len(rows)
+		}
+		rows = append(rows, m.renderStep(i, step, width-2)...)
+	}
+	if len(rb.Steps) == 0 {
+		rows = append(rows, dimStyle.Render(" No steps · a to add one"))
+	}
+
+	footer := m.footer(*rb, width-2)
+	rows = scrollTo(rows, selectedRow, height-len(lines)-len(footer))
+	lines = append(append(lines, rows...), footer...)
+	return style.Render(strings.Join(lines, "\n"))
+}
+
+func runbookStats(rb storage.Runbook) string {
+	parts := []string{"never run"}
+	if rb.UsageCount > 0 {
+		parts = []string{fmt.Sprintf("%.0f%% success over %d runs", rb.SuccessRate*100, rb.UsageCount)}
+		if !rb.LastUsed.IsZero() {
+			parts = append(parts, "last used "+formatAgo(time.Since(rb.LastUsed)))
+		}
+	}
+	if len(rb.Tags) > 0 {
+		parts = append(parts, "#"+strings.Join(rb.Tags, " #"))
+	}
+	return strings.Join(parts, " · ")
+}
+
+// renderStep renders a step with its command and, where set, its rollback
+// and condition.
+func (m Model) renderStep(i int, step storage.RunbookStep, width int) []string {
+	dimStyle := lipgloss.NewStyle().Foreground(theme.Overlay0)
+	commandStyle := lipgloss.NewStyle().Foreground(theme.Text)
+
+	icon, iconStyle := " ", dimStyle
+	var state stepState
+	if m.run != nil && m.run.runbookID == m.SelectedRunbook().ID {
+		state = m.run.steps[stepID(i, step)]
+		icon, iconStyle = statusIcon(state.status)
+		if m.run.asking == stepID(i, step) {
+			icon, iconStyle = "▶", lipgloss.NewStyle().Foreground(theme.Yellow).Bold(true)
+		}
+	}
+
+	// A step without a name or description goes by its command alone.
+	title := step.Name
+	if title == "" {
+		title = step.Description
+	}
+	command := step.Command
+	if title == "" {
+		title, command = "$ "+command, ""
+	}
+	nameStyle := lipgloss.NewStyle().Foreground(theme.Text)
+	cursor := "  "
+	if i == m.step && m.focus == FocusSteps {
+		cursor = lipgloss.NewStyle().Foreground(theme.Mauve).Render("▌ ")
+		nameStyle = nameStyle.Foreground(theme.Lavender).Bold(true)
+	}
+
+	head := fmt.Sprintf("%s%s %2d. %s", cursor, iconStyle.Render(icon), i+1, nameStyle.Render(ansi.Truncate(title, width-10, "…")))
+	if state.status == workflow.StepFailed {
+		head += lipgloss.NewStyle().Foreground(theme.Red).Render(fmt.Sprintf("  exit %d", state.exitCode))
+	}
+
+	if m.edit != nil && !m.edit.adding && m.edit.index == i {
+		input := m.edit.input
+		input.Width = width - 10
+		return []string{head, "       " + input.View()}
+	}
+	lines := []string{head}
+	if command != "" {
+		prefix := "       $ "
+		if workflow.IsDestructive(command) {
+			prefix = lipgloss.NewStyle().Foreground(theme.Peach).Render("     ⚠ $ ")
+		}
+		lines = append(lines, prefix+commandStyle.Render(ansi.Truncate(command, width-9, "…")))
+	} else if workflow.IsDestructive(step.Command) {
+		lines[0] += lipgloss.NewStyle().Foreground(theme.Peach).Render("  ⚠ destructive")
+	}
+	if step.Rollback != "" {
+		lines = append(lines, dimStyle.Render("       ↺ "+ansi.Truncate(step.Rollback, width-9, "…")))
+	}
+	if step.Condition != "" {
+		lines = append(lines, dimStyle.Render("       if "+ansi.Truncate(step.Condition, width-10, "…")))
+	}
+	if state.status == workflow.StepFailed && state.output != "" {
+		output := strings.Split(strings.TrimRight(state.output, "\n"), "\n")
+		for _, line := range output[max(len(output)-3, 0):] {
+			lines = append(lines, lipgloss.NewStyle().Foreground(theme.Red).Render("       │ "+ansi.Truncate(line, width-9, "…")))
+		}
+	}
+
+	if m.edit != nil && m.edit.adding && m.edit.index == i {
+		input := m.edit.input
+		input.Width = width - 10
+		lines = append(lines, "  "+dimStyle.Render("+   ")+" "+input.View())
+	}
+	return lines
+}
+
+func statusIcon(status workflow.StepStatus) (string, lipgloss.Style) {
+	switch status {
+	case workflow.StepRunning:
+		return "▶", lipgloss.NewStyle().Foreground(theme.Blue)
+	case workflow.StepSuccess:
+		return "✓", lipgloss.NewStyle().Foreground(theme.Green)
+	case workflow.StepFailed:
+		return "✗", lipgloss.NewStyle().Foreground(theme.Red)
+	case workflow.StepSkipped:
+		return "⏭", lipgloss.NewStyle().Foreground(theme.Overlay0)
+	case workflow.StepRolledBack:
+		return "↺", lipgloss.NewStyle().Foreground(theme.Peach)
+	}
+	return "◌", lipgloss.NewStyle().Foreground(theme.Overlay0)
+}
+
+// footer is the question about the next step while a run waits for it,
+// the outcome once it ends, or an error to show.
+func (m Model) footer(rb storage.Runbook, width int) []string {
+	dimStyle := lipgloss.NewStyle().Foreground(theme.Overlay0)
+	errStyle := lipgloss.NewStyle().Foreground(theme.Red)
+
+	if m.err != "" {
+		return []string{"", errStyle.Render(ansi.Truncate(" ✗ "+m.err, width, "…"))}
+	}
+	if m.edit != nil {
+		return []string{"", dimStyle.Render(" Enter save · Esc cancel")}
+	}
+	if m.run == nil || m.run.runbookID != rb.ID {
+		return nil
+	}
+
+	if m.run.asking != "" {
+		var command string
+		for i, step := range rb.Steps {
+			if stepID(i, step) == m.run.asking {
+				command = step.Command
+			}
+		}
+		question := lipgloss.NewStyle().Foreground(theme.Yellow).Bold(true).Render(" Run " + ansi.Truncate(command, width-30, "…") + "?")
+		if workflow.IsDestructive(command) {
+			question = lipgloss.NewStyle().Foreground(theme.Peach).Bold(true).Render(" ⚠ Destructive: run " + ansi.Truncate(command, width-40, "…") + "?")
+		}
+		return []string{"", question, dimStyle.Render(" y run · n skip · Esc abort")}
+	}
+
+	switch {
+	case !m.run.done:
+		return []string{"", lipgloss.NewStyle().Foreground(theme.Blue).Render(" Running...")}
+	case m.run.result == workflow.StatusCompleted:
+		return []string{"", lipgloss.NewStyle().Foreground(theme.Green).Render(" ✓ Completed")}
+	case m.run.result == workflow.StatusPaused:
+		return []string{"", lipgloss.NewStyle().Foreground(theme.Peach).Render(" ⏸ Aborted")}
+	}
+	msg := " ✗ Failed"
+	if m.run.err != "" {
+		msg += ": " + m.run.err
+	}
+	return []string{"", errStyle.Render(ansi.Truncate(msg, width, "…"))}
+}
+
+// stepID is the ID the workflow engine knows step i by.
+func stepID(i int, step storage.RunbookStep) string {
+	if step.ID == "" {
+		return fmt.Sprintf("step_%d", i)
+	}
+	return step.ID
+}
+
+// scrollTo keeps at most height of rows, scrolled so row selected shows.
+func scrollTo(rows []string, selected, height int) []string {
+	height = max(height, 1)
+	if len(rows) <= height {
+		return rows
+	}
+	start := min(max(selected-height/2, 0), len(rows)-height)
+	return rows[start : start+height]
+}
+
+func formatAgo(d time.Duration) string {
+	switch {
+	case d < time.Minute:
+		return "just now"
+	case d < time.Hour:
+		return fmt.Sprintf("%dm ago", int(d.Minutes()))
+	case d < 24*time.Hour:
+		return fmt.Sprintf("%dh ago", int(d.Hours()))
+	}
+	return fmt.Sprintf("%dd ago", int(d.Hours()/24))
+}
@@ -0,0 +1,60 @@
+package theme
+
+import "strings"
+
+// ascii is set by SetASCII.
+var ascii bool
+
+// SetASCII turns plain ASCII rendering on or off, for terminals without
+// box drawing or emoji and for screen readers.
+func SetASCII(on bool) { ascii = on }
+
+// ASCII reports whether the UI renders in plain ASCII.
+func ASCII() bool { return ascii }
+
+// Icon is glyph, or label in ASCII mode. Emoji take two cells, so Plain
+// can't swap them in place; views name them with Icon instead.
+func Icon(glyph, label string) string {
+	if ascii {
+		return label
+	}
+	return glyph
+}
+
+// Plain rewrites a rendered view for ASCII mode: borders, status glyphs and
+// sparkline bars become ASCII characters of the same width, so the layout
+// holds. Outside ASCII mode the view is returned as is.
+func Plain(view string) string {
+	if !ascii {
+		return view
+	}
+	return plainGlyphs.Replace(view)
+}
+
+var plainGlyphs = strings.NewReplacer(plainPairs...)
+
+// plainPairs maps each glyph to its ASCII stand-in, one cell each.
+var plainPairs = []string{
+	// Borders.
+	"╭", "+", "╮", "+", "╰", "+", "╯", "+",
+	"┌", "+", "┐", "+", "└", "+", "┘", "+",
+	"├", "+", "┤", "+", "┬", "+", "┴", "+", "┼", "+",
+	"─", "-", "━", "-", "│", "|", "┃", "|",
+	"▌", "|", "▐", "|", "▍", "|", "▎", "|", "▏", "|", "▮", "|",
+	// Sparklines and gauges, low to high.
+	"▁", "_", "▂", ".", "▃", "-", "▄", "-", "▅", "=", "▆", "+", "▇", "*", "█", "#",
+	"░", ".", "▒", ":", "▓", "#",
+	// Status.
+	"✓", "+", "✗", "x", "✕", "x", "×", "x", "⚠", "!", "♥", "h",
+	"●", "*", "○", "o", "◌", "-", "◐", "~", "ℹ", "i", "ⓘ", "i",
+	"⏸", "|", "⏭", ">", "⌫", "<",
+	// Arrows and prompts.
+	"↑", "^", "↓", "v", "→", ">", "←", "<", "↳", ">", "↺", "@", "↻", "@",
+	"❯", ">", "▶", ">", "▸", ">", "⧩", "v",
+	// Panel and tab icons.
+	"◈", "*", "⬢", "*", "⬡", "*", "◇", "*", "▤", "*", "▦", "*", "▣", "*",
+	"◫", "*", "⛁", "*", "☸", "*", "⎈", "*", "⚙", "*", "⌘", "*", "✦", "*",
+	"⌂", "*", "⌕", "/", "≡", "=", "≋", "~",
+	// Punctuation.
+	"…", "~", "•", "*", "·", ".", "–", "-",
+}
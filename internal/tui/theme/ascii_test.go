@@ -0,0 +1,40 @@
+package theme
+
+import (
+	"strings"
+	"testing"
+	"unicode"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
+)
+
+func TestPlain(t *testing.T) {
+	t.Cleanup(func() { SetASCII(false) })
+
+	for i := 0; i < len(plainPairs); i += 2 {
+		glyph, plain := plainPairs[i], plainPairs[i+1]
+		if ansi.StringWidth(glyph) != len(plain) {
+			t.Errorf("%q takes %d cells but its stand-in %q %d", glyph, ansi.StringWidth(glyph), plain, len(plain))
+		}
+	}
+
+	box := lipgloss.NewStyle().Border(lipgloss.RoundedBorder()).Width(12).Render("✓ ok • ▁▃█")
+	if got := Plain(box); got != box || Icon("📌", "[pin]") != "📌" {
+		t.Fatalf("expected views unchanged outside ASCII mode, got:\n%s", got)
+	}
+
+	SetASCII(true)
+	got := Plain(box)
+	for _, r := range got {
+		if r > unicode.MaxASCII {
+			t.Fatalf("expected only ASCII, found %q in:\n%s", r, got)
+		}
+	}
+	if lipgloss.Width(got) != lipgloss.Width(box) || !strings.Contains(got, "+------------+") || !strings.Contains(got, "|+ ok * _-#") {
+		t.Errorf("expected the box redrawn in ASCII at the same width, got:\n%s", got)
+	}
+	if got := Icon("📌", "[pin]"); got != "[pin]" {
+		t.Errorf("expected the label in ASCII mode, got %q", got)
+	}
+}
@@ -0,0 +1,206 @@
+package theme
+
+import (
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+// Palette is a full set of UI colors. Light palettes invert the shade
+// roles: Base is the light background and Text the dark foreground.
+type Palette struct {
+	Name  string
+	Light bool
+
+	Crust, Base, Mantle                    lipgloss.Color
+	Surface0, Surface1, Surface2           lipgloss.Color
+	Overlay0, Overlay1                     lipgloss.Color
+	Text, Subtext0, Lavender               lipgloss.Color
+	Mauve, Red, Green, Yellow, Blue, Peach lipgloss.Color
+	Teal, Pink                             lipgloss.Color
+}
+
+// Catppuccin is Catppuccin Mocha, the default.
+var Catppuccin = Palette{
+	Name:     "catppuccin",
+	Crust:    "#11111b",
+	Base:     "#1e1e2e",
+	Mantle:   "#181825",
+	Surface0: "#313244",
+	Surface1: "#45475a",
+	Surface2: "#585b70",
+	Overlay0: "#6c7086",
+	Overlay1: "#7f849c",
+	Text:     "#cdd6f4",
+	Subtext0: "#a6adc8",
+	Lavender: "#b4befe",
+	Mauve:    "#cba6f7",
+	Red:      "#f38ba8",
+	Green:    "#a6e3a1",
+	Yellow:   "#f9e2af",
+	Blue:     "#89b4fa",
+	Peach:    "#fab387",
+	Teal:     "#94e2d5",
+	Pink:     "#f5c2e7",
+}
+
+// Gruvbox is Gruvbox dark with its bright accents.
+var Gruvbox = Palette{
+	Name:     "gruvbox",
+	Crust:    "#1d2021",
+	Base:     "#282828",
+	Mantle:   "#32302f",
+	Surface0: "#3c3836",
+	Surface1: "#504945",
+	Surface2: "#665c54",
+	Overlay0: "#7c6f64",
+	Overlay1: "#928374",
+	Text:     "#ebdbb2",
+	Subtext0: "#bdae93",
+	Lavender: "#d5c4a1",
+	Mauve:    "#d3869b",
+	Red:      "#fb4934",
+	Green:    "#b8bb26",
+	Yellow:   "#fabd2f",
+	Blue:     "#83a598",
+	Peach:    "#fe8019",
+	Teal:     "#8ec07c",
+	Pink:     "#d3869b",
+}
+
+// SolarizedDark is Solarized on base03.
+var SolarizedDark = Palette{
+	Name:     "solarized-dark",
+	Crust:    "#001e26",
+	Base:     "#002b36",
+	Mantle:   "#00252f",
+	Surface0: "#073642",
+	Surface1: "#0a4454",
+	Surface2: "#35616d",
+	Overlay0: "#586e75",
+	Overlay1: "#657b83",
+	Text:     "#93a1a1",
+	Subtext0: "#839496",
+	Lavender: "#eee8d5",
+	Mauve:    "#6c71c4",
+	Red:      "#dc322f",
+	Green:    "#859900",
+	Yellow:   "#b58900",
+	Blue:     "#268bd2",
+	Peach:    "#cb4b16",
+	Teal:     "#2aa198",
+	Pink:     "#d33682",
+}
+
+// SolarizedLight is Solarized on base3, for light terminals.
+var SolarizedLight = Palette{
+	Name:     "solarized-light",
+	Light:    true,
+	Crust:    "#fdf6e3",
+	Base:     "#fdf6e3",
+	Mantle:   "#eee8d5",
+	Surface0: "#eee8d5",
+	Surface1: "#e4ddc8",
+	Surface2: "#93a1a1",
+	Overlay0: "#93a1a1",
+	Overlay1: "#839496",
+	Text:     "#586e75",
+	Subtext0: "#657b83",
+	Lavender: "#073642",
+	Mauve:    "#6c71c4",
+	Red:      "#dc322f",
+	Green:    "#859900",
+	Yellow:   "#b58900",
+	Blue:     "#268bd2",
+	Peach:    "#cb4b16",
+	Teal:     "#2aa198",
+	Pink:     "#d33682",
+}
+
+// HighContrast is white and saturated colors on black, for low-vision use
+// and washed-out projectors.
+var HighContrast = Palette{
+	Name:     "high-contrast",
+	Crust:    "#000000",
+	Base:     "#000000",
+	Mantle:   "#000000",
+	Surface0: "#262626",
+	Surface1: "#444444",
+	Surface2: "#ffffff",
+	Overlay0: "#bcbcbc",
+	Overlay1: "#d0d0d0",
+	Text:     "#ffffff",
+	Subtext0: "#e4e4e4",
+	Lavender: "#ffffff",
+	Mauve:    "#ff5fff",
+	Red:      "#ff5f5f",
+	Green:    "#5fff5f",
+	Yellow:   "#ffff00",
+	Blue:     "#5fafff",
+	Peach:    "#ffaf00",
+	Teal:     "#00ffff",
+	Pink:     "#ff87d7",
+}
+
+// palettes is the registry, in the order Next cycles through.
+var palettes = []Palette{Catppuccin, Gruvbox, SolarizedDark, SolarizedLight, HighContrast}
+
+var current Palette
+
+// Names lists the registered palettes.
+func Names() []string {
+	names := make([]string, len(palettes))
+	for i, p := range palettes {
+		names[i] = p.Name
+	}
+	return names
+}
+
+// Lookup finds a palette by name, ignoring case.
+func Lookup(name string) (Palette, bool) {
+	name = strings.ToLower(strings.TrimSpace(name))
+	for _, p := range palettes {
+		if p.Name == name {
+			return p, true
+		}
+	}
+	return Palette{}, false
+}
+
+// Current is the palette in use.
+func Current() Palette {
+	return current
+}
+
+// Next is the palette after the current one, wrapping around.
+func Next() Palette {
+	for i, p := range palettes {
+		if p.Name == current.Name {
+			return palettes[(i+1)%len(palettes)]
+		}
+	}
+	return palettes[0]
+}
+
+// Apply makes p the palette in use, rebuilding the shared styles. Styles
+// built before the call keep the old colors, so views should derive their
+// styles when rendering.
+func Apply(p Palette) {
+	current = p
+
+	Crust, Base, Mantle = p.Crust, p.Base, p.Mantle
+	Surface0, Surface1, Surface2 = p.Surface0, p.Surface1, p.Surface2
+	Overlay0, Overlay1 = p.Overlay0, p.Overlay1
+	Text, Subtext0, Lavender = p.Text, p.Subtext0, p.Lavender
+	Mauve, Red, Green, Yellow, Blue, Peach = p.Mauve, p.Red, p.Green, p.Yellow, p.Blue, p.Peach
+	Teal, Pink = p.Teal, p.Pink
+
+	LogError, LogWarn, LogInfo, LogDebug = Red, Yellow, Blue, Overlay0
+	StatusRunning, StatusStopped, StatusPending = Green, Red, Yellow
+
+	// Components that use adaptive colors (the bubbles defaults) follow the
+	// palette's background rather than the terminal's.
+	lipgloss.SetHasDarkBackground(!p.Light)
+
+	buildStyles()
+}
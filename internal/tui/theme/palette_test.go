@@ -0,0 +1,48 @@
+package theme
+
+import "testing"
+
+func TestLookup(t *testing.T) {
+	for _, name := range Names() {
+		p, ok := Lookup(name)
+		if !ok || p.Name != name {
+			t.Errorf("Lookup(%q) = %q, %v", name, p.Name, ok)
+		}
+	}
+	if p, ok := Lookup(" Gruvbox "); !ok || p.Name != "gruvbox" {
+		t.Errorf("expected lookup to ignore case and spaces, got %q, %v", p.Name, ok)
+	}
+	if _, ok := Lookup("nord"); ok {
+		t.Error("expected an unknown theme not to be found")
+	}
+}
+
+func TestApply(t *testing.T) {
+	t.Cleanup(func() { Apply(Catppuccin) })
+
+	Apply(SolarizedLight)
+	if Current().Name != "solarized-light" || Base != SolarizedLight.Base || Text != SolarizedLight.Text {
+		t.Fatalf("expected the palette's colors, got base %s text %s", Base, Text)
+	}
+	if LogError != SolarizedLight.Red || StatusRunning != SolarizedLight.Green {
+		t.Error("expected the log and status aliases to follow the palette")
+	}
+	if Title.GetBackground() != SolarizedLight.Mauve || Panel.GetBorderTopForeground() != SolarizedLight.Surface2 {
+		t.Error("expected the shared styles to be rebuilt from the palette")
+	}
+}
+
+func TestNext(t *testing.T) {
+	t.Cleanup(func() { Apply(Catppuccin) })
+
+	Apply(Catppuccin)
+	seen := map[string]bool{}
+	for range Names() {
+		p := Next()
+		seen[p.Name] = true
+		Apply(p)
+	}
+	if len(seen) != len(Names()) || Current().Name != "catppuccin" {
+		t.Errorf("expected Next to visit every theme and wrap around, saw %v", seen)
+	}
+}
@@ -0,0 +1,330 @@
+package tui
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"dev-cli/internal/tui/components"
+	"dev-cli/internal/tui/theme"
+	"dev-cli/internal/workflow"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
+)
+
+// workflowOutputLines is how much of each step's output a run keeps.
+const workflowOutputLines = 2000
+
+// workflowRun is a workflow run started from the palette, shown live in
+// an overlay: its steps as they start and end, and the selected step's
+// output as it is written.
+type workflowRun struct {
+	name  string
+	steps []workflowRunStep
+
+	cursor int
+	// follow moves the cursor to each step as it starts, until the cursor
+	// is moved by hand.
+	follow bool
+	// scroll is how many lines the output is scrolled back from its end.
+	scroll int
+
+	started time.Time
+	// ended is set, with status and err, when the run is over.
+	ended  time.Time
+	status workflow.RunStatus
+	err    string
+}
+
+// workflowRunStep is a step of the run, or a combination of a matrix step.
+type workflowRunStep struct {
+	id, name string
+	// status is empty until the step starts.
+	status   workflow.StepStatus
+	exitCode int
+	attempt  int
+	started  time.Time
+	duration time.Duration
+	lines    []string
+	err      string
+}
+
+func newWorkflowRun(wf *workflow.Workflow, now time.Time) *workflowRun {
+	r := &workflowRun{name: wf.Name, follow: true, started: now}
+	for _, step := range wf.Steps {
+		r.steps = append(r.steps, workflowRunStep{id: step.ID, name: cmp.Or(step.Name, step.ID)})
+	}
+	return r
+}
+
+// running reports whether the run is still going.
+func (r *workflowRun) running() bool {
+	return r.ended.IsZero()
+}
+
+// step returns the row for id, adding one for a matrix combination after
+// the rows of its step.
+func (r *workflowRun) step(id string) *workflowRunStep {
+	if i := slices.IndexFunc(r.steps, func(s workflowRunStep) bool { return s.id == id }); i >= 0 {
+		return &r.steps[i]
+	}
+	at := len(r.steps)
+	if parent, _, ok := strings.Cut(id, "["); ok {
+		for i, s := range r.steps {
+			if s.id == parent || strings.HasPrefix(s.id, parent+"[") {
+				at = i + 1
+			}
+		}
+	}
+	r.steps = slices.Insert(r.steps, at, workflowRunStep{id: id, name: id})
+	if r.cursor >= at && at < len(r.steps)-1 {
+		r.cursor++
+	}
+	return &r.steps[at]
+}
+
+// apply records what msg reports about the run at now.
+func (r *workflowRun) apply(msg workflowProgressMsg, now time.Time) {
+	switch {
+	case msg.done:
+		r.ended = now
+		r.status = workflow.StatusFailed
+		if msg.result != nil {
+			r.status, r.err = msg.result.Status, msg.result.Error
+			for id, result := range msg.result.StepResults {
+				if s := r.step(id); s.status != workflow.StepRunning {
+					s.status = result.Status
+				}
+			}
+		}
+		if msg.err != nil && r.err == "" {
+			r.err = msg.err.Error()
+		}
+
+	case msg.output:
+		s := r.step(msg.stepID)
+		s.lines = append(s.lines, ansi.Strip(msg.line))
+		if over := len(s.lines) - workflowOutputLines; over > 0 {
+			s.lines = s.lines[over:]
+		}
+
+	case msg.step != nil:
+		s := r.step(msg.stepID)
+		s.name = cmp.Or(msg.name, s.name)
+		result := msg.step
+		if result.Status == workflow.StepRunning {
+			if s.status == "" {
+				s.started = now
+			} else {
+				s.lines = append(s.lines, fmt.Sprintf("── attempt %d ──", result.Retries+1))
+			}
+			s.status, s.attempt = workflow.StepRunning, result.Retries+1
+			if r.follow {
+				r.cursor = slices.IndexFunc(r.steps, func(st workflowRunStep) bool { return st.id == msg.stepID })
+				r.scroll = 0
+			}
+			return
+		}
+		s.status, s.exitCode, s.err = result.Status, result.ExitCode, result.Error
+		s.duration = result.Duration
+		if !s.started.IsZero() {
+			s.duration = now.Sub(s.started)
+		}
+		// A matrix step's output is its combinations', and a step run
+		// without streaming has only its final output.
+		if len(s.lines) == 0 && result.Output != "" {
+			s.lines = strings.Split(ansi.Strip(result.Output), "\n")
+		}
+	}
+}
+
+// elapsed is how long the step has been running, or ran.
+func (s workflowRunStep) elapsed(now time.Time) time.Duration {
+	if s.status == workflow.StepRunning {
+		return now.Sub(s.started)
+	}
+	return s.duration
+}
+
+// runWorkflowFile starts the workflow at path and opens its overlay. One
+// run goes at a time; while it does, this shows it instead.
+func runWorkflowFile(path string) func(Model) (Model, tea.Cmd) {
+	return func(m Model) (Model, tea.Cmd) {
+		if m.workflowRun != nil && m.workflowRun.running() {
+			m.workflowOpen = true
+			return m.notify(components.NotifyWarning, "Workflow "+m.workflowRun.name+" is still running")
+		}
+		wf, err := workflow.ParseFile(path)
+		if err != nil {
+			return m.notify(components.NotifyError, fmt.Sprintf("Workflow %s is invalid: %v", filepath.Base(path), err))
+		}
+		ctx, cancel := context.WithCancel(context.Background())
+		m.workflowRun = newWorkflowRun(wf, time.Now())
+		m.workflowOpen = true
+		m.workflowCancel = cancel
+		return m, runWorkflow(ctx, m.db, wf)
+	}
+}
+
+func showWorkflowRun(m Model) (Model, tea.Cmd) {
+	m.workflowOpen = true
+	return m, nil
+}
+
+// updateWorkflowRun handles a key while the run's overlay is open: ↑/↓
+// pick a step, PgUp/PgDn scroll its output, x pauses the run and Esc hides
+// the overlay, leaving the run going.
+func (m Model) updateWorkflowRun(msg tea.KeyMsg) (Model, tea.Cmd) {
+	r := m.workflowRun
+	switch msg.String() {
+	case "esc", "q":
+		m.workflowOpen = false
+	case "up", "k":
+		r.cursor = max(r.cursor-1, 0)
+		r.follow, r.scroll = false, 0
+	case "down", "j":
+		r.cursor = min(r.cursor+1, len(r.steps)-1)
+		r.follow, r.scroll = false, 0
+	case "f":
+		r.follow = true
+		if i := slices.IndexFunc(r.steps, func(s workflowRunStep) bool { return s.status == workflow.StepRunning }); i >= 0 {
+			r.cursor, r.scroll = i, 0
+		}
+	case "pgup", "ctrl+u":
+		if r.cursor < len(r.steps) {
+			r.scroll = min(r.scroll+10, max(len(r.steps[r.cursor].lines)-1, 0))
+		}
+	case "pgdown", "ctrl+d":
+		r.scroll = max(r.scroll-10, 0)
+	case "x":
+		if r.running() && m.workflowCancel != nil {
+			m.workflowCancel()
+		}
+	}
+	return m, nil
+}
+
+// view renders the run: its steps with a spinner on the running ones and
+// the time each took, then as much of the selected step's output as fits.
+func (r *workflowRun) view(width, height int, spinner string, now time.Time) string {
+	titleStyle := lipgloss.NewStyle().Foreground(theme.Lavender).Bold(true)
+	dimStyle := lipgloss.NewStyle().Foreground(theme.Overlay0)
+	textStyle := lipgloss.NewStyle().Foreground(theme.Text)
+	selectedStyle := lipgloss.NewStyle().Background(theme.Surface1).Foreground(theme.Lavender).Bold(true)
+	inner := width - 4
+
+	var status string
+	switch {
+	case r.running():
+		status = lipgloss.NewStyle().Foreground(theme.Blue).Render("running " + formatElapsed(now.Sub(r.started)))
+	case r.status == workflow.StatusCompleted:
+		status = lipgloss.NewStyle().Foreground(theme.Green).Render("✓ completed in " + formatElapsed(r.ended.Sub(r.started)))
+	case r.status == workflow.StatusPaused:
+		status = lipgloss.NewStyle().Foreground(theme.Yellow).Render("⏸ paused after " + formatElapsed(r.ended.Sub(r.started)))
+	default:
+		status = lipgloss.NewStyle().Foreground(theme.Red).Render(fmt.Sprintf("✗ %s after %s", r.status, formatElapsed(r.ended.Sub(r.started))))
+	}
+	title := titleStyle.Render("⚙ " + ansi.Truncate(r.name, inner-lipgloss.Width(status)-5, "…"))
+	lines := []string{title + "  " + status}
+
+	// The steps get up to half of what's left after the title, the
+	// output's header, the footer and the border.
+	rows := max(height-7, 2)
+	stepRows := min(len(r.steps), max(rows/2, 1))
+	start := min(max(r.cursor-stepRows/2, 0), max(len(r.steps)-stepRows, 0))
+	for i := start; i < min(start+stepRows, len(r.steps)); i++ {
+		s := r.steps[i]
+		icon, iconStyle := workflowStepIcon(s.status, spinner)
+		var detail string
+		switch {
+		case s.status == workflow.StepFailed && s.exitCode != 0:
+			detail = fmt.Sprintf("exit %d · %s", s.exitCode, formatElapsed(s.elapsed(now)))
+		case s.status == workflow.StepRunning && s.attempt > 1:
+			detail = fmt.Sprintf("attempt %d · %s", s.attempt, formatElapsed(s.elapsed(now)))
+		case s.status == workflow.StepRunning || s.status == workflow.StepSuccess || s.status == workflow.StepFailed:
+			detail = formatElapsed(s.elapsed(now))
+		}
+		label := ansi.Truncate(s.name, inner-4-lipgloss.Width(detail), "…")
+		gap := strings.Repeat(" ", max(inner-3-lipgloss.Width(label)-lipgloss.Width(detail), 1))
+		if i == r.cursor {
+			lines = append(lines, iconStyle.Render(icon)+" "+selectedStyle.Width(inner-2).Render(label+gap+detail))
+			continue
+		}
+		lines = append(lines, iconStyle.Render(icon)+" "+textStyle.Render(label)+gap+dimStyle.Render(detail))
+	}
+
+	outputRows := max(rows-stepRows, 1)
+	if r.err != "" && !r.running() {
+		outputRows = max(outputRows-1, 1)
+	}
+	var output []string
+	if r.cursor < len(r.steps) {
+		s := r.steps[r.cursor]
+		header := "─ " + ansi.Truncate(s.name, inner-4, "…") + " "
+		lines = append(lines, dimStyle.Render(header+strings.Repeat("─", max(inner-lipgloss.Width(header), 0))))
+		output = s.lines
+		if s.err != "" && s.err != r.err && s.status == workflow.StepFailed {
+			output = append(slices.Clip(output), s.err)
+		}
+	}
+	end := len(output) - min(r.scroll, max(len(output)-outputRows, 0))
+	shown := output[max(end-outputRows, 0):end]
+	for _, line := range shown {
+		lines = append(lines, textStyle.Render(ansi.Truncate(strings.ReplaceAll(line, "\t", "    "), inner, "…")))
+	}
+	if len(output) == 0 {
+		lines = append(lines, dimStyle.Render("no output"))
+	}
+	for range outputRows - max(len(shown), 1) {
+		lines = append(lines, "")
+	}
+
+	if r.err != "" && !r.running() {
+		lines = append(lines, lipgloss.NewStyle().Foreground(theme.Red).Render(ansi.Truncate("✗ "+r.err, inner, "…")))
+	}
+	help := "↑/↓ step • PgUp/PgDn scroll • f follow • x pause • Esc hide"
+	if !r.running() {
+		help = "↑/↓ step • PgUp/PgDn scroll • Esc close"
+	}
+	lines = append(lines, dimStyle.Render(ansi.Truncate(help, inner, "…")))
+
+	return lipgloss.NewStyle().
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(theme.Mauve).
+		Padding(0, 1).
+		Width(width).
+		Render(strings.Join(lines, "\n"))
+}
+
+// workflowStepIcon is the mark in front of a step in the run's overlay;
+// a running step spins.
+func workflowStepIcon(status workflow.StepStatus, spinner string) (string, lipgloss.Style) {
+	switch status {
+	case workflow.StepRunning:
+		return spinner, lipgloss.NewStyle().Foreground(theme.Blue)
+	case workflow.StepSuccess:
+		return "✓", lipgloss.NewStyle().Foreground(theme.Green)
+	case workflow.StepFailed:
+		return "✗", lipgloss.NewStyle().Foreground(theme.Red)
+	case workflow.StepSkipped:
+		return "⏭", lipgloss.NewStyle().Foreground(theme.Overlay0)
+	case workflow.StepRolledBack:
+		return "↺", lipgloss.NewStyle().Foreground(theme.Peach)
+	}
+	return "◌", lipgloss.NewStyle().Foreground(theme.Overlay0)
+}
+
+// formatElapsed shows d in whole seconds, as 42s or 3m05s.
+func formatElapsed(d time.Duration) string {
+	d = d.Truncate(time.Second)
+	if d < time.Minute {
+		return fmt.Sprintf("%ds", int(d.Seconds()))
+	}
+	return fmt.Sprintf("%dm%02ds", int(d.Minutes()), int(d.Seconds())%60)
+}
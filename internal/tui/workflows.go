@@ -0,0 +1,91 @@
+package tui
+
+import (
+	"context"
+	"database/sql"
+	"os"
+	"path/filepath"
+	"time"
+
+	"dev-cli/internal/pipeline"
+	"dev-cli/internal/plugins/notify"
+	"dev-cli/internal/workflow"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// draftWorkflow saves a workflow drafted from commands where workflow
+// generate puts them, so the palette offers to run it. The user fills in
+// its placeholder rollbacks first.
+func draftWorkflow(commands []workflow.RecordedCommand, now time.Time) tea.Cmd {
+	return func() tea.Msg {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return workflowDraftedMsg{err: err}
+		}
+		wf, data, err := workflow.DraftFromCommands("Drafted "+now.Format("2006-01-02 15:04"), commands)
+		if err != nil {
+			return workflowDraftedMsg{err: err}
+		}
+		path, err := workflow.SaveGenerated(filepath.Join(home, ".devlogs", "workflows"), wf, data)
+		return workflowDraftedMsg{path: path, steps: len(wf.Steps), err: err}
+	}
+}
+
+// runWorkflow runs wf through the workflow engine in the background,
+// checkpointed to db like workflow run does, so it can be resumed from the
+// command line; waitWorkflow delivers its steps' progress and output until
+// a final message with done set. Cancelling ctx pauses the run.
+func runWorkflow(ctx context.Context, db *sql.DB, wf *workflow.Workflow) tea.Cmd {
+	return func() tea.Msg {
+		var store *workflow.CheckpointStore
+		if db != nil {
+			store = workflow.NewCheckpointStore(db)
+			if err := store.InitSchema(); err != nil {
+				return workflowProgressMsg{done: true, err: err}
+			}
+		}
+
+		ch := make(chan workflowProgressMsg, 64)
+		bus := pipeline.NewEventBus()
+		bus.Subscribe(pipeline.EventWorkflowStep, func(e pipeline.Event) {
+			data, _ := e.Data.(map[string]interface{})
+			name, _ := data["step_name"].(string)
+			status, _ := data["status"].(string)
+			attempt, _ := data["attempt"].(int)
+			result := &workflow.StepResult{StepID: e.BlockID, Status: workflow.StepStatus(status), Retries: attempt - 1}
+			result.ExitCode, _ = data["exit_code"].(int)
+			result.Output, _ = data["output"].(string)
+			result.Error, _ = data["error"].(string)
+			result.Duration, _ = data["duration"].(time.Duration)
+			ch <- workflowProgressMsg{ch: ch, stepID: e.BlockID, name: name, step: result}
+		})
+		bus.Subscribe(pipeline.EventWorkflowOutput, func(e pipeline.Event) {
+			data, _ := e.Data.(map[string]interface{})
+			line, _ := data["line"].(string)
+			ch <- workflowProgressMsg{ch: ch, stepID: e.BlockID, output: true, line: line}
+		})
+		// The workflow's notifiers hear about the end of the run as they
+		// do from the command line, with failures shown as toasts.
+		engine := workflow.NewEngine(store, bus)
+		notifier := notify.New()
+		notifier.SetWarn(func(err error) {
+			ch <- workflowProgressMsg{ch: ch, warning: err}
+		})
+		notifier.SetURLResolver(engine.NotifierURL)
+		notifier.Init(bus, nil)
+
+		go func() {
+			result, err := engine.Run(ctx, wf)
+			ch <- workflowProgressMsg{ch: ch, done: true, result: result, err: err}
+			close(ch)
+		}()
+		return waitWorkflow(ch)()
+	}
+}
+
+func waitWorkflow(ch <-chan workflowProgressMsg) tea.Cmd {
+	return func() tea.Msg {
+		return <-ch
+	}
+}
@@ -47,28 +47,44 @@ func (s *CheckpointStore) InitSchema() error {
 		FOREIGN KEY (run_id) REFERENCES workflow_runs(id)
 	);
 
+	CREATE TABLE IF NOT EXISTS workflow_schedules (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		cron TEXT NOT NULL,
+		path TEXT NOT NULL,
+		created_at INTEGER NOT NULL,
+		last_run INTEGER,
+		last_run_id TEXT
+	);
+
 	CREATE INDEX IF NOT EXISTS idx_workflow_runs_status ON workflow_runs(status);
 	CREATE INDEX IF NOT EXISTS idx_step_results_run_id ON workflow_step_results(run_id);
 	`
 
-	_, err := s.db.Exec(schema)
-	return err
+	if _, err := s.db.Exec(schema); err != nil {
+		return err
+	}
+	_, _ = s.db.Exec("ALTER TABLE workflow_runs ADD COLUMN vars TEXT")
+	return nil
 }
 
 // SaveRun persists or updates a workflow run state.
 func (s *CheckpointStore) SaveRun(state *RunState) error {
 	query := `
 	INSERT OR REPLACE INTO workflow_runs 
-		(id, workflow_id, workflow_name, status, current_step, started_at, updated_at, completed_at, error)
-	VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)
+		(id, workflow_id, workflow_name, status, current_step, started_at, updated_at, completed_at, error, vars)
+	VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
 	`
 
 	var completedAt *time.Time
 	if !state.CompletedAt.IsZero() {
 		completedAt = &state.CompletedAt
 	}
+	vars, err := json.Marshal(state.Vars)
+	if err != nil {
+		return fmt.Errorf("marshal vars: %w", err)
+	}
 
-	_, err := s.db.Exec(query,
+	_, err = s.db.Exec(query,
 		state.RunID,
 		state.WorkflowID,
 		state.WorkflowName,
@@ -78,6 +94,7 @@ func (s *CheckpointStore) SaveRun(state *RunState) error {
 		state.UpdatedAt,
 		completedAt,
 		state.Error,
+		string(vars),
 	)
 
 	return err
@@ -115,7 +132,7 @@ func (s *CheckpointStore) SaveStepResult(runID string, result *StepResult) error
 // LoadRun retrieves a workflow run state by ID.
 func (s *CheckpointStore) LoadRun(runID string) (*RunState, error) {
 	query := `
-	SELECT id, workflow_id, workflow_name, status, current_step, started_at, updated_at, completed_at, error
+	SELECT id, workflow_id, workflow_name, status, current_step, started_at, updated_at, completed_at, error, vars
 	FROM workflow_runs WHERE id = ?
 	`
 
@@ -126,7 +143,7 @@ func (s *CheckpointStore) LoadRun(runID string) (*RunState, error) {
 	}
 
 	var completedAt sql.NullTime
-	var errStr sql.NullString
+	var errStr, vars sql.NullString
 	var status string
 
 	err := row.Scan(
@@ -139,6 +156,7 @@ func (s *CheckpointStore) LoadRun(runID string) (*RunState, error) {
 		&state.UpdatedAt,
 		&completedAt,
 		&errStr,
+		&vars,
 	)
 
 	if err == sql.ErrNoRows {
@@ -155,6 +173,12 @@ func (s *CheckpointStore) LoadRun(runID string) (*RunState, error) {
 	if errStr.Valid {
 		state.Error = errStr.String
 	}
+	state.Vars = make(map[string]string)
+	if vars.Valid {
+		if err := json.Unmarshal([]byte(vars.String), &state.Vars); err != nil {
+			return nil, fmt.Errorf("unmarshal vars: %w", err)
+		}
+	}
 
 	stepResults, err := s.LoadStepResults(runID)
 	if err != nil {
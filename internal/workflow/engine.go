@@ -1,10 +1,17 @@
 package workflow
 
 import (
+	"bytes"
 	"context"
 	"fmt"
+	"math/rand/v2"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
 	"time"
 
+	"dev-cli/internal/config"
 	"dev-cli/internal/executor"
 	"dev-cli/internal/pipeline"
 )
@@ -16,8 +23,22 @@ type Engine struct {
 	verbose  bool
 	safeCtx  *SafeModeContext
 	rollback *RollbackRegistry
+	approve  StepApproval
+	policy   *CommandPolicy
+
+	secretsFile       string
+	secretsPassphrase string
+
+	// ending holds the env, secrets included, of each run whose end is
+	// being published, for NotifierURL. It never goes into an event.
+	endingMu sync.Mutex
+	ending   map[string]map[string]string
 }
 
+// StepApproval is asked before each step that runs; a step it declines
+// is skipped. It should return false once ctx is done.
+type StepApproval func(ctx context.Context, step Step) bool
+
 // NewEngine creates a new workflow execution engine.
 func NewEngine(store *CheckpointStore, bus *pipeline.EventBus) *Engine {
 	return &Engine{
@@ -25,9 +46,19 @@ func NewEngine(store *CheckpointStore, bus *pipeline.EventBus) *Engine {
 		bus:      bus,
 		safeCtx:  NewSafeModeContext(),
 		rollback: NewRollbackRegistry(),
+
+		secretsFile:       config.Current.SecretsFile,
+		secretsPassphrase: config.Current.SecretsPassphrase,
 	}
 }
 
+// SetSecretsFile changes the encrypted file "file:" secrets are read from,
+// and its passphrase, from the configured ones.
+func (e *Engine) SetSecretsFile(path, passphrase string) {
+	e.secretsFile = path
+	e.secretsPassphrase = passphrase
+}
+
 // SetVerbose enables verbose logging.
 func (e *Engine) SetVerbose(v bool) {
 	e.verbose = v
@@ -38,6 +69,34 @@ func (e *Engine) SetSafeMode(ctx *SafeModeContext) {
 	e.safeCtx = ctx
 }
 
+// SetStepApproval makes the engine ask approve before running each step,
+// for runs a human confirms step by step.
+func (e *Engine) SetStepApproval(approve StepApproval) {
+	e.approve = approve
+}
+
+// SetCommandPolicy makes the engine check each step's shell and its
+// command and rollback, fully expanded, against policy before running
+// them; a refused step fails.
+func (e *Engine) SetCommandPolicy(policy CommandPolicy) {
+	e.policy = &policy
+}
+
+// checkPolicy returns why the engine's policy refuses to run command in
+// shell, or nil.
+func (e *Engine) checkPolicy(command, shell string) error {
+	if e.policy == nil {
+		return nil
+	}
+	if err := e.policy.CheckShell(shell); err != nil {
+		return fmt.Errorf("refused by policy: %w", err)
+	}
+	if err := e.policy.Check(command); err != nil {
+		return fmt.Errorf("refused by policy: %s: %w", command, err)
+	}
+	return nil
+}
+
 // GetSafeMode returns the current safe mode context.
 func (e *Engine) GetSafeMode() *SafeModeContext {
 	return e.safeCtx
@@ -59,9 +118,46 @@ type RunResult struct {
 
 // Run executes a workflow from the beginning.
 func (e *Engine) Run(ctx context.Context, wf *Workflow) (*RunResult, error) {
+	state, err := e.begin(wf)
+	if err != nil {
+		return nil, err
+	}
+	return e.executeSteps(ctx, wf, state)
+}
+
+// Start executes a workflow in the background. It returns the run ID once
+// the initial checkpoint is saved, so callers can poll the run with
+// CheckpointStore.LoadRun; the final result is sent on the channel.
+func (e *Engine) Start(ctx context.Context, wf *Workflow) (string, <-chan *RunResult, error) {
+	if e.store == nil {
+		return "", nil, fmt.Errorf("checkpoint store required for background runs")
+	}
+
+	state, err := e.begin(wf)
+	if err != nil {
+		return "", nil, err
+	}
+
+	runID := state.RunID
+	done := make(chan *RunResult, 1)
+	go func() {
+		result, _ := e.executeSteps(ctx, wf, state)
+		done <- result
+	}()
+	return runID, done, nil
+}
+
+// begin creates and checkpoints the state for a new run.
+func (e *Engine) begin(wf *Workflow) (*RunState, error) {
+	secrets, err := e.resolveSecrets(wf)
+	if err != nil {
+		return nil, err
+	}
+
 	runID := GenerateRunID()
 	state := NewRunState(runID, wf)
 	state.Status = StatusRunning
+	state.secrets = secrets
 
 	if e.store != nil {
 		if err := e.store.SaveRun(state); err != nil {
@@ -80,7 +176,7 @@ func (e *Engine) Run(ctx context.Context, wf *Workflow) (*RunResult, error) {
 		},
 	})
 
-	return e.executeSteps(ctx, wf, state)
+	return state, nil
 }
 
 // Resume continues execution of a paused or failed workflow.
@@ -97,6 +193,9 @@ func (e *Engine) Resume(ctx context.Context, wf *Workflow, runID string) (*RunRe
 	if state.Status != StatusPaused && state.Status != StatusFailed {
 		return nil, fmt.Errorf("cannot resume run with status: %s", state.Status)
 	}
+	if state.secrets, err = e.resolveSecrets(wf); err != nil {
+		return nil, err
+	}
 
 	state.Status = StatusRunning
 	state.UpdatedAt = time.Now()
@@ -118,6 +217,9 @@ func (e *Engine) Rollback(ctx context.Context, wf *Workflow, runID string) error
 	if err != nil {
 		return fmt.Errorf("failed to load run state: %w", err)
 	}
+	if state.secrets, err = e.resolveSecrets(wf); err != nil {
+		return err
+	}
 
 	return e.executeRollback(ctx, wf, state)
 }
@@ -125,30 +227,40 @@ func (e *Engine) Rollback(ctx context.Context, wf *Workflow, runID string) error
 // executeSteps runs workflow steps starting from the current position.
 func (e *Engine) executeSteps(ctx context.Context, wf *Workflow, state *RunState) (*RunResult, error) {
 	startTime := time.Now()
+	env := mergeEnv(wf.Env, state.secrets)
 
 	for i := state.CurrentStepIdx; i < len(wf.Steps); i++ {
-		select {
-		case <-ctx.Done():
-			state.Status = StatusPaused
-			state.UpdatedAt = time.Now()
+		state.CurrentStepIdx = i
+		if ctx.Err() != nil {
+			return e.pauseRun(ctx, state, startTime)
+		}
+
+		step, expandErr := expandStep(wf.Steps[i], env, state.Vars)
+
+		if expandErr == nil && ShouldSkip(&step, state.StepResults) {
+			result := &StepResult{
+				StepID:      step.ID,
+				Status:      StepSkipped,
+				StartedAt:   time.Now(),
+				CompletedAt: time.Now(),
+			}
+			state.SetStepResult(result)
+
 			if e.store != nil {
+				e.store.SaveStepResult(state.RunID, result)
 				e.store.SaveRun(state)
 			}
-			return &RunResult{
-				RunID:       state.RunID,
-				Status:      StatusPaused,
-				StepResults: state.StepResults,
-				Error:       "cancelled",
-				Duration:    time.Since(startTime),
-			}, ctx.Err()
-
-		default:
-		}
 
-		step := wf.Steps[i]
-		state.CurrentStepIdx = i
+			e.log("⏭ Skipping step: %s (condition not met)", step.Name)
+			e.publishStep(state, step, result)
+			continue
+		}
 
-		if ShouldSkip(&step, state.StepResults) {
+		if expandErr == nil && e.approve != nil && !e.approve(ctx, step) {
+			if ctx.Err() != nil {
+				// Cancelled while asking.
+				return e.pauseRun(ctx, state, startTime)
+			}
 			result := &StepResult{
 				StepID:      step.ID,
 				Status:      StepSkipped,
@@ -162,11 +274,31 @@ func (e *Engine) executeSteps(ctx context.Context, wf *Workflow, state *RunState
 				e.store.SaveRun(state)
 			}
 
-			e.log("⏭ Skipping step: %s (condition not met)", step.Name)
+			e.log("⏭ Skipping step: %s (declined)", step.Name)
+			e.publishStep(state, step, result)
 			continue
 		}
 
-		result := e.executeStep(ctx, &step, wf.Env, state)
+		var result *StepResult
+		if expandErr != nil {
+			result = &StepResult{
+				StepID:      step.ID,
+				Status:      StepFailed,
+				Error:       expandErr.Error(),
+				StartedAt:   time.Now(),
+				CompletedAt: time.Now(),
+			}
+			e.log("✗ Step failed: %s: %v", step.Name, expandErr)
+		} else if len(step.Matrix) > 0 {
+			e.publishStep(state, step, &StepResult{StepID: step.ID, Status: StepRunning})
+			result = e.executeMatrix(ctx, &step, env, state)
+		} else {
+			result = e.executeStep(ctx, &step, env, state)
+		}
+		if result.Status == StepFailed && ctx.Err() != nil {
+			// Interrupted, not failed: the step runs again on resume.
+			return e.pauseRun(ctx, state, startTime)
+		}
 		state.SetStepResult(result)
 
 		if e.store != nil {
@@ -174,22 +306,10 @@ func (e *Engine) executeSteps(ctx context.Context, wf *Workflow, state *RunState
 			e.store.SaveRun(state)
 		}
 
-		e.publishEvent(pipeline.Event{
-			Type:      pipeline.EventType("workflow.step"),
-			Timestamp: time.Now(),
-			Source:    "workflow",
-			BlockID:   step.ID,
-			Data: map[string]interface{}{
-				"run_id":    state.RunID,
-				"step_id":   step.ID,
-				"step_name": step.Name,
-				"status":    string(result.Status),
-				"exit_code": result.ExitCode,
-			},
-		})
+		e.publishStep(state, step, result)
 
 		if result.Status == StepFailed {
-			action := e.determineFailureAction(wf, &step)
+			action := determineFailureAction(wf, &step)
 
 			switch action {
 			case FailureRollback:
@@ -203,6 +323,7 @@ func (e *Engine) executeSteps(ctx context.Context, wf *Workflow, state *RunState
 				if e.store != nil {
 					e.store.SaveRun(state)
 				}
+				e.publishEnd(wf, state, time.Since(startTime))
 				return &RunResult{
 					RunID:       state.RunID,
 					Status:      StatusRolledBack,
@@ -218,6 +339,7 @@ func (e *Engine) executeSteps(ctx context.Context, wf *Workflow, state *RunState
 				if e.store != nil {
 					e.store.SaveRun(state)
 				}
+				e.publishEnd(wf, state, time.Since(startTime))
 				return &RunResult{
 					RunID:       state.RunID,
 					Status:      StatusFailed,
@@ -246,16 +368,7 @@ func (e *Engine) executeSteps(ctx context.Context, wf *Workflow, state *RunState
 		e.store.SaveRun(state)
 	}
 
-	e.publishEvent(pipeline.Event{
-		Type:      pipeline.EventType("workflow.complete"),
-		Timestamp: time.Now(),
-		Source:    "workflow",
-		Data: map[string]interface{}{
-			"run_id":   state.RunID,
-			"status":   string(StatusCompleted),
-			"duration": time.Since(startTime).String(),
-		},
-	})
+	e.publishEnd(wf, state, time.Since(startTime))
 
 	return &RunResult{
 		RunID:       state.RunID,
@@ -265,6 +378,23 @@ func (e *Engine) executeSteps(ctx context.Context, wf *Workflow, state *RunState
 	}, nil
 }
 
+// pauseRun checkpoints the run as paused at its current step, which runs
+// first when the run is resumed.
+func (e *Engine) pauseRun(ctx context.Context, state *RunState, startTime time.Time) (*RunResult, error) {
+	state.Status = StatusPaused
+	state.UpdatedAt = time.Now()
+	if e.store != nil {
+		e.store.SaveRun(state)
+	}
+	return &RunResult{
+		RunID:       state.RunID,
+		Status:      StatusPaused,
+		StepResults: state.StepResults,
+		Error:       "cancelled",
+		Duration:    time.Since(startTime),
+	}, ctx.Err()
+}
+
 // executeStep runs a single step with retries.
 func (e *Engine) executeStep(ctx context.Context, step *Step, env map[string]string, state *RunState) *StepResult {
 	result := &StepResult{
@@ -273,15 +403,23 @@ func (e *Engine) executeStep(ctx context.Context, step *Step, env map[string]str
 		StartedAt: time.Now(),
 	}
 
-	maxRetries := step.Retries
-	if maxRetries == 0 {
-		maxRetries = 1
+	if err := e.checkPolicy(step.Command, step.Shell); err != nil {
+		e.log("✗ Step refused: %s: %v", step.Name, err)
+		result.Status = StepFailed
+		result.ExitCode = -1
+		result.Error = state.mask(err.Error())
+		result.CompletedAt = time.Now()
+		return result
 	}
 
-	for attempt := 0; attempt < maxRetries; attempt++ {
-		result.Retries = attempt
+	policy := step.retryPolicy()
+	attempts := 0
+	for attempts < policy.Attempts {
+		result.Retries = attempts
+		attempts++
 
-		e.log("▶ Running step: %s (attempt %d/%d)", step.Name, attempt+1, maxRetries)
+		e.log("▶ Running step: %s (attempt %d/%d)", step.Name, attempts, policy.Attempts)
+		e.publishStep(state, *step, result)
 
 		stepCtx := ctx
 		if step.Timeout > 0 {
@@ -290,10 +428,24 @@ func (e *Engine) executeStep(ctx context.Context, step *Step, env map[string]str
 			defer cancel()
 		}
 
-		execResult := executor.ExecuteWithContext(stepCtx, step.Command)
+		opts := stepOptions(step, env)
+		var stdout, stderr *stepOutput
+		if e.bus != nil {
+			publish := func(line string) { e.publishOutput(state, step, line) }
+			stdout, stderr = &stepOutput{publish: publish}, &stepOutput{publish: publish}
+			opts.Stdout, opts.Stderr = stdout, stderr
+		}
+		execResult := executor.ExecuteWithOptions(stepCtx, step.shellCommand(), opts)
+		if e.bus != nil {
+			stdout.flush()
+			stderr.flush()
+		}
+		if step.Register != "" {
+			state.SetVar(step.Register, state.mask(execResult.Stdout))
+		}
 
 		result.ExitCode = execResult.ExitCode
-		result.Output = execResult.Output
+		result.Output = state.mask(execResult.Output)
 		result.Duration = execResult.Duration
 		result.CompletedAt = time.Now()
 
@@ -305,14 +457,25 @@ func (e *Engine) executeStep(ctx context.Context, step *Step, env map[string]str
 
 		e.log("✗ Step failed (exit %d): %s", execResult.ExitCode, step.Name)
 
-		if attempt < maxRetries-1 {
-			e.log("  Retrying in 2 seconds...")
-			time.Sleep(2 * time.Second)
+		if attempts == policy.Attempts {
+			break
+		}
+		if !policy.retryable(execResult.ExitCode) {
+			e.log("  Exit code %d is not retried", execResult.ExitCode)
+			break
+		}
+		delay := policy.delay(attempts, rand.Float64())
+		e.log("  Retrying in %s...", delay.Round(time.Millisecond))
+		if !waitRetry(ctx, delay) {
+			break
 		}
 	}
 
 	result.Status = StepFailed
-	result.Error = fmt.Sprintf("step failed with exit code %d after %d attempts", result.ExitCode, maxRetries)
+	result.Error = fmt.Sprintf("step failed with exit code %d after %d attempts", result.ExitCode, attempts)
+	if attempts == 1 {
+		result.Error = fmt.Sprintf("step failed with exit code %d", result.ExitCode)
+	}
 	return result
 }
 
@@ -342,6 +505,26 @@ func (e *Engine) executeRollback(ctx context.Context, wf *Workflow, state *RunSt
 
 		e.log("↺ Rolling back: %s", step.Name)
 
+		env := mergeEnv(wf.Env, state.secrets)
+		expanded, err := expandStep(step, env, state.Vars)
+		if err != nil {
+			e.log("⚠ Rollback failed for %s: %v", step.Name, err)
+			continue
+		}
+		command, err := Interpolate(step.Rollback.Command, state.Vars, mergeEnv(env, step.Env))
+		if err == nil {
+			err = e.checkPolicy(command, expanded.Shell)
+		}
+		var run string
+		var runEnv map[string]string
+		if err == nil {
+			run, runEnv, err = shellRefs(step.Rollback.Command, state.Vars, mergeEnv(env, step.Env))
+		}
+		if err != nil {
+			e.log("⚠ Rollback failed for %s: %v", step.Name, state.mask(err.Error()))
+			continue
+		}
+
 		rollbackCtx := ctx
 		if step.Rollback.Timeout > 0 {
 			var cancel context.CancelFunc
@@ -349,10 +532,12 @@ func (e *Engine) executeRollback(ctx context.Context, wf *Workflow, state *RunSt
 			defer cancel()
 		}
 
-		result := executor.ExecuteWithContext(rollbackCtx, step.Rollback.Command)
+		opts := stepOptions(&expanded, env)
+		opts.Env = mergeEnv(opts.Env, runEnv)
+		result := executor.ExecuteWithOptions(rollbackCtx, run, opts)
 
 		if result.ExitCode != 0 {
-			e.log("⚠ Rollback failed for %s: %s", step.Name, result.Output)
+			e.log("⚠ Rollback failed for %s: %s", step.Name, state.mask(result.Output))
 		} else {
 			e.log("✓ Rolled back: %s", step.Name)
 
@@ -368,8 +553,27 @@ func (e *Engine) executeRollback(ctx context.Context, wf *Workflow, state *RunSt
 	return nil
 }
 
+// stepOptions is where and how to run an expanded step's commands: in its
+// dir (with ~ for the home directory), with its shell, and with the
+// workflow's env, its own and the values its command refers to.
+func stepOptions(step *Step, wfEnv map[string]string) executor.Options {
+	dir := step.WorkDir
+	if home, err := os.UserHomeDir(); err == nil {
+		if dir == "~" {
+			dir = home
+		} else if rest, ok := strings.CutPrefix(dir, "~/"); ok {
+			dir = filepath.Join(home, rest)
+		}
+	}
+	return executor.Options{
+		Dir:   dir,
+		Shell: step.Shell,
+		Env:   mergeEnv(mergeEnv(wfEnv, step.Env), step.runEnv),
+	}
+}
+
 // determineFailureAction returns the action to take on step failure.
-func (e *Engine) determineFailureAction(wf *Workflow, step *Step) FailureAction {
+func determineFailureAction(wf *Workflow, step *Step) FailureAction {
 
 	if step.OnFailure != "" {
 		switch step.OnFailure {
@@ -399,6 +603,126 @@ func (e *Engine) findStepIndex(wf *Workflow, stepID string) int {
 	return -1
 }
 
+// publishStep announces a step starting (status running, once per
+// attempt) or its outcome.
+func (e *Engine) publishStep(state *RunState, step Step, result *StepResult) {
+	e.publishEvent(pipeline.Event{
+		Type:      pipeline.EventWorkflowStep,
+		Timestamp: time.Now(),
+		Source:    "workflow",
+		BlockID:   step.ID,
+		Data: map[string]interface{}{
+			"run_id":    state.RunID,
+			"step_id":   step.ID,
+			"step_name": state.mask(step.Name),
+			"status":    string(result.Status),
+			"exit_code": result.ExitCode,
+			"attempt":   result.Retries + 1,
+			"output":    result.Output,
+			"error":     result.Error,
+			"duration":  result.Duration,
+		},
+	})
+}
+
+// publishOutput announces a line a running step wrote.
+func (e *Engine) publishOutput(state *RunState, step *Step, line string) {
+	e.publishEvent(pipeline.Event{
+		Type:      pipeline.EventWorkflowOutput,
+		Timestamp: time.Now(),
+		Source:    "workflow",
+		BlockID:   step.ID,
+		Data: map[string]interface{}{
+			"run_id":  state.RunID,
+			"step_id": step.ID,
+			"line":    state.mask(line),
+		},
+	})
+}
+
+// stepOutput hands what a step writes to stdout or stderr to publish a
+// line at a time, so a secret is never split across two events and
+// escapes masking.
+type stepOutput struct {
+	publish func(line string)
+	partial []byte
+}
+
+func (o *stepOutput) Write(p []byte) (int, error) {
+	o.partial = append(o.partial, p...)
+	for {
+		i := bytes.IndexByte(o.partial, '\n')
+		if i < 0 {
+			break
+		}
+		o.publish(strings.TrimSuffix(string(o.partial[:i]), "\r"))
+		o.partial = o.partial[i+1:]
+	}
+	return len(p), nil
+}
+
+// flush publishes the last line when the output didn't end with one.
+func (o *stepOutput) flush() {
+	if len(o.partial) > 0 {
+		o.publish(string(o.partial))
+		o.partial = nil
+	}
+}
+
+// publishEnd announces a finished run, as workflow.complete when it
+// completed and workflow.failed otherwise, with what notifiers need to
+// report it.
+func (e *Engine) publishEnd(wf *Workflow, state *RunState, duration time.Duration) {
+	eventType := pipeline.EventWorkflowFailed
+	if state.Status == StatusCompleted {
+		eventType = pipeline.EventWorkflowComplete
+	}
+	steps := make(map[StepStatus]int)
+	for _, step := range wf.Steps {
+		if r := state.StepResults[step.ID]; r != nil {
+			steps[r.Status]++
+		}
+	}
+	// Handlers run before Publish returns, so the env is only held for
+	// as long as notifiers can ask for it.
+	e.endingMu.Lock()
+	if e.ending == nil {
+		e.ending = make(map[string]map[string]string)
+	}
+	e.ending[state.RunID] = mergeEnv(wf.Env, state.secrets)
+	e.endingMu.Unlock()
+	defer func() {
+		e.endingMu.Lock()
+		delete(e.ending, state.RunID)
+		e.endingMu.Unlock()
+	}()
+
+	e.publishEvent(pipeline.Event{
+		Type:      eventType,
+		Timestamp: time.Now(),
+		Source:    "workflow",
+		Data: map[string]interface{}{
+			"run_id":        state.RunID,
+			"workflow_name": wf.Name,
+			"status":        string(state.Status),
+			"duration":      duration.Round(time.Millisecond).String(),
+			"error":         state.mask(state.Error),
+			"steps":         steps,
+			"notify":        wf.Notify,
+		},
+	})
+}
+
+// NotifierURL resolves n's URL against the env and secrets of run runID.
+// It works while the run's workflow.complete or workflow.failed event is
+// being handled, which is when notifiers send.
+func (e *Engine) NotifierURL(runID string, n Notifier) (string, error) {
+	e.endingMu.Lock()
+	env := e.ending[runID]
+	e.endingMu.Unlock()
+	return Interpolate(n.URL, nil, env)
+}
+
 // publishEvent sends an event to the event bus if available.
 func (e *Engine) publishEvent(event pipeline.Event) {
 	if e.bus != nil {
@@ -0,0 +1,474 @@
+package workflow
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"dev-cli/internal/pipeline"
+	"dev-cli/internal/secrets"
+	"dev-cli/internal/storage"
+)
+
+func TestEngine_StartCanBePolled(t *testing.T) {
+	db, err := storage.OpenDB(filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	store := NewCheckpointStore(db)
+	if err := store.InitSchema(); err != nil {
+		t.Fatal(err)
+	}
+
+	wf := &Workflow{
+		ID:    "wf_poll",
+		Name:  "Poll me",
+		Steps: []Step{{ID: "hello", Name: "Hello", Command: "echo hello"}},
+	}
+
+	engine := NewEngine(store, nil)
+	runID, done, err := engine.Start(context.Background(), wf)
+	if err != nil {
+		t.Fatalf("Start failed: %v", err)
+	}
+
+	state, err := store.LoadRun(runID)
+	if err != nil {
+		t.Fatalf("run should be checkpointed before Start returns: %v", err)
+	}
+	if state.WorkflowName != "Poll me" {
+		t.Errorf("unexpected workflow name %q", state.WorkflowName)
+	}
+
+	select {
+	case result := <-done:
+		if result.RunID != runID || result.Status != StatusCompleted {
+			t.Fatalf("unexpected result: %+v", result)
+		}
+	case <-time.After(30 * time.Second):
+		t.Fatal("run did not finish")
+	}
+
+	state, err = store.LoadRun(runID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if state.Status != StatusCompleted || state.StepResults["hello"] == nil {
+		t.Errorf("polled state not final: %+v", state)
+	}
+}
+
+func TestEngine_StartRequiresStore(t *testing.T) {
+	wf := &Workflow{Name: "x", Steps: []Step{{ID: "a", Command: "true"}}}
+	if _, _, err := NewEngine(nil, nil).Start(context.Background(), wf); err == nil {
+		t.Error("expected error without a checkpoint store")
+	}
+}
+
+func TestEngine_StepApproval(t *testing.T) {
+	wf := &Workflow{
+		ID:   "wf_approve",
+		Name: "Approve me",
+		Steps: []Step{
+			{ID: "first", Name: "First", Command: "echo one"},
+			{ID: "second", Name: "Second", Command: "echo two"},
+			{ID: "third", Name: "Third", Command: "echo three"},
+		},
+	}
+
+	var asked []string
+	engine := NewEngine(nil, nil)
+	engine.SetStepApproval(func(ctx context.Context, step Step) bool {
+		asked = append(asked, step.ID)
+		return step.ID != "second"
+	})
+	result, err := engine.Run(context.Background(), wf)
+	if err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+	if len(asked) != 3 {
+		t.Errorf("expected every step to be asked about, got %v", asked)
+	}
+	if result.Status != StatusCompleted {
+		t.Fatalf("expected completed, got %s", result.Status)
+	}
+	for id, want := range map[string]StepStatus{"first": StepSuccess, "second": StepSkipped, "third": StepSuccess} {
+		if r := result.StepResults[id]; r == nil || r.Status != want {
+			t.Errorf("step %s: expected %s, got %+v", id, want, r)
+		}
+	}
+
+	// Cancelling while asking pauses the run at that step.
+	ctx, cancel := context.WithCancel(context.Background())
+	engine.SetStepApproval(func(ctx context.Context, step Step) bool {
+		if step.ID == "second" {
+			cancel()
+			return false
+		}
+		return true
+	})
+	result, _ = engine.Run(ctx, wf)
+	if result.Status != StatusPaused {
+		t.Fatalf("expected paused, got %s", result.Status)
+	}
+	if r := result.StepResults["second"]; r != nil {
+		t.Errorf("expected the cancelled step not to be recorded, got %+v", r)
+	}
+
+	// The last step too: there's no next step to notice the cancel.
+	ctx, cancel = context.WithCancel(context.Background())
+	engine.SetStepApproval(func(ctx context.Context, step Step) bool {
+		if step.ID == "third" {
+			cancel()
+			return false
+		}
+		return true
+	})
+	if result, _ = engine.Run(ctx, wf); result.Status != StatusPaused {
+		t.Fatalf("expected a cancel at the last step to pause, got %s", result.Status)
+	}
+
+	// A step interrupted while it runs pauses the run rather than failing it.
+	engine.SetStepApproval(nil)
+	ctx, cancel = context.WithTimeout(context.Background(), 200*time.Millisecond)
+	defer cancel()
+	result, _ = engine.Run(ctx, &Workflow{Name: "Interrupted", Steps: []Step{{ID: "wait", Name: "Wait", Command: "exec sleep 30"}}})
+	if result.Status != StatusPaused || result.StepResults["wait"] != nil {
+		t.Errorf("expected the interrupted step to pause the run, got %s with %+v", result.Status, result.StepResults["wait"])
+	}
+}
+
+func TestEngine_RegisteredVars(t *testing.T) {
+	db, err := storage.OpenDB(filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	store := NewCheckpointStore(db)
+	if err := store.InitSchema(); err != nil {
+		t.Fatal(err)
+	}
+
+	wf, err := Parse([]byte(`
+name: Pass data
+env:
+  GREETING: hello
+steps:
+  - id: version
+    command: echo 1.2.3; echo noise >&2
+    register: version
+  - id: tag
+    command: echo "${{ env.GREETING }} v${{ vars.version }}"
+    register: tag
+  - id: release
+    command: echo released
+    condition:
+      type: output_contains
+      value: v${{ vars.version }}
+      step_ref: tag
+  - id: skipped
+    command: echo never
+    condition:
+      type: output_contains
+      value: ${{ vars.tag }}!
+      step_ref: release
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	result, err := NewEngine(store, nil).Run(context.Background(), wf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r := result.StepResults["tag"]; r == nil || r.Output != "hello v1.2.3" {
+		t.Errorf("expected the registered stdout in the next command, got %+v", r)
+	}
+	for id, want := range map[string]StepStatus{"release": StepSuccess, "skipped": StepSkipped} {
+		if r := result.StepResults[id]; r == nil || r.Status != want {
+			t.Errorf("step %s: expected %s, got %+v", id, want, r)
+		}
+	}
+
+	// The vars are checkpointed, so a resumed run still has them.
+	state, err := store.LoadRun(result.RunID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if state.Vars["version"] != "1.2.3" || state.Vars["tag"] != "hello v1.2.3" {
+		t.Errorf("expected the vars checkpointed, got %v", state.Vars)
+	}
+}
+
+func TestEngine_Matrix(t *testing.T) {
+	db, err := storage.OpenDB(filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	store := NewCheckpointStore(db)
+	if err := store.InitSchema(); err != nil {
+		t.Fatal(err)
+	}
+
+	wf, err := Parse([]byte(`
+name: Matrix
+on_failure:
+  action: continue
+steps:
+  - id: build
+    command: echo "${{ matrix.dir }} on ${{ matrix.node }}"
+    matrix:
+      node: ["18", "20"]
+      dir: [api, web]
+    max_parallel: 2
+  - id: test
+    command: test ${{ matrix.node }} != 20
+    matrix:
+      node: ["18", "20"]
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	result, err := NewEngine(store, nil).Run(context.Background(), wf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r := result.StepResults["build[dir=web,node=20]"]; r == nil || r.Status != StepSuccess || r.Output != "web on 20" {
+		t.Errorf("expected a result per combination, got %+v", r)
+	}
+	if r := result.StepResults["build"]; r == nil || r.Status != StepSuccess || !strings.Contains(r.Output, "[dir=api,node=18]\napi on 18") {
+		t.Errorf("expected the combined build result, got %+v", r)
+	}
+
+	if r := result.StepResults["test[node=18]"]; r == nil || r.Status != StepSuccess {
+		t.Errorf("expected the passing combination to succeed on its own, got %+v", r)
+	}
+	r := result.StepResults["test"]
+	if r == nil || r.Status != StepFailed || r.ExitCode != 1 || !strings.Contains(r.Error, "1 of 2 matrix combinations failed: node=20") {
+		t.Errorf("expected the step to fail with its failing combination, got %+v", r)
+	}
+
+	state, err := store.LoadRun(result.RunID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if state.StepResults["test[node=20]"] == nil {
+		t.Error("expected the combination results checkpointed")
+	}
+}
+
+func TestEngine_StepDirShellEnv(t *testing.T) {
+	root := t.TempDir()
+	for _, dir := range []string{"api", "web"} {
+		if err := os.Mkdir(filepath.Join(root, dir), 0o755); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	wf, err := Parse([]byte(`
+name: Repos
+env:
+  STAGE: prod
+steps:
+  - id: where
+    command: basename "$(pwd)"
+    dir: ` + root + `/${{ matrix.repo }}
+    matrix:
+      repo: [api, web]
+  - id: env
+    command: echo "$STAGE $REGION $0"
+    shell: sh
+    workdir: ` + root + `
+    env:
+      REGION: eu
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	result, err := NewEngine(nil, nil).Run(context.Background(), wf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, repo := range []string{"api", "web"} {
+		if r := result.StepResults["where[repo="+repo+"]"]; r == nil || r.Output != repo {
+			t.Errorf("expected the step to run in %s, got %+v", repo, r)
+		}
+	}
+	if r := result.StepResults["env"]; r == nil || r.Output != "prod eu sh" {
+		t.Errorf("expected the workflow and step env under sh, got %+v", r)
+	}
+}
+
+func TestEngine_Secrets(t *testing.T) {
+	db, err := storage.OpenDB(filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	store := NewCheckpointStore(db)
+	if err := store.InitSchema(); err != nil {
+		t.Fatal(err)
+	}
+
+	secretsFile := filepath.Join(t.TempDir(), "secrets.enc")
+	if err := secrets.SaveFile(secretsFile, "pass", map[string]string{"api_token": "s3cr3t-value"}); err != nil {
+		t.Fatal(err)
+	}
+
+	wf, err := Parse([]byte(`
+name: Secrets
+secrets:
+  TOKEN: file:api_token
+steps:
+  - id: use
+    command: test "$TOKEN" = s3cr3t-value && echo "sent ${{ env.TOKEN }}"
+    register: echoed
+  - id: leak
+    command: echo "again ${{ vars.echoed }}"
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	engine := NewEngine(store, nil)
+	engine.SetSecretsFile(secretsFile, "pass")
+	result, err := engine.Run(context.Background(), wf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r := result.StepResults["use"]; r == nil || r.Status != StepSuccess || r.Output != "sent ***" {
+		t.Errorf("expected the secret in the env and masked in the output, got %+v", r)
+	}
+
+	state, err := store.LoadRun(result.RunID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r := state.StepResults["leak"]; r == nil || r.Output != "again sent ***" {
+		t.Errorf("expected the stored output masked, got %+v", r)
+	}
+	if strings.Contains(state.Vars["echoed"], "s3cr3t") {
+		t.Errorf("expected the checkpointed vars masked, got %v", state.Vars)
+	}
+
+	engine.SetSecretsFile(secretsFile, "wrong")
+	if _, err := engine.Run(context.Background(), wf); err == nil {
+		t.Error("expected a run whose secrets can't be read not to start")
+	}
+}
+
+func TestEngine_PublishesProgress(t *testing.T) {
+	wf, err := Parse([]byte(`
+name: Progress
+steps:
+  - id: greet
+    command: echo one; echo two >&2; printf three
+  - id: never
+    command: echo no
+    condition:
+      type: env_set
+      value: DEV_CLI_TEST_UNSET_VAR
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	bus := pipeline.NewEventBus()
+	var mu sync.Mutex
+	var events []string
+	record := func(e pipeline.Event) {
+		data := e.Data.(map[string]interface{})
+		mu.Lock()
+		defer mu.Unlock()
+		if e.Type == pipeline.EventWorkflowOutput {
+			events = append(events, e.BlockID+" > "+data["line"].(string))
+		} else {
+			events = append(events, e.BlockID+" "+data["status"].(string))
+		}
+	}
+	bus.Subscribe(pipeline.EventWorkflowStep, record)
+	bus.Subscribe(pipeline.EventWorkflowOutput, record)
+
+	if _, err := NewEngine(nil, bus).Run(context.Background(), wf); err != nil {
+		t.Fatal(err)
+	}
+	// stdout and stderr are copied separately, so "one" and "two" may
+	// come in either order.
+	if len(events) >= 3 {
+		slices.Sort(events[1:3])
+	}
+	want := []string{"greet running", "greet > one", "greet > two", "greet > three", "greet success", "never skipped"}
+	if !slices.Equal(events, want) {
+		t.Errorf("expected events %q, got %q", want, events)
+	}
+}
+
+func TestEngine_VarsAreNotShellSyntax(t *testing.T) {
+	dir := t.TempDir()
+	// The value a step registers ends up in later commands; it must come
+	// through as data, never run.
+	wf, err := Parse([]byte(`
+name: Untrusted output
+steps:
+  - id: fetch
+    command: printf '%s' 'a; touch pwned $(touch pwned2) "q" * '"'"'s'"'"''
+    dir: ` + dir + `
+    register: payload
+  - id: bare
+    command: printf '[%s]' ${{ vars.payload }}
+    dir: ` + dir + `
+  - id: double
+    command: printf '%s' "<${{ vars.payload }}>"
+    dir: ` + dir + `
+  - id: single
+    command: printf '%s' '<${{ vars.payload }}>'
+    dir: ` + dir + `
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	result, err := NewEngine(nil, nil).Run(context.Background(), wf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	payload := `a; touch pwned $(touch pwned2) "q" * 's'`
+	for id, want := range map[string]string{
+		"bare":   "[" + payload + "]",
+		"double": "<" + payload + ">",
+		"single": "<" + payload + ">",
+	} {
+		if r := result.StepResults[id]; r == nil || r.Output != want {
+			t.Errorf("step %s: expected %q, got %+v", id, want, r)
+		}
+	}
+	for _, name := range []string{"pwned", "pwned2"} {
+		if _, err := os.Stat(filepath.Join(dir, name)); err == nil {
+			t.Errorf("expected the registered output not run, but %s exists", name)
+		}
+	}
+}
+
+func TestInterpolate(t *testing.T) {
+	t.Setenv("DEV_CLI_TEST_REGION", "eu-west-1")
+	vars := map[string]string{"id": "abc"}
+	env := map[string]string{"STAGE": "prod"}
+
+	got, err := Interpolate("deploy ${{vars.id}} to ${{ env.STAGE }} in ${{ env.DEV_CLI_TEST_REGION }}", vars, env)
+	if err != nil || got != "deploy abc to prod in eu-west-1" {
+		t.Errorf("Interpolate = %q, %v", got, err)
+	}
+	if _, err := Interpolate("echo ${{ vars.missing }}", vars, env); err == nil {
+		t.Error("expected an unset variable to be an error")
+	}
+}
@@ -0,0 +1,199 @@
+package workflow
+
+import (
+	"cmp"
+	"fmt"
+	"math"
+	"slices"
+	"strings"
+	"time"
+)
+
+// Export converts wf into a starting point for running it elsewhere: a
+// GitHub Actions workflow ("gha") or a Makefile ("make"). Steps keep their
+// dir, env, shell, file and env conditions, retries and registered vars;
+// what doesn't translate is returned as notes, which also head the output
+// as TODO comments.
+func Export(wf *Workflow, format string) ([]byte, []string, error) {
+	x := &exporter{wf: wf}
+	x.noteUnsupported()
+	switch format {
+	case "gha":
+		return x.gha()
+	case "make":
+		return x.makefile(), x.notes, nil
+	}
+	return nil, nil, fmt.Errorf("unknown export format %q: want gha or make", format)
+}
+
+// exporter collects what doesn't translate while converting a workflow.
+type exporter struct {
+	wf    *Workflow
+	notes []string
+}
+
+func (x *exporter) note(format string, args ...any) {
+	if n := fmt.Sprintf(format, args...); !slices.Contains(x.notes, n) {
+		x.notes = append(x.notes, n)
+	}
+}
+
+// noteUnsupported notes what neither format can express.
+func (x *exporter) noteUnsupported() {
+	for _, step := range x.wf.Steps {
+		if c := step.Condition; c != nil && (c.Type == CondOutputContains || c.Type == CondOutputMatches) {
+			x.note("step %q: its %s condition is not exported, so it always runs", step.ID, c.Type)
+		}
+		if step.OnSuccess != "" {
+			x.note("step %q: on_success jumps are not exported; steps run in file order", step.ID)
+		}
+		if step.Retry != nil && len(step.Retry.OnExitCodes) > 0 {
+			x.note("step %q: retries every failure, not only exit codes %v", step.ID, step.Retry.OnExitCodes)
+		}
+	}
+	if len(x.wf.Notify) > 0 {
+		x.note("notify is not exported; use the CI system's own notifications")
+	}
+}
+
+// header is the comment the exported file starts with.
+func (x *exporter) header() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "# %s, exported from a dev-cli workflow as a starting point.\n", x.wf.Name)
+	if x.wf.Description != "" {
+		fmt.Fprintf(&b, "# %s\n", strings.ReplaceAll(strings.TrimSpace(x.wf.Description), "\n", "\n# "))
+	}
+	for _, n := range x.notes {
+		fmt.Fprintf(&b, "# TODO: %s\n", n)
+	}
+	return b.String()
+}
+
+// exportedStep is a step as it is exported: a matrix step becomes one per
+// combination, with its matrix expressions expanded.
+type exportedStep struct {
+	Step
+	// matrix is the ID of the matrix step this is a combination of, and
+	// label the combination, like "dir=api,node=18".
+	matrix, label string
+	action        FailureAction
+}
+
+// title is what the step is called in the export.
+func (s exportedStep) title() string {
+	name := cmp.Or(s.Name, s.ID)
+	if s.label != "" {
+		name += " (" + s.label + ")"
+	}
+	return name
+}
+
+// steps returns the workflow's steps with matrix steps unrolled.
+func (x *exporter) steps() []exportedStep {
+	var out []exportedStep
+	for _, step := range x.wf.Steps {
+		action := determineFailureAction(x.wf, &step)
+		if len(step.Matrix) == 0 {
+			out = append(out, exportedStep{Step: step, action: action})
+			continue
+		}
+		for _, combo := range matrixCombos(step.Matrix) {
+			s := step
+			s.Command = expandMatrix(step.Command, combo.values)
+			s.WorkDir = expandMatrix(step.WorkDir, combo.values)
+			out = append(out, exportedStep{Step: s, matrix: step.ID, label: combo.label, action: action})
+		}
+	}
+	return out
+}
+
+// script is the shell script that runs step the way the engine would: in
+// its dir, skipped unless its file or env condition holds, and retried by
+// its policy. register wraps a command to capture its stdout under name.
+// ${{ }} expressions are left for the format to rewrite.
+func script(step Step, register func(command, name string) string) string {
+	var b strings.Builder
+	if c := step.Condition; c != nil {
+		switch c.Type {
+		case CondFileExists:
+			fmt.Fprintf(&b, "[ -e %s ] || exit 0\n", quoteExprs(c.Value))
+		case CondEnvSet:
+			fmt.Fprintf(&b, "printenv %s >/dev/null || exit 0\n", quoteExprs(c.Value))
+		}
+	}
+	if step.WorkDir != "" {
+		fmt.Fprintf(&b, "cd %s\n", quoteExprs(step.WorkDir))
+	}
+	command := strings.TrimRight(step.Command, "\n")
+	if p := step.retryPolicy(); p.Attempts > 1 {
+		command = retryLoop(command, p)
+	}
+	if step.Register != "" {
+		command = register(command, step.Register)
+	}
+	b.WriteString(command)
+	return b.String()
+}
+
+// retryLoop runs command until it succeeds, at most p.Attempts times, with
+// the policy's doubling delay (in whole seconds, without jitter) between
+// attempts.
+func retryLoop(command string, p RetryPolicy) string {
+	seconds := func(d time.Duration) int { return max(int(math.Ceil(d.Seconds())), 1) }
+	next := fmt.Sprintf("delay * 2 > %[1]d ? %[1]d : delay * 2", seconds(p.maxDelay()))
+	return fmt.Sprintf(`attempt=1
+delay=%d
+until (
+%s
+); do
+  status=$?
+  [ "$attempt" -lt %d ] || exit "$status"
+  sleep "$delay"
+  attempt=$((attempt + 1))
+  delay=$((%s))
+done`, seconds(p.Backoff), command, p.Attempts, next)
+}
+
+// quoteExprs double-quotes s for the shell, keeping its ${{ }} expressions
+// for the format to rewrite. A leading ~ stays outside the quotes, so the
+// shell expands it as the engine does.
+func quoteExprs(s string) string {
+	prefix := ""
+	if s == "~" || strings.HasPrefix(s, "~/") {
+		prefix, s = "~", s[1:]
+	}
+	var b strings.Builder
+	last := 0
+	for _, loc := range templateExpr.FindAllStringIndex(s, -1) {
+		b.WriteString(dquoteEscaper.Replace(s[last:loc[0]]))
+		b.WriteString(s[loc[0]:loc[1]])
+		last = loc[1]
+	}
+	b.WriteString(dquoteEscaper.Replace(s[last:]))
+	return prefix + `"` + b.String() + `"`
+}
+
+var dquoteEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, `$`, `\$`, "`", "\\`")
+
+// shellQuote single-quotes s for the shell.
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
+// rewriteExprs rewrites s for a format: the text around ${{ }} expressions
+// through literal, and each expression through expr.
+func rewriteExprs(s string, literal func(string) string, expr func(scope, name string) string) string {
+	var b strings.Builder
+	last := 0
+	for _, loc := range templateExpr.FindAllStringSubmatchIndex(s, -1) {
+		b.WriteString(literal(s[last:loc[0]]))
+		if scope, name, err := parseExpr(s[loc[2]:loc[3]]); err == nil {
+			b.WriteString(expr(scope, name))
+		} else {
+			b.WriteString(literal(s[loc[0]:loc[1]]))
+		}
+		last = loc[1]
+	}
+	b.WriteString(literal(s[last:]))
+	return b.String()
+}
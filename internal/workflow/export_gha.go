@@ -0,0 +1,222 @@
+package workflow
+
+import (
+	"cmp"
+	"fmt"
+	"maps"
+	"math"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+type ghaWorkflow struct {
+	Name string              `yaml:"name"`
+	On   map[string]struct{} `yaml:"on"`
+	Jobs map[string]ghaJob   `yaml:"jobs"`
+}
+
+type ghaJob struct {
+	RunsOn string            `yaml:"runs-on"`
+	Env    map[string]string `yaml:"env,omitempty"`
+	Steps  []ghaStep         `yaml:"steps"`
+}
+
+type ghaStep struct {
+	ID              string            `yaml:"id,omitempty"`
+	Name            string            `yaml:"name,omitempty"`
+	If              string            `yaml:"if,omitempty"`
+	Uses            string            `yaml:"uses,omitempty"`
+	Shell           string            `yaml:"shell,omitempty"`
+	Env             map[string]string `yaml:"env,omitempty"`
+	TimeoutMinutes  int               `yaml:"timeout-minutes,omitempty"`
+	ContinueOnError bool              `yaml:"continue-on-error,omitempty"`
+	Run             string            `yaml:"run,omitempty"`
+}
+
+// ghaIDChars are what a GitHub Actions step or job ID can't contain.
+var ghaIDChars = regexp.MustCompile(`[^A-Za-z0-9_-]+`)
+
+// ghaID turns s into a step or job ID: letters, digits, _ and -, starting
+// with a letter or _.
+func ghaID(s string) string {
+	id := strings.Trim(ghaIDChars.ReplaceAllString(s, "_"), "_-")
+	if id == "" || !(id[0] == '_' || 'a' <= id[0] && id[0] <= 'z' || 'A' <= id[0] && id[0] <= 'Z') {
+		id = "_" + id
+	}
+	return id
+}
+
+// ghaShells are the shells GitHub Actions knows by name; others run as
+// "<shell> {0}".
+var ghaShells = []string{"bash", "sh", "pwsh", "powershell", "python", "cmd"}
+
+// gha exports the workflow as a GitHub Actions workflow with one job,
+// started by hand (workflow_dispatch), which checks out the repository and
+// runs the steps in order. Matrix combinations become steps of their own,
+// and a workflow that rolls back gets rollback steps that run when the job
+// fails.
+func (x *exporter) gha() ([]byte, []string, error) {
+	steps := x.steps()
+
+	// ids maps workflow step IDs to GitHub step IDs; a matrix step has
+	// none of its own. outputs maps registered vars to their step.
+	ids := make(map[string]string)
+	outputs := make(map[string]string)
+	taken := make(map[string]bool)
+	ghaIDs := make([]string, len(steps))
+	for i, s := range steps {
+		id := ghaID(s.ID)
+		if s.label != "" {
+			id = ghaID(s.ID + "_" + s.label)
+		}
+		base := id
+		for n := 2; taken[id]; n++ {
+			id = fmt.Sprintf("%s_%d", base, n)
+		}
+		taken[id] = true
+		ghaIDs[i] = id
+		if s.matrix == "" {
+			ids[s.ID] = id
+		}
+		if s.Register != "" {
+			outputs[s.Register] = id
+		}
+	}
+
+	rewrite := func(s string) string {
+		return rewriteExprs(s, func(lit string) string { return lit }, func(scope, name string) string {
+			if scope == "vars" {
+				return fmt.Sprintf("${{ steps.%s.outputs.%s }}", outputs[name], name)
+			}
+			return fmt.Sprintf("${{ %s.%s }}", scope, name)
+		})
+	}
+	register := func(command, name string) string {
+		return fmt.Sprintf("{\n  echo '%[1]s<<DEV_CLI_EOF'\n%[2]s\n  echo 'DEV_CLI_EOF'\n} >> \"$GITHUB_OUTPUT\"", name, command)
+	}
+
+	job := ghaJob{RunsOn: "ubuntu-latest", Env: maps.Clone(x.wf.Env)}
+	if len(x.wf.Secrets) > 0 {
+		if job.Env == nil {
+			job.Env = make(map[string]string)
+		}
+		names := slices.Sorted(maps.Keys(x.wf.Secrets))
+		for _, name := range names {
+			job.Env[name] = fmt.Sprintf("${{ secrets.%s }}", name)
+		}
+		x.note("add %s as repository secrets", strings.Join(names, ", "))
+	}
+	job.Steps = append(job.Steps, ghaStep{Uses: "actions/checkout@v4"})
+
+	// prev is the workflow step before each one, which a condition
+	// without step_ref looks at.
+	prev := make(map[string]string)
+	for i := 1; i < len(x.wf.Steps); i++ {
+		prev[x.wf.Steps[i].ID] = x.wf.Steps[i-1].ID
+	}
+
+	matrixNoted := make(map[string]bool)
+	rollback := false
+	for i, s := range steps {
+		if s.matrix != "" && !matrixNoted[s.matrix] {
+			matrixNoted[s.matrix] = true
+			x.note("step %q: its matrix combinations run one after another; move them to a job matrix to run them in parallel", s.matrix)
+		}
+		gs := ghaStep{
+			ID:              ghaIDs[i],
+			Name:            s.title(),
+			If:              x.ghaIf(s, prev[cmp.Or(s.matrix, s.ID)], ids),
+			Shell:           ghaShell(s.Shell),
+			Env:             s.Env,
+			ContinueOnError: s.action == FailureContinue,
+			Run:             rewrite(script(s.Step, register)),
+		}
+		if s.Timeout > 0 {
+			gs.TimeoutMinutes = int(math.Ceil(s.Timeout.Minutes()))
+		}
+		job.Steps = append(job.Steps, gs)
+		rollback = rollback || s.action == FailureRollback
+	}
+
+	// The engine undoes the steps that succeeded, newest first.
+	if rollback {
+		for i := len(steps) - 1; i >= 0; i-- {
+			s := steps[i]
+			if s.Rollback == nil {
+				continue
+			}
+			job.Steps = append(job.Steps, ghaStep{
+				Name:  "Roll back " + s.title(),
+				If:    fmt.Sprintf("failure() && steps.%s.outcome == 'success'", ghaIDs[i]),
+				Shell: ghaShell(s.Shell),
+				Env:   s.Env,
+				Run:   rewrite(script(Step{Command: s.Rollback.Command, WorkDir: s.WorkDir}, register)),
+			})
+		}
+	}
+
+	jobID := ghaID(strings.Trim(slugPattern.ReplaceAllString(strings.ToLower(x.wf.Name), "-"), "-"))
+	if jobID == "_" {
+		jobID = "workflow"
+	}
+	var b strings.Builder
+	b.WriteString(x.header())
+	enc := yaml.NewEncoder(&b)
+	enc.SetIndent(2)
+	err := enc.Encode(ghaWorkflow{
+		Name: x.wf.Name,
+		On:   map[string]struct{}{"workflow_dispatch": {}},
+		Jobs: map[string]ghaJob{jobID: job},
+	})
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to encode workflow: %w", err)
+	}
+	return []byte(b.String()), x.notes, nil
+}
+
+// ghaIf is the if: of a step with an exit_code condition on the step
+// before it (prev, a workflow step ID) or the one it names.
+func (x *exporter) ghaIf(s exportedStep, prev string, ids map[string]string) string {
+	c := s.Condition
+	if c == nil || c.Type != CondExitCode {
+		return ""
+	}
+	ref := cmp.Or(c.StepRef, prev)
+	id, ok := ids[ref]
+	if ref != "" && !ok {
+		x.note("step %q: its condition on matrix step %q is not exported", s.ID, ref)
+		return ""
+	}
+	switch c.Value {
+	case "0":
+		if ref == "" {
+			return ""
+		}
+		return fmt.Sprintf("steps.%s.outcome == 'success'", id)
+	case "!0":
+		if ref == "" {
+			return "false"
+		}
+		return fmt.Sprintf("!cancelled() && steps.%s.outcome == 'failure'", id)
+	}
+	x.note("step %q: its exit_code %s condition is exported as any failure", s.ID, c.Value)
+	if ref == "" {
+		return "false"
+	}
+	return fmt.Sprintf("!cancelled() && steps.%s.outcome == 'failure'", id)
+}
+
+// ghaShell is the shell: of a step run with shell, "" for the default.
+func ghaShell(shell string) string {
+	if shell == "" {
+		return ""
+	}
+	if name := filepath.Base(shell); slices.Contains(ghaShells, name) {
+		return name
+	}
+	return shell + " {0}"
+}
@@ -0,0 +1,164 @@
+package workflow
+
+import (
+	"cmp"
+	"fmt"
+	"maps"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// makeTargetChars are what the exported targets don't contain.
+var makeTargetChars = regexp.MustCompile(`[^A-Za-z0-9_.-]+`)
+
+// makeEscaper escapes text for a recipe or variable value.
+var makeEscaper = strings.NewReplacer("$", "$$")
+
+// makefile exports the workflow as a Makefile with a target per step, each
+// depending on the step before, so "make" runs them all in order and
+// "make <step>" runs up to that step. A matrix step's combinations are
+// targets of their own, which make -j runs in parallel. Rollbacks are a
+// "rollback" target to run by hand after a failure.
+func (x *exporter) makefile() []byte {
+	steps := x.steps()
+
+	targets := make([]string, len(steps))
+	taken := map[string]bool{"all": true, "rollback": true}
+	name := func(s string) string {
+		base := cmp.Or(strings.Trim(makeTargetChars.ReplaceAllString(s, "-"), "-"), "step")
+		t := base
+		for n := 2; taken[t]; n++ {
+			t = fmt.Sprintf("%s-%d", base, n)
+		}
+		taken[t] = true
+		return t
+	}
+	// stepTargets maps workflow step IDs to their targets; a matrix
+	// step's depends on its combinations'.
+	stepTargets := make(map[string]string)
+	for i, s := range steps {
+		if s.label == "" {
+			targets[i] = name(s.ID)
+			stepTargets[s.ID] = targets[i]
+			continue
+		}
+		if _, ok := stepTargets[s.matrix]; !ok {
+			stepTargets[s.matrix] = name(s.matrix)
+		}
+		targets[i] = name(s.matrix + "-" + s.label)
+	}
+
+	rewrite := func(s string) string {
+		return rewriteExprs(s, makeEscaper.Replace, func(scope, name string) string {
+			if scope == "vars" {
+				return fmt.Sprintf(`$$(cat "$$WORKFLOW_VARS/%s")`, name)
+			}
+			return "$(" + name + ")"
+		})
+	}
+	register := func(command, name string) string {
+		return fmt.Sprintf("mkdir -p \"$WORKFLOW_VARS\"\n{\n%[2]s\n} > \"$WORKFLOW_VARS/%[1]s\"\ncat \"$WORKFLOW_VARS/%[1]s\"", name, command)
+	}
+
+	for _, name := range slices.Sorted(maps.Keys(x.wf.Secrets)) {
+		x.note("set %s in the environment; the workflow reads it from %s", name, x.wf.Secrets[name])
+	}
+	x.note("step timeouts are not exported; make lets a step run as long as it takes")
+	for _, s := range x.wf.Steps {
+		if s.Condition != nil && s.Condition.Type == CondExitCode && s.Condition.Value != "0" {
+			x.note("step %q: its exit_code %s condition is not exported; make stops at a failed step", s.ID, s.Condition.Value)
+		}
+	}
+
+	var rollbacks []string
+	for i := len(steps) - 1; i >= 0; i-- {
+		if steps[i].Rollback != nil {
+			rollbacks = append(rollbacks, "rollback-"+targets[i])
+		}
+	}
+	if len(rollbacks) > 0 && slices.ContainsFunc(steps, func(s exportedStep) bool { return s.action == FailureRollback }) {
+		x.note("make doesn't roll back a failed run; run make rollback")
+	}
+
+	var b strings.Builder
+	b.WriteString(x.header())
+	b.WriteString("# Needs GNU make 3.82 or later, to run each recipe as one script.\n\n")
+	b.WriteString(".ONESHELL:\n.SHELLFLAGS := -ec\n\n")
+	if slices.ContainsFunc(steps, func(s exportedStep) bool { return s.Register != "" }) {
+		b.WriteString("export WORKFLOW_VARS := $(CURDIR)/.workflow-vars\n")
+	}
+	for _, k := range slices.Sorted(maps.Keys(x.wf.Env)) {
+		fmt.Fprintf(&b, "export %s := %s\n", k, strings.ReplaceAll(makeEscaper.Replace(x.wf.Env[k]), "#", `\#`))
+	}
+
+	var all []string
+	for _, s := range x.wf.Steps {
+		all = append(all, stepTargets[s.ID])
+	}
+	phony := append(append([]string{"all"}, slices.Concat(targets, all)...), rollbacks...)
+	if len(rollbacks) > 0 {
+		phony = append(phony, "rollback")
+	}
+	fmt.Fprintf(&b, "\n.PHONY: %s\n\nall: %s\n", strings.Join(slices.Compact(slices.Sorted(slices.Values(phony))), " "), strings.Join(all, " "))
+
+	recipe := func(target, deps string, s exportedStep, command string, ignoreErrors bool) {
+		b.WriteString("\n")
+		if s.Shell != "" {
+			fmt.Fprintf(&b, "%s: private SHELL := %s\n", target, s.Shell)
+		}
+		fmt.Fprintf(&b, "%s:%s\n", target, deps)
+		var lines []string
+		for _, k := range slices.Sorted(maps.Keys(s.Env)) {
+			lines = append(lines, fmt.Sprintf("export %s=%s", k, shellQuote(s.Env[k])))
+		}
+		lines = append(lines, strings.Split(rewrite(command), "\n")...)
+		if ignoreErrors {
+			lines[0] = "-" + lines[0]
+		}
+		b.WriteString("\t" + strings.Join(lines, "\n\t") + "\n")
+	}
+
+	prev := ""
+	for i, s := range steps {
+		deps := ""
+		if prev != "" {
+			deps = " " + prev
+		}
+		recipe(targets[i], deps, s, script(s.Step, register), s.action == FailureContinue)
+
+		// After a matrix step's last combination, its own target.
+		if s.label != "" && (i+1 == len(steps) || steps[i+1].matrix != s.matrix) {
+			var combos []string
+			for j, c := range steps {
+				if c.matrix == s.matrix {
+					combos = append(combos, targets[j])
+				}
+			}
+			fmt.Fprintf(&b, "\n%s: %s\n", stepTargets[s.matrix], strings.Join(combos, " "))
+		}
+		if s.label == "" || i+1 == len(steps) || steps[i+1].matrix != s.matrix {
+			prev = stepTargets[cmp.Or(s.matrix, s.ID)]
+		}
+	}
+
+	// Rollbacks run newest first, each whether the one before worked or not.
+	if len(rollbacks) > 0 {
+		fmt.Fprintf(&b, "\nrollback: %s\n", strings.Join(rollbacks, " "))
+		next := ""
+		for i := len(steps) - 1; i >= 0; i-- {
+			s := steps[i]
+			if s.Rollback == nil {
+				continue
+			}
+			deps := ""
+			if next != "" {
+				deps = " " + next
+			}
+			rb := exportedStep{Step: Step{Shell: s.Shell, Env: s.Env}}
+			recipe("rollback-"+targets[i], deps, rb, script(Step{Command: s.Rollback.Command, WorkDir: s.WorkDir}, register), true)
+			next = "rollback-" + targets[i]
+		}
+	}
+	return []byte(b.String())
+}
@@ -0,0 +1,216 @@
+package workflow
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"gopkg.in/yaml.v3"
+)
+
+const exportYAML = `
+name: Deploy API
+description: Build and ship the api.
+env:
+  REGISTRY: ghcr.io/acme
+secrets:
+  TOKEN: keyring:acme/deploy
+on_failure:
+  action: rollback
+notify:
+  - type: desktop
+steps:
+  - id: version
+    command: echo v1
+    register: version
+  - id: test
+    command: echo testing ${{ matrix.pkg }}
+    matrix:
+      pkg: [api, worker]
+  - id: build
+    dir: ~/src/api
+    shell: /bin/bash
+    env:
+      CGO_ENABLED: "0"
+    command: echo "$REGISTRY/api:${{ vars.version }} ${{ env.REGISTRY }}"
+    retry:
+      attempts: 3
+      backoff: 5s
+      max_delay: 20s
+    rollback:
+      command: echo undo ${{ vars.version }}
+  - id: announce
+    condition:
+      type: env_set
+      value: SLACK_URL
+    command: curl -X POST "$SLACK_URL"
+`
+
+func TestExportGitHubActions(t *testing.T) {
+	wf, err := Parse([]byte(exportYAML))
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, notes, err := Export(wf, "gha")
+	if err != nil {
+		t.Fatalf("Export() error = %v", err)
+	}
+
+	var out struct {
+		Jobs map[string]struct {
+			Env   map[string]string `yaml:"env"`
+			Steps []struct {
+				ID    string            `yaml:"id"`
+				If    string            `yaml:"if"`
+				Uses  string            `yaml:"uses"`
+				Shell string            `yaml:"shell"`
+				Env   map[string]string `yaml:"env"`
+				Run   string            `yaml:"run"`
+			} `yaml:"steps"`
+		} `yaml:"jobs"`
+	}
+	if err := yaml.Unmarshal(data, &out); err != nil {
+		t.Fatalf("export is not YAML: %v\n%s", err, data)
+	}
+	job, ok := out.Jobs["deploy-api"]
+	if !ok {
+		t.Fatalf("expected a deploy-api job, got:\n%s", data)
+	}
+	if job.Env["TOKEN"] != "${{ secrets.TOKEN }}" || job.Env["REGISTRY"] != "ghcr.io/acme" {
+		t.Errorf("job env = %v", job.Env)
+	}
+
+	var ids []string
+	for _, s := range job.Steps {
+		ids = append(ids, s.ID)
+	}
+	if got := strings.Join(ids, ","); got != ",version,test_pkg_api,test_pkg_worker,build,announce," {
+		t.Fatalf("step IDs = %s", got)
+	}
+	steps := job.Steps
+	if steps[0].Uses != "actions/checkout@v4" {
+		t.Errorf("expected a checkout first, got %+v", steps[0])
+	}
+	if !strings.Contains(steps[1].Run, `>> "$GITHUB_OUTPUT"`) {
+		t.Errorf("expected the registered step to write $GITHUB_OUTPUT, got:\n%s", steps[1].Run)
+	}
+	if steps[3].Run != "echo testing worker" {
+		t.Errorf("matrix step run = %q", steps[3].Run)
+	}
+	build := steps[4]
+	for _, want := range []string{`cd ~"/src/api"`, "${{ steps.version.outputs.version }} ${{ env.REGISTRY }}", `[ "$attempt" -lt 3 ]`, "delay * 2 > 20 ? 20"} {
+		if !strings.Contains(build.Run, want) {
+			t.Errorf("build run lacks %q:\n%s", want, build.Run)
+		}
+	}
+	if build.Shell != "bash" || build.Env["CGO_ENABLED"] != "0" {
+		t.Errorf("build shell %q, env %v", build.Shell, build.Env)
+	}
+	if !strings.HasPrefix(steps[5].Run, `printenv "SLACK_URL" >/dev/null || exit 0`) {
+		t.Errorf("announce run = %q", steps[5].Run)
+	}
+	rollback := steps[6]
+	if rollback.If != "failure() && steps.build.outcome == 'success'" || !strings.Contains(rollback.Run, "echo undo ${{ steps.version.outputs.version }}") {
+		t.Errorf("rollback step = %+v", rollback)
+	}
+
+	text := strings.Join(notes, "\n")
+	for _, want := range []string{"notify is not exported", "add TOKEN as repository secrets", `step "test": its matrix combinations`} {
+		if !strings.Contains(text, want) || !strings.Contains(string(data), "# TODO: "+want) {
+			t.Errorf("expected note %q, got:\n%s", want, text)
+		}
+	}
+}
+
+func TestExportMakefile(t *testing.T) {
+	wf, err := Parse([]byte(exportYAML))
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, notes, err := Export(wf, "make")
+	if err != nil {
+		t.Fatalf("Export() error = %v", err)
+	}
+	makefile := string(data)
+	for _, want := range []string{
+		"export REGISTRY := ghcr.io/acme\n",
+		"all: version test build announce\n",
+		"test-pkg-api: version\n\techo testing api\n",
+		"test: test-pkg-api test-pkg-worker\n",
+		"build: private SHELL := /bin/bash\nbuild: test\n\texport CGO_ENABLED='0'\n",
+		`echo "$$REGISTRY/api:$$(cat "$$WORKFLOW_VARS/version") $(REGISTRY)"`,
+		"rollback: rollback-build\n",
+		"rollback-build:\n\t-export CGO_ENABLED='0'\n",
+	} {
+		if !strings.Contains(makefile, want) {
+			t.Errorf("Makefile lacks %q:\n%s", want, makefile)
+		}
+	}
+	if !strings.Contains(strings.Join(notes, "\n"), "run make rollback") {
+		t.Errorf("expected a note about make rollback, got %v", notes)
+	}
+
+	if _, _, err := Export(wf, "xml"); err == nil {
+		t.Error("expected an error for an unknown format")
+	}
+}
+
+func TestExportMakefile_Runs(t *testing.T) {
+	if testing.Short() {
+		t.Skip("runs make")
+	}
+	if _, err := exec.LookPath("make"); err != nil {
+		t.Skip("make not installed")
+	}
+	dir := t.TempDir()
+	wf, err := Parse([]byte(`
+name: Runs
+steps:
+  - id: who
+    command: echo world
+    register: who
+  - id: flaky
+    command: |
+      n=$(cat count 2>/dev/null || echo 0); echo $((n+1)) > count
+      [ "$(cat count)" -ge 2 ]
+    retries: 2
+  - id: greet
+    command: echo "hello ${{ vars.who }} from ${{ matrix.x }}" >> greetings
+    matrix:
+      x: [a, b]
+  - id: skipped
+    condition:
+      type: file_exists
+      value: missing
+    command: touch skipped-ran
+  - id: tolerated
+    on_failure: continue
+    command: |
+      false
+      touch tolerated-ran
+  - id: last
+    command: touch last-ran
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, _, _ := Export(wf, "make")
+	if err := os.WriteFile(filepath.Join(dir, "Makefile"), data, 0644); err != nil {
+		t.Fatal(err)
+	}
+	if out, err := exec.Command("make", "-C", dir, "-j2").CombinedOutput(); err != nil {
+		t.Fatalf("make failed: %v\n%s\n%s", err, out, data)
+	}
+
+	greetings, _ := os.ReadFile(filepath.Join(dir, "greetings"))
+	if got := string(greetings); !strings.Contains(got, "hello world from a\n") || !strings.Contains(got, "hello world from b\n") {
+		t.Errorf("greetings = %q", got)
+	}
+	for file, want := range map[string]bool{"skipped-ran": false, "tolerated-ran": false, "last-ran": true} {
+		if _, err := os.Stat(filepath.Join(dir, file)); (err == nil) != want {
+			t.Errorf("%s exists = %v, want %v", file, err == nil, want)
+		}
+	}
+}
@@ -0,0 +1,153 @@
+package workflow
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"dev-cli/internal/storage"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ValidateGenerated checks an AI-drafted workflow. On top of the normal parse
+// rules every step must declare a rollback, so a generated workflow can always
+// be undone. Destructive commands are not rejected but reported as warnings
+// for the user to review before running.
+func ValidateGenerated(data []byte) (*Workflow, []string, error) {
+	wf, err := Parse(data)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	for _, step := range wf.Steps {
+		if step.Rollback == nil || strings.TrimSpace(step.Rollback.Command) == "" {
+			return nil, nil, fmt.Errorf("step %q: rollback is required", step.ID)
+		}
+	}
+
+	safe := NewSafeModeContext()
+	var warnings []string
+	for _, step := range wf.Steps {
+		if safe.isDestructive(step.Command) {
+			warnings = append(warnings, fmt.Sprintf("step %q runs a destructive command: %s", step.ID, step.Command))
+		}
+	}
+
+	return wf, warnings, nil
+}
+
+var slugPattern = regexp.MustCompile(`[^a-z0-9]+`)
+
+// SaveGenerated writes a generated workflow into dir, named after the
+// workflow. Existing files are never overwritten; a numeric suffix is added.
+func SaveGenerated(dir string, wf *Workflow, data []byte) (string, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return "", fmt.Errorf("failed to create workflow directory: %w", err)
+	}
+
+	slug := strings.Trim(slugPattern.ReplaceAllString(strings.ToLower(wf.Name), "-"), "-")
+	if slug == "" {
+		slug = "workflow"
+	}
+
+	// O_EXCL claims the name atomically, so a concurrent save can't take
+	// the same one; only a taken name moves on to the next suffix.
+	path := filepath.Join(dir, slug+".yaml")
+	var f *os.File
+	for i := 2; ; i++ {
+		var err error
+		f, err = os.OpenFile(path, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0644)
+		if err == nil {
+			break
+		}
+		if !errors.Is(err, fs.ErrExist) {
+			return "", fmt.Errorf("failed to create workflow file: %w", err)
+		}
+		path = filepath.Join(dir, fmt.Sprintf("%s-%d.yaml", slug, i))
+	}
+
+	_, err := f.Write(data)
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to write workflow: %w", err)
+	}
+	return path, nil
+}
+
+// RecordedCommand is a command run by hand, in the directory it ran in.
+type RecordedCommand struct {
+	Command string
+	Dir     string
+}
+
+// DraftFromCommands drafts a workflow that runs commands in order, one step
+// each, to turn a fix done by hand into something to run again. Every step
+// gets a placeholder rollback for the user to replace, so the draft passes
+// ValidateGenerated; steps get a dir only when the commands ran in more
+// than one directory.
+func DraftFromCommands(name string, commands []RecordedCommand) (*Workflow, []byte, error) {
+	if len(commands) == 0 {
+		return nil, nil, fmt.Errorf("no commands to draft a workflow from")
+	}
+
+	type draftRollback struct {
+		Command string `yaml:"command"`
+	}
+	type draftStep struct {
+		ID       string        `yaml:"id"`
+		Name     string        `yaml:"name"`
+		Command  string        `yaml:"command"`
+		Dir      string        `yaml:"dir,omitempty"`
+		Rollback draftRollback `yaml:"rollback"`
+	}
+	draft := struct {
+		Name        string      `yaml:"name"`
+		Description string      `yaml:"description"`
+		Steps       []draftStep `yaml:"steps"`
+	}{
+		Name:        name,
+		Description: "Drafted from commands run by hand. Review the steps and replace the placeholder rollbacks before running it.",
+	}
+
+	dirs := make(map[string]bool)
+	for _, c := range commands {
+		dirs[c.Dir] = true
+	}
+	ids := make(map[string]int)
+	for _, c := range commands {
+		id := strings.Trim(slugPattern.ReplaceAllString(strings.ToLower(storage.BaseCommand(c.Command)), "-"), "-")
+		if id == "" {
+			id = "step"
+		}
+		if ids[id]++; ids[id] > 1 {
+			id = fmt.Sprintf("%s-%d", id, ids[id])
+		}
+		step := draftStep{
+			ID:       id,
+			Name:     strings.Join(strings.Fields(c.Command), " "),
+			Command:  c.Command,
+			Rollback: draftRollback{Command: fmt.Sprintf("echo 'TODO: write a rollback for %s'", id)},
+		}
+		if len(dirs) > 1 {
+			step.Dir = c.Dir
+		}
+		draft.Steps = append(draft.Steps, step)
+	}
+
+	data, err := yaml.Marshal(draft)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to encode workflow: %w", err)
+	}
+	wf, _, err := ValidateGenerated(data)
+	if err != nil {
+		return nil, nil, err
+	}
+	return wf, data, nil
+}
@@ -0,0 +1,116 @@
+package workflow
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+// matrixCombo is one combination of a step's matrix values.
+type matrixCombo struct {
+	values map[string]string
+	// label is the combination as key=value pairs in key order, like
+	// "dir=api,node=18".
+	label string
+}
+
+// matrixCombos returns every combination of the matrix's values, varying
+// the last key (in sorted order) fastest.
+func matrixCombos(matrix map[string][]string) []matrixCombo {
+	keys := slices.Sorted(maps.Keys(matrix))
+	combos := []map[string]string{{}}
+	for _, key := range keys {
+		var next []map[string]string
+		for _, combo := range combos {
+			for _, value := range matrix[key] {
+				c := maps.Clone(combo)
+				c[key] = value
+				next = append(next, c)
+			}
+		}
+		combos = next
+	}
+
+	out := make([]matrixCombo, len(combos))
+	for i, values := range combos {
+		pairs := make([]string, len(keys))
+		for j, key := range keys {
+			pairs[j] = key + "=" + values[key]
+		}
+		out[i] = matrixCombo{values: values, label: strings.Join(pairs, ",")}
+	}
+	return out
+}
+
+// executeMatrix runs a step once per matrix combination, up to
+// step.MaxParallel at a time. Each combination gets its own result, under
+// the ID "<step>[<label>]", saved as soon as it finishes; the returned
+// result for the step itself succeeds only if every combination did.
+func (e *Engine) executeMatrix(ctx context.Context, step *Step, env map[string]string, state *RunState) *StepResult {
+	combos := matrixCombos(step.Matrix)
+	limit := step.MaxParallel
+	if limit <= 0 {
+		limit = len(combos)
+	}
+
+	started := time.Now()
+	results := make([]*StepResult, len(combos))
+	sem := make(chan struct{}, limit)
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	for i, combo := range combos {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+
+			run := *step
+			run.ID = fmt.Sprintf("%s[%s]", step.ID, combo.label)
+			run.Name = fmt.Sprintf("%s [%s]", step.Name, combo.label)
+			run.Command = expandMatrix(step.Command, combo.values)
+			run.run = expandMatrix(step.run, combo.values)
+			run.WorkDir = expandMatrix(step.WorkDir, combo.values)
+			result := e.executeStep(ctx, &run, env, state)
+			results[i] = result
+
+			mu.Lock()
+			defer mu.Unlock()
+			state.SetStepResult(result)
+			if e.store != nil {
+				e.store.SaveStepResult(state.RunID, result)
+			}
+			e.publishStep(state, run, result)
+		}()
+	}
+	wg.Wait()
+
+	result := &StepResult{
+		StepID:      step.ID,
+		Status:      StepSuccess,
+		StartedAt:   started,
+		CompletedAt: time.Now(),
+		Duration:    time.Since(started),
+	}
+	var output strings.Builder
+	var failed []string
+	for i, r := range results {
+		fmt.Fprintf(&output, "[%s]\n%s\n", combos[i].label, r.Output)
+		if r.Status == StepFailed {
+			failed = append(failed, combos[i].label)
+			if result.ExitCode == 0 {
+				result.ExitCode = r.ExitCode
+			}
+		}
+	}
+	result.Output = strings.TrimSuffix(output.String(), "\n")
+	if len(failed) > 0 {
+		result.Status = StepFailed
+		result.Error = fmt.Sprintf("%d of %d matrix combinations failed: %s", len(failed), len(combos), strings.Join(failed, "; "))
+	}
+	return result
+}
@@ -1,6 +1,7 @@
 package workflow
 
 import (
+	"cmp"
 	"fmt"
 	"os"
 	"time"
@@ -36,20 +37,36 @@ type rawWorkflow struct {
 	Steps       []rawStep         `yaml:"steps"`
 	OnFailure   *FailurePolicy    `yaml:"on_failure"`
 	Env         map[string]string `yaml:"env"`
+	Secrets     map[string]string `yaml:"secrets"`
+	Notify      []Notifier        `yaml:"notify"`
 }
 
 type rawStep struct {
-	ID        string            `yaml:"id"`
-	Name      string            `yaml:"name"`
-	Command   string            `yaml:"command"`
-	Condition *Condition        `yaml:"condition"`
-	OnSuccess string            `yaml:"on_success"`
-	OnFailure string            `yaml:"on_failure"`
-	Rollback  *rawRollback      `yaml:"rollback"`
-	Timeout   string            `yaml:"timeout"`
-	Retries   int               `yaml:"retries"`
-	Env       map[string]string `yaml:"env"`
-	WorkDir   string            `yaml:"workdir"`
+	ID          string              `yaml:"id"`
+	Name        string              `yaml:"name"`
+	Command     string              `yaml:"command"`
+	Condition   *Condition          `yaml:"condition"`
+	OnSuccess   string              `yaml:"on_success"`
+	OnFailure   string              `yaml:"on_failure"`
+	Rollback    *rawRollback        `yaml:"rollback"`
+	Timeout     string              `yaml:"timeout"`
+	Retries     int                 `yaml:"retries"`
+	Retry       *rawRetry           `yaml:"retry"`
+	Env         map[string]string   `yaml:"env"`
+	Dir         string              `yaml:"dir"`
+	WorkDir     string              `yaml:"workdir"` // older spelling of dir
+	Shell       string              `yaml:"shell"`
+	Register    string              `yaml:"register"`
+	Matrix      map[string][]string `yaml:"matrix"`
+	MaxParallel int                 `yaml:"max_parallel"`
+}
+
+type rawRetry struct {
+	Attempts    int      `yaml:"attempts"`
+	Backoff     string   `yaml:"backoff"`
+	MaxDelay    string   `yaml:"max_delay"`
+	Jitter      *float64 `yaml:"jitter"`
+	OnExitCodes []int    `yaml:"on_exit_codes"`
 }
 
 type rawRollback struct {
@@ -77,6 +94,8 @@ func (rw *rawWorkflow) toWorkflow() (*Workflow, error) {
 		Description: rw.Description,
 		OnFailure:   rw.OnFailure,
 		Env:         rw.Env,
+		Secrets:     rw.Secrets,
+		Notify:      rw.Notify,
 		Steps:       make([]Step, 0, len(rw.Steps)),
 	}
 
@@ -101,15 +120,19 @@ func (rw *rawWorkflow) toWorkflow() (*Workflow, error) {
 
 func (rs *rawStep) toStep(index int) (Step, error) {
 	step := Step{
-		ID:        rs.ID,
-		Name:      rs.Name,
-		Command:   rs.Command,
-		Condition: rs.Condition,
-		OnSuccess: rs.OnSuccess,
-		OnFailure: rs.OnFailure,
-		Retries:   rs.Retries,
-		Env:       rs.Env,
-		WorkDir:   rs.WorkDir,
+		ID:          rs.ID,
+		Name:        rs.Name,
+		Command:     rs.Command,
+		Condition:   rs.Condition,
+		OnSuccess:   rs.OnSuccess,
+		OnFailure:   rs.OnFailure,
+		Retries:     rs.Retries,
+		Env:         rs.Env,
+		WorkDir:     cmp.Or(rs.Dir, rs.WorkDir),
+		Shell:       rs.Shell,
+		Register:    rs.Register,
+		Matrix:      rs.Matrix,
+		MaxParallel: rs.MaxParallel,
 	}
 
 	if step.ID == "" {
@@ -126,6 +149,14 @@ func (rs *rawStep) toStep(index int) (Step, error) {
 		step.Timeout = 5 * time.Minute
 	}
 
+	if rs.Retry != nil {
+		retry, err := rs.Retry.toPolicy()
+		if err != nil {
+			return step, err
+		}
+		step.Retry = retry
+	}
+
 	if rs.Rollback != nil {
 		step.Rollback = &RollbackAction{
 			Command: rs.Rollback.Command,
@@ -144,6 +175,36 @@ func (rs *rawStep) toStep(index int) (Step, error) {
 	return step, nil
 }
 
+func (rr *rawRetry) toPolicy() (*RetryPolicy, error) {
+	policy := &RetryPolicy{
+		Attempts:    rr.Attempts,
+		Backoff:     defaultRetryBackoff,
+		Jitter:      defaultRetryJitter,
+		OnExitCodes: rr.OnExitCodes,
+	}
+	if rr.Backoff != "" {
+		d, err := time.ParseDuration(rr.Backoff)
+		if err != nil {
+			return nil, fmt.Errorf("invalid retry backoff %q: %w", rr.Backoff, err)
+		}
+		policy.Backoff = d
+	}
+	if rr.MaxDelay != "" {
+		d, err := time.ParseDuration(rr.MaxDelay)
+		if err != nil {
+			return nil, fmt.Errorf("invalid retry max_delay %q: %w", rr.MaxDelay, err)
+		}
+		policy.MaxDelay = d
+	}
+	if rr.Jitter != nil {
+		policy.Jitter = *rr.Jitter
+	}
+	if err := policy.validate(); err != nil {
+		return nil, err
+	}
+	return policy, nil
+}
+
 func validateWorkflow(wf *Workflow) error {
 	if wf.Name == "" {
 		return fmt.Errorf("workflow name is required")
@@ -153,7 +214,23 @@ func validateWorkflow(wf *Workflow) error {
 		return fmt.Errorf("workflow must have at least one step")
 	}
 
+	for name, ref := range wf.Secrets {
+		if !varName.MatchString(name) {
+			return fmt.Errorf("invalid secret name %q", name)
+		}
+		if _, err := parseSecretRef(name, ref); err != nil {
+			return err
+		}
+	}
+
+	for i, n := range wf.Notify {
+		if err := validateNotifier(n); err != nil {
+			return fmt.Errorf("notify %d: %w", i, err)
+		}
+	}
+
 	stepIDs := make(map[string]bool)
+	registered := make(map[string]bool)
 	for _, step := range wf.Steps {
 		if step.Command == "" {
 			return fmt.Errorf("step %q: command is required", step.ID)
@@ -163,6 +240,38 @@ func validateWorkflow(wf *Workflow) error {
 			return fmt.Errorf("duplicate step ID: %s", step.ID)
 		}
 		stepIDs[step.ID] = true
+
+		if step.Register != "" {
+			if !varName.MatchString(step.Register) {
+				return fmt.Errorf("step %q: invalid register name %q", step.ID, step.Register)
+			}
+			registered[step.Register] = true
+		}
+
+		if err := validateMatrix(step); err != nil {
+			return fmt.Errorf("step %q: %w", step.ID, err)
+		}
+	}
+
+	for _, step := range wf.Steps {
+		templates := []string{step.Command, step.WorkDir}
+		if step.Condition != nil {
+			templates = append(templates, step.Condition.Value)
+		}
+		if step.Rollback != nil {
+			templates = append(templates, step.Rollback.Command)
+		}
+		for _, t := range templates {
+			names, err := referenced(t, "vars")
+			if err != nil {
+				return fmt.Errorf("step %q: %w", step.ID, err)
+			}
+			for _, name := range names {
+				if !registered[name] {
+					return fmt.Errorf("step %q: no step registers vars.%s", step.ID, name)
+				}
+			}
+		}
 	}
 
 	for _, step := range wf.Steps {
@@ -179,6 +288,78 @@ func validateWorkflow(wf *Workflow) error {
 	return nil
 }
 
+func validateNotifier(n Notifier) error {
+	switch n.Type {
+	case "desktop":
+	case "slack", "webhook":
+		if n.URL == "" {
+			return fmt.Errorf("%s notifier needs a url", n.Type)
+		}
+	default:
+		return fmt.Errorf("unknown notifier type %q: want desktop, slack or webhook", n.Type)
+	}
+	vars, err := referenced(n.URL, "vars")
+	matrix, _ := referenced(n.URL, "matrix")
+	if err != nil || len(vars)+len(matrix) > 0 {
+		return fmt.Errorf("notifier url can only use ${{ env.NAME }}")
+	}
+	for _, on := range n.On {
+		if on != "success" && on != "failure" {
+			return fmt.Errorf("invalid on %q: want success or failure", on)
+		}
+	}
+	return nil
+}
+
+// validateMatrix checks a step's matrix, and that ${{ matrix.<key> }} is
+// only used in the command and dir of a step whose matrix has the key.
+func validateMatrix(step Step) error {
+	for key, values := range step.Matrix {
+		if !varName.MatchString(key) {
+			return fmt.Errorf("invalid matrix key %q", key)
+		}
+		if len(values) == 0 {
+			return fmt.Errorf("matrix key %q has no values", key)
+		}
+	}
+	if step.Matrix != nil && step.Register != "" {
+		return fmt.Errorf("a matrix step can't register a variable")
+	}
+	if step.MaxParallel < 0 {
+		return fmt.Errorf("invalid max_parallel %d", step.MaxParallel)
+	}
+
+	for _, t := range []string{step.Command, step.WorkDir} {
+		keys, err := referenced(t, "matrix")
+		if err != nil {
+			return err
+		}
+		for _, key := range keys {
+			if _, ok := step.Matrix[key]; !ok {
+				return fmt.Errorf("matrix.%s is not in the step's matrix", key)
+			}
+		}
+	}
+	if keys, _ := referenced(step.WorkDir, "matrix"); len(keys) > 0 && step.Rollback != nil {
+		// The rollback runs once for the whole step, so it has no
+		// combination to take the dir from.
+		return fmt.Errorf("a step with matrix.%s in its dir can't have a rollback", keys[0])
+	}
+	var others []string
+	if step.Condition != nil {
+		others = append(others, step.Condition.Value)
+	}
+	if step.Rollback != nil {
+		others = append(others, step.Rollback.Command)
+	}
+	for _, t := range others {
+		if keys, _ := referenced(t, "matrix"); len(keys) > 0 {
+			return fmt.Errorf("matrix.%s can only be used in the command and dir", keys[0])
+		}
+	}
+	return nil
+}
+
 // generateID creates a simple unique ID based on timestamp.
 func generateID() string {
 	return fmt.Sprintf("wf_%d", time.Now().UnixNano())
@@ -128,6 +128,90 @@ steps:
     on_success: nonexistent`,
 			wantErr: "unknown step",
 		},
+		{
+			name: "unregistered variable",
+			yaml: `
+name: test
+steps:
+  - id: step1
+    command: echo ${{ vars.version }}`,
+			wantErr: "no step registers vars.version",
+		},
+		{
+			name: "invalid expression",
+			yaml: `
+name: test
+steps:
+  - id: step1
+    command: echo ${{ steps.build.output }}`,
+			wantErr: "invalid expression",
+		},
+		{
+			name: "invalid register name",
+			yaml: `
+name: test
+steps:
+  - id: step1
+    command: echo 1
+    register: my-var`,
+			wantErr: "invalid register name",
+		},
+		{
+			name: "matrix key not declared",
+			yaml: `
+name: test
+steps:
+  - id: step1
+    command: echo ${{ matrix.node }}
+    matrix:
+      os: [linux]`,
+			wantErr: "matrix.node is not in the step's matrix",
+		},
+		{
+			name: "matrix in rollback",
+			yaml: `
+name: test
+steps:
+  - id: step1
+    command: echo ${{ matrix.node }}
+    rollback: echo ${{ matrix.node }}
+    matrix:
+      node: ["18"]`,
+			wantErr: "can only be used in the command",
+		},
+		{
+			name: "invalid secret source",
+			yaml: `
+name: test
+secrets:
+  TOKEN: vault:api
+steps:
+  - id: step1
+    command: echo 1`,
+			wantErr: "invalid source",
+		},
+		{
+			name: "notifier without url",
+			yaml: `
+name: test
+notify:
+  - type: slack
+steps:
+  - id: step1
+    command: echo 1`,
+			wantErr: "slack notifier needs a url",
+		},
+		{
+			name: "empty matrix values",
+			yaml: `
+name: test
+steps:
+  - id: step1
+    command: echo 1
+    matrix:
+      node: []`,
+			wantErr: "has no values",
+		},
 	}
 
 	for _, tt := range tests {
@@ -180,3 +264,94 @@ func TestGenerateRunID(t *testing.T) {
 		t.Errorf("GenerateRunID() = %q, want prefix 'run_'", id1)
 	}
 }
+
+func TestValidateGenerated(t *testing.T) {
+	valid := `
+name: Deploy API
+steps:
+  - id: build
+    command: docker build -t api .
+    rollback: docker rmi api
+  - id: clean
+    command: rm -rf ./dist
+    rollback: "true"
+`
+	wf, warnings, err := ValidateGenerated([]byte(valid))
+	if err != nil {
+		t.Fatalf("ValidateGenerated() error = %v", err)
+	}
+	if len(warnings) != 1 || !strings.Contains(warnings[0], "clean") {
+		t.Errorf("expected one destructive warning for clean, got %v", warnings)
+	}
+
+	missing := `
+name: no-rollback
+steps:
+  - id: build
+    command: make
+`
+	if _, _, err := ValidateGenerated([]byte(missing)); err == nil {
+		t.Error("expected error for step without rollback")
+	}
+
+	dir := t.TempDir()
+	first, err := SaveGenerated(dir, wf, []byte(valid))
+	if err != nil {
+		t.Fatalf("SaveGenerated() error = %v", err)
+	}
+	second, _ := SaveGenerated(dir, wf, []byte(valid))
+	if !strings.HasSuffix(first, "deploy-api.yaml") || !strings.HasSuffix(second, "deploy-api-2.yaml") {
+		t.Errorf("unexpected paths %q, %q", first, second)
+	}
+
+	// A name the filesystem refuses is an error, not a search for a free one.
+	long := &Workflow{Name: strings.Repeat("x", 300)}
+	if _, err := SaveGenerated(dir, long, []byte(valid)); err == nil {
+		t.Error("expected an error for a file name that is too long")
+	}
+}
+
+func TestDraftFromCommands(t *testing.T) {
+	wf, data, err := DraftFromCommands("Fix api", []RecordedCommand{
+		{Command: "git pull", Dir: "/src/api"},
+		{Command: "FOO=1 sudo systemctl restart api", Dir: "/src/api"},
+		{Command: "git   status", Dir: "/src/api"},
+	})
+	if err != nil {
+		t.Fatalf("DraftFromCommands() error = %v", err)
+	}
+	var ids []string
+	for _, step := range wf.Steps {
+		ids = append(ids, step.ID)
+		if step.WorkDir != "" {
+			t.Errorf("step %q: dir = %q, want none for a single directory", step.ID, step.WorkDir)
+		}
+		if step.Rollback == nil || !strings.Contains(step.Rollback.Command, "TODO") {
+			t.Errorf("step %q: want a placeholder rollback, got %+v", step.ID, step.Rollback)
+		}
+	}
+	if got := strings.Join(ids, ","); got != "git,systemctl,git-2" {
+		t.Errorf("step IDs = %s, want git,systemctl,git-2", got)
+	}
+	if wf.Steps[2].Name != "git status" || wf.Steps[2].Command != "git   status" {
+		t.Errorf("step 3 = %q / %q", wf.Steps[2].Name, wf.Steps[2].Command)
+	}
+	if _, _, err := ValidateGenerated(data); err != nil {
+		t.Errorf("draft does not validate: %v", err)
+	}
+
+	wf, _, err = DraftFromCommands("Two dirs", []RecordedCommand{
+		{Command: "make", Dir: "/a"},
+		{Command: "make install", Dir: "/b"},
+	})
+	if err != nil {
+		t.Fatalf("DraftFromCommands() error = %v", err)
+	}
+	if wf.Steps[0].WorkDir != "/a" || wf.Steps[1].WorkDir != "/b" {
+		t.Errorf("dirs = %q, %q, want /a, /b", wf.Steps[0].WorkDir, wf.Steps[1].WorkDir)
+	}
+
+	if _, _, err := DraftFromCommands("Empty", nil); err == nil {
+		t.Error("expected error for no commands")
+	}
+}
@@ -0,0 +1,92 @@
+package workflow
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"time"
+)
+
+// RetryPolicy says how often and how soon a failed step is run again.
+type RetryPolicy struct {
+	// Attempts is the most times the step runs, the first included.
+	Attempts int `yaml:"attempts"`
+	// Backoff is the delay before the first retry; it doubles for each
+	// one after, up to MaxDelay (an hour, or Backoff if longer, when 0).
+	Backoff  time.Duration `yaml:"backoff,omitempty"`
+	MaxDelay time.Duration `yaml:"max_delay,omitempty"`
+	// Jitter spreads each delay randomly by up to this fraction either
+	// way, so steps retrying together don't hit a service in lockstep.
+	Jitter float64 `yaml:"jitter,omitempty"`
+	// OnExitCodes limits retries to failures with these exit codes; any
+	// other failure fails the step at once. Empty retries every failure.
+	OnExitCodes []int `yaml:"on_exit_codes,omitempty"`
+}
+
+const (
+	defaultRetryBackoff = 2 * time.Second
+	defaultRetryJitter  = 0.2
+	// defaultRetryMaxDelay caps the doubling when max_delay is unset, so
+	// many attempts can't overflow the delay.
+	defaultRetryMaxDelay = time.Hour
+)
+
+// retryPolicy is the step's retry block, or for the older "retries: N"
+// the fixed two seconds between N attempts it has always meant.
+func (s *Step) retryPolicy() RetryPolicy {
+	if s.Retry != nil {
+		return *s.Retry
+	}
+	return RetryPolicy{Attempts: max(s.Retries, 1), Backoff: defaultRetryBackoff, MaxDelay: defaultRetryBackoff}
+}
+
+// retryable reports whether a failure with exitCode is worth another
+// attempt.
+func (p RetryPolicy) retryable(exitCode int) bool {
+	return len(p.OnExitCodes) == 0 || slices.Contains(p.OnExitCodes, exitCode)
+}
+
+// maxDelay is the longest delay between attempts.
+func (p RetryPolicy) maxDelay() time.Duration {
+	if p.MaxDelay > 0 {
+		return p.MaxDelay
+	}
+	return max(p.Backoff, defaultRetryMaxDelay)
+}
+
+// delay is how long to wait before retry n (1 for the first), with r in
+// [0, 1) placing it within the jitter.
+func (p RetryPolicy) delay(n int, r float64) time.Duration {
+	limit := p.maxDelay()
+	d := p.Backoff
+	for i := 1; i < n && d < limit; i++ {
+		d *= 2
+	}
+	d = min(d, limit)
+	return time.Duration(float64(d) * (1 + p.Jitter*(2*r-1)))
+}
+
+// validate checks a retry block parsed from YAML.
+func (p RetryPolicy) validate() error {
+	switch {
+	case p.Attempts < 1:
+		return fmt.Errorf("retry attempts must be at least 1")
+	case p.Backoff < 0 || p.MaxDelay < 0:
+		return fmt.Errorf("retry delays can't be negative")
+	case p.Jitter < 0 || p.Jitter > 1:
+		return fmt.Errorf("retry jitter must be between 0 and 1")
+	}
+	return nil
+}
+
+// waitRetry waits d before a retry, returning false if ctx ends first.
+func waitRetry(ctx context.Context, d time.Duration) bool {
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-ctx.Done():
+		return false
+	case <-t.C:
+		return true
+	}
+}
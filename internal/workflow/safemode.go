@@ -130,6 +130,12 @@ func (c *SafeModeContext) RequireApprovalForDestructive(command string) bool {
 	return c.RequireApproval(fmt.Sprintf("⚠️  Potentially destructive command:\n  %s\n\nProceed?", command))
 }
 
+// IsDestructive reports whether command matches one of the
+// DefaultDestructivePatterns.
+func IsDestructive(command string) bool {
+	return NewSafeModeContext().isDestructive(command)
+}
+
 // isDestructive checks if a command matches any destructive pattern.
 func (c *SafeModeContext) isDestructive(command string) bool {
 	lower := strings.ToLower(command)
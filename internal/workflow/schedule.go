@@ -0,0 +1,202 @@
+package workflow
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// ScheduledWorkflow is a workflow file run on a cron schedule by the
+// scheduler daemon.
+type ScheduledWorkflow struct {
+	ID        int64
+	Cron      string
+	Path      string
+	CreatedAt time.Time
+	// LastRun is when the scheduler last started it, and LastRunID the
+	// run it started; zero before the first run.
+	LastRun   time.Time
+	LastRunID string
+}
+
+// Next is when the schedule runs next after the last run (or its creation).
+func (s ScheduledWorkflow) Next() (time.Time, error) {
+	c, err := ParseCron(s.Cron)
+	if err != nil {
+		return time.Time{}, err
+	}
+	from := s.CreatedAt
+	if !s.LastRun.IsZero() {
+		from = s.LastRun
+	}
+	return c.Next(from), nil
+}
+
+// AddSchedule stores a schedule for the workflow at path and returns its ID.
+func (s *CheckpointStore) AddSchedule(cron, path string, now time.Time) (int64, error) {
+	if _, err := ParseCron(cron); err != nil {
+		return 0, err
+	}
+	result, err := s.db.Exec(`INSERT INTO workflow_schedules (cron, path, created_at) VALUES (?, ?, ?)`, cron, path, now.Unix())
+	if err != nil {
+		return 0, err
+	}
+	return result.LastInsertId()
+}
+
+// ListSchedules returns the stored schedules, oldest first.
+func (s *CheckpointStore) ListSchedules() ([]ScheduledWorkflow, error) {
+	rows, err := s.db.Query(`SELECT id, cron, path, created_at, COALESCE(last_run, 0), COALESCE(last_run_id, '')
+		FROM workflow_schedules ORDER BY id`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var schedules []ScheduledWorkflow
+	for rows.Next() {
+		var sw ScheduledWorkflow
+		var created, lastRun int64
+		if err := rows.Scan(&sw.ID, &sw.Cron, &sw.Path, &created, &lastRun, &sw.LastRunID); err != nil {
+			return nil, err
+		}
+		sw.CreatedAt = time.Unix(created, 0)
+		if lastRun != 0 {
+			sw.LastRun = time.Unix(lastRun, 0)
+		}
+		schedules = append(schedules, sw)
+	}
+	return schedules, rows.Err()
+}
+
+// RemoveSchedule deletes a schedule; runs it started stay.
+func (s *CheckpointStore) RemoveSchedule(id int64) error {
+	result, err := s.db.Exec(`DELETE FROM workflow_schedules WHERE id = ?`, id)
+	if err != nil {
+		return err
+	}
+	if n, _ := result.RowsAffected(); n == 0 {
+		return fmt.Errorf("schedule not found: %d", id)
+	}
+	return nil
+}
+
+// markScheduleRun records that the scheduler started the schedule at t.
+func (s *CheckpointStore) markScheduleRun(id int64, t time.Time, runID string) error {
+	_, err := s.db.Exec(`UPDATE workflow_schedules SET last_run = ?, last_run_id = ? WHERE id = ?`, t.Unix(), runID, id)
+	return err
+}
+
+// ScheduleEvent reports a scheduled run starting (Result nil) or ending,
+// or a schedule that couldn't start (RunID empty).
+type ScheduleEvent struct {
+	Schedule ScheduledWorkflow
+	RunID    string
+	Result   *RunResult
+	Err      error
+}
+
+// Scheduler runs the stored schedules when they are due, recording each
+// run in the checkpoint store like `workflow run` does.
+type Scheduler struct {
+	store  *CheckpointStore
+	engine *Engine
+	notify func(ScheduleEvent)
+
+	mu      sync.Mutex
+	running map[int64]bool
+	wg      sync.WaitGroup
+}
+
+// NewScheduler creates a scheduler that starts runs on engine, which
+// should checkpoint to store. notify, if not nil, hears about every run.
+func NewScheduler(store *CheckpointStore, engine *Engine, notify func(ScheduleEvent)) *Scheduler {
+	if notify == nil {
+		notify = func(ScheduleEvent) {}
+	}
+	return &Scheduler{store: store, engine: engine, notify: notify, running: make(map[int64]bool)}
+}
+
+// Run checks the schedules every minute until ctx is done, then waits for
+// the runs it started, which are paused by the cancellation. Schedules
+// are read on every check, so ones added meanwhile are picked up. A
+// schedule that came due while the scheduler was not running runs once
+// when it starts.
+func (s *Scheduler) Run(ctx context.Context) error {
+	defer s.wg.Wait()
+	for {
+		if _, err := s.RunDue(ctx, time.Now()); err != nil {
+			return err
+		}
+		now := time.Now()
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-time.After(now.Truncate(time.Minute).Add(time.Minute).Sub(now)):
+		}
+	}
+}
+
+// RunDue starts the schedules due at now in the background, skipping
+// ones whose previous run is still going, and returns how many it
+// started.
+func (s *Scheduler) RunDue(ctx context.Context, now time.Time) (int, error) {
+	schedules, err := s.store.ListSchedules()
+	if err != nil {
+		return 0, fmt.Errorf("list schedules: %w", err)
+	}
+	started := 0
+	for _, sw := range schedules {
+		next, err := sw.Next()
+		if err != nil || next.IsZero() || next.After(now) {
+			continue
+		}
+		s.mu.Lock()
+		busy := s.running[sw.ID]
+		s.running[sw.ID] = true
+		s.mu.Unlock()
+		if busy {
+			continue
+		}
+
+		wf, err := ParseFile(sw.Path)
+		if err != nil {
+			// Don't retry a broken file every minute; it runs again at
+			// its next time, once fixed.
+			s.store.markScheduleRun(sw.ID, now, "")
+			s.finish(sw.ID)
+			s.notify(ScheduleEvent{Schedule: sw, Err: err})
+			continue
+		}
+		runID, done, err := s.engine.Start(ctx, wf)
+		if err != nil {
+			s.finish(sw.ID)
+			s.notify(ScheduleEvent{Schedule: sw, Err: err})
+			continue
+		}
+		// Err on a start event means the run is going but the schedule
+		// couldn't record it.
+		err = s.store.markScheduleRun(sw.ID, now, runID)
+		s.notify(ScheduleEvent{Schedule: sw, RunID: runID, Err: err})
+		started++
+
+		s.wg.Add(1)
+		go func() {
+			defer s.wg.Done()
+			result := <-done
+			s.finish(sw.ID)
+			s.notify(ScheduleEvent{Schedule: sw, RunID: runID, Result: result})
+		}()
+	}
+	return started, nil
+}
+
+func (s *Scheduler) finish(id int64) {
+	s.mu.Lock()
+	delete(s.running, id)
+	s.mu.Unlock()
+}
+
+// Wait blocks until the runs started so far have finished.
+func (s *Scheduler) Wait() { s.wg.Wait() }
@@ -0,0 +1,109 @@
+package workflow
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"dev-cli/internal/storage"
+)
+
+func TestCronNext(t *testing.T) {
+	// Wednesday.
+	from := time.Date(2025, 1, 15, 10, 7, 30, 0, time.UTC)
+	tests := []struct {
+		expr string
+		want time.Time
+	}{
+		{"* * * * *", time.Date(2025, 1, 15, 10, 8, 0, 0, time.UTC)},
+		{"*/15 * * * *", time.Date(2025, 1, 15, 10, 15, 0, 0, time.UTC)},
+		{"0 3 * * *", time.Date(2025, 1, 16, 3, 0, 0, 0, time.UTC)},
+		{"30 9-17/4 * * 1-5", time.Date(2025, 1, 15, 13, 30, 0, 0, time.UTC)},
+		{"0 0 * * 0", time.Date(2025, 1, 19, 0, 0, 0, 0, time.UTC)},
+		{"0 0 * * 7", time.Date(2025, 1, 19, 0, 0, 0, 0, time.UTC)},
+		{"0 12 1,20 * *", time.Date(2025, 1, 20, 12, 0, 0, 0, time.UTC)},
+		// Day of month or day of week, when both are given.
+		{"0 0 31 * 5", time.Date(2025, 1, 17, 0, 0, 0, 0, time.UTC)},
+		{"@monthly", time.Date(2025, 2, 1, 0, 0, 0, 0, time.UTC)},
+		{"0 0 29 2 *", time.Date(2028, 2, 29, 0, 0, 0, 0, time.UTC)},
+		{"0 0 30 2 *", time.Time{}},
+	}
+	for _, tt := range tests {
+		c, err := ParseCron(tt.expr)
+		if err != nil {
+			t.Errorf("ParseCron(%q): %v", tt.expr, err)
+			continue
+		}
+		if got := c.Next(from); !got.Equal(tt.want) {
+			t.Errorf("%q.Next = %v, want %v", tt.expr, got, tt.want)
+		}
+	}
+
+	for _, bad := range []string{"", "* * * *", "60 * * * *", "* * 0 * *", "*/0 * * * *", "5-1 * * * *", "@often"} {
+		if _, err := ParseCron(bad); err == nil {
+			t.Errorf("ParseCron(%q) should fail", bad)
+		}
+	}
+}
+
+func TestScheduler_RunDue(t *testing.T) {
+	db, err := storage.OpenDB(filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	store := NewCheckpointStore(db)
+	if err := store.InitSchema(); err != nil {
+		t.Fatal(err)
+	}
+
+	path := filepath.Join(t.TempDir(), "hello.yaml")
+	os.WriteFile(path, []byte("name: Hello\nsteps:\n  - command: echo hello\n"), 0o644)
+	created := time.Date(2025, 1, 15, 10, 7, 0, 0, time.Local)
+	id, err := store.AddSchedule("0 * * * *", path, created)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := store.AddSchedule("every hour", path, created); err == nil {
+		t.Error("expected an invalid cron expression to be refused")
+	}
+
+	var events []ScheduleEvent
+	scheduler := NewScheduler(store, NewEngine(store, nil), func(ev ScheduleEvent) { events = append(events, ev) })
+	ctx := context.Background()
+
+	if n, err := scheduler.RunDue(ctx, created.Add(30*time.Minute)); err != nil || n != 0 {
+		t.Fatalf("expected nothing due before 11:00, started %d (%v)", n, err)
+	}
+	if n, _ := scheduler.RunDue(ctx, created.Add(53*time.Minute)); n != 1 {
+		t.Fatalf("expected the schedule to run at 11:00, started %d", n)
+	}
+	scheduler.Wait()
+	if n, _ := scheduler.RunDue(ctx, created.Add(54*time.Minute)); n != 0 {
+		t.Errorf("expected one run per due time, started %d more", n)
+	}
+
+	schedules, _ := store.ListSchedules()
+	if len(schedules) != 1 || schedules[0].LastRunID == "" {
+		t.Fatalf("expected the run recorded on the schedule, got %+v", schedules)
+	}
+	state, err := store.LoadRun(schedules[0].LastRunID)
+	if err != nil || state.Status != StatusCompleted {
+		t.Errorf("expected the run in the checkpoint store, got %+v (%v)", state, err)
+	}
+	if len(events) != 2 || events[1].Result == nil || events[1].Result.Status != StatusCompleted {
+		t.Errorf("expected a start and an end event, got %+v", events)
+	}
+	if next, _ := schedules[0].Next(); !next.Equal(created.Add(113 * time.Minute)) {
+		t.Errorf("expected the next run at 12:00, got %v", next)
+	}
+
+	if err := store.RemoveSchedule(id); err != nil {
+		t.Fatal(err)
+	}
+	if err := store.RemoveSchedule(id); err == nil {
+		t.Error("expected removing a removed schedule to fail")
+	}
+}
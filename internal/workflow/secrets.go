@@ -0,0 +1,83 @@
+package workflow
+
+import (
+	"cmp"
+	"fmt"
+	"slices"
+	"strings"
+
+	"dev-cli/internal/secrets"
+)
+
+// secretRef is where a workflow secret's value comes from: the OS keyring
+// ("keyring:<service>[/<account>]", the account defaulting to the secret's
+// name) or the encrypted secrets file ("file:<key>").
+type secretRef struct {
+	keyring          bool
+	service, account string
+	key              string
+}
+
+func parseSecretRef(name, ref string) (secretRef, error) {
+	source, rest, _ := strings.Cut(strings.TrimSpace(ref), ":")
+	switch {
+	case source == "keyring" && rest != "":
+		service, account, _ := strings.Cut(rest, "/")
+		return secretRef{keyring: true, service: service, account: cmp.Or(account, name)}, nil
+	case source == "file" && rest != "":
+		return secretRef{key: rest}, nil
+	}
+	return secretRef{}, fmt.Errorf("secret %s: invalid source %q: want keyring:<service>[/<account>] or file:<key>", name, ref)
+}
+
+// resolveSecrets looks up the values of the workflow's secrets. The
+// secrets file is only opened if a secret comes from it.
+func (e *Engine) resolveSecrets(wf *Workflow) (map[string]string, error) {
+	values := make(map[string]string, len(wf.Secrets))
+	var file map[string]string
+	for name, ref := range wf.Secrets {
+		r, err := parseSecretRef(name, ref)
+		if err != nil {
+			return nil, err
+		}
+		if r.keyring {
+			value, err := secrets.Keyring(r.service, r.account)
+			if err != nil {
+				return nil, fmt.Errorf("secret %s: %w", name, err)
+			}
+			values[name] = value
+			continue
+		}
+
+		if file == nil {
+			if file, err = secrets.LoadFile(e.secretsFile, e.secretsPassphrase); err != nil {
+				return nil, fmt.Errorf("secret %s: %w", name, err)
+			}
+		}
+		value, ok := file[r.key]
+		if !ok {
+			return nil, fmt.Errorf("secret %s: no %q in %s", name, r.key, e.secretsFile)
+		}
+		values[name] = value
+	}
+	return values, nil
+}
+
+// mask replaces the run's secret values in s with ***, longest first so a
+// secret containing another is masked whole.
+func (r *RunState) mask(s string) string {
+	if len(r.secrets) == 0 || s == "" {
+		return s
+	}
+	values := make([]string, 0, len(r.secrets))
+	for _, v := range r.secrets {
+		if v != "" {
+			values = append(values, v)
+		}
+	}
+	slices.SortFunc(values, func(a, b string) int { return len(b) - len(a) })
+	for _, v := range values {
+		s = strings.ReplaceAll(s, v, "***")
+	}
+	return s
+}
@@ -0,0 +1,172 @@
+package workflow
+
+import (
+	"fmt"
+	"maps"
+	"os"
+	"regexp"
+	"strings"
+)
+
+// templateExpr matches a ${{ ... }} expression in a command or condition.
+var templateExpr = regexp.MustCompile(`\$\{\{(.*?)\}\}`)
+
+// varName is what a register name, and a name after vars. or env., looks
+// like.
+var varName = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// parseExpr splits the inside of a ${{ ... }} expression into its scope,
+// vars, env or matrix, and name.
+func parseExpr(expr string) (scope, name string, err error) {
+	expr = strings.TrimSpace(expr)
+	scope, name, ok := strings.Cut(expr, ".")
+	if !ok || (scope != "vars" && scope != "env" && scope != "matrix") || !varName.MatchString(name) {
+		return "", "", fmt.Errorf("invalid expression ${{ %s }}: want vars.<name>, env.<NAME> or matrix.<key>", expr)
+	}
+	return scope, name, nil
+}
+
+// Interpolate replaces ${{ vars.name }} in s with the output registered
+// under name, and ${{ env.NAME }} with NAME from env or else the process
+// environment (empty when unset). A variable no step registered yet is an
+// error, so a command never runs with a hole in it. ${{ matrix.key }} is
+// left for expandMatrix.
+func Interpolate(s string, vars, env map[string]string) (string, error) {
+	return interpolate(s, vars, env, func(_, _, _, value string) string { return value })
+}
+
+// shellRefs is s with each ${{ vars.name }} and ${{ env.NAME }} replaced by
+// a reference to an env var, DEVCLI_VAR_name or DEVCLI_ENV_NAME, quoted for
+// where it stands in s, and the values to set those to. A POSIX shell then
+// never parses a value as syntax, whatever a step registered.
+func shellRefs(s string, vars, env map[string]string) (string, map[string]string, error) {
+	refs := make(map[string]string)
+	out, err := interpolate(s, vars, env, func(before, scope, name, value string) string {
+		ref := "DEVCLI_" + strings.ToUpper(scope) + "_" + name
+		refs[ref] = value
+		switch single, double := quoteState(before); {
+		case double:
+			return "${" + ref + "}"
+		case single:
+			return `'"${` + ref + `}"'`
+		}
+		return `"${` + ref + `}"`
+	})
+	return out, refs, err
+}
+
+// quoteState reports whether shell text ending in s is inside single or
+// double quotes.
+func quoteState(s string) (single, double bool) {
+	for i := 0; i < len(s); i++ {
+		switch c := s[i]; {
+		case single:
+			single = c != '\''
+		case c == '\\':
+			i++
+		case c == '"':
+			double = !double
+		case c == '\'' && !double:
+			single = true
+		}
+	}
+	return single, double
+}
+
+// interpolate replaces the ${{ vars }} and ${{ env }} expressions in s
+// with what replace makes of their values, as Interpolate describes;
+// before is the text of s ahead of the expression.
+func interpolate(s string, vars, env map[string]string, replace func(before, scope, name, value string) string) (string, error) {
+	var out strings.Builder
+	last := 0
+	for _, m := range templateExpr.FindAllStringSubmatchIndex(s, -1) {
+		out.WriteString(s[last:m[0]])
+		last = m[1]
+		scope, name, err := parseExpr(s[m[2]:m[3]])
+		if err != nil {
+			return "", err
+		}
+		switch scope {
+		case "matrix":
+			out.WriteString(s[m[0]:m[1]])
+			continue
+		case "vars":
+			value, ok := vars[name]
+			if !ok {
+				return "", fmt.Errorf("variable %q is not set: no step registered it yet", name)
+			}
+			out.WriteString(replace(s[:m[0]], scope, name, value))
+			continue
+		}
+		value, ok := env[name]
+		if !ok {
+			value = os.Getenv(name)
+		}
+		out.WriteString(replace(s[:m[0]], scope, name, value))
+	}
+	out.WriteString(s[last:])
+	return out.String(), nil
+}
+
+// referenced returns the names s refers to in scope, as ${{ scope.name }},
+// or the first malformed expression in it.
+func referenced(s, scope string) ([]string, error) {
+	var names []string
+	for _, m := range templateExpr.FindAllStringSubmatch(s, -1) {
+		exprScope, name, err := parseExpr(m[1])
+		if err != nil {
+			return nil, err
+		}
+		if exprScope == scope {
+			names = append(names, name)
+		}
+	}
+	return names, nil
+}
+
+// expandStep returns step with the expressions in its command, dir and
+// condition replaced, against the run's registered vars and the workflow's
+// and step's env. Its Command shows the values, for the policy, approvals
+// and events; the shell runs its shellRefs form instead.
+func expandStep(step Step, wfEnv, vars map[string]string) (Step, error) {
+	env := mergeEnv(wfEnv, step.Env)
+	command, err := Interpolate(step.Command, vars, env)
+	if err != nil {
+		return step, err
+	}
+	if step.run, step.runEnv, err = shellRefs(step.Command, vars, env); err != nil {
+		return step, err
+	}
+	step.Command = command
+	if step.WorkDir, err = Interpolate(step.WorkDir, vars, env); err != nil {
+		return step, err
+	}
+	if step.Condition != nil {
+		cond := *step.Condition
+		if cond.Value, err = Interpolate(cond.Value, vars, env); err != nil {
+			return step, err
+		}
+		step.Condition = &cond
+	}
+	return step, nil
+}
+
+// expandMatrix replaces ${{ matrix.key }} in s with the key's value in
+// combo. The step was validated, so every key is there.
+func expandMatrix(s string, combo map[string]string) string {
+	return templateExpr.ReplaceAllStringFunc(s, func(match string) string {
+		scope, name, err := parseExpr(templateExpr.FindStringSubmatch(match)[1])
+		if err != nil || scope != "matrix" {
+			return match
+		}
+		return combo[name]
+	})
+}
+
+// mergeEnv is the workflow's env with the step's on top.
+func mergeEnv(wfEnv, stepEnv map[string]string) map[string]string {
+	env := make(map[string]string, len(wfEnv)+len(stepEnv))
+	maps.Copy(env, wfEnv)
+	maps.Copy(env, stepEnv)
+	return env
+}
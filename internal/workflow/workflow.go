@@ -3,6 +3,7 @@
 package workflow
 
 import (
+	"slices"
 	"time"
 )
 
@@ -74,9 +75,33 @@ type Step struct {
 	OnFailure string            `yaml:"on_failure,omitempty"` // Step ID, "rollback", or "abort"
 	Rollback  *RollbackAction   `yaml:"rollback,omitempty"`
 	Timeout   time.Duration     `yaml:"timeout,omitempty"`
-	Retries   int               `yaml:"retries,omitempty"`
-	Env       map[string]string `yaml:"env,omitempty"`
-	WorkDir   string            `yaml:"workdir,omitempty"`
+	Retries   int               `yaml:"retries,omitempty"` // Attempts 2s apart; see Retry
+	Retry     *RetryPolicy      `yaml:"retry,omitempty"`   // Backoff and which failures to retry (optional, overrides Retries)
+	Env       map[string]string `yaml:"env,omitempty"`     // Set for the command, on top of the workflow's
+	WorkDir   string            `yaml:"dir,omitempty"`     // Working directory (optional, defaults to the current one)
+	Shell     string            `yaml:"shell,omitempty"`   // Shell to run the command with (optional, defaults to $SHELL)
+	// Register names a variable that captures the step's stdout, for later
+	// steps to use as ${{ vars.<name> }}.
+	Register string `yaml:"register,omitempty"`
+	// Matrix runs the step once per combination of its values, each with
+	// ${{ matrix.<key> }} in the command and dir set to that combination's
+	// value.
+	Matrix map[string][]string `yaml:"matrix,omitempty"`
+	// MaxParallel caps how many combinations run at once; 0 runs them all.
+	MaxParallel int `yaml:"max_parallel,omitempty"`
+
+	// run and runEnv are what expandStep hands the shell: the command with
+	// its expressions as env references, and the values of those.
+	run    string
+	runEnv map[string]string
+}
+
+// shellCommand is the command the shell runs for an expanded step.
+func (s *Step) shellCommand() string {
+	if s.run == "" {
+		return s.Command
+	}
+	return s.run
 }
 
 // FailurePolicy defines workflow-level failure handling.
@@ -92,6 +117,34 @@ type Workflow struct {
 	Steps       []Step            `yaml:"steps"`
 	OnFailure   *FailurePolicy    `yaml:"on_failure,omitempty"`
 	Env         map[string]string `yaml:"env,omitempty"`
+	// Secrets maps env names to where their values come from at run time,
+	// "keyring:<service>[/<account>]" or "file:<key>". Every step gets
+	// them in its env, and their values are masked in stored output.
+	Secrets map[string]string `yaml:"secrets,omitempty"`
+	// Notify lists who hears about the run when it ends.
+	Notify []Notifier `yaml:"notify,omitempty"`
+}
+
+// Notifier sends a summary of a finished run: a desktop notification, a
+// Slack incoming webhook message or a JSON POST to a generic webhook.
+type Notifier struct {
+	Type string `yaml:"type"` // desktop, slack or webhook
+	// URL is the webhook to post to; it may use ${{ env.NAME }}.
+	URL string `yaml:"url,omitempty"`
+	// On limits it to "success" or "failure" runs (both when empty).
+	On []string `yaml:"on,omitempty"`
+}
+
+// Wants reports whether the notifier reports a run that succeeded or not.
+func (n Notifier) Wants(success bool) bool {
+	if len(n.On) == 0 {
+		return true
+	}
+	want := "failure"
+	if success {
+		want = "success"
+	}
+	return slices.Contains(n.On, want)
 }
 
 // StepResult holds the outcome of executing a single step.
@@ -119,6 +172,12 @@ type RunState struct {
 	UpdatedAt      time.Time
 	CompletedAt    time.Time
 	Error          string
+	// Vars holds the stdout registered by the steps run so far.
+	Vars map[string]string
+
+	// secrets holds the resolved secret values; they are looked up again
+	// on resume rather than checkpointed.
+	secrets map[string]string
 }
 
 // NewRunState creates a new RunState for a workflow execution.
@@ -129,6 +188,7 @@ func NewRunState(runID string, wf *Workflow) *RunState {
 		WorkflowName: wf.Name,
 		Status:       StatusPending,
 		StepResults:  make(map[string]*StepResult),
+		Vars:         make(map[string]string),
 		StartedAt:    time.Now(),
 		UpdatedAt:    time.Now(),
 	}
@@ -145,6 +205,15 @@ func (r *RunState) SetStepResult(result *StepResult) {
 	r.UpdatedAt = time.Now()
 }
 
+// SetVar registers value under name for the steps that follow.
+func (r *RunState) SetVar(name, value string) {
+	if r.Vars == nil {
+		r.Vars = make(map[string]string)
+	}
+	r.Vars[name] = value
+	r.UpdatedAt = time.Now()
+}
+
 // LastStepResult returns the most recently completed step result.
 func (r *RunState) LastStepResult() *StepResult {
 	var last *StepResult